@@ -0,0 +1,173 @@
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Services;
+
+/// <summary>
+/// 記録したイベントをアラートルールと照合し、生成したアラートを保持する
+/// </summary>
+/// <remarks>
+/// ルールの期間内の一致件数はルールとタグの組み合わせごとに数え、イベントの発生時刻で期間を判定する。
+/// アラートはサービス全体で連番を振り、保持件数を超えた古いものから捨てる。
+/// </remarks>
+public class AlertRuleEngine
+{
+    /// <summary>
+    /// 既定の保持件数
+    /// </summary>
+    public const int DefaultCapacity = 1000;
+
+    private readonly ILogger<AlertRuleEngine> _logger;
+    private readonly IReadOnlyList<AlertRule> _rules;
+    private readonly int _capacity;
+    private readonly Dictionary<(AlertRule Rule, string TagName), Queue<DateTime>> _matches = new();
+    private readonly Queue<Alert> _alerts = new();
+    private readonly object _lockObject = new();
+    private long _lastSequenceNumber;
+
+    /// <summary>
+    /// 有効なルール
+    /// </summary>
+    public IReadOnlyList<AlertRule> Rules => _rules;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    /// <param name="logger">ロガー</param>
+    /// <param name="rules">アラートルール（パターンが無効なルールは警告を出して無視する）</param>
+    /// <param name="capacity">アラートの保持件数</param>
+    public AlertRuleEngine(ILogger<AlertRuleEngine> logger, IReadOnlyList<AlertRule> rules, int capacity = DefaultCapacity)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+        ArgumentNullException.ThrowIfNull(rules);
+        _capacity = capacity > 0 ? capacity : DefaultCapacity;
+        _rules = rules.Where(IsValid).ToList();
+    }
+
+    private bool IsValid(AlertRule rule)
+    {
+        if (rule.PathPattern != null && !PathPattern.IsValid(rule.PathPattern))
+        {
+            _logger.LogWarning("アラートルールのパターンが無効なため無視します (Rule: {RuleName}, Pattern: {Pattern})", rule.Name, rule.PathPattern);
+            return false;
+        }
+
+        if (rule.Threshold <= 0 || rule.WindowSeconds <= 0)
+        {
+            _logger.LogWarning("アラートルールの件数または期間が無効なため無視します (Rule: {RuleName}, Threshold: {Threshold}, WindowSeconds: {WindowSeconds})",
+                rule.Name, rule.Threshold, rule.WindowSeconds);
+            return false;
+        }
+
+        return true;
+    }
+
+    /// <summary>
+    /// イベントをルールと照合し、条件を満たしたルールのアラートを生成
+    /// </summary>
+    /// <param name="eventData">記録したイベント</param>
+    /// <returns>生成したアラート</returns>
+    public IReadOnlyList<Alert> Evaluate(BaseEventData eventData)
+    {
+        if (_rules.Count == 0)
+        {
+            return Array.Empty<Alert>();
+        }
+
+        List<Alert>? raised = null;
+        lock (_lockObject)
+        {
+            foreach (var rule in _rules.Where(rule => IsMatch(rule, eventData)))
+            {
+                var key = (rule, eventData.TagName);
+                if (!_matches.TryGetValue(key, out var timestamps))
+                {
+                    timestamps = new Queue<DateTime>();
+                    _matches[key] = timestamps;
+                }
+
+                var windowStart = eventData.Timestamp - TimeSpan.FromSeconds(rule.WindowSeconds);
+                while (timestamps.Count > 0 && timestamps.Peek() <= windowStart)
+                {
+                    timestamps.Dequeue();
+                }
+
+                timestamps.Enqueue(eventData.Timestamp);
+                if (timestamps.Count < rule.Threshold)
+                {
+                    continue;
+                }
+
+                var alert = new Alert(++_lastSequenceNumber, rule.Name, eventData.TagName, eventData.Timestamp, timestamps.Count, eventData, rule.Message);
+                timestamps.Clear();
+
+                _alerts.Enqueue(alert);
+                while (_alerts.Count > _capacity)
+                {
+                    _alerts.Dequeue();
+                }
+
+                (raised ??= new List<Alert>()).Add(alert);
+            }
+        }
+
+        return (IReadOnlyList<Alert>?)raised ?? Array.Empty<Alert>();
+    }
+
+    /// <summary>
+    /// 指定した連番より後のアラートを取得
+    /// </summary>
+    /// <param name="tagName">タグ名（nullの場合は全てのタグ）</param>
+    /// <param name="afterSequenceNumber">この連番より後のアラートを返す</param>
+    /// <param name="maxCount">最大件数</param>
+    /// <returns>アラートと全体の最後の連番</returns>
+    public (IReadOnlyList<Alert> Alerts, long LastSequenceNumber) GetAfter(string? tagName, long afterSequenceNumber, int maxCount)
+    {
+        lock (_lockObject)
+        {
+            var alerts = _alerts
+                .Where(alert => alert.SequenceNumber > afterSequenceNumber)
+                .Where(alert => tagName == null || alert.TagName == tagName)
+                .Take(Math.Max(maxCount, 0))
+                .ToList();
+            return (alerts, _lastSequenceNumber);
+        }
+    }
+
+    /// <summary>
+    /// タグのアラートと一致件数を削除（連番は継続する）
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    public void Clear(string tagName)
+    {
+        lock (_lockObject)
+        {
+            var remaining = _alerts.Where(alert => alert.TagName != tagName).ToList();
+            _alerts.Clear();
+            foreach (var alert in remaining)
+            {
+                _alerts.Enqueue(alert);
+            }
+
+            foreach (var key in _matches.Keys.Where(key => key.TagName == tagName).ToList())
+            {
+                _matches.Remove(key);
+            }
+        }
+    }
+
+    private static bool IsMatch(AlertRule rule, BaseEventData eventData)
+    {
+        if (rule.TagName != null && rule.TagName != eventData.TagName)
+        {
+            return false;
+        }
+
+        if (rule.EventNames.Count > 0 && !rule.EventNames.Any(name => EventFilterMatcher.IsEventNameMatch(name, eventData.EventName)))
+        {
+            return false;
+        }
+
+        return rule.PathPattern == null || EventFilterMatcher.GetPaths(eventData).Any(path => PathPattern.IsMatch(rule.PathPattern, path));
+    }
+}
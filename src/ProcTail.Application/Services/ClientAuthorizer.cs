@@ -0,0 +1,170 @@
+using System.Security.Cryptography;
+using System.Text;
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Services;
+
+/// <summary>
+/// クライアントのトークンを認証し、要求の操作とタグを許可するか判定する
+/// </summary>
+/// <remarks>
+/// トークンが1つも設定されていない場合は認証を行わず、全ての要求を許可する（Named Pipeのアクセス制御のみ）。
+/// トークンの照合は比較時間から内容を推測されないよう、一致する位置によらず同じ時間で行う。
+/// </remarks>
+public class ClientAuthorizer
+{
+    /// <summary>
+    /// トークンがない、または一致しない場合のエラーメッセージ
+    /// </summary>
+    public const string UnauthorizedMessage = "Unauthorized: a valid auth token is required";
+
+    /// <summary>
+    /// トークンに許可されていない操作のエラーメッセージの先頭
+    /// </summary>
+    public const string ForbiddenPrefix = "Forbidden:";
+
+    private const string BearerPrefix = "Bearer ";
+
+    private readonly ILogger<ClientAuthorizer> _logger;
+    private readonly List<(ClientToken Client, byte[] Token)> _tokens;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    /// <param name="logger">ロガー</param>
+    /// <param name="tokens">クライアントのトークン（トークンまたはタグのパターンが無効なものは警告を出して無視する）</param>
+    public ClientAuthorizer(ILogger<ClientAuthorizer> logger, IReadOnlyList<ClientToken> tokens)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+        ArgumentNullException.ThrowIfNull(tokens);
+        _tokens = tokens
+            .Where(IsValid)
+            .Select(client => (client, Encoding.UTF8.GetBytes(client.Token)))
+            .ToList();
+    }
+
+    /// <summary>
+    /// トークンによる認証が有効かどうか
+    /// </summary>
+    public bool IsEnabled => _tokens.Count > 0;
+
+    private bool IsValid(ClientToken client)
+    {
+        if (string.IsNullOrWhiteSpace(client.Token))
+        {
+            _logger.LogWarning("クライアントのトークンが空のため無視します (Name: {Name})", client.Name);
+            return false;
+        }
+
+        var invalidPattern = client.Tags.FirstOrDefault(pattern => !PathPattern.IsValid(pattern));
+        if (invalidPattern != null)
+        {
+            _logger.LogWarning("クライアントのタグのパターンが無効なため無視します (Name: {Name}, Pattern: {Pattern})", client.Name, invalidPattern);
+            return false;
+        }
+
+        return true;
+    }
+
+    /// <summary>
+    /// トークンに一致するクライアントを取得
+    /// </summary>
+    /// <param name="token">要求に指定されたトークン</param>
+    /// <returns>一致したクライアント（一致しない場合はnull）</returns>
+    public ClientToken? Authenticate(string? token)
+    {
+        if (string.IsNullOrEmpty(token))
+        {
+            return null;
+        }
+
+        var tokenBytes = Encoding.UTF8.GetBytes(token);
+        ClientToken? matched = null;
+        foreach (var (client, expected) in _tokens)
+        {
+            // 一致した後も残りと照合し、照合にかかる時間をトークンの位置によらず揃える
+            if (CryptographicOperations.FixedTimeEquals(tokenBytes, expected) && matched == null)
+            {
+                matched = client;
+            }
+        }
+
+        if (matched == null)
+        {
+            _logger.LogWarning("クライアントのトークンが一致しないため要求を拒否しました");
+        }
+        return matched;
+    }
+
+    /// <summary>
+    /// Named Pipeの要求の種類に必要な権限を取得
+    /// </summary>
+    /// <param name="requestType">要求の種類（RequestType）</param>
+    /// <returns>必要な権限（不明な種類はAdmin）</returns>
+    public static ClientPermission GetRequiredPermission(string? requestType)
+    {
+        return requestType switch
+        {
+            "GetWatchTargets" or "GetRecordedEvents" or "GetRawEvents" or "GetAlerts" or
+                "GetSaveDirCandidates" or "GetEventSummary" or "GetStatus" => ClientPermission.Read,
+            "AddWatchTarget" or "AddWatchTargetByPath" or "RemoveWatchTarget" or "ClearEvents" => ClientPermission.Watch,
+            _ => ClientPermission.Admin
+        };
+    }
+
+    /// <summary>
+    /// クライアントに操作を許可するかどうか
+    /// </summary>
+    /// <param name="client">認証したクライアント</param>
+    /// <param name="permission">操作に必要な権限</param>
+    /// <param name="tagName">操作するタグ名（タグを指定しない操作の場合はnull）</param>
+    /// <returns>許可する場合true</returns>
+    public static bool IsAllowed(ClientToken client, ClientPermission permission, string? tagName)
+    {
+        ArgumentNullException.ThrowIfNull(client);
+        return client.Permission >= permission && (tagName == null || IsTagAllowed(client, tagName));
+    }
+
+    /// <summary>
+    /// クライアントがタグを操作できるかどうか
+    /// </summary>
+    /// <param name="client">認証したクライアント</param>
+    /// <param name="tagName">タグ名</param>
+    /// <returns>操作できる場合true</returns>
+    public static bool IsTagAllowed(ClientToken client, string tagName)
+    {
+        ArgumentNullException.ThrowIfNull(client);
+        return client.Tags.Count == 0 || client.Tags.Any(pattern => PathPattern.IsMatch(pattern, tagName));
+    }
+
+    /// <summary>
+    /// 許可されていない操作のエラーメッセージを作成
+    /// </summary>
+    /// <param name="client">認証したクライアント</param>
+    /// <param name="operation">操作（要求の種類）</param>
+    /// <param name="tagName">操作するタグ名</param>
+    /// <returns>エラーメッセージ</returns>
+    public static string CreateForbiddenMessage(ClientToken client, string? operation, string? tagName)
+    {
+        return tagName == null
+            ? $"{ForbiddenPrefix} token '{client.Name}' is not allowed to {operation}"
+            : $"{ForbiddenPrefix} token '{client.Name}' is not allowed to {operation} for tag: {tagName}";
+    }
+
+    /// <summary>
+    /// Authorizationヘッダーの値からBearerトークンを取り出す
+    /// </summary>
+    /// <param name="authorization">Authorizationヘッダーの値</param>
+    /// <returns>トークン（Bearer形式でない場合はnull）</returns>
+    public static string? ParseBearerToken(string? authorization)
+    {
+        if (authorization == null || !authorization.StartsWith(BearerPrefix, StringComparison.OrdinalIgnoreCase))
+        {
+            return null;
+        }
+
+        var token = authorization[BearerPrefix.Length..].Trim();
+        return token.Length > 0 ? token : null;
+    }
+}
@@ -0,0 +1,107 @@
+using System.Collections.Concurrent;
+using System.Net;
+
+namespace ProcTail.Application.Services;
+
+/// <summary>
+/// DNSクエリ結果から得たIPアドレス→ホスト名の対応表
+/// </summary>
+/// <remarks>
+/// 監視対象プロセスのDNSクエリ完了イベントで記録し、後続のネットワークイベントのリモートアドレスをホスト名に解決する。
+/// 同じアドレスが複数の名前に解決された場合は最後のクエリを優先する。
+/// </remarks>
+public class DnsResolutionCache
+{
+    private readonly ConcurrentDictionary<string, string> _hostNames = new();
+    private readonly int _maxEntries;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    /// <param name="maxEntries">保持する最大アドレス数（超えた場合は全消去）</param>
+    public DnsResolutionCache(int maxEntries = 4096)
+    {
+        if (maxEntries <= 0)
+            throw new ArgumentOutOfRangeException(nameof(maxEntries), "最大エントリ数は1以上である必要があります");
+
+        _maxEntries = maxEntries;
+    }
+
+    /// <summary>
+    /// 保持しているアドレス数
+    /// </summary>
+    public int Count => _hostNames.Count;
+
+    /// <summary>
+    /// 解決結果を記録
+    /// </summary>
+    /// <param name="hostName">クエリしたホスト名</param>
+    /// <param name="addresses">解決されたアドレス</param>
+    public void Record(string hostName, IEnumerable<string> addresses)
+    {
+        if (string.IsNullOrEmpty(hostName))
+        {
+            return;
+        }
+
+        foreach (var address in addresses)
+        {
+            var key = Normalize(address);
+            if (key == null)
+            {
+                continue;
+            }
+
+            // 長時間稼働でも肥大化しないよう、上限に達したら作り直す
+            if (_hostNames.Count >= _maxEntries && !_hostNames.ContainsKey(key))
+            {
+                _hostNames.Clear();
+            }
+
+            _hostNames[key] = hostName;
+        }
+    }
+
+    /// <summary>
+    /// アドレスからホスト名を取得
+    /// </summary>
+    /// <param name="address">IPアドレス</param>
+    /// <returns>ホスト名（未解決の場合はnull）</returns>
+    public string? TryGetHostName(string address)
+    {
+        var key = Normalize(address);
+        return key != null && _hostNames.TryGetValue(key, out var hostName) ? hostName : null;
+    }
+
+    /// <summary>
+    /// DNS-ClientのQueryResults文字列からアドレスを抽出
+    /// </summary>
+    /// <param name="queryResults">"type:  5 alias.example.com;93.184.216.34;::ffff:93.184.216.34;" 形式の文字列</param>
+    /// <returns>IPアドレス一覧</returns>
+    public static IReadOnlyList<string> ParseQueryResults(string queryResults)
+    {
+        if (string.IsNullOrEmpty(queryResults))
+        {
+            return Array.Empty<string>();
+        }
+
+        // CNAME等のレコードは "type: N 名前" で表されるため、IPアドレスとして解釈できるものだけを返す
+        return queryResults
+            .Split(';', StringSplitOptions.RemoveEmptyEntries | StringSplitOptions.TrimEntries)
+            .Where(entry => IPAddress.TryParse(entry, out _))
+            .ToList();
+    }
+
+    /// <summary>
+    /// IPv4射影アドレス（::ffff:a.b.c.d）をIPv4表記に揃える
+    /// </summary>
+    private static string? Normalize(string address)
+    {
+        if (!IPAddress.TryParse(address, out var ip))
+        {
+            return null;
+        }
+
+        return ip.IsIPv4MappedToIPv6 ? ip.MapToIPv4().ToString() : ip.ToString();
+    }
+}
@@ -0,0 +1,152 @@
+using System.Collections.Concurrent;
+using System.Reflection;
+using System.Text.Json.Serialization;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Services;
+
+/// <summary>
+/// イベントの絞り込み条件の照合
+/// </summary>
+public static class EventFilterMatcher
+{
+    // JSONの$typeと同じ種別名（BaseEventDataのJsonDerivedTypeから取得）
+    private static readonly Dictionary<Type, string> _eventTypes = typeof(BaseEventData)
+        .GetCustomAttributes<JsonDerivedTypeAttribute>()
+        .Where(attribute => attribute.TypeDiscriminator is string)
+        .ToDictionary(attribute => attribute.DerivedType, attribute => (string)attribute.TypeDiscriminator!);
+
+    private static readonly ConcurrentDictionary<string, EventQuery> _queries = new();
+
+    /// <summary>
+    /// 絞り込み条件が有効かどうか（パスのパターンやクエリ式に構文エラーがないか）
+    /// </summary>
+    /// <param name="filter">絞り込み条件</param>
+    /// <returns>有効な場合true</returns>
+    public static bool IsValid(EventFilter filter)
+    {
+        return Validate(filter) == null;
+    }
+
+    /// <summary>
+    /// 絞り込み条件の誤りを取得
+    /// </summary>
+    /// <param name="filter">絞り込み条件</param>
+    /// <returns>誤りの説明（有効な場合はnull）</returns>
+    public static string? Validate(EventFilter filter)
+    {
+        if (!filter.PathPatterns.All(PathPattern.IsValid))
+        {
+            return "Invalid path pattern in filter";
+        }
+
+        if (!string.IsNullOrWhiteSpace(filter.Query))
+        {
+            if (!EventQuery.TryParse(filter.Query, out var query, out var error))
+            {
+                return $"Invalid query: {error}";
+            }
+            EventQuery.AddToCache(_queries, filter.Query, query);
+        }
+
+        return null;
+    }
+
+    /// <summary>
+    /// イベントが絞り込み条件に一致するかどうか
+    /// </summary>
+    /// <param name="filter">絞り込み条件</param>
+    /// <param name="eventData">イベント</param>
+    /// <returns>一致する場合true</returns>
+    public static bool IsMatch(EventFilter filter, BaseEventData eventData)
+    {
+        if (filter.ProcessIds.Count > 0 && !filter.ProcessIds.Contains(eventData.ProcessId))
+        {
+            return false;
+        }
+
+        // 日時の種類（UTC/ローカル）が異なっても比較できるようUTCで比べる
+        var timestamp = eventData.Timestamp.ToUniversalTime();
+        if ((filter.Since.HasValue && timestamp < filter.Since.Value.ToUniversalTime())
+            || (filter.Until.HasValue && timestamp >= filter.Until.Value.ToUniversalTime()))
+        {
+            return false;
+        }
+
+        if (filter.EventTypes.Count > 0
+            && !filter.EventTypes.Contains(GetEventType(eventData), StringComparer.OrdinalIgnoreCase))
+        {
+            return false;
+        }
+
+        if (filter.EventNames.Count > 0 && !filter.EventNames.Any(name => IsEventNameMatch(name, eventData.EventName)))
+        {
+            return false;
+        }
+
+        if (filter.PathPatterns.Count > 0
+            && !GetPaths(eventData).Any(path => filter.PathPatterns.Any(pattern => PathPattern.IsMatch(pattern, path))))
+        {
+            return false;
+        }
+
+        return string.IsNullOrWhiteSpace(filter.Query) || GetQuery(filter.Query).IsMatch(eventData);
+    }
+
+    private static EventQuery GetQuery(string expression)
+    {
+        if (!_queries.TryGetValue(expression, out var query))
+        {
+            query = EventQuery.Parse(expression);
+            EventQuery.AddToCache(_queries, expression, query);
+        }
+        return query;
+    }
+
+    /// <summary>
+    /// イベントの種別名（JSONの$typeと同じ "file", "process_start" など）
+    /// </summary>
+    /// <param name="eventData">イベント</param>
+    /// <returns>種別名（種別が登録されていない場合は空文字列）</returns>
+    public static string GetEventType(BaseEventData eventData)
+    {
+        return _eventTypes.TryGetValue(eventData.GetType(), out var eventType) ? eventType : string.Empty;
+    }
+
+    /// <summary>
+    /// イベント名の条件と照合（"FileIO/*" はカテゴリ内の全てのイベントに一致）
+    /// </summary>
+    internal static bool IsEventNameMatch(string name, string eventName)
+    {
+        return name.EndsWith("/*", StringComparison.Ordinal)
+            ? eventName.StartsWith(name[..^1], StringComparison.OrdinalIgnoreCase)
+            : string.Equals(name, eventName, StringComparison.OrdinalIgnoreCase);
+    }
+
+    /// <summary>
+    /// パスの条件と照合するイベントのパス（リネームはリネーム後のパスも含む）
+    /// </summary>
+    internal static IEnumerable<string> GetPaths(BaseEventData eventData)
+    {
+        switch (eventData)
+        {
+            case FileEventData fileEvent:
+                yield return fileEvent.FilePath;
+                if (fileEvent.RawFilePath != null)
+                {
+                    yield return fileEvent.RawFilePath;
+                }
+                if (fileEvent.Payload.TryGetValue("NewFileName", out var newFileName) && newFileName is string newFilePath)
+                {
+                    yield return newFilePath;
+                }
+                break;
+            case RegistryEventData registryEvent:
+                yield return registryEvent.KeyName;
+                break;
+            case ImageLoadEventData imageLoadEvent:
+                yield return imageLoadEvent.ImagePath;
+                break;
+        }
+    }
+}
@@ -1,4 +1,5 @@
 using System.Collections.Concurrent;
+using System.Text.RegularExpressions;
 using Microsoft.Extensions.Logging;
 using ProcTail.Core.Interfaces;
 using ProcTail.Core.Models;
@@ -8,13 +9,56 @@ namespace ProcTail.Application.Services;
 /// <summary>
 /// イベント処理サービス
 /// </summary>
-public class EventProcessor : IEventProcessor
+public class EventProcessor : IEventProcessor, IFollowUpEventSource
 {
+    /// <summary>
+    /// 更新検出イベントのイベント名
+    /// </summary>
+    public const string UpdateDetectedEventName = "Update/Detected";
+
+    /// <summary>
+    /// 更新検出イベントのプロバイダー名
+    /// </summary>
+    public const string UpdateProviderName = "ProcTail";
+
+    /// <summary>
+    /// クラッシュダンプの書き込みを検出したイベントのイベント名
+    /// </summary>
+    public const string CrashDumpWrittenEventName = "Crash/DumpWritten";
+
     private readonly ILogger<EventProcessor> _logger;
     private readonly IWatchTargetManager _watchTargetManager;
     private readonly IReadOnlyList<string> _enabledProviders;
     private readonly IReadOnlyList<string> _enabledEventNames;
     private readonly ConcurrentDictionary<string, byte> _firstTimeEvents;
+    private readonly DnsResolutionCache _dnsCache = new();
+    private readonly ConcurrentDictionary<string, long> _fileReadCounters = new();
+    private readonly FileSessionTracker _fileSessions = new();
+    private readonly EventRateLimiter _rateLimiter;
+    private readonly IProcessEnvironmentReader? _environmentReader;
+    private readonly IImageHashProvider? _imageHashProvider;
+    private readonly IFileContentHasher? _fileContentHasher;
+    private readonly IProcessTokenReader? _tokenReader;
+    private readonly IReparsePointResolver? _reparsePointResolver;
+    private readonly ICrashDumpLocator? _crashDumpLocator;
+    private readonly ConcurrentDictionary<string, FilterCounters> _filterCounters = new();
+    private readonly ConcurrentDictionary<int, byte> _errorReportedProcesses = new();
+    private readonly ConcurrentDictionary<string, byte> _writtenExecutables = new(StringComparer.OrdinalIgnoreCase);
+
+    // WerFault.exe のコマンドラインの対象プロセス（"-u -p <PID> -s <ID>" など）
+    private static readonly Regex ErrorReportTargetPattern = new(@"(?:^|\s)[-/]p\s+(\d+)", RegexOptions.CultureInvariant);
+
+    // インストーラー・アップデーターとみなす子プロセスの実行ファイル名（小文字で照合）
+    private static readonly string[] InstallerPatterns = { "msiexec.exe", "*setup*.exe", "*installer*.exe", "*updater*.exe", "update.exe" };
+
+    // 書き込みを更新とみなす実行ファイルの場所（"C:\Program Files\" と "C:\Program Files (x86)\"）
+    private static readonly Regex ProgramFilesExecutablePattern = new(@"^[A-Za-z]:[\\/]Program Files( \(x86\))?[\\/].+\.exe$",
+        RegexOptions.IgnoreCase | RegexOptions.CultureInvariant);
+
+    /// <summary>
+    /// 変換後に遅れて作成したイベント（クラッシュダンプの書き込みの検出）を配信する時に発火するイベント
+    /// </summary>
+    public event EventHandler<BaseEventData>? FollowUpEventCreated;
 
     /// <summary>
     /// コンストラクタ
@@ -26,6 +70,35 @@ public class EventProcessor : IEventProcessor
         ILogger<EventProcessor> logger,
         IWatchTargetManager watchTargetManager,
         IEtwConfiguration etwConfiguration)
+        : this(logger, watchTargetManager, etwConfiguration, null)
+    {
+    }
+
+    /// <summary>
+    /// コンストラクタ（子プロセスの環境変数を記録する場合）
+    /// </summary>
+    public EventProcessor(
+        ILogger<EventProcessor> logger,
+        IWatchTargetManager watchTargetManager,
+        IEtwConfiguration etwConfiguration,
+        IProcessEnvironmentReader? environmentReader)
+        : this(logger, watchTargetManager, etwConfiguration, environmentReader, null)
+    {
+    }
+
+    /// <summary>
+    /// コンストラクタ（子プロセスの実行ファイルや書き込んだファイルのハッシュを記録する場合）
+    /// </summary>
+    public EventProcessor(
+        ILogger<EventProcessor> logger,
+        IWatchTargetManager watchTargetManager,
+        IEtwConfiguration etwConfiguration,
+        IProcessEnvironmentReader? environmentReader,
+        IImageHashProvider? imageHashProvider,
+        IFileContentHasher? fileContentHasher = null,
+        IProcessTokenReader? tokenReader = null,
+        IReparsePointResolver? reparsePointResolver = null,
+        ICrashDumpLocator? crashDumpLocator = null)
     {
         _logger = logger ?? throw new ArgumentNullException(nameof(logger));
         _watchTargetManager = watchTargetManager ?? throw new ArgumentNullException(nameof(watchTargetManager));
@@ -34,6 +107,13 @@ public class EventProcessor : IEventProcessor
         _enabledProviders = config.EnabledProviders;
         _enabledEventNames = config.EnabledEventNames;
         _firstTimeEvents = new ConcurrentDictionary<string, byte>();
+        _rateLimiter = new EventRateLimiter(config.MaxEventsPerSecond, config.OverflowSampleRate);
+        _environmentReader = environmentReader;
+        _imageHashProvider = imageHashProvider;
+        _fileContentHasher = fileContentHasher;
+        _tokenReader = tokenReader;
+        _reparsePointResolver = reparsePointResolver;
+        _crashDumpLocator = crashDumpLocator;
     }
 
     /// <summary>
@@ -59,147 +139,829 @@ public class EventProcessor : IEventProcessor
                 return new ProcessingResult(false, ErrorMessage: "Event filtered out");
             }
 
-            // 監視対象プロセスかチェック
-            if (!_watchTargetManager.IsWatchedProcess(rawEvent.ProcessId))
+            // ディレクトリ監視・再監視待ちに一致するプロセスの起動を監視対象に追加
+            await AddProcessByPathAsync(rawEvent);
+
+            // 監視対象プロセスのクラッシュを終了時に判定できるよう、エラー報告の起動を記録
+            TrackErrorReporting(rawEvent);
+
+            // バックエンドがタグを決めたイベント（制限モードのディレクトリ監視など）はPIDによる判定を行わない
+            if (rawEvent.TagName == null && !_watchTargetManager.IsWatchedProcess(rawEvent.ProcessId))
             {
                 return new ProcessingResult(false, ErrorMessage: "Process not watched");
             }
 
-            // タグ名を取得
-            var tagName = _watchTargetManager.GetTagForProcess(rawEvent.ProcessId);
-            if (string.IsNullOrEmpty(tagName))
+            // タグ名を取得（複数のタグに属するプロセスのイベントはタグごとに記録）
+            var tagNames = rawEvent.TagName != null
+                ? new[] { rawEvent.TagName }
+                : _watchTargetManager.GetTagsForProcess(rawEvent.ProcessId);
+            if (tagNames.Count == 0)
             {
                 _logger.LogWarning("監視対象プロセスのタグが見つかりません (ProcessId: {ProcessId})", rawEvent.ProcessId);
                 return new ProcessingResult(false, ErrorMessage: "Tag not found for watched process");
             }
 
+            var enabledTagNames = new List<string>();
+            ProcessingResult? rejected = null;
+            foreach (var tagName in tagNames)
+            {
+                // タグの除外ルールに一致するプロセスは記録しない（子プロセスの自動追加も行わない）
+                if (_watchTargetManager.IsExcludedProcess(rawEvent.ProcessId, tagName))
+                {
+                    Interlocked.Increment(ref GetFilterCounters(tagName).ExcludedProcess);
+                    rejected = new ProcessingResult(false, ErrorMessage: "Process excluded for tag");
+                }
+                // タグごとのオプトインが必要なイベントの判定
+                else if (!IsEnabledForTag(rawEvent, tagName))
+                {
+                    rejected = new ProcessingResult(false, ErrorMessage: "Event disabled for tag");
+                }
+                else
+                {
+                    enabledTagNames.Add(tagName);
+                }
+            }
+
+            if (enabledTagNames.Count == 0)
+            {
+                return rejected!;
+            }
+
             // 初回イベントキャッチの記録・ログ出力
             var eventKey = $"{rawEvent.ProcessId}:{rawEvent.ProviderName}:{rawEvent.EventName}";
             if (_firstTimeEvents.TryAdd(eventKey, 0))
             {
                 _logger.LogInformation("初回イベントキャッチ (Provider: {Provider}, Event: {Event}, ProcessId: {ProcessId}, Tag: {Tag})",
-                    rawEvent.ProviderName, rawEvent.EventName, rawEvent.ProcessId, tagName);
+                    rawEvent.ProviderName, rawEvent.EventName, rawEvent.ProcessId, enabledTagNames[0]);
             }
 
-            // ドメインイベントに変換
-            var eventData = await ConvertToEventDataAsync(rawEvent, tagName);
+            // ドメインイベントに変換（ファイルセッションなどの状態を持つため変換は1回のみ行い、他のタグには複製する。
+            // 環境変数やハッシュなど変換時に取得する情報は最初のタグのオプションに従う）
+            var eventData = await ConvertToEventDataAsync(rawEvent, enabledTagNames[0]);
             if (eventData == null)
             {
                 return new ProcessingResult(false, ErrorMessage: "Failed to convert to domain event");
             }
 
-            _logger.LogDebug("イベント処理完了 (Provider: {Provider}, Event: {Event}, ProcessId: {ProcessId}, Tag: {Tag})",
-                rawEvent.ProviderName, rawEvent.EventName, rawEvent.ProcessId, tagName);
+            // インストーラーの起動や実行ファイルの更新はタグのフィルタとは関係なく検出する
+            var updateEvent = await DetectUpdateAsync(eventData);
+
+            var events = new List<BaseEventData>();
+            foreach (var tagName in enabledTagNames)
+            {
+                var tagEvent = WithTag(eventData, tagName);
+
+                // 記録するディレクトリ外のファイルイベントは件数と書き込みバイト数だけを数えて記録しない
+                if (tagEvent is FileEventData rootedEvent && !IsUnderRecordRoots(rootedEvent, tagName))
+                {
+                    var counters = GetFilterCounters(tagName);
+                    Interlocked.Increment(ref counters.OutsideRoot);
+                    if (rootedEvent.EventName == "FileIO/Write")
+                    {
+                        Interlocked.Add(ref counters.OutsideRootBytesWritten, GetPayloadLong(rootedEvent.Payload, "IoSize"));
+                    }
+                    rejected = new ProcessingResult(false, ErrorMessage: "Event outside record roots");
+                    continue;
+                }
+
+                // タグのパスフィルタに一致しないファイルイベントは記録しない
+                if (tagEvent is FileEventData fileEvent && !IsPathIncluded(fileEvent, tagName))
+                {
+                    Interlocked.Increment(ref GetFilterCounters(tagName).PathFiltered);
+                    rejected = new ProcessingResult(false, ErrorMessage: "Event filtered by path");
+                    continue;
+                }
+
+                // 暴走したプロセスがメモリや他タグの記録を圧迫しないよう、上限を超えた分は間引く
+                var tagMaxEventsPerSecond = _watchTargetManager.GetOptionsForTag(tagName)?.MaxEventsPerSecond ?? 0;
+                if (!_rateLimiter.ShouldKeep(tagName, rawEvent.EventName, rawEvent.Timestamp, tagMaxEventsPerSecond))
+                {
+                    Interlocked.Increment(ref GetFilterCounters(tagName).RateLimited);
+                    rejected = new ProcessingResult(false, ErrorMessage: "Event sampled out by rate limit");
+                    continue;
+                }
+
+                events.Add(tagEvent);
+            }
+
+            if (updateEvent != null)
+            {
+                events.AddRange(enabledTagNames.Select(tagName => WithTag(updateEvent, tagName)));
+            }
+
+            if (events.Count == 0)
+            {
+                return rejected!;
+            }
+
+            _logger.LogDebug("イベント処理完了 (Provider: {Provider}, Event: {Event}, ProcessId: {ProcessId}, Tags: {Tags})",
+                rawEvent.ProviderName, rawEvent.EventName, rawEvent.ProcessId, string.Join(", ", events.Select(e => e.TagName)));
+
+            return new ProcessingResult(true, events[0]) { Events = events };
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "イベント処理中にエラーが発生しました (Provider: {Provider}, Event: {Event}, ProcessId: {ProcessId})",
+                rawEvent.ProviderName, rawEvent.EventName, rawEvent.ProcessId);
+            return new ProcessingResult(false, ErrorMessage: $"Processing error: {ex.Message}");
+        }
+    }
+
+    /// <summary>
+    /// 変換したイベントをタグのイベントとして複製し、タグのメタデータを付与
+    /// </summary>
+    private BaseEventData WithTag(BaseEventData eventData, string tagName)
+    {
+        var metadata = _watchTargetManager.GetOptionsForTag(tagName)?.Metadata;
+        return eventData with
+        {
+            TagName = tagName,
+            TagMetadata = metadata?.Count > 0 ? metadata : null
+        };
+    }
+
+    /// <summary>
+    /// タグごとのフィルタにより記録しなかったイベント数を取得
+    /// </summary>
+    /// <returns>タグ名をキーとしたフィルタ統計</returns>
+    public IReadOnlyDictionary<string, TagFilterStatistics> GetFilterStatistics()
+    {
+        return _filterCounters.ToDictionary(
+            kvp => kvp.Key,
+            kvp => new TagFilterStatistics(
+                Interlocked.Read(ref kvp.Value.ExcludedProcess),
+                Interlocked.Read(ref kvp.Value.PathFiltered),
+                Interlocked.Read(ref kvp.Value.RateLimited),
+                Interlocked.Read(ref kvp.Value.OutsideRoot),
+                Interlocked.Read(ref kvp.Value.OutsideRootBytesWritten)));
+    }
+
+    /// <summary>
+    /// イベントタイプのフィルタリング（フィルタリング無効化）
+    /// </summary>
+    /// <param name="rawEvent">生ETWイベント</param>
+    /// <returns>処理すべき場合true</returns>
+    public bool ShouldProcessEvent(RawEventData rawEvent)
+    {
+        if (rawEvent == null)
+        {
+            return false;
+        }
+
+        // フィルタリングを無効化：すべてのイベントを処理
+        return true;
+    }
+
+    /// <summary>
+    /// プロセス開始・実行ファイル切り替えイベントの対象プロセスをディレクトリ監視・再監視待ちに照合し、実行ファイルパスを追跡
+    /// </summary>
+    /// <param name="rawEvent">生ETWイベント</param>
+    private async Task AddProcessByPathAsync(RawEventData rawEvent)
+    {
+        if (rawEvent.ProviderName != "Microsoft-Windows-Kernel-Process")
+        {
+            return;
+        }
+
+        switch (rawEvent.EventName)
+        {
+            case "Process/Start":
+                // 新しいプロセスのIDはペイロードに入る（実行ファイルパスは起動直後のプロセスから取得）
+                var childProcessInfo = ExtractChildProcessInfo(rawEvent.Payload);
+                if (childProcessInfo != null)
+                {
+                    await _watchTargetManager.AddProcessByPathAsync(childProcessInfo.Value.ChildProcessId, null);
+                    await _watchTargetManager.RewatchRestartedProcessAsync(childProcessInfo.Value.ChildProcessId, null);
+                }
+                break;
+
+            case "Process/Exec":
+                // Linux/macOSではfork後のexecで実行ファイルが確定する
+                var fileName = GetPayloadString(rawEvent.Payload, "FileName");
+                if (_watchTargetManager.IsWatchedProcess(rawEvent.ProcessId))
+                {
+                    // 除外ルールの判定に使う実行ファイルパスを更新
+                    if (!string.IsNullOrEmpty(fileName))
+                    {
+                        _watchTargetManager.UpdateExecutablePath(rawEvent.ProcessId, fileName);
+                    }
+                }
+                else
+                {
+                    await _watchTargetManager.AddProcessByPathAsync(rawEvent.ProcessId, string.IsNullOrEmpty(fileName) ? null : fileName);
+                    await _watchTargetManager.RewatchRestartedProcessAsync(rawEvent.ProcessId, string.IsNullOrEmpty(fileName) ? null : fileName);
+                }
+                break;
+        }
+    }
+
+    /// <summary>
+    /// 監視対象プロセスを対象にしたWindowsエラー報告（WerFault.exe）の起動を記録
+    /// </summary>
+    /// <remarks>
+    /// WerFault.exe はクラッシュしたプロセスの子プロセスとは限らないため、全てのプロセス開始イベントのコマンドラインから対象のPIDを取得する。
+    /// </remarks>
+    /// <param name="rawEvent">生ETWイベント</param>
+    private void TrackErrorReporting(RawEventData rawEvent)
+    {
+        if (rawEvent.ProviderName != "Microsoft-Windows-Kernel-Process" || rawEvent.EventName != "Process/Start")
+        {
+            return;
+        }
+
+        var imageName = ExtractChildProcessInfo(rawEvent.Payload)?.ChildProcessName;
+        if (imageName == null || !imageName[(imageName.LastIndexOfAny(new[] { '\\', '/' }) + 1)..].Equals("WerFault.exe", StringComparison.OrdinalIgnoreCase))
+        {
+            return;
+        }
+
+        var match = ErrorReportTargetPattern.Match(GetPayloadString(rawEvent.Payload, "CommandLine"));
+        if (match.Success && int.TryParse(match.Groups[1].Value, out var targetProcessId) && _watchTargetManager.IsWatchedProcess(targetProcessId))
+        {
+            _errorReportedProcesses.TryAdd(targetProcessId, 0);
+            _logger.LogDebug("監視対象プロセスのエラー報告を検出しました (ProcessId: {ProcessId})", targetProcessId);
+        }
+    }
+
+    /// <summary>
+    /// タグの監視オプションでイベントが有効かどうかを判定
+    /// </summary>
+    /// <param name="rawEvent">生ETWイベント</param>
+    /// <param name="tagName">タグ名</param>
+    /// <returns>記録すべき場合true</returns>
+    private bool IsEnabledForTag(RawEventData rawEvent, string tagName)
+    {
+        // ディレクトリ監視やポーリングを取得元とするタグは、ETWのファイルイベントを記録しない（それらのイベントはタグ指定で届く）
+        if (rawEvent.ProviderName == "Microsoft-Windows-Kernel-FileIO" && rawEvent.TagName == null &&
+            _watchTargetManager.GetOptionsForTag(tagName)?.Backend is FileEventBackend.DirectoryWatcher or FileEventBackend.Polling)
+        {
+            return false;
+        }
+
+        // スレッドイベントは高頻度のため、タグで明示的に有効化された場合のみ記録
+        if (rawEvent.ProviderName == "Microsoft-Windows-Kernel-Thread")
+        {
+            return _watchTargetManager.GetOptionsForTag(tagName)?.IncludeThreadEvents == true;
+        }
+
+        // ファイル読み取りは件数が多いため、タグのサンプリング間隔ごとに1件だけ記録
+        if (rawEvent.ProviderName == "Microsoft-Windows-Kernel-FileIO" && rawEvent.EventName == "FileIO/Read")
+        {
+            var sampleRate = _watchTargetManager.GetOptionsForTag(tagName)?.FileReadSampleRate ?? 0;
+            if (sampleRate <= 0)
+            {
+                return false;
+            }
+
+            var count = _fileReadCounters.AddOrUpdate(tagName, 1, (_, current) => current + 1);
+            return (count - 1) % sampleRate == 0;
+        }
+
+        return true;
+    }
+
+    /// <summary>
+    /// ファイルイベントがタグの記録するディレクトリの配下かどうかを判定
+    /// </summary>
+    /// <remarks>
+    /// パスフィルタと同様に、再解析ポイントを解決した場合は解決後と解決前のどちらのパスでも判定する。
+    /// パスが取得できなかったファイルイベント（一部のクローズなど）はディレクトリ外として扱う。
+    /// </remarks>
+    /// <param name="fileEvent">ファイルイベント</param>
+    /// <param name="tagName">タグ名</param>
+    /// <returns>記録すべき場合true</returns>
+    private bool IsUnderRecordRoots(FileEventData fileEvent, string tagName)
+    {
+        var recordRoots = _watchTargetManager.GetOptionsForTag(tagName)?.RecordRoots;
+        if (recordRoots == null || recordRoots.Count == 0)
+        {
+            return true;
+        }
+
+        return recordRoots.Any(root => PathPattern.IsUnderDirectory(root, fileEvent.FilePath) ||
+                                       (fileEvent.RawFilePath != null && PathPattern.IsUnderDirectory(root, fileEvent.RawFilePath)));
+    }
+
+    /// <summary>
+    /// ファイルイベントがタグのパスフィルタを通過するかどうかを判定
+    /// </summary>
+    /// <remarks>
+    /// 再解析ポイントを解決した場合は、解決後と解決前のどちらのパスで指定したフィルタにも一致させる。
+    /// </remarks>
+    /// <param name="fileEvent">ファイルイベント</param>
+    /// <param name="tagName">タグ名</param>
+    /// <returns>記録すべき場合true</returns>
+    private bool IsPathIncluded(FileEventData fileEvent, string tagName)
+    {
+        var options = _watchTargetManager.GetOptionsForTag(tagName);
+        if (options == null)
+        {
+            return true;
+        }
+
+        var filePaths = fileEvent.RawFilePath == null
+            ? new[] { fileEvent.FilePath }
+            : new[] { fileEvent.FilePath, fileEvent.RawFilePath };
+
+        if (options.IncludePaths.Count > 0 && !options.IncludePaths.Any(pattern => filePaths.Any(filePath => PathPattern.IsMatch(pattern, filePath))))
+        {
+            return false;
+        }
+
+        return !options.ExcludePaths.Any(pattern => filePaths.Any(filePath => PathPattern.IsMatch(pattern, filePath)));
+    }
+
+    /// <summary>
+    /// 生ETWイベントをドメインイベントに変換
+    /// </summary>
+    /// <param name="rawEvent">生ETWイベント</param>
+    /// <param name="tagName">タグ名</param>
+    /// <returns>変換されたイベントデータ</returns>
+    private async Task<BaseEventData?> ConvertToEventDataAsync(RawEventData rawEvent, string tagName)
+    {
+        try
+        {
+            var baseProperties = new
+            {
+                Timestamp = rawEvent.Timestamp,
+                TagName = tagName,
+                ProcessId = rawEvent.ProcessId,
+                ThreadId = rawEvent.ThreadId,
+                ProviderName = rawEvent.ProviderName,
+                EventName = rawEvent.EventName,
+                ActivityId = rawEvent.ActivityId,
+                RelatedActivityId = rawEvent.RelatedActivityId,
+                Payload = new Dictionary<string, object>(rawEvent.Payload)
+            };
+
+            // プロバイダーとイベント名に基づいて適切な型に変換
+            BaseEventData? eventData = rawEvent.ProviderName switch
+            {
+                "Microsoft-Windows-Kernel-FileIO" => await ConvertFileEventAsync(rawEvent, baseProperties),
+                "Microsoft-Windows-Kernel-Process" => await ConvertProcessEventAsync(rawEvent, baseProperties),
+                "Microsoft-Windows-Kernel-Registry" => await ConvertRegistryEventAsync(rawEvent, baseProperties),
+                "Microsoft-Windows-Kernel-Network" => await ConvertNetworkEventAsync(rawEvent, baseProperties),
+                "Microsoft-Windows-DNS-Client" => await ConvertDnsEventAsync(rawEvent, baseProperties),
+                "Microsoft-Windows-Kernel-Image" => await ConvertImageLoadEventAsync(rawEvent, baseProperties),
+                "Microsoft-Windows-Kernel-Thread" => await ConvertThreadEventAsync(rawEvent, baseProperties),
+                "Application Error" => ConvertCrashEvent(rawEvent, baseProperties),
+                "WinEvent" => ConvertForegroundEvent(rawEvent, baseProperties),
+                _ => new GenericEventData
+                {
+                    Timestamp = baseProperties.Timestamp,
+                    TagName = baseProperties.TagName,
+                    ProcessId = baseProperties.ProcessId,
+                    ThreadId = baseProperties.ThreadId,
+                    ProviderName = baseProperties.ProviderName,
+                    EventName = baseProperties.EventName,
+                    ActivityId = baseProperties.ActivityId,
+                    RelatedActivityId = baseProperties.RelatedActivityId,
+                    Payload = baseProperties.Payload
+                }
+            };
+
+            return eventData == null
+                ? null
+                : eventData with { MonotonicTimestamp = rawEvent.MonotonicTimestamp, IsDegraded = rawEvent.IsDegraded };
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "イベント変換中にエラーが発生しました (Provider: {Provider}, Event: {Event})",
+                rawEvent.ProviderName, rawEvent.EventName);
+            return null;
+        }
+    }
+
+    /// <summary>
+    /// ファイルイベントを変換
+    /// </summary>
+    /// <param name="rawEvent">生ETWイベント</param>
+    /// <param name="baseProperties">基本プロパティ</param>
+    /// <returns>ファイルイベントデータ</returns>
+    private async Task<FileEventData?> ConvertFileEventAsync(RawEventData rawEvent, dynamic baseProperties)
+    {
+        try
+        {
+            // ファイルパスの抽出
+            var filePath = ExtractFilePathFromPayload(rawEvent.Payload);
+            
+            // FileIO/Closeなどの一部のイベントではファイルパスが取得できない場合があるが、
+            // これは正常な動作なので処理を継続する
+            if (string.IsNullOrEmpty(filePath) && rawEvent.EventName != "FileIO/Close")
+            {
+                _logger.LogWarning("ファイルパスが見つかりません (Event: {Event}, ProcessId: {ProcessId})",
+                    rawEvent.EventName, rawEvent.ProcessId);
+                return null; // ファイルパスが必須のイベントでは処理失敗とする
+            }
+
+            var correlationId = _fileSessions.GetCorrelationId(rawEvent.ProcessId, rawEvent.EventName, rawEvent.Payload,
+                string.IsNullOrEmpty(filePath) ? null : filePath, out var closedWrittenFilePath);
+
+            if (string.IsNullOrEmpty(filePath))
+            {
+                // 書き込みのあったセッションであればオープン時のパスを使う
+                _logger.LogTrace("FileIO/Closeイベントでファイルパスが未取得 (ProcessId: {ProcessId}) - 正常動作", rawEvent.ProcessId);
+                filePath = closedWrittenFilePath ?? "Unknown"; // デフォルト値を設定
+            }
+
+            // ジャンクションなどで別の場所にリダイレクトされたパスは実際のパスで記録し、元のパスも残す
+            var resolvedPath = filePath == "Unknown" ? null : _reparsePointResolver?.ResolveFinalPath(filePath);
+
+            return new FileEventData
+            {
+                Timestamp = baseProperties.Timestamp,
+                TagName = baseProperties.TagName,
+                ProcessId = baseProperties.ProcessId,
+                ThreadId = baseProperties.ThreadId,
+                ProviderName = baseProperties.ProviderName,
+                EventName = baseProperties.EventName,
+                ActivityId = baseProperties.ActivityId,
+                RelatedActivityId = baseProperties.RelatedActivityId,
+                Payload = baseProperties.Payload,
+                FilePath = resolvedPath ?? filePath,
+                RawFilePath = resolvedPath == null ? null : filePath,
+                CorrelationId = correlationId,
+                ContentSha256 = closedWrittenFilePath == null
+                    ? null
+                    : await GetContentSha256Async(closedWrittenFilePath, (string)baseProperties.TagName)
+            };
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "ファイルイベント変換エラー (Event: {Event}, ProcessId: {ProcessId})",
+                rawEvent.EventName, rawEvent.ProcessId);
+            return null;
+        }
+    }
+
+    /// <summary>
+    /// レジストリイベントを変換
+    /// </summary>
+    /// <param name="rawEvent">生ETWイベント</param>
+    /// <param name="baseProperties">基本プロパティ</param>
+    /// <returns>レジストリイベントデータ</returns>
+    private async Task<RegistryEventData?> ConvertRegistryEventAsync(RawEventData rawEvent, dynamic baseProperties)
+    {
+        await Task.CompletedTask;
+
+        try
+        {
+            // KCBのランダウン前に開かれたキーは名前が解決できない場合がある
+            var keyName = GetPayloadString(rawEvent.Payload, "KeyName");
+            if (string.IsNullOrEmpty(keyName))
+            {
+                _logger.LogDebug("レジストリキー名が見つかりません (Event: {Event}, ProcessId: {ProcessId})",
+                    rawEvent.EventName, rawEvent.ProcessId);
+                return null;
+            }
+
+            // Registry/SetValue -> SetValue
+            var operation = rawEvent.EventName.StartsWith("Registry/")
+                ? rawEvent.EventName["Registry/".Length..]
+                : rawEvent.EventName;
+
+            return new RegistryEventData
+            {
+                Timestamp = baseProperties.Timestamp,
+                TagName = baseProperties.TagName,
+                ProcessId = baseProperties.ProcessId,
+                ThreadId = baseProperties.ThreadId,
+                ProviderName = baseProperties.ProviderName,
+                EventName = baseProperties.EventName,
+                ActivityId = baseProperties.ActivityId,
+                RelatedActivityId = baseProperties.RelatedActivityId,
+                Payload = baseProperties.Payload,
+                KeyName = keyName,
+                ValueName = GetPayloadString(rawEvent.Payload, "ValueName"),
+                Operation = operation
+            };
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "レジストリイベント変換エラー (Event: {Event}, ProcessId: {ProcessId})",
+                rawEvent.EventName, rawEvent.ProcessId);
+            return null;
+        }
+    }
+
+    /// <summary>
+    /// ネットワークイベントを変換
+    /// </summary>
+    /// <param name="rawEvent">生ETWイベント</param>
+    /// <param name="baseProperties">基本プロパティ</param>
+    /// <returns>ネットワークイベントデータ</returns>
+    private async Task<NetworkEventData?> ConvertNetworkEventAsync(RawEventData rawEvent, dynamic baseProperties)
+    {
+        await Task.CompletedTask;
 
-            return new ProcessingResult(true, eventData);
+        try
+        {
+            // カーネルのTCP/IPイベントはローカル視点で、saddrがローカル、daddrがリモート
+            var remoteAddress = GetPayloadString(rawEvent.Payload, "daddr");
+            if (string.IsNullOrEmpty(remoteAddress))
+            {
+                _logger.LogDebug("リモートアドレスが見つかりません (Event: {Event}, ProcessId: {ProcessId})",
+                    rawEvent.EventName, rawEvent.ProcessId);
+                return null;
+            }
+
+            // TcpIp/Recv -> (TCP, Receive)
+            var separator = rawEvent.EventName.IndexOf('/');
+            var protocol = rawEvent.EventName.StartsWith("UdpIp") ? "UDP" : "TCP";
+            var operation = separator >= 0 ? rawEvent.EventName[(separator + 1)..] : rawEvent.EventName;
+            if (operation == "Recv")
+            {
+                operation = "Receive";
+            }
+
+            return new NetworkEventData
+            {
+                Timestamp = baseProperties.Timestamp,
+                TagName = baseProperties.TagName,
+                ProcessId = baseProperties.ProcessId,
+                ThreadId = baseProperties.ThreadId,
+                ProviderName = baseProperties.ProviderName,
+                EventName = baseProperties.EventName,
+                ActivityId = baseProperties.ActivityId,
+                RelatedActivityId = baseProperties.RelatedActivityId,
+                Payload = baseProperties.Payload,
+                Protocol = protocol,
+                Operation = operation,
+                LocalAddress = GetPayloadString(rawEvent.Payload, "saddr"),
+                LocalPort = (int)GetPayloadLong(rawEvent.Payload, "sport"),
+                RemoteAddress = remoteAddress,
+                RemotePort = (int)GetPayloadLong(rawEvent.Payload, "dport"),
+                Bytes = GetPayloadLong(rawEvent.Payload, "size"),
+                RemoteHostName = _dnsCache.TryGetHostName(remoteAddress)
+            };
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "ネットワークイベント変換エラー (Event: {Event}, ProcessId: {ProcessId})",
+                rawEvent.EventName, rawEvent.ProcessId);
+            return null;
+        }
+    }
+
+    /// <summary>
+    /// イメージロードイベントを変換
+    /// </summary>
+    /// <param name="rawEvent">生ETWイベント</param>
+    /// <param name="baseProperties">基本プロパティ</param>
+    /// <returns>イメージロードイベントデータ</returns>
+    private async Task<ImageLoadEventData?> ConvertImageLoadEventAsync(RawEventData rawEvent, dynamic baseProperties)
+    {
+        await Task.CompletedTask;
+
+        try
+        {
+            var imagePath = GetPayloadString(rawEvent.Payload, "FileName");
+            if (string.IsNullOrEmpty(imagePath))
+            {
+                _logger.LogDebug("イメージパスが見つかりません (Event: {Event}, ProcessId: {ProcessId})",
+                    rawEvent.EventName, rawEvent.ProcessId);
+                return null;
+            }
+
+            // Image/Load -> Load
+            var operation = rawEvent.EventName.StartsWith("Image/")
+                ? rawEvent.EventName["Image/".Length..]
+                : rawEvent.EventName;
+
+            return new ImageLoadEventData
+            {
+                Timestamp = baseProperties.Timestamp,
+                TagName = baseProperties.TagName,
+                ProcessId = baseProperties.ProcessId,
+                ThreadId = baseProperties.ThreadId,
+                ProviderName = baseProperties.ProviderName,
+                EventName = baseProperties.EventName,
+                ActivityId = baseProperties.ActivityId,
+                RelatedActivityId = baseProperties.RelatedActivityId,
+                Payload = baseProperties.Payload,
+                Operation = operation,
+                ImagePath = imagePath,
+                BaseAddress = GetPayloadULong(rawEvent.Payload, "ImageBase"),
+                ImageSize = GetPayloadLong(rawEvent.Payload, "ImageSize"),
+                IsSigned = ToIsSigned(rawEvent.Payload)
+            };
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "イメージロードイベント変換エラー (Event: {Event}, ProcessId: {ProcessId})",
+                rawEvent.EventName, rawEvent.ProcessId);
+            return null;
+        }
+    }
+
+    /// <summary>
+    /// スレッドイベントを変換
+    /// </summary>
+    /// <param name="rawEvent">生ETWイベント</param>
+    /// <param name="baseProperties">基本プロパティ</param>
+    /// <returns>スレッドイベントデータ</returns>
+    private async Task<ThreadEventData?> ConvertThreadEventAsync(RawEventData rawEvent, dynamic baseProperties)
+    {
+        await Task.CompletedTask;
+
+        try
+        {
+            // Thread/Start -> Start
+            var operation = rawEvent.EventName.StartsWith("Thread/")
+                ? rawEvent.EventName["Thread/".Length..]
+                : rawEvent.EventName;
+
+            return new ThreadEventData
+            {
+                Timestamp = baseProperties.Timestamp,
+                TagName = baseProperties.TagName,
+                ProcessId = baseProperties.ProcessId,
+                ThreadId = baseProperties.ThreadId,
+                ProviderName = baseProperties.ProviderName,
+                EventName = baseProperties.EventName,
+                ActivityId = baseProperties.ActivityId,
+                RelatedActivityId = baseProperties.RelatedActivityId,
+                Payload = baseProperties.Payload,
+                Operation = operation,
+                StartAddress = GetPayloadULong(rawEvent.Payload, "Win32StartAddr")
+            };
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "スレッドイベント変換エラー (Event: {Event}, ProcessId: {ProcessId})",
+                rawEvent.EventName, rawEvent.ProcessId);
+            return null;
+        }
+    }
+
+    /// <summary>
+    /// フォアグラウンドウィンドウの変更イベントを変換
+    /// </summary>
+    /// <param name="rawEvent">生ETWイベント</param>
+    /// <param name="baseProperties">基本プロパティ</param>
+    /// <returns>フォアグラウンドイベントデータ</returns>
+    private ForegroundEventData? ConvertForegroundEvent(RawEventData rawEvent, dynamic baseProperties)
+    {
+        try
+        {
+            var isForeground = rawEvent.EventName == "Focus/Gained";
+
+            return new ForegroundEventData
+            {
+                Timestamp = baseProperties.Timestamp,
+                TagName = baseProperties.TagName,
+                ProcessId = baseProperties.ProcessId,
+                ThreadId = baseProperties.ThreadId,
+                ProviderName = baseProperties.ProviderName,
+                EventName = baseProperties.EventName,
+                ActivityId = baseProperties.ActivityId,
+                RelatedActivityId = baseProperties.RelatedActivityId,
+                Payload = baseProperties.Payload,
+                IsForeground = isForeground,
+                WindowTitle = isForeground ? GetPayloadString(rawEvent.Payload, "WindowTitle") : null,
+                ForegroundDurationMs = isForeground ? null : GetPayloadLong(rawEvent.Payload, "ForegroundDurationMs")
+            };
         }
         catch (Exception ex)
         {
-            _logger.LogError(ex, "イベント処理中にエラーが発生しました (Provider: {Provider}, Event: {Event}, ProcessId: {ProcessId})",
-                rawEvent.ProviderName, rawEvent.EventName, rawEvent.ProcessId);
-            return new ProcessingResult(false, ErrorMessage: $"Processing error: {ex.Message}");
+            _logger.LogError(ex, "フォアグラウンドイベント変換エラー (Event: {Event}, ProcessId: {ProcessId})",
+                rawEvent.EventName, rawEvent.ProcessId);
+            return null;
         }
     }
 
     /// <summary>
-    /// イベントタイプのフィルタリング（フィルタリング無効化）
+    /// Windowsエラー報告のクラッシュイベントを変換
     /// </summary>
+    /// <remarks>
+    /// ダンプは記録後に書き込まれるため、クラッシュイベントはダンプのパスなしで返し、
+    /// ダンプが見つかった場合は変換を止めないよう別に Crash/DumpWritten を配信する。
+    /// </remarks>
     /// <param name="rawEvent">生ETWイベント</param>
-    /// <returns>処理すべき場合true</returns>
-    public bool ShouldProcessEvent(RawEventData rawEvent)
+    /// <param name="baseProperties">基本プロパティ</param>
+    /// <returns>クラッシュイベントデータ</returns>
+    private CrashDetectedEventData? ConvertCrashEvent(RawEventData rawEvent, dynamic baseProperties)
     {
-        if (rawEvent == null)
+        try
         {
-            return false;
-        }
+            // プロセス終了イベントの終了種別をクラッシュにする
+            _errorReportedProcesses.TryAdd(rawEvent.ProcessId, 0);
 
-        // フィルタリングを無効化：すべてのイベントを処理
-        return true;
+            CrashDetectedEventData crashEvent = new CrashDetectedEventData
+            {
+                Timestamp = baseProperties.Timestamp,
+                TagName = baseProperties.TagName,
+                ProcessId = baseProperties.ProcessId,
+                ThreadId = baseProperties.ThreadId,
+                ProviderName = baseProperties.ProviderName,
+                EventName = baseProperties.EventName,
+                ActivityId = baseProperties.ActivityId,
+                RelatedActivityId = baseProperties.RelatedActivityId,
+                Payload = baseProperties.Payload,
+                ApplicationName = GetPayloadString(rawEvent.Payload, "ApplicationName"),
+                ApplicationPath = NullIfEmpty(GetPayloadString(rawEvent.Payload, "ApplicationPath")),
+                FaultingModule = GetPayloadString(rawEvent.Payload, "FaultingModuleName"),
+                FaultingModulePath = NullIfEmpty(GetPayloadString(rawEvent.Payload, "FaultingModulePath")),
+                ExceptionCode = (uint)GetPayloadULong(rawEvent.Payload, "ExceptionCode"),
+                FaultOffset = rawEvent.Payload.ContainsKey("FaultOffset") ? GetPayloadULong(rawEvent.Payload, "FaultOffset") : null,
+                ReportId = NullIfEmpty(GetPayloadString(rawEvent.Payload, "ReportId"))
+            };
+
+            if (_crashDumpLocator != null)
+            {
+                _ = Task.Run(() => ReportDumpWrittenAsync(_crashDumpLocator, crashEvent));
+            }
+            return crashEvent;
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "クラッシュイベント変換エラー (Event: {Event}, ProcessId: {ProcessId})",
+                rawEvent.EventName, rawEvent.ProcessId);
+            return null;
+        }
     }
 
     /// <summary>
-    /// 生ETWイベントをドメインイベントに変換
+    /// クラッシュダンプの書き込みを待って検索し、見つかった場合はダンプのパスを含むイベントを配信
     /// </summary>
-    /// <param name="rawEvent">生ETWイベント</param>
-    /// <param name="tagName">タグ名</param>
-    /// <returns>変換されたイベントデータ</returns>
-    private async Task<BaseEventData?> ConvertToEventDataAsync(RawEventData rawEvent, string tagName)
+    /// <param name="crashDumpLocator">クラッシュダンプの検索</param>
+    /// <param name="crashEvent">クラッシュイベントデータ</param>
+    private async Task ReportDumpWrittenAsync(ICrashDumpLocator crashDumpLocator, CrashDetectedEventData crashEvent)
     {
         try
         {
-            var baseProperties = new
+            var dumpPath = await crashDumpLocator.FindDumpPathAsync(crashEvent.ApplicationName, crashEvent.ProcessId);
+            if (dumpPath == null)
             {
-                Timestamp = rawEvent.Timestamp,
-                TagName = tagName,
-                ProcessId = rawEvent.ProcessId,
-                ThreadId = rawEvent.ThreadId,
-                ProviderName = rawEvent.ProviderName,
-                EventName = rawEvent.EventName,
-                ActivityId = rawEvent.ActivityId,
-                RelatedActivityId = rawEvent.RelatedActivityId,
-                Payload = new Dictionary<string, object>(rawEvent.Payload)
-            };
+                return;
+            }
 
-            // プロバイダーとイベント名に基づいて適切な型に変換
-            return rawEvent.ProviderName switch
+            FollowUpEventCreated?.Invoke(this, crashEvent with
             {
-                "Microsoft-Windows-Kernel-FileIO" => await ConvertFileEventAsync(rawEvent, baseProperties),
-                "Microsoft-Windows-Kernel-Process" => await ConvertProcessEventAsync(rawEvent, baseProperties),
-                _ => new GenericEventData
-                {
-                    Timestamp = baseProperties.Timestamp,
-                    TagName = baseProperties.TagName,
-                    ProcessId = baseProperties.ProcessId,
-                    ThreadId = baseProperties.ThreadId,
-                    ProviderName = baseProperties.ProviderName,
-                    EventName = baseProperties.EventName,
-                    ActivityId = baseProperties.ActivityId,
-                    RelatedActivityId = baseProperties.RelatedActivityId,
-                    Payload = baseProperties.Payload
-                }
-            };
+                Timestamp = DateTime.UtcNow,
+                EventName = CrashDumpWrittenEventName,
+                Payload = new Dictionary<string, object>(crashEvent.Payload),
+                DumpPath = dumpPath
+            });
         }
         catch (Exception ex)
         {
-            _logger.LogError(ex, "イベント変換中にエラーが発生しました (Provider: {Provider}, Event: {Event})",
-                rawEvent.ProviderName, rawEvent.EventName);
+            _logger.LogError(ex, "クラッシュダンプの検索中にエラーが発生しました (ProcessId: {ProcessId}, Application: {ApplicationName})",
+                crashEvent.ProcessId, crashEvent.ApplicationName);
+        }
+    }
+
+    private static string? NullIfEmpty(string value) => value.Length == 0 ? null : value;
+
+    /// <summary>
+    /// SignatureLevelから署名の有無を判定
+    /// </summary>
+    /// <remarks>
+    /// SE_SIGNING_LEVEL: 0 = 未検証, 1 = 未署名, 2以上 = 何らかの署名あり
+    /// </remarks>
+    private static bool? ToIsSigned(IReadOnlyDictionary<string, object> payload)
+    {
+        if (!payload.ContainsKey("SignatureLevel"))
+        {
             return null;
         }
+
+        return GetPayloadLong(payload, "SignatureLevel") switch
+        {
+            0 => null,
+            1 => false,
+            _ => true
+        };
     }
 
     /// <summary>
-    /// ファイルイベントを変換
+    /// DNSクエリイベントを変換し、解決結果を後続のネットワークイベント用に記録
     /// </summary>
     /// <param name="rawEvent">生ETWイベント</param>
     /// <param name="baseProperties">基本プロパティ</param>
-    /// <returns>ファイルイベントデータ</returns>
-    private async Task<FileEventData?> ConvertFileEventAsync(RawEventData rawEvent, dynamic baseProperties)
+    /// <returns>DNSクエリイベントデータ</returns>
+    private async Task<DnsQueryEventData?> ConvertDnsEventAsync(RawEventData rawEvent, dynamic baseProperties)
     {
-        await Task.CompletedTask; // 非同期処理の準備
+        await Task.CompletedTask;
 
         try
         {
-            // ファイルパスの抽出
-            var filePath = ExtractFilePathFromPayload(rawEvent.Payload);
-            
-            // FileIO/Closeなどの一部のイベントではファイルパスが取得できない場合があるが、
-            // これは正常な動作なので処理を継続する
-            if (string.IsNullOrEmpty(filePath))
+            var queryName = GetPayloadString(rawEvent.Payload, "QueryName");
+            if (string.IsNullOrEmpty(queryName))
             {
-                if (rawEvent.EventName == "FileIO/Close")
-                {
-                    _logger.LogTrace("FileIO/Closeイベントでファイルパスが未取得 (ProcessId: {ProcessId}) - 正常動作", rawEvent.ProcessId);
-                    filePath = "Unknown"; // デフォルト値を設定
-                }
-                else
-                {
-                    _logger.LogWarning("ファイルパスが見つかりません (Event: {Event}, ProcessId: {ProcessId})",
-                        rawEvent.EventName, rawEvent.ProcessId);
-                    return null; // ファイルパスが必須のイベントでは処理失敗とする
-                }
+                _logger.LogDebug("DNSクエリ名が見つかりません (Event: {Event}, ProcessId: {ProcessId})",
+                    rawEvent.EventName, rawEvent.ProcessId);
+                return null;
             }
 
-            return new FileEventData
+            var status = (int)GetPayloadLong(rawEvent.Payload, "QueryStatus");
+            var addresses = DnsResolutionCache.ParseQueryResults(GetPayloadString(rawEvent.Payload, "QueryResults"));
+            if (status == 0)
+            {
+                _dnsCache.Record(queryName, addresses);
+            }
+
+            return new DnsQueryEventData
             {
                 Timestamp = baseProperties.Timestamp,
                 TagName = baseProperties.TagName,
@@ -210,12 +972,15 @@ public class EventProcessor : IEventProcessor
                 ActivityId = baseProperties.ActivityId,
                 RelatedActivityId = baseProperties.RelatedActivityId,
                 Payload = baseProperties.Payload,
-                FilePath = filePath
+                QueryName = queryName,
+                QueryType = (int)GetPayloadLong(rawEvent.Payload, "QueryType"),
+                Status = status,
+                Addresses = addresses
             };
         }
         catch (Exception ex)
         {
-            _logger.LogError(ex, "ファイルイベント変換エラー (Event: {Event}, ProcessId: {ProcessId})",
+            _logger.LogError(ex, "DNSクエリイベント変換エラー (Event: {Event}, ProcessId: {ProcessId})",
                 rawEvent.EventName, rawEvent.ProcessId);
             return null;
         }
@@ -265,8 +1030,6 @@ public class EventProcessor : IEventProcessor
     /// <returns>プロセス開始イベントデータ</returns>
     private async Task<ProcessStartEventData?> ConvertProcessStartEventAsync(RawEventData rawEvent, dynamic baseProperties)
     {
-        await Task.CompletedTask;
-
         try
         {
             // 子プロセス情報の抽出
@@ -280,6 +1043,9 @@ public class EventProcessor : IEventProcessor
 
             var (childProcessId, childProcessName) = childProcessInfo.Value;
 
+            // 昇格したアップデーターや別ユーザーによる起動を区別するため、子プロセスのトークン情報を記録
+            var token = ResolveProcessToken(childProcessId, rawEvent.Payload);
+
             // 子プロセスを監視対象に自動追加
             if (childProcessId != rawEvent.ProcessId)
             {
@@ -309,7 +1075,15 @@ public class EventProcessor : IEventProcessor
                 RelatedActivityId = baseProperties.RelatedActivityId,
                 Payload = baseProperties.Payload,
                 ChildProcessId = childProcessId,
-                ChildProcessName = childProcessName
+                ChildProcessName = childProcessName,
+                ParentProcessId = ExtractParentProcessId(rawEvent),
+                CommandLine = GetPayloadString(rawEvent.Payload, "CommandLine"),
+                CurrentDirectory = GetPayloadString(rawEvent.Payload, "CurrentDirectory"),
+                Environment = CaptureEnvironment(childProcessId, (string)baseProperties.TagName),
+                ImageSha256 = await GetImageSha256Async(childProcessId),
+                UserSid = token.UserSid,
+                SessionId = token.SessionId,
+                IntegrityLevel = token.IntegrityLevel
             };
         }
         catch (Exception ex)
@@ -334,6 +1108,14 @@ public class EventProcessor : IEventProcessor
         {
             // 終了コードの抽出
             var exitCode = ExtractExitCodeFromPayload(rawEvent.Payload);
+            var errorReported = _errorReportedProcesses.TryRemove(rawEvent.ProcessId, out _);
+            var exitReason = ClassifyExit(exitCode, ExtractTerminationSignal(rawEvent.Payload), errorReported);
+
+            // 終了済みのためトークンは読めないことが多く、主にペイロードの値を使う
+            var token = ResolveProcessToken(rawEvent.ProcessId, rawEvent.Payload);
+
+            // 終了したプロセスのハンドルは再利用されるため、未クローズのファイルセッションを破棄
+            _fileSessions.RemoveProcess(rawEvent.ProcessId);
 
             // プロセス終了時に監視対象から除去
             _ = Task.Run(() =>
@@ -359,7 +1141,11 @@ public class EventProcessor : IEventProcessor
                 ActivityId = baseProperties.ActivityId,
                 RelatedActivityId = baseProperties.RelatedActivityId,
                 Payload = baseProperties.Payload,
-                ExitCode = exitCode
+                ExitCode = exitCode,
+                ExitReason = exitReason,
+                UserSid = token.UserSid,
+                SessionId = token.SessionId,
+                IntegrityLevel = token.IntegrityLevel
             };
         }
         catch (Exception ex)
@@ -391,6 +1177,224 @@ public class EventProcessor : IEventProcessor
         return string.Empty;
     }
 
+    /// <summary>
+    /// ペイロードから文字列値を取得
+    /// </summary>
+    /// <param name="payload">ペイロード</param>
+    /// <param name="key">キー</param>
+    /// <returns>値（存在しない場合は空文字）</returns>
+    private static string GetPayloadString(IReadOnlyDictionary<string, object> payload, string key)
+    {
+        return payload.TryGetValue(key, out var value) && value is string text ? text : string.Empty;
+    }
+
+    /// <summary>
+    /// ペイロードから数値を取得
+    /// </summary>
+    /// <param name="payload">ペイロード</param>
+    /// <param name="key">キー</param>
+    /// <returns>値（存在しないか数値でない場合は0）</returns>
+    private static long GetPayloadLong(IReadOnlyDictionary<string, object> payload, string key)
+    {
+        return payload.TryGetValue(key, out var value) && long.TryParse(value?.ToString(), out var number) ? number : 0;
+    }
+
+    /// <summary>
+    /// ペイロードから符号なし数値（アドレスなど）を取得
+    /// </summary>
+    /// <param name="payload">ペイロード</param>
+    /// <param name="key">キー</param>
+    /// <returns>値（存在しないか数値でない場合は0）</returns>
+    private static ulong GetPayloadULong(IReadOnlyDictionary<string, object> payload, string key)
+    {
+        return payload.TryGetValue(key, out var value) && ulong.TryParse(value?.ToString(), out var number) ? number : 0;
+    }
+
+    /// <summary>
+    /// タグで許可された環境変数を子プロセスから読み取る
+    /// </summary>
+    /// <param name="childProcessId">子プロセスID</param>
+    /// <param name="tagName">タグ名</param>
+    /// <returns>環境変数（タグで指定がない場合はnull）</returns>
+    private IReadOnlyDictionary<string, string>? CaptureEnvironment(int childProcessId, string tagName)
+    {
+        var names = _watchTargetManager.GetOptionsForTag(tagName)?.EnvironmentVariables;
+        if (_environmentReader == null || names == null || names.Count == 0)
+        {
+            return null;
+        }
+
+        // 短命なプロセスは読み取り前に終了している場合があるため、失敗時は空の結果となる
+        return _environmentReader.ReadEnvironment(childProcessId, names);
+    }
+
+    /// <summary>
+    /// プロセスのユーザーSID・セッションID・整合性レベルを取得
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <param name="payload">プロセスイベントのペイロード</param>
+    /// <returns>トークン情報（取得できない項目はnull）</returns>
+    private ProcessTokenInfo ResolveProcessToken(int processId, IReadOnlyDictionary<string, object> payload)
+    {
+        // ETWのプロセスイベントはUserSIDとSessionIDを含むため、終了済みの短命なプロセスでも取得できる
+        var payloadUserSid = GetPayloadString(payload, "UserSID");
+        int? payloadSessionId = payload.ContainsKey("SessionID") ? (int)GetPayloadLong(payload, "SessionID") : null;
+
+        // 整合性レベルはペイロードにないため、トークンから読み取る
+        ProcessTokenInfo? token = null;
+        try
+        {
+            token = _tokenReader?.ReadToken(processId);
+        }
+        catch (Exception ex)
+        {
+            _logger.LogDebug(ex, "トークン情報を取得できませんでした (ProcessId: {ProcessId})", processId);
+        }
+
+        return new ProcessTokenInfo(
+            string.IsNullOrEmpty(payloadUserSid) ? token?.UserSid : payloadUserSid,
+            payloadSessionId ?? token?.SessionId,
+            token?.IntegrityLevel);
+    }
+
+    /// <summary>
+    /// 書き込みを終えたファイルの内容のSHA-256を取得（タグのHashOnClosePathsに一致する場合のみ）
+    /// </summary>
+    /// <param name="filePath">ファイルパス</param>
+    /// <param name="tagName">タグ名</param>
+    /// <returns>ハッシュ（対象外または取得できない場合はnull）</returns>
+    private async Task<string?> GetContentSha256Async(string filePath, string tagName)
+    {
+        var patterns = _watchTargetManager.GetOptionsForTag(tagName)?.HashOnClosePaths;
+        if (_fileContentHasher == null || patterns == null || !patterns.Any(pattern => PathPattern.IsMatch(pattern, filePath)))
+        {
+            return null;
+        }
+
+        try
+        {
+            // クローズ後に削除・リネームされたファイルは読み取れないためnullとなる
+            return await _fileContentHasher.ComputeSha256Async(filePath);
+        }
+        catch (Exception ex)
+        {
+            _logger.LogDebug(ex, "ファイル内容のハッシュを取得できませんでした (Path: {Path})", filePath);
+            return null;
+        }
+    }
+
+    /// <summary>
+    /// インストーラー・アップデーターの起動や、Program Files配下の実行ファイルの更新を検出
+    /// </summary>
+    /// <param name="eventData">変換したイベント</param>
+    /// <returns>更新検出イベント（検出しなかった場合はnull）</returns>
+    private async Task<UpdateDetectedEventData?> DetectUpdateAsync(BaseEventData eventData)
+    {
+        switch (eventData)
+        {
+            case ProcessStartEventData processStart when IsInstaller(processStart.ChildProcessName):
+                return CreateUpdateEvent(eventData, UpdateTrigger.InstallerStarted, processStart.ChildProcessName,
+                    processStart.ImageSha256, processStart.ChildProcessId);
+
+            case FileEventData { EventName: "FileIO/Write" } write when ProgramFilesExecutablePattern.IsMatch(write.FilePath):
+                // 書き込み中の内容ではなく、クローズ時点の実行ファイルのハッシュを記録する
+                _writtenExecutables.TryAdd(write.FilePath, 0);
+                return null;
+
+            case FileEventData { EventName: "FileIO/Close" } close when _writtenExecutables.TryRemove(close.FilePath, out _):
+                var sha256 = close.ContentSha256;
+                if (sha256 == null && _fileContentHasher != null)
+                {
+                    try
+                    {
+                        sha256 = await _fileContentHasher.ComputeSha256Async(close.FilePath);
+                    }
+                    catch (Exception ex)
+                    {
+                        _logger.LogDebug(ex, "更新された実行ファイルのハッシュを取得できませんでした (Path: {Path})", close.FilePath);
+                    }
+                }
+                return CreateUpdateEvent(eventData, UpdateTrigger.ExecutableWritten, close.FilePath, sha256, null);
+
+            default:
+                return null;
+        }
+    }
+
+    /// <summary>
+    /// 子プロセスがインストーラー・アップデーターかどうか（パスで記録されている場合はファイル名で判定）
+    /// </summary>
+    private static bool IsInstaller(string childProcessName)
+    {
+        var fileName = childProcessName[(childProcessName.LastIndexOfAny(new[] { '\\', '/' }) + 1)..].ToLowerInvariant();
+        return fileName.Length > 0 && InstallerPatterns.Any(pattern => PathPattern.IsMatch(pattern, fileName));
+    }
+
+    private static UpdateDetectedEventData CreateUpdateEvent(BaseEventData source, UpdateTrigger trigger, string executablePath, string? executableSha256, int? installerProcessId)
+    {
+        return new UpdateDetectedEventData
+        {
+            Timestamp = source.Timestamp,
+            MonotonicTimestamp = source.MonotonicTimestamp,
+            IsDegraded = source.IsDegraded,
+            TagName = source.TagName,
+            ProcessId = source.ProcessId,
+            ThreadId = source.ThreadId,
+            ProviderName = UpdateProviderName,
+            EventName = UpdateDetectedEventName,
+            ActivityId = Guid.Empty,
+            RelatedActivityId = Guid.Empty,
+            Payload = new Dictionary<string, object>(),
+            Trigger = trigger,
+            ExecutablePath = executablePath,
+            ExecutableSha256 = executableSha256,
+            InstallerProcessId = installerProcessId
+        };
+    }
+
+    /// <summary>
+    /// 子プロセスの実行ファイルのSHA-256を取得
+    /// </summary>
+    /// <param name="childProcessId">子プロセスID</param>
+    /// <returns>ハッシュ（取得できない場合はnull）</returns>
+    private async Task<string?> GetImageSha256Async(int childProcessId)
+    {
+        if (_imageHashProvider == null)
+        {
+            return null;
+        }
+
+        try
+        {
+            // 同じ実行ファイルはキャッシュされるため、初回以外はファイルを読まない
+            return await _imageHashProvider.GetImageSha256Async(childProcessId);
+        }
+        catch (Exception ex)
+        {
+            _logger.LogDebug(ex, "実行ファイルのハッシュを取得できませんでした (ProcessId: {ProcessId})", childProcessId);
+            return null;
+        }
+    }
+
+    /// <summary>
+    /// 親プロセスIDを抽出（ETWはParentID、eBPF・Endpoint SecurityはParentId）
+    /// </summary>
+    /// <param name="rawEvent">生イベント</param>
+    /// <returns>親プロセスID（ペイロードにない場合はイベント発生元のプロセスID）</returns>
+    private static int ExtractParentProcessId(RawEventData rawEvent)
+    {
+        foreach (var key in new[] { "ParentID", "ParentId" })
+        {
+            var parentId = GetPayloadLong(rawEvent.Payload, key);
+            if (parentId > 0)
+            {
+                return (int)parentId;
+            }
+        }
+
+        return rawEvent.ProcessId;
+    }
+
     /// <summary>
     /// ペイロードから子プロセス情報を抽出
     /// </summary>
@@ -439,12 +1443,81 @@ public class EventProcessor : IEventProcessor
 
         foreach (var key in exitCodeKeys)
         {
-            if (payload.TryGetValue(key, out var value) && int.TryParse(value.ToString(), out var exitCode))
+            if (!payload.TryGetValue(key, out var value))
+            {
+                continue;
+            }
+
+            if (int.TryParse(value.ToString(), out var exitCode))
             {
                 return exitCode;
             }
+
+            // ETWの終了コードはUInt32のため、0x80000000以上のNTSTATUS（0xC0000005など）はビットパターンを保って変換
+            if (uint.TryParse(value.ToString(), out var unsignedExitCode))
+            {
+                return unchecked((int)unsignedExitCode);
+            }
         }
 
         return 0; // デフォルトは正常終了
     }
-}
\ No newline at end of file
+
+    /// <summary>
+    /// ペイロードから終了させたシグナル番号を抽出（Linux/macOS）
+    /// </summary>
+    /// <param name="payload">ペイロード</param>
+    /// <returns>シグナル番号（シグナルによる終了でない場合はnull）</returns>
+    private static int? ExtractTerminationSignal(IReadOnlyDictionary<string, object> payload)
+    {
+        return payload.TryGetValue("TerminationSignal", out var value) && int.TryParse(value.ToString(), out var signal) && signal > 0
+            ? signal
+            : null;
+    }
+
+    /// <summary>
+    /// 終了コード・シグナル・エラー報告の有無から終了の種別を判定
+    /// </summary>
+    /// <param name="exitCode">終了コード</param>
+    /// <param name="terminationSignal">終了させたシグナル番号（Linux/macOS）</param>
+    /// <param name="errorReported">Windowsエラー報告が起動されたかどうか</param>
+    /// <returns>終了の種別</returns>
+    private static ProcessExitReason ClassifyExit(int exitCode, int? terminationSignal, bool errorReported)
+    {
+        if (errorReported)
+        {
+            return ProcessExitReason.Crashed;
+        }
+
+        if (terminationSignal.HasValue)
+        {
+            // SIGILL, SIGTRAP, SIGABRT, SIGBUS, SIGFPE, SIGSEGV, SIGSYS はクラッシュ、それ以外（SIGKILL, SIGTERMなど）は強制終了
+            return terminationSignal.Value is 4 or 5 or 6 or 7 or 8 or 11 or 31
+                ? ProcessExitReason.Crashed
+                : ProcessExitReason.Killed;
+        }
+
+        return unchecked((uint)exitCode) switch
+        {
+            0xC000013A => ProcessExitReason.Killed, // STATUS_CONTROL_C_EXIT
+            0x40010004 => ProcessExitReason.Killed, // DBG_TERMINATE_PROCESS
+            0x40000015 => ProcessExitReason.Crashed, // STATUS_FATAL_APP_EXIT（abort）
+            >= 0xC0000000 => ProcessExitReason.Crashed, // エラーのNTSTATUS（アクセス違反、スタックオーバーフロー、fail fastなど）
+            _ => ProcessExitReason.Normal
+        };
+    }
+
+    private FilterCounters GetFilterCounters(string tagName) => _filterCounters.GetOrAdd(tagName, _ => new FilterCounters());
+
+    /// <summary>
+    /// タグごとのフィルタ件数（Interlockedで更新する）
+    /// </summary>
+    private sealed class FilterCounters
+    {
+        public long ExcludedProcess;
+        public long PathFiltered;
+        public long RateLimited;
+        public long OutsideRoot;
+        public long OutsideRootBytesWritten;
+    }
+}
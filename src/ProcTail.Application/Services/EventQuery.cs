@@ -0,0 +1,537 @@
+using System.Collections.Concurrent;
+using System.Globalization;
+using System.Reflection;
+using System.Text;
+using System.Text.RegularExpressions;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Services;
+
+/// <summary>
+/// イベントのクエリ式
+/// </summary>
+/// <remarks>
+/// 「項目 演算子 値」の比較を AND・OR・NOT と括弧で組み合わせる（例: type=file.write AND path~"*.sav" AND ts>now-1h）。
+/// 項目は type（$typeまたは "file.write" のように$typeとイベント名の操作）、name（イベント名。"FileIO/*" でカテゴリ指定）、
+/// path（ファイル・レジストリ・モジュールロードのパス）、pid、tid、ts（日時）、tag、provider のほか、
+/// イベントの項目名（CommandLine など）とPayloadのキーを指定できる。
+/// 演算子は = != ~（ワイルドカード） !~ &gt; &gt;= &lt; &lt;= で、値は空白を含む場合に "" で囲む。
+/// path~ は区切り文字を含まないパターンをファイル名と照合し、それ以外はPathPatternのglob（"regex:" で正規表現）で照合する。
+/// 日時は now、now-1h（s・m・h・d。最大で前後100年）、または "2025-01-01T00:00:00Z" で指定する。
+/// </remarks>
+public sealed class EventQuery
+{
+    /// <summary>
+    /// now からのずれの最大値
+    /// </summary>
+    public static readonly TimeSpan MaxOffsetFromNow = TimeSpan.FromDays(36500);
+
+    // クライアントが指定した文字列をキーにするため、件数が上限に達したら作り直す
+    private const int MaxCacheSize = 1024;
+
+    private static readonly ConcurrentDictionary<string, Regex> _wildcards = new();
+    private static readonly ConcurrentDictionary<(Type Type, string Name), PropertyInfo?> _properties = new();
+
+    private readonly Node _root;
+
+    private EventQuery(string expression, Node root)
+    {
+        Expression = expression;
+        _root = root;
+    }
+
+    /// <summary>
+    /// クエリ式
+    /// </summary>
+    public string Expression { get; }
+
+    /// <summary>
+    /// クエリ式を解析
+    /// </summary>
+    /// <param name="expression">クエリ式</param>
+    /// <returns>クエリ</returns>
+    /// <exception cref="FormatException">構文が正しくない場合</exception>
+    public static EventQuery Parse(string expression)
+    {
+        ArgumentNullException.ThrowIfNull(expression);
+        return new EventQuery(expression, new Parser(expression).ParseQuery());
+    }
+
+    /// <summary>
+    /// クエリ式を解析
+    /// </summary>
+    /// <param name="expression">クエリ式</param>
+    /// <param name="query">クエリ</param>
+    /// <param name="error">構文が正しくない場合の理由</param>
+    /// <returns>解析できた場合true</returns>
+    public static bool TryParse(string expression, [System.Diagnostics.CodeAnalysis.NotNullWhen(true)] out EventQuery? query, out string error)
+    {
+        try
+        {
+            query = Parse(expression);
+            error = string.Empty;
+            return true;
+        }
+        catch (FormatException ex)
+        {
+            query = null;
+            error = ex.Message;
+            return false;
+        }
+    }
+
+    /// <summary>
+    /// イベントがクエリに一致するかどうか
+    /// </summary>
+    /// <param name="eventData">イベント</param>
+    /// <returns>一致する場合true</returns>
+    public bool IsMatch(BaseEventData eventData)
+    {
+        return IsMatch(eventData, DateTime.UtcNow);
+    }
+
+    /// <summary>
+    /// イベントがクエリに一致するかどうか
+    /// </summary>
+    /// <param name="eventData">イベント</param>
+    /// <param name="nowUtc">now を評価する現在日時（UTC）</param>
+    /// <returns>一致する場合true</returns>
+    public bool IsMatch(BaseEventData eventData, DateTime nowUtc)
+    {
+        return _root.Evaluate(eventData, nowUtc);
+    }
+
+    private abstract record Node
+    {
+        public abstract bool Evaluate(BaseEventData eventData, DateTime nowUtc);
+    }
+
+    private sealed record AndNode(Node Left, Node Right) : Node
+    {
+        public override bool Evaluate(BaseEventData eventData, DateTime nowUtc) => Left.Evaluate(eventData, nowUtc) && Right.Evaluate(eventData, nowUtc);
+    }
+
+    private sealed record OrNode(Node Left, Node Right) : Node
+    {
+        public override bool Evaluate(BaseEventData eventData, DateTime nowUtc) => Left.Evaluate(eventData, nowUtc) || Right.Evaluate(eventData, nowUtc);
+    }
+
+    private sealed record NotNode(Node Operand) : Node
+    {
+        public override bool Evaluate(BaseEventData eventData, DateTime nowUtc) => !Operand.Evaluate(eventData, nowUtc);
+    }
+
+    /// <summary>
+    /// 比較の値（数値・日時として解釈できる場合はその値も持つ）
+    /// </summary>
+    private sealed record Value(string Text, double? Number, DateTime? Time, TimeSpan? OffsetFromNow)
+    {
+        public bool IsTime => Time.HasValue || OffsetFromNow.HasValue;
+
+        public DateTime ResolveTime(DateTime nowUtc) => Time ?? nowUtc + OffsetFromNow!.Value;
+    }
+
+    private sealed record ComparisonNode(string Field, string Op, Value Value) : Node
+    {
+        public override bool Evaluate(BaseEventData eventData, DateTime nowUtc)
+        {
+            // 否定の演算子は、いずれの値にも一致しない場合に一致（パスが複数ある場合など）
+            return Op switch
+            {
+                "!=" => !GetValues(eventData).Any(actual => Compare(actual, "=", nowUtc)),
+                "!~" => !GetValues(eventData).Any(actual => Compare(actual, "~", nowUtc)),
+                _ => GetValues(eventData).Any(actual => Compare(actual, Op, nowUtc))
+            };
+        }
+
+        private IEnumerable<object?> GetValues(BaseEventData eventData)
+        {
+            switch (Field)
+            {
+                case "type":
+                    yield return eventData;
+                    break;
+                case "name":
+                    yield return eventData.EventName;
+                    break;
+                case "path":
+                    foreach (var path in EventFilterMatcher.GetPaths(eventData))
+                    {
+                        yield return path;
+                    }
+                    break;
+                case "pid":
+                    yield return eventData.ProcessId;
+                    break;
+                case "tid":
+                    yield return eventData.ThreadId;
+                    break;
+                case "ts":
+                    yield return eventData.Timestamp;
+                    break;
+                case "tag":
+                    yield return eventData.TagName;
+                    break;
+                case "provider":
+                    yield return eventData.ProviderName;
+                    break;
+                default:
+                    // "payload." で始まる場合はPayloadのキーのみ、それ以外はイベントの項目を優先してPayloadのキーと照合
+                    var key = Field.StartsWith("payload.", StringComparison.Ordinal) ? Field["payload.".Length..] : Field;
+                    var property = key == Field ? GetProperty(eventData.GetType(), key) : null;
+                    if (property != null)
+                    {
+                        yield return property.GetValue(eventData);
+                    }
+                    else
+                    {
+                        yield return eventData.Payload.FirstOrDefault(pair => string.Equals(pair.Key, key, StringComparison.OrdinalIgnoreCase)).Value;
+                    }
+                    break;
+            }
+        }
+
+        private bool Compare(object? actual, string comparison, DateTime nowUtc)
+        {
+            switch (actual)
+            {
+                case null:
+                    return false;
+                case BaseEventData eventData:
+                    return IsTypeMatch(eventData, Value.Text);
+                case DateTime timestamp when Value.IsTime:
+                    return CompareOrder(timestamp.ToUniversalTime().CompareTo(Value.ResolveTime(nowUtc)), comparison);
+            }
+
+            var text = Convert.ToString(actual, CultureInfo.InvariantCulture) ?? string.Empty;
+            if (comparison == "~")
+            {
+                return Field == "path" ? IsPathMatch(Value.Text, text) : IsWildcardMatch(Value.Text, text);
+            }
+
+            if (Field == "name" && comparison == "=")
+            {
+                return EventFilterMatcher.IsEventNameMatch(Value.Text, text);
+            }
+
+            if (Value.Number.HasValue && double.TryParse(text, NumberStyles.Float, CultureInfo.InvariantCulture, out var number))
+            {
+                return CompareOrder(number.CompareTo(Value.Number.Value), comparison);
+            }
+
+            return CompareOrder(string.Compare(text, Value.Text, StringComparison.OrdinalIgnoreCase), comparison);
+        }
+
+        private static bool CompareOrder(int order, string comparison)
+        {
+            return comparison switch
+            {
+                "=" => order == 0,
+                ">" => order > 0,
+                ">=" => order >= 0,
+                "<" => order < 0,
+                "<=" => order <= 0,
+                _ => false
+            };
+        }
+    }
+
+    /// <summary>
+    /// 種別の照合（"file" は$type、"file.write" は$typeとイベント名の操作（"FileIO/Write" の "Write"）で照合）
+    /// </summary>
+    private static bool IsTypeMatch(BaseEventData eventData, string type)
+    {
+        var eventType = EventFilterMatcher.GetEventType(eventData);
+        var separator = type.IndexOf('.');
+        if (separator < 0)
+        {
+            return string.Equals(eventType, type, StringComparison.OrdinalIgnoreCase);
+        }
+
+        var operation = eventData.EventName[(eventData.EventName.LastIndexOf('/') + 1)..];
+        return string.Equals(eventType, type[..separator], StringComparison.OrdinalIgnoreCase)
+            && string.Equals(operation, type[(separator + 1)..], StringComparison.OrdinalIgnoreCase);
+    }
+
+    /// <summary>
+    /// パスのパターン照合（区切り文字を含まないパターンはファイル名と照合）
+    /// </summary>
+    private static bool IsPathMatch(string pattern, string path)
+    {
+        if (!pattern.StartsWith("regex:", StringComparison.Ordinal) && pattern.IndexOfAny(new[] { '/', '\\' }) < 0)
+        {
+            path = path[(path.LastIndexOfAny(new[] { '/', '\\' }) + 1)..];
+        }
+
+        return PathPattern.IsMatch(pattern, path);
+    }
+
+    /// <summary>
+    /// ワイルドカードの照合（"*" は任意の文字列、"?" は任意の1文字。大文字小文字を区別しない）
+    /// </summary>
+    private static bool IsWildcardMatch(string pattern, string text)
+    {
+        if (!_wildcards.TryGetValue(pattern, out var regex))
+        {
+            regex = new Regex("^" + Regex.Escape(pattern).Replace(@"\*", ".*").Replace(@"\?", ".") + "$",
+                RegexOptions.IgnoreCase | RegexOptions.Singleline | RegexOptions.CultureInvariant);
+            AddToCache(_wildcards, pattern, regex);
+        }
+        return regex.IsMatch(text);
+    }
+
+    private static PropertyInfo? GetProperty(Type type, string name)
+    {
+        if (!_properties.TryGetValue((type, name), out var property))
+        {
+            property = type.GetProperty(name, BindingFlags.Public | BindingFlags.Instance | BindingFlags.IgnoreCase);
+            AddToCache(_properties, (type, name), property);
+        }
+        return property;
+    }
+
+    internal static void AddToCache<TKey, TValue>(ConcurrentDictionary<TKey, TValue> cache, TKey key, TValue value)
+        where TKey : notnull
+    {
+        if (cache.Count >= MaxCacheSize)
+        {
+            cache.Clear();
+        }
+        cache.TryAdd(key, value);
+    }
+
+    /// <summary>
+    /// クエリ式の再帰下降パーサー
+    /// </summary>
+    private sealed class Parser
+    {
+        private static readonly string[] Operators = { "!=", "!~", ">=", "<=", "=", "~", ">", "<" };
+
+        private readonly string _text;
+        private int _position;
+
+        public Parser(string text)
+        {
+            _text = text;
+        }
+
+        public Node ParseQuery()
+        {
+            var node = ParseOr();
+            SkipWhitespace();
+            if (_position < _text.Length)
+            {
+                throw Error($"Unexpected '{_text[_position]}'");
+            }
+            return node;
+        }
+
+        private Node ParseOr()
+        {
+            var node = ParseAnd();
+            while (TryKeyword("OR"))
+            {
+                node = new OrNode(node, ParseAnd());
+            }
+            return node;
+        }
+
+        private Node ParseAnd()
+        {
+            var node = ParseUnary();
+            while (TryKeyword("AND"))
+            {
+                node = new AndNode(node, ParseUnary());
+            }
+            return node;
+        }
+
+        private Node ParseUnary()
+        {
+            if (TryKeyword("NOT"))
+            {
+                return new NotNode(ParseUnary());
+            }
+
+            SkipWhitespace();
+            if (_position < _text.Length && _text[_position] == '(')
+            {
+                _position++;
+                var node = ParseOr();
+                SkipWhitespace();
+                if (_position >= _text.Length || _text[_position] != ')')
+                {
+                    throw Error("Expected ')'");
+                }
+                _position++;
+                return node;
+            }
+
+            return ParseComparison();
+        }
+
+        private Node ParseComparison()
+        {
+            SkipWhitespace();
+            var start = _position;
+            while (_position < _text.Length && (char.IsLetterOrDigit(_text[_position]) || _text[_position] is '_' or '.'))
+            {
+                _position++;
+            }
+            if (_position == start)
+            {
+                throw Error("Expected a field name");
+            }
+            var field = _text[start.._position].ToLowerInvariant();
+
+            SkipWhitespace();
+            var op = Operators.FirstOrDefault(candidate => string.CompareOrdinal(_text, _position, candidate, 0, candidate.Length) == 0)
+                ?? throw Error($"Expected an operator after '{field}'");
+            _position += op.Length;
+
+            var value = ParseValue();
+            Validate(field, op, value);
+            return new ComparisonNode(field, op, value);
+        }
+
+        private Value ParseValue()
+        {
+            SkipWhitespace();
+            string valueText;
+            if (_position < _text.Length && _text[_position] == '"')
+            {
+                // "" 内は \" と \\ のみエスケープ（Windowsのパスをそのまま書けるようにする）
+                var builder = new StringBuilder();
+                _position++;
+                while (true)
+                {
+                    if (_position >= _text.Length)
+                    {
+                        throw Error("Unterminated string");
+                    }
+                    var c = _text[_position++];
+                    if (c == '"')
+                    {
+                        break;
+                    }
+                    if (c == '\\' && _position < _text.Length && _text[_position] is ('"' or '\\'))
+                    {
+                        c = _text[_position++];
+                    }
+                    builder.Append(c);
+                }
+                valueText = builder.ToString();
+            }
+            else
+            {
+                var start = _position;
+                while (_position < _text.Length && !char.IsWhiteSpace(_text[_position]) && _text[_position] is not ('(' or ')'))
+                {
+                    _position++;
+                }
+                if (_position == start)
+                {
+                    throw Error("Expected a value");
+                }
+                valueText = _text[start.._position];
+            }
+
+            double? number = double.TryParse(valueText, NumberStyles.Float, CultureInfo.InvariantCulture, out var parsedNumber) ? parsedNumber : null;
+            return new Value(valueText, number, ParseTime(valueText), ParseOffsetFromNow(valueText));
+        }
+
+        private static DateTime? ParseTime(string valueText)
+        {
+            // 数値は日時として扱わない
+            if (valueText.Length == 0 || char.IsDigit(valueText[^1]) && !valueText.Contains('-') && !valueText.Contains(':'))
+            {
+                return null;
+            }
+
+            return DateTime.TryParse(valueText, CultureInfo.InvariantCulture, DateTimeStyles.AdjustToUniversal | DateTimeStyles.AssumeUniversal, out var time)
+                ? time
+                : null;
+        }
+
+        private TimeSpan? ParseOffsetFromNow(string valueText)
+        {
+            if (string.Equals(valueText, "now", StringComparison.OrdinalIgnoreCase))
+            {
+                return TimeSpan.Zero;
+            }
+
+            var match = Regex.Match(valueText, @"^now([+-])(\d+)([smhd])$", RegexOptions.IgnoreCase);
+            if (!match.Success)
+            {
+                return null;
+            }
+
+            // 評価時に日時の範囲を超えないよう、解析時にずれの大きさを制限する
+            var unitSeconds = char.ToLowerInvariant(match.Groups[3].Value[0]) switch
+            {
+                's' => 1L,
+                'm' => 60L,
+                'h' => 3600L,
+                _ => 86400L
+            };
+            if (!long.TryParse(match.Groups[2].Value, NumberStyles.None, CultureInfo.InvariantCulture, out var amount) ||
+                amount > (long)MaxOffsetFromNow.TotalSeconds / unitSeconds)
+            {
+                throw Error($"Time offset '{valueText}' is out of range (up to {MaxOffsetFromNow.TotalDays:0}d)");
+            }
+
+            var offset = TimeSpan.FromSeconds(amount * unitSeconds);
+            return match.Groups[1].Value == "-" ? -offset : offset;
+        }
+
+        private void Validate(string field, string op, Value value)
+        {
+            if (field is "type" && op is not ("=" or "!="))
+            {
+                throw Error("'type' supports only = and !=");
+            }
+
+            if (field is "ts" && !value.IsTime)
+            {
+                throw Error($"'{value.Text}' is not a time (use now, now-1h or \"2025-01-01T00:00:00Z\")");
+            }
+
+            if (field is "path" && op is ("~" or "!~") && !PathPattern.IsValid(value.Text))
+            {
+                throw Error($"Invalid path pattern '{value.Text}'");
+            }
+        }
+
+        private bool TryKeyword(string keyword)
+        {
+            SkipWhitespace();
+            if (string.Compare(_text, _position, keyword, 0, keyword.Length, StringComparison.OrdinalIgnoreCase) != 0)
+            {
+                return false;
+            }
+
+            // "ORDER" のような項目名を区別する
+            var end = _position + keyword.Length;
+            if (end < _text.Length && !char.IsWhiteSpace(_text[end]) && _text[end] != '(')
+            {
+                return false;
+            }
+
+            _position = end;
+            return true;
+        }
+
+        private void SkipWhitespace()
+        {
+            while (_position < _text.Length && char.IsWhiteSpace(_text[_position]))
+            {
+                _position++;
+            }
+        }
+
+        private FormatException Error(string message)
+        {
+            return new FormatException($"{message} at position {_position + 1}");
+        }
+    }
+}
@@ -0,0 +1,102 @@
+namespace ProcTail.Application.Services;
+
+/// <summary>
+/// 1秒ごとのイベント数に基づくレート制限
+/// </summary>
+/// <remarks>
+/// 上限を超えた秒内のイベントはN件に1件だけ残す（乱数を使わないため同じ入力には常に同じ結果になる）。
+/// 削除・リネームは後から復元できない情報のため上限を超えても常に残す。
+/// 秒の区切りにはイベントのタイムスタンプを使用する。
+/// </remarks>
+public class EventRateLimiter
+{
+    private const string GlobalKey = "";
+
+    private static readonly HashSet<string> AlwaysKeptEvents = new(StringComparer.OrdinalIgnoreCase)
+    {
+        "FileIO/Delete",
+        "FileIO/Rename"
+    };
+
+    private readonly int _globalMaxEventsPerSecond;
+    private readonly int _overflowSampleRate;
+    private readonly Dictionary<string, RateWindow> _windows = new();
+    private readonly object _lockObject = new();
+    private long _sampledOutCount;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    /// <param name="globalMaxEventsPerSecond">全タグ合計の1秒あたりの上限（0以下の場合は無制限）</param>
+    /// <param name="overflowSampleRate">上限超過時に残す間隔（N件に1件、0以下の場合は全て破棄）</param>
+    public EventRateLimiter(int globalMaxEventsPerSecond, int overflowSampleRate)
+    {
+        _globalMaxEventsPerSecond = globalMaxEventsPerSecond;
+        _overflowSampleRate = overflowSampleRate;
+    }
+
+    /// <summary>
+    /// レート制限により破棄したイベントの累計
+    /// </summary>
+    public long SampledOutCount => Interlocked.Read(ref _sampledOutCount);
+
+    /// <summary>
+    /// イベントを残すかどうかを判定
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="eventName">イベント名</param>
+    /// <param name="timestamp">イベントのタイムスタンプ</param>
+    /// <param name="tagMaxEventsPerSecond">タグの1秒あたりの上限（0以下の場合は無制限）</param>
+    /// <returns>残す場合true</returns>
+    public bool ShouldKeep(string tagName, string eventName, DateTime timestamp, int tagMaxEventsPerSecond)
+    {
+        if (_globalMaxEventsPerSecond <= 0 && tagMaxEventsPerSecond <= 0)
+        {
+            return true;
+        }
+
+        var second = timestamp.Ticks / TimeSpan.TicksPerSecond;
+        lock (_lockObject)
+        {
+            var tagWindow = GetWindow(tagName, second);
+            var globalWindow = GetWindow(GlobalKey, second);
+            tagWindow.Count++;
+            globalWindow.Count++;
+
+            var overLimit = (tagMaxEventsPerSecond > 0 && tagWindow.Count > tagMaxEventsPerSecond) ||
+                            (_globalMaxEventsPerSecond > 0 && globalWindow.Count > _globalMaxEventsPerSecond);
+            if (!overLimit || AlwaysKeptEvents.Contains(eventName))
+            {
+                return true;
+            }
+
+            // 超過分はタグごとに数えてN件に1件残す
+            var overflowIndex = tagWindow.Overflow++;
+            if (_overflowSampleRate > 0 && overflowIndex % _overflowSampleRate == 0)
+            {
+                return true;
+            }
+        }
+
+        Interlocked.Increment(ref _sampledOutCount);
+        return false;
+    }
+
+    private RateWindow GetWindow(string key, long second)
+    {
+        if (!_windows.TryGetValue(key, out var window) || window.Second != second)
+        {
+            window = new RateWindow { Second = second };
+            _windows[key] = window;
+        }
+
+        return window;
+    }
+
+    private sealed class RateWindow
+    {
+        public long Second { get; init; }
+        public int Count { get; set; }
+        public long Overflow { get; set; }
+    }
+}
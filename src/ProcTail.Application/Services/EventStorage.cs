@@ -14,7 +14,11 @@ public class EventStorage : IEventStorage, IDisposable
     private readonly int _maxEventsPerTag;
     private readonly ConcurrentDictionary<string, ConcurrentQueue<BaseEventData>> _eventQueues = new();
     private readonly ConcurrentDictionary<string, int> _eventCounts = new();
-    private readonly object _lockObject = new();
+    private readonly ConcurrentDictionary<string, (BackpressurePolicy Policy, TimeSpan BlockTimeout)> _backpressurePolicies = new();
+    private readonly ConcurrentDictionary<string, long> _droppedCounts = new();
+    private readonly ConcurrentDictionary<string, long> _sequenceNumbers = new();
+    private readonly ConcurrentDictionary<string, object> _tagLocks = new();
+    private TaskCompletionSource _spaceReleased = new(TaskCreationOptions.RunContinuationsAsynchronously);
     private readonly Timer _cleanupTimer;
     private bool _disposed;
 
@@ -39,51 +43,102 @@ public class EventStorage : IEventStorage, IDisposable
     /// </summary>
     /// <param name="tagName">タグ名</param>
     /// <param name="eventData">イベントデータ</param>
-    /// <returns>非同期タスク</returns>
-    public async Task StoreEventAsync(string tagName, BaseEventData eventData)
+    /// <returns>連番を付与して記録したイベント（破棄した場合や記録できなかった場合はnull）</returns>
+    public async Task<BaseEventData?> StoreEventAsync(string tagName, BaseEventData eventData)
     {
         if (string.IsNullOrWhiteSpace(tagName))
         {
             _logger.LogWarning("イベント記録に失敗: タグ名が無効です");
-            return;
+            return null;
         }
 
         if (eventData == null)
         {
             _logger.LogWarning("イベント記録に失敗: イベントデータがnullです (Tag: {TagName})", tagName);
-            return;
+            return null;
         }
 
         try
         {
-            await Task.Run(() =>
+            // 満杯の場合はタグの設定に従って新しいイベントを破棄、または空きを待機
+            var (policy, blockTimeout) = GetBackpressurePolicy(tagName);
+            var deadline = DateTime.UtcNow + (policy == BackpressurePolicy.Block ? blockTimeout : TimeSpan.Zero);
+            while (true)
             {
-                // キューを取得または作成
-                var queue = _eventQueues.GetOrAdd(tagName, _ => new ConcurrentQueue<BaseEventData>());
-                
-                // イベントを追加
-                queue.Enqueue(eventData);
-                
-                // カウントを更新
-                var currentCount = _eventCounts.AddOrUpdate(tagName, 1, (key, oldValue) => oldValue + 1);
+                // 空きの確認より先に通知を取得し、確認後の解放を取りこぼさない
+                var spaceReleased = Volatile.Read(ref _spaceReleased).Task;
+                var remaining = deadline - DateTime.UtcNow;
 
-                // 最大数を超えた場合、古いイベントを削除
-                if (currentCount > _maxEventsPerTag)
+                // 空きの確認・採番・追加を同じロックで行い、同時に記録されても上限を超えないようにする
+                lock (GetTagLock(tagName))
                 {
-                    TrimQueueToLimit(tagName, queue);
+                    var isFull = policy != BackpressurePolicy.DropOldest &&
+                        _eventCounts.TryGetValue(tagName, out var count) && count >= _maxEventsPerTag;
+                    if (!isFull)
+                    {
+                        return Enqueue(tagName, eventData);
+                    }
+
+                    if (remaining <= TimeSpan.Zero)
+                    {
+                        // 破棄したイベントも連番を消費し、利用側で欠番として検出できるようにする
+                        NextSequenceNumber(tagName);
+                        _droppedCounts.AddOrUpdate(tagName, 1, (key, oldValue) => oldValue + 1);
+                        _logger.LogDebug("バッファが満杯のためイベントを破棄しました (Tag: {TagName}, Policy: {Policy})", tagName, policy);
+                        return null;
+                    }
                 }
 
-                _logger.LogDebug("イベントを記録しました (Tag: {TagName}, EventType: {EventType}, ProcessId: {ProcessId}, CurrentCount: {CurrentCount})",
-                    tagName, eventData.GetType().Name, eventData.ProcessId, currentCount);
-            });
+                try
+                {
+                    await spaceReleased.WaitAsync(remaining);
+                }
+                catch (TimeoutException)
+                {
+                    // 期限後にもう一度空きを確認し、なければ破棄する
+                }
+            }
         }
         catch (Exception ex)
         {
             _logger.LogError(ex, "イベント記録中にエラーが発生しました (Tag: {TagName}, EventType: {EventType})",
                 tagName, eventData?.GetType().Name ?? "Unknown");
+            return null;
         }
     }
 
+    /// <summary>
+    /// イベントを採番してキューに追加（タグのロック内で呼び出す）
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="eventData">イベントデータ</param>
+    /// <returns>連番を付与したイベント</returns>
+    private BaseEventData Enqueue(string tagName, BaseEventData eventData)
+    {
+        var queue = _eventQueues.GetOrAdd(tagName, _ => new ConcurrentQueue<BaseEventData>());
+        var stored = eventData with { SequenceNumber = NextSequenceNumber(tagName) };
+        queue.Enqueue(stored);
+        var currentCount = _eventCounts.AddOrUpdate(tagName, 1, (key, oldValue) => oldValue + 1);
+
+        // 最大数を超えた場合、古いイベントを削除（DropOldestの場合のみ）
+        if (currentCount > _maxEventsPerTag)
+        {
+            TrimQueueToLimit(tagName, queue);
+        }
+
+        _logger.LogDebug("イベントを記録しました (Tag: {TagName}, EventType: {EventType}, ProcessId: {ProcessId}, CurrentCount: {CurrentCount})",
+            tagName, eventData.GetType().Name, eventData.ProcessId, currentCount);
+        return stored;
+    }
+
+    /// <summary>
+    /// タグのキューとカウントを更新する際のロックを取得
+    /// </summary>
+    private object GetTagLock(string tagName)
+    {
+        return _tagLocks.GetOrAdd(tagName, _ => new object());
+    }
+
     /// <summary>
     /// タグに関連するイベントを取得
     /// </summary>
@@ -136,16 +191,21 @@ public class EventStorage : IEventStorage, IDisposable
         {
             await Task.Run(() =>
             {
-                if (_eventQueues.TryRemove(tagName, out var queue))
-                {
-                    var removedCount = _eventCounts.TryRemove(tagName, out var count) ? count : queue.Count;
-                    _logger.LogInformation("イベントをクリアしました (Tag: {TagName}, RemovedCount: {RemovedCount})", 
-                        tagName, removedCount);
-                }
-                else
+                int removedCount;
+                lock (GetTagLock(tagName))
                 {
-                    _logger.LogDebug("クリア対象のイベントが見つかりません (Tag: {TagName})", tagName);
+                    if (!_eventQueues.TryRemove(tagName, out var queue))
+                    {
+                        _logger.LogDebug("クリア対象のイベントが見つかりません (Tag: {TagName})", tagName);
+                        return;
+                    }
+
+                    removedCount = _eventCounts.TryRemove(tagName, out var count) ? count : queue.Count;
                 }
+
+                SignalSpaceReleased();
+                _logger.LogInformation("イベントをクリアしました (Tag: {TagName}, RemovedCount: {RemovedCount})", 
+                    tagName, removedCount);
             });
         }
         catch (Exception ex)
@@ -210,11 +270,22 @@ public class EventStorage : IEventStorage, IDisposable
                 // メモリ使用量の概算（非常に大まかな計算）
                 var estimatedMemoryUsage = CalculateEstimatedMemoryUsage();
 
+                var bufferStatisticsByTag = _eventQueues.Keys
+                    .Concat(_backpressurePolicies.Keys)
+                    .Concat(_droppedCounts.Keys)
+                    .Distinct()
+                    .ToDictionary(
+                        tag => tag,
+                        tag => new TagBufferStatistics(
+                            GetBackpressurePolicy(tag).Policy,
+                            _droppedCounts.TryGetValue(tag, out var droppedCount) ? droppedCount : 0));
+
                 var statistics = new StorageStatistics(
                     totalTags,
                     totalEvents,
                     eventCountByTag,
-                    estimatedMemoryUsage
+                    estimatedMemoryUsage,
+                    bufferStatisticsByTag
                 );
 
                 _logger.LogDebug("ストレージ統計を取得しました (Tags: {TotalTags}, Events: {TotalEvents}, Memory: {MemoryMB}MB)",
@@ -329,6 +400,47 @@ public class EventStorage : IEventStorage, IDisposable
         }
     }
 
+    /// <summary>
+    /// タグのバッファが満杯の場合の動作を設定
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="policy">満杯時の動作</param>
+    /// <param name="blockTimeout">BackpressurePolicy.Blockで空きを待つ最大時間</param>
+    public void SetBackpressurePolicy(string tagName, BackpressurePolicy policy, TimeSpan blockTimeout)
+    {
+        if (string.IsNullOrWhiteSpace(tagName))
+        {
+            return;
+        }
+
+        _backpressurePolicies[tagName] = (policy, blockTimeout > TimeSpan.Zero ? blockTimeout : TimeSpan.Zero);
+        _logger.LogInformation("バッファ満杯時の動作を設定しました (Tag: {TagName}, Policy: {Policy}, BlockTimeout: {BlockTimeout})",
+            tagName, policy, blockTimeout);
+    }
+
+    private (BackpressurePolicy Policy, TimeSpan BlockTimeout) GetBackpressurePolicy(string tagName)
+    {
+        return _backpressurePolicies.TryGetValue(tagName, out var setting)
+            ? setting
+            : (BackpressurePolicy.DropOldest, TimeSpan.Zero);
+    }
+
+    /// <summary>
+    /// タグの次の連番を取得（クリア後も番号は戻さない）
+    /// </summary>
+    private long NextSequenceNumber(string tagName)
+    {
+        return _sequenceNumbers.AddOrUpdate(tagName, 1, (key, oldValue) => oldValue + 1);
+    }
+
+    /// <summary>
+    /// バッファの空きを待機中の記録に通知
+    /// </summary>
+    private void SignalSpaceReleased()
+    {
+        Interlocked.Exchange(ref _spaceReleased, new TaskCompletionSource(TaskCreationOptions.RunContinuationsAsynchronously)).TrySetResult();
+    }
+
     /// <summary>
     /// キューを最大数まで削減
     /// </summary>
@@ -348,6 +460,7 @@ public class EventStorage : IEventStorage, IDisposable
                     if (queue.TryDequeue(out _))
                     {
                         _eventCounts.AddOrUpdate(tagName, 0, (key, oldValue) => Math.Max(0, oldValue - 1));
+                        _droppedCounts.AddOrUpdate(tagName, 1, (key, oldValue) => oldValue + 1);
                     }
                     else
                     {
@@ -378,8 +491,13 @@ public class EventStorage : IEventStorage, IDisposable
 
             foreach (var tagName in _eventQueues.Keys.ToList())
             {
-                if (_eventQueues.TryGetValue(tagName, out var queue))
+                lock (GetTagLock(tagName))
                 {
+                    if (!_eventQueues.TryGetValue(tagName, out var queue))
+                    {
+                        continue;
+                    }
+
                     var cleanedCount = CleanOldEventsFromQueue(queue, cutoffTime);
                     if (cleanedCount > 0)
                     {
@@ -398,6 +516,7 @@ public class EventStorage : IEventStorage, IDisposable
 
             if (totalCleaned > 0)
             {
+                SignalSpaceReleased();
                 _logger.LogInformation("定期クリーンアップを実行しました (CleanedEvents: {CleanedEvents})", totalCleaned);
             }
         }
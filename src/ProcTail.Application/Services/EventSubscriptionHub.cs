@@ -0,0 +1,151 @@
+using System.Threading.Channels;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Services;
+
+/// <summary>
+/// 記録したイベントを購読者に配信する
+/// </summary>
+/// <remarks>
+/// イベントはストレージに記録した直後に、タグが一致する全ての購読に配信する。
+/// 購読ごとのバッファが満杯の場合は、記録を止めないよう古いイベントから捨てて件数を数える。
+/// 配信するのはストレージが連番を付与して記録したイベントのみで、満杯で破棄したイベントは配信しない。
+/// </remarks>
+public class EventSubscriptionHub
+{
+    /// <summary>
+    /// 既定の購読ごとのバッファ件数
+    /// </summary>
+    public const int DefaultCapacity = 1000;
+
+    private readonly List<EventSubscription> _subscriptions = new();
+    private readonly object _lockObject = new();
+    private EventSubscription[] _snapshot = Array.Empty<EventSubscription>();
+
+    /// <summary>
+    /// 購読数
+    /// </summary>
+    public int SubscriptionCount => Volatile.Read(ref _snapshot).Length;
+
+    /// <summary>
+    /// タグのイベントを購読
+    /// </summary>
+    /// <param name="tagNames">購読するタグ名（空の場合は全てのタグ）</param>
+    /// <param name="filter">配信するイベントの絞り込み条件（nullの場合は全て）</param>
+    /// <param name="capacity">読み出されていないイベントを保持する件数</param>
+    /// <returns>購読（破棄すると購読を終了する）</returns>
+    public EventSubscription Subscribe(IReadOnlyCollection<string> tagNames, EventFilter? filter = null, int capacity = DefaultCapacity)
+    {
+        ArgumentNullException.ThrowIfNull(tagNames);
+        var filterError = filter == null ? null : EventFilterMatcher.Validate(filter);
+        if (filterError != null)
+        {
+            throw new ArgumentException(filterError, nameof(filter));
+        }
+
+        var subscription = new EventSubscription(this, tagNames, filter, capacity > 0 ? capacity : DefaultCapacity);
+        lock (_lockObject)
+        {
+            _subscriptions.Add(subscription);
+            Volatile.Write(ref _snapshot, _subscriptions.ToArray());
+        }
+        return subscription;
+    }
+
+    /// <summary>
+    /// 記録したイベントをタグが一致する購読に配信
+    /// </summary>
+    /// <param name="eventData">記録したイベント</param>
+    public void Publish(BaseEventData eventData)
+    {
+        foreach (var subscription in Volatile.Read(ref _snapshot))
+        {
+            if (subscription.Matches(eventData))
+            {
+                subscription.Write(eventData);
+            }
+        }
+    }
+
+    /// <summary>
+    /// 全ての購読を終了（サービスの停止時）
+    /// </summary>
+    public void CompleteAll()
+    {
+        foreach (var subscription in Volatile.Read(ref _snapshot))
+        {
+            subscription.Dispose();
+        }
+    }
+
+    internal void Remove(EventSubscription subscription)
+    {
+        lock (_lockObject)
+        {
+            if (_subscriptions.Remove(subscription))
+            {
+                Volatile.Write(ref _snapshot, _subscriptions.ToArray());
+            }
+        }
+    }
+}
+
+/// <summary>
+/// タグのイベントの購読
+/// </summary>
+public sealed class EventSubscription : IDisposable
+{
+    private readonly EventSubscriptionHub _hub;
+    private readonly HashSet<string> _tagNames;
+    private readonly EventFilter? _filter;
+    private readonly Channel<BaseEventData> _channel;
+    private long _droppedCount;
+
+    internal EventSubscription(EventSubscriptionHub hub, IReadOnlyCollection<string> tagNames, EventFilter? filter, int capacity)
+    {
+        _hub = hub;
+        _tagNames = new HashSet<string>(tagNames);
+        _filter = filter;
+        _channel = Channel.CreateBounded<BaseEventData>(
+            new BoundedChannelOptions(capacity)
+            {
+                FullMode = BoundedChannelFullMode.DropOldest,
+                SingleReader = true
+            },
+            _ => Interlocked.Increment(ref _droppedCount));
+    }
+
+    /// <summary>
+    /// 購読したイベント（購読の終了で完了する）
+    /// </summary>
+    public ChannelReader<BaseEventData> Reader => _channel.Reader;
+
+    /// <summary>
+    /// 前回の取得以降にバッファが満杯で捨てたイベント数を取得し、0に戻す
+    /// </summary>
+    /// <returns>捨てたイベント数</returns>
+    public long TakeDroppedCount()
+    {
+        return Interlocked.Exchange(ref _droppedCount, 0);
+    }
+
+    internal bool Matches(BaseEventData eventData)
+    {
+        return (_tagNames.Count == 0 || _tagNames.Contains(eventData.TagName))
+            && (_filter == null || EventFilterMatcher.IsMatch(_filter, eventData));
+    }
+
+    internal void Write(BaseEventData eventData)
+    {
+        _channel.Writer.TryWrite(eventData);
+    }
+
+    /// <summary>
+    /// 購読を終了
+    /// </summary>
+    public void Dispose()
+    {
+        _hub.Remove(this);
+        _channel.Writer.TryComplete();
+    }
+}
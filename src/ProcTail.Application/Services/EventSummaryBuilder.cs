@@ -0,0 +1,105 @@
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Services;
+
+/// <summary>
+/// タグの記録イベントから概要表示用の集計を作成する
+/// </summary>
+/// <remarks>
+/// ストレージに残っているイベントだけを集計するため、古いイベントが破棄されたタグでは直近のイベントの集計になる。
+/// 集約された書き込み（--coalesce-writes）は集約前の件数と合計バイト数で数える。
+/// パスが取得できなかったファイルイベント（一部のクローズなど）はファイルの集計に含めない。
+/// </remarks>
+public static class EventSummaryBuilder
+{
+    /// <summary>
+    /// タグのイベントを集計
+    /// </summary>
+    /// <param name="events">タグのイベント</param>
+    /// <param name="topCount">イベント数の多いファイルの件数</param>
+    /// <returns>集計結果</returns>
+    public static TagEventSummary Build(IReadOnlyList<BaseEventData> events, int topCount)
+    {
+        if (events.Count == 0)
+        {
+            return new TagEventSummary();
+        }
+
+        var firstEventAt = events.Min(eventData => eventData is FileEventData { FirstTimestamp: { } firstTimestamp } ? firstTimestamp : eventData.Timestamp);
+        var lastEventAt = events.Max(eventData => eventData.Timestamp);
+
+        var paths = events.OfType<FileEventData>()
+            .Where(fileEvent => !string.IsNullOrEmpty(fileEvent.FilePath))
+            .GroupBy(fileEvent => fileEvent.FilePath, StringComparer.OrdinalIgnoreCase)
+            .Select(fileEvents => new PathActivity(
+                fileEvents.Key,
+                fileEvents.Sum(GetEventCount),
+                fileEvents.Where(fileEvent => fileEvent.EventName == "FileIO/Write").Sum(GetBytesWritten)))
+            .ToList();
+
+        var childProcesses = events.OfType<ProcessStartEventData>().ToList();
+
+        return new TagEventSummary
+        {
+            EventCount = events.Sum(GetEventCount),
+            FirstEventAt = firstEventAt,
+            LastEventAt = lastEventAt,
+            SessionDurationMs = (long)Math.Max(0, (lastEventAt - firstEventAt).TotalMilliseconds),
+            FileCount = paths.Count,
+            BytesWritten = paths.Sum(path => path.BytesWritten),
+            ChildProcessCount = childProcesses.Count,
+            Directories = paths
+                .GroupBy(path => GetDirectory(path.FilePath), StringComparer.OrdinalIgnoreCase)
+                .Select(directory => new DirectorySummary(
+                    directory.Key,
+                    directory.Select(path => GetFileName(path.FilePath)).OrderBy(name => name, StringComparer.OrdinalIgnoreCase).ToList(),
+                    directory.Sum(path => path.EventCount),
+                    directory.Sum(path => path.BytesWritten)))
+                .OrderByDescending(directory => directory.EventCount)
+                .ThenBy(directory => directory.Directory, StringComparer.OrdinalIgnoreCase)
+                .ToList(),
+            NoisyPaths = paths
+                .OrderByDescending(path => path.EventCount)
+                .ThenBy(path => path.FilePath, StringComparer.OrdinalIgnoreCase)
+                .Take(Math.Max(0, topCount))
+                .ToList(),
+            ChildProcesses = childProcesses
+                .GroupBy(processStart => processStart.ChildProcessName, StringComparer.OrdinalIgnoreCase)
+                .Select(processStarts => new ChildProcessSummary(processStarts.Key, processStarts.Count()))
+                .OrderByDescending(child => child.Count)
+                .ThenBy(child => child.ProcessName, StringComparer.OrdinalIgnoreCase)
+                .ToList()
+        };
+    }
+
+    private static int GetEventCount(BaseEventData eventData)
+    {
+        return eventData is FileEventData { CoalescedCount: { } coalescedCount } ? coalescedCount : 1;
+    }
+
+    private static long GetBytesWritten(FileEventData write)
+    {
+        if (write.TotalBytes.HasValue)
+        {
+            return write.TotalBytes.Value;
+        }
+
+        return write.Payload.TryGetValue("IoSize", out var value) && long.TryParse(value?.ToString(), out var size)
+            ? size
+            : 0;
+    }
+
+    /// <summary>
+    /// パスのディレクトリ部分（実行環境に関係なく "/" と "\" の両方を区切り文字として扱う）
+    /// </summary>
+    private static string GetDirectory(string path)
+    {
+        var separatorIndex = path.LastIndexOfAny(new[] { '\\', '/' });
+        return separatorIndex > 0 ? path[..separatorIndex] : string.Empty;
+    }
+
+    private static string GetFileName(string path)
+    {
+        return path[(path.LastIndexOfAny(new[] { '\\', '/' }) + 1)..];
+    }
+}
@@ -0,0 +1,214 @@
+using System.Collections.Concurrent;
+
+namespace ProcTail.Application.Services;
+
+/// <summary>
+/// ファイルのオープンからクローズまでを1つのセッションとして相関IDを割り当てる
+/// </summary>
+/// <remarks>
+/// ハンドルの識別にはETWのFileObject、eBPFバックエンドではファイルディスクリプタを使用する。
+/// いずれもクローズ後に再利用されるため、クローズ時とプロセス終了時にエントリを破棄する。
+/// クローズ時のイベントはパスが取得できない場合があるため、オープン時のパスと書き込みの有無もセッションに保持する。
+/// 一時ファイルに書き込んでクローズした後に元のファイルへリネームする保存パターンを1つの操作として扱えるよう、
+/// 書き込み後にクローズしたファイルを同じプロセスがリネームした場合は、書き込んだセッションの相関IDを引き継ぐ。
+/// </remarks>
+public class FileSessionTracker
+{
+    private static readonly string[] HandleKeys = { "FileObject", "FileDescriptor" };
+
+    /// <summary>
+    /// リネームを待つ書き込み済みファイルの上限（超えた場合は全て破棄する）
+    /// </summary>
+    private const int MaxPendingRenames = 1024;
+
+    private readonly ConcurrentDictionary<(int ProcessId, string Handle), FileSession> _sessions = new();
+    private readonly ConcurrentDictionary<(int ProcessId, string Path), Guid> _closedWrittenFiles = new();
+
+    /// <summary>
+    /// 追跡中のセッション数
+    /// </summary>
+    public int Count => _sessions.Count;
+
+    /// <summary>
+    /// ファイルイベントの相関IDを取得
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <param name="eventName">イベント名（FileIO/Create, FileIO/Closeなど）</param>
+    /// <param name="payload">ペイロード</param>
+    /// <returns>相関ID（ハンドルが取得できない場合やオープンを観測していない場合はnull）</returns>
+    public Guid? GetCorrelationId(int processId, string eventName, IReadOnlyDictionary<string, object> payload)
+    {
+        return GetCorrelationId(processId, eventName, payload, null, out _);
+    }
+
+    /// <summary>
+    /// ファイルイベントの相関IDを取得し、書き込みのあったセッションのクローズを判定
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <param name="eventName">イベント名（FileIO/Create, FileIO/Closeなど）</param>
+    /// <param name="payload">ペイロード</param>
+    /// <param name="filePath">イベントのファイルパス（オープン時にセッションに保持する）</param>
+    /// <param name="closedWrittenFilePath">書き込みのあったセッションのクローズの場合、そのファイルの現在のパス（リネーム後はリネーム先）</param>
+    /// <returns>相関ID（ハンドルが取得できない場合やオープンを観測していない場合はnull）</returns>
+    public Guid? GetCorrelationId(int processId, string eventName, IReadOnlyDictionary<string, object> payload, string? filePath, out string? closedWrittenFilePath)
+    {
+        closedWrittenFilePath = null;
+        var handle = GetHandle(payload);
+        if (handle == null)
+        {
+            // Linux/macOSのリネームはパスを指定するシステムコールのため、ハンドルを持たない
+            return eventName == "FileIO/Rename" && filePath != null
+                ? TakeClosedWrittenFile(processId, filePath)
+                : null;
+        }
+
+        var key = (processId, handle);
+        switch (eventName)
+        {
+            case "FileIO/Create":
+                // 同じハンドルが再オープンされた場合は新しいセッションとする
+                var session = new FileSession(Guid.NewGuid(), filePath);
+                _sessions[key] = session;
+                return session.CorrelationId;
+
+            case "FileIO/Close":
+                if (!_sessions.TryRemove(key, out var closedSession))
+                {
+                    return null;
+                }
+
+                if (closedSession.IsWritten)
+                {
+                    closedWrittenFilePath = closedSession.FilePath ?? filePath;
+                    if (closedWrittenFilePath != null)
+                    {
+                        AddClosedWrittenFile(processId, closedWrittenFilePath, closedSession.CorrelationId);
+                    }
+                }
+                return closedSession.CorrelationId;
+
+            case "FileIO/Rename":
+                if (!_sessions.TryGetValue(key, out var renamedSession))
+                {
+                    return filePath != null ? TakeClosedWrittenFile(processId, filePath) : null;
+                }
+
+                // リネームのために開き直したハンドルは、書き込んだセッションの相関IDに付け替える
+                var sourcePath = renamedSession.FilePath ?? filePath;
+                if (!renamedSession.IsWritten && sourcePath != null && TakeClosedWrittenFile(processId, sourcePath) is { } writtenId)
+                {
+                    renamedSession.CorrelationId = writtenId;
+                }
+
+                // ETWのリネームイベントはリネーム先を含まないため、分かる場合のみ更新する
+                var newFilePath = GetNewFileName(payload);
+                if (newFilePath != null)
+                {
+                    renamedSession.FilePath = newFilePath;
+                }
+                return renamedSession.CorrelationId;
+
+            default:
+                if (!_sessions.TryGetValue(key, out var existingSession))
+                {
+                    return null;
+                }
+
+                if (eventName == "FileIO/Write")
+                {
+                    existingSession.IsWritten = true;
+                }
+                return existingSession.CorrelationId;
+        }
+    }
+
+    /// <summary>
+    /// プロセス終了時に未クローズのセッションを破棄
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    public void RemoveProcess(int processId)
+    {
+        foreach (var key in _sessions.Keys)
+        {
+            if (key.ProcessId == processId)
+            {
+                _sessions.TryRemove(key, out _);
+            }
+        }
+
+        foreach (var key in _closedWrittenFiles.Keys)
+        {
+            if (key.ProcessId == processId)
+            {
+                _closedWrittenFiles.TryRemove(key, out _);
+            }
+        }
+    }
+
+    private void AddClosedWrittenFile(int processId, string filePath, Guid correlationId)
+    {
+        if (_closedWrittenFiles.Count >= MaxPendingRenames)
+        {
+            _closedWrittenFiles.Clear();
+        }
+
+        _closedWrittenFiles[(processId, NormalizePath(filePath))] = correlationId;
+    }
+
+    private Guid? TakeClosedWrittenFile(int processId, string filePath)
+    {
+        return _closedWrittenFiles.TryRemove((processId, NormalizePath(filePath)), out var correlationId)
+            ? correlationId
+            : null;
+    }
+
+    /// <summary>
+    /// Windowsのパスは大文字小文字を区別しない
+    /// </summary>
+    private static string NormalizePath(string filePath)
+    {
+        return OperatingSystem.IsWindows() ? filePath.ToUpperInvariant() : filePath;
+    }
+
+    private static string? GetNewFileName(IReadOnlyDictionary<string, object> payload)
+    {
+        return payload.TryGetValue("NewFileName", out var value) && value is string newFileName && !string.IsNullOrEmpty(newFileName)
+            ? newFileName
+            : null;
+    }
+
+    /// <summary>
+    /// ペイロードからハンドルの識別子を取得
+    /// </summary>
+    private static string? GetHandle(IReadOnlyDictionary<string, object> payload)
+    {
+        foreach (var handleKey in HandleKeys)
+        {
+            if (payload.TryGetValue(handleKey, out var value))
+            {
+                var handle = value?.ToString();
+                if (!string.IsNullOrEmpty(handle) && handle != "0")
+                {
+                    return handle;
+                }
+            }
+        }
+
+        return null;
+    }
+
+    private sealed class FileSession
+    {
+        public FileSession(Guid correlationId, string? filePath)
+        {
+            CorrelationId = correlationId;
+            FilePath = filePath;
+        }
+
+        public Guid CorrelationId { get; set; }
+
+        public string? FilePath { get; set; }
+
+        public bool IsWritten { get; set; }
+    }
+}
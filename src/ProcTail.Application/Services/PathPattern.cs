@@ -0,0 +1,162 @@
+using System.Collections.Concurrent;
+using System.Text;
+using System.Text.RegularExpressions;
+
+namespace ProcTail.Application.Services;
+
+/// <summary>
+/// パスのglob/正規表現パターン照合
+/// </summary>
+/// <remarks>
+/// "**" は区切り文字を含む任意の文字列、"*" は区切り文字以外の任意の文字列、"?" は区切り文字以外の1文字に一致する。
+/// 区切り文字は "/" と "\" を区別しない。"regex:" で始まるパターンは残りを正規表現として部分一致で照合する。
+/// Linux以外では大文字小文字を区別しない。
+/// </remarks>
+public static class PathPattern
+{
+    private const string RegexPrefix = "regex:";
+
+    private static readonly ConcurrentDictionary<string, Regex> _compiled = new();
+
+    /// <summary>
+    /// パスがパターンに一致するかどうか
+    /// </summary>
+    /// <param name="pattern">globパターン、または "regex:" で始まる正規表現</param>
+    /// <param name="path">パス</param>
+    /// <returns>一致する場合true</returns>
+    public static bool IsMatch(string pattern, string path)
+    {
+        if (string.IsNullOrEmpty(pattern) || string.IsNullOrEmpty(path))
+        {
+            return false;
+        }
+
+        return _compiled.GetOrAdd(pattern, Compile).IsMatch(path);
+    }
+
+    /// <summary>
+    /// パターンが有効かどうか（正規表現の構文エラーがないか）
+    /// </summary>
+    /// <param name="pattern">globパターン、または "regex:" で始まる正規表現</param>
+    /// <returns>有効な場合true</returns>
+    public static bool IsValid(string pattern)
+    {
+        if (string.IsNullOrEmpty(pattern))
+        {
+            return false;
+        }
+
+        try
+        {
+            _compiled.GetOrAdd(pattern, Compile);
+            return true;
+        }
+        catch (ArgumentException)
+        {
+            return false;
+        }
+    }
+
+    /// <summary>
+    /// パスがディレクトリ自身またはその配下かどうか（区切り文字は "/" と "\" を区別しない）
+    /// </summary>
+    /// <param name="directory">ディレクトリ</param>
+    /// <param name="path">パス</param>
+    /// <returns>ディレクトリ自身または配下の場合true</returns>
+    public static bool IsUnderDirectory(string directory, string path)
+    {
+        if (string.IsNullOrEmpty(directory) || string.IsNullOrEmpty(path))
+        {
+            return false;
+        }
+
+        var length = directory.Length;
+        while (length > 0 && IsSeparator(directory[length - 1]))
+        {
+            length--;
+        }
+
+        // ルートディレクトリ（"/"）は全ての絶対パスを含む
+        if (length == 0)
+        {
+            return IsSeparator(path[0]);
+        }
+
+        if (path.Length < length)
+        {
+            return false;
+        }
+
+        var ignoreCase = !OperatingSystem.IsLinux();
+        for (var i = 0; i < length; i++)
+        {
+            var expected = directory[i];
+            var actual = path[i];
+            if (IsSeparator(expected) && IsSeparator(actual))
+            {
+                continue;
+            }
+
+            if (ignoreCase ? char.ToUpperInvariant(expected) != char.ToUpperInvariant(actual) : expected != actual)
+            {
+                return false;
+            }
+        }
+
+        return path.Length == length || IsSeparator(path[length]);
+    }
+
+    /// <summary>
+    /// パターンに区切り文字が含まれるかどうか（ファイル名ではなくパス全体と照合するパターンか）
+    /// </summary>
+    public static bool HasPathSyntax(string pattern)
+    {
+        return pattern.IndexOfAny(new[] { '/', '\\' }) >= 0;
+    }
+
+    private static bool IsSeparator(char c) => c is '/' or '\\';
+
+    private static Regex Compile(string pattern)
+    {
+        var options = RegexOptions.CultureInvariant;
+        if (!OperatingSystem.IsLinux())
+        {
+            options |= RegexOptions.IgnoreCase;
+        }
+
+        return pattern.StartsWith(RegexPrefix, StringComparison.Ordinal)
+            ? new Regex(pattern[RegexPrefix.Length..], options)
+            : new Regex(GlobToRegex(pattern), options);
+    }
+
+    private static string GlobToRegex(string pattern)
+    {
+        var builder = new StringBuilder("^");
+        for (var i = 0; i < pattern.Length; i++)
+        {
+            var c = pattern[i];
+            switch (c)
+            {
+                case '*' when i + 1 < pattern.Length && pattern[i + 1] == '*':
+                    builder.Append(".*");
+                    i++;
+                    break;
+                case '*':
+                    builder.Append(@"[^/\\]*");
+                    break;
+                case '?':
+                    builder.Append(@"[^/\\]");
+                    break;
+                case '/':
+                case '\\':
+                    builder.Append(@"[/\\]");
+                    break;
+                default:
+                    builder.Append(Regex.Escape(c.ToString()));
+                    break;
+            }
+        }
+
+        return builder.Append('$').ToString();
+    }
+}
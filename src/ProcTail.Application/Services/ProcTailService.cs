@@ -7,7 +7,7 @@ namespace ProcTail.Application.Services;
 /// <summary>
 /// ProcTailメインサービス - 全体のワークフローを統合
 /// </summary>
-public class ProcTailService : IProcTailService, IDisposable
+public class ProcTailService : IProcTailService, IIpcRequestHandler, IDisposable
 {
     private readonly ILogger<ProcTailService> _logger;
     private readonly IEtwEventProvider _etwProvider;
@@ -15,10 +15,27 @@ public class ProcTailService : IProcTailService, IDisposable
     private readonly IEventProcessor _eventProcessor;
     private readonly IEventStorage _eventStorage;
     private readonly INamedPipeServer _pipeServer;
+    private readonly IStartupDiagnostics? _startupDiagnostics;
+    private readonly IDirectoryChangeWatcher? _directoryWatcher;
+    private readonly AlertRuleEngine? _alertRuleEngine;
+    private readonly IAlertNotifier? _alertNotifier;
+    private readonly ResourceSnapshotSampler? _resourceSnapshotSampler;
+    private readonly TagActivityTracker? _tagActivityTracker;
+    private readonly IForegroundWindowMonitor? _foregroundWindowMonitor;
+    private readonly ProcessHangMonitor? _processHangMonitor;
+    private readonly EventSubscriptionHub? _eventSubscriptionHub;
+    private readonly ClientAuthorizer? _clientAuthorizer;
     private readonly CancellationTokenSource _cancellationTokenSource = new();
+    private readonly WriteCoalescer _writeCoalescer = new();
+    private readonly RawEventChannel _rawEventChannel = new();
+    private Timer? _coalescingFlushTimer;
+    private Timer? _resourceSnapshotTimer;
+    private Timer? _idleCheckTimer;
+    private Timer? _hangCheckTimer;
     private bool _isRunning;
     private bool _disposed;
     private ServiceStatus _status = ServiceStatus.Stopped;
+    private IReadOnlyList<DiagnosticCheckResult> _diagnostics = Array.Empty<DiagnosticCheckResult>();
 
     /// <summary>
     /// サービスが実行中かどうか
@@ -30,6 +47,11 @@ public class ProcTailService : IProcTailService, IDisposable
     /// </summary>
     public ServiceStatus Status => _status;
 
+    /// <summary>
+    /// 起動時診断の結果
+    /// </summary>
+    public IReadOnlyList<DiagnosticCheckResult> Diagnostics => _diagnostics;
+
     /// <summary>
     /// 状態変更イベント
     /// </summary>
@@ -46,7 +68,17 @@ public class ProcTailService : IProcTailService, IDisposable
         IWatchTargetManager watchTargetManager,
         IEventProcessor eventProcessor,
         IEventStorage eventStorage,
-        INamedPipeServer pipeServer)
+        INamedPipeServer pipeServer,
+        IStartupDiagnostics? startupDiagnostics = null,
+        IDirectoryChangeWatcher? directoryWatcher = null,
+        AlertRuleEngine? alertRuleEngine = null,
+        IAlertNotifier? alertNotifier = null,
+        ResourceSnapshotSampler? resourceSnapshotSampler = null,
+        TagActivityTracker? tagActivityTracker = null,
+        IForegroundWindowMonitor? foregroundWindowMonitor = null,
+        ProcessHangMonitor? processHangMonitor = null,
+        EventSubscriptionHub? eventSubscriptionHub = null,
+        ClientAuthorizer? clientAuthorizer = null)
     {
         _logger = logger ?? throw new ArgumentNullException(nameof(logger));
         _etwProvider = etwProvider ?? throw new ArgumentNullException(nameof(etwProvider));
@@ -54,6 +86,16 @@ public class ProcTailService : IProcTailService, IDisposable
         _eventProcessor = eventProcessor ?? throw new ArgumentNullException(nameof(eventProcessor));
         _eventStorage = eventStorage ?? throw new ArgumentNullException(nameof(eventStorage));
         _pipeServer = pipeServer ?? throw new ArgumentNullException(nameof(pipeServer));
+        _startupDiagnostics = startupDiagnostics;
+        _directoryWatcher = directoryWatcher;
+        _alertRuleEngine = alertRuleEngine;
+        _alertNotifier = alertNotifier;
+        _resourceSnapshotSampler = resourceSnapshotSampler;
+        _tagActivityTracker = tagActivityTracker;
+        _foregroundWindowMonitor = foregroundWindowMonitor;
+        _processHangMonitor = processHangMonitor;
+        _eventSubscriptionHub = eventSubscriptionHub;
+        _clientAuthorizer = clientAuthorizer;
     }
 
     /// <summary>
@@ -74,9 +116,34 @@ public class ProcTailService : IProcTailService, IDisposable
         {
             _logger.LogInformation("=== ProcTailServiceを開始しています ===");
 
+            // 権限・前提条件の診断（パイプ名の使用状況を見るためNamed Pipeサーバーの開始前に行う）
+            RunStartupDiagnostics();
+
             // ETWイベント処理の設定
             _logger.LogInformation("ETWイベントハンドラーを設定中...");
             _etwProvider.EventReceived += OnEtwEventReceived;
+            if (_directoryWatcher != null)
+            {
+                // ディレクトリ監視のイベントもETWのイベントと同じ経路で処理する
+                _directoryWatcher.EventReceived += OnEtwEventReceived;
+            }
+            if (_foregroundWindowMonitor != null)
+            {
+                // フォアグラウンドの変化もETWのイベントと同じ経路で監視対象プロセスに絞り込む
+                _foregroundWindowMonitor.EventReceived += OnEtwEventReceived;
+            }
+            if (_eventProcessor is IFollowUpEventSource followUpEventSource)
+            {
+                // クラッシュダンプの検出など、変換後に遅れて作成されたイベントも同じストレージに保存する
+                followUpEventSource.FollowUpEventCreated += OnFollowUpEventCreated;
+            }
+            if (_etwProvider is IRawEventSource { IsRawPassthroughEnabled: true } rawEventSource)
+            {
+                // 生イベントは監視対象プロセスのものだけをプロバイダー側で絞り込む
+                rawEventSource.RawRecordFilter = _watchTargetManager.IsWatchedProcess;
+                rawEventSource.RawRecordReceived += OnRawRecordReceived;
+                _logger.LogInformation("生イベントのパススルーを有効にしました");
+            }
             _logger.LogInformation("ETWイベントハンドラーの設定が完了しました");
 
             // Named Pipeサーバーの要求処理の設定
@@ -89,12 +156,35 @@ public class ProcTailService : IProcTailService, IDisposable
             await _etwProvider.StartMonitoringAsync(cancellationToken);
             _logger.LogInformation("ETW監視を開始しました (IsMonitoring: {IsMonitoring})", _etwProvider.IsMonitoring);
 
+            _foregroundWindowMonitor?.Start();
+
             // Named Pipeサーバーを開始
             _logger.LogInformation("Named Pipeサーバーを開始中...");
             await _pipeServer.StartAsync(cancellationToken);
             _logger.LogInformation("Named Pipeサーバーを開始しました (IsRunning: {IsRunning}, PipeName: {PipeName})", 
                 _pipeServer.IsRunning, _pipeServer.PipeName);
 
+            // 集約ウィンドウが経過した書き込みを定期的に保存
+            _coalescingFlushTimer = new Timer(FlushCoalescedWrites, null, TimeSpan.FromMilliseconds(100), TimeSpan.FromMilliseconds(100));
+
+            // 間隔が経過したタグのリソース使用量を定期的に保存
+            if (_resourceSnapshotSampler != null)
+            {
+                _resourceSnapshotTimer = new Timer(StoreResourceSnapshots, null, TimeSpan.Zero, TimeSpan.FromMilliseconds(250));
+            }
+
+            // 無操作時間が経過したタグの無操作イベントを定期的に保存
+            if (_tagActivityTracker != null)
+            {
+                _idleCheckTimer = new Timer(StoreIdleEvents, null, TimeSpan.Zero, TimeSpan.FromMilliseconds(250));
+            }
+
+            // 応答なしの検出を有効にしたタグのプロセスのウィンドウを定期的に確認
+            if (_processHangMonitor != null)
+            {
+                _hangCheckTimer = new Timer(StoreHangEvents, null, TimeSpan.Zero, TimeSpan.FromSeconds(1));
+            }
+
             _isRunning = true;
             _logger.LogInformation("=== ProcTailServiceが正常に開始されました ===");
         }
@@ -106,6 +196,38 @@ public class ProcTailService : IProcTailService, IDisposable
         }
     }
 
+    /// <summary>
+    /// 起動時診断を実行して結果をログに出力
+    /// </summary>
+    private void RunStartupDiagnostics()
+    {
+        if (_startupDiagnostics == null)
+        {
+            return;
+        }
+
+        try
+        {
+            _diagnostics = _startupDiagnostics.Run();
+        }
+        catch (Exception ex)
+        {
+            _logger.LogWarning(ex, "起動時診断を実行できませんでした");
+            return;
+        }
+
+        foreach (var result in _diagnostics)
+        {
+            var level = result.Status switch
+            {
+                DiagnosticStatus.Failed => LogLevel.Error,
+                DiagnosticStatus.Warning => LogLevel.Warning,
+                _ => LogLevel.Information
+            };
+            _logger.Log(level, "起動時診断 {Check}: {Status} - {Message}", result.Name, result.Status, result.Message);
+        }
+    }
+
     /// <summary>
     /// サービス全体を停止
     /// </summary>
@@ -122,6 +244,20 @@ public class ProcTailService : IProcTailService, IDisposable
 
             _cancellationTokenSource.Cancel();
 
+            // 保留中の集約した書き込みを保存
+            _coalescingFlushTimer?.Dispose();
+            _coalescingFlushTimer = null;
+            _resourceSnapshotTimer?.Dispose();
+            _resourceSnapshotTimer = null;
+            _idleCheckTimer?.Dispose();
+            _idleCheckTimer = null;
+            _hangCheckTimer?.Dispose();
+            _hangCheckTimer = null;
+            await StoreEventsAsync(_writeCoalescer.FlushAll());
+
+            // 保存し終えたイベントまでで購読を終了
+            _eventSubscriptionHub?.CompleteAll();
+
             // ETW監視を停止
             if (_etwProvider.IsMonitoring)
             {
@@ -129,6 +265,28 @@ public class ProcTailService : IProcTailService, IDisposable
                 _logger.LogInformation("ETW監視を停止しました");
             }
 
+            if (_etwProvider is IRawEventSource rawEventSource)
+            {
+                rawEventSource.RawRecordReceived -= OnRawRecordReceived;
+                rawEventSource.RawRecordFilter = null;
+            }
+
+            if (_directoryWatcher != null)
+            {
+                _directoryWatcher.EventReceived -= OnEtwEventReceived;
+            }
+
+            if (_eventProcessor is IFollowUpEventSource followUpEventSource)
+            {
+                followUpEventSource.FollowUpEventCreated -= OnFollowUpEventCreated;
+            }
+
+            if (_foregroundWindowMonitor != null)
+            {
+                _foregroundWindowMonitor.Stop();
+                _foregroundWindowMonitor.EventReceived -= OnEtwEventReceived;
+            }
+
             // Named Pipeサーバーを停止
             if (_pipeServer.IsRunning)
             {
@@ -149,7 +307,19 @@ public class ProcTailService : IProcTailService, IDisposable
     /// <summary>
     /// 監視対象を追加
     /// </summary>
-    public async Task<bool> AddWatchTargetAsync(int processId, string tagName, CancellationToken cancellationToken = default)
+    public Task<bool> AddWatchTargetAsync(int processId, string tagName, CancellationToken cancellationToken = default)
+    {
+        return AddWatchTargetAsync(processId, tagName, null, cancellationToken);
+    }
+
+    /// <summary>
+    /// 監視対象をタグのオプション付きで追加
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="options">タグの監視オプション（nullの場合は既存のオプションを維持）</param>
+    /// <param name="cancellationToken">キャンセレーショントークン</param>
+    public async Task<bool> AddWatchTargetAsync(int processId, string tagName, WatchTargetOptions? options, CancellationToken cancellationToken = default)
     {
         if (!_isRunning)
         {
@@ -159,9 +329,13 @@ public class ProcTailService : IProcTailService, IDisposable
 
         try
         {
-            var result = await _watchTargetManager.AddTargetAsync(processId, tagName);
+            var result = options == null
+                ? await _watchTargetManager.AddTargetAsync(processId, tagName)
+                : await _watchTargetManager.AddTargetAsync(processId, tagName, options);
             if (result)
             {
+                ApplyBackpressurePolicy(tagName, options);
+                ApplyFileEventBackend(tagName, options);
                 _logger.LogInformation("監視対象を追加しました (ProcessId: {ProcessId}, Tag: {TagName})", processId, tagName);
             }
             return result;
@@ -173,6 +347,92 @@ public class ProcTailService : IProcTailService, IDisposable
         }
     }
 
+    /// <summary>
+    /// ディレクトリ配下の実行ファイルから起動したプロセスを監視対象に追加
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="directory">インストールディレクトリ</param>
+    /// <param name="options">タグの監視オプション（nullの場合は既存のオプションを維持）</param>
+    /// <param name="cancellationToken">キャンセレーショントークン</param>
+    /// <returns>登録時点で実行中だったため追加されたプロセス数（登録に失敗した場合はnull）</returns>
+    public async Task<int?> AddWatchTargetByPathAsync(string tagName, string directory, WatchTargetOptions? options = null, CancellationToken cancellationToken = default)
+    {
+        if (!_isRunning)
+        {
+            _logger.LogWarning("サービスが実行中ではありません");
+            return null;
+        }
+
+        try
+        {
+            var addedCount = await _watchTargetManager.AddPathTargetAsync(tagName, directory, options);
+            ApplyBackpressurePolicy(tagName, options);
+            ApplyFileEventBackend(tagName, options);
+            _logger.LogInformation("ディレクトリ監視を追加しました (Directory: {Directory}, Tag: {TagName}, AddedCount: {AddedCount})",
+                directory, tagName, addedCount);
+            return addedCount;
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "ディレクトリ監視追加中にエラーが発生しました (Directory: {Directory}, Tag: {TagName})", directory, tagName);
+            return null;
+        }
+    }
+
+    /// <summary>
+    /// タグの監視対象を全て削除し、タグのディレクトリ監視も停止
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="cancellationToken">キャンセレーショントークン</param>
+    /// <returns>削除した監視対象数</returns>
+    public async Task<int> RemoveWatchTargetAsync(string tagName, CancellationToken cancellationToken = default)
+    {
+        var removedCount = await _watchTargetManager.RemoveWatchTargetsByTagAsync(tagName, cancellationToken);
+        _directoryWatcher?.Unwatch(tagName);
+
+        _logger.LogInformation("監視対象を削除しました (Tag: {TagName}, RemovedCount: {RemovedCount})", tagName, removedCount);
+        return removedCount;
+    }
+
+    private void ApplyBackpressurePolicy(string tagName, WatchTargetOptions? options)
+    {
+        if (options != null)
+        {
+            _eventStorage.SetBackpressurePolicy(tagName, options.BackpressurePolicy, TimeSpan.FromMilliseconds(options.BlockTimeoutMs));
+        }
+    }
+
+    /// <summary>
+    /// タグのファイルイベントの取得元に合わせてディレクトリ監視を開始・停止
+    /// </summary>
+    private void ApplyFileEventBackend(string tagName, WatchTargetOptions? options)
+    {
+        if (options == null)
+        {
+            return;
+        }
+
+        if (options.Backend == FileEventBackend.Etw)
+        {
+            _directoryWatcher?.Unwatch(tagName);
+            return;
+        }
+
+        if (_directoryWatcher == null)
+        {
+            _logger.LogWarning("ディレクトリ監視を利用できないため、ファイルイベントは記録されません (Tag: {TagName})", tagName);
+            return;
+        }
+
+        var watchedCount = options.Backend == FileEventBackend.Polling
+            ? _directoryWatcher.WatchByPolling(tagName, options.WatchDirectories, TimeSpan.FromMilliseconds(options.PollIntervalMs))
+            : _directoryWatcher.Watch(tagName, options.WatchDirectories);
+        if (watchedCount == 0)
+        {
+            _logger.LogWarning("監視できるディレクトリがないため、ファイルイベントは記録されません (Tag: {TagName})", tagName);
+        }
+    }
+
     /// <summary>
     /// 記録されたイベントを取得
     /// </summary>
@@ -189,6 +449,74 @@ public class ProcTailService : IProcTailService, IDisposable
         }
     }
 
+    /// <summary>
+    /// 記録されたイベントを絞り込み条件とカーソルで取得
+    /// </summary>
+    /// <remarks>
+    /// カーソルより後のイベントを記録順にLimit件まで返し、続きを取得するカーソルを応答に含める。
+    /// 欠落は絞り込む前の連番で求める（絞り込みで除いたイベントを欠落としない）。
+    /// </remarks>
+    public async Task<GetRecordedEventsResponse> QueryRecordedEventsAsync(GetRecordedEventsRequest request, CancellationToken cancellationToken = default)
+    {
+        var filter = request.Filter;
+        var filterError = filter == null ? null : EventFilterMatcher.Validate(filter);
+        if (filterError != null)
+        {
+            return new GetRecordedEventsResponse { Success = false, ErrorMessage = filterError };
+        }
+
+        var afterSequenceNumber = 0L;
+        if (!string.IsNullOrEmpty(request.Cursor))
+        {
+            if (!EventCursor.TryDecode(request.Cursor, out var cursor) || cursor.TagName != request.TagName)
+            {
+                return new GetRecordedEventsResponse { Success = false, ErrorMessage = "Invalid cursor" };
+            }
+            afterSequenceNumber = cursor.SequenceNumber;
+        }
+
+        var events = await GetRecordedEventsAsync(request.TagName, cancellationToken);
+
+        // 連番は戻らないため、カーソルより新しい記録がないのにカーソルより前の連番しかない場合は再起動で振り直されている
+        if (afterSequenceNumber > 0 && events.Count > 0 && events[^1].SequenceNumber < afterSequenceNumber)
+        {
+            return new GetRecordedEventsResponse { Success = false, ErrorMessage = "Cursor is no longer valid (events were renumbered after a service restart)" };
+        }
+
+        var limit = request.Limit is > 0 ? request.Limit.Value : int.MaxValue;
+        var page = new List<BaseEventData>();
+        var scanned = new List<BaseEventData>();
+        var hasMore = false;
+        foreach (var eventData in events)
+        {
+            if (eventData.SequenceNumber <= afterSequenceNumber)
+            {
+                continue;
+            }
+
+            if (filter == null || EventFilterMatcher.IsMatch(filter, eventData))
+            {
+                if (page.Count == limit)
+                {
+                    hasMore = true;
+                    break;
+                }
+                page.Add(eventData);
+            }
+            scanned.Add(eventData);
+        }
+
+        // 一致しなかったイベントも読み終えたものとしてカーソルを進める
+        var lastSequenceNumber = scanned.Count > 0 ? scanned[^1].SequenceNumber : afterSequenceNumber;
+        return new GetRecordedEventsResponse(page)
+        {
+            Success = true,
+            Gaps = SequenceGap.Find(scanned, afterSequenceNumber),
+            NextCursor = new EventCursor(request.TagName, lastSequenceNumber).Encode(),
+            HasMore = hasMore
+        };
+    }
+
     /// <summary>
     /// ストレージ統計情報を取得
     /// </summary>
@@ -205,6 +533,24 @@ public class ProcTailService : IProcTailService, IDisposable
         }
     }
 
+    /// <summary>
+    /// 生ETWイベント受信時の処理
+    /// </summary>
+    private void OnRawRecordReceived(object? sender, RawEtwRecord record)
+    {
+        try
+        {
+            foreach (var tagName in _watchTargetManager.GetTagsForProcess(record.ProcessId))
+            {
+                _rawEventChannel.Add(tagName, record);
+            }
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "生イベント処理中にエラーが発生しました (Provider: {Provider}, Event: {Event})", record.ProviderName, record.EventName);
+        }
+    }
+
     /// <summary>
     /// ETWイベント受信時の処理
     /// </summary>
@@ -227,15 +573,19 @@ public class ProcTailService : IProcTailService, IDisposable
             // イベントを処理してドメインイベントに変換
             var processingResult = await _eventProcessor.ProcessEventAsync(rawEvent);
             
-            if (processingResult.Success && processingResult.EventData != null)
+            if (!processingResult.Success)
+                return;
+
+            // 変換されたイベントをタグごとにストレージに保存（連続した書き込みはタグの設定に従って集約）
+            foreach (var eventData in processingResult.Events)
             {
-                // 変換されたイベントをストレージに保存
-                await _eventStorage.StoreEventAsync(processingResult.EventData.TagName, processingResult.EventData);
+                var coalescingWindowMs = _watchTargetManager.GetOptionsForTag(eventData.TagName)?.WriteCoalescingWindowMs ?? 0;
+                await StoreEventsAsync(_writeCoalescer.Add(eventData, TimeSpan.FromMilliseconds(coalescingWindowMs)));
                 
                 _logger.LogDebug("イベントを処理・保存しました (Type: {EventType}, ProcessId: {ProcessId}, Tag: {Tag})",
-                    processingResult.EventData.GetType().Name, 
-                    processingResult.EventData.ProcessId, 
-                    processingResult.EventData.TagName);
+                    eventData.GetType().Name, 
+                    eventData.ProcessId, 
+                    eventData.TagName);
             }
         }
         catch (Exception ex)
@@ -245,6 +595,129 @@ public class ProcTailService : IProcTailService, IDisposable
         }
     }
 
+    /// <summary>
+    /// 変換後に遅れて作成されたイベントの保存
+    /// </summary>
+    private async void OnFollowUpEventCreated(object? sender, BaseEventData eventData)
+    {
+        if (!IsRunning)
+            return;
+
+        try
+        {
+            await StoreEventsAsync(new[] { eventData });
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "遅れて作成されたイベントの保存中にエラーが発生しました (Event: {Event}, ProcessId: {ProcessId})",
+                eventData.EventName, eventData.ProcessId);
+        }
+    }
+
+    /// <summary>
+    /// 集約ウィンドウが経過した書き込みを保存
+    /// </summary>
+    private async void FlushCoalescedWrites(object? state)
+    {
+        try
+        {
+            await StoreEventsAsync(_writeCoalescer.FlushExpired(DateTime.UtcNow));
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "集約した書き込みイベントの保存中にエラーが発生しました");
+        }
+    }
+
+    /// <summary>
+    /// 間隔が経過したタグのリソース使用量のスナップショットを保存
+    /// </summary>
+    private async void StoreResourceSnapshots(object? state)
+    {
+        try
+        {
+            await StoreEventsAsync(_resourceSnapshotSampler!.Collect(DateTime.UtcNow));
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "リソース使用量のスナップショットの保存中にエラーが発生しました");
+        }
+    }
+
+    /// <summary>
+    /// 無操作時間が経過したタグの無操作イベントを保存
+    /// </summary>
+    private async void StoreIdleEvents(object? state)
+    {
+        try
+        {
+            await StoreEventsAsync(_tagActivityTracker!.Collect(DateTime.UtcNow));
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "無操作イベントの保存中にエラーが発生しました");
+        }
+    }
+
+    /// <summary>
+    /// 監視対象プロセスのウィンドウの応答状態の変化を保存
+    /// </summary>
+    private async void StoreHangEvents(object? state)
+    {
+        try
+        {
+            await StoreEventsAsync(_processHangMonitor!.Collect(DateTime.UtcNow));
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "応答状態イベントの保存中にエラーが発生しました");
+        }
+    }
+
+    private async Task StoreEventsAsync(IReadOnlyList<BaseEventData> events)
+    {
+        foreach (var eventData in events)
+        {
+            // 無操作状態から復帰した場合は、復帰のきっかけになったイベントより先に復帰イベントを保存
+            var activityEvent = _tagActivityTracker?.RecordActivity(eventData, DateTime.UtcNow);
+            if (activityEvent != null)
+            {
+                await StoreEventAsync(activityEvent);
+            }
+
+            await StoreEventAsync(eventData);
+        }
+    }
+
+    private async Task StoreEventAsync(BaseEventData eventData)
+    {
+        // 配信は記録して連番が付いたイベントのみ（満杯で破棄したイベントは配信しない）
+        var stored = await _eventStorage.StoreEventAsync(eventData.TagName, eventData);
+        if (stored != null)
+        {
+            _eventSubscriptionHub?.Publish(stored);
+        }
+        EvaluateAlertRules(stored ?? eventData);
+    }
+
+    /// <summary>
+    /// 記録したイベントをアラートルールと照合
+    /// </summary>
+    private void EvaluateAlertRules(BaseEventData eventData)
+    {
+        if (_alertRuleEngine == null)
+        {
+            return;
+        }
+
+        foreach (var alert in _alertRuleEngine.Evaluate(eventData))
+        {
+            _logger.LogWarning("アラートを生成しました (Rule: {RuleName}, Tag: {TagName}, Event: {EventName}, MatchCount: {MatchCount})",
+                alert.RuleName, alert.TagName, alert.Event.EventName, alert.MatchCount);
+            _alertNotifier?.Notify(alert);
+        }
+    }
+
     /// <summary>
     /// Named Pipe要求受信時の処理
     /// </summary>
@@ -283,6 +756,35 @@ public class ProcTailService : IProcTailService, IDisposable
         }
     }
 
+    /// <summary>
+    /// サポートされる要求タイプ
+    /// </summary>
+    public IReadOnlyList<Type> SupportedRequestTypes { get; } = new[]
+    {
+        typeof(AddWatchTargetRequest),
+        typeof(AddWatchTargetByPathRequest),
+        typeof(RemoveWatchTargetRequest),
+        typeof(GetWatchTargetsRequest),
+        typeof(GetRecordedEventsRequest),
+        typeof(GetRawEventsRequest),
+        typeof(GetAlertsRequest),
+        typeof(GetSaveDirCandidatesRequest),
+        typeof(GetEventSummaryRequest),
+        typeof(ClearEventsRequest),
+        typeof(ShutdownRequest)
+    };
+
+    /// <summary>
+    /// Named Pipe以外（HTTP APIなど）で受けた要求をNamed Pipeの要求と同じ処理で処理
+    /// </summary>
+    /// <param name="requestJson">要求JSON（Named Pipeと同じ形式）</param>
+    /// <param name="cancellationToken">キャンセレーショントークン</param>
+    /// <returns>応答JSON</returns>
+    public Task<string> HandleRequestAsync(string requestJson, CancellationToken cancellationToken = default)
+    {
+        return ProcessIpcRequestAsync(requestJson, cancellationToken);
+    }
+
     /// <summary>
     /// IPC要求を処理
     /// </summary>
@@ -293,13 +795,44 @@ public class ProcTailService : IProcTailService, IDisposable
             using var jsonDocument = System.Text.Json.JsonDocument.Parse(requestJson);
             var requestType = jsonDocument.RootElement.GetProperty("RequestType").GetString();
 
+            // トークンが設定されている場合は、要求の種類とタグがトークンに許可されているか確認
+            Func<string, bool> isTagAllowed = _ => true;
+            if (_clientAuthorizer?.IsEnabled == true)
+            {
+                // AuthTokenはトークンが設定されていない場合は省略可能（旧クライアント互換）
+                var authToken = jsonDocument.RootElement.TryGetProperty("AuthToken", out var authTokenElement) &&
+                    authTokenElement.ValueKind == System.Text.Json.JsonValueKind.String ? authTokenElement.GetString() : null;
+                var client = _clientAuthorizer.Authenticate(authToken);
+                if (client == null)
+                {
+                    return CreateErrorResponse(ClientAuthorizer.UnauthorizedMessage);
+                }
+
+                var tagName = jsonDocument.RootElement.TryGetProperty("TagName", out var tagElement) &&
+                    tagElement.ValueKind == System.Text.Json.JsonValueKind.String ? tagElement.GetString() : null;
+                if (!ClientAuthorizer.IsAllowed(client, ClientAuthorizer.GetRequiredPermission(requestType), tagName))
+                {
+                    _logger.LogWarning("トークンに許可されていない要求を拒否しました (Token: {Name}, RequestType: {RequestType}, Tag: {TagName})",
+                        client.Name, requestType, tagName);
+                    return CreateErrorResponse(ClientAuthorizer.CreateForbiddenMessage(client, requestType, tagName));
+                }
+
+                // タグを指定しない取得は、トークンが操作できるタグのみ返す
+                isTagAllowed = tag => ClientAuthorizer.IsTagAllowed(client, tag);
+            }
+
             return requestType switch
             {
                 "AddWatchTarget" => await ProcessAddWatchTargetRequestAsync(jsonDocument, cancellationToken),
+                "AddWatchTargetByPath" => await ProcessAddWatchTargetByPathRequestAsync(jsonDocument, cancellationToken),
                 "RemoveWatchTarget" => await ProcessRemoveWatchTargetRequestAsync(jsonDocument, cancellationToken),
-                "GetWatchTargets" => await ProcessGetWatchTargetsRequestAsync(cancellationToken),
+                "GetWatchTargets" => await ProcessGetWatchTargetsRequestAsync(isTagAllowed, cancellationToken),
                 "GetRecordedEvents" => await ProcessGetRecordedEventsRequestAsync(jsonDocument, cancellationToken),
-                "GetStatus" => await ProcessGetStatusRequestAsync(cancellationToken),
+                "GetRawEvents" => ProcessGetRawEventsRequest(jsonDocument),
+                "GetAlerts" => ProcessGetAlertsRequest(jsonDocument, isTagAllowed),
+                "GetSaveDirCandidates" => await ProcessGetSaveDirCandidatesRequestAsync(jsonDocument, cancellationToken),
+                "GetEventSummary" => await ProcessGetEventSummaryRequestAsync(jsonDocument, cancellationToken),
+                "GetStatus" => await ProcessGetStatusRequestAsync(isTagAllowed, cancellationToken),
                 "ClearEvents" => await ProcessClearEventsRequestAsync(jsonDocument, cancellationToken),
                 "Shutdown" => await ProcessShutdownRequestAsync(cancellationToken),
                 _ => CreateErrorResponse($"Unknown request type: {requestType}")
@@ -319,7 +852,15 @@ public class ProcTailService : IProcTailService, IDisposable
             var processId = request.RootElement.GetProperty("ProcessId").GetInt32();
             var tagName = request.RootElement.GetProperty("TagName").GetString() ?? string.Empty;
 
-            var success = await AddWatchTargetAsync(processId, tagName, cancellationToken);
+            // Optionsは省略可能（旧クライアント互換）
+            WatchTargetOptions? options = null;
+            if (request.RootElement.TryGetProperty("Options", out var optionsElement) &&
+                optionsElement.ValueKind == System.Text.Json.JsonValueKind.Object)
+            {
+                options = System.Text.Json.JsonSerializer.Deserialize<WatchTargetOptions>(optionsElement);
+            }
+
+            var success = await AddWatchTargetAsync(processId, tagName, options, cancellationToken);
 
             var response = new AddWatchTargetResponse
             {
@@ -335,13 +876,44 @@ public class ProcTailService : IProcTailService, IDisposable
         }
     }
 
+    private async Task<string> ProcessAddWatchTargetByPathRequestAsync(System.Text.Json.JsonDocument request, CancellationToken cancellationToken)
+    {
+        try
+        {
+            var tagName = request.RootElement.GetProperty("TagName").GetString() ?? string.Empty;
+            var directory = request.RootElement.GetProperty("Directory").GetString() ?? string.Empty;
+
+            WatchTargetOptions? options = null;
+            if (request.RootElement.TryGetProperty("Options", out var optionsElement) &&
+                optionsElement.ValueKind == System.Text.Json.JsonValueKind.Object)
+            {
+                options = System.Text.Json.JsonSerializer.Deserialize<WatchTargetOptions>(optionsElement);
+            }
+
+            var addedCount = await AddWatchTargetByPathAsync(tagName, directory, options, cancellationToken);
+
+            var response = new AddWatchTargetByPathResponse
+            {
+                Success = addedCount.HasValue,
+                AddedCount = addedCount ?? 0,
+                ErrorMessage = addedCount.HasValue ? string.Empty : $"Failed to add watch directory: {directory}"
+            };
+
+            return System.Text.Json.JsonSerializer.Serialize(response);
+        }
+        catch (Exception ex)
+        {
+            return CreateErrorResponse($"AddWatchTargetByPath error: {ex.Message}");
+        }
+    }
+
     private async Task<string> ProcessRemoveWatchTargetRequestAsync(System.Text.Json.JsonDocument request, CancellationToken cancellationToken)
     {
         try
         {
             var tagName = request.RootElement.GetProperty("TagName").GetString() ?? string.Empty;
 
-            var removedCount = await _watchTargetManager.RemoveWatchTargetsByTagAsync(tagName, cancellationToken);
+            var removedCount = await RemoveWatchTargetAsync(tagName, cancellationToken);
 
             var response = new RemoveWatchTargetResponse
             {
@@ -349,8 +921,6 @@ public class ProcTailService : IProcTailService, IDisposable
                 ErrorMessage = removedCount > 0 ? string.Empty : $"No watch targets found for tag: {tagName}"
             };
 
-            _logger.LogInformation("監視対象を削除しました (Tag: {TagName}, RemovedCount: {RemovedCount})", tagName, removedCount);
-
             return System.Text.Json.JsonSerializer.Serialize(response);
         }
         catch (Exception ex)
@@ -359,13 +929,13 @@ public class ProcTailService : IProcTailService, IDisposable
         }
     }
 
-    private async Task<string> ProcessGetWatchTargetsRequestAsync(CancellationToken cancellationToken)
+    private async Task<string> ProcessGetWatchTargetsRequestAsync(Func<string, bool> isTagAllowed, CancellationToken cancellationToken)
     {
         try
         {
             var watchTargets = await _watchTargetManager.GetWatchTargetInfosAsync();
 
-            var response = new GetWatchTargetsResponse(watchTargets)
+            var response = new GetWatchTargetsResponse(watchTargets.Where(target => isTagAllowed(target.TagName)).ToList())
             {
                 Success = true
             };
@@ -383,9 +953,122 @@ public class ProcTailService : IProcTailService, IDisposable
         try
         {
             var tagName = request.RootElement.GetProperty("TagName").GetString() ?? string.Empty;
+
+            // Filter・Cursor・Limitは省略可能（旧クライアント互換）
+            EventFilter? filter = null;
+            if (request.RootElement.TryGetProperty("Filter", out var filterElement) &&
+                filterElement.ValueKind == System.Text.Json.JsonValueKind.Object)
+            {
+                filter = System.Text.Json.JsonSerializer.Deserialize<EventFilter>(filterElement);
+            }
+            var cursor = request.RootElement.TryGetProperty("Cursor", out var cursorElement) &&
+                cursorElement.ValueKind == System.Text.Json.JsonValueKind.String ? cursorElement.GetString() : null;
+            var limit = request.RootElement.TryGetProperty("Limit", out var limitElement) &&
+                limitElement.ValueKind == System.Text.Json.JsonValueKind.Number ? limitElement.GetInt32() : (int?)null;
+
+            var response = await QueryRecordedEventsAsync(new GetRecordedEventsRequest(tagName, filter, cursor, limit), cancellationToken);
+            return System.Text.Json.JsonSerializer.Serialize(response);
+        }
+        catch (Exception ex)
+        {
+            return CreateErrorResponse($"GetRecordedEvents error: {ex.Message}");
+        }
+    }
+
+    private string ProcessGetRawEventsRequest(System.Text.Json.JsonDocument request)
+    {
+        try
+        {
+            if (_etwProvider is not IRawEventSource { IsRawPassthroughEnabled: true })
+            {
+                return CreateErrorResponse("Raw event passthrough is not enabled (ETW:RawPassthrough:Enabled)");
+            }
+
+            var tagName = request.RootElement.GetProperty("TagName").GetString() ?? string.Empty;
+            var afterSequenceNumber = request.RootElement.TryGetProperty("AfterSequenceNumber", out var afterElement) ? afterElement.GetInt64() : 0;
+            var maxCount = request.RootElement.TryGetProperty("MaxCount", out var maxCountElement) ? maxCountElement.GetInt32() : 1000;
+
+            var (records, lastSequenceNumber) = _rawEventChannel.GetAfter(tagName, afterSequenceNumber, maxCount);
+            var response = new GetRawEventsResponse(records.ToList())
+            {
+                Success = true,
+                LastSequenceNumber = lastSequenceNumber
+            };
+
+            return System.Text.Json.JsonSerializer.Serialize(response);
+        }
+        catch (Exception ex)
+        {
+            return CreateErrorResponse($"GetRawEvents error: {ex.Message}");
+        }
+    }
+
+    private string ProcessGetAlertsRequest(System.Text.Json.JsonDocument request, Func<string, bool> isTagAllowed)
+    {
+        try
+        {
+            var tagName = request.RootElement.TryGetProperty("TagName", out var tagElement) ? tagElement.GetString() : null;
+            var afterSequenceNumber = request.RootElement.TryGetProperty("AfterSequenceNumber", out var afterElement) ? afterElement.GetInt64() : 0;
+            var maxCount = request.RootElement.TryGetProperty("MaxCount", out var maxCountElement) ? maxCountElement.GetInt32() : 1000;
+
+            var (alerts, lastSequenceNumber) = _alertRuleEngine?.GetAfter(tagName, afterSequenceNumber, maxCount)
+                ?? (Array.Empty<Alert>(), 0);
+            var response = new GetAlertsResponse(alerts.Where(alert => isTagAllowed(alert.TagName)).ToList())
+            {
+                Success = true,
+                LastSequenceNumber = lastSequenceNumber
+            };
+
+            return System.Text.Json.JsonSerializer.Serialize(response);
+        }
+        catch (Exception ex)
+        {
+            return CreateErrorResponse($"GetAlerts error: {ex.Message}");
+        }
+    }
+
+    private async Task<string> ProcessGetSaveDirCandidatesRequestAsync(System.Text.Json.JsonDocument request, CancellationToken cancellationToken)
+    {
+        try
+        {
+            var tagName = request.RootElement.GetProperty("TagName").GetString() ?? string.Empty;
+            var maxCount = request.RootElement.TryGetProperty("MaxCount", out var maxCountElement) ? maxCountElement.GetInt32() : 10;
+
             var events = await GetRecordedEventsAsync(tagName, cancellationToken);
+            var response = new GetSaveDirCandidatesResponse(SaveDirectoryAnalyzer.Analyze(events, maxCount).ToList())
+            {
+                Success = true
+            };
 
-            var response = new GetRecordedEventsResponse(events.ToList())
+            return System.Text.Json.JsonSerializer.Serialize(response);
+        }
+        catch (Exception ex)
+        {
+            return CreateErrorResponse($"GetSaveDirCandidates error: {ex.Message}");
+        }
+    }
+
+    private async Task<string> ProcessGetEventSummaryRequestAsync(System.Text.Json.JsonDocument request, CancellationToken cancellationToken)
+    {
+        try
+        {
+            var tagName = request.RootElement.GetProperty("TagName").GetString() ?? string.Empty;
+            var topCount = request.RootElement.TryGetProperty("TopCount", out var topCountElement) ? topCountElement.GetInt32() : 10;
+
+            var events = await GetRecordedEventsAsync(tagName, cancellationToken);
+            var summary = EventSummaryBuilder.Build(events, topCount);
+
+            // 記録するディレクトリ外の活動は記録していないため、フィルタ統計の件数を合わせて返す
+            if (_eventProcessor.GetFilterStatistics()?.TryGetValue(tagName, out var filter) == true)
+            {
+                summary = summary with
+                {
+                    OutsideRootEventCount = filter.OutsideRootCount,
+                    OutsideRootBytesWritten = filter.OutsideRootBytesWritten
+                };
+            }
+
+            var response = new GetEventSummaryResponse(summary)
             {
                 Success = true
             };
@@ -394,16 +1077,18 @@ public class ProcTailService : IProcTailService, IDisposable
         }
         catch (Exception ex)
         {
-            return CreateErrorResponse($"GetRecordedEvents error: {ex.Message}");
+            return CreateErrorResponse($"GetEventSummary error: {ex.Message}");
         }
     }
 
-    private async Task<string> ProcessGetStatusRequestAsync(CancellationToken cancellationToken)
+    private async Task<string> ProcessGetStatusRequestAsync(Func<string, bool> isTagAllowed, CancellationToken cancellationToken)
     {
         try
         {
             var statistics = await GetStatisticsAsync(cancellationToken);
             var watchTargets = _watchTargetManager.GetWatchTargets();
+            var bufferStatistics = statistics.BufferStatisticsByTag ?? new Dictionary<string, TagBufferStatistics>();
+            var filterStatistics = _eventProcessor.GetFilterStatistics() ?? new Dictionary<string, TagFilterStatistics>();
 
             var response = new
             {
@@ -411,10 +1096,38 @@ public class ProcTailService : IProcTailService, IDisposable
                 IsRunning = _isRunning,
                 IsEtwMonitoring = _etwProvider.IsMonitoring,
                 IsPipeServerRunning = _pipeServer.IsRunning,
+                IsRawPassthroughEnabled = _etwProvider is IRawEventSource { IsRawPassthroughEnabled: true },
                 ActiveWatchTargets = watchTargets.Count,
                 TotalTags = statistics.TotalTags,
                 TotalEvents = statistics.TotalEvents,
                 EstimatedMemoryUsageMB = statistics.EstimatedMemoryUsage / 1024 / 1024,
+                EtwEventsLost = _etwProvider.EventsLost + (_directoryWatcher?.EventsLost ?? 0),
+                Buffers = bufferStatistics.Keys
+                    .Union(filterStatistics.Keys)
+                    .Where(isTagAllowed)
+                    .OrderBy(tag => tag)
+                    .Select(tag => new
+                    {
+                        TagName = tag,
+                        Policy = (bufferStatistics.TryGetValue(tag, out var buffer) ? buffer.Policy : BackpressurePolicy.DropOldest).ToString(),
+                        EventCount = statistics.EventCountByTag.TryGetValue(tag, out var eventCount) ? eventCount : 0,
+                        DroppedCount = buffer?.DroppedCount ?? 0,
+                        ExcludedProcessCount = filterStatistics.TryGetValue(tag, out var filter) ? filter.ExcludedProcessCount : 0,
+                        PathFilteredCount = filter?.PathFilteredCount ?? 0,
+                        RateLimitedCount = filter?.RateLimitedCount ?? 0,
+                        OutsideRootCount = filter?.OutsideRootCount ?? 0,
+                        OutsideRootBytesWritten = filter?.OutsideRootBytesWritten ?? 0
+                    })
+                    .ToList(),
+                _etwProvider.ClockMapping,
+                Diagnostics = _diagnostics
+                    .Select(result => new
+                    {
+                        result.Name,
+                        Status = result.Status.ToString(),
+                        result.Message
+                    })
+                    .ToList(),
                 Message = "ProcTail service is running normally"
             };
 
@@ -432,6 +1145,8 @@ public class ProcTailService : IProcTailService, IDisposable
         {
             var tagName = request.RootElement.GetProperty("TagName").GetString() ?? string.Empty;
             await _eventStorage.ClearEventsAsync(tagName);
+            _rawEventChannel.Clear(tagName);
+            _alertRuleEngine?.Clear(tagName);
 
             var response = new ClearEventsResponse
             {
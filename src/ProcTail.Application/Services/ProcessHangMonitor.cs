@@ -0,0 +1,120 @@
+using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Services;
+
+/// <summary>
+/// 監視対象プロセスのウィンドウが応答しなくなった・応答が戻ったことを検出する
+/// </summary>
+/// <remarks>
+/// 応答なしの検出を有効にしたタグのプロセスだけを確認し、複数のタグに属するプロセスは1回の確認結果を有効なタグ全てのイベントにする。
+/// 表示中のウィンドウがなく判定できない場合は状態を変えない。
+/// 監視対象から外れたプロセスの状態は、応答が戻ったイベントを生成せずに破棄する。
+/// </remarks>
+public class ProcessHangMonitor
+{
+    /// <summary>
+    /// 応答しなくなった時のイベント名
+    /// </summary>
+    public const string HangEventName = "Process/Hang";
+
+    /// <summary>
+    /// 応答が戻った時のイベント名
+    /// </summary>
+    public const string UnhangEventName = "Process/Unhang";
+
+    /// <summary>
+    /// 応答状態イベントのプロバイダー名
+    /// </summary>
+    public const string HangProviderName = "ProcTail";
+
+    private readonly IWatchTargetManager _watchTargetManager;
+    private readonly IProcessHangProbe _hangProbe;
+    private readonly Dictionary<int, HangState> _hungProcesses = new();
+    private readonly object _lockObject = new();
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public ProcessHangMonitor(IWatchTargetManager watchTargetManager, IProcessHangProbe hangProbe)
+    {
+        _watchTargetManager = watchTargetManager ?? throw new ArgumentNullException(nameof(watchTargetManager));
+        _hangProbe = hangProbe ?? throw new ArgumentNullException(nameof(hangProbe));
+    }
+
+    /// <summary>
+    /// 監視対象プロセスのウィンドウを確認し、応答状態が変わったプロセスのイベントを生成
+    /// </summary>
+    /// <param name="utcNow">現在時刻（UTC）</param>
+    /// <returns>保存すべき応答状態イベント</returns>
+    public IReadOnlyList<HangEventData> Collect(DateTime utcNow)
+    {
+        var hangEvents = new List<HangEventData>();
+
+        lock (_lockObject)
+        {
+            var targetsByProcess = _watchTargetManager.GetWatchTargets()
+                .Select(target => (target, options: _watchTargetManager.GetOptionsForTag(target.TagName)))
+                .Where(entry => entry.options.DetectHangs)
+                .GroupBy(entry => entry.target.ProcessId)
+                .ToList();
+
+            // 監視対象から外れたプロセスの状態を破棄
+            foreach (var processId in _hungProcesses.Keys.Except(targetsByProcess.Select(group => group.Key)).ToList())
+            {
+                _hungProcesses.Remove(processId);
+            }
+
+            foreach (var targets in targetsByProcess)
+            {
+                var windowState = _hangProbe.Probe(targets.Key);
+                if (windowState == null)
+                {
+                    continue;
+                }
+
+                var wasHung = _hungProcesses.TryGetValue(targets.Key, out var hangState);
+                if (windowState.IsHung && !wasHung)
+                {
+                    _hungProcesses[targets.Key] = new HangState(utcNow, windowState.WindowTitle);
+                    hangEvents.AddRange(targets.Select(entry =>
+                        CreateEvent(entry.target, entry.options, true, windowState.WindowTitle, null, utcNow)));
+                }
+                else if (!windowState.IsHung && wasHung)
+                {
+                    _hungProcesses.Remove(targets.Key);
+                    var hangDurationMs = (long)Math.Max(0, (utcNow - hangState!.HungSince).TotalMilliseconds);
+                    hangEvents.AddRange(targets.Select(entry =>
+                        CreateEvent(entry.target, entry.options, false, hangState.WindowTitle, hangDurationMs, utcNow)));
+                }
+            }
+        }
+
+        return hangEvents;
+    }
+
+    private static HangEventData CreateEvent(WatchTarget target, WatchTargetOptions options, bool isHung, string windowTitle, long? hangDurationMs, DateTime utcNow)
+    {
+        return new HangEventData
+        {
+            Timestamp = utcNow,
+            TagName = target.TagName,
+            TagMetadata = options.Metadata.Count > 0 ? options.Metadata : null,
+            ProcessId = target.ProcessId,
+            ThreadId = 0,
+            ProviderName = HangProviderName,
+            EventName = isHung ? HangEventName : UnhangEventName,
+            ActivityId = Guid.Empty,
+            RelatedActivityId = Guid.Empty,
+            Payload = new Dictionary<string, object>(),
+            IsHung = isHung,
+            WindowTitle = windowTitle,
+            HangDurationMs = hangDurationMs
+        };
+    }
+
+    /// <summary>
+    /// 応答しなくなった時刻とその時のウィンドウのタイトル
+    /// </summary>
+    private sealed record HangState(DateTime HungSince, string WindowTitle);
+}
@@ -0,0 +1,100 @@
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Services;
+
+/// <summary>
+/// 生イベントのパススルー用に、タグごとに直近のレコードを保持する
+/// </summary>
+/// <remarks>
+/// 生イベントは量が多いためストレージには保存せず、容量を超えた古いレコードから捨てる。
+/// クライアントは前回受け取った連番を渡して続きを取得し、連番の飛びで取りこぼしを判別する。
+/// </remarks>
+public class RawEventChannel
+{
+    /// <summary>
+    /// タグごとの既定の保持件数
+    /// </summary>
+    public const int DefaultCapacityPerTag = 1000;
+
+    private readonly Dictionary<string, TagBuffer> _buffers = new();
+    private readonly object _lockObject = new();
+    private readonly int _capacityPerTag;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    /// <param name="capacityPerTag">タグごとの保持件数</param>
+    public RawEventChannel(int capacityPerTag = DefaultCapacityPerTag)
+    {
+        _capacityPerTag = capacityPerTag > 0 ? capacityPerTag : DefaultCapacityPerTag;
+    }
+
+    /// <summary>
+    /// レコードを追加
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="record">レコード（連番はここで採番する）</param>
+    public void Add(string tagName, RawEtwRecord record)
+    {
+        lock (_lockObject)
+        {
+            if (!_buffers.TryGetValue(tagName, out var buffer))
+            {
+                buffer = new TagBuffer();
+                _buffers[tagName] = buffer;
+            }
+
+            buffer.Records.Enqueue(record with { SequenceNumber = ++buffer.LastSequenceNumber });
+            while (buffer.Records.Count > _capacityPerTag)
+            {
+                buffer.Records.Dequeue();
+            }
+        }
+    }
+
+    /// <summary>
+    /// 指定した連番より後のレコードを取得
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="afterSequenceNumber">この連番より後のレコードを返す</param>
+    /// <param name="maxCount">最大件数</param>
+    /// <returns>レコードとタグの最後の連番</returns>
+    public (IReadOnlyList<RawEtwRecord> Records, long LastSequenceNumber) GetAfter(string tagName, long afterSequenceNumber, int maxCount)
+    {
+        lock (_lockObject)
+        {
+            if (!_buffers.TryGetValue(tagName, out var buffer))
+            {
+                return (Array.Empty<RawEtwRecord>(), 0);
+            }
+
+            var records = buffer.Records
+                .Where(record => record.SequenceNumber > afterSequenceNumber)
+                .Take(Math.Max(maxCount, 0))
+                .ToList();
+            return (records, buffer.LastSequenceNumber);
+        }
+    }
+
+    /// <summary>
+    /// タグのレコードを削除（連番は継続する）
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    public void Clear(string tagName)
+    {
+        lock (_lockObject)
+        {
+            if (_buffers.TryGetValue(tagName, out var buffer))
+            {
+                buffer.Records.Clear();
+            }
+        }
+    }
+
+    private sealed class TagBuffer
+    {
+        public Queue<RawEtwRecord> Records { get; } = new();
+
+        public long LastSequenceNumber { get; set; }
+    }
+}
@@ -0,0 +1,119 @@
+using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Services;
+
+/// <summary>
+/// 監視対象プロセスのリソース使用量をタグの間隔ごとにスナップショットとして生成する
+/// </summary>
+/// <remarks>
+/// 間隔はタグ単位で判定し、間隔が経過したタグの全プロセスを同じ時刻のスナップショットとして記録する。
+/// 複数のタグに属するプロセスは、同じ時刻に間隔が経過したタグの間で読み取り結果を共有する。
+/// 終了済み・アクセスできないプロセスのスナップショットは生成しない。
+/// </remarks>
+public class ResourceSnapshotSampler
+{
+    /// <summary>
+    /// スナップショットのイベント名
+    /// </summary>
+    public const string SnapshotEventName = "Process/ResourceSnapshot";
+
+    /// <summary>
+    /// スナップショットのプロバイダー名
+    /// </summary>
+    public const string SnapshotProviderName = "ProcTail";
+
+    private readonly IWatchTargetManager _watchTargetManager;
+    private readonly IProcessResourceReader _resourceReader;
+    private readonly Dictionary<string, DateTime> _lastSampledAt = new();
+    private readonly object _lockObject = new();
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public ResourceSnapshotSampler(IWatchTargetManager watchTargetManager, IProcessResourceReader resourceReader)
+    {
+        _watchTargetManager = watchTargetManager ?? throw new ArgumentNullException(nameof(watchTargetManager));
+        _resourceReader = resourceReader ?? throw new ArgumentNullException(nameof(resourceReader));
+    }
+
+    /// <summary>
+    /// 間隔が経過したタグのスナップショットを生成
+    /// </summary>
+    /// <param name="utcNow">現在時刻（UTC）</param>
+    /// <returns>保存すべきスナップショット</returns>
+    public IReadOnlyList<ResourceSnapshotEventData> Collect(DateTime utcNow)
+    {
+        var snapshots = new List<ResourceSnapshotEventData>();
+        var usages = new Dictionary<int, ProcessResourceUsage?>();
+
+        lock (_lockObject)
+        {
+            var targetsByTag = _watchTargetManager.GetWatchTargets().GroupBy(target => target.TagName).ToList();
+
+            // 削除されたタグの前回時刻を破棄
+            foreach (var tagName in _lastSampledAt.Keys.Except(targetsByTag.Select(group => group.Key)).ToList())
+            {
+                _lastSampledAt.Remove(tagName);
+            }
+
+            foreach (var targets in targetsByTag)
+            {
+                var options = _watchTargetManager.GetOptionsForTag(targets.Key);
+                if (options.ResourceSnapshotIntervalMs <= 0)
+                {
+                    _lastSampledAt.Remove(targets.Key);
+                    continue;
+                }
+
+                if (_lastSampledAt.TryGetValue(targets.Key, out var lastSampledAt)
+                    && utcNow - lastSampledAt < TimeSpan.FromMilliseconds(options.ResourceSnapshotIntervalMs))
+                {
+                    continue;
+                }
+
+                _lastSampledAt[targets.Key] = utcNow;
+                foreach (var target in targets)
+                {
+                    if (!usages.TryGetValue(target.ProcessId, out var usage))
+                    {
+                        usage = _resourceReader.ReadUsage(target.ProcessId);
+                        usages[target.ProcessId] = usage;
+                    }
+
+                    if (usage != null)
+                    {
+                        snapshots.Add(CreateSnapshot(target, usage, options, utcNow));
+                    }
+                }
+            }
+        }
+
+        return snapshots;
+    }
+
+    private static ResourceSnapshotEventData CreateSnapshot(WatchTarget target, ProcessResourceUsage usage, WatchTargetOptions options, DateTime utcNow)
+    {
+        return new ResourceSnapshotEventData
+        {
+            Timestamp = utcNow,
+            TagName = target.TagName,
+            TagMetadata = options.Metadata.Count > 0 ? options.Metadata : null,
+            ProcessId = target.ProcessId,
+            ThreadId = 0,
+            ProviderName = SnapshotProviderName,
+            EventName = SnapshotEventName,
+            ActivityId = Guid.Empty,
+            RelatedActivityId = Guid.Empty,
+            Payload = new Dictionary<string, object>(),
+            CpuTimeMs = (long)usage.CpuTime.TotalMilliseconds,
+            UserCpuTimeMs = (long)usage.UserCpuTime.TotalMilliseconds,
+            WorkingSetBytes = usage.WorkingSetBytes,
+            HandleCount = usage.HandleCount,
+            ReadOperations = usage.ReadOperations,
+            WriteOperations = usage.WriteOperations,
+            ReadBytes = usage.ReadBytes,
+            WriteBytes = usage.WriteBytes
+        };
+    }
+}
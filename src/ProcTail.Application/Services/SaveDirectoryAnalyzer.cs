@@ -0,0 +1,156 @@
+using System.Text.RegularExpressions;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Services;
+
+/// <summary>
+/// タグの書き込みイベントからセーブデータの保存先らしいディレクトリを推定する
+/// </summary>
+/// <remarks>
+/// 書き込みをファイルごとに「保存」（前の書き込みから2秒以上空いた一連の書き込み）にまとめ、ファイルのあるディレクトリごとに集計する。
+/// ユーザーのプロファイル配下（C:\Users\&lt;ユーザー&gt;\、/home/&lt;ユーザー&gt;/ など）の、1回の保存が16MB以下のファイルだけを対象とし、
+/// キャッシュ・ログ・一時ファイルのディレクトリとログ・ダンプの拡張子は除外する。
+/// 2回以上保存されたファイルがあるディレクトリを候補とし、保存回数が多いほど、ディレクトリ名に "save" を含む場合ほど上位にする。
+/// ストレージに残っているイベントだけが対象のため、古いイベントが破棄されたタグでは直近の書き込みから推定する。
+/// </remarks>
+public static class SaveDirectoryAnalyzer
+{
+    /// <summary>
+    /// 別の保存として数える書き込みの間隔
+    /// </summary>
+    public static readonly TimeSpan SaveGap = TimeSpan.FromSeconds(2);
+
+    /// <summary>
+    /// 対象とする1回の保存の最大バイト数
+    /// </summary>
+    public const long MaxSaveBytes = 16L * 1024 * 1024;
+
+    private const int MaxListedFiles = 10;
+
+    /// <summary>
+    /// 1ファイルの保存回数としてスコアに数える上限（頻繁に書き換えるファイル1つで順位が決まらないようにする）
+    /// </summary>
+    private const int MaxScoredSavesPerFile = 10;
+
+    private static readonly Regex UserProfilePath = new(
+        @"^(?:[A-Za-z]:\\Users\\[^\\]+\\|/home/[^/]+/|/Users/[^/]+/|/root/)",
+        RegexOptions.Compiled | RegexOptions.IgnoreCase);
+
+    private static readonly HashSet<string> ExcludedDirectoryNames = new(StringComparer.OrdinalIgnoreCase)
+    {
+        "temp", "tmp", "log", "logs", "crashdumps", "crashes", "crashreports", "caches"
+    };
+
+    private static readonly HashSet<string> ExcludedExtensions = new(StringComparer.OrdinalIgnoreCase)
+    {
+        ".log", ".etl", ".dmp", ".mdmp", ".pf", ".lock"
+    };
+
+    /// <summary>
+    /// 書き込みイベントからセーブデータの保存先の候補を推定
+    /// </summary>
+    /// <param name="events">タグのイベント</param>
+    /// <param name="maxCount">最大件数</param>
+    /// <returns>保存先らしい順の候補</returns>
+    public static IReadOnlyList<SaveDirectoryCandidate> Analyze(IEnumerable<BaseEventData> events, int maxCount)
+    {
+        var files = events.OfType<FileEventData>()
+            .Where(fileEvent => fileEvent.EventName == "FileIO/Write" && IsCandidatePath(fileEvent.FilePath))
+            .GroupBy(fileEvent => fileEvent.FilePath, StringComparer.OrdinalIgnoreCase)
+            .Select(writes => SummarizeFile(writes.Key, writes))
+            .Where(file => file.MaxSaveBytes <= MaxSaveBytes);
+
+        return files
+            .GroupBy(file => GetDirectory(file.Path), StringComparer.OrdinalIgnoreCase)
+            .Select(directory => CreateCandidate(directory.Key, directory.ToList()))
+            .Where(candidate => candidate.RewrittenFileCount > 0)
+            .OrderByDescending(candidate => candidate.Score)
+            .ThenByDescending(candidate => candidate.LastWriteAt)
+            .Take(Math.Max(0, maxCount))
+            .ToList();
+    }
+
+    private static bool IsCandidatePath(string path)
+    {
+        if (!UserProfilePath.IsMatch(path) || ExcludedExtensions.Contains(Path.GetExtension(path)))
+        {
+            return false;
+        }
+
+        // ShaderCache・GPUCache・Code Cache などのキャッシュもディレクトリ名で除外する
+        var directorySegments = GetDirectory(path).Split('\\', '/');
+        return !directorySegments.Any(segment =>
+            ExcludedDirectoryNames.Contains(segment) || segment.EndsWith("cache", StringComparison.OrdinalIgnoreCase));
+    }
+
+    private static FileSummary SummarizeFile(string path, IEnumerable<FileEventData> writes)
+    {
+        var saveCount = 0;
+        var maxSaveBytes = 0L;
+        var saveBytes = 0L;
+        DateTime? lastWriteAt = null;
+
+        foreach (var write in writes.OrderBy(write => write.FirstTimestamp ?? write.Timestamp))
+        {
+            if (lastWriteAt == null || (write.FirstTimestamp ?? write.Timestamp) - lastWriteAt.Value >= SaveGap)
+            {
+                saveCount++;
+                saveBytes = 0;
+            }
+
+            saveBytes += write.TotalBytes ?? GetIoSize(write);
+            maxSaveBytes = Math.Max(maxSaveBytes, saveBytes);
+            if (lastWriteAt == null || write.Timestamp > lastWriteAt.Value)
+            {
+                lastWriteAt = write.Timestamp;
+            }
+        }
+
+        return new FileSummary(path, saveCount, maxSaveBytes, lastWriteAt ?? DateTime.MinValue);
+    }
+
+    private static SaveDirectoryCandidate CreateCandidate(string directory, IReadOnlyList<FileSummary> files)
+    {
+        var rewrittenFileCount = files.Count(file => file.SaveCount >= 2);
+        var score = files.Sum(file => Math.Min(file.SaveCount, MaxScoredSavesPerFile)) + 2.0 * rewrittenFileCount;
+        if (directory.Split('\\', '/').Any(segment => segment.Contains("save", StringComparison.OrdinalIgnoreCase)))
+        {
+            score *= 2;
+        }
+
+        return new SaveDirectoryCandidate(
+            directory,
+            score,
+            files.Count,
+            rewrittenFileCount,
+            files.Sum(file => file.SaveCount),
+            files.Max(file => file.MaxSaveBytes),
+            files.Max(file => file.LastWriteAt),
+            files.OrderByDescending(file => file.SaveCount)
+                .ThenBy(file => file.Path, StringComparer.OrdinalIgnoreCase)
+                .Take(MaxListedFiles)
+                .Select(file => file.Path[(directory.Length + 1)..])
+                .ToList());
+    }
+
+    /// <summary>
+    /// パスのディレクトリ部分（実行環境に関係なく "/" と "\" の両方を区切り文字として扱う）
+    /// </summary>
+    private static string GetDirectory(string path)
+    {
+        var separatorIndex = path.LastIndexOfAny(new[] { '\\', '/' });
+        return separatorIndex > 0 ? path[..separatorIndex] : string.Empty;
+    }
+
+    private static long GetIoSize(FileEventData write)
+    {
+        return write.Payload.TryGetValue("IoSize", out var value) && long.TryParse(value?.ToString(), out var size)
+            ? size
+            : 0;
+    }
+
+    /// <summary>
+    /// ファイルごとの保存回数・1回の保存の最大バイト数・最後の書き込み時刻
+    /// </summary>
+    private sealed record FileSummary(string Path, int SaveCount, long MaxSaveBytes, DateTime LastWriteAt);
+}
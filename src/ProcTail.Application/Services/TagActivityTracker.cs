@@ -0,0 +1,150 @@
+using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Services;
+
+/// <summary>
+/// タグごとのイベントの記録状況から無操作状態への移行・復帰を検出する
+/// </summary>
+/// <remarks>
+/// 無操作時間を指定したタグだけを追跡し、ProcTail自身が生成したイベント（リソース使用量のスナップショットなど）は活動として扱わない。
+/// 監視を開始してからイベントが1件もないタグも、開始時点から無操作時間が経過すれば無操作状態になる。
+/// 時刻はイベントのタイムスタンプではなく記録時のUTC時刻で判定する。
+/// </remarks>
+public class TagActivityTracker
+{
+    /// <summary>
+    /// 無操作状態への移行イベントのイベント名
+    /// </summary>
+    public const string IdleEventName = "Tag/Idle";
+
+    /// <summary>
+    /// 無操作状態からの復帰イベントのイベント名
+    /// </summary>
+    public const string ActiveEventName = "Tag/Active";
+
+    /// <summary>
+    /// 活動状態イベントのプロバイダー名
+    /// </summary>
+    public const string ActivityProviderName = "ProcTail";
+
+    private readonly IWatchTargetManager _watchTargetManager;
+    private readonly Dictionary<string, ActivityState> _states = new();
+    private readonly object _lockObject = new();
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public TagActivityTracker(IWatchTargetManager watchTargetManager)
+    {
+        _watchTargetManager = watchTargetManager ?? throw new ArgumentNullException(nameof(watchTargetManager));
+    }
+
+    /// <summary>
+    /// 記録したイベントをタグの活動として反映
+    /// </summary>
+    /// <param name="eventData">記録したイベント</param>
+    /// <param name="utcNow">現在時刻（UTC）</param>
+    /// <returns>無操作状態から復帰した場合は復帰イベント（それ以外はnull）</returns>
+    public TagActivityEventData? RecordActivity(BaseEventData eventData, DateTime utcNow)
+    {
+        if (eventData.ProviderName == ActivityProviderName)
+        {
+            return null;
+        }
+
+        var options = _watchTargetManager.GetOptionsForTag(eventData.TagName);
+        lock (_lockObject)
+        {
+            if (options.IdleTimeoutMs <= 0)
+            {
+                _states.Remove(eventData.TagName);
+                return null;
+            }
+
+            var isTracked = _states.TryGetValue(eventData.TagName, out var state);
+            _states[eventData.TagName] = new ActivityState(utcNow, false);
+            if (!isTracked || !state!.IsIdle)
+            {
+                return null;
+            }
+
+            var idleDuration = utcNow - state.LastActivityAt;
+            return CreateEvent(eventData.TagName, TagActivityState.Active, state.LastActivityAt, options, utcNow) with
+            {
+                IdleDurationMs = (long)Math.Max(0, idleDuration.TotalMilliseconds)
+            };
+        }
+    }
+
+    /// <summary>
+    /// 無操作時間が経過したタグの無操作イベントを生成
+    /// </summary>
+    /// <param name="utcNow">現在時刻（UTC）</param>
+    /// <returns>保存すべき無操作イベント</returns>
+    public IReadOnlyList<TagActivityEventData> Collect(DateTime utcNow)
+    {
+        var idleEvents = new List<TagActivityEventData>();
+
+        lock (_lockObject)
+        {
+            var tagNames = _watchTargetManager.GetWatchTargets().Select(target => target.TagName).ToHashSet();
+
+            // 削除されたタグの状態を破棄
+            foreach (var tagName in _states.Keys.Except(tagNames).ToList())
+            {
+                _states.Remove(tagName);
+            }
+
+            foreach (var tagName in tagNames)
+            {
+                var options = _watchTargetManager.GetOptionsForTag(tagName);
+                if (options.IdleTimeoutMs <= 0)
+                {
+                    _states.Remove(tagName);
+                    continue;
+                }
+
+                if (!_states.TryGetValue(tagName, out var state))
+                {
+                    _states[tagName] = new ActivityState(utcNow, false);
+                    continue;
+                }
+
+                if (state.IsIdle || utcNow - state.LastActivityAt < TimeSpan.FromMilliseconds(options.IdleTimeoutMs))
+                {
+                    continue;
+                }
+
+                _states[tagName] = state with { IsIdle = true };
+                idleEvents.Add(CreateEvent(tagName, TagActivityState.Idle, state.LastActivityAt, options, utcNow));
+            }
+        }
+
+        return idleEvents;
+    }
+
+    private static TagActivityEventData CreateEvent(string tagName, TagActivityState activityState, DateTime lastActivityAt, WatchTargetOptions options, DateTime utcNow)
+    {
+        return new TagActivityEventData
+        {
+            Timestamp = utcNow,
+            TagName = tagName,
+            TagMetadata = options.Metadata.Count > 0 ? options.Metadata : null,
+            ProcessId = 0,
+            ThreadId = 0,
+            ProviderName = ActivityProviderName,
+            EventName = activityState == TagActivityState.Idle ? IdleEventName : ActiveEventName,
+            ActivityId = Guid.Empty,
+            RelatedActivityId = Guid.Empty,
+            Payload = new Dictionary<string, object>(),
+            State = activityState,
+            LastActivityAt = lastActivityAt
+        };
+    }
+
+    /// <summary>
+    /// タグの最後の活動時刻と無操作状態かどうか
+    /// </summary>
+    private sealed record ActivityState(DateTime LastActivityAt, bool IsIdle);
+}
@@ -8,26 +8,52 @@ namespace ProcTail.Application.Services;
 /// <summary>
 /// 監視対象管理サービス
 /// </summary>
+/// <remarks>
+/// 1つのプロセスは複数のタグに同時に属することができ、タグごとに監視対象エントリを持つ。
+/// プロセスIDごとのエントリ一覧は変更時に置き換え、読み取りはロックなしで行う。
+/// 再監視期間を指定したタグのプロセスが終了した場合、期間内に同じ実行ファイルから起動した最初のプロセスをそのタグに追加する。
+/// </remarks>
 public class WatchTargetManager : IWatchTargetManager, IDisposable
 {
+    private const int MaxAncestorDepth = 64;
+
+    // Linux以外のファイルシステムは既定で大文字小文字を区別しない
+    private static readonly StringComparer PathComparer = OperatingSystem.IsLinux() ? StringComparer.Ordinal : StringComparer.OrdinalIgnoreCase;
+    private static readonly StringComparison PathComparison = OperatingSystem.IsLinux() ? StringComparison.Ordinal : StringComparison.OrdinalIgnoreCase;
+
     private readonly ILogger<WatchTargetManager> _logger;
-    private readonly ConcurrentDictionary<int, WatchTarget> _watchTargets = new();
+    private readonly IProcessValidator? _processValidator;
+    private readonly ConcurrentDictionary<int, IReadOnlyList<WatchTarget>> _watchTargets = new();
     private readonly ConcurrentDictionary<string, HashSet<int>> _tagToProcessMap = new();
+    private readonly ConcurrentDictionary<string, WatchTargetOptions> _tagOptions = new();
+    private readonly ConcurrentDictionary<string, string> _pathTargets = new(PathComparer);
+    private readonly List<PendingRewatch> _pendingRewatches = new();
     private readonly object _lockObject = new();
     private bool _disposed;
 
     /// <summary>
-    /// 監視中の対象数
+    /// 監視中の対象数（複数のタグに属するプロセスはタグごとに数える）
     /// </summary>
-    public int ActiveTargetCount => _watchTargets.Count;
+    public int ActiveTargetCount => _watchTargets.Values.Sum(targets => targets.Count);
 
     /// <summary>
     /// コンストラクタ
     /// </summary>
     /// <param name="logger">ロガー</param>
     public WatchTargetManager(ILogger<WatchTargetManager> logger)
+        : this(logger, null)
+    {
+    }
+
+    /// <summary>
+    /// コンストラクタ（祖先プロセスを記録する場合）
+    /// </summary>
+    /// <param name="logger">ロガー</param>
+    /// <param name="processValidator">プロセス情報の取得に使用するバリデーター</param>
+    public WatchTargetManager(ILogger<WatchTargetManager> logger, IProcessValidator? processValidator)
     {
         _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+        _processValidator = processValidator;
     }
 
     /// <summary>
@@ -37,6 +63,26 @@ public class WatchTargetManager : IWatchTargetManager, IDisposable
     /// <param name="tagName">タグ名</param>
     /// <returns>追加結果</returns>
     public Task<bool> AddTargetAsync(int processId, string tagName)
+    {
+        return AddTargetCoreAsync(processId, tagName, null);
+    }
+
+    /// <summary>
+    /// 監視対象をタグのオプション付きで追加
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="options">タグに適用する監視オプション（同じタグの既存オプションは上書き）</param>
+    /// <returns>追加結果</returns>
+    public Task<bool> AddTargetAsync(int processId, string tagName, WatchTargetOptions options)
+    {
+        return AddTargetCoreAsync(processId, tagName, options ?? throw new ArgumentNullException(nameof(options)));
+    }
+
+    /// <summary>
+    /// 監視対象を追加（オプション未指定の場合はタグの既存オプションを維持）
+    /// </summary>
+    private Task<bool> AddTargetCoreAsync(int processId, string tagName, WatchTargetOptions? options, string? executablePath = null)
     {
         if (string.IsNullOrWhiteSpace(tagName))
         {
@@ -50,26 +96,29 @@ public class WatchTargetManager : IWatchTargetManager, IDisposable
             return Task.FromResult(false);
         }
 
+        var invalidPattern = options == null ? null : FindInvalidPattern(options);
+        if (invalidPattern != null)
+        {
+            _logger.LogWarning("監視対象追加に失敗: パターンが無効です (Pattern: {Pattern}, Tag: {TagName})", invalidPattern, tagName);
+            return Task.FromResult(false);
+        }
+
         try
         {
             var watchTarget = new WatchTarget(
                 processId,
                 tagName,
-                DateTime.UtcNow
+                DateTime.UtcNow,
+                Ancestors: BuildAncestors(processId),
+                ExecutablePath: executablePath ?? GetExecutablePath(processId)
             );
 
-            // 監視対象を追加
-            if (_watchTargets.TryAdd(processId, watchTarget))
+            // 監視対象を追加（他のタグで監視中のプロセスも追加できる）
+            if (TryAddTarget(watchTarget))
             {
-                // タグマッピングを更新
-                lock (_lockObject)
+                if (options != null)
                 {
-                    if (!_tagToProcessMap.TryGetValue(tagName, out var processSet))
-                    {
-                        processSet = new HashSet<int>();
-                        _tagToProcessMap[tagName] = processSet;
-                    }
-                    processSet.Add(processId);
+                    _tagOptions[tagName] = options;
                 }
 
                 _logger.LogInformation("監視対象を追加しました (ProcessId: {ProcessId}, Tag: {TagName})", 
@@ -78,7 +127,7 @@ public class WatchTargetManager : IWatchTargetManager, IDisposable
             }
             else
             {
-                _logger.LogWarning("監視対象追加に失敗: 既に監視中です (ProcessId: {ProcessId}, Tag: {TagName})", processId, tagName);
+                _logger.LogWarning("監視対象追加に失敗: 既にこのタグで監視中です (ProcessId: {ProcessId}, Tag: {TagName})", processId, tagName);
                 return Task.FromResult(false);
             }
         }
@@ -90,15 +139,15 @@ public class WatchTargetManager : IWatchTargetManager, IDisposable
     }
 
     /// <summary>
-    /// 子プロセスを自動追加
+    /// 子プロセスを自動追加（親プロセスが属する全てのタグに、タグごとの伝播ルールに従って追加）
     /// </summary>
     /// <param name="childProcessId">子プロセスID</param>
     /// <param name="parentProcessId">親プロセスID</param>
-    /// <returns>追加成功の場合true</returns>
+    /// <returns>いずれかのタグに追加した場合true</returns>
     public Task<bool> AddChildProcessAsync(int childProcessId, int parentProcessId)
     {
         // 親プロセスが監視対象かチェック
-        if (!_watchTargets.TryGetValue(parentProcessId, out var parentTarget))
+        if (!_watchTargets.TryGetValue(parentProcessId, out var parentTargets))
         {
             _logger.LogWarning("子プロセス追加に失敗: 親プロセスが監視対象ではありません (ParentId: {ParentProcessId}, ChildId: {ChildProcessId})", 
                 parentProcessId, childProcessId);
@@ -107,34 +156,57 @@ public class WatchTargetManager : IWatchTargetManager, IDisposable
 
         try
         {
-            var childTarget = new WatchTarget(
-                childProcessId,
-                parentTarget.TagName,
-                DateTime.UtcNow,
-                IsChildProcess: true,
-                ParentProcessId: parentProcessId
-            );
+            var parentInfo = _processValidator?.GetProcessInfo(parentProcessId);
+            var childInfo = _processValidator?.GetProcessInfo(childProcessId);
+            var added = false;
 
-            if (_watchTargets.TryAdd(childProcessId, childTarget))
+            foreach (var parentTarget in parentTargets)
             {
-                // タグマッピングを更新
-                lock (_lockObject)
+                var options = GetOptionsForTag(parentTarget.TagName);
+
+                // 親プロセスを除外しているタグには子プロセスも追加しない
+                if (IsExcluded(options, parentTarget))
                 {
-                    if (_tagToProcessMap.TryGetValue(parentTarget.TagName, out var processSet))
-                    {
-                        processSet.Add(childProcessId);
-                    }
+                    continue;
                 }
 
-                _logger.LogInformation("子プロセスを追加しました (ChildId: {ChildProcessId}, ParentId: {ParentProcessId}, Tag: {TagName})", 
-                    childProcessId, parentProcessId, parentTarget.TagName);
-                return Task.FromResult(true);
-            }
-            else
-            {
-                _logger.LogWarning("子プロセス追加に失敗: 既に監視中です (ChildId: {ChildProcessId})", childProcessId);
-                return Task.FromResult(false);
+                var depth = parentTarget.Depth + 1;
+                var skipReason = GetChildSkipReason(options, depth, parentInfo, childInfo);
+                if (skipReason != null)
+                {
+                    _logger.LogInformation("子プロセスを追加しませんでした: {Reason} (ChildId: {ChildProcessId}, ParentId: {ParentProcessId}, Tag: {TagName})",
+                        skipReason, childProcessId, parentProcessId, parentTarget.TagName);
+                    continue;
+                }
+
+                // 親の祖先は親の監視追加時点で記録済みのため、中間の親が終了していても系譜をたどれる
+                var ancestors = new List<ProcessAncestor> { ToAncestor(parentProcessId, parentInfo) };
+                ancestors.AddRange(parentTarget.Ancestors ?? Array.Empty<ProcessAncestor>());
+
+                var childTarget = new WatchTarget(
+                    childProcessId,
+                    parentTarget.TagName,
+                    DateTime.UtcNow,
+                    IsChildProcess: true,
+                    ParentProcessId: parentProcessId,
+                    Ancestors: ancestors.Take(MaxAncestorDepth).ToList(),
+                    Depth: depth,
+                    ExecutablePath: childInfo?.ExecutablePath ?? GetExecutablePath(childProcessId)
+                );
+
+                if (TryAddTarget(childTarget))
+                {
+                    _logger.LogInformation("子プロセスを追加しました (ChildId: {ChildProcessId}, ParentId: {ParentProcessId}, Tag: {TagName})", 
+                        childProcessId, parentProcessId, parentTarget.TagName);
+                    added = true;
+                }
+                else
+                {
+                    _logger.LogWarning("子プロセス追加に失敗: 既にこのタグで監視中です (ChildId: {ChildProcessId}, Tag: {TagName})", childProcessId, parentTarget.TagName);
+                }
             }
+
+            return Task.FromResult(added);
         }
         catch (Exception ex)
         {
@@ -145,7 +217,280 @@ public class WatchTargetManager : IWatchTargetManager, IDisposable
     }
 
     /// <summary>
-    /// 監視対象を除去
+    /// 監視対象エントリを追加し、タグマッピングを更新
+    /// </summary>
+    /// <returns>追加した場合true（同じタグで監視中の場合false）</returns>
+    private bool TryAddTarget(WatchTarget watchTarget)
+    {
+        lock (_lockObject)
+        {
+            var targets = _watchTargets.TryGetValue(watchTarget.ProcessId, out var existing) ? existing : Array.Empty<WatchTarget>();
+            if (targets.Any(target => target.TagName == watchTarget.TagName))
+            {
+                return false;
+            }
+
+            _watchTargets[watchTarget.ProcessId] = targets.Append(watchTarget).ToList();
+
+            if (!_tagToProcessMap.TryGetValue(watchTarget.TagName, out var processSet))
+            {
+                processSet = new HashSet<int>();
+                _tagToProcessMap[watchTarget.TagName] = processSet;
+            }
+            processSet.Add(watchTarget.ProcessId);
+            return true;
+        }
+    }
+
+    /// <summary>
+    /// インストールディレクトリ配下の実行ファイルから起動したプロセスを監視対象として登録
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="directory">ディレクトリ（配下のサブディレクトリを含む）</param>
+    /// <param name="options">タグに適用する監視オプション（nullの場合は既存のオプションを維持）</param>
+    /// <returns>登録時点で既に実行中だったため追加されたプロセス数</returns>
+    public async Task<int> AddPathTargetAsync(string tagName, string directory, WatchTargetOptions? options)
+    {
+        if (string.IsNullOrWhiteSpace(tagName))
+        {
+            throw new ArgumentException("タグ名が無効です", nameof(tagName));
+        }
+
+        if (string.IsNullOrWhiteSpace(directory) || !Path.IsPathRooted(directory))
+        {
+            throw new ArgumentException($"ディレクトリは絶対パスで指定してください: {directory}", nameof(directory));
+        }
+
+        var invalidPattern = options == null ? null : FindInvalidPattern(options);
+        if (invalidPattern != null)
+        {
+            throw new ArgumentException($"パターンが無効です: {invalidPattern}", nameof(options));
+        }
+
+        var normalizedDirectory = NormalizeDirectory(directory);
+        lock (_lockObject)
+        {
+            _pathTargets[normalizedDirectory] = tagName;
+            if (options != null)
+            {
+                _tagOptions[tagName] = options;
+            }
+        }
+
+        _logger.LogInformation("ディレクトリ監視を登録しました (Directory: {Directory}, Tag: {TagName})", normalizedDirectory, tagName);
+
+        // 登録前から実行中のプロセスを追加
+        var addedCount = 0;
+        foreach (var process in System.Diagnostics.Process.GetProcesses())
+        {
+            using (process)
+            {
+                if (await AddProcessByPathAsync(process.Id, null))
+                {
+                    addedCount++;
+                }
+            }
+        }
+
+        return addedCount;
+    }
+
+    /// <summary>
+    /// 実行ファイルが登録済みディレクトリ配下にある場合、プロセスを監視対象に追加
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <param name="executablePath">実行ファイルパス（nullの場合はプロセスから取得）</param>
+    /// <returns>追加された場合true</returns>
+    public async Task<bool> AddProcessByPathAsync(int processId, string? executablePath)
+    {
+        if (_pathTargets.IsEmpty || processId <= 0)
+        {
+            return false;
+        }
+
+        var path = executablePath ?? GetExecutablePath(processId);
+        if (string.IsNullOrEmpty(path) || !Path.IsPathRooted(path))
+        {
+            return false;
+        }
+
+        // 入れ子のディレクトリが登録されている場合は最も深いディレクトリのタグを使用
+        var match = _pathTargets
+            .Where(entry => path.StartsWith(entry.Key, PathComparison))
+            .OrderByDescending(entry => entry.Key.Length)
+            .FirstOrDefault();
+        if (match.Value == null || GetTagsForProcess(processId).Contains(match.Value))
+        {
+            return false;
+        }
+
+        return await AddTargetCoreAsync(processId, match.Value, null, path);
+    }
+
+    /// <summary>
+    /// 再監視期間内に終了した監視対象と同じ実行ファイルから起動したプロセスを、終了したプロセスのタグに追加
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <param name="executablePath">実行ファイルパス（nullの場合はプロセスから取得）</param>
+    /// <returns>いずれかのタグに追加した場合true</returns>
+    public async Task<bool> RewatchRestartedProcessAsync(int processId, string? executablePath)
+    {
+        if (processId <= 0 || !HasPendingRewatches())
+        {
+            return false;
+        }
+
+        var path = executablePath ?? GetExecutablePath(processId);
+        if (string.IsNullOrEmpty(path) || !Path.IsPathRooted(path))
+        {
+            return false;
+        }
+
+        // 終了した1プロセスにつき、再起動した1プロセスだけを追加
+        List<PendingRewatch> matches;
+        lock (_lockObject)
+        {
+            matches = _pendingRewatches
+                .Where(pending => PathComparer.Equals(pending.ExecutablePath, path))
+                .GroupBy(pending => pending.TagName)
+                .Select(group => group.First())
+                .ToList();
+            foreach (var pending in matches)
+            {
+                _pendingRewatches.Remove(pending);
+            }
+        }
+
+        var added = false;
+        foreach (var pending in matches)
+        {
+            // ディレクトリ監視などで既に追加済みの場合
+            if (GetTagsForProcess(processId).Contains(pending.TagName))
+            {
+                continue;
+            }
+
+            if (await AddTargetCoreAsync(processId, pending.TagName, null, path))
+            {
+                _logger.LogInformation("再起動したプロセスを再監視しました (ProcessId: {ProcessId}, Path: {Path}, Tag: {TagName})",
+                    processId, path, pending.TagName);
+                added = true;
+            }
+        }
+
+        return added;
+    }
+
+    /// <summary>
+    /// 期限切れの再監視待ちを破棄し、待機中のものが残っているかどうかを取得
+    /// </summary>
+    private bool HasPendingRewatches()
+    {
+        lock (_lockObject)
+        {
+            if (_pendingRewatches.Count == 0)
+            {
+                return false;
+            }
+
+            var now = DateTime.UtcNow;
+            var expired = _pendingRewatches.Where(pending => pending.ExpiresAt < now).ToList();
+            foreach (var pending in expired)
+            {
+                _pendingRewatches.Remove(pending);
+            }
+
+            // 再監視を待つ間だけ維持していたオプションを破棄
+            foreach (var tagName in expired.Select(pending => pending.TagName).Distinct())
+            {
+                RemoveOptionsIfUnused(tagName);
+            }
+
+            return _pendingRewatches.Count > 0;
+        }
+    }
+
+    /// <summary>
+    /// タグの伝播ルールに基づき子プロセスを追加しない理由を取得
+    /// </summary>
+    /// <param name="options">タグの監視オプション</param>
+    /// <param name="depth">子プロセスの深さ（明示的に追加した対象の直接の子が1）</param>
+    /// <param name="parentInfo">親プロセスの情報</param>
+    /// <param name="childInfo">子プロセスの情報</param>
+    /// <returns>追加しない理由（追加する場合はnull）</returns>
+    private static string? GetChildSkipReason(WatchTargetOptions options, int depth, ProcessInfo? parentInfo, ProcessInfo? childInfo)
+    {
+        if (options.MaxChildDepth is int maxDepth && depth > maxDepth)
+        {
+            return $"最大深さ{maxDepth}を超えています";
+        }
+
+        // プロセス情報が取得できない場合は判定できないため追加する
+        if (!options.FollowAcrossSessions &&
+            parentInfo?.SessionId is int parentSession &&
+            childInfo?.SessionId is int childSession &&
+            parentSession != childSession)
+        {
+            return $"セッション境界を越えています (Session: {parentSession} -> {childSession})";
+        }
+
+        if (childInfo != null && options.StopAtExecutables.Any(name => MatchesExecutable(name, childInfo)))
+        {
+            return $"停止対象の実行ファイルです ({childInfo.ProcessName})";
+        }
+
+        return null;
+    }
+
+    /// <summary>
+    /// オプションのパスパターンから無効なものを探す
+    /// </summary>
+    /// <returns>最初の無効なパターン（全て有効な場合はnull）</returns>
+    private static string? FindInvalidPattern(WatchTargetOptions options)
+    {
+        return options.IncludePaths
+            .Concat(options.ExcludePaths)
+            .Concat(options.HashOnClosePaths)
+            .Concat(options.ExcludedProcesses.Where(rule => !int.TryParse(rule, out _)))
+            .FirstOrDefault(pattern => !PathPattern.IsValid(pattern));
+    }
+
+    /// <summary>
+    /// 除外ルールがプロセスに一致するかどうか
+    /// </summary>
+    /// <param name="rule">PID、イメージ名（ワイルドカード可）、または区切り文字を含むパスのglobパターン</param>
+    /// <param name="processId">プロセスID</param>
+    /// <param name="executablePath">実行ファイルパス</param>
+    private static bool MatchesExclusion(string rule, int processId, string? executablePath)
+    {
+        if (int.TryParse(rule, out var excludedProcessId))
+        {
+            return excludedProcessId == processId;
+        }
+
+        if (string.IsNullOrEmpty(executablePath))
+        {
+            return false;
+        }
+
+        return PathPattern.HasPathSyntax(rule)
+            ? PathPattern.IsMatch(rule, executablePath)
+            : PathPattern.IsMatch(rule, Path.GetFileName(executablePath));
+    }
+
+    /// <summary>
+    /// 実行ファイル名（拡張子は省略可）がプロセスに一致するかどうか
+    /// </summary>
+    private static bool MatchesExecutable(string name, ProcessInfo info)
+    {
+        var fileName = Path.GetFileName(info.ExecutablePath);
+        return string.Equals(name, fileName, StringComparison.OrdinalIgnoreCase) ||
+               string.Equals(name, Path.GetFileNameWithoutExtension(fileName), StringComparison.OrdinalIgnoreCase) ||
+               string.Equals(Path.GetFileNameWithoutExtension(name), info.ProcessName, StringComparison.OrdinalIgnoreCase);
+    }
+
+    /// <summary>
+    /// 監視対象を全てのタグから除去
     /// </summary>
     /// <param name="processId">プロセスID</param>
     /// <returns>除去結果</returns>
@@ -153,23 +498,23 @@ public class WatchTargetManager : IWatchTargetManager, IDisposable
     {
         try
         {
-            if (_watchTargets.TryRemove(processId, out var watchTarget))
+            IReadOnlyList<WatchTarget>? watchTargets;
+            lock (_lockObject)
             {
-                // タグマッピングからも除去
-                lock (_lockObject)
+                if (_watchTargets.TryRemove(processId, out watchTargets))
                 {
-                    if (_tagToProcessMap.TryGetValue(watchTarget.TagName, out var processSet))
+                    foreach (var watchTarget in watchTargets)
                     {
-                        processSet.Remove(processId);
-                        if (processSet.Count == 0)
-                        {
-                            _tagToProcessMap.TryRemove(watchTarget.TagName, out _);
-                        }
+                        AddPendingRewatch(watchTarget);
+                        RemoveFromTagMap(processId, watchTarget.TagName);
                     }
                 }
+            }
 
-                _logger.LogInformation("監視対象を除去しました (ProcessId: {ProcessId}, Tag: {TagName})", 
-                    processId, watchTarget.TagName);
+            if (watchTargets != null)
+            {
+                _logger.LogInformation("監視対象を除去しました (ProcessId: {ProcessId}, Tags: {TagNames})", 
+                    processId, string.Join(", ", watchTargets.Select(target => target.TagName)));
                 return true;
             }
             else
@@ -185,6 +530,80 @@ public class WatchTargetManager : IWatchTargetManager, IDisposable
         }
     }
 
+    /// <summary>
+    /// 監視対象を指定したタグからのみ除去（他のタグでの監視は継続）
+    /// </summary>
+    /// <returns>除去した場合true</returns>
+    private bool RemoveTargetFromTag(int processId, string tagName)
+    {
+        lock (_lockObject)
+        {
+            if (!_watchTargets.TryGetValue(processId, out var targets) || !targets.Any(target => target.TagName == tagName))
+            {
+                return false;
+            }
+
+            var remaining = targets.Where(target => target.TagName != tagName).ToList();
+            if (remaining.Count == 0)
+            {
+                _watchTargets.TryRemove(processId, out _);
+            }
+            else
+            {
+                _watchTargets[processId] = remaining;
+            }
+
+            RemoveFromTagMap(processId, tagName);
+        }
+
+        _logger.LogInformation("監視対象を除去しました (ProcessId: {ProcessId}, Tag: {TagName})", processId, tagName);
+        return true;
+    }
+
+    /// <summary>
+    /// 終了した監視対象のタグに再監視期間が指定されている場合、同じ実行ファイルの再起動を待つ（ロック内で呼び出す）
+    /// </summary>
+    private void AddPendingRewatch(WatchTarget watchTarget)
+    {
+        var windowMs = GetOptionsForTag(watchTarget.TagName).RewatchWindowMs;
+        if (windowMs <= 0 || string.IsNullOrEmpty(watchTarget.ExecutablePath) || !Path.IsPathRooted(watchTarget.ExecutablePath))
+        {
+            return;
+        }
+
+        _pendingRewatches.Add(new PendingRewatch(watchTarget.TagName, watchTarget.ExecutablePath, DateTime.UtcNow.AddMilliseconds(windowMs)));
+    }
+
+    /// <summary>
+    /// タグマッピングからプロセスを除去（ロック内で呼び出す）
+    /// </summary>
+    private void RemoveFromTagMap(int processId, string tagName)
+    {
+        if (_tagToProcessMap.TryGetValue(tagName, out var processSet))
+        {
+            processSet.Remove(processId);
+            if (processSet.Count == 0)
+            {
+                _tagToProcessMap.TryRemove(tagName, out _);
+                RemoveOptionsIfUnused(tagName);
+            }
+        }
+    }
+
+    /// <summary>
+    /// 監視中のプロセスがなくなったタグのオプションを破棄（ロック内で呼び出す）
+    /// </summary>
+    private void RemoveOptionsIfUnused(string tagName)
+    {
+        // ディレクトリ監視や再監視待ちが残っているタグは、今後起動するプロセスのためにオプションを維持
+        if (!_tagToProcessMap.ContainsKey(tagName) &&
+            !_pathTargets.Values.Contains(tagName) &&
+            !_pendingRewatches.Any(pending => pending.TagName == tagName))
+        {
+            _tagOptions.TryRemove(tagName, out _);
+        }
+    }
+
     /// <summary>
     /// タグ名で監視対象を除去
     /// </summary>
@@ -209,13 +628,25 @@ public class WatchTargetManager : IWatchTargetManager, IDisposable
                 {
                     processIds = new HashSet<int>(processIds); // コピーを作成
                 }
+
+                // ディレクトリ監視の登録も除去対象として数える
+                foreach (var directory in _pathTargets.Where(entry => entry.Value == tagName).Select(entry => entry.Key).ToList())
+                {
+                    if (_pathTargets.TryRemove(directory, out _))
+                    {
+                        removedCount++;
+                    }
+                }
+
+                _pendingRewatches.RemoveAll(pending => pending.TagName == tagName);
+                _tagOptions.TryRemove(tagName, out _);
             }
 
             if (processIds != null)
             {
                 foreach (var processId in processIds)
                 {
-                    if (RemoveTarget(processId))
+                    if (RemoveTargetFromTag(processId, tagName))
                     {
                         removedCount++;
                     }
@@ -247,10 +678,84 @@ public class WatchTargetManager : IWatchTargetManager, IDisposable
     /// プロセスIDに対応するタグ名を取得
     /// </summary>
     /// <param name="processId">プロセスID</param>
-    /// <returns>タグ名（見つからない場合はnull）</returns>
+    /// <returns>最初に追加したタグ名（見つからない場合はnull）</returns>
     public string? GetTagForProcess(int processId)
     {
-        return _watchTargets.TryGetValue(processId, out var watchTarget) ? watchTarget.TagName : null;
+        return _watchTargets.TryGetValue(processId, out var watchTargets) ? watchTargets[0].TagName : null;
+    }
+
+    /// <summary>
+    /// プロセスIDが属する全てのタグ名を取得
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <returns>追加順のタグ名（監視対象でない場合は空）</returns>
+    public IReadOnlyList<string> GetTagsForProcess(int processId)
+    {
+        return _watchTargets.TryGetValue(processId, out var watchTargets)
+            ? watchTargets.Select(target => target.TagName).ToList()
+            : Array.Empty<string>();
+    }
+
+    /// <summary>
+    /// プロセスがタグの除外ルールに一致するかチェック
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <param name="tagName">タグ名</param>
+    /// <returns>除外対象の場合true</returns>
+    public bool IsExcludedProcess(int processId, string tagName)
+    {
+        if (!_watchTargets.TryGetValue(processId, out var watchTargets))
+        {
+            return false;
+        }
+
+        var watchTarget = watchTargets.FirstOrDefault(target => target.TagName == tagName);
+        return watchTarget != null && IsExcluded(GetOptionsForTag(tagName), watchTarget);
+    }
+
+    /// <summary>
+    /// 監視対象エントリがタグの除外ルールに一致するかどうか
+    /// </summary>
+    private static bool IsExcluded(WatchTargetOptions options, WatchTarget watchTarget)
+    {
+        var rules = options.ExcludedProcesses;
+        return rules.Count > 0 && rules.Any(rule => MatchesExclusion(rule, watchTarget.ProcessId, watchTarget.ExecutablePath));
+    }
+
+    /// <summary>
+    /// 監視中のプロセスの実行ファイルパスを更新（exec後など）
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <param name="executablePath">実行ファイルパス</param>
+    public void UpdateExecutablePath(int processId, string executablePath)
+    {
+        lock (_lockObject)
+        {
+            if (_watchTargets.TryGetValue(processId, out var watchTargets) && watchTargets.Any(target => target.ExecutablePath != executablePath))
+            {
+                _watchTargets[processId] = watchTargets.Select(target => target with { ExecutablePath = executablePath }).ToList();
+            }
+        }
+    }
+
+    /// <summary>
+    /// タグの監視オプションを取得
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <returns>監視オプション（未指定の場合は既定値）</returns>
+    public WatchTargetOptions GetOptionsForTag(string tagName)
+    {
+        return _tagOptions.TryGetValue(tagName, out var options) ? options : WatchTargetOptions.Default;
+    }
+
+    /// <summary>
+    /// タグのメタデータを取得
+    /// </summary>
+    /// <returns>メタデータ（未指定の場合はnull）</returns>
+    private IReadOnlyDictionary<string, string>? GetMetadataForTag(string tagName)
+    {
+        var metadata = GetOptionsForTag(tagName).Metadata;
+        return metadata.Count > 0 ? metadata : null;
     }
 
     /// <summary>
@@ -259,7 +764,7 @@ public class WatchTargetManager : IWatchTargetManager, IDisposable
     /// <returns>全監視対象のリスト</returns>
     public IReadOnlyList<WatchTarget> GetWatchTargets()
     {
-        return _watchTargets.Values.ToList().AsReadOnly();
+        return _watchTargets.Values.SelectMany(targets => targets).ToList().AsReadOnly();
     }
 
     /// <summary>
@@ -272,44 +777,39 @@ public class WatchTargetManager : IWatchTargetManager, IDisposable
         {
             var watchTargetInfos = new List<WatchTargetInfo>();
 
-            foreach (var watchTarget in _watchTargets.Values)
+            foreach (var (processId, watchTargets) in _watchTargets)
             {
+                string processName;
+                string executablePath;
                 try
                 {
-                    using var process = System.Diagnostics.Process.GetProcessById(watchTarget.ProcessId);
-                    var processName = process.ProcessName;
-                    var executablePath = GetProcessExecutablePath(process);
-
-                    watchTargetInfos.Add(new WatchTargetInfo(
-                        watchTarget.ProcessId,
-                        processName,
-                        executablePath,
-                        watchTarget.RegisteredAt,
-                        watchTarget.TagName
-                    ));
+                    using var process = System.Diagnostics.Process.GetProcessById(processId);
+                    processName = process.ProcessName;
+                    executablePath = GetProcessExecutablePath(process);
                 }
                 catch (ArgumentException)
                 {
                     // プロセスが既に終了している場合
-                    watchTargetInfos.Add(new WatchTargetInfo(
-                        watchTarget.ProcessId,
-                        "[Terminated]",
-                        "[Unknown]",
-                        watchTarget.RegisteredAt,
-                        watchTarget.TagName
-                    ));
+                    processName = "[Terminated]";
+                    executablePath = "[Unknown]";
                 }
                 catch (Exception ex)
                 {
-                    _logger.LogWarning(ex, "プロセス情報取得に失敗しました (ProcessId: {ProcessId})", watchTarget.ProcessId);
-                    watchTargetInfos.Add(new WatchTargetInfo(
-                        watchTarget.ProcessId,
-                        "[Error]",
-                        "[Unknown]",
-                        watchTarget.RegisteredAt,
-                        watchTarget.TagName
-                    ));
+                    _logger.LogWarning(ex, "プロセス情報取得に失敗しました (ProcessId: {ProcessId})", processId);
+                    processName = "[Error]";
+                    executablePath = "[Unknown]";
                 }
+
+                // 複数のタグに属するプロセスはタグごとに1件
+                watchTargetInfos.AddRange(watchTargets.Select(watchTarget => new WatchTargetInfo(
+                    processId,
+                    processName,
+                    executablePath,
+                    watchTarget.RegisteredAt,
+                    watchTarget.TagName,
+                    watchTarget.Ancestors,
+                    GetMetadataForTag(watchTarget.TagName)
+                )));
             }
 
             return Task.FromResult(watchTargetInfos);
@@ -321,6 +821,94 @@ public class WatchTargetManager : IWatchTargetManager, IDisposable
         }
     }
 
+    /// <summary>
+    /// 親プロセスをセッションのルートまでたどって祖先一覧を作成
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <returns>祖先一覧（直近の親が先頭）</returns>
+    private IReadOnlyList<ProcessAncestor>? BuildAncestors(int processId)
+    {
+        if (_processValidator == null)
+        {
+            return null;
+        }
+
+        var ancestors = new List<ProcessAncestor>();
+        try
+        {
+            var visited = new HashSet<int> { processId };
+            var current = _processValidator.GetProcessInfo(processId);
+
+            while (current?.ParentProcessId is int parentId && parentId > 0 &&
+                   ancestors.Count < MaxAncestorDepth && visited.Add(parentId))
+            {
+                var parent = _processValidator.GetProcessInfo(parentId);
+
+                // 子より後に開始したプロセスは、終了した親のPIDが再利用されたもの
+                if (parent != null && parent.StartTime > current.StartTime && current.StartTime != DateTime.MinValue)
+                {
+                    parent = null;
+                }
+
+                ancestors.Add(ToAncestor(parentId, parent));
+                current = parent;
+            }
+        }
+        catch (Exception ex)
+        {
+            _logger.LogWarning(ex, "祖先プロセスの取得中にエラーが発生しました (ProcessId: {ProcessId})", processId);
+        }
+
+        return ancestors;
+    }
+
+    /// <summary>
+    /// プロセス情報を祖先エントリに変換
+    /// </summary>
+    private static ProcessAncestor ToAncestor(int processId, ProcessInfo? info)
+    {
+        return info == null
+            ? new ProcessAncestor(processId, "[Terminated]", "[Unknown]", null)
+            : new ProcessAncestor(
+                processId,
+                info.ProcessName,
+                info.ExecutablePath,
+                info.StartTime == DateTime.MinValue ? null : info.StartTime);
+    }
+
+    /// <summary>
+    /// ディレクトリを前方一致で比較できるよう正規化（末尾に区切り文字を付与）
+    /// </summary>
+    private static string NormalizeDirectory(string directory)
+    {
+        return Path.TrimEndingDirectorySeparator(Path.GetFullPath(directory)) + Path.DirectorySeparatorChar;
+    }
+
+    /// <summary>
+    /// プロセスIDから実行ファイルパスを取得
+    /// </summary>
+    private string? GetExecutablePath(int processId)
+    {
+        if (_processValidator != null)
+        {
+            return _processValidator.GetProcessInfo(processId)?.ExecutablePath;
+        }
+
+        try
+        {
+            using var process = System.Diagnostics.Process.GetProcessById(processId);
+            return GetProcessExecutablePath(process);
+        }
+        catch (ArgumentException)
+        {
+            return null;
+        }
+        catch (InvalidOperationException)
+        {
+            return null;
+        }
+    }
+
     /// <summary>
     /// プロセスの実行ファイルパスを取得
     /// </summary>
@@ -352,8 +940,16 @@ public class WatchTargetManager : IWatchTargetManager, IDisposable
         lock (_lockObject)
         {
             _tagToProcessMap.Clear();
+            _tagOptions.Clear();
+            _pathTargets.Clear();
+            _pendingRewatches.Clear();
         }
 
         _logger.LogInformation("WatchTargetManagerが解放されました");
     }
+
+    /// <summary>
+    /// 再起動を待っている終了済みの監視対象
+    /// </summary>
+    private sealed record PendingRewatch(string TagName, string ExecutablePath, DateTime ExpiresAt);
 }
\ No newline at end of file
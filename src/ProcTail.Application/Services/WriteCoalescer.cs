@@ -0,0 +1,163 @@
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Services;
+
+/// <summary>
+/// 同じファイルへの連続した書き込みイベントを1件の集約イベントにまとめる
+/// </summary>
+/// <remarks>
+/// 最初の書き込みからウィンドウ内に続いた書き込みを保留し、ウィンドウ経過後に件数・合計バイト数・最初と最後の時刻を持つ1件として出力する。
+/// 書き込み以外のイベントを受け取った場合は、記録順を保つため同じタグの保留中の書き込みを先に出力する。
+/// </remarks>
+public class WriteCoalescer
+{
+    private readonly Dictionary<(string TagName, int ProcessId, string File), PendingWrite> _pending = new();
+    private readonly object _lockObject = new();
+
+    /// <summary>
+    /// 保留中の集約数
+    /// </summary>
+    public int PendingCount
+    {
+        get
+        {
+            lock (_lockObject)
+            {
+                return _pending.Count;
+            }
+        }
+    }
+
+    /// <summary>
+    /// イベントを追加し、保存すべきイベントを返す
+    /// </summary>
+    /// <param name="eventData">イベント</param>
+    /// <param name="window">タグの集約ウィンドウ（0以下の場合は集約しない）</param>
+    /// <returns>保存すべきイベント（保留した場合は空）</returns>
+    public IReadOnlyList<BaseEventData> Add(BaseEventData eventData, TimeSpan window)
+    {
+        var ready = new List<BaseEventData>();
+
+        lock (_lockObject)
+        {
+            if (window > TimeSpan.Zero && eventData is FileEventData { EventName: "FileIO/Write" } write)
+            {
+                // 同じハンドルの書き込みをまとめる（相関IDがない場合はファイルパスで代用）
+                var key = (write.TagName, write.ProcessId, write.CorrelationId?.ToString() ?? write.FilePath);
+                if (_pending.TryGetValue(key, out var pending))
+                {
+                    if (write.Timestamp - pending.First.Timestamp < window)
+                    {
+                        pending.Append(write);
+                        return ready;
+                    }
+
+                    _pending.Remove(key);
+                    ready.Add(pending.ToEvent());
+                }
+
+                _pending[key] = new PendingWrite(write, DateTime.UtcNow + window);
+                return ready;
+            }
+
+            FlushWhere(key => key.TagName == eventData.TagName, ready);
+        }
+
+        ready.Add(eventData);
+        return ready;
+    }
+
+    /// <summary>
+    /// ウィンドウが経過した集約を出力
+    /// </summary>
+    /// <param name="utcNow">現在時刻（UTC）</param>
+    /// <returns>保存すべきイベント</returns>
+    public IReadOnlyList<BaseEventData> FlushExpired(DateTime utcNow)
+    {
+        var ready = new List<BaseEventData>();
+        lock (_lockObject)
+        {
+            FlushWhere(key => _pending[key].ExpiresAt <= utcNow, ready);
+        }
+
+        return ready;
+    }
+
+    /// <summary>
+    /// 全ての集約を出力
+    /// </summary>
+    /// <returns>保存すべきイベント</returns>
+    public IReadOnlyList<BaseEventData> FlushAll()
+    {
+        var ready = new List<BaseEventData>();
+        lock (_lockObject)
+        {
+            FlushWhere(_ => true, ready);
+        }
+
+        return ready;
+    }
+
+    private void FlushWhere(Func<(string TagName, int ProcessId, string File), bool> predicate, List<BaseEventData> ready)
+    {
+        foreach (var key in _pending.Keys.Where(predicate).ToList())
+        {
+            ready.Add(_pending[key].ToEvent());
+            _pending.Remove(key);
+        }
+    }
+
+    private sealed class PendingWrite
+    {
+        private FileEventData _last;
+        private int _count = 1;
+        private long? _totalBytes;
+
+        public PendingWrite(FileEventData first, DateTime expiresAt)
+        {
+            First = first;
+            ExpiresAt = expiresAt;
+            _last = first;
+            _totalBytes = GetIoSize(first);
+        }
+
+        public FileEventData First { get; }
+
+        public DateTime ExpiresAt { get; }
+
+        public void Append(FileEventData write)
+        {
+            _last = write;
+            _count++;
+
+            var size = GetIoSize(write);
+            if (size.HasValue)
+            {
+                _totalBytes = (_totalBytes ?? 0) + size.Value;
+            }
+        }
+
+        public FileEventData ToEvent()
+        {
+            if (_count == 1)
+            {
+                return First;
+            }
+
+            return First with
+            {
+                Timestamp = _last.Timestamp,
+                FirstTimestamp = First.Timestamp,
+                CoalescedCount = _count,
+                TotalBytes = _totalBytes
+            };
+        }
+
+        private static long? GetIoSize(FileEventData write)
+        {
+            return write.Payload.TryGetValue("IoSize", out var value) && long.TryParse(value?.ToString(), out var size)
+                ? size
+                : null;
+        }
+    }
+}
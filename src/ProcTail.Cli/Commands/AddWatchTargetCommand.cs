@@ -1,5 +1,6 @@
 using System.CommandLine.Invocation;
 using ProcTail.Cli.Services;
+using ProcTail.Core.Models;
 
 namespace ProcTail.Cli.Commands;
 
@@ -22,7 +23,31 @@ public class AddWatchTargetCommand : BaseCommand
     {
         var processId = 0;
         var processName = "";
+        var directory = "";
         var tagName = "";
+        var includeThreads = false;
+        var readSampleRate = 0;
+        var environmentVariables = Array.Empty<string>();
+        int? maxChildDepth = null;
+        var sameSessionOnly = false;
+        var stopAtExecutables = Array.Empty<string>();
+        var excludedProcesses = Array.Empty<string>();
+        var includePaths = Array.Empty<string>();
+        var excludePaths = Array.Empty<string>();
+        var recordRoots = Array.Empty<string>();
+        var hashOnClosePaths = Array.Empty<string>();
+        var maxEventsPerSecond = 0;
+        var coalesceWritesMs = 0;
+        var backpressure = "";
+        int? blockTimeoutMs = null;
+        var backend = "";
+        var watchDirectories = Array.Empty<string>();
+        int? pollIntervalMs = null;
+        var resourceIntervalMs = 0;
+        var rewatchWindowMs = 0;
+        var idleTimeoutMs = 0;
+        var detectHangs = false;
+        var metadataEntries = Array.Empty<string>();
         
         // オプション値を取得
         foreach (var option in context.ParseResult.CommandResult.Command.Options)
@@ -38,19 +63,186 @@ public class AddWatchTargetCommand : BaseCommand
                 case "name":
                     processName = value as string;
                     break;
+                case "path":
+                    directory = value as string ?? "";
+                    break;
                 case "tag":
                     tagName = value as string ?? "";
                     break;
+                case "threads":
+                    includeThreads = (bool?)value ?? false;
+                    break;
+                case "read-sample-rate":
+                    readSampleRate = (int?)value ?? 0;
+                    break;
+                case "env":
+                    environmentVariables = value as string[] ?? Array.Empty<string>();
+                    break;
+                case "max-depth":
+                    maxChildDepth = (int?)value;
+                    break;
+                case "same-session":
+                    sameSessionOnly = (bool?)value ?? false;
+                    break;
+                case "stop-at":
+                    stopAtExecutables = value as string[] ?? Array.Empty<string>();
+                    break;
+                case "exclude":
+                    excludedProcesses = value as string[] ?? Array.Empty<string>();
+                    break;
+                case "include-path":
+                    includePaths = ExpandPathPatterns(value as string[]);
+                    break;
+                case "exclude-path":
+                    excludePaths = ExpandPathPatterns(value as string[]);
+                    break;
+                case "record-root":
+                    recordRoots = ExpandPathPatterns(value as string[]).Select(Path.GetFullPath).ToArray();
+                    break;
+                case "hash-on-close":
+                    hashOnClosePaths = ExpandPathPatterns(value as string[]);
+                    break;
+                case "max-events-per-second":
+                    maxEventsPerSecond = (int?)value ?? 0;
+                    break;
+                case "coalesce-writes":
+                    coalesceWritesMs = (int?)value ?? 0;
+                    break;
+                case "backpressure":
+                    backpressure = value as string ?? "";
+                    break;
+                case "block-timeout":
+                    blockTimeoutMs = (int?)value;
+                    break;
+                case "backend":
+                    backend = value as string ?? "";
+                    break;
+                case "watch-dir":
+                    watchDirectories = ExpandPathPatterns(value as string[]).Select(Path.GetFullPath).ToArray();
+                    break;
+                case "poll-interval":
+                    pollIntervalMs = (int?)value;
+                    break;
+                case "resource-interval":
+                    resourceIntervalMs = (int?)value ?? 0;
+                    break;
+                case "rewatch":
+                    rewatchWindowMs = (int?)value ?? 0;
+                    break;
+                case "idle-timeout":
+                    idleTimeoutMs = (int?)value ?? 0;
+                    break;
+                case "detect-hangs":
+                    detectHangs = (bool?)value ?? false;
+                    break;
+                case "meta":
+                    metadataEntries = value as string[] ?? Array.Empty<string>();
+                    break;
             }
         }
 
-        // プロセスIDまたはプロセス名のいずれかが必要
-        if (processId == 0 && string.IsNullOrEmpty(processName))
+        // プロセスID・プロセス名・ディレクトリのいずれかが必要
+        if (processId == 0 && string.IsNullOrEmpty(processName) && string.IsNullOrEmpty(directory))
         {
-            WriteError("プロセスIDまたはプロセス名を指定してください。");
+            WriteError("プロセスID、プロセス名またはディレクトリを指定してください。");
             WriteInfo("使用例:");
             WriteInfo("  proctail add --pid 1234 --tag my-app");
             WriteInfo("  proctail add --name notepad.exe --tag notepad");
+            WriteInfo("  proctail add --path \"C:\\Games\\MyGame\" --tag my-game");
+            context.ExitCode = 1;
+            return;
+        }
+
+        if (readSampleRate < 0)
+        {
+            WriteError("--read-sample-rate には0以上の値を指定してください。");
+            context.ExitCode = 1;
+            return;
+        }
+
+        if (maxEventsPerSecond < 0)
+        {
+            WriteError("--max-events-per-second には0以上の値を指定してください。");
+            context.ExitCode = 1;
+            return;
+        }
+
+        if (coalesceWritesMs < 0)
+        {
+            WriteError("--coalesce-writes には0以上の値を指定してください。");
+            context.ExitCode = 1;
+            return;
+        }
+
+        var backpressurePolicy = ParseBackpressurePolicy(backpressure);
+        if (!string.IsNullOrEmpty(backpressure) && backpressurePolicy == null)
+        {
+            WriteError("--backpressure には drop-oldest, drop-newest, block のいずれかを指定してください。");
+            context.ExitCode = 1;
+            return;
+        }
+
+        if (blockTimeoutMs < 0)
+        {
+            WriteError("--block-timeout には0以上の値を指定してください。");
+            context.ExitCode = 1;
+            return;
+        }
+
+        var fileEventBackend = ParseFileEventBackend(backend);
+        if (!string.IsNullOrEmpty(backend) && fileEventBackend == null)
+        {
+            WriteError("--backend には etw, directory-watcher, polling のいずれかを指定してください。");
+            context.ExitCode = 1;
+            return;
+        }
+
+        if (fileEventBackend is FileEventBackend.DirectoryWatcher or FileEventBackend.Polling && watchDirectories.Length == 0)
+        {
+            WriteError($"--backend {backend} には --watch-dir で監視するディレクトリを指定してください。");
+            context.ExitCode = 1;
+            return;
+        }
+
+        if (pollIntervalMs < 100)
+        {
+            WriteError("--poll-interval には100以上の値を指定してください。");
+            context.ExitCode = 1;
+            return;
+        }
+
+        if (resourceIntervalMs is < 0 or > 0 and < 1000)
+        {
+            WriteError("--resource-interval には0（記録しない）または1000以上の値を指定してください。");
+            context.ExitCode = 1;
+            return;
+        }
+
+        if (rewatchWindowMs < 0)
+        {
+            WriteError("--rewatch には0以上の値を指定してください。");
+            context.ExitCode = 1;
+            return;
+        }
+
+        if (idleTimeoutMs is < 0 or > 0 and < 1000)
+        {
+            WriteError("--idle-timeout には0（記録しない）または1000以上の値を指定してください。");
+            context.ExitCode = 1;
+            return;
+        }
+
+        if (maxChildDepth < 0)
+        {
+            WriteError("--max-depth には0以上の値を指定してください。");
+            context.ExitCode = 1;
+            return;
+        }
+
+        var metadata = ParseMetadata(metadataEntries);
+        if (metadata == null)
+        {
+            WriteError("--meta は key=value の形式で指定してください。");
             context.ExitCode = 1;
             return;
         }
@@ -64,6 +256,59 @@ public class AddWatchTargetCommand : BaseCommand
 
         try
         {
+            var hasOptions = includeThreads || readSampleRate > 0 || environmentVariables.Length > 0 ||
+                             maxChildDepth.HasValue || sameSessionOnly || stopAtExecutables.Length > 0 ||
+                             excludedProcesses.Length > 0 || includePaths.Length > 0 || excludePaths.Length > 0 ||
+                             recordRoots.Length > 0 || hashOnClosePaths.Length > 0 ||
+                             maxEventsPerSecond > 0 || coalesceWritesMs > 0 ||
+                             backpressurePolicy.HasValue || blockTimeoutMs.HasValue ||
+                             fileEventBackend.HasValue || watchDirectories.Length > 0 || pollIntervalMs.HasValue ||
+                             resourceIntervalMs > 0 || rewatchWindowMs > 0 || idleTimeoutMs > 0 || detectHangs || metadata.Count > 0;
+            var options = hasOptions
+                ? new WatchTargetOptions
+                {
+                    IncludeThreadEvents = includeThreads,
+                    FileReadSampleRate = readSampleRate,
+                    EnvironmentVariables = environmentVariables,
+                    MaxChildDepth = maxChildDepth,
+                    FollowAcrossSessions = !sameSessionOnly,
+                    StopAtExecutables = stopAtExecutables,
+                    ExcludedProcesses = excludedProcesses,
+                    IncludePaths = includePaths,
+                    ExcludePaths = excludePaths,
+                    RecordRoots = recordRoots,
+                    HashOnClosePaths = hashOnClosePaths,
+                    MaxEventsPerSecond = maxEventsPerSecond,
+                    WriteCoalescingWindowMs = coalesceWritesMs,
+                    BackpressurePolicy = backpressurePolicy ?? BackpressurePolicy.DropOldest,
+                    BlockTimeoutMs = blockTimeoutMs ?? 1000,
+                    Backend = fileEventBackend ?? FileEventBackend.Etw,
+                    WatchDirectories = watchDirectories,
+                    PollIntervalMs = pollIntervalMs ?? 2000,
+                    ResourceSnapshotIntervalMs = resourceIntervalMs,
+                    RewatchWindowMs = rewatchWindowMs,
+                    IdleTimeoutMs = idleTimeoutMs,
+                    DetectHangs = detectHangs,
+                    Metadata = metadata
+                }
+                : null;
+
+            // ディレクトリが指定された場合、配下から起動するプロセスを監視
+            if (!string.IsNullOrEmpty(directory))
+            {
+                var pathResponse = await _pipeClient.AddWatchTargetByPathAsync(tagName, Path.GetFullPath(directory), options, context.GetCancellationToken());
+                if (pathResponse.Success)
+                {
+                    WriteSuccess($"ディレクトリ監視を追加しました (ディレクトリ: {directory}, タグ: {tagName}, 実行中のプロセス: {pathResponse.AddedCount}件)");
+                }
+                else
+                {
+                    WriteError($"ディレクトリ監視の追加に失敗しました: {pathResponse.ErrorMessage}");
+                    context.ExitCode = 1;
+                }
+                return;
+            }
+
             // プロセス名が指定された場合、プロセスIDを検索
             if (processId == 0 && !string.IsNullOrEmpty(processName))
             {
@@ -77,8 +322,8 @@ public class AddWatchTargetCommand : BaseCommand
                 WriteInfo($"プロセス '{processName}' のPID {processId} を監視対象に追加します。");
             }
 
-            // 監視対象を追加
-            var response = await _pipeClient.AddWatchTargetAsync(processId, tagName, context.GetCancellationToken());
+            // 監視対象を追加（オプション未指定の場合はタグの既存設定を維持）
+            var response = await _pipeClient.AddWatchTargetAsync(processId, tagName, options, context.GetCancellationToken());
 
             if (response.Success)
             {
@@ -97,6 +342,62 @@ public class AddWatchTargetCommand : BaseCommand
         }
     }
 
+    /// <summary>
+    /// パスパターン中の環境変数を展開（サービスは別ユーザーで動作し得るため、CLI側のユーザー環境で展開する）
+    /// </summary>
+    private static string[] ExpandPathPatterns(string[]? patterns)
+    {
+        return patterns?.Select(Environment.ExpandEnvironmentVariables).ToArray() ?? Array.Empty<string>();
+    }
+
+    /// <summary>
+    /// key=value 形式のメタデータを解析（キーが空の要素がある場合はnull）
+    /// </summary>
+    private static Dictionary<string, string>? ParseMetadata(string[] entries)
+    {
+        var metadata = new Dictionary<string, string>();
+        foreach (var entry in entries)
+        {
+            var separatorIndex = entry.IndexOf('=');
+            if (separatorIndex <= 0)
+            {
+                return null;
+            }
+
+            metadata[entry[..separatorIndex].Trim()] = entry[(separatorIndex + 1)..];
+        }
+
+        return metadata;
+    }
+
+    /// <summary>
+    /// バッファ満杯時の動作を解析（未指定または不明な値の場合はnull）
+    /// </summary>
+    private static BackpressurePolicy? ParseBackpressurePolicy(string value)
+    {
+        return value.ToLowerInvariant() switch
+        {
+            "drop-oldest" => BackpressurePolicy.DropOldest,
+            "drop-newest" => BackpressurePolicy.DropNewest,
+            "block" => BackpressurePolicy.Block,
+            _ => null
+        };
+    }
+
+    /// <summary>
+    /// ファイルイベントの取得元を解析（未指定または不明な値の場合はnull）
+    /// </summary>
+    private static FileEventBackend? ParseFileEventBackend(string value)
+    {
+        return value.ToLowerInvariant() switch
+        {
+            "etw" => FileEventBackend.Etw,
+            "directory-watcher" => FileEventBackend.DirectoryWatcher,
+            "polling" => FileEventBackend.Polling,
+            _ => null
+        };
+    }
+
     /// <summary>
     /// プロセス名からプロセスIDを検索
     /// </summary>
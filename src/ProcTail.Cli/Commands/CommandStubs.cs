@@ -154,6 +154,12 @@ public class ListWatchTargetsCommand : BaseCommand
             var path = TruncateString(target.ExecutablePath, pathWidth);
 
             Console.WriteLine($"{pid} {tag} {name} {startTime} {path}");
+
+            if (target.Ancestors is { Count: > 0 } ancestors)
+            {
+                var chain = string.Join(" <- ", ancestors.Select(a => $"{a.ProcessName}({a.ProcessId})"));
+                Console.WriteLine($"{"".PadRight(pidWidth)} 祖先: {chain}");
+            }
         }
     }
 
@@ -221,6 +227,34 @@ public class GetStatusCommand : BaseCommand
                     Console.WriteLine($"総タグ数: {response.TotalTags}");
                     Console.WriteLine($"総イベント数: {response.TotalEvents}");
                     Console.WriteLine($"推定メモリ使用量: {response.EstimatedMemoryUsageMB}MB");
+                    Console.WriteLine($"ETWの取りこぼし: {response.EtwEventsLost}件");
+                    Console.WriteLine($"生イベントのパススルー: {(response.IsRawPassthroughEnabled ? "有効" : "無効")}");
+                    foreach (var buffer in response.Buffers)
+                    {
+                        Console.WriteLine($"バッファ [{buffer.TagName}]: {buffer.EventCount}件, 満杯時: {buffer.Policy}, 破棄: {buffer.DroppedCount}件, " +
+                                          $"除外 (プロセス: {buffer.ExcludedProcessCount}件, パス: {buffer.PathFilteredCount}件, レート制限: {buffer.RateLimitedCount}件)" +
+                                          (buffer.OutsideRootCount > 0 ? $", 記録ディレクトリ外: {buffer.OutsideRootCount}件 ({buffer.OutsideRootBytesWritten} bytes書き込み)" : ""));
+                    }
+                    if (response.ClockMapping != null)
+                    {
+                        Console.WriteLine($"クロック対応付け: {response.ClockMapping.Frequency}Hz, 基準値 {response.ClockMapping.SyncTimestamp} = {response.ClockMapping.SyncTimeUtc:O}");
+                    }
+                    foreach (var diagnostic in response.Diagnostics)
+                    {
+                        var line = $"起動時診断 [{diagnostic.Name}]: {diagnostic.Message}";
+                        switch (diagnostic.Status)
+                        {
+                            case "Failed":
+                                WriteError(line);
+                                break;
+                            case "Warning":
+                                WriteWarning(line);
+                                break;
+                            default:
+                                Console.WriteLine(line);
+                                break;
+                        }
+                    }
                 }
             }
             else
@@ -550,7 +584,24 @@ public class ServiceInstallCommand
             if (result)
             {
                 WriteSuccess($"サービス '{serviceName}' が正常にインストールされました。");
-                WriteInfo("'proctail service start' でサービスを開始してください。");
+
+                var startAfterInstall = context.ParseResult.CommandResult.Command.Options
+                    .Where(option => option.Name == "start")
+                    .Select(option => (bool?)context.ParseResult.GetValueForOption(option) ?? false)
+                    .FirstOrDefault();
+                if (!startAfterInstall)
+                {
+                    WriteInfo("'proctail service start' でサービスを開始してください。");
+                }
+                else if (await _serviceManager.StartServiceAsync(serviceName))
+                {
+                    WriteSuccess("サービスが正常に開始されました。");
+                }
+                else
+                {
+                    WriteError("サービスの開始に失敗しました。");
+                    context.ExitCode = 1;
+                }
             }
             else
             {
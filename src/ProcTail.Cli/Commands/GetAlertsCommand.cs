@@ -0,0 +1,119 @@
+using System.CommandLine.Invocation;
+using System.Text.Json;
+using ProcTail.Cli.Services;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Cli.Commands;
+
+/// <summary>
+/// アラート取得コマンド
+/// </summary>
+public class GetAlertsCommand : BaseCommand
+{
+    public GetAlertsCommand(IProcTailPipeClient pipeClient) : base(pipeClient) { }
+
+    public override async Task ExecuteAsync(InvocationContext context)
+    {
+        string? tagName = null;
+        var format = "table";
+        var follow = false;
+
+        // オプション値を取得
+        foreach (var option in context.ParseResult.CommandResult.Command.Options)
+        {
+            var value = context.ParseResult.GetValueForOption(option);
+            switch (option.Name)
+            {
+                case "tag":
+                    tagName = value as string;
+                    break;
+                case "format":
+                    format = value as string ?? "table";
+                    break;
+                case "follow":
+                    follow = (bool?)value ?? false;
+                    break;
+            }
+        }
+
+        if (!await TestServiceConnectionAsync())
+        {
+            context.ExitCode = 1;
+            return;
+        }
+
+        var cancellationToken = context.GetCancellationToken();
+        var lastSequenceNumber = 0L;
+        var pollInterval = TimeSpan.FromSeconds(1);
+
+        try
+        {
+            if (follow)
+            {
+                WriteInfo("アラートを監視中... (Ctrl+C で停止)");
+            }
+
+            do
+            {
+                var response = await _pipeClient.GetAlertsAsync(tagName, lastSequenceNumber, cancellationToken: cancellationToken);
+                if (!response.Success)
+                {
+                    WriteError($"アラート取得に失敗しました: {response.ErrorMessage}");
+                    context.ExitCode = 1;
+                    return;
+                }
+
+                if (!follow && format.Equals("table", StringComparison.OrdinalIgnoreCase))
+                {
+                    if (response.Alerts.Count == 0)
+                    {
+                        WriteInfo("アラートはありません。");
+                        return;
+                    }
+
+                    WriteTable(
+                        new[] { "#", "時刻", "ルール", "タグ", "件数", "詳細" },
+                        response.Alerts.Select(alert => new[]
+                        {
+                            alert.SequenceNumber.ToString(),
+                            alert.Timestamp.ToString("yyyy-MM-dd HH:mm:ss"),
+                            alert.RuleName,
+                            alert.TagName,
+                            alert.MatchCount.ToString(),
+                            GetEventsCommand.GetEventDetails(alert.Event)
+                        }).ToArray());
+                    return;
+                }
+
+                foreach (var alert in response.Alerts)
+                {
+                    Console.WriteLine(format.Equals("json", StringComparison.OrdinalIgnoreCase)
+                        ? JsonSerializer.Serialize(alert)
+                        : FormatAlert(alert));
+                    lastSequenceNumber = alert.SequenceNumber;
+                }
+
+                // 取得件数の上限で打ち切られた場合は待たずに続きを取得（連番は全タグ共通のため、該当なしの場合は待つ）
+                if (follow && (response.Alerts.Count == 0 || lastSequenceNumber >= response.LastSequenceNumber))
+                {
+                    await Task.Delay(pollInterval, cancellationToken);
+                }
+            }
+            while (follow && !cancellationToken.IsCancellationRequested);
+        }
+        catch (OperationCanceledException)
+        {
+            // Ctrl+C による停止
+        }
+        catch (Exception ex)
+        {
+            WriteError($"アラート取得中にエラーが発生しました: {ex.Message}");
+            context.ExitCode = 1;
+        }
+    }
+
+    private static string FormatAlert(Alert alert)
+    {
+        return $"[{alert.Timestamp:HH:mm:ss}] {alert.RuleName} ({alert.TagName}, {alert.MatchCount}件): {GetEventsCommand.GetEventDetails(alert.Event)}";
+    }
+}
@@ -0,0 +1,118 @@
+using System.CommandLine.Invocation;
+using System.Text.Json;
+using ProcTail.Cli.Services;
+
+namespace ProcTail.Cli.Commands;
+
+/// <summary>
+/// イベント集計取得コマンド
+/// </summary>
+public class GetEventSummaryCommand : BaseCommand
+{
+    public GetEventSummaryCommand(IProcTailPipeClient pipeClient) : base(pipeClient) { }
+
+    public override async Task ExecuteAsync(InvocationContext context)
+    {
+        var tagName = "";
+        var topCount = 10;
+        var format = "table";
+
+        // オプション値を取得
+        foreach (var option in context.ParseResult.CommandResult.Command.Options)
+        {
+            var value = context.ParseResult.GetValueForOption(option);
+            switch (option.Name)
+            {
+                case "tag":
+                    tagName = value as string ?? "";
+                    break;
+                case "top":
+                    topCount = (int?)value ?? 10;
+                    break;
+                case "format":
+                    format = value as string ?? "table";
+                    break;
+            }
+        }
+
+        if (!await TestServiceConnectionAsync())
+        {
+            context.ExitCode = 1;
+            return;
+        }
+
+        try
+        {
+            var response = await _pipeClient.GetEventSummaryAsync(tagName, topCount, context.GetCancellationToken());
+            if (!response.Success)
+            {
+                WriteError($"イベントの集計に失敗しました: {response.ErrorMessage}");
+                context.ExitCode = 1;
+                return;
+            }
+
+            var summary = response.Summary;
+            if (format.Equals("json", StringComparison.OrdinalIgnoreCase))
+            {
+                Console.WriteLine(JsonSerializer.Serialize(summary, new JsonSerializerOptions { WriteIndented = true }));
+                return;
+            }
+
+            if (summary.EventCount == 0 && summary.OutsideRootEventCount == 0)
+            {
+                WriteInfo($"タグ '{tagName}' のイベントは見つかりませんでした。");
+                return;
+            }
+
+            Console.WriteLine($"タグ: {tagName}");
+            Console.WriteLine($"記録期間: {summary.FirstEventAt:yyyy-MM-dd HH:mm:ss} - {summary.LastEventAt:yyyy-MM-dd HH:mm:ss} ({summary.SessionDurationMs / 1000}秒)");
+            Console.WriteLine($"イベント数: {summary.EventCount}");
+            Console.WriteLine($"ファイル数: {summary.FileCount} (書き込み: {summary.BytesWritten} bytes)");
+            Console.WriteLine($"子プロセス: {summary.ChildProcessCount}");
+            if (summary.OutsideRootEventCount > 0)
+            {
+                Console.WriteLine($"記録ディレクトリ外: {summary.OutsideRootEventCount}件 (書き込み: {summary.OutsideRootBytesWritten} bytes)");
+            }
+
+            if (summary.Directories.Count > 0)
+            {
+                Console.WriteLine();
+                WriteTable(
+                    new[] { "ディレクトリ", "ファイル数", "イベント数", "書き込み (bytes)" },
+                    summary.Directories.Select(directory => new[]
+                    {
+                        directory.Directory,
+                        directory.Files.Count.ToString(),
+                        directory.EventCount.ToString(),
+                        directory.BytesWritten.ToString()
+                    }).ToArray());
+            }
+
+            if (summary.NoisyPaths.Count > 0)
+            {
+                Console.WriteLine();
+                WriteTable(
+                    new[] { "ファイル", "イベント数", "書き込み (bytes)" },
+                    summary.NoisyPaths.Select(path => new[]
+                    {
+                        path.FilePath,
+                        path.EventCount.ToString(),
+                        path.BytesWritten.ToString()
+                    }).ToArray());
+            }
+
+            if (summary.ChildProcesses.Count > 0)
+            {
+                Console.WriteLine();
+                WriteTable(
+                    new[] { "子プロセス", "起動数" },
+                    summary.ChildProcesses.Select(child => new[] { child.ProcessName, child.Count.ToString() }).ToArray());
+            }
+        }
+        catch (Exception ex)
+        {
+            WriteError($"イベントの集計中にエラーが発生しました: {ex.Message}");
+            context.ExitCode = 1;
+        }
+    }
+}
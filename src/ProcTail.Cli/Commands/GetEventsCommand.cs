@@ -1,6 +1,8 @@
 using System.CommandLine.Invocation;
+using System.Text;
 using System.Text.Json;
 using ProcTail.Cli.Services;
+using ZstdSharp;
 
 namespace ProcTail.Cli.Commands;
 
@@ -17,6 +19,9 @@ public class GetEventsCommand : BaseCommand
         var count = 50;
         var format = "table";
         var follow = false;
+        var output = "";
+        var compress = false;
+        var query = "";
         
         // オプション値を取得
         foreach (var option in context.ParseResult.CommandResult.Command.Options)
@@ -36,9 +41,25 @@ public class GetEventsCommand : BaseCommand
                 case "follow":
                     follow = (bool?)value ?? false;
                     break;
+                case "output":
+                    output = value as string ?? "";
+                    break;
+                case "compress":
+                    compress = (bool?)value ?? false;
+                    break;
+                case "query":
+                    query = value as string ?? "";
+                    break;
             }
         }
 
+        if (follow && !string.IsNullOrEmpty(output))
+        {
+            WriteError("--output は --follow と同時に指定できません。");
+            context.ExitCode = 1;
+            return;
+        }
+
         if (!await TestServiceConnectionAsync())
         {
             context.ExitCode = 1;
@@ -49,11 +70,11 @@ public class GetEventsCommand : BaseCommand
         {
             if (follow)
             {
-                await FollowEventsAsync(tagName, format, context.GetCancellationToken());
+                await FollowEventsAsync(tagName, format, query, context.GetCancellationToken());
             }
             else
             {
-                var response = await _pipeClient.GetRecordedEventsAsync(tagName, count, context.GetCancellationToken());
+                var response = await _pipeClient.GetRecordedEventsAsync(tagName, count, query, context.GetCancellationToken());
 
                 if (response.Success)
                 {
@@ -63,16 +84,28 @@ public class GetEventsCommand : BaseCommand
                         return;
                     }
 
+                    if (!string.IsNullOrEmpty(output))
+                    {
+                        compress |= output.EndsWith(".zst", StringComparison.OrdinalIgnoreCase);
+                        await ExportEventsAsync(response.Events, format, output, compress);
+                        WriteSuccess($"{response.Events.Count}件のイベントを出力しました: {output}{(compress ? " (zstd)" : "")}");
+                        return;
+                    }
+
                     switch (format.ToLowerInvariant())
                     {
                         case "json":
                             Console.WriteLine(JsonSerializer.Serialize(response.Events, new JsonSerializerOptions { WriteIndented = true }));
                             break;
                         case "csv":
-                            WriteEventsCsv(response.Events);
+                            WriteEventsCsv(response.Events, Console.Out);
                             break;
                         default:
                             WriteEventsTable(response.Events);
+                            foreach (var gap in response.Gaps)
+                            {
+                                WriteWarning(FormatGap(gap));
+                            }
                             break;
                     }
                 }
@@ -104,12 +137,38 @@ public class GetEventsCommand : BaseCommand
         WriteTable(headers, rows);
     }
 
-    private static void WriteEventsCsv(IList<Core.Models.BaseEventData> events)
+    private static void WriteEventsCsv(IList<Core.Models.BaseEventData> events, TextWriter writer)
     {
-        Console.WriteLine("Timestamp,ProcessId,EventType,Details");
+        writer.WriteLine("Timestamp,ProcessId,EventType,Details");
         foreach (var e in events)
         {
-            Console.WriteLine($"{e.Timestamp:yyyy-MM-dd HH:mm:ss},{e.ProcessId},{e.GetType().Name},\"{GetEventDetails(e)}\"");
+            writer.WriteLine($"{e.Timestamp:yyyy-MM-dd HH:mm:ss},{e.ProcessId},{e.GetType().Name},\"{GetEventDetails(e)}\"");
+        }
+    }
+
+    /// <summary>
+    /// イベントをファイルに出力（csv以外はjson）
+    /// </summary>
+    /// <param name="events">イベント一覧</param>
+    /// <param name="format">出力フォーマット</param>
+    /// <param name="path">出力先</param>
+    /// <param name="compress">zstdで圧縮するかどうか</param>
+    private static async Task ExportEventsAsync(IList<Core.Models.BaseEventData> events, string format, string path, bool compress)
+    {
+        Stream stream = File.Create(path);
+        if (compress)
+        {
+            stream = new CompressionStream(stream);
+        }
+
+        await using var writer = new StreamWriter(stream, new UTF8Encoding(false));
+        if (format.Equals("csv", StringComparison.OrdinalIgnoreCase))
+        {
+            WriteEventsCsv(events, writer);
+        }
+        else
+        {
+            await writer.WriteAsync(JsonSerializer.Serialize(events, new JsonSerializerOptions { WriteIndented = true }));
         }
     }
 
@@ -118,12 +177,13 @@ public class GetEventsCommand : BaseCommand
     /// </summary>
     /// <param name="tagName">タグ名</param>
     /// <param name="format">出力フォーマット</param>
+    /// <param name="query">クエリ式</param>
     /// <param name="cancellationToken">キャンセレーショントークン</param>
-    private async Task FollowEventsAsync(string tagName, string format, CancellationToken cancellationToken)
+    private async Task FollowEventsAsync(string tagName, string format, string query, CancellationToken cancellationToken)
     {
         WriteInfo($"タグ '{tagName}' のイベントを監視中... (Ctrl+C で停止)");
         
-        var lastEventCount = 0;
+        string? cursor = null;
         var pollInterval = TimeSpan.FromSeconds(1);
 
         // CSV形式の場合、最初にヘッダーを出力
@@ -136,15 +196,28 @@ public class GetEventsCommand : BaseCommand
         {
             try
             {
-                var response = await _pipeClient.GetRecordedEventsAsync(tagName, 1000, cancellationToken);
+                // 前回表示したイベントの続きのみを取得し、間の欠番は破棄として通知
+                var response = await _pipeClient.GetRecordedEventsPageAsync(tagName, cursor, 1000, query, cancellationToken);
                 
-                if (response.Success && response.Events.Count > lastEventCount)
+                if (response.Success)
                 {
-                    // 新しいイベントのみを表示
-                    var newEvents = response.Events.Skip(lastEventCount).ToList();
-                    
-                    foreach (var eventData in newEvents)
+                    var gaps = response.Gaps.ToDictionary(g => g.LastMissing + 1);
+
+                    foreach (var eventData in response.Events)
                     {
+                        if (gaps.TryGetValue(eventData.SequenceNumber, out var gap))
+                        {
+                            // json/csvの出力を壊さないよう、欠番の通知は標準エラーに出す
+                            if (format.Equals("json", StringComparison.OrdinalIgnoreCase) || format.Equals("csv", StringComparison.OrdinalIgnoreCase))
+                            {
+                                Console.Error.WriteLine(FormatGap(gap));
+                            }
+                            else
+                            {
+                                WriteWarning(FormatGap(gap));
+                            }
+                        }
+
                         switch (format.ToLowerInvariant())
                         {
                             case "json":
@@ -159,7 +232,19 @@ public class GetEventsCommand : BaseCommand
                         }
                     }
                     
-                    lastEventCount = response.Events.Count;
+                    cursor = response.NextCursor;
+
+                    // 取得件数の上限で打ち切った場合は待たずに続きを取得
+                    if (response.HasMore)
+                    {
+                        continue;
+                    }
+                }
+                else
+                {
+                    // サービスの再起動で連番が振り直された場合など、カーソルが使えない場合は最初から取得し直す
+                    WriteWarning($"イベント取得に失敗しました: {response.ErrorMessage}");
+                    cursor = null;
                 }
                 
                 await Task.Delay(pollInterval, cancellationToken);
@@ -178,14 +263,118 @@ public class GetEventsCommand : BaseCommand
         WriteInfo("イベント監視を停止しました。");
     }
 
-    private static string GetEventDetails(Core.Models.BaseEventData eventData)
+    private static string FormatGap(Core.Models.SequenceGap gap)
+    {
+        return gap.Count == 1
+            ? $"イベントが1件破棄されました (#{gap.FirstMissing})"
+            : $"イベントが{gap.Count}件破棄されました (#{gap.FirstMissing}-#{gap.LastMissing})";
+    }
+
+    internal static string GetEventDetails(Core.Models.BaseEventData eventData)
+    {
+        // 制限モードのイベントはPIDなどが欠けるため区別できるようにする
+        var details = GetEventDetailsByType(eventData);
+        return eventData.IsDegraded ? $"{details} [制限モード]" : details;
+    }
+
+    private static string GetEventDetailsByType(Core.Models.BaseEventData eventData)
     {
         return eventData switch
         {
-            Core.Models.FileEventData fileEvent => $"{fileEvent.FilePath} ({fileEvent.EventName})",
-            Core.Models.ProcessStartEventData processStart => $"子プロセス: {processStart.ChildProcessName} (PID: {processStart.ChildProcessId})",
-            Core.Models.ProcessEndEventData processEnd => $"終了コード: {processEnd.ExitCode}",
+            Core.Models.FileEventData fileEvent => GetFileEventDetails(fileEvent),
+            Core.Models.ProcessStartEventData processStart => (string.IsNullOrEmpty(processStart.CommandLine)
+                ? $"子プロセス: {processStart.ChildProcessName} (PID: {processStart.ChildProcessId})"
+                : $"子プロセス: {processStart.ChildProcessName} (PID: {processStart.ChildProcessId}) {processStart.CommandLine}")
+                + FormatToken(processStart.UserSid, processStart.SessionId, processStart.IntegrityLevel),
+            Core.Models.ProcessEndEventData processEnd => FormatExit(processEnd)
+                + FormatToken(processEnd.UserSid, processEnd.SessionId, processEnd.IntegrityLevel),
+            Core.Models.RegistryEventData registry => string.IsNullOrEmpty(registry.ValueName)
+                ? $"{registry.KeyName} ({registry.Operation})"
+                : $"{registry.KeyName}\\{registry.ValueName} ({registry.Operation})",
+            Core.Models.NetworkEventData network => $"{network.Protocol} {network.Operation} {network.RemoteHostName ?? network.RemoteAddress}:{network.RemotePort} ({network.Bytes} bytes)",
+            Core.Models.ThreadEventData thread => $"スレッド {thread.ThreadId} ({thread.Operation}, 0x{thread.StartAddress:X})",
+            Core.Models.DnsQueryEventData dns => $"DNS {dns.QueryName} -> {string.Join(", ", dns.Addresses)}",
+            Core.Models.ResourceSnapshotEventData resource => FormatResourceSnapshot(resource),
+            Core.Models.ForegroundEventData foreground => foreground.IsForeground
+                ? $"フォアグラウンド: {foreground.WindowTitle}"
+                : $"バックグラウンド (フォアグラウンドだった時間: {(foreground.ForegroundDurationMs ?? 0) / 1000}秒)",
+            Core.Models.HangEventData hang => hang.IsHung
+                ? $"応答なし: {hang.WindowTitle}"
+                : $"応答再開: {hang.WindowTitle} (応答なし: {(hang.HangDurationMs ?? 0) / 1000}秒)",
+            Core.Models.UpdateDetectedEventData update => (update.Trigger == Core.Models.UpdateTrigger.InstallerStarted
+                ? $"更新検出: インストーラー起動 {update.ExecutablePath} (PID: {update.InstallerProcessId})"
+                : $"更新検出: 実行ファイル更新 {update.ExecutablePath}")
+                + (update.ExecutableSha256 == null ? "" : $" SHA-256: {update.ExecutableSha256}"),
+            Core.Models.TagActivityEventData activity => activity.State == Core.Models.TagActivityState.Idle
+                ? $"無操作 (最後のイベント: {activity.LastActivityAt:yyyy-MM-dd HH:mm:ss} UTC)"
+                : $"活動再開 (無操作: {(activity.IdleDurationMs ?? 0) / 1000}秒)",
+            Core.Models.CrashDetectedEventData { EventName: "Crash/DumpWritten" } dump => $"クラッシュダンプ: {dump.ApplicationName} {dump.DumpPath}",
+            Core.Models.CrashDetectedEventData crash => string.IsNullOrEmpty(crash.DumpPath)
+                ? $"クラッシュ: {crash.ApplicationName} ({crash.FaultingModule}, 0x{crash.ExceptionCode:X8})"
+                : $"クラッシュ: {crash.ApplicationName} ({crash.FaultingModule}, 0x{crash.ExceptionCode:X8}) ダンプ: {crash.DumpPath}",
+            Core.Models.ImageLoadEventData image => $"{image.ImagePath} ({image.Operation}, 0x{image.BaseAddress:X}, {(image.IsSigned == null ? "署名不明" : image.IsSigned.Value ? "署名あり" : "未署名")})",
             _ => eventData.EventName
         };
     }
+
+    private static string FormatExit(Core.Models.ProcessEndEventData processEnd)
+    {
+        // WindowsのNTSTATUS（0xC0000005など）は負の値になるため16進数で表示
+        var exitCode = processEnd.ExitCode < 0 ? $"0x{processEnd.ExitCode:X8}" : processEnd.ExitCode.ToString();
+        return processEnd.ExitReason switch
+        {
+            Core.Models.ProcessExitReason.Killed => $"終了コード: {exitCode} (強制終了)",
+            Core.Models.ProcessExitReason.Crashed => $"終了コード: {exitCode} (クラッシュ)",
+            _ => $"終了コード: {exitCode}"
+        };
+    }
+
+    private static string FormatResourceSnapshot(Core.Models.ResourceSnapshotEventData resource)
+    {
+        var details = $"CPU: {resource.CpuTimeMs}ms, ワーキングセット: {resource.WorkingSetBytes / (1024 * 1024)}MB, ハンドル: {resource.HandleCount}";
+        return resource.ReadBytes.HasValue && resource.WriteBytes.HasValue
+            ? $"{details}, 読み取り: {resource.ReadBytes} bytes, 書き込み: {resource.WriteBytes} bytes"
+            : details;
+    }
+
+    private static string FormatToken(string? userSid, int? sessionId, string? integrityLevel)
+    {
+        var parts = new List<string>();
+        if (!string.IsNullOrEmpty(userSid))
+        {
+            parts.Add(userSid);
+        }
+        if (sessionId.HasValue)
+        {
+            parts.Add($"セッション {sessionId}");
+        }
+        if (!string.IsNullOrEmpty(integrityLevel))
+        {
+            parts.Add(integrityLevel);
+        }
+
+        return parts.Count == 0 ? "" : $" [{string.Join(", ", parts)}]";
+    }
+
+    private static string GetFileEventDetails(Core.Models.FileEventData fileEvent)
+    {
+        var details = fileEvent.RawFilePath == null
+            ? $"{fileEvent.FilePath} ({fileEvent.EventName})"
+            : $"{fileEvent.FilePath} <- {fileEvent.RawFilePath} ({fileEvent.EventName})";
+        if (fileEvent.CoalescedCount.HasValue)
+        {
+            var span = fileEvent.Timestamp - (fileEvent.FirstTimestamp ?? fileEvent.Timestamp);
+            var bytes = fileEvent.TotalBytes.HasValue ? $", {fileEvent.TotalBytes} bytes" : "";
+            details += $" x{fileEvent.CoalescedCount}{bytes}, {span.TotalMilliseconds:F0}ms";
+        }
+
+        if (fileEvent.ContentSha256 != null)
+        {
+            details += $" sha256:{fileEvent.ContentSha256[..12]}";
+        }
+
+        return fileEvent.CorrelationId == null
+            ? details
+            : $"{details} [{fileEvent.CorrelationId.Value.ToString()[..8]}]";
+    }
 }
\ No newline at end of file
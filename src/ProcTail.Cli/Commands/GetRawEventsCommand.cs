@@ -0,0 +1,89 @@
+using System.CommandLine.Invocation;
+using System.Text.Json;
+using ProcTail.Cli.Services;
+
+namespace ProcTail.Cli.Commands;
+
+/// <summary>
+/// 生ETWイベント取得コマンド
+/// </summary>
+/// <remarks>
+/// 1行に1レコードのJSONで標準出力に出し、取りこぼしの通知は出力を壊さないよう標準エラーに出す。
+/// </remarks>
+public class GetRawEventsCommand : BaseCommand
+{
+    public GetRawEventsCommand(IProcTailPipeClient pipeClient) : base(pipeClient) { }
+
+    public override async Task ExecuteAsync(InvocationContext context)
+    {
+        var tagName = "";
+        var follow = false;
+
+        // オプション値を取得
+        foreach (var option in context.ParseResult.CommandResult.Command.Options)
+        {
+            var value = context.ParseResult.GetValueForOption(option);
+            switch (option.Name)
+            {
+                case "tag":
+                    tagName = value as string ?? "";
+                    break;
+                case "follow":
+                    follow = (bool?)value ?? false;
+                    break;
+            }
+        }
+
+        if (!await TestServiceConnectionAsync())
+        {
+            context.ExitCode = 1;
+            return;
+        }
+
+        var cancellationToken = context.GetCancellationToken();
+        var lastSequenceNumber = 0L;
+        var pollInterval = TimeSpan.FromSeconds(1);
+
+        try
+        {
+            do
+            {
+                var response = await _pipeClient.GetRawEventsAsync(tagName, lastSequenceNumber, cancellationToken: cancellationToken);
+                if (!response.Success)
+                {
+                    WriteError($"生イベント取得に失敗しました: {response.ErrorMessage}");
+                    context.ExitCode = 1;
+                    return;
+                }
+
+                foreach (var record in response.Records)
+                {
+                    // 保持件数を超えて捨てられたレコードは連番の飛びで分かる
+                    if (record.SequenceNumber > lastSequenceNumber + 1)
+                    {
+                        Console.Error.WriteLine($"生イベントが{record.SequenceNumber - lastSequenceNumber - 1}件破棄されました (#{lastSequenceNumber + 1}-#{record.SequenceNumber - 1})");
+                    }
+
+                    Console.WriteLine(JsonSerializer.Serialize(record));
+                    lastSequenceNumber = record.SequenceNumber;
+                }
+
+                // 取得件数の上限で打ち切られた場合は待たずに続きを取得
+                if (follow && lastSequenceNumber >= response.LastSequenceNumber)
+                {
+                    await Task.Delay(pollInterval, cancellationToken);
+                }
+            }
+            while (follow && !cancellationToken.IsCancellationRequested);
+        }
+        catch (OperationCanceledException)
+        {
+            // Ctrl+C による停止
+        }
+        catch (Exception ex)
+        {
+            WriteError($"生イベント取得中にエラーが発生しました: {ex.Message}");
+            context.ExitCode = 1;
+        }
+    }
+}
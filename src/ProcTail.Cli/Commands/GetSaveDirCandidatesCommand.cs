@@ -0,0 +1,84 @@
+using System.CommandLine.Invocation;
+using System.Text.Json;
+using ProcTail.Cli.Services;
+
+namespace ProcTail.Cli.Commands;
+
+/// <summary>
+/// セーブデータの保存先候補取得コマンド
+/// </summary>
+public class GetSaveDirCandidatesCommand : BaseCommand
+{
+    public GetSaveDirCandidatesCommand(IProcTailPipeClient pipeClient) : base(pipeClient) { }
+
+    public override async Task ExecuteAsync(InvocationContext context)
+    {
+        var tagName = "";
+        var count = 10;
+        var format = "table";
+
+        // オプション値を取得
+        foreach (var option in context.ParseResult.CommandResult.Command.Options)
+        {
+            var value = context.ParseResult.GetValueForOption(option);
+            switch (option.Name)
+            {
+                case "tag":
+                    tagName = value as string ?? "";
+                    break;
+                case "count":
+                    count = (int?)value ?? 10;
+                    break;
+                case "format":
+                    format = value as string ?? "table";
+                    break;
+            }
+        }
+
+        if (!await TestServiceConnectionAsync())
+        {
+            context.ExitCode = 1;
+            return;
+        }
+
+        try
+        {
+            var response = await _pipeClient.GetSaveDirCandidatesAsync(tagName, count, context.GetCancellationToken());
+            if (!response.Success)
+            {
+                WriteError($"保存先候補の取得に失敗しました: {response.ErrorMessage}");
+                context.ExitCode = 1;
+                return;
+            }
+
+            if (format.Equals("json", StringComparison.OrdinalIgnoreCase))
+            {
+                Console.WriteLine(JsonSerializer.Serialize(response.Candidates, new JsonSerializerOptions { WriteIndented = true }));
+                return;
+            }
+
+            if (response.Candidates.Count == 0)
+            {
+                WriteInfo($"タグ '{tagName}' の書き込みからセーブデータの保存先は見つかりませんでした。");
+                return;
+            }
+
+            WriteTable(
+                new[] { "スコア", "ディレクトリ", "ファイル数", "保存回数", "最終書き込み", "主なファイル" },
+                response.Candidates.Select(candidate => new[]
+                {
+                    candidate.Score.ToString("0.#"),
+                    candidate.Directory,
+                    $"{candidate.RewrittenFileCount}/{candidate.FileCount}",
+                    candidate.SaveCount.ToString(),
+                    candidate.LastWriteAt.ToString("yyyy-MM-dd HH:mm:ss"),
+                    string.Join(", ", candidate.Files.Take(3))
+                }).ToArray());
+        }
+        catch (Exception ex)
+        {
+            WriteError($"保存先候補の取得中にエラーが発生しました: {ex.Message}");
+            context.ExitCode = 1;
+        }
+    }
+}
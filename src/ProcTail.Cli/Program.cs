@@ -95,6 +95,10 @@ public class Program
             getDefaultValue: () => "ProcTail",
             description: "Named Pipeの名前");
 
+        var tokenOption = new Option<string?>(
+            aliases: new[] { "--token" },
+            description: "サービスに設定された認証トークン（省略時は環境変数 PROCTAIL_TOKEN）");
+
         var noUacOption = new Option<bool>(
             aliases: new[] { "--no-uac" },
             description: "UACプロンプトを無効にする（管理者権限なしで実行失敗）");
@@ -102,6 +106,7 @@ public class Program
         rootCommand.AddGlobalOption(verboseOption);
         rootCommand.AddGlobalOption(configOption);
         rootCommand.AddGlobalOption(pipeNameOption);
+        rootCommand.AddGlobalOption(tokenOption);
         rootCommand.AddGlobalOption(noUacOption);
 
         // サブコマンドを追加
@@ -109,6 +114,10 @@ public class Program
         rootCommand.AddCommand(CreateRemoveCommand());
         rootCommand.AddCommand(CreateListCommand());
         rootCommand.AddCommand(CreateEventsCommand());
+        rootCommand.AddCommand(CreateRawCommand());
+        rootCommand.AddCommand(CreateAlertsCommand());
+        rootCommand.AddCommand(CreateSaveDirsCommand());
+        rootCommand.AddCommand(CreateSummaryCommand());
         rootCommand.AddCommand(CreateStatusCommand());
         rootCommand.AddCommand(CreateClearCommand());
         rootCommand.AddCommand(CreateServiceCommand());
@@ -138,11 +147,158 @@ public class Program
             IsRequired = true
         };
 
+        var pathOption = new Option<string?>(
+            aliases: new[] { "--path" },
+            description: "このディレクトリ配下の実行ファイルから起動したプロセスを全て監視（ゲームのインストール先など）");
+
+        var threadsOption = new Option<bool>(
+            aliases: new[] { "--threads" },
+            description: "スレッドの開始・終了イベントも記録する");
+
+        var readSampleRateOption = new Option<int>(
+            aliases: new[] { "--read-sample-rate" },
+            description: "ファイル読み取りをN回に1回記録する（0: 記録しない）");
+
+        var envOption = new Option<string[]>(
+            aliases: new[] { "--env" },
+            description: "子プロセス開始時に記録する環境変数名（複数指定可）")
+        {
+            AllowMultipleArgumentsPerToken = true
+        };
+
+        var maxDepthOption = new Option<int?>(
+            aliases: new[] { "--max-depth" },
+            description: "自動監視する子孫プロセスの最大深さ（0: 子プロセスを監視しない, 省略時: 無制限）");
+
+        var sameSessionOption = new Option<bool>(
+            aliases: new[] { "--same-session" },
+            description: "親と異なるセッションで開始された子プロセスは監視しない");
+
+        var stopAtOption = new Option<string[]>(
+            aliases: new[] { "--stop-at" },
+            description: "子プロセスの自動監視を打ち切る実行ファイル名（ランチャーやアップデーターなど、複数指定可）")
+        {
+            AllowMultipleArgumentsPerToken = true
+        };
+
+        var excludeOption = new Option<string[]>(
+            aliases: new[] { "--exclude" },
+            description: "イベントを記録しないプロセス（PID、イメージ名、またはパスのglobパターン。複数指定可）")
+        {
+            AllowMultipleArgumentsPerToken = true
+        };
+
+        var includePathOption = new Option<string[]>(
+            aliases: new[] { "--include-path" },
+            description: "記録するファイルパス（globまたは regex:<正規表現>。%APPDATA% などの環境変数を展開、複数指定可）")
+        {
+            AllowMultipleArgumentsPerToken = true
+        };
+
+        var excludePathOption = new Option<string[]>(
+            aliases: new[] { "--exclude-path" },
+            description: "記録しないファイルパス（globまたは regex:<正規表現>。--include-pathより優先、複数指定可）")
+        {
+            AllowMultipleArgumentsPerToken = true
+        };
+
+        var recordRootOption = new Option<string[]>(
+            aliases: new[] { "--record-root" },
+            description: "ファイルイベントを記録するディレクトリ（配下を含む、複数指定可）。ディレクトリ外のファイルイベントは件数と書き込みバイト数だけを数えて記録しない")
+        {
+            AllowMultipleArgumentsPerToken = true
+        };
+
+        var hashOnCloseOption = new Option<string[]>(
+            aliases: new[] { "--hash-on-close" },
+            description: "書き込んだハンドルのクローズ時に内容のSHA-256を記録するファイルパス（globまたは regex:<正規表現>。複数指定可）")
+        {
+            AllowMultipleArgumentsPerToken = true
+        };
+
+        var maxEventsPerSecondOption = new Option<int>(
+            aliases: new[] { "--max-events-per-second" },
+            description: "タグで記録する1秒あたりの最大イベント数（超過分は間引き、削除・リネームは常に記録。0: 無制限）");
+
+        var coalesceWritesOption = new Option<int>(
+            aliases: new[] { "--coalesce-writes" },
+            description: "同じファイルへの連続した書き込みを指定ミリ秒のウィンドウで1件にまとめる（0: 集約しない）");
+
+        var backpressureOption = new Option<string>(
+            aliases: new[] { "--backpressure" },
+            description: "タグのイベントバッファが満杯の場合の動作 (drop-oldest, drop-newest, block。省略時: drop-oldest)");
+
+        var blockTimeoutOption = new Option<int?>(
+            aliases: new[] { "--block-timeout" },
+            description: "--backpressure block で空きを待つ最大時間（ミリ秒、経過後は新しいイベントを破棄。省略時: 1000）");
+
+        var backendOption = new Option<string>(
+            aliases: new[] { "--backend" },
+            description: "ファイルイベントの取得元 (etw, directory-watcher, polling。etw以外は--watch-dirの変更を記録し、変更したプロセスは記録しない。省略時: etw)");
+
+        var watchDirOption = new Option<string[]>(
+            aliases: new[] { "--watch-dir" },
+            description: "--backend directory-watcher または polling で監視するディレクトリ（ネットワークドライブも可、配下を含む、複数指定可）")
+        {
+            AllowMultipleArgumentsPerToken = true
+        };
+
+        var pollIntervalOption = new Option<int?>(
+            aliases: new[] { "--poll-interval" },
+            description: "--backend polling でディレクトリを列挙する間隔（ミリ秒。省略時: 2000）");
+
+        var resourceIntervalOption = new Option<int>(
+            aliases: new[] { "--resource-interval" },
+            description: "監視対象プロセスのCPU時間・ワーキングセット・ハンドル数・I/Oカウンタを記録する間隔（ミリ秒、0: 記録しない）");
+
+        var rewatchOption = new Option<int>(
+            aliases: new[] { "--rewatch" },
+            description: "監視対象プロセスの終了後、指定ミリ秒以内に同じ実行ファイルから起動したプロセスを同じタグで監視し直す（アップデーターによる再起動など。0: 監視し直さない）");
+
+        var idleTimeoutOption = new Option<int>(
+            aliases: new[] { "--idle-timeout" },
+            description: "タグのイベントが指定ミリ秒記録されない場合に無操作イベント（Tag/Idle）を、再開時に復帰イベント（Tag/Active）を記録する（0: 記録しない）");
+
+        var detectHangsOption = new Option<bool>(
+            aliases: new[] { "--detect-hangs" },
+            description: "監視対象プロセスのウィンドウが応答しなくなった時（Process/Hang）と応答が戻った時（Process/Unhang）を記録する（Windowsのみ）");
+
+        var metaOption = new Option<string[]>(
+            aliases: new[] { "--meta" },
+            description: "タグのメタデータ（key=value 形式。記録したイベントにそのまま付与、複数指定可）")
+        {
+            AllowMultipleArgumentsPerToken = true
+        };
+
         var addCommand = new Command("add", "監視対象を追加")
         {
             processIdOption,
             processNameOption,
-            tagOption
+            pathOption,
+            tagOption,
+            threadsOption,
+            readSampleRateOption,
+            envOption,
+            maxDepthOption,
+            sameSessionOption,
+            stopAtOption,
+            excludeOption,
+            includePathOption,
+            excludePathOption,
+            recordRootOption,
+            hashOnCloseOption,
+            maxEventsPerSecondOption,
+            coalesceWritesOption,
+            backpressureOption,
+            blockTimeoutOption,
+            backendOption,
+            watchDirOption,
+            pollIntervalOption,
+            resourceIntervalOption,
+            rewatchOption,
+            idleTimeoutOption,
+            detectHangsOption,
+            metaOption
         };
 
         addCommand.SetHandler(async (context) =>
@@ -233,12 +389,27 @@ public class Program
             aliases: new[] { "--follow" },
             description: "リアルタイムでイベントを表示");
 
+        var outputOption = new Option<string>(
+            aliases: new[] { "--output", "-o" },
+            description: "イベントをファイルに出力 (json, csv)");
+
+        var compressOption = new Option<bool>(
+            aliases: new[] { "--compress" },
+            description: "出力ファイルをzstdで圧縮（拡張子が .zst の場合は常に圧縮）");
+
+        var queryOption = new Option<string>(
+            aliases: new[] { "--query", "-q" },
+            description: "イベントを絞り込むクエリ式 (例: type=file.write AND path~\"*.sav\" AND ts>now-1h)");
+
         var eventsCommand = new Command("events", "記録されたイベントを表示")
         {
             tagOption,
             countOption,
             formatOption,
-            followOption
+            followOption,
+            outputOption,
+            compressOption,
+            queryOption
         };
 
         eventsCommand.SetHandler(async (context) =>
@@ -251,6 +422,151 @@ public class Program
         return eventsCommand;
     }
 
+    /// <summary>
+    /// rawコマンドを作成
+    /// </summary>
+    private static Command CreateRawCommand()
+    {
+        var tagOption = new Option<string>(
+            aliases: new[] { "--tag", "-t" },
+            description: "取得するタグ名")
+        {
+            IsRequired = true
+        };
+
+        var followOption = new Option<bool>(
+            aliases: new[] { "--follow" },
+            description: "リアルタイムで生イベントを表示");
+
+        var rawCommand = new Command("raw", "監視対象プロセスの生ETWイベントをJSON Linesで表示（ETW:RawPassthrough:Enabled が必要）")
+        {
+            tagOption,
+            followOption
+        };
+
+        rawCommand.SetHandler(async (context) =>
+        {
+            var client = CreatePipeClient(context);
+            var command = new GetRawEventsCommand(client);
+            await command.ExecuteAsync(context);
+        });
+
+        return rawCommand;
+    }
+
+    /// <summary>
+    /// alertsコマンドを作成
+    /// </summary>
+    private static Command CreateAlertsCommand()
+    {
+        var tagOption = new Option<string?>(
+            aliases: new[] { "--tag", "-t" },
+            description: "取得するタグ名（省略時: 全てのタグ）");
+
+        var formatOption = new Option<string>(
+            aliases: new[] { "--format", "-f" },
+            getDefaultValue: () => "table",
+            description: "出力形式 (table, json)");
+
+        var followOption = new Option<bool>(
+            aliases: new[] { "--follow" },
+            description: "リアルタイムでアラートを表示");
+
+        var alertsCommand = new Command("alerts", "設定したアラートルールに一致したイベントのアラートを表示")
+        {
+            tagOption,
+            formatOption,
+            followOption
+        };
+
+        alertsCommand.SetHandler(async (context) =>
+        {
+            var client = CreatePipeClient(context);
+            var command = new GetAlertsCommand(client);
+            await command.ExecuteAsync(context);
+        });
+
+        return alertsCommand;
+    }
+
+    /// <summary>
+    /// save-dirsコマンドを作成
+    /// </summary>
+    private static Command CreateSaveDirsCommand()
+    {
+        var tagOption = new Option<string>(
+            aliases: new[] { "--tag", "-t" },
+            description: "推定するタグ名")
+        {
+            IsRequired = true
+        };
+
+        var countOption = new Option<int>(
+            aliases: new[] { "--count", "-n" },
+            getDefaultValue: () => 10,
+            description: "表示する候補の最大数");
+
+        var formatOption = new Option<string>(
+            aliases: new[] { "--format", "-f" },
+            getDefaultValue: () => "table",
+            description: "出力形式 (table, json)");
+
+        var saveDirsCommand = new Command("save-dirs", "タグの書き込みイベントからセーブデータの保存先らしいディレクトリを推定")
+        {
+            tagOption,
+            countOption,
+            formatOption
+        };
+
+        saveDirsCommand.SetHandler(async (context) =>
+        {
+            var client = CreatePipeClient(context);
+            var command = new GetSaveDirCandidatesCommand(client);
+            await command.ExecuteAsync(context);
+        });
+
+        return saveDirsCommand;
+    }
+
+    /// <summary>
+    /// summaryコマンドを作成
+    /// </summary>
+    private static Command CreateSummaryCommand()
+    {
+        var tagOption = new Option<string>(
+            aliases: new[] { "--tag", "-t" },
+            description: "集計するタグ名")
+        {
+            IsRequired = true
+        };
+
+        var topOption = new Option<int>(
+            aliases: new[] { "--top" },
+            getDefaultValue: () => 10,
+            description: "表示するイベント数の多いファイルの件数");
+
+        var formatOption = new Option<string>(
+            aliases: new[] { "--format", "-f" },
+            getDefaultValue: () => "table",
+            description: "出力形式 (table, json)");
+
+        var summaryCommand = new Command("summary", "タグのイベントの概要（ディレクトリごとのファイル、書き込みバイト数、子プロセス、記録期間）を表示")
+        {
+            tagOption,
+            topOption,
+            formatOption
+        };
+
+        summaryCommand.SetHandler(async (context) =>
+        {
+            var client = CreatePipeClient(context);
+            var command = new GetEventSummaryCommand(client);
+            await command.ExecuteAsync(context);
+        });
+
+        return summaryCommand;
+    }
+
     /// <summary>
     /// statusコマンドを作成
     /// </summary>
@@ -316,7 +632,10 @@ public class Program
         var startCommand = new Command("start", "サービスを開始");
         var stopCommand = new Command("stop", "サービスを停止");
         var restartCommand = new Command("restart", "サービスを再起動");
-        var installCommand = new Command("install", "サービスをインストール");
+        var installCommand = new Command("install", "サービスをインストール（異常終了時は自動で再起動）")
+        {
+            new Option<bool>("--start", "インストール後にサービスを開始")
+        };
         var uninstallCommand = new Command("uninstall", "サービスをアンインストール");
 
         var serviceCommand = new Command("service", "サービス管理")
@@ -446,6 +765,7 @@ public class Program
     {
         var pipeName = "ProcTail";
         var verbose = false;
+        var token = Environment.GetEnvironmentVariable("PROCTAIL_TOKEN");
         
         // グローバルオプション値を取得
         foreach (var option in context.ParseResult.RootCommandResult.Command.Options)
@@ -459,6 +779,9 @@ public class Program
                 case "verbose":
                     verbose = (bool?)value ?? false;
                     break;
+                case "token":
+                    token = value as string ?? token;
+                    break;
             }
         }
 
@@ -479,7 +802,7 @@ public class Program
         services.AddSingleton<IProcTailPipeClient>(provider =>
         {
             var logger = provider.GetRequiredService<ILogger<ProcTailPipeClient>>();
-            return new ProcTailPipeClient(logger, pipeName);
+            return new ProcTailPipeClient(logger, pipeName, token);
         });
 
         var serviceProvider = services.BuildServiceProvider();
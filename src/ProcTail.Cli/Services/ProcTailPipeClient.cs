@@ -2,6 +2,7 @@ using Microsoft.Extensions.Logging;
 using System.IO.Pipes;
 using System.Text;
 using System.Text.Json;
+using System.Text.Json.Nodes;
 using ProcTail.Core.Models;
 
 namespace ProcTail.Cli.Services;
@@ -14,7 +15,12 @@ public interface IProcTailPipeClient : IDisposable
     /// <summary>
     /// 監視対象を追加
     /// </summary>
-    Task<AddWatchTargetResponse> AddWatchTargetAsync(int processId, string tagName, CancellationToken cancellationToken = default);
+    Task<AddWatchTargetResponse> AddWatchTargetAsync(int processId, string tagName, WatchTargetOptions? options = null, CancellationToken cancellationToken = default);
+
+    /// <summary>
+    /// ディレクトリ配下の実行ファイルから起動したプロセスを監視対象に追加
+    /// </summary>
+    Task<AddWatchTargetByPathResponse> AddWatchTargetByPathAsync(string tagName, string directory, WatchTargetOptions? options = null, CancellationToken cancellationToken = default);
 
     /// <summary>
     /// 監視対象を削除
@@ -27,9 +33,34 @@ public interface IProcTailPipeClient : IDisposable
     Task<GetWatchTargetsResponse> GetWatchTargetsAsync(CancellationToken cancellationToken = default);
 
     /// <summary>
-    /// 記録されたイベントを取得
+    /// 記録されたイベントを取得（queryはサービス側で絞り込むクエリ式）
     /// </summary>
-    Task<GetRecordedEventsResponse> GetRecordedEventsAsync(string tagName, int maxCount = 100, CancellationToken cancellationToken = default);
+    Task<GetRecordedEventsResponse> GetRecordedEventsAsync(string tagName, int maxCount = 100, string? query = null, CancellationToken cancellationToken = default);
+
+    /// <summary>
+    /// 記録されたイベントをカーソルの続きから取得（queryはサービス側で絞り込むクエリ式）
+    /// </summary>
+    Task<GetRecordedEventsResponse> GetRecordedEventsPageAsync(string tagName, string? cursor, int limit = 1000, string? query = null, CancellationToken cancellationToken = default);
+
+    /// <summary>
+    /// 生ETWイベントを取得
+    /// </summary>
+    Task<GetRawEventsResponse> GetRawEventsAsync(string tagName, long afterSequenceNumber = 0, int maxCount = 1000, CancellationToken cancellationToken = default);
+
+    /// <summary>
+    /// アラートを取得
+    /// </summary>
+    Task<GetAlertsResponse> GetAlertsAsync(string? tagName = null, long afterSequenceNumber = 0, int maxCount = 1000, CancellationToken cancellationToken = default);
+
+    /// <summary>
+    /// セーブデータの保存先候補を取得
+    /// </summary>
+    Task<GetSaveDirCandidatesResponse> GetSaveDirCandidatesAsync(string tagName, int maxCount = 10, CancellationToken cancellationToken = default);
+
+    /// <summary>
+    /// タグのイベントの集計を取得
+    /// </summary>
+    Task<GetEventSummaryResponse> GetEventSummaryAsync(string tagName, int topCount = 10, CancellationToken cancellationToken = default);
 
     /// <summary>
     /// サービス状態を取得
@@ -59,6 +90,7 @@ public class ProcTailPipeClient : IProcTailPipeClient
 {
     private readonly ILogger<ProcTailPipeClient> _logger;
     private readonly string _pipeName;
+    private readonly string? _authToken;
     private readonly TimeSpan _connectionTimeout = TimeSpan.FromSeconds(10);
     private readonly TimeSpan _responseTimeout = TimeSpan.FromSeconds(30);
     private bool _disposed;
@@ -66,22 +98,27 @@ public class ProcTailPipeClient : IProcTailPipeClient
     /// <summary>
     /// コンストラクタ
     /// </summary>
-    public ProcTailPipeClient(ILogger<ProcTailPipeClient> logger, string pipeName)
+    /// <param name="logger">ロガー</param>
+    /// <param name="pipeName">Named Pipeの名前</param>
+    /// <param name="authToken">全ての要求にAuthTokenとして付けるトークン（nullの場合は付けない）</param>
+    public ProcTailPipeClient(ILogger<ProcTailPipeClient> logger, string pipeName, string? authToken = null)
     {
         _logger = logger ?? throw new ArgumentNullException(nameof(logger));
         _pipeName = pipeName ?? throw new ArgumentNullException(nameof(pipeName));
+        _authToken = string.IsNullOrEmpty(authToken) ? null : authToken;
     }
 
     /// <summary>
     /// 監視対象を追加
     /// </summary>
-    public async Task<AddWatchTargetResponse> AddWatchTargetAsync(int processId, string tagName, CancellationToken cancellationToken = default)
+    public async Task<AddWatchTargetResponse> AddWatchTargetAsync(int processId, string tagName, WatchTargetOptions? options = null, CancellationToken cancellationToken = default)
     {
         var request = new
         {
             RequestType = "AddWatchTarget",
             ProcessId = processId,
-            TagName = tagName
+            TagName = tagName,
+            Options = options
         };
 
         var responseJson = await SendRequestAsync(JsonSerializer.Serialize(request), cancellationToken);
@@ -89,6 +126,24 @@ public class ProcTailPipeClient : IProcTailPipeClient
             ?? throw new InvalidOperationException("応答のデシリアライズに失敗しました");
     }
 
+    /// <summary>
+    /// ディレクトリ配下の実行ファイルから起動したプロセスを監視対象に追加
+    /// </summary>
+    public async Task<AddWatchTargetByPathResponse> AddWatchTargetByPathAsync(string tagName, string directory, WatchTargetOptions? options = null, CancellationToken cancellationToken = default)
+    {
+        var request = new
+        {
+            RequestType = "AddWatchTargetByPath",
+            TagName = tagName,
+            Directory = directory,
+            Options = options
+        };
+
+        var responseJson = await SendRequestAsync(JsonSerializer.Serialize(request), cancellationToken);
+        return JsonSerializer.Deserialize<AddWatchTargetByPathResponse>(responseJson)
+            ?? throw new InvalidOperationException("応答のデシリアライズに失敗しました");
+    }
+
     /// <summary>
     /// 監視対象を削除
     /// </summary>
@@ -123,13 +178,14 @@ public class ProcTailPipeClient : IProcTailPipeClient
     /// <summary>
     /// 記録されたイベントを取得
     /// </summary>
-    public async Task<GetRecordedEventsResponse> GetRecordedEventsAsync(string tagName, int maxCount = 100, CancellationToken cancellationToken = default)
+    public async Task<GetRecordedEventsResponse> GetRecordedEventsAsync(string tagName, int maxCount = 100, string? query = null, CancellationToken cancellationToken = default)
     {
         var request = new
         {
             RequestType = "GetRecordedEvents",
             TagName = tagName,
-            MaxCount = maxCount
+            MaxCount = maxCount,
+            Filter = CreateQueryFilter(query)
         };
 
         var responseJson = await SendRequestAsync(JsonSerializer.Serialize(request), cancellationToken);
@@ -137,6 +193,100 @@ public class ProcTailPipeClient : IProcTailPipeClient
             ?? throw new InvalidOperationException("応答のデシリアライズに失敗しました");
     }
 
+    /// <summary>
+    /// 記録されたイベントをカーソルの続きから取得
+    /// </summary>
+    public async Task<GetRecordedEventsResponse> GetRecordedEventsPageAsync(string tagName, string? cursor, int limit = 1000, string? query = null, CancellationToken cancellationToken = default)
+    {
+        var request = new
+        {
+            RequestType = "GetRecordedEvents",
+            TagName = tagName,
+            Cursor = cursor,
+            Limit = limit,
+            Filter = CreateQueryFilter(query)
+        };
+
+        var responseJson = await SendRequestAsync(JsonSerializer.Serialize(request), cancellationToken);
+        return JsonSerializer.Deserialize<GetRecordedEventsResponse>(responseJson)
+            ?? throw new InvalidOperationException("応答のデシリアライズに失敗しました");
+    }
+
+    private static EventFilter? CreateQueryFilter(string? query)
+    {
+        return string.IsNullOrWhiteSpace(query) ? null : new EventFilter { Query = query };
+    }
+
+    /// <summary>
+    /// 生ETWイベントを取得
+    /// </summary>
+    public async Task<GetRawEventsResponse> GetRawEventsAsync(string tagName, long afterSequenceNumber = 0, int maxCount = 1000, CancellationToken cancellationToken = default)
+    {
+        var request = new
+        {
+            RequestType = "GetRawEvents",
+            TagName = tagName,
+            AfterSequenceNumber = afterSequenceNumber,
+            MaxCount = maxCount
+        };
+
+        var responseJson = await SendRequestAsync(JsonSerializer.Serialize(request), cancellationToken);
+        return JsonSerializer.Deserialize<GetRawEventsResponse>(responseJson)
+            ?? throw new InvalidOperationException("応答のデシリアライズに失敗しました");
+    }
+
+    /// <summary>
+    /// アラートを取得
+    /// </summary>
+    public async Task<GetAlertsResponse> GetAlertsAsync(string? tagName = null, long afterSequenceNumber = 0, int maxCount = 1000, CancellationToken cancellationToken = default)
+    {
+        var request = new
+        {
+            RequestType = "GetAlerts",
+            TagName = tagName,
+            AfterSequenceNumber = afterSequenceNumber,
+            MaxCount = maxCount
+        };
+
+        var responseJson = await SendRequestAsync(JsonSerializer.Serialize(request), cancellationToken);
+        return JsonSerializer.Deserialize<GetAlertsResponse>(responseJson)
+            ?? throw new InvalidOperationException("応答のデシリアライズに失敗しました");
+    }
+
+    /// <summary>
+    /// セーブデータの保存先候補を取得
+    /// </summary>
+    public async Task<GetSaveDirCandidatesResponse> GetSaveDirCandidatesAsync(string tagName, int maxCount = 10, CancellationToken cancellationToken = default)
+    {
+        var request = new
+        {
+            RequestType = "GetSaveDirCandidates",
+            TagName = tagName,
+            MaxCount = maxCount
+        };
+
+        var responseJson = await SendRequestAsync(JsonSerializer.Serialize(request), cancellationToken);
+        return JsonSerializer.Deserialize<GetSaveDirCandidatesResponse>(responseJson)
+            ?? throw new InvalidOperationException("応答のデシリアライズに失敗しました");
+    }
+
+    /// <summary>
+    /// タグのイベントの集計を取得
+    /// </summary>
+    public async Task<GetEventSummaryResponse> GetEventSummaryAsync(string tagName, int topCount = 10, CancellationToken cancellationToken = default)
+    {
+        var request = new
+        {
+            RequestType = "GetEventSummary",
+            TagName = tagName,
+            TopCount = topCount
+        };
+
+        var responseJson = await SendRequestAsync(JsonSerializer.Serialize(request), cancellationToken);
+        return JsonSerializer.Deserialize<GetEventSummaryResponse>(responseJson)
+            ?? throw new InvalidOperationException("応答のデシリアライズに失敗しました");
+    }
+
     /// <summary>
     /// サービス状態を取得
     /// </summary>
@@ -208,6 +358,13 @@ public class ProcTailPipeClient : IProcTailPipeClient
         if (_disposed)
             throw new ObjectDisposedException(nameof(ProcTailPipeClient));
 
+        if (_authToken != null)
+        {
+            var requestNode = JsonNode.Parse(requestJson)!.AsObject();
+            requestNode["AuthToken"] = _authToken;
+            requestJson = requestNode.ToJsonString();
+        }
+
         using var pipeClient = new NamedPipeClientStream(".", _pipeName, PipeDirection.InOut, PipeOptions.Asynchronous);
 
         try
@@ -339,10 +496,41 @@ public class ServiceStatusResponse
     public bool IsRunning { get; set; }
     public bool IsEtwMonitoring { get; set; }
     public bool IsPipeServerRunning { get; set; }
+    public bool IsRawPassthroughEnabled { get; set; }
     public int ActiveWatchTargets { get; set; }
     public int TotalTags { get; set; }
     public int TotalEvents { get; set; }
     public long EstimatedMemoryUsageMB { get; set; }
+    public long EtwEventsLost { get; set; }
+    public List<TagBufferStatus> Buffers { get; set; } = new();
+    public ClockMapping? ClockMapping { get; set; }
+    public List<DiagnosticCheckStatus> Diagnostics { get; set; } = new();
     public string Message { get; set; } = string.Empty;
     public string ErrorMessage { get; set; } = string.Empty;
+}
+
+/// <summary>
+/// タグのイベントバッファの状態
+/// </summary>
+public class TagBufferStatus
+{
+    public string TagName { get; set; } = string.Empty;
+    public string Policy { get; set; } = string.Empty;
+    public int EventCount { get; set; }
+    public long DroppedCount { get; set; }
+    public long ExcludedProcessCount { get; set; }
+    public long PathFilteredCount { get; set; }
+    public long RateLimitedCount { get; set; }
+    public long OutsideRootCount { get; set; }
+    public long OutsideRootBytesWritten { get; set; }
+}
+
+/// <summary>
+/// 起動時診断の結果
+/// </summary>
+public class DiagnosticCheckStatus
+{
+    public string Name { get; set; } = string.Empty;
+    public string Status { get; set; } = string.Empty;
+    public string Message { get; set; } = string.Empty;
 }
\ No newline at end of file
@@ -214,7 +214,7 @@ public class WindowsServiceManager : IWindowsServiceManager
             }
 
             // sc.exe コマンドを使用してサービスをインストール
-            var arguments = $"create \"{serviceName}\" binpath= \"{binaryPath}\" start= auto DisplayName= \"{displayName}\"";
+            var arguments = CreateInstallArguments(serviceName, binaryPath, displayName);
             var processInfo = new ProcessStartInfo
             {
                 FileName = "sc.exe",
@@ -245,6 +245,12 @@ public class WindowsServiceManager : IWindowsServiceManager
                 {
                     await SetServiceDescriptionAsync(serviceName, description);
                 }
+
+                // 異常終了時に自動で再起動するよう回復オプションを設定
+                if (!await ConfigureRecoveryAsync(serviceName))
+                {
+                    _logger.LogWarning("サービス '{ServiceName}' の回復オプションを設定できませんでした", serviceName);
+                }
                 
                 return true;
             }
@@ -351,17 +357,7 @@ public class WindowsServiceManager : IWindowsServiceManager
             using var service = new ServiceController(serviceName);
             service.Refresh();
             
-            return service.Status switch
-            {
-                ServiceControllerStatus.Stopped => ServiceStatus.Stopped,
-                ServiceControllerStatus.Running => ServiceStatus.Running,
-                ServiceControllerStatus.Paused => ServiceStatus.Paused,
-                ServiceControllerStatus.StartPending => ServiceStatus.StartPending,
-                ServiceControllerStatus.StopPending => ServiceStatus.StopPending,
-                ServiceControllerStatus.ContinuePending => ServiceStatus.ContinuePending,
-                ServiceControllerStatus.PausePending => ServiceStatus.PausePending,
-                _ => ServiceStatus.NotFound
-            };
+            return ToServiceStatus(service.Status);
         }
         catch (InvalidOperationException)
         {
@@ -420,7 +416,7 @@ public class WindowsServiceManager : IWindowsServiceManager
     {
         try
         {
-            var arguments = $"description \"{serviceName}\" \"{description}\"";
+            var arguments = CreateDescriptionArguments(serviceName, description);
             var processInfo = new ProcessStartInfo
             {
                 FileName = "sc.exe",
@@ -443,6 +439,93 @@ public class WindowsServiceManager : IWindowsServiceManager
         }
     }
 
+    /// <summary>
+    /// サービスの回復オプションを設定（5秒後、10秒後、60秒後に再起動し、1日で失敗回数をリセット）
+    /// </summary>
+    private async Task<bool> ConfigureRecoveryAsync(string serviceName)
+    {
+        try
+        {
+            foreach (var arguments in CreateRecoveryArguments(serviceName))
+            {
+                var processInfo = new ProcessStartInfo
+                {
+                    FileName = "sc.exe",
+                    Arguments = arguments,
+                    UseShellExecute = false,
+                    RedirectStandardOutput = true,
+                    RedirectStandardError = true,
+                    CreateNoWindow = true
+                };
+
+                using var process = Process.Start(processInfo);
+                if (process == null) return false;
+
+                await process.WaitForExitAsync();
+                if (process.ExitCode != 0)
+                {
+                    _logger.LogWarning("sc.exe {Arguments} が失敗しました。終了コード: {ExitCode}", arguments, process.ExitCode);
+                    return false;
+                }
+            }
+
+            return true;
+        }
+        catch (Exception ex)
+        {
+            _logger.LogWarning(ex, "回復オプションの設定中にエラーが発生しました");
+            return false;
+        }
+    }
+
+    /// <summary>
+    /// サービスをインストールする sc.exe の引数を作成
+    /// </summary>
+    /// <remarks>空白を含むパスが別の実行ファイルとして解釈されないよう、binpathのパスは引用符で囲む</remarks>
+    internal static string CreateInstallArguments(string serviceName, string binaryPath, string displayName)
+    {
+        return $"create \"{serviceName}\" binpath= \"\\\"{binaryPath}\\\"\" start= auto DisplayName= \"{displayName}\"";
+    }
+
+    /// <summary>
+    /// サービス説明を設定する sc.exe の引数を作成
+    /// </summary>
+    internal static string CreateDescriptionArguments(string serviceName, string description)
+    {
+        return $"description \"{serviceName}\" \"{description}\"";
+    }
+
+    /// <summary>
+    /// 回復オプションを設定する sc.exe の引数を作成
+    /// </summary>
+    /// <remarks>failureflagを有効にし、クラッシュだけでなく終了コード0以外での停止でも回復動作を行う</remarks>
+    internal static IReadOnlyList<string> CreateRecoveryArguments(string serviceName)
+    {
+        return new[]
+        {
+            $"failure \"{serviceName}\" reset= 86400 actions= restart/5000/restart/10000/restart/60000",
+            $"failureflag \"{serviceName}\" 1"
+        };
+    }
+
+    /// <summary>
+    /// ServiceControllerの状態をサービス状態に変換
+    /// </summary>
+    internal static ServiceStatus ToServiceStatus(ServiceControllerStatus status)
+    {
+        return status switch
+        {
+            ServiceControllerStatus.Stopped => ServiceStatus.Stopped,
+            ServiceControllerStatus.Running => ServiceStatus.Running,
+            ServiceControllerStatus.Paused => ServiceStatus.Paused,
+            ServiceControllerStatus.StartPending => ServiceStatus.StartPending,
+            ServiceControllerStatus.StopPending => ServiceStatus.StopPending,
+            ServiceControllerStatus.ContinuePending => ServiceStatus.ContinuePending,
+            ServiceControllerStatus.PausePending => ServiceStatus.PausePending,
+            _ => ServiceStatus.NotFound
+        };
+    }
+
     /// <summary>
     /// 管理者権限で実行されているかチェック
     /// </summary>
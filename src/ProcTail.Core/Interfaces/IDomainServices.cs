@@ -16,13 +16,47 @@ public interface IWatchTargetManager
     Task<bool> AddTargetAsync(int processId, string tagName);
 
     /// <summary>
-    /// 子プロセスを自動追加
+    /// 監視対象をタグのオプション付きで追加
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="options">タグに適用する監視オプション</param>
+    /// <returns>追加成功の場合true</returns>
+    Task<bool> AddTargetAsync(int processId, string tagName, WatchTargetOptions options);
+
+    /// <summary>
+    /// 子プロセスを親プロセスが属する全てのタグに自動追加
     /// </summary>
     /// <param name="childProcessId">子プロセスID</param>
     /// <param name="parentProcessId">親プロセスID</param>
-    /// <returns>追加成功の場合true</returns>
+    /// <returns>いずれかのタグに追加した場合true</returns>
     Task<bool> AddChildProcessAsync(int childProcessId, int parentProcessId);
 
+    /// <summary>
+    /// インストールディレクトリ配下の実行ファイルから起動したプロセスを監視対象として登録
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="directory">ディレクトリ（配下のサブディレクトリを含む）</param>
+    /// <param name="options">タグに適用する監視オプション（nullの場合は既存のオプションを維持）</param>
+    /// <returns>登録時点で既に実行中だったため追加されたプロセス数</returns>
+    Task<int> AddPathTargetAsync(string tagName, string directory, WatchTargetOptions? options);
+
+    /// <summary>
+    /// 実行ファイルが登録済みディレクトリ配下にある場合、プロセスを監視対象に追加
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <param name="executablePath">実行ファイルパス（nullの場合はプロセスから取得）</param>
+    /// <returns>追加された場合true</returns>
+    Task<bool> AddProcessByPathAsync(int processId, string? executablePath);
+
+    /// <summary>
+    /// 再監視期間内に終了した監視対象と同じ実行ファイルから起動したプロセスを、終了したプロセスのタグに追加
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <param name="executablePath">実行ファイルパス（nullの場合はプロセスから取得）</param>
+    /// <returns>いずれかのタグに追加した場合true</returns>
+    Task<bool> RewatchRestartedProcessAsync(int processId, string? executablePath);
+
     /// <summary>
     /// プロセスが監視対象かチェック
     /// </summary>
@@ -30,13 +64,42 @@ public interface IWatchTargetManager
     /// <returns>監視対象の場合true</returns>
     bool IsWatchedProcess(int processId);
 
+    /// <summary>
+    /// プロセスがタグの除外ルールに一致するかチェック
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <param name="tagName">タグ名</param>
+    /// <returns>除外対象の場合true</returns>
+    bool IsExcludedProcess(int processId, string tagName);
+
+    /// <summary>
+    /// 監視中のプロセスの実行ファイルパスを更新（exec後など）
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <param name="executablePath">実行ファイルパス</param>
+    void UpdateExecutablePath(int processId, string executablePath);
+
     /// <summary>
     /// プロセスのタグ名を取得
     /// </summary>
     /// <param name="processId">プロセスID</param>
-    /// <returns>タグ名（監視対象でない場合null）</returns>
+    /// <returns>最初に追加したタグ名（監視対象でない場合null）</returns>
     string? GetTagForProcess(int processId);
 
+    /// <summary>
+    /// プロセスが属する全てのタグ名を取得
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <returns>追加順のタグ名（監視対象でない場合は空）</returns>
+    IReadOnlyList<string> GetTagsForProcess(int processId);
+
+    /// <summary>
+    /// タグの監視オプションを取得
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <returns>監視オプション（未指定の場合は既定値）</returns>
+    WatchTargetOptions GetOptionsForTag(string tagName);
+
     /// <summary>
     /// 監視対象一覧を取得
     /// </summary>
@@ -44,7 +107,7 @@ public interface IWatchTargetManager
     IReadOnlyList<WatchTarget> GetWatchTargets();
 
     /// <summary>
-    /// 監視対象を削除（プロセス終了時）
+    /// 監視対象を全てのタグから削除（プロセス終了時）
     /// </summary>
     /// <param name="processId">プロセスID</param>
     /// <returns>削除成功の場合true</returns>
@@ -83,6 +146,23 @@ public interface IEventProcessor
     /// <param name="rawEvent">生ETWイベント</param>
     /// <returns>処理すべき場合true</returns>
     bool ShouldProcessEvent(RawEventData rawEvent);
+
+    /// <summary>
+    /// タグごとのフィルタにより記録しなかったイベント数を取得
+    /// </summary>
+    /// <returns>タグ名をキーとしたフィルタ統計</returns>
+    IReadOnlyDictionary<string, TagFilterStatistics> GetFilterStatistics();
+}
+
+/// <summary>
+/// 変換後に遅れて作成したイベントを配信できるプロセッサー（クラッシュダンプの検出など）
+/// </summary>
+public interface IFollowUpEventSource
+{
+    /// <summary>
+    /// 遅れて作成したイベントを配信する時に発火するイベント
+    /// </summary>
+    event EventHandler<BaseEventData>? FollowUpEventCreated;
 }
 
 /// <summary>
@@ -95,8 +175,8 @@ public interface IEventStorage
     /// </summary>
     /// <param name="tagName">タグ名</param>
     /// <param name="eventData">イベントデータ</param>
-    /// <returns>非同期タスク</returns>
-    Task StoreEventAsync(string tagName, BaseEventData eventData);
+    /// <returns>連番を付与して記録したイベント（破棄した場合や記録できなかった場合はnull）</returns>
+    Task<BaseEventData?> StoreEventAsync(string tagName, BaseEventData eventData);
 
     /// <summary>
     /// タグに関連するイベントを取得
@@ -147,6 +227,14 @@ public interface IEventStorage
     /// <param name="count">取得件数</param>
     /// <returns>イベント一覧</returns>
     Task<IReadOnlyList<BaseEventData>> GetLatestEventsAsync(string tagName, int count);
+
+    /// <summary>
+    /// タグのバッファが満杯の場合の動作を設定
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="policy">満杯時の動作</param>
+    /// <param name="blockTimeout">BackpressurePolicy.Blockで空きを待つ最大時間</param>
+    void SetBackpressurePolicy(string tagName, BackpressurePolicy policy, TimeSpan blockTimeout);
 }
 
 /// <summary>
@@ -17,6 +17,16 @@ public interface IEtwEventProvider : IDisposable
     /// </summary>
     bool IsMonitoring { get; }
 
+    /// <summary>
+    /// 現在のセッションにおける単調増加クロックと壁時計の対応付け
+    /// </summary>
+    ClockMapping ClockMapping { get; }
+
+    /// <summary>
+    /// バッファ溢れなどでバックエンドが取りこぼしたイベントの累計（タグに関係なく全体の値）
+    /// </summary>
+    long EventsLost { get; }
+
     /// <summary>
     /// ETW監視を開始
     /// </summary>
@@ -32,6 +42,98 @@ public interface IEtwEventProvider : IDisposable
     Task StopMonitoringAsync(CancellationToken cancellationToken = default);
 }
 
+/// <summary>
+/// 変換前のETWイベントを配信できるプロバイダー（生イベントのパススルー）
+/// </summary>
+public interface IRawEventSource
+{
+    /// <summary>
+    /// 生イベントの配信が有効かどうか（設定でオプトインした場合のみ有効）
+    /// </summary>
+    bool IsRawPassthroughEnabled { get; }
+
+    /// <summary>
+    /// 配信するプロセスの判定（未設定の場合は配信しない）
+    /// </summary>
+    Func<int, bool>? RawRecordFilter { get; set; }
+
+    /// <summary>
+    /// 生イベント受信時に発火するイベント
+    /// </summary>
+    event EventHandler<RawEtwRecord>? RawRecordReceived;
+}
+
+/// <summary>
+/// タグごとにディレクトリの変更を監視するウォッチャー（ETWの代替バックエンド）
+/// </summary>
+/// <remarks>
+/// 変更したプロセスは分からないため、生成するイベントはPIDを0としてタグを直接指定する。
+/// </remarks>
+public interface IDirectoryChangeWatcher : IDisposable
+{
+    /// <summary>
+    /// ディレクトリの変更を受信した時に発火するイベント
+    /// </summary>
+    event EventHandler<RawEventData>? EventReceived;
+
+    /// <summary>
+    /// バッファ溢れで失われた変更通知の累計
+    /// </summary>
+    long EventsLost { get; }
+
+    /// <summary>
+    /// タグのディレクトリ監視を開始（同じタグの既存の監視は置き換える）
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="directories">監視するディレクトリ（配下のサブディレクトリも含む）</param>
+    /// <returns>監視を開始できたディレクトリ数</returns>
+    int Watch(string tagName, IReadOnlyList<string> directories);
+
+    /// <summary>
+    /// タグのディレクトリをポーリングで監視（変更通知を持たないファイルシステム向け。同じタグの既存の監視は置き換える）
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="directories">監視するディレクトリ（配下のサブディレクトリも含む）</param>
+    /// <param name="interval">ポーリング間隔</param>
+    /// <returns>監視を開始できたディレクトリ数</returns>
+    int WatchByPolling(string tagName, IReadOnlyList<string> directories, TimeSpan interval);
+
+    /// <summary>
+    /// タグのディレクトリ監視を停止
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    void Unwatch(string tagName);
+}
+
+/// <summary>
+/// フォアグラウンドウィンドウを持つプロセスの変化を監視するモニター
+/// </summary>
+/// <remarks>
+/// 生成するイベントのPIDはフォーカスを得た・失ったウィンドウのプロセスのため、監視対象かどうかの判定はETWのイベントと同じ経路で行う。
+/// </remarks>
+public interface IForegroundWindowMonitor : IDisposable
+{
+    /// <summary>
+    /// フォアグラウンドのプロセスが変わった時に発火するイベント
+    /// </summary>
+    event EventHandler<RawEventData>? EventReceived;
+
+    /// <summary>
+    /// 監視中かどうか
+    /// </summary>
+    bool IsMonitoring { get; }
+
+    /// <summary>
+    /// 監視を開始
+    /// </summary>
+    void Start();
+
+    /// <summary>
+    /// 監視を停止
+    /// </summary>
+    void Stop();
+}
+
 /// <summary>
 /// ETW設定の抽象化
 /// </summary>
@@ -47,6 +149,26 @@ public interface IEtwConfiguration
     /// </summary>
     IReadOnlyList<string> EnabledEventNames { get; }
 
+    /// <summary>
+    /// カーネルセッションで有効にするキーワード（空の場合は有効なプロバイダーから決定）
+    /// </summary>
+    IReadOnlyList<string> KernelKeywords { get; }
+
+    /// <summary>
+    /// ユーザーモードプロバイダーごとのレベルとキーワード（未設定のプロバイダーは全て購読）
+    /// </summary>
+    IReadOnlyDictionary<string, EtwProviderSettings> ProviderSettings { get; }
+
+    /// <summary>
+    /// 監視対象プロセスの生イベントを変換せずに配信するか
+    /// </summary>
+    bool EnableRawPassthrough { get; }
+
+    /// <summary>
+    /// 生イベントの配信用に追加で有効にするユーザーモードプロバイダー（名前またはGUID）
+    /// </summary>
+    IReadOnlyList<string> RawPassthroughProviders { get; }
+
     /// <summary>
     /// イベントバッファタイムアウト
     /// </summary>
@@ -61,4 +183,14 @@ public interface IEtwConfiguration
     /// ETWセッションのバッファ数
     /// </summary>
     int BufferCount { get; }
+
+    /// <summary>
+    /// 全タグ合計で記録する1秒あたりの最大イベント数（0以下の場合は無制限）
+    /// </summary>
+    int MaxEventsPerSecond { get; }
+
+    /// <summary>
+    /// レート上限を超えたときに残すイベントの間隔（N件に1件）
+    /// </summary>
+    int OverflowSampleRate { get; }
 }
\ No newline at end of file
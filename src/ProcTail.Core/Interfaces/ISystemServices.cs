@@ -10,7 +10,8 @@ public record ProcessInfo(
     string ProcessName,
     string ExecutablePath,
     DateTime StartTime,
-    int? ParentProcessId
+    int? ParentProcessId,
+    int? SessionId = null
 );
 
 /// <summary>
@@ -64,6 +65,51 @@ public interface ISystemPrivilegeChecker
     Task<bool> TryElevatePrivilegesAsync();
 }
 
+/// <summary>
+/// 起動時診断の判定
+/// </summary>
+public enum DiagnosticStatus
+{
+    /// <summary>
+    /// 問題なし
+    /// </summary>
+    Passed,
+
+    /// <summary>
+    /// 一部のイベントが記録されない可能性がある
+    /// </summary>
+    Warning,
+
+    /// <summary>
+    /// イベントが記録されない
+    /// </summary>
+    Failed
+}
+
+/// <summary>
+/// 起動時診断の結果
+/// </summary>
+/// <param name="Name">チェック名</param>
+/// <param name="Status">判定</param>
+/// <param name="Message">結果の説明（失敗時は対処方法を含む）</param>
+public record DiagnosticCheckResult(
+    string Name,
+    DiagnosticStatus Status,
+    string Message
+);
+
+/// <summary>
+/// 起動時の権限・前提条件チェックの抽象化
+/// </summary>
+public interface IStartupDiagnostics
+{
+    /// <summary>
+    /// 全てのチェックを実行
+    /// </summary>
+    /// <returns>チェックごとの結果</returns>
+    IReadOnlyList<DiagnosticCheckResult> Run();
+}
+
 /// <summary>
 /// システム時刻の抽象化（テスト用）
 /// </summary>
@@ -78,4 +124,160 @@ public interface ISystemClock
     /// 現在のローカル時刻
     /// </summary>
     DateTime Now { get; }
-}
\ No newline at end of file
+}
+/// <summary>
+/// 他プロセスの環境変数読み取りの抽象化
+/// </summary>
+public interface IProcessEnvironmentReader
+{
+    /// <summary>
+    /// 指定した名前の環境変数を読み取る
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <param name="names">読み取る環境変数名（許可リスト）</param>
+    /// <returns>見つかった環境変数（プロセスにアクセスできない場合は空）</returns>
+    IReadOnlyDictionary<string, string> ReadEnvironment(int processId, IReadOnlyCollection<string> names);
+}
+
+/// <summary>
+/// プロセスのトークン情報
+/// </summary>
+/// <param name="UserSid">ユーザーのSID（Linux/macOSではUID）</param>
+/// <param name="SessionId">セッションID（Linux/macOSではセッションリーダーのプロセスID）</param>
+/// <param name="IntegrityLevel">整合性レベル（Untrusted/Low/Medium/MediumPlus/High/System/Protected。Windows以外ではnull）</param>
+public record ProcessTokenInfo(string? UserSid, int? SessionId, string? IntegrityLevel);
+
+/// <summary>
+/// 他プロセスのトークン情報読み取りの抽象化
+/// </summary>
+public interface IProcessTokenReader
+{
+    /// <summary>
+    /// プロセスのトークン情報を読み取る
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <returns>トークン情報（プロセスが終了済み・アクセスできない場合はnull）</returns>
+    ProcessTokenInfo? ReadToken(int processId);
+}
+
+/// <summary>
+/// プロセスのリソース使用量
+/// </summary>
+/// <param name="CpuTime">累計CPU時間（ユーザー＋カーネル）</param>
+/// <param name="UserCpuTime">累計ユーザーモードCPU時間</param>
+/// <param name="WorkingSetBytes">ワーキングセット（バイト）</param>
+/// <param name="HandleCount">ハンドル数（Linux/macOSではファイルディスクリプタ数）</param>
+/// <param name="ReadOperations">累計読み取り操作数（取得できない場合はnull）</param>
+/// <param name="WriteOperations">累計書き込み操作数（取得できない場合はnull）</param>
+/// <param name="ReadBytes">累計読み取りバイト数（取得できない場合はnull）</param>
+/// <param name="WriteBytes">累計書き込みバイト数（取得できない場合はnull）</param>
+public record ProcessResourceUsage(
+    TimeSpan CpuTime,
+    TimeSpan UserCpuTime,
+    long WorkingSetBytes,
+    int HandleCount,
+    long? ReadOperations = null,
+    long? WriteOperations = null,
+    long? ReadBytes = null,
+    long? WriteBytes = null);
+
+/// <summary>
+/// 他プロセスのリソース使用量読み取りの抽象化
+/// </summary>
+public interface IProcessResourceReader
+{
+    /// <summary>
+    /// プロセスのリソース使用量を読み取る
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <returns>リソース使用量（プロセスが終了済み・アクセスできない場合はnull）</returns>
+    ProcessResourceUsage? ReadUsage(int processId);
+}
+
+/// <summary>
+/// プロセスのウィンドウの応答状態
+/// </summary>
+/// <param name="IsHung">いずれかの表示中のトップレベルウィンドウが応答していない場合true</param>
+/// <param name="WindowTitle">応答していないウィンドウ（応答している場合は最初のウィンドウ）のタイトル</param>
+public record ProcessWindowState(bool IsHung, string WindowTitle);
+
+/// <summary>
+/// プロセスのウィンドウが応答しているかどうかの確認の抽象化
+/// </summary>
+public interface IProcessHangProbe
+{
+    /// <summary>
+    /// プロセスのウィンドウの応答状態を確認
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <returns>応答状態（表示中のウィンドウがない場合やWindows以外ではnull）</returns>
+    ProcessWindowState? Probe(int processId);
+}
+
+/// <summary>
+/// ファイル内容のハッシュ計算の抽象化
+/// </summary>
+public interface IFileContentHasher
+{
+    /// <summary>
+    /// ファイル内容のSHA-256を計算
+    /// </summary>
+    /// <param name="filePath">ファイルパス</param>
+    /// <param name="cancellationToken">キャンセレーショントークン</param>
+    /// <returns>小文字16進数のハッシュ（ファイルが存在しない・読み取れない場合はnull）</returns>
+    Task<string?> ComputeSha256Async(string filePath, CancellationToken cancellationToken = default);
+}
+
+/// <summary>
+/// プロセスの実行ファイルのハッシュ計算の抽象化
+/// </summary>
+public interface IImageHashProvider
+{
+    /// <summary>
+    /// プロセスの実行ファイルのSHA-256を取得
+    /// </summary>
+    /// <param name="processId">プロセスID</param>
+    /// <param name="cancellationToken">キャンセレーショントークン</param>
+    /// <returns>小文字16進数のハッシュ（プロセスが終了済み・ファイルを読めない場合はnull）</returns>
+    Task<string?> GetImageSha256Async(int processId, CancellationToken cancellationToken = default);
+}
+
+/// <summary>
+/// ファイルパスに含まれる再解析ポイント（シンボリックリンク・ジャンクション・マウントポイント）の解決の抽象化
+/// </summary>
+public interface IReparsePointResolver
+{
+    /// <summary>
+    /// 再解析ポイントを解決した実際のパスを取得
+    /// </summary>
+    /// <param name="filePath">ファイルパス</param>
+    /// <returns>解決したパス（再解析ポイントを含まない場合や解決できない場合はnull）</returns>
+    string? ResolveFinalPath(string filePath);
+}
+
+/// <summary>
+/// クラッシュダンプの検索の抽象化
+/// </summary>
+public interface ICrashDumpLocator
+{
+    /// <summary>
+    /// クラッシュしたプロセスのダンプファイルを検索（ダンプの書き込みが終わるまで一定時間待つ）
+    /// </summary>
+    /// <param name="applicationName">クラッシュしたプロセスの実行ファイル名</param>
+    /// <param name="processId">クラッシュしたプロセスのID</param>
+    /// <param name="cancellationToken">キャンセレーショントークン</param>
+    /// <returns>ダンプファイルのパス（作成されなかった場合はnull）</returns>
+    Task<string?> FindDumpPathAsync(string applicationName, int processId, CancellationToken cancellationToken = default);
+}
+
+/// <summary>
+/// アラート通知の抽象化
+/// </summary>
+public interface IAlertNotifier
+{
+    /// <summary>
+    /// アラートを通知（イベント処理を止めないよう、表示の完了を待たずに戻る）
+    /// </summary>
+    /// <param name="alert">生成したアラート</param>
+    void Notify(Alert alert);
+}
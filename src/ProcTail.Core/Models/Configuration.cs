@@ -57,7 +57,13 @@ public class EventSettings
     public List<string> EnabledProviders { get; set; } = new()
     {
         "Microsoft-Windows-Kernel-FileIO",
-        "Microsoft-Windows-Kernel-Process"
+        "Microsoft-Windows-Kernel-Process",
+        "Microsoft-Windows-Kernel-Registry",
+        "Microsoft-Windows-Kernel-Network",
+        "Microsoft-Windows-DNS-Client",
+        "Microsoft-Windows-Kernel-Image",
+        "Microsoft-Windows-Kernel-Thread",
+        "Application Error"
     };
 
     /// <summary>
@@ -71,10 +77,46 @@ public class EventSettings
         "FileIo/Rename",
         "FileIo/SetInfo",
         "Process/Start",
-        "Process/End"
+        "Process/End",
+        "Registry/Create",
+        "Registry/Open",
+        "Registry/Delete",
+        "Registry/SetValue",
+        "Registry/DeleteValue",
+        "TcpIp/Connect",
+        "TcpIp/Accept",
+        "TcpIp/Send",
+        "TcpIp/Recv",
+        "UdpIp/Send",
+        "UdpIp/Recv",
+        "Dns/QueryCompleted",
+        "Image/Load",
+        "Image/Unload",
+        "Thread/Start",
+        "Thread/End",
+        "Crash/Detected"
     };
 }
 
+/// <summary>
+/// ユーザーモードETWプロバイダーの購読設定
+/// </summary>
+/// <remarks>
+/// カーネルプロバイダー（Microsoft-Windows-Kernel-*）はレベル・キーワードではなくカーネルキーワードで制御するため対象外。
+/// </remarks>
+public class EtwProviderSettings
+{
+    /// <summary>
+    /// 購読するレベル（Critical、Error、Warning、Informational、Verbose）
+    /// </summary>
+    public string Level { get; set; } = "Verbose";
+
+    /// <summary>
+    /// 購読するキーワード（いずれかに一致するイベントを受け取る）
+    /// </summary>
+    public ulong MatchAnyKeywords { get; set; } = ulong.MaxValue;
+}
+
 /// <summary>
 /// Named Pipe設定
 /// </summary>
@@ -154,16 +196,293 @@ public class LoggingSettings
 }
 
 /// <summary>
-/// 監視対象エントリ
+/// 監視対象エントリ（複数のタグに属するプロセスはタグごとに1件）
 /// </summary>
 public record WatchTarget(
     int ProcessId,
     string TagName,
     DateTime RegisteredAt,
     bool IsChildProcess = false,
-    int? ParentProcessId = null
+    int? ParentProcessId = null,
+    IReadOnlyList<ProcessAncestor>? Ancestors = null,
+    int Depth = 0,
+    string? ExecutablePath = null
 );
 
+/// <summary>
+/// タグごとの監視オプション
+/// </summary>
+public record WatchTargetOptions
+{
+    /// <summary>
+    /// 既定のオプション（追加のイベント収集なし）
+    /// </summary>
+    public static WatchTargetOptions Default { get; } = new();
+
+    /// <summary>
+    /// スレッド開始・終了イベントを記録するかどうか
+    /// </summary>
+    public bool IncludeThreadEvents { get; init; }
+
+    /// <summary>
+    /// ファイル読み取りイベントのサンプリング間隔（0: 記録しない, 1: 全て記録, N: N回に1回記録）
+    /// </summary>
+    public int FileReadSampleRate { get; init; }
+
+    /// <summary>
+    /// 子プロセス開始時に記録する環境変数名（許可リスト、空の場合は記録しない）
+    /// </summary>
+    public IReadOnlyList<string> EnvironmentVariables { get; init; } = Array.Empty<string>();
+
+    /// <summary>
+    /// 自動追加する子孫プロセスの最大深さ（null: 無制限, 0: 子プロセスを追加しない, 1: 直接の子のみ）
+    /// </summary>
+    public int? MaxChildDepth { get; init; }
+
+    /// <summary>
+    /// 親と異なるセッションで開始された子プロセスも自動追加するかどうか
+    /// </summary>
+    public bool FollowAcrossSessions { get; init; } = true;
+
+    /// <summary>
+    /// 自動追加を打ち切る実行ファイル名（ランチャーやアップデーターなど、該当プロセスとその子孫は追加しない）
+    /// </summary>
+    public IReadOnlyList<string> StopAtExecutables { get; init; } = Array.Empty<string>();
+
+    /// <summary>
+    /// イベントを記録しないプロセス（PID、イメージ名、またはパスのglobパターン。該当プロセスの子プロセスも追加しない）
+    /// </summary>
+    public IReadOnlyList<string> ExcludedProcesses { get; init; } = Array.Empty<string>();
+
+    /// <summary>
+    /// 記録するファイルイベントのパス（globまたは "regex:" で始まる正規表現。空の場合は全て記録）
+    /// </summary>
+    public IReadOnlyList<string> IncludePaths { get; init; } = Array.Empty<string>();
+
+    /// <summary>
+    /// 記録しないファイルイベントのパス（globまたは "regex:" で始まる正規表現。IncludePathsより優先）
+    /// </summary>
+    public IReadOnlyList<string> ExcludePaths { get; init; } = Array.Empty<string>();
+
+    /// <summary>
+    /// ファイルイベントを記録するディレクトリ（配下を含む。空の場合は全て記録）
+    /// </summary>
+    /// <remarks>
+    /// 指定した場合、ディレクトリ外のファイルイベントは記録せず、件数と書き込みバイト数だけをタグのフィルタ統計に数える。
+    /// IncludePaths・ExcludePathsはディレクトリ内のファイルイベントに適用する。
+    /// </remarks>
+    public IReadOnlyList<string> RecordRoots { get; init; } = Array.Empty<string>();
+
+    /// <summary>
+    /// 書き込んだハンドルのクローズ時に内容のSHA-256を記録するファイルのパス（globまたは "regex:" で始まる正規表現。空の場合は記録しない）
+    /// </summary>
+    public IReadOnlyList<string> HashOnClosePaths { get; init; } = Array.Empty<string>();
+
+    /// <summary>
+    /// ファイルイベントの取得元（Etw以外の場合はETWのファイルイベントを使わずWatchDirectoriesの変更を記録）
+    /// </summary>
+    public FileEventBackend Backend { get; init; } = FileEventBackend.Etw;
+
+    /// <summary>
+    /// FileEventBackend.DirectoryWatcher・Pollingで監視するディレクトリ（配下のサブディレクトリも含む）
+    /// </summary>
+    public IReadOnlyList<string> WatchDirectories { get; init; } = Array.Empty<string>();
+
+    /// <summary>
+    /// FileEventBackend.Pollingでディレクトリを列挙する間隔（ミリ秒）
+    /// </summary>
+    public int PollIntervalMs { get; init; } = 2000;
+
+    /// <summary>
+    /// タグのメタデータ（ゲームIDやランチャーのセッションなど任意のキーと値。記録したイベントのTagMetadataにそのまま付与）
+    /// </summary>
+    public IReadOnlyDictionary<string, string> Metadata { get; init; } = new Dictionary<string, string>();
+
+    /// <summary>
+    /// タグで記録する1秒あたりの最大イベント数（0: 無制限。超過分は間引き、削除・リネームは常に記録）
+    /// </summary>
+    public int MaxEventsPerSecond { get; init; }
+
+    /// <summary>
+    /// 同じファイルへの連続した書き込みを1件にまとめるウィンドウ（ミリ秒、0: 集約しない）
+    /// </summary>
+    public int WriteCoalescingWindowMs { get; init; }
+
+    /// <summary>
+    /// 監視対象プロセスのリソース使用量を記録する間隔（ミリ秒、0: 記録しない）
+    /// </summary>
+    public int ResourceSnapshotIntervalMs { get; init; }
+
+    /// <summary>
+    /// 監視対象プロセスの終了後、同じ実行ファイルから起動したプロセスを同じタグで監視し直す期間（ミリ秒、0: 監視し直さない）
+    /// </summary>
+    public int RewatchWindowMs { get; init; }
+
+    /// <summary>
+    /// イベントが記録されない状態がこの時間続いた場合にタグの無操作イベントを記録する（ミリ秒、0: 記録しない）
+    /// </summary>
+    public int IdleTimeoutMs { get; init; }
+
+    /// <summary>
+    /// 監視対象プロセスのウィンドウが応答しなくなった・応答が戻ったことを記録するかどうか（Windowsのみ）
+    /// </summary>
+    public bool DetectHangs { get; init; }
+
+    /// <summary>
+    /// タグのイベントバッファが満杯の場合の動作
+    /// </summary>
+    public BackpressurePolicy BackpressurePolicy { get; init; } = BackpressurePolicy.DropOldest;
+
+    /// <summary>
+    /// BackpressurePolicy.Blockで空きを待つ最大時間（ミリ秒、経過後は新しいイベントを破棄）
+    /// </summary>
+    public int BlockTimeoutMs { get; init; } = 1000;
+}
+
+/// <summary>
+/// イベントからアラートを生成するルール
+/// </summary>
+/// <remarks>
+/// 条件は全て満たす必要がある（未指定の条件は全てのイベントに一致）。
+/// 一致したイベントがWindowSeconds以内にThreshold件に達した時点でアラートを1件生成し、件数の数え直しを始める。
+/// </remarks>
+public record AlertRule
+{
+    /// <summary>
+    /// ルール名（アラートに記録）
+    /// </summary>
+    public string Name { get; init; } = string.Empty;
+
+    /// <summary>
+    /// 対象のタグ名（nullの場合は全てのタグ）
+    /// </summary>
+    public string? TagName { get; init; }
+
+    /// <summary>
+    /// 対象のイベント名（"FileIO/Write" などの完全一致、または "FileIO/*" のようなカテゴリ指定。空の場合は全て）
+    /// </summary>
+    public IReadOnlyList<string> EventNames { get; init; } = Array.Empty<string>();
+
+    /// <summary>
+    /// 対象のパス（globまたは "regex:" で始まる正規表現。ファイル・レジストリ・モジュールロードのイベントのパスと照合し、nullの場合は全て）
+    /// </summary>
+    public string? PathPattern { get; init; }
+
+    /// <summary>
+    /// アラートを生成する一致件数（1の場合は一致したイベントごとに生成）
+    /// </summary>
+    public int Threshold { get; init; } = 1;
+
+    /// <summary>
+    /// Thresholdを数える期間（秒）
+    /// </summary>
+    public int WindowSeconds { get; init; } = 60;
+
+    /// <summary>
+    /// 通知に表示するメッセージ（"セーブデータが変更されました" など。nullの場合はルール名）
+    /// </summary>
+    public string? Message { get; init; }
+}
+
+/// <summary>
+/// クライアントのトークンに許可する操作
+/// </summary>
+/// <remarks>
+/// 上位の権限は下位の権限の操作を全て含む。
+/// </remarks>
+public enum ClientPermission
+{
+    /// <summary>
+    /// イベント・アラート・監視対象・状態の取得
+    /// </summary>
+    Read,
+
+    /// <summary>
+    /// Readに加えて、監視対象の追加・削除とイベントのクリア
+    /// </summary>
+    Watch,
+
+    /// <summary>
+    /// Watchに加えて、サービスの停止
+    /// </summary>
+    Admin
+}
+
+/// <summary>
+/// クライアントの認証に使うトークン
+/// </summary>
+/// <remarks>
+/// トークンを1つ以上設定すると、Named Pipe・gRPC・REST APIの要求は一致するトークンを指定した場合のみ受け付ける。
+/// </remarks>
+public record ClientToken
+{
+    /// <summary>
+    /// トークンの名前（ログに記録）
+    /// </summary>
+    public string Name { get; init; } = string.Empty;
+
+    /// <summary>
+    /// クライアントが要求に指定するトークン
+    /// </summary>
+    public string Token { get; init; } = string.Empty;
+
+    /// <summary>
+    /// 許可する操作
+    /// </summary>
+    public ClientPermission Permission { get; init; } = ClientPermission.Read;
+
+    /// <summary>
+    /// 操作できるタグ名（globまたは "regex:" で始まる正規表現。空の場合は全てのタグ）
+    /// </summary>
+    public IReadOnlyList<string> Tags { get; init; } = Array.Empty<string>();
+}
+
+/// <summary>
+/// タグのファイルイベントの取得元
+/// </summary>
+public enum FileEventBackend
+{
+    /// <summary>
+    /// ETW（Linux/macOSではeBPF/Endpoint Security）のファイルイベント
+    /// </summary>
+    Etw,
+
+    /// <summary>
+    /// ディレクトリの変更通知（WindowsではReadDirectoryChangesW。ネットワークドライブにも使えるが、変更したプロセスは記録されない）
+    /// </summary>
+    DirectoryWatcher,
+
+    /// <summary>
+    /// ディレクトリの定期的な列挙（変更通知を持たないWSLのマウントやFUSEなど向け。リネームは削除と作成として記録）
+    /// </summary>
+    Polling
+}
+
+/// <summary>
+/// イベントバッファが満杯の場合の動作
+/// </summary>
+public enum BackpressurePolicy
+{
+    /// <summary>
+    /// 最も古いイベントを破棄して新しいイベントを記録
+    /// </summary>
+    DropOldest,
+
+    /// <summary>
+    /// 新しいイベントを破棄
+    /// </summary>
+    DropNewest,
+
+    /// <summary>
+    /// 空きができるまで待機（タイムアウト後は新しいイベントを破棄）
+    /// </summary>
+    /// <remarks>
+    /// 空きはクライアントがイベントをクリアするか、24時間を過ぎたイベントが定期クリーンアップで削除された場合にのみできる。
+    /// クリアするクライアントがいない場合はDropNewestと同じく新しいイベントを破棄し、待機した分だけ記録が遅れる。
+    /// </remarks>
+    Block
+}
+
 /// <summary>
 /// ヘルス状態
 /// </summary>
@@ -201,5 +520,30 @@ public record StorageStatistics(
     int TotalTags,
     int TotalEvents,
     IReadOnlyDictionary<string, int> EventCountByTag,
-    long EstimatedMemoryUsage
+    long EstimatedMemoryUsage,
+    IReadOnlyDictionary<string, TagBufferStatistics>? BufferStatisticsByTag = null
+);
+
+/// <summary>
+/// タグのイベントバッファの状態
+/// </summary>
+public record TagBufferStatistics(
+    BackpressurePolicy Policy,
+    long DroppedCount
+);
+
+/// <summary>
+/// タグのフィルタにより記録しなかったイベント数
+/// </summary>
+/// <param name="ExcludedProcessCount">除外したプロセスのイベント数</param>
+/// <param name="PathFilteredCount">パスフィルタに一致しなかったファイルイベント数</param>
+/// <param name="RateLimitedCount">レート制限で間引いたイベント数</param>
+/// <param name="OutsideRootCount">記録するディレクトリ（RecordRoots）外のファイルイベント数</param>
+/// <param name="OutsideRootBytesWritten">記録するディレクトリ外に書き込まれたバイト数</param>
+public record TagFilterStatistics(
+    long ExcludedProcessCount,
+    long PathFilteredCount,
+    long RateLimitedCount,
+    long OutsideRootCount = 0,
+    long OutsideRootBytesWritten = 0
 );
\ No newline at end of file
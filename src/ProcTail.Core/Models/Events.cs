@@ -9,6 +9,17 @@ namespace ProcTail.Core.Models;
 [JsonDerivedType(typeof(FileEventData), typeDiscriminator: "file")]
 [JsonDerivedType(typeof(ProcessStartEventData), typeDiscriminator: "process_start")]
 [JsonDerivedType(typeof(ProcessEndEventData), typeDiscriminator: "process_end")]
+[JsonDerivedType(typeof(RegistryEventData), typeDiscriminator: "registry")]
+[JsonDerivedType(typeof(NetworkEventData), typeDiscriminator: "network")]
+[JsonDerivedType(typeof(DnsQueryEventData), typeDiscriminator: "dns")]
+[JsonDerivedType(typeof(ImageLoadEventData), typeDiscriminator: "image")]
+[JsonDerivedType(typeof(ThreadEventData), typeDiscriminator: "thread")]
+[JsonDerivedType(typeof(ResourceSnapshotEventData), typeDiscriminator: "resource")]
+[JsonDerivedType(typeof(CrashDetectedEventData), typeDiscriminator: "crash")]
+[JsonDerivedType(typeof(TagActivityEventData), typeDiscriminator: "activity")]
+[JsonDerivedType(typeof(ForegroundEventData), typeDiscriminator: "foreground")]
+[JsonDerivedType(typeof(HangEventData), typeDiscriminator: "hang")]
+[JsonDerivedType(typeof(UpdateDetectedEventData), typeDiscriminator: "update")]
 [JsonDerivedType(typeof(GenericEventData), typeDiscriminator: "generic")]
 public abstract record BaseEventData
 {
@@ -17,11 +28,34 @@ public abstract record BaseEventData
     /// </summary>
     public required DateTime Timestamp { get; init; }
 
+    /// <summary>
+    /// 単調増加クロックによるイベント発生時刻（ETWではQPC値、取得できない場合はnull）
+    /// </summary>
+    /// <remarks>
+    /// 時刻への換算には <see cref="ClockMapping"/> を使用する
+    /// </remarks>
+    public long? MonotonicTimestamp { get; init; }
+
+    /// <summary>
+    /// 管理者権限なしの制限モードで取得したイベントかどうか（PIDやスレッドIDなど一部の情報が欠ける）
+    /// </summary>
+    public bool IsDegraded { get; init; }
+
     /// <summary>
     /// 監視対象のタグ名
     /// </summary>
     public required string TagName { get; init; }
 
+    /// <summary>
+    /// 記録した時点のタグのメタデータ（未指定の場合はnull）
+    /// </summary>
+    public IReadOnlyDictionary<string, string>? TagMetadata { get; init; }
+
+    /// <summary>
+    /// タグごとの記録順の連番（1から始まり、破棄されたイベントの番号は欠番になる。未採番の場合は0）
+    /// </summary>
+    public long SequenceNumber { get; init; }
+
     /// <summary>
     /// イベントを発生させたプロセスID
     /// </summary>
@@ -67,6 +101,36 @@ public record FileEventData : BaseEventData
     /// 操作対象のファイルパス
     /// </summary>
     public required string FilePath { get; init; }
+
+    /// <summary>
+    /// 再解析ポイント（シンボリックリンク・ジャンクション・マウントポイント）を解決する前の、イベントに記録されていたパス（解決していない場合はnull）
+    /// </summary>
+    public string? RawFilePath { get; init; }
+
+    /// <summary>
+    /// 同じハンドルのオープンからクローズまでのイベントに共通の相関ID（オープンを観測していない場合はnull）
+    /// </summary>
+    public Guid? CorrelationId { get; init; }
+
+    /// <summary>
+    /// 集約した書き込みイベントの件数（集約していない場合はnull）
+    /// </summary>
+    public int? CoalescedCount { get; init; }
+
+    /// <summary>
+    /// 集約した書き込みの合計バイト数（集約していない場合、またはサイズが不明な場合はnull）
+    /// </summary>
+    public long? TotalBytes { get; init; }
+
+    /// <summary>
+    /// 集約した最初の書き込みのタイムスタンプ（Timestampは最後の書き込み。集約していない場合はnull）
+    /// </summary>
+    public DateTime? FirstTimestamp { get; init; }
+
+    /// <summary>
+    /// 書き込んだハンドルのクローズ時点のファイル内容のSHA-256（小文字16進数。タグで対象外のパス、または読み取れなかった場合はnull）
+    /// </summary>
+    public string? ContentSha256 { get; init; }
 }
 
 /// <summary>
@@ -83,6 +147,46 @@ public record ProcessStartEventData : BaseEventData
     /// 開始された子プロセス名
     /// </summary>
     public required string ChildProcessName { get; init; }
+
+    /// <summary>
+    /// 子プロセスの親プロセスID
+    /// </summary>
+    public int ParentProcessId { get; init; }
+
+    /// <summary>
+    /// 子プロセスのコマンドライン（バックエンドが提供しない場合は空文字）
+    /// </summary>
+    public string CommandLine { get; init; } = string.Empty;
+
+    /// <summary>
+    /// 子プロセスのカレントディレクトリ（バックエンドが提供しない場合は空文字）
+    /// </summary>
+    public string CurrentDirectory { get; init; } = string.Empty;
+
+    /// <summary>
+    /// タグで指定された環境変数のスナップショット（指定がない場合はnull）
+    /// </summary>
+    public IReadOnlyDictionary<string, string>? Environment { get; init; }
+
+    /// <summary>
+    /// 子プロセスの実行ファイルのSHA-256（小文字16進数。取得できなかった場合はnull）
+    /// </summary>
+    public string? ImageSha256 { get; init; }
+
+    /// <summary>
+    /// 子プロセスのユーザーSID（Linux/macOSではUID。取得できなかった場合はnull）
+    /// </summary>
+    public string? UserSid { get; init; }
+
+    /// <summary>
+    /// 子プロセスのセッションID（取得できなかった場合はnull）
+    /// </summary>
+    public int? SessionId { get; init; }
+
+    /// <summary>
+    /// 子プロセスの整合性レベル（Windowsのみ。取得できなかった場合はnull）
+    /// </summary>
+    public string? IntegrityLevel { get; init; }
 }
 
 /// <summary>
@@ -94,6 +198,413 @@ public record ProcessEndEventData : BaseEventData
     /// プロセスの終了コード
     /// </summary>
     public required int ExitCode { get; init; }
+
+    /// <summary>
+    /// 終了の種別（正常終了・強制終了・クラッシュ）
+    /// </summary>
+    public ProcessExitReason ExitReason { get; init; } = ProcessExitReason.Normal;
+
+    /// <summary>
+    /// 終了したプロセスのユーザーSID（Linux/macOSではUID。取得できなかった場合はnull）
+    /// </summary>
+    public string? UserSid { get; init; }
+
+    /// <summary>
+    /// 終了したプロセスのセッションID（取得できなかった場合はnull）
+    /// </summary>
+    public int? SessionId { get; init; }
+
+    /// <summary>
+    /// 終了したプロセスの整合性レベル（Windowsのみ。取得できなかった場合はnull）
+    /// </summary>
+    public string? IntegrityLevel { get; init; }
+}
+
+/// <summary>
+/// プロセスの終了の種別
+/// </summary>
+[JsonConverter(typeof(JsonStringEnumConverter))]
+public enum ProcessExitReason
+{
+    /// <summary>
+    /// プロセス自身による終了（終了コードが0以外の場合も含む）
+    /// </summary>
+    Normal,
+
+    /// <summary>
+    /// 外部からの強制終了（Linux/macOSではクラッシュ以外のシグナル、WindowsではCtrl+Cやデバッガーによる終了）
+    /// </summary>
+    Killed,
+
+    /// <summary>
+    /// クラッシュ（Windowsエラー報告の起動、例外のNTSTATUSによる終了、またはSIGSEGVなどのシグナル）
+    /// </summary>
+    Crashed
+}
+
+/// <summary>
+/// レジストリ操作イベント
+/// </summary>
+public record RegistryEventData : BaseEventData
+{
+    /// <summary>
+    /// 操作対象のキー名
+    /// </summary>
+    public required string KeyName { get; init; }
+
+    /// <summary>
+    /// 操作対象の値名（キー自体の操作では空文字）
+    /// </summary>
+    public required string ValueName { get; init; }
+
+    /// <summary>
+    /// 操作種別（Create, Open, Delete, SetValue, DeleteValue）
+    /// </summary>
+    public required string Operation { get; init; }
+}
+
+/// <summary>
+/// ネットワーク通信イベント
+/// </summary>
+public record NetworkEventData : BaseEventData
+{
+    /// <summary>
+    /// プロトコル（TCP, UDP）
+    /// </summary>
+    public required string Protocol { get; init; }
+
+    /// <summary>
+    /// 操作種別（Connect, Accept, Send, Receive）
+    /// </summary>
+    public required string Operation { get; init; }
+
+    /// <summary>
+    /// ローカルアドレス
+    /// </summary>
+    public required string LocalAddress { get; init; }
+
+    /// <summary>
+    /// ローカルポート
+    /// </summary>
+    public required int LocalPort { get; init; }
+
+    /// <summary>
+    /// リモートアドレス
+    /// </summary>
+    public required string RemoteAddress { get; init; }
+
+    /// <summary>
+    /// リモートポート
+    /// </summary>
+    public required int RemotePort { get; init; }
+
+    /// <summary>
+    /// 送受信バイト数
+    /// </summary>
+    public required long Bytes { get; init; }
+
+    /// <summary>
+    /// リモートアドレスのホスト名（監視対象のDNSクエリで解決済みの場合）
+    /// </summary>
+    public string? RemoteHostName { get; init; }
+}
+
+/// <summary>
+/// モジュール（EXE/DLL）のロード・アンロードイベント
+/// </summary>
+public record ImageLoadEventData : BaseEventData
+{
+    /// <summary>
+    /// 操作種別（Load, Unload）
+    /// </summary>
+    public required string Operation { get; init; }
+
+    /// <summary>
+    /// イメージのファイルパス
+    /// </summary>
+    public required string ImagePath { get; init; }
+
+    /// <summary>
+    /// ロードされたベースアドレス
+    /// </summary>
+    public required ulong BaseAddress { get; init; }
+
+    /// <summary>
+    /// イメージサイズ（バイト）
+    /// </summary>
+    public required long ImageSize { get; init; }
+
+    /// <summary>
+    /// 署名済みかどうか（署名レベルが取得できない場合はnull）
+    /// </summary>
+    public bool? IsSigned { get; init; }
+}
+
+/// <summary>
+/// スレッドの開始・終了イベント
+/// </summary>
+public record ThreadEventData : BaseEventData
+{
+    /// <summary>
+    /// 操作種別（Start, End）
+    /// </summary>
+    public required string Operation { get; init; }
+
+    /// <summary>
+    /// スレッドの開始アドレス
+    /// </summary>
+    public required ulong StartAddress { get; init; }
+}
+
+/// <summary>
+/// DNSクエリイベント
+/// </summary>
+public record DnsQueryEventData : BaseEventData
+{
+    /// <summary>
+    /// クエリしたホスト名
+    /// </summary>
+    public required string QueryName { get; init; }
+
+    /// <summary>
+    /// レコード種別（1: A, 28: AAAA など）
+    /// </summary>
+    public required int QueryType { get; init; }
+
+    /// <summary>
+    /// クエリ結果のステータス（0: 成功）
+    /// </summary>
+    public required int Status { get; init; }
+
+    /// <summary>
+    /// 解決されたIPアドレス
+    /// </summary>
+    public required IReadOnlyList<string> Addresses { get; init; }
+}
+
+/// <summary>
+/// プロセスのリソース使用量の定期スナップショット
+/// </summary>
+/// <remarks>
+/// CPU時間とI/Oカウンタはプロセス開始からの累計値のため、利用側で前回のスナップショットとの差分を取る。
+/// </remarks>
+public record ResourceSnapshotEventData : BaseEventData
+{
+    /// <summary>
+    /// 累計CPU時間（ユーザー＋カーネル、ミリ秒）
+    /// </summary>
+    public required long CpuTimeMs { get; init; }
+
+    /// <summary>
+    /// 累計ユーザーモードCPU時間（ミリ秒）
+    /// </summary>
+    public required long UserCpuTimeMs { get; init; }
+
+    /// <summary>
+    /// ワーキングセット（バイト）
+    /// </summary>
+    public required long WorkingSetBytes { get; init; }
+
+    /// <summary>
+    /// ハンドル数（Linux/macOSではファイルディスクリプタ数）
+    /// </summary>
+    public required int HandleCount { get; init; }
+
+    /// <summary>
+    /// 累計読み取り操作数（取得できない場合はnull）
+    /// </summary>
+    public long? ReadOperations { get; init; }
+
+    /// <summary>
+    /// 累計書き込み操作数（取得できない場合はnull）
+    /// </summary>
+    public long? WriteOperations { get; init; }
+
+    /// <summary>
+    /// 累計読み取りバイト数（取得できない場合はnull）
+    /// </summary>
+    public long? ReadBytes { get; init; }
+
+    /// <summary>
+    /// 累計書き込みバイト数（取得できない場合はnull）
+    /// </summary>
+    public long? WriteBytes { get; init; }
+}
+
+/// <summary>
+/// Windowsエラー報告が検出したプロセスのクラッシュ
+/// </summary>
+public record CrashDetectedEventData : BaseEventData
+{
+    /// <summary>
+    /// クラッシュしたプロセスの実行ファイル名
+    /// </summary>
+    public required string ApplicationName { get; init; }
+
+    /// <summary>
+    /// クラッシュしたプロセスの実行ファイルのパス（記録されていない場合はnull）
+    /// </summary>
+    public string? ApplicationPath { get; init; }
+
+    /// <summary>
+    /// 例外が発生したモジュール名
+    /// </summary>
+    public required string FaultingModule { get; init; }
+
+    /// <summary>
+    /// 例外が発生したモジュールのパス（記録されていない場合はnull）
+    /// </summary>
+    public string? FaultingModulePath { get; init; }
+
+    /// <summary>
+    /// 例外コード（0xC0000005: アクセス違反 など）
+    /// </summary>
+    public required uint ExceptionCode { get; init; }
+
+    /// <summary>
+    /// モジュール内の例外発生位置のオフセット（記録されていない場合はnull）
+    /// </summary>
+    public ulong? FaultOffset { get; init; }
+
+    /// <summary>
+    /// Windowsエラー報告のレポートID（記録されていない場合はnull）
+    /// </summary>
+    public string? ReportId { get; init; }
+
+    /// <summary>
+    /// 作成されたクラッシュダンプのパス（LocalDumpsが無効な場合など、見つからない場合はnull）
+    /// </summary>
+    public string? DumpPath { get; init; }
+}
+
+/// <summary>
+/// プロセスがフォアグラウンドウィンドウを得た・失ったイベント
+/// </summary>
+public record ForegroundEventData : BaseEventData
+{
+    /// <summary>
+    /// フォアグラウンドになった場合true、フォアグラウンドでなくなった場合false
+    /// </summary>
+    public required bool IsForeground { get; init; }
+
+    /// <summary>
+    /// フォアグラウンドになったウィンドウのタイトル（フォアグラウンドでなくなった場合はnull）
+    /// </summary>
+    public string? WindowTitle { get; init; }
+
+    /// <summary>
+    /// フォアグラウンドだった時間（ミリ秒、フォアグラウンドでなくなった場合のみ）
+    /// </summary>
+    public long? ForegroundDurationMs { get; init; }
+}
+
+/// <summary>
+/// プロセスのウィンドウが応答しなくなった・応答が戻ったイベント
+/// </summary>
+public record HangEventData : BaseEventData
+{
+    /// <summary>
+    /// 応答しなくなった場合true、応答が戻った場合false
+    /// </summary>
+    public required bool IsHung { get; init; }
+
+    /// <summary>
+    /// 応答しなくなったウィンドウのタイトル
+    /// </summary>
+    public required string WindowTitle { get; init; }
+
+    /// <summary>
+    /// 応答しなかった時間（ミリ秒、応答が戻った場合のみ）
+    /// </summary>
+    public long? HangDurationMs { get; init; }
+}
+
+/// <summary>
+/// インストーラー・アップデーターの実行や実行ファイルの更新を検出したイベント
+/// </summary>
+/// <remarks>
+/// ランチャーなどが更新後の実行ファイルのメタデータを取り直すきっかけに使う。
+/// 検出のもとになったイベント（子プロセスの起動やファイルのクローズ）の直後に記録する。
+/// </remarks>
+public record UpdateDetectedEventData : BaseEventData
+{
+    /// <summary>
+    /// 検出のきっかけ
+    /// </summary>
+    public required UpdateTrigger Trigger { get; init; }
+
+    /// <summary>
+    /// インストーラーの実行ファイル（子プロセスの起動イベントと同じ名前またはパス）、または書き込まれた実行ファイルのパス
+    /// </summary>
+    public required string ExecutablePath { get; init; }
+
+    /// <summary>
+    /// 実行ファイルのSHA-256（取得できない場合はnull）
+    /// </summary>
+    public string? ExecutableSha256 { get; init; }
+
+    /// <summary>
+    /// インストーラーのプロセスID（InstallerStartedのみ）
+    /// </summary>
+    public int? InstallerProcessId { get; init; }
+}
+
+/// <summary>
+/// 更新を検出したきっかけ
+/// </summary>
+[JsonConverter(typeof(JsonStringEnumConverter))]
+public enum UpdateTrigger
+{
+    /// <summary>
+    /// インストーラー・アップデーター（msiexec.exe、*setup*.exe など）が子プロセスとして起動した
+    /// </summary>
+    InstallerStarted,
+
+    /// <summary>
+    /// Program Files配下の実行ファイルへの書き込みが完了した
+    /// </summary>
+    ExecutableWritten
+}
+
+/// <summary>
+/// タグの無操作状態への移行・無操作状態からの復帰
+/// </summary>
+/// <remarks>
+/// 無操作だった期間は LastActivityAt から復帰（Active）イベントの Timestamp まで。
+/// </remarks>
+public record TagActivityEventData : BaseEventData
+{
+    /// <summary>
+    /// 移行後の状態
+    /// </summary>
+    public required TagActivityState State { get; init; }
+
+    /// <summary>
+    /// 無操作状態になる前に最後にイベントを記録した時刻（UTC）
+    /// </summary>
+    public required DateTime LastActivityAt { get; init; }
+
+    /// <summary>
+    /// 無操作だった時間（ミリ秒、復帰イベントのみ）
+    /// </summary>
+    public long? IdleDurationMs { get; init; }
+}
+
+/// <summary>
+/// タグの活動状態
+/// </summary>
+[JsonConverter(typeof(JsonStringEnumConverter))]
+public enum TagActivityState
+{
+    /// <summary>
+    /// 無操作時間を超えてイベントがない
+    /// </summary>
+    Idle,
+
+    /// <summary>
+    /// 無操作状態からイベントの記録が再開した
+    /// </summary>
+    Active
 }
 
 /// <summary>
@@ -104,6 +615,10 @@ public record GenericEventData : BaseEventData;
 /// <summary>
 /// ETWから受信した生イベントデータ
 /// </summary>
+/// <remarks>
+/// TagNameはPIDからタグを特定できないイベント（制限モードのディレクトリ監視など）でバックエンドが決めたタグ。
+/// IsDegradedは管理者権限なしの制限モードで取得したイベントであることを示す。
+/// </remarks>
 public record RawEventData(
     DateTime Timestamp,
     string ProviderName,
@@ -112,9 +627,129 @@ public record RawEventData(
     int ThreadId,
     Guid ActivityId,
     Guid RelatedActivityId,
+    IReadOnlyDictionary<string, object> Payload,
+    long? MonotonicTimestamp = null,
+    string? TagName = null,
+    bool IsDegraded = false
+);
+
+/// <summary>
+/// アラートルールに一致したイベントから生成したアラート
+/// </summary>
+/// <param name="SequenceNumber">サービス全体の連番（1から始まる）</param>
+/// <param name="RuleName">一致したルール名</param>
+/// <param name="TagName">イベントのタグ名</param>
+/// <param name="Timestamp">アラートを生成したイベントの発生時刻</param>
+/// <param name="MatchCount">ルールの期間内に一致したイベント数</param>
+/// <param name="Event">アラートを生成したイベント（期間内で最後に一致したもの）</param>
+/// <param name="Message">ルールに設定した通知メッセージ</param>
+public record Alert(
+    long SequenceNumber,
+    string RuleName,
+    string TagName,
+    DateTime Timestamp,
+    int MatchCount,
+    BaseEventData Event,
+    string? Message = null
+);
+
+/// <summary>
+/// ドメインイベントに変換していないETWイベントレコード（生イベントのパススルー用）
+/// </summary>
+/// <param name="SequenceNumber">タグ内の連番（パススルーのバッファに追加した時点で採番）</param>
+/// <param name="Timestamp">イベント発生時刻</param>
+/// <param name="MonotonicTimestamp">単調増加クロックの値（QPC値）</param>
+/// <param name="ProviderGuid">プロバイダーのGUID</param>
+/// <param name="ProviderName">プロバイダー名</param>
+/// <param name="EventId">イベントID</param>
+/// <param name="Opcode">オペコード</param>
+/// <param name="OpcodeName">オペコード名</param>
+/// <param name="EventName">イベント名</param>
+/// <param name="ProcessId">プロセスID</param>
+/// <param name="ThreadId">スレッドID</param>
+/// <param name="Payload">ペイロード</param>
+public record RawEtwRecord(
+    long SequenceNumber,
+    DateTime Timestamp,
+    long? MonotonicTimestamp,
+    Guid ProviderGuid,
+    string ProviderName,
+    int EventId,
+    int Opcode,
+    string OpcodeName,
+    string EventName,
+    int ProcessId,
+    int ThreadId,
     IReadOnlyDictionary<string, object> Payload
 );
 
+/// <summary>
+/// 連番の欠落（バッファの破棄などで記録されなかったイベントの範囲）
+/// </summary>
+/// <param name="FirstMissing">欠落した最初の連番</param>
+/// <param name="LastMissing">欠落した最後の連番</param>
+public record SequenceGap(long FirstMissing, long LastMissing)
+{
+    /// <summary>
+    /// 欠落したイベント数
+    /// </summary>
+    public long Count => LastMissing - FirstMissing + 1;
+
+    /// <summary>
+    /// 記録順のイベント一覧から連番の欠落を検出
+    /// </summary>
+    /// <param name="events">記録順のイベント一覧</param>
+    /// <param name="previousSequenceNumber">前回までに受け取った最後の連番（不明な場合は0）</param>
+    /// <returns>欠落一覧</returns>
+    public static List<SequenceGap> Find(IEnumerable<BaseEventData> events, long previousSequenceNumber = 0)
+    {
+        var gaps = new List<SequenceGap>();
+        var previous = previousSequenceNumber;
+        foreach (var eventData in events)
+        {
+            if (eventData.SequenceNumber == 0)
+            {
+                continue;
+            }
+
+            if (previous > 0 && eventData.SequenceNumber > previous + 1)
+            {
+                gaps.Add(new SequenceGap(previous + 1, eventData.SequenceNumber - 1));
+            }
+
+            previous = eventData.SequenceNumber;
+        }
+
+        return gaps;
+    }
+}
+
+/// <summary>
+/// 単調増加クロックと壁時計の対応付け
+/// </summary>
+/// <param name="Frequency">単調増加クロックの周波数（1秒あたりのカウント数）</param>
+/// <param name="SyncTimestamp">同期時点の単調増加クロック値</param>
+/// <param name="SyncTimeUtc">同期時点のUTC時刻</param>
+public record ClockMapping(long Frequency, long SyncTimestamp, DateTime SyncTimeUtc)
+{
+    /// <summary>
+    /// 現在のStopwatchクロック（WindowsではQPC）と壁時計を対応付ける
+    /// </summary>
+    public static ClockMapping Capture()
+    {
+        return new ClockMapping(System.Diagnostics.Stopwatch.Frequency, System.Diagnostics.Stopwatch.GetTimestamp(), DateTime.UtcNow);
+    }
+
+    /// <summary>
+    /// 単調増加クロック値をUTC時刻に換算
+    /// </summary>
+    public DateTime ToUtc(long monotonicTimestamp)
+    {
+        var elapsedTicks = (long)((monotonicTimestamp - SyncTimestamp) * ((double)TimeSpan.TicksPerSecond / Frequency));
+        return SyncTimeUtc.AddTicks(elapsedTicks);
+    }
+}
+
 /// <summary>
 /// イベント処理結果
 /// </summary>
@@ -122,4 +757,10 @@ public record ProcessingResult(
     bool Success,
     BaseEventData? EventData = null,
     string? ErrorMessage = null
-);
\ No newline at end of file
+)
+{
+    /// <summary>
+    /// 記録するイベント（複数のタグに属するプロセスのイベントはタグごとに1件、先頭はEventData）
+    /// </summary>
+    public IReadOnlyList<BaseEventData> Events { get; init; } = EventData == null ? Array.Empty<BaseEventData>() : new[] { EventData };
+}
\ No newline at end of file
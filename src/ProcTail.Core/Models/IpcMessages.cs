@@ -8,7 +8,22 @@ public record WatchTargetInfo(
     string ProcessName,
     string ExecutablePath,
     DateTime StartTime,
-    string TagName);
+    string TagName,
+    IReadOnlyList<ProcessAncestor>? Ancestors = null,
+    IReadOnlyDictionary<string, string>? TagMetadata = null);
+
+/// <summary>
+/// 監視対象の祖先プロセス（監視追加時点の情報）
+/// </summary>
+/// <param name="ProcessId">プロセスID</param>
+/// <param name="ProcessName">プロセス名（終了済みの場合は "[Terminated]"）</param>
+/// <param name="ExecutablePath">実行ファイルパス</param>
+/// <param name="StartTime">開始時刻（取得できない場合はnull）</param>
+public record ProcessAncestor(
+    int ProcessId,
+    string ProcessName,
+    string ExecutablePath,
+    DateTime? StartTime);
 
 /// <summary>
 /// 全IPC応答の基底クラス
@@ -30,13 +45,30 @@ public abstract record BaseResponse
 /// <summary>
 /// 監視対象追加要求
 /// </summary>
-public record AddWatchTargetRequest(int ProcessId, string TagName);
+public record AddWatchTargetRequest(int ProcessId, string TagName, WatchTargetOptions? Options = null);
 
 /// <summary>
 /// 監視対象追加応答
 /// </summary>
 public record AddWatchTargetResponse : BaseResponse;
 
+// --- AddWatchTargetByPath ---
+/// <summary>
+/// ディレクトリ指定の監視対象追加要求
+/// </summary>
+public record AddWatchTargetByPathRequest(string TagName, string Directory, WatchTargetOptions? Options = null);
+
+/// <summary>
+/// ディレクトリ指定の監視対象追加応答
+/// </summary>
+public record AddWatchTargetByPathResponse : BaseResponse
+{
+    /// <summary>
+    /// 登録時点で実行中だったため追加されたプロセス数
+    /// </summary>
+    public int AddedCount { get; init; }
+}
+
 // --- RemoveWatchTarget ---
 /// <summary>
 /// 監視対象削除要求
@@ -52,19 +84,337 @@ public record RemoveWatchTargetResponse : BaseResponse;
 /// <summary>
 /// 記録イベント取得要求
 /// </summary>
-public record GetRecordedEventsRequest(string TagName);
+/// <param name="TagName">タグ名</param>
+/// <param name="Filter">イベントの絞り込み条件（nullの場合は全て）</param>
+/// <param name="Cursor">前回の応答のNextCursor（nullの場合は最も古いイベントから）</param>
+/// <param name="Limit">最大取得件数（nullの場合は全て）</param>
+public record GetRecordedEventsRequest(string TagName, EventFilter? Filter = null, string? Cursor = null, int? Limit = null);
+
+/// <summary>
+/// イベントの絞り込み条件
+/// </summary>
+/// <remarks>
+/// 条件は全て満たす必要がある（未指定の条件は全てのイベントに一致）。
+/// </remarks>
+public record EventFilter
+{
+    /// <summary>
+    /// イベントの種別（JSONの$type: "file", "process_start" など。空の場合は全て）
+    /// </summary>
+    public List<string> EventTypes { get; init; } = new();
+
+    /// <summary>
+    /// イベント名（"FileIO/Write" などの完全一致、または "FileIO/*" のようなカテゴリ指定。空の場合は全て）
+    /// </summary>
+    public List<string> EventNames { get; init; } = new();
+
+    /// <summary>
+    /// パス（globまたは "regex:" で始まる正規表現のいずれかに一致。ファイル・レジストリ・モジュールロードのイベントのパスと照合し、空の場合は全て）
+    /// </summary>
+    public List<string> PathPatterns { get; init; } = new();
+
+    /// <summary>
+    /// プロセスID（空の場合は全て）
+    /// </summary>
+    public List<int> ProcessIds { get; init; } = new();
+
+    /// <summary>
+    /// この日時以降のイベント（nullの場合は制限なし）
+    /// </summary>
+    public DateTime? Since { get; init; }
+
+    /// <summary>
+    /// この日時より前のイベント（nullの場合は制限なし）
+    /// </summary>
+    public DateTime? Until { get; init; }
+
+    /// <summary>
+    /// クエリ式（type=file.write AND path~"*.sav" AND ts>now-1h など。nullの場合は全て）
+    /// </summary>
+    public string? Query { get; init; }
+}
 
 /// <summary>
 /// 記録イベント取得応答
 /// </summary>
 public record GetRecordedEventsResponse(List<BaseEventData> Events) : BaseResponse
 {
+    /// <summary>
+    /// イベント一覧中の連番の欠落
+    /// </summary>
+    public List<SequenceGap> Gaps { get; init; } = new();
+
+    /// <summary>
+    /// 続きを取得するカーソル（次の要求のCursorに指定）
+    /// </summary>
+    public string NextCursor { get; init; } = string.Empty;
+
+    /// <summary>
+    /// Limitで打ち切ったため、続きのイベントがあるかどうか
+    /// </summary>
+    public bool HasMore { get; init; }
+
     /// <summary>
     /// デフォルトコンストラクタ（失敗時用）
     /// </summary>
     public GetRecordedEventsResponse() : this(new List<BaseEventData>()) { }
 }
 
+/// <summary>
+/// 記録イベント取得のカーソル（クライアントには不透明な文字列として渡す）
+/// </summary>
+/// <remarks>
+/// タグの連番は新しいイベントほど大きく、クリアしても戻らないため、
+/// 受け取った最後の連番より後を取得すれば、取得中にイベントが追加されても重複や取りこぼしがない。
+/// </remarks>
+/// <param name="TagName">タグ名</param>
+/// <param name="SequenceNumber">受け取った最後の連番</param>
+public record EventCursor(string TagName, long SequenceNumber)
+{
+    /// <summary>
+    /// カーソルを文字列にする（URLのクエリにそのまま使えるBase64URL）
+    /// </summary>
+    /// <returns>カーソル文字列</returns>
+    public string Encode()
+    {
+        var bytes = System.Text.Encoding.UTF8.GetBytes($"{SequenceNumber}:{TagName}");
+        return Convert.ToBase64String(bytes).TrimEnd('=').Replace('+', '-').Replace('/', '_');
+    }
+
+    /// <summary>
+    /// カーソル文字列を解析
+    /// </summary>
+    /// <param name="cursor">カーソル文字列</param>
+    /// <param name="result">カーソル</param>
+    /// <returns>解析できた場合true</returns>
+    public static bool TryDecode(string cursor, [System.Diagnostics.CodeAnalysis.NotNullWhen(true)] out EventCursor? result)
+    {
+        result = null;
+        if (string.IsNullOrEmpty(cursor))
+        {
+            return false;
+        }
+
+        var base64 = cursor.Replace('-', '+').Replace('_', '/');
+        base64 = base64.PadRight(base64.Length + (4 - base64.Length % 4) % 4, '=');
+
+        var bytes = new byte[base64.Length];
+        if (!Convert.TryFromBase64String(base64, bytes, out var length))
+        {
+            return false;
+        }
+
+        var text = System.Text.Encoding.UTF8.GetString(bytes, 0, length);
+        var separator = text.IndexOf(':');
+        if (separator <= 0 || !long.TryParse(text.AsSpan(0, separator), out var sequenceNumber) || sequenceNumber < 0)
+        {
+            return false;
+        }
+
+        result = new EventCursor(text[(separator + 1)..], sequenceNumber);
+        return true;
+    }
+}
+
+// --- GetRawEvents ---
+/// <summary>
+/// 生イベント取得要求
+/// </summary>
+/// <param name="TagName">タグ名</param>
+/// <param name="AfterSequenceNumber">この連番より後のレコードを取得</param>
+/// <param name="MaxCount">最大取得件数</param>
+public record GetRawEventsRequest(string TagName, long AfterSequenceNumber = 0, int MaxCount = 1000);
+
+/// <summary>
+/// 生イベント取得応答
+/// </summary>
+public record GetRawEventsResponse(List<RawEtwRecord> Records) : BaseResponse
+{
+    /// <summary>
+    /// バッファ内の最後の連番（取得件数の上限で打ち切った場合もバッファ全体の値）
+    /// </summary>
+    public long LastSequenceNumber { get; init; }
+
+    /// <summary>
+    /// デフォルトコンストラクタ（失敗時用）
+    /// </summary>
+    public GetRawEventsResponse() : this(new List<RawEtwRecord>()) { }
+}
+
+// --- GetAlerts ---
+/// <summary>
+/// アラート取得要求
+/// </summary>
+/// <param name="TagName">タグ名（nullの場合は全てのタグ）</param>
+/// <param name="AfterSequenceNumber">この連番より後のアラートを取得</param>
+/// <param name="MaxCount">最大取得件数</param>
+public record GetAlertsRequest(string? TagName = null, long AfterSequenceNumber = 0, int MaxCount = 1000);
+
+/// <summary>
+/// アラート取得応答
+/// </summary>
+public record GetAlertsResponse(List<Alert> Alerts) : BaseResponse
+{
+    /// <summary>
+    /// 保持しているアラートの最後の連番（取得件数の上限で打ち切った場合も全体の値）
+    /// </summary>
+    public long LastSequenceNumber { get; init; }
+
+    /// <summary>
+    /// デフォルトコンストラクタ（失敗時用）
+    /// </summary>
+    public GetAlertsResponse() : this(new List<Alert>()) { }
+}
+
+// --- GetSaveDirCandidates ---
+/// <summary>
+/// セーブデータの保存先と推定したディレクトリ
+/// </summary>
+/// <param name="Directory">ディレクトリのパス</param>
+/// <param name="Score">セーブデータの保存先らしさ（候補間の順位付け用の相対値）</param>
+/// <param name="FileCount">書き込まれたファイル数</param>
+/// <param name="RewrittenFileCount">2回以上保存されたファイル数</param>
+/// <param name="SaveCount">全ファイルの保存回数の合計（短い間隔の連続した書き込みは1回として数える）</param>
+/// <param name="MaxSaveBytes">1回の保存で書き込まれた最大バイト数</param>
+/// <param name="LastWriteAt">最後に書き込まれた時刻</param>
+/// <param name="Files">保存回数の多いファイル名（最大10件）</param>
+public record SaveDirectoryCandidate(
+    string Directory,
+    double Score,
+    int FileCount,
+    int RewrittenFileCount,
+    int SaveCount,
+    long MaxSaveBytes,
+    DateTime LastWriteAt,
+    IReadOnlyList<string> Files);
+
+/// <summary>
+/// セーブデータの保存先候補取得要求
+/// </summary>
+/// <param name="TagName">タグ名</param>
+/// <param name="MaxCount">最大取得件数</param>
+public record GetSaveDirCandidatesRequest(string TagName, int MaxCount = 10);
+
+/// <summary>
+/// セーブデータの保存先候補取得応答（保存先らしい順）
+/// </summary>
+public record GetSaveDirCandidatesResponse(List<SaveDirectoryCandidate> Candidates) : BaseResponse
+{
+    /// <summary>
+    /// デフォルトコンストラクタ（失敗時用）
+    /// </summary>
+    public GetSaveDirCandidatesResponse() : this(new List<SaveDirectoryCandidate>()) { }
+}
+
+// --- GetEventSummary ---
+/// <summary>
+/// タグの記録イベントの集計
+/// </summary>
+public record TagEventSummary
+{
+    /// <summary>
+    /// イベント数（集約された書き込みは集約前の件数で数える）
+    /// </summary>
+    public int EventCount { get; init; }
+
+    /// <summary>
+    /// 最初のイベントの時刻（イベントがない場合はnull）
+    /// </summary>
+    public DateTime? FirstEventAt { get; init; }
+
+    /// <summary>
+    /// 最後のイベントの時刻（イベントがない場合はnull）
+    /// </summary>
+    public DateTime? LastEventAt { get; init; }
+
+    /// <summary>
+    /// 最初から最後のイベントまでの時間（ミリ秒）
+    /// </summary>
+    public long SessionDurationMs { get; init; }
+
+    /// <summary>
+    /// 操作されたファイル数
+    /// </summary>
+    public int FileCount { get; init; }
+
+    /// <summary>
+    /// 書き込まれた合計バイト数
+    /// </summary>
+    public long BytesWritten { get; init; }
+
+    /// <summary>
+    /// 起動された子プロセス数
+    /// </summary>
+    public int ChildProcessCount { get; init; }
+
+    /// <summary>
+    /// 記録するディレクトリ（RecordRoots）外のため記録しなかったファイルイベント数
+    /// </summary>
+    public long OutsideRootEventCount { get; init; }
+
+    /// <summary>
+    /// 記録するディレクトリ外に書き込まれたバイト数
+    /// </summary>
+    public long OutsideRootBytesWritten { get; init; }
+
+    /// <summary>
+    /// 操作されたファイルのディレクトリごとの集計（イベント数の多い順）
+    /// </summary>
+    public List<DirectorySummary> Directories { get; init; } = new();
+
+    /// <summary>
+    /// イベント数の多いファイル（上位N件）
+    /// </summary>
+    public List<PathActivity> NoisyPaths { get; init; } = new();
+
+    /// <summary>
+    /// 起動された子プロセスの実行ファイル名ごとの数（多い順）
+    /// </summary>
+    public List<ChildProcessSummary> ChildProcesses { get; init; } = new();
+}
+
+/// <summary>
+/// ディレクトリごとのファイル操作の集計
+/// </summary>
+/// <param name="Directory">ディレクトリのパス</param>
+/// <param name="Files">操作されたファイル名</param>
+/// <param name="EventCount">イベント数</param>
+/// <param name="BytesWritten">書き込まれたバイト数</param>
+public record DirectorySummary(string Directory, List<string> Files, int EventCount, long BytesWritten);
+
+/// <summary>
+/// ファイルごとのイベント数と書き込まれたバイト数
+/// </summary>
+/// <param name="FilePath">ファイルパス</param>
+/// <param name="EventCount">イベント数</param>
+/// <param name="BytesWritten">書き込まれたバイト数</param>
+public record PathActivity(string FilePath, int EventCount, long BytesWritten);
+
+/// <summary>
+/// 実行ファイル名ごとの子プロセスの起動数
+/// </summary>
+/// <param name="ProcessName">実行ファイル名</param>
+/// <param name="Count">起動数</param>
+public record ChildProcessSummary(string ProcessName, int Count);
+
+/// <summary>
+/// イベント集計取得要求
+/// </summary>
+/// <param name="TagName">タグ名</param>
+/// <param name="TopCount">イベント数の多いファイルの取得件数</param>
+public record GetEventSummaryRequest(string TagName, int TopCount = 10);
+
+/// <summary>
+/// イベント集計取得応答
+/// </summary>
+public record GetEventSummaryResponse(TagEventSummary Summary) : BaseResponse
+{
+    /// <summary>
+    /// デフォルトコンストラクタ（失敗時用）
+    /// </summary>
+    public GetEventSummaryResponse() : this(new TagEventSummary()) { }
+}
+
 // --- ClearEvents ---
 /// <summary>
 /// イベントクリア要求
@@ -0,0 +1,126 @@
+using System.Text;
+using System.Text.Json;
+using Microsoft.AspNetCore.Builder;
+using Microsoft.AspNetCore.Http;
+using Microsoft.AspNetCore.Mvc;
+using Microsoft.AspNetCore.Routing;
+using Microsoft.Extensions.Diagnostics.HealthChecks;
+using ProcTail.Application.Services;
+using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
+using HealthStatus = Microsoft.Extensions.Diagnostics.HealthChecks.HealthStatus;
+
+namespace ProcTail.Host.Api;
+
+/// <summary>
+/// Named Pipeと同じ操作を提供するREST APIのエンドポイント
+/// </summary>
+/// <remarks>
+/// 各エンドポイントはNamed Pipeの要求を組み立てて同じ処理に渡し、応答JSONをそのまま返す（失敗した場合は400）。
+/// 要求の本文はNamed Pipeの要求と同じ項目で、名前の大文字小文字は区別しない。
+/// Authorizationヘッダーの Bearer トークンはNamed Pipeの要求のAuthTokenとして渡し、認証の失敗は401、許可されていない操作は403を返す。
+/// </remarks>
+public static class HttpApiEndpoints
+{
+    /// <summary>
+    /// REST APIのエンドポイントを登録
+    /// </summary>
+    /// <param name="endpoints">エンドポイントの登録先</param>
+    public static void MapProcTailHttpApi(this IEndpointRouteBuilder endpoints)
+    {
+        endpoints.MapPost("/watch-targets", (AddWatchTargetRequest request, IIpcRequestHandler handler, HttpRequest httpRequest, CancellationToken cancellationToken) =>
+            ForwardAsync(handler, httpRequest, new { RequestType = "AddWatchTarget", request.ProcessId, request.TagName, request.Options }, cancellationToken));
+
+        endpoints.MapPost("/watch-targets/by-path", (AddWatchTargetByPathRequest request, IIpcRequestHandler handler, HttpRequest httpRequest, CancellationToken cancellationToken) =>
+            ForwardAsync(handler, httpRequest, new { RequestType = "AddWatchTargetByPath", request.TagName, request.Directory, request.Options }, cancellationToken));
+
+        endpoints.MapGet("/watch-targets", (IIpcRequestHandler handler, HttpRequest httpRequest, CancellationToken cancellationToken) =>
+            ForwardAsync(handler, httpRequest, new { RequestType = "GetWatchTargets" }, cancellationToken));
+
+        endpoints.MapDelete("/watch-targets/{tag}", (string tag, IIpcRequestHandler handler, HttpRequest httpRequest, CancellationToken cancellationToken) =>
+            ForwardAsync(handler, httpRequest, new { RequestType = "RemoveWatchTarget", TagName = tag }, cancellationToken));
+
+        // 絞り込み条件は同じパラメータを繰り返すとそのいずれかに一致（?type=file&type=registry）
+        endpoints.MapGet("/events", (
+            string tag,
+            [FromQuery(Name = "type")] string[]? eventTypes,
+            [FromQuery(Name = "name")] string[]? eventNames,
+            [FromQuery(Name = "path")] string[]? pathPatterns,
+            [FromQuery(Name = "pid")] int[]? processIds,
+            DateTime? since,
+            DateTime? until,
+            [FromQuery(Name = "q")] string? query,
+            string? cursor,
+            int? limit,
+            IIpcRequestHandler handler,
+            HttpRequest httpRequest,
+            CancellationToken cancellationToken) =>
+        {
+            var filter = new EventFilter
+            {
+                EventTypes = eventTypes?.ToList() ?? new(),
+                EventNames = eventNames?.ToList() ?? new(),
+                PathPatterns = pathPatterns?.ToList() ?? new(),
+                ProcessIds = processIds?.ToList() ?? new(),
+                Since = since,
+                Until = until,
+                Query = query
+            };
+            return ForwardAsync(handler, httpRequest, new { RequestType = "GetRecordedEvents", TagName = tag, Filter = filter, Cursor = cursor, Limit = limit }, cancellationToken);
+        });
+
+        endpoints.MapDelete("/events", (string tag, IIpcRequestHandler handler, HttpRequest httpRequest, CancellationToken cancellationToken) =>
+            ForwardAsync(handler, httpRequest, new { RequestType = "ClearEvents", TagName = tag }, cancellationToken));
+
+        endpoints.MapGet("/events/summary", (string tag, int? top, IIpcRequestHandler handler, HttpRequest httpRequest, CancellationToken cancellationToken) =>
+            ForwardAsync(handler, httpRequest, new { RequestType = "GetEventSummary", TagName = tag, TopCount = top ?? 10 }, cancellationToken));
+
+        endpoints.MapGet("/status", (IIpcRequestHandler handler, HttpRequest httpRequest, CancellationToken cancellationToken) =>
+            ForwardAsync(handler, httpRequest, new { RequestType = "GetStatus" }, cancellationToken));
+
+        // ホストのヘルスチェックと同じ判定（Unhealthyの場合は503）
+        endpoints.MapGet("/health", async (HealthCheckService healthCheckService, CancellationToken cancellationToken) =>
+        {
+            var report = await healthCheckService.CheckHealthAsync(cancellationToken);
+            var response = new HealthCheckResponse(report.Status.ToString()) { Success = report.Status != HealthStatus.Unhealthy };
+            return Results.Content(JsonSerializer.Serialize(response), "application/json", Encoding.UTF8,
+                response.Success ? StatusCodes.Status200OK : StatusCodes.Status503ServiceUnavailable);
+        });
+    }
+
+    /// <summary>
+    /// Named Pipeの要求として処理し、応答JSONを返す
+    /// </summary>
+    internal static async Task<IResult> ForwardAsync(IIpcRequestHandler handler, HttpRequest httpRequest, object request, CancellationToken cancellationToken)
+    {
+        var requestNode = JsonSerializer.SerializeToNode(request)!.AsObject();
+        var authToken = ClientAuthorizer.ParseBearerToken(httpRequest.Headers.Authorization.ToString());
+        if (authToken != null)
+        {
+            requestNode["AuthToken"] = authToken;
+        }
+
+        var responseJson = await handler.HandleRequestAsync(requestNode.ToJsonString(), cancellationToken);
+
+        using var response = JsonDocument.Parse(responseJson);
+        var success = !response.RootElement.TryGetProperty("Success", out var successElement) || successElement.GetBoolean();
+        return Results.Content(responseJson, "application/json", Encoding.UTF8,
+            success ? StatusCodes.Status200OK : GetErrorStatusCode(response.RootElement));
+    }
+
+    /// <summary>
+    /// 失敗の応答のHTTPステータスコードを取得（認証の失敗は401、許可されていない操作は403、それ以外は400）
+    /// </summary>
+    internal static int GetErrorStatusCode(JsonElement response)
+    {
+        var errorMessage = response.TryGetProperty("ErrorMessage", out var errorElement) ? errorElement.GetString() : null;
+        if (errorMessage == ClientAuthorizer.UnauthorizedMessage)
+        {
+            return StatusCodes.Status401Unauthorized;
+        }
+
+        return errorMessage?.StartsWith(ClientAuthorizer.ForbiddenPrefix, StringComparison.Ordinal) == true
+            ? StatusCodes.Status403Forbidden
+            : StatusCodes.Status400BadRequest;
+    }
+}
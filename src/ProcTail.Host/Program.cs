@@ -1,12 +1,24 @@
 using Microsoft.Extensions.DependencyInjection;
+using Microsoft.Extensions.DependencyInjection.Extensions;
 using Microsoft.Extensions.Hosting;
 using Microsoft.Extensions.Logging;
 using ProcTail.Application.Services;
 using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
 using ProcTail.Host.Workers;
 using ProcTail.Infrastructure.Configuration;
+using ProcTail.Infrastructure.Desktop;
+using ProcTail.Infrastructure.Diagnostics;
+using ProcTail.Infrastructure.Ebpf;
+using ProcTail.Infrastructure.EndpointSecurity;
 using ProcTail.Infrastructure.Etw;
+using ProcTail.Infrastructure.Files;
 using ProcTail.Infrastructure.NamedPipes;
+using ProcTail.Infrastructure.Notifications;
+using ProcTail.Infrastructure.Processes;
+using ProcTail.Infrastructure.Storage;
+using ProcTail.Infrastructure.UserMode;
+using ProcTail.Infrastructure.Usn;
 using Serilog;
 using System.Diagnostics;
 using System.Runtime.InteropServices;
@@ -32,9 +44,11 @@ public class Program
         var logDirectory = Path.Combine(AppDomain.CurrentDomain.BaseDirectory, "Logs");
         Directory.CreateDirectory(logDirectory);
 
+        var degradedMode = false;
+
         try
         {
-            // Windows環境チェック
+            // 実行環境チェック
             Log.Information("ProcTail Host starting...");
             Log.Information("Command line args: {Args}", string.Join(" ", args));
             Log.Information("Process ID: {ProcessId}", Environment.ProcessId);
@@ -42,44 +56,65 @@ public class Program
             Log.Information("Base directory: {BaseDirectory}", AppDomain.CurrentDomain.BaseDirectory);
             Log.Information(".NET Runtime version: {RuntimeVersion}", RuntimeInformation.FrameworkDescription);
             
-            if (!RuntimeInformation.IsOSPlatform(OSPlatform.Windows))
+            if (RuntimeInformation.IsOSPlatform(OSPlatform.Linux) || RuntimeInformation.IsOSPlatform(OSPlatform.OSX))
             {
-                Log.Error("このアプリケーションはWindows環境でのみ動作します。");
-                Console.WriteLine("このアプリケーションはWindows環境でのみ動作します。");
-                Environment.Exit(1);
-                return;
-            }
+                Log.Information("{Platform} platform confirmed", RuntimeInformation.IsOSPlatform(OSPlatform.Linux) ? "Linux (eBPF backend)" : "macOS (Endpoint Security backend)");
 
-            Log.Information("Windows platform confirmed");
-
-            // 管理者権限チェック
-            if (!IsRunningAsAdministrator())
-            {
-                Log.Error("このアプリケーションは管理者権限が必要です。");
-                Console.WriteLine("このアプリケーションは管理者権限が必要です。");
-                
-                // UACプロンプトによる権限昇格を試行
-                if (args.Length == 0 || !args.Contains("--no-uac"))
+                // eBPF/Endpoint Securityの購読にはroot権限が必要（UACに相当する昇格手段はない）
+                if (!Environment.IsPrivilegedProcess)
                 {
-                    Log.Information("UACプロンプトによる権限昇格を試行します");
-                    Console.WriteLine("UACプロンプトによる権限昇格を試行します");
-                    await RequestAdministratorPrivilegesAsync(args);
-                    return;
+                    Log.Warning("root権限がないため制限モードで起動します。全てのイベントを記録するにはsudoで実行してください。");
+                    Console.WriteLine("root権限がないため制限モードで起動します。全てのイベントを記録するにはsudoで実行してください。");
+                    degradedMode = true;
                 }
                 else
                 {
-                    Log.Error("管理者権限なしでは実行できません。");
-                    Console.WriteLine("管理者権限なしでは実行できません。");
+                    Log.Information("Root privileges confirmed");
+                }
+            }
+            else
+            {
+                if (!RuntimeInformation.IsOSPlatform(OSPlatform.Windows))
+                {
+                    Log.Error("このアプリケーションはWindows、LinuxまたはmacOS環境でのみ動作します。");
+                    Console.WriteLine("このアプリケーションはWindows、LinuxまたはmacOS環境でのみ動作します。");
                     Environment.Exit(1);
                     return;
                 }
-            }
 
-            Log.Information("Administrator privileges confirmed");
+                Log.Information("Windows platform confirmed");
+
+                // 管理者権限チェック
+                if (!IsRunningAsAdministrator())
+                {
+                    Log.Warning("ETWの購読には管理者権限が必要です。");
+                    Console.WriteLine("ETWの購読には管理者権限が必要です。");
+                    
+                    // UACプロンプトによる権限昇格を試行
+                    if (args.Length == 0 || !args.Contains("--no-uac"))
+                    {
+                        Log.Information("UACプロンプトによる権限昇格を試行します");
+                        Console.WriteLine("UACプロンプトによる権限昇格を試行します");
+                        if (await RequestAdministratorPrivilegesAsync(args))
+                        {
+                            return;
+                        }
+                    }
+
+                    // 昇格できない場合は終了せず、ユーザーモードの監視で継続する
+                    Log.Warning("管理者権限なしのため制限モードで起動します（ディレクトリ変更とプロセスの開始・終了のみ記録）");
+                    Console.WriteLine("管理者権限なしのため制限モードで起動します（ディレクトリ変更とプロセスの開始・終了のみ記録）");
+                    degradedMode = true;
+                }
+                else
+                {
+                    Log.Information("Administrator privileges confirmed");
+                }
+            }
 
             // ホストビルダーを作成して実行
             Log.Information("Creating host builder...");
-            var hostBuilder = CreateHostBuilder(args);
+            var hostBuilder = CreateHostBuilder(args, degradedMode);
             Log.Information("Host builder created successfully");
             
             Log.Information("Building host...");
@@ -111,8 +146,9 @@ public class Program
     /// ホストビルダーを作成
     /// </summary>
     /// <param name="args">コマンドライン引数</param>
+    /// <param name="degradedMode">権限不足のため制限モードで起動するか</param>
     /// <returns>ホストビルダー</returns>
-    private static IHostBuilder CreateHostBuilder(string[] args)
+    private static IHostBuilder CreateHostBuilder(string[] args, bool degradedMode)
     {
         var builder = Microsoft.Extensions.Hosting.Host.CreateDefaultBuilder(args)
             .UseWindowsService(options =>
@@ -136,14 +172,14 @@ public class Program
             })
             .ConfigureServices((context, services) =>
             {
-                ConfigureServices(services, context.Configuration);
+                ConfigureServices(services, context.Configuration, degradedMode);
             });
     }
 
     /// <summary>
     /// DIサービスを設定
     /// </summary>
-    private static void ConfigureServices(IServiceCollection services, MSConfiguration.IConfiguration configuration)
+    private static void ConfigureServices(IServiceCollection services, MSConfiguration.IConfiguration configuration, bool degradedMode)
     {
         // 設定を直接提供（ConfigurationManagerをバイパス）
         services.AddSingleton<IEtwConfiguration>(provider => 
@@ -163,19 +199,117 @@ public class Program
         {
             services.AddSingleton<IEtwEventProvider, WindowsEtwEventProvider>();
             services.AddSingleton<INamedPipeServer, WindowsNamedPipeServer>();
+            services.AddSingleton<IStartupDiagnostics, WindowsStartupDiagnostics>();
+        }
+        else if (RuntimeInformation.IsOSPlatform(OSPlatform.Linux))
+        {
+            services.AddSingleton<IEtwEventProvider, LinuxEbpfEventProvider>();
+            services.AddSingleton<INamedPipeServer, UnixNamedPipeServer>();
+            services.AddSingleton<IStartupDiagnostics, UnixStartupDiagnostics>();
+        }
+        else if (RuntimeInformation.IsOSPlatform(OSPlatform.OSX))
+        {
+            services.AddSingleton<IEtwEventProvider, MacEndpointSecurityEventProvider>();
+            services.AddSingleton<INamedPipeServer, UnixNamedPipeServer>();
+            services.AddSingleton<IStartupDiagnostics, UnixStartupDiagnostics>();
         }
         else
         {
-            throw new PlatformNotSupportedException("このアプリケーションはWindows専用です。");
+            throw new PlatformNotSupportedException("このアプリケーションはWindows、LinuxまたはmacOS専用です。");
+        }
+
+        if (degradedMode)
+        {
+            // 権限不足の場合は設定したディレクトリの変更とプロセス一覧のポーリングで代替
+            services.Replace(ServiceDescriptor.Singleton<IEtwEventProvider>(provider =>
+            {
+                var watchDirectories = configuration.GetSection("ProcTail:DegradedWatchDirectories").Get<Dictionary<string, string[]>>()
+                    ?? new Dictionary<string, string[]>();
+                return new UserModeEventProvider(
+                    provider.GetRequiredService<ILogger<UserModeEventProvider>>(),
+                    provider.GetRequiredService<IProcessValidator>(),
+                    watchDirectories.ToDictionary(kvp => kvp.Key, kvp => (IReadOnlyList<string>)kvp.Value),
+                    TimeSpan.FromMilliseconds(configuration.GetValue<int>("ProcTail:DegradedProcessPollIntervalMs", 1000)));
+            }));
         }
 
+        // USNジャーナルをファイルイベントのソースにする場合は、ETWのプロバイダーと合成する
+        var fileEventSource = configuration.GetValue<string>("ProcTail:FileEventSource", "Etw")!;
+        if (!degradedMode && OperatingSystem.IsWindows() && !string.Equals(fileEventSource, "Etw", StringComparison.OrdinalIgnoreCase))
+        {
+            services.Replace(ServiceDescriptor.Singleton<IEtwEventProvider>(provider => OperatingSystem.IsWindows()
+                ? CreateUsnMergingEventProvider(provider, configuration, fileEventSource)
+                : throw new PlatformNotSupportedException("USNジャーナルはWindowsでのみ使用できます。")));
+        }
+
+        services.AddSingleton<IProcessEnvironmentReader, ProcessEnvironmentReader>();
+        services.AddSingleton<IProcessTokenReader, ProcessTokenReader>();
+        services.AddSingleton<IProcessResourceReader, ProcessResourceReader>();
+        services.AddSingleton<ICrashDumpLocator, CrashDumpLocator>();
+        services.AddSingleton<IProcessHangProbe, ProcessHangProbe>();
+        services.AddSingleton<IFileContentHasher, FileContentHasher>();
+        services.AddSingleton<IReparsePointResolver, ReparsePointResolver>();
+        services.AddSingleton<IDirectoryChangeWatcher, DirectoryChangeWatcher>();
+        services.AddSingleton<IImageHashProvider, ImageHashProvider>();
+        services.AddSingleton<IProcessValidator, ProcessValidator>();
+
         // アプリケーション層
         services.AddSingleton<IWatchTargetManager, WatchTargetManager>();
         services.AddSingleton<IEventProcessor, EventProcessor>();
+        services.AddSingleton<ResourceSnapshotSampler>();
+        services.AddSingleton<TagActivityTracker>();
+        services.AddSingleton<ProcessHangMonitor>();
+        services.AddSingleton<EventSubscriptionHub>();
+        services.AddSingleton(provider => new AlertRuleEngine(
+            provider.GetRequiredService<ILogger<AlertRuleEngine>>(),
+            configuration.GetSection("ProcTail:AlertRules").Get<List<AlertRule>>() ?? new List<AlertRule>(),
+            configuration.GetValue<int>("ProcTail:MaxAlerts", AlertRuleEngine.DefaultCapacity)));
+        services.AddSingleton(provider => new ClientAuthorizer(
+            provider.GetRequiredService<ILogger<ClientAuthorizer>>(),
+            configuration.GetSection("ProcTail:ClientTokens").Get<List<ClientToken>>() ?? new List<ClientToken>()));
+
+        // トースト通知はユーザーのデスクトップにのみ表示されるため、対話モードで実行している場合のみ有効にする
+        if (configuration.GetValue<bool>("ProcTail:ToastNotifications") && OperatingSystem.IsWindows() && Environment.UserInteractive)
+        {
+            services.AddSingleton<IAlertNotifier, WindowsToastNotifier>();
+        }
+
+        // フォアグラウンドウィンドウのフックもユーザーのデスクトップにのみ届くため、対話モードで実行している場合のみ有効にする
+        if (configuration.GetValue<bool>("ProcTail:ForegroundEvents", true) && OperatingSystem.IsWindows() && Environment.UserInteractive)
+        {
+            services.AddSingleton<IForegroundWindowMonitor, ForegroundWindowMonitor>();
+        }
         services.AddSingleton<IEventStorage>(provider => 
         {
-            var logger = provider.GetRequiredService<ILogger<EventStorage>>();
+            // Sqliteを指定した場合はイベントをデータディレクトリのデータベースに永続化、
+            // Spillを指定した場合は古いイベントをデータディレクトリに書き出してメモリ上のイベント数を抑える
+            var storageProvider = configuration.GetValue<string>("ProcTail:StorageProvider", "Memory");
+            var dataDirectory = Path.Combine(AppDomain.CurrentDomain.BaseDirectory, configuration.GetValue<string>("ProcTail:DataDirectory", "Data")!);
             var maxEvents = configuration.GetValue<int>("ProcTail:MaxEventsPerTag", 1000);
+            if (string.Equals(storageProvider, "Sqlite", StringComparison.OrdinalIgnoreCase))
+            {
+                var sqliteMaxEvents = configuration.GetValue<int>("ProcTail:SqliteMaxEventsPerTag", 0);
+                var retentionDays = configuration.GetValue<int>("ProcTail:EventRetentionDays", 7);
+                return new SqliteEventStorage(
+                    provider.GetRequiredService<ILogger<SqliteEventStorage>>(),
+                    Path.Combine(dataDirectory, "events.db"),
+                    sqliteMaxEvents,
+                    TimeSpan.FromDays(retentionDays));
+            }
+
+            if (string.Equals(storageProvider, "Spill", StringComparison.OrdinalIgnoreCase))
+            {
+                var spillMaxDiskMB = configuration.GetValue<long>("ProcTail:SpillMaxDiskMB", 1024);
+                var spillCompression = configuration.GetValue<bool>("ProcTail:SpillCompression", true);
+                return new SpillingEventStorage(
+                    provider.GetRequiredService<ILogger<SpillingEventStorage>>(),
+                    Path.Combine(dataDirectory, "spill"),
+                    maxEvents,
+                    spillMaxDiskMB * 1024 * 1024,
+                    compress: spillCompression);
+            }
+
+            var logger = provider.GetRequiredService<ILogger<EventStorage>>();
             return new EventStorage(logger, maxEvents);
         });
 
@@ -185,6 +319,18 @@ public class Program
         // ワーカーサービス
         services.AddHostedService<ProcTailWorker>();
 
+        // Named Pipeと同じ操作をgRPCでも提供する
+        if (configuration.GetValue<bool>("Grpc:Enabled"))
+        {
+            services.AddHostedService<GrpcServerWorker>();
+        }
+
+        // curlやスクリプトから使えるREST APIも提供する
+        if (configuration.GetValue<bool>("HttpApi:Enabled"))
+        {
+            services.AddHostedService<HttpApiWorker>();
+        }
+
         // ヘルスチェック
         services.AddHealthChecks()
             .AddCheck<ProcTailHealthCheck>("proctail_health");
@@ -196,6 +342,34 @@ public class Program
             .ValidateOnStart();
     }
 
+    /// <summary>
+    /// ETWとUSNジャーナルを合成したプロバイダーを作成
+    /// </summary>
+    /// <param name="provider">サービスプロバイダー</param>
+    /// <param name="configuration">設定</param>
+    /// <param name="fileEventSource">ファイルイベントのソース（Usn: USNジャーナルのみ、Merged: ETWと併用）</param>
+    [SupportedOSPlatform("windows")]
+    private static IEtwEventProvider CreateUsnMergingEventProvider(IServiceProvider provider, MSConfiguration.IConfiguration configuration, string fileEventSource)
+    {
+        var replaceEtwFileEvents = string.Equals(fileEventSource, "Usn", StringComparison.OrdinalIgnoreCase);
+        var watchDirectories = configuration.GetSection("ProcTail:UsnWatchDirectories").Get<Dictionary<string, string[]>>()
+            ?? new Dictionary<string, string[]>();
+        var dataDirectory = Path.Combine(AppDomain.CurrentDomain.BaseDirectory, configuration.GetValue<string>("ProcTail:DataDirectory", "Data")!);
+
+        var usnProvider = new UsnJournalEventProvider(
+            provider.GetRequiredService<ILogger<UsnJournalEventProvider>>(),
+            watchDirectories.ToDictionary(kvp => kvp.Key, kvp => (IReadOnlyList<string>)kvp.Value),
+            Path.Combine(dataDirectory, "usn-checkpoint.json"),
+            renameAndDeleteOnly: !replaceEtwFileEvents,
+            TimeSpan.FromMilliseconds(configuration.GetValue<int>("ProcTail:UsnPollIntervalMs", 500)));
+
+        return new UsnMergingEventProvider(
+            provider.GetRequiredService<ILogger<UsnMergingEventProvider>>(),
+            ActivatorUtilities.CreateInstance<WindowsEtwEventProvider>(provider),
+            usnProvider,
+            replaceEtwFileEvents);
+    }
+
     /// <summary>
     /// 管理者権限で実行されているかチェック
     /// </summary>
@@ -219,9 +393,9 @@ public class Program
     /// 管理者権限を要求してアプリケーションを再起動
     /// </summary>
     /// <param name="originalArgs">元のコマンドライン引数</param>
-    /// <returns>非同期タスク</returns>
+    /// <returns>管理者権限のプロセスを起動できた場合true</returns>
     [SupportedOSPlatform("windows")]
-    private static async Task RequestAdministratorPrivilegesAsync(string[] originalArgs)
+    private static async Task<bool> RequestAdministratorPrivilegesAsync(string[] originalArgs)
     {
         try
         {
@@ -246,12 +420,11 @@ public class Program
                 Console.WriteLine("管理者権限でのプロセス起動に成功しました。");
                 // 元のプロセスはそのまま終了させて、管理者権限でのプロセスに引き継ぐ
                 await Task.Delay(1000); // 少し待機してからプロセス終了
+                return true;
             }
-            else
-            {
-                Console.WriteLine("管理者権限でのプロセス起動に失敗しました。");
-                Environment.Exit(1);
-            }
+
+            Console.WriteLine("管理者権限でのプロセス起動に失敗しました。");
+            return false;
         }
         catch (Exception ex)
         {
@@ -262,8 +435,8 @@ public class Program
             {
                 Console.WriteLine("ユーザーがUACプロンプトをキャンセルしました。");
             }
-            
-            Environment.Exit(1);
+
+            return false;
         }
     }
 }
@@ -303,6 +476,67 @@ public class ProcTailOptions
     /// </summary>
     public int MaxEventsPerTag { get; set; } = 1000;
 
+    /// <summary>
+    /// イベントの保存先（Memory: メモリ上のキュー, Sqlite: データディレクトリのevents.dbに永続化,
+    /// Spill: MaxEventsPerTagを超えた古いイベントをデータディレクトリのspillに書き出す）
+    /// </summary>
+    public string StorageProvider { get; set; } = "Memory";
+
+    /// <summary>
+    /// Sqlite使用時のタグあたりの最大イベント数（0: 無制限、保持日数でのみ削除）
+    /// </summary>
+    public int SqliteMaxEventsPerTag { get; set; }
+
+    /// <summary>
+    /// Spill使用時に書き出すイベントの全タグ合計の最大サイズ（MB）
+    /// </summary>
+    public long SpillMaxDiskMB { get; set; } = 1024;
+
+    /// <summary>
+    /// Spill使用時に書き出すイベントをzstdで圧縮するか
+    /// </summary>
+    public bool SpillCompression { get; set; } = true;
+
+    /// <summary>
+    /// 制限モード（管理者権限なし）で変更を監視するディレクトリ（タグ名をキーとする。環境変数を展開する）
+    /// </summary>
+    public Dictionary<string, List<string>> DegradedWatchDirectories { get; set; } = new();
+
+    /// <summary>
+    /// 制限モードでのプロセス一覧のポーリング間隔（ミリ秒）
+    /// </summary>
+    public int DegradedProcessPollIntervalMs { get; set; } = 1000;
+
+    /// <summary>
+    /// ファイルイベントのソース（Etw: ETWのファイルI/O, Usn: USNジャーナル, Merged: ETWとUSNジャーナルのリネーム・削除を併用。Windowsのみ）
+    /// </summary>
+    public string FileEventSource { get; set; } = "Etw";
+
+    /// <summary>
+    /// USNジャーナルで変更を記録するディレクトリ（タグ名をキーとする。環境変数を展開する）
+    /// </summary>
+    public Dictionary<string, List<string>> UsnWatchDirectories { get; set; } = new();
+
+    /// <summary>
+    /// 新しいレコードがない場合のUSNジャーナルの読み取り間隔（ミリ秒）
+    /// </summary>
+    public int UsnPollIntervalMs { get; set; } = 500;
+
+    /// <summary>
+    /// 記録したイベントからアラートを生成するルール
+    /// </summary>
+    public List<AlertRule> AlertRules { get; set; } = new();
+
+    /// <summary>
+    /// 保持するアラートの最大数（超えた場合は古いものから破棄）
+    /// </summary>
+    public int MaxAlerts { get; set; } = 1000;
+
+    /// <summary>
+    /// アラートをWindowsのトースト通知で表示するか（対話モードで実行している場合のみ）
+    /// </summary>
+    public bool ToastNotifications { get; set; }
+
     /// <summary>
     /// イベント保持日数
     /// </summary>
@@ -0,0 +1,270 @@
+using System.Text.Json;
+using Google.Protobuf.WellKnownTypes;
+using Grpc.Core;
+using Microsoft.Extensions.Diagnostics.HealthChecks;
+using Microsoft.Extensions.Logging;
+using ProcTail.Application.Services;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Host.Rpc;
+
+/// <summary>
+/// Named Pipeと同じ操作をgRPCで提供するサービス
+/// </summary>
+/// <remarks>
+/// 処理はNamed Pipeの要求と同じProcTailServiceに委譲し、失敗はgRPCのステータスではなく応答のsuccess・error_messageで返す。
+/// ただし認証の失敗はUnauthenticated、トークンに許可されていない操作はPermissionDeniedのステータスで返す。
+/// </remarks>
+public class ProcTailGrpcService : ProcTailApi.ProcTailApiBase
+{
+    private readonly ILogger<ProcTailGrpcService> _logger;
+    private readonly ProcTailService _procTailService;
+    private readonly HealthCheckService _healthCheckService;
+    private readonly EventSubscriptionHub _eventSubscriptionHub;
+    private readonly ClientAuthorizer _clientAuthorizer;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public ProcTailGrpcService(
+        ILogger<ProcTailGrpcService> logger,
+        ProcTailService procTailService,
+        HealthCheckService healthCheckService,
+        EventSubscriptionHub eventSubscriptionHub,
+        ClientAuthorizer clientAuthorizer)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+        _procTailService = procTailService ?? throw new ArgumentNullException(nameof(procTailService));
+        _healthCheckService = healthCheckService ?? throw new ArgumentNullException(nameof(healthCheckService));
+        _eventSubscriptionHub = eventSubscriptionHub ?? throw new ArgumentNullException(nameof(eventSubscriptionHub));
+        _clientAuthorizer = clientAuthorizer ?? throw new ArgumentNullException(nameof(clientAuthorizer));
+    }
+
+    /// <summary>
+    /// 監視対象を追加
+    /// </summary>
+    public override async Task<AddWatchTargetResponse> AddWatchTarget(AddWatchTargetRequest request, ServerCallContext context)
+    {
+        Authorize(context.RequestHeaders, ClientPermission.Watch, nameof(AddWatchTarget), request.TagName);
+
+        try
+        {
+            // オプションはNamed Pipeの要求と同じJSON（省略時は既存のオプションを維持）
+            var options = string.IsNullOrWhiteSpace(request.OptionsJson)
+                ? null
+                : JsonSerializer.Deserialize<WatchTargetOptions>(request.OptionsJson);
+
+            var success = await _procTailService.AddWatchTargetAsync(request.ProcessId, request.TagName, options, context.CancellationToken);
+            return new AddWatchTargetResponse
+            {
+                Success = success,
+                ErrorMessage = success ? string.Empty : "Failed to add watch target"
+            };
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "gRPCの監視対象追加中にエラーが発生しました (ProcessId: {ProcessId}, Tag: {TagName})", request.ProcessId, request.TagName);
+            return new AddWatchTargetResponse { Success = false, ErrorMessage = $"AddWatchTarget error: {ex.Message}" };
+        }
+    }
+
+    /// <summary>
+    /// タグの監視対象を削除
+    /// </summary>
+    public override async Task<RemoveWatchTargetResponse> RemoveWatchTarget(RemoveWatchTargetRequest request, ServerCallContext context)
+    {
+        Authorize(context.RequestHeaders, ClientPermission.Watch, nameof(RemoveWatchTarget), request.TagName);
+
+        try
+        {
+            var removedCount = await _procTailService.RemoveWatchTargetAsync(request.TagName, context.CancellationToken);
+            return new RemoveWatchTargetResponse
+            {
+                Success = removedCount > 0,
+                ErrorMessage = removedCount > 0 ? string.Empty : $"No watch targets found for tag: {request.TagName}",
+                RemovedCount = removedCount
+            };
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "gRPCの監視対象削除中にエラーが発生しました (Tag: {TagName})", request.TagName);
+            return new RemoveWatchTargetResponse { Success = false, ErrorMessage = $"RemoveWatchTarget error: {ex.Message}" };
+        }
+    }
+
+    /// <summary>
+    /// タグの記録イベントを取得
+    /// </summary>
+    public override async Task<GetRecordedEventsResponse> GetRecordedEvents(GetRecordedEventsRequest request, ServerCallContext context)
+    {
+        Authorize(context.RequestHeaders, ClientPermission.Read, nameof(GetRecordedEvents), request.TagName);
+
+        try
+        {
+            var result = await _procTailService.QueryRecordedEventsAsync(
+                new Core.Models.GetRecordedEventsRequest(
+                    request.TagName,
+                    ToEventFilter(request.Filter),
+                    string.IsNullOrEmpty(request.Cursor) ? null : request.Cursor,
+                    request.Limit > 0 ? request.Limit : null),
+                context.CancellationToken);
+            if (!result.Success)
+            {
+                return new GetRecordedEventsResponse { Success = false, ErrorMessage = result.ErrorMessage };
+            }
+
+            var response = new GetRecordedEventsResponse
+            {
+                Success = true,
+                NextCursor = result.NextCursor,
+                HasMore = result.HasMore
+            };
+            response.Events.AddRange(result.Events.Select(ToRecordedEvent));
+            response.Gaps.AddRange(result.Gaps.Select(gap => new SequenceGap
+            {
+                FirstMissing = gap.FirstMissing,
+                LastMissing = gap.LastMissing
+            }));
+            return response;
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "gRPCのイベント取得中にエラーが発生しました (Tag: {TagName})", request.TagName);
+            return new GetRecordedEventsResponse { Success = false, ErrorMessage = $"GetRecordedEvents error: {ex.Message}" };
+        }
+    }
+
+    /// <summary>
+    /// タグのイベントを記録される都度配信
+    /// </summary>
+    public override async Task Subscribe(SubscribeRequest request, IServerStreamWriter<SubscribeResponse> responseStream, ServerCallContext context)
+    {
+        AuthorizeSubscription(context.RequestHeaders, request.TagNames);
+
+        var filter = ToEventFilter(request.Filter);
+        var filterError = filter == null ? null : EventFilterMatcher.Validate(filter);
+        if (filterError != null)
+        {
+            throw new RpcException(new Status(StatusCode.InvalidArgument, filterError));
+        }
+
+        using var subscription = _eventSubscriptionHub.Subscribe(request.TagNames.ToList(), filter);
+        _logger.LogInformation("gRPCのイベント購読を開始しました (Tags: {TagNames})", string.Join(", ", request.TagNames));
+
+        try
+        {
+            await foreach (var eventData in subscription.Reader.ReadAllAsync(context.CancellationToken))
+            {
+                await responseStream.WriteAsync(new SubscribeResponse
+                {
+                    Event = ToRecordedEvent(eventData),
+                    DroppedCount = subscription.TakeDroppedCount()
+                });
+            }
+        }
+        catch (OperationCanceledException)
+        {
+            // クライアントの切断
+        }
+
+        _logger.LogInformation("gRPCのイベント購読を終了しました (Tags: {TagNames})", string.Join(", ", request.TagNames));
+    }
+
+    /// <summary>
+    /// サービスの稼働状況を確認（ホストのヘルスチェックと同じ判定）
+    /// </summary>
+    public override async Task<HealthCheckResponse> HealthCheck(HealthCheckRequest request, ServerCallContext context)
+    {
+        var report = await _healthCheckService.CheckHealthAsync(context.CancellationToken);
+        return new HealthCheckResponse
+        {
+            Status = report.Status.ToString(),
+            Description = string.Join("; ", report.Entries.Values
+                .Select(entry => entry.Description)
+                .Where(description => !string.IsNullOrEmpty(description)))
+        };
+    }
+
+    /// <summary>
+    /// メタデータの authorization のトークンで認証し、操作が許可されていない場合は失敗のステータスを返す
+    /// </summary>
+    /// <returns>認証したクライアント（トークンが設定されていない場合はnull）</returns>
+    internal ClientToken? Authorize(Metadata requestHeaders, ClientPermission permission, string operation, string? tagName)
+    {
+        if (!_clientAuthorizer.IsEnabled)
+        {
+            return null;
+        }
+
+        var client = _clientAuthorizer.Authenticate(ClientAuthorizer.ParseBearerToken(requestHeaders.GetValue("authorization")));
+        if (client == null)
+        {
+            throw new RpcException(new Status(StatusCode.Unauthenticated, ClientAuthorizer.UnauthorizedMessage));
+        }
+
+        if (!ClientAuthorizer.IsAllowed(client, permission, tagName))
+        {
+            _logger.LogWarning("トークンに許可されていないgRPCの要求を拒否しました (Token: {Name}, Operation: {Operation}, Tag: {TagName})",
+                client.Name, operation, tagName);
+            throw new RpcException(new Status(StatusCode.PermissionDenied, ClientAuthorizer.CreateForbiddenMessage(client, operation, tagName)));
+        }
+
+        return client;
+    }
+
+    /// <summary>
+    /// 購読する全てのタグを読み取れるか確認し、許可されていない場合は失敗のステータスを返す
+    /// </summary>
+    /// <remarks>タグを限定したトークンでは、全てのタグの購読（tag_namesが空）は許可しない</remarks>
+    internal void AuthorizeSubscription(Metadata requestHeaders, IReadOnlyCollection<string> tagNames)
+    {
+        var client = Authorize(requestHeaders, ClientPermission.Read, nameof(Subscribe), null);
+        if (client != null && client.Tags.Count > 0 && tagNames.Count == 0)
+        {
+            throw new RpcException(new Status(StatusCode.PermissionDenied, ClientAuthorizer.CreateForbiddenMessage(client, nameof(Subscribe), "*")));
+        }
+        foreach (var tagName in tagNames)
+        {
+            Authorize(requestHeaders, ClientPermission.Read, nameof(Subscribe), tagName);
+        }
+    }
+
+    internal static Core.Models.EventFilter? ToEventFilter(EventFilter? filter)
+    {
+        if (filter == null)
+        {
+            return null;
+        }
+
+        return new Core.Models.EventFilter
+        {
+            EventTypes = filter.EventTypes.ToList(),
+            EventNames = filter.EventNames.ToList(),
+            PathPatterns = filter.PathPatterns.ToList(),
+            ProcessIds = filter.ProcessIds.ToList(),
+            Since = filter.Since?.ToDateTime(),
+            Until = filter.Until?.ToDateTime(),
+            Query = string.IsNullOrEmpty(filter.Query) ? null : filter.Query
+        };
+    }
+
+    internal static RecordedEvent ToRecordedEvent(BaseEventData eventData)
+    {
+        // 種別ごとの項目はNamed Pipeと同じポリモーフィックJSONで渡し、種別は$typeから取り出す
+        var json = JsonSerializer.Serialize(eventData);
+        using var document = JsonDocument.Parse(json);
+
+        return new RecordedEvent
+        {
+            Type = document.RootElement.TryGetProperty("$type", out var type) ? type.GetString() ?? string.Empty : string.Empty,
+            Timestamp = Timestamp.FromDateTime(eventData.Timestamp.ToUniversalTime()),
+            TagName = eventData.TagName,
+            SequenceNumber = eventData.SequenceNumber,
+            ProcessId = eventData.ProcessId,
+            ThreadId = eventData.ThreadId,
+            ProviderName = eventData.ProviderName,
+            EventName = eventData.EventName,
+            Json = json
+        };
+    }
+}
@@ -0,0 +1,155 @@
+using Microsoft.AspNetCore.Builder;
+using Microsoft.AspNetCore.Hosting;
+using Microsoft.AspNetCore.Server.Kestrel.Core;
+using Microsoft.Extensions.Configuration;
+using Microsoft.Extensions.DependencyInjection;
+using Microsoft.Extensions.Diagnostics.HealthChecks;
+using Microsoft.Extensions.Hosting;
+using Microsoft.Extensions.Logging;
+using ProcTail.Application.Services;
+using ProcTail.Host.Rpc;
+using ProcTail.Infrastructure.NamedPipes;
+using Serilog;
+using System.Runtime.Versioning;
+
+namespace ProcTail.Host.Workers;
+
+/// <summary>
+/// Named Pipeと同じ操作をgRPCで提供するサーバー
+/// </summary>
+/// <remarks>
+/// 設定の Grpc:Port（localhostのTCP）、Grpc:UnixSocketPath（Unixドメインソケット）、Grpc:NamedPipeName（Windowsの名前付きパイプ）のうち、
+/// 指定したものを全て待ち受ける。いずれもローカルからの接続のみで、TLSは使わない（HTTP/2の平文）。
+/// TCPで待ち受ける場合は、:authority（Host）はループバックの名前のみ許可する（Unixドメインソケットと名前付きパイプも同じ制限になる）。
+/// 名前付きパイプはNamed Pipe（JSON）と同じく現在のユーザーとAdministratorsのみ接続でき、Unixドメインソケットはファイルのパーミッションで保護される。
+/// ProcTail:ClientTokens を設定した場合は、メタデータの authorization（Bearer トークン）で認証する（TCPで待ち受けるのに設定しない場合は起動時に警告する）。
+/// gRPCサーバーを起動できない場合もNamed Pipeでの監視は続ける。
+/// </remarks>
+public class GrpcServerWorker : BackgroundService
+{
+    private readonly ILogger<GrpcServerWorker> _logger;
+    private readonly ProcTailService _procTailService;
+    private readonly HealthCheckService _healthCheckService;
+    private readonly EventSubscriptionHub _eventSubscriptionHub;
+    private readonly ClientAuthorizer _clientAuthorizer;
+    private readonly IConfiguration _configuration;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public GrpcServerWorker(
+        ILogger<GrpcServerWorker> logger,
+        ProcTailService procTailService,
+        HealthCheckService healthCheckService,
+        EventSubscriptionHub eventSubscriptionHub,
+        ClientAuthorizer clientAuthorizer,
+        IConfiguration configuration)
+    {
+        _logger = logger;
+        _procTailService = procTailService;
+        _healthCheckService = healthCheckService;
+        _eventSubscriptionHub = eventSubscriptionHub;
+        _clientAuthorizer = clientAuthorizer;
+        _configuration = configuration;
+    }
+
+    /// <summary>
+    /// ワーカー実行処理
+    /// </summary>
+    protected override async Task ExecuteAsync(CancellationToken stoppingToken)
+    {
+        var port = _configuration.GetValue<int>("Grpc:Port", 50051);
+        var unixSocketPath = _configuration.GetValue<string>("Grpc:UnixSocketPath");
+        var namedPipeName = _configuration.GetValue<string>("Grpc:NamedPipeName");
+
+        if (port <= 0 && string.IsNullOrEmpty(unixSocketPath) && string.IsNullOrEmpty(namedPipeName))
+        {
+            _logger.LogWarning("gRPCの待ち受け先が設定されていないため、gRPCサーバーを開始しません");
+            return;
+        }
+
+        try
+        {
+            var builder = WebApplication.CreateBuilder(new WebApplicationOptions { ContentRootPath = AppContext.BaseDirectory });
+            builder.Host.UseSerilog();
+
+            // 監視の状態はホストのサービスを共有する
+            builder.Services.AddSingleton(_procTailService);
+            builder.Services.AddSingleton(_healthCheckService);
+            builder.Services.AddSingleton(_eventSubscriptionHub);
+            builder.Services.AddSingleton(_clientAuthorizer);
+            builder.Services.AddGrpc();
+
+            builder.WebHost.ConfigureKestrel(options =>
+            {
+                if (port > 0)
+                {
+                    options.ListenLocalhost(port, listen => listen.Protocols = HttpProtocols.Http2);
+                }
+
+                if (!string.IsNullOrEmpty(unixSocketPath))
+                {
+                    // 前回の異常終了で残ったソケットファイルがあると待ち受けられない
+                    if (File.Exists(unixSocketPath))
+                    {
+                        File.Delete(unixSocketPath);
+                    }
+                    options.ListenUnixSocket(unixSocketPath, listen => listen.Protocols = HttpProtocols.Http2);
+                }
+
+                if (!string.IsNullOrEmpty(namedPipeName) && OperatingSystem.IsWindows())
+                {
+                    options.ListenNamedPipe(namedPipeName, listen => listen.Protocols = HttpProtocols.Http2);
+                }
+            });
+
+            if (!string.IsNullOrEmpty(namedPipeName) && OperatingSystem.IsWindows())
+            {
+                UseSecuredNamedPipes(builder.WebHost);
+            }
+
+            if (port > 0)
+            {
+                // Hostの制限は全ての待ち受け先に適用されるため、TCPで待ち受ける場合のみ行う
+                LocalhostProtection.RestrictHosts(builder);
+            }
+
+            var app = builder.Build();
+            app.MapGrpcService<ProcTailGrpcService>();
+
+            _logger.LogInformation("gRPCサーバーを開始します (Port: {Port}, UnixSocket: {UnixSocketPath}, NamedPipe: {NamedPipeName})",
+                port, unixSocketPath, namedPipeName);
+            if (port > 0)
+            {
+                // Unixドメインソケットと名前付きパイプはアクセス制御で保護されるため、TCPの場合のみ警告する
+                LocalhostProtection.WarnIfUnauthenticated(_logger, _clientAuthorizer, "gRPCサーバー");
+            }
+
+            // 停止時にWebApplicationも破棄される
+            await app.RunAsync(stoppingToken);
+        }
+        catch (OperationCanceledException)
+        {
+            _logger.LogInformation("gRPCサーバーを停止しました");
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "gRPCサーバーを開始できませんでした");
+        }
+    }
+
+    /// <summary>
+    /// 名前付きパイプのアクセス制御をNamed Pipe（JSON）と同じにする
+    /// </summary>
+    [SupportedOSPlatform("windows")]
+    private static void UseSecuredNamedPipes(IWebHostBuilder webHost)
+    {
+        var pipeSecurity = WindowsNamedPipeServer.CreatePipeSecurity();
+        webHost.UseNamedPipes(options =>
+        {
+            // 既定では待ち受けたユーザー（サービスのアカウント）しか接続できないため、PipeSecurityで許可する
+            options.CurrentUserOnly = false;
+            options.PipeSecurity = pipeSecurity;
+        });
+    }
+}
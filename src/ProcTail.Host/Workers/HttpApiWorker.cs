@@ -0,0 +1,86 @@
+using Microsoft.AspNetCore.Builder;
+using Microsoft.AspNetCore.Hosting;
+using Microsoft.Extensions.Configuration;
+using Microsoft.Extensions.DependencyInjection;
+using Microsoft.Extensions.Diagnostics.HealthChecks;
+using Microsoft.Extensions.Hosting;
+using Microsoft.Extensions.Logging;
+using ProcTail.Application.Services;
+using ProcTail.Core.Interfaces;
+using ProcTail.Host.Api;
+using Serilog;
+
+namespace ProcTail.Host.Workers;
+
+/// <summary>
+/// Named Pipeと同じ操作をREST APIで提供するHTTPサーバー
+/// </summary>
+/// <remarks>
+/// curlでの確認や、Named Pipeを扱えないスクリプトからの連携向け。
+/// 設定の HttpApi:Port でlocalhostのみを待ち受け、TLSは使わない。Hostヘッダーはループバックの名前のみ許可する。
+/// ProcTail:ClientTokens を設定した場合は、Authorizationヘッダーの Bearer トークンで認証する（設定しない場合は起動時に警告する）。
+/// HTTPサーバーを起動できない場合もNamed Pipeでの監視は続ける。
+/// </remarks>
+public class HttpApiWorker : BackgroundService
+{
+    private readonly ILogger<HttpApiWorker> _logger;
+    private readonly ProcTailService _procTailService;
+    private readonly HealthCheckService _healthCheckService;
+    private readonly ClientAuthorizer _clientAuthorizer;
+    private readonly IConfiguration _configuration;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public HttpApiWorker(
+        ILogger<HttpApiWorker> logger,
+        ProcTailService procTailService,
+        HealthCheckService healthCheckService,
+        ClientAuthorizer clientAuthorizer,
+        IConfiguration configuration)
+    {
+        _logger = logger;
+        _procTailService = procTailService;
+        _healthCheckService = healthCheckService;
+        _clientAuthorizer = clientAuthorizer;
+        _configuration = configuration;
+    }
+
+    /// <summary>
+    /// ワーカー実行処理
+    /// </summary>
+    protected override async Task ExecuteAsync(CancellationToken stoppingToken)
+    {
+        var port = _configuration.GetValue<int>("HttpApi:Port", 5080);
+
+        try
+        {
+            var builder = WebApplication.CreateBuilder(new WebApplicationOptions { ContentRootPath = AppContext.BaseDirectory });
+            builder.Host.UseSerilog();
+
+            // 要求はNamed Pipeと同じProcTailServiceで処理する
+            builder.Services.AddSingleton<IIpcRequestHandler>(_procTailService);
+            builder.Services.AddSingleton(_healthCheckService);
+
+            builder.WebHost.ConfigureKestrel(options => options.ListenLocalhost(port));
+            LocalhostProtection.RestrictHosts(builder);
+
+            var app = builder.Build();
+            app.MapProcTailHttpApi();
+
+            _logger.LogInformation("HTTP APIを開始します (Port: {Port})", port);
+            LocalhostProtection.WarnIfUnauthenticated(_logger, _clientAuthorizer, "HTTP API");
+
+            // 停止時にWebApplicationも破棄される
+            await app.RunAsync(stoppingToken);
+        }
+        catch (OperationCanceledException)
+        {
+            _logger.LogInformation("HTTP APIを停止しました");
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "HTTP APIを開始できませんでした");
+        }
+    }
+}
@@ -0,0 +1,49 @@
+using Microsoft.AspNetCore.Builder;
+using Microsoft.Extensions.Logging;
+using ProcTail.Application.Services;
+
+namespace ProcTail.Host.Workers;
+
+/// <summary>
+/// localhostのTCPで待ち受けるサーバー（REST API、gRPC）の保護
+/// </summary>
+/// <remarks>
+/// localhostのみで待ち受けても、ブラウザーで開いた外部のページからDNSリバインディングで要求される可能性があるため、
+/// Hostヘッダーがループバックの名前でない要求はステータス400で拒否する。
+/// TCPは同じマシンの全てのユーザーが接続できるため、認証トークンが設定されていない場合は起動時に警告する。
+/// </remarks>
+public static class LocalhostProtection
+{
+    /// <summary>
+    /// 許可するHostヘッダーのホスト名（ポートは問わない）
+    /// </summary>
+    public const string AllowedHosts = "localhost;127.0.0.1;[::1]";
+
+    /// <summary>
+    /// ループバックの名前以外のHostヘッダーを拒否する
+    /// </summary>
+    /// <param name="builder">Webアプリケーションのビルダー</param>
+    public static void RestrictHosts(WebApplicationBuilder builder)
+    {
+        // appsettings.json の AllowedHosts より優先する
+        builder.Configuration["AllowedHosts"] = AllowedHosts;
+    }
+
+    /// <summary>
+    /// 認証トークンが設定されていない場合に警告する
+    /// </summary>
+    /// <param name="logger">ロガー</param>
+    /// <param name="clientAuthorizer">クライアントの認証</param>
+    /// <param name="serverName">サーバー名（ログ用）</param>
+    /// <returns>警告した場合true</returns>
+    public static bool WarnIfUnauthenticated(ILogger logger, ClientAuthorizer clientAuthorizer, string serverName)
+    {
+        if (clientAuthorizer.IsEnabled)
+        {
+            return false;
+        }
+
+        logger.LogWarning("{ServerName}は認証トークン（ProcTail:ClientTokens）なしでlocalhostのTCPを待ち受けます。同じマシンの全てのユーザーが全ての操作を行えます", serverName);
+        return true;
+    }
+}
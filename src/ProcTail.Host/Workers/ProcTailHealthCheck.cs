@@ -1,6 +1,7 @@
 using Microsoft.Extensions.Diagnostics.HealthChecks;
 using Microsoft.Extensions.Logging;
 using ProcTail.Application.Services;
+using ProcTail.Core.Interfaces;
 
 namespace ProcTail.Host.Workers;
 
@@ -32,14 +33,24 @@ public class ProcTailHealthCheck : IHealthCheck
     {
         try
         {
-            if (_procTailService.IsRunning)
+            if (!_procTailService.IsRunning)
             {
-                return Task.FromResult(HealthCheckResult.Healthy("ProcTail service is running"));
+                return Task.FromResult(HealthCheckResult.Unhealthy("ProcTail service is not running"));
             }
-            else
+
+            // 起動時診断で失敗したチェックがある場合はイベントが記録されていない可能性がある
+            var failedChecks = _procTailService.Diagnostics
+                .Where(result => result.Status == DiagnosticStatus.Failed)
+                .ToList();
+            if (failedChecks.Count > 0)
             {
-                return Task.FromResult(HealthCheckResult.Unhealthy("ProcTail service is not running"));
+                var data = failedChecks.ToDictionary(result => result.Name, result => (object)result.Message);
+                return Task.FromResult(HealthCheckResult.Degraded(
+                    $"ProcTail service is running but startup diagnostics failed: {string.Join(", ", failedChecks.Select(result => result.Name))}",
+                    data: data));
             }
+
+            return Task.FromResult(HealthCheckResult.Healthy("ProcTail service is running"));
         }
         catch (Exception ex)
         {
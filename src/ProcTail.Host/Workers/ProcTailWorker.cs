@@ -60,6 +60,9 @@ public class ProcTailWorker : BackgroundService
         catch (Exception ex)
         {
             _logger.LogError(ex, "ProcTail Worker encountered an error");
+
+            // 終了コードを0以外にし、サービスの回復オプション（自動再起動）を働かせる
+            Environment.ExitCode = 1;
             throw;
         }
         finally
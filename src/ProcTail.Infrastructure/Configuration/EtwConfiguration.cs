@@ -1,6 +1,7 @@
 using Microsoft.Extensions.Configuration;
 using Microsoft.Extensions.Logging;
 using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
 
 namespace ProcTail.Infrastructure.Configuration;
 
@@ -22,6 +23,26 @@ public class EtwConfiguration : IEtwConfiguration
     /// </summary>
     public IReadOnlyList<string> EnabledEventNames { get; private set; }
 
+    /// <summary>
+    /// カーネルセッションで有効にするキーワード
+    /// </summary>
+    public IReadOnlyList<string> KernelKeywords { get; private set; } = Array.Empty<string>();
+
+    /// <summary>
+    /// ユーザーモードプロバイダーごとのレベルとキーワード
+    /// </summary>
+    public IReadOnlyDictionary<string, EtwProviderSettings> ProviderSettings { get; private set; } = new Dictionary<string, EtwProviderSettings>();
+
+    /// <summary>
+    /// 監視対象プロセスの生イベントを変換せずに配信するか
+    /// </summary>
+    public bool EnableRawPassthrough { get; private set; }
+
+    /// <summary>
+    /// 生イベントの配信用に追加で有効にするユーザーモードプロバイダー
+    /// </summary>
+    public IReadOnlyList<string> RawPassthroughProviders { get; private set; } = Array.Empty<string>();
+
     /// <summary>
     /// ETWセッションのバッファサイズ（MB）
     /// </summary>
@@ -47,6 +68,16 @@ public class EtwConfiguration : IEtwConfiguration
     /// </summary>
     public EtwPerformanceOptions PerformanceOptions { get; private set; }
 
+    /// <summary>
+    /// 全タグ合計で記録する1秒あたりの最大イベント数
+    /// </summary>
+    public int MaxEventsPerSecond => PerformanceOptions.MaxEventsPerSecond;
+
+    /// <summary>
+    /// レート上限を超えたときに残すイベントの間隔（N件に1件）
+    /// </summary>
+    public int OverflowSampleRate => PerformanceOptions.OverflowSampleRate;
+
     /// <summary>
     /// パラメーターレスコンストラクタ（デフォルト設定用）
     /// </summary>
@@ -60,7 +91,13 @@ public class EtwConfiguration : IEtwConfiguration
         EnabledProviders = new[]
         {
             "Microsoft-Windows-Kernel-FileIO",
-            "Microsoft-Windows-Kernel-Process"
+            "Microsoft-Windows-Kernel-Process",
+            "Microsoft-Windows-Kernel-Registry",
+            "Microsoft-Windows-Kernel-Network",
+            "Microsoft-Windows-DNS-Client",
+            "Microsoft-Windows-Kernel-Image",
+            "Microsoft-Windows-Kernel-Thread",
+            "Application Error"
         };
         
         EnabledEventNames = new[]
@@ -74,7 +111,24 @@ public class EtwConfiguration : IEtwConfiguration
             "FileIO/Close",
             "Process/Start",
             "Process/Stop",
-            "Process/End"
+            "Process/End",
+            "Registry/Create",
+            "Registry/Open",
+            "Registry/Delete",
+            "Registry/SetValue",
+            "Registry/DeleteValue",
+            "TcpIp/Connect",
+            "TcpIp/Accept",
+            "TcpIp/Send",
+            "TcpIp/Recv",
+            "UdpIp/Send",
+            "UdpIp/Recv",
+            "Dns/QueryCompleted",
+            "Image/Load",
+            "Image/Unload",
+            "Thread/Start",
+            "Thread/End",
+            "Crash/Detected"
         };
         
         // フィルタリングを完全に無効化
@@ -93,7 +147,8 @@ public class EtwConfiguration : IEtwConfiguration
             MaxEventQueueSize = 10000,
             EventProcessingIntervalMs = 10,
             EnableHighFrequencyEvents = false,
-            MaxEventsPerSecond = 1000
+            MaxEventsPerSecond = 1000,
+            OverflowSampleRate = 10
         };
         
         _logger = null!;
@@ -119,18 +174,28 @@ public class EtwConfiguration : IEtwConfiguration
     }
 
     /// <summary>
-    /// 設定を読み込み（ハードコード化された固定設定を使用）
+    /// 設定を読み込み
     /// </summary>
     private void LoadConfiguration()
     {
         try
         {
-            // 設定ファイルに関係なく、常に全てのイベントを監視
-            EnabledProviders = GetDefaultProviders().AsReadOnly();
-            EnabledEventNames = GetDefaultEventNames().AsReadOnly();
-
-            // バッファ設定のみ設定ファイルから読み込み（パフォーマンス調整用）
+            // プロバイダー・イベント・キーワードは設定ファイルで絞り込める（未設定の場合は全てのイベントを監視）
             var etwSection = _configuration.GetSection("ETW");
+            var configuredProviders = etwSection.GetSection("EnabledProviders").Get<string[]>();
+            var configuredEventNames = etwSection.GetSection("EnabledEventNames").Get<string[]>();
+            EnabledProviders = (configuredProviders is { Length: > 0 } ? configuredProviders : GetDefaultProviders()).AsReadOnly();
+            EnabledEventNames = (configuredEventNames is { Length: > 0 } ? configuredEventNames : GetDefaultEventNames()).AsReadOnly();
+            KernelKeywords = (etwSection.GetSection("KernelKeywords").Get<string[]>() ?? Array.Empty<string>()).AsReadOnly();
+            ProviderSettings = etwSection.GetSection("Providers").Get<Dictionary<string, EtwProviderSettings>>()
+                ?? new Dictionary<string, EtwProviderSettings>();
+
+            // 生イベントのパススルー（オプトイン）
+            var rawPassthroughSection = etwSection.GetSection("RawPassthrough");
+            EnableRawPassthrough = rawPassthroughSection.GetValue<bool>("Enabled", false);
+            RawPassthroughProviders = (rawPassthroughSection.GetSection("Providers").Get<string[]>() ?? Array.Empty<string>()).AsReadOnly();
+
+            // バッファ設定（パフォーマンス調整用）
             BufferSizeMB = etwSection.GetValue<int>("BufferSizeMB", 64);
             BufferCount = etwSection.GetValue<int>("BufferCount", 20);
             EventBufferTimeout = TimeSpan.FromMilliseconds(etwSection.GetValue<int>("EventBufferTimeoutMs", 1000));
@@ -153,11 +218,12 @@ public class EtwConfiguration : IEtwConfiguration
                 MaxEventQueueSize = performanceSection.GetValue<int>("MaxEventQueueSize", 10000),
                 EventProcessingIntervalMs = performanceSection.GetValue<int>("EventProcessingIntervalMs", 10),
                 EnableHighFrequencyEvents = performanceSection.GetValue<bool>("EnableHighFrequencyEvents", false),
-                MaxEventsPerSecond = performanceSection.GetValue<int>("MaxEventsPerSecond", 1000)
+                MaxEventsPerSecond = performanceSection.GetValue<int>("MaxEventsPerSecond", 1000),
+                OverflowSampleRate = performanceSection.GetValue<int>("OverflowSampleRate", 10)
             };
 
-            _logger.LogInformation("ETW設定をハードコード化された値で初期化しました - プロバイダー: {ProviderCount}, イベント: {EventCount}, フィルタリング: 無効", 
-                EnabledProviders.Count, EnabledEventNames.Count);
+            _logger.LogInformation("ETW設定を読み込みました - プロバイダー: {ProviderCount}, イベント: {EventCount}, カーネルキーワード: {KernelKeywords}", 
+                EnabledProviders.Count, EnabledEventNames.Count, KernelKeywords.Count > 0 ? string.Join(", ", KernelKeywords) : "自動");
         }
         catch (Exception ex)
         {
@@ -173,6 +239,8 @@ public class EtwConfiguration : IEtwConfiguration
     {
         EnabledProviders = GetDefaultProviders().AsReadOnly();
         EnabledEventNames = GetDefaultEventNames().AsReadOnly();
+        KernelKeywords = Array.Empty<string>();
+        ProviderSettings = new Dictionary<string, EtwProviderSettings>();
         BufferSizeMB = 64;
         BufferCount = 20;
         EventBufferTimeout = TimeSpan.FromMilliseconds(1000);
@@ -193,10 +261,11 @@ public class EtwConfiguration : IEtwConfiguration
             MaxEventQueueSize = 10000,
             EventProcessingIntervalMs = 10,
             EnableHighFrequencyEvents = false,
-            MaxEventsPerSecond = 1000
+            MaxEventsPerSecond = 1000,
+            OverflowSampleRate = 10
         };
 
-        _logger?.LogInformation("デフォルトETW設定を使用します - プロバイダー: {ProviderCount}, イベント: {EventCount}, フィルタリング: 無効", 
+        _logger?.LogInformation("デフォルトETW設定を使用します - プロバイダー: {ProviderCount}, イベント: {EventCount}", 
             EnabledProviders.Count, EnabledEventNames.Count);
     }
 
@@ -208,7 +277,13 @@ public class EtwConfiguration : IEtwConfiguration
         return new[]
         {
             "Microsoft-Windows-Kernel-FileIO",
-            "Microsoft-Windows-Kernel-Process"
+            "Microsoft-Windows-Kernel-Process",
+            "Microsoft-Windows-Kernel-Registry",
+            "Microsoft-Windows-Kernel-Network",
+            "Microsoft-Windows-DNS-Client",
+            "Microsoft-Windows-Kernel-Image",
+            "Microsoft-Windows-Kernel-Thread",
+            "Application Error"
         };
     }
 
@@ -228,7 +303,24 @@ public class EtwConfiguration : IEtwConfiguration
             "FileIO/Close",
             "Process/Start",
             "Process/Stop",
-            "Process/End"
+            "Process/End",
+            "Registry/Create",
+            "Registry/Open",
+            "Registry/Delete",
+            "Registry/SetValue",
+            "Registry/DeleteValue",
+            "TcpIp/Connect",
+            "TcpIp/Accept",
+            "TcpIp/Send",
+            "TcpIp/Recv",
+            "UdpIp/Send",
+            "UdpIp/Recv",
+            "Dns/QueryCompleted",
+            "Image/Load",
+            "Image/Unload",
+            "Thread/Start",
+            "Thread/End",
+            "Crash/Detected"
         };
     }
 
@@ -346,4 +438,9 @@ public class EtwPerformanceOptions
     /// 1秒あたりの最大イベント数
     /// </summary>
     public int MaxEventsPerSecond { get; init; }
+
+    /// <summary>
+    /// 最大イベント数を超えたときに残すイベントの間隔（N件に1件）
+    /// </summary>
+    public int OverflowSampleRate { get; init; }
 }
\ No newline at end of file
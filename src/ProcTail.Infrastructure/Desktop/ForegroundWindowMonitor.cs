@@ -0,0 +1,238 @@
+using System.Diagnostics;
+using System.Text;
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
+using ProcTail.Infrastructure.Processes;
+
+namespace ProcTail.Infrastructure.Desktop;
+
+/// <summary>
+/// WinEventフック（EVENT_SYSTEM_FOREGROUND）でフォアグラウンドウィンドウを持つプロセスの変化を監視する
+/// </summary>
+/// <remarks>
+/// フックはProcTailを実行しているユーザーのデスクトップにのみ届くため、サービス（セッション0）として実行している場合はイベントが発生しない。
+/// 同じプロセスのウィンドウ間でフォーカスが移った場合は記録せず、プロセスが変わった時に前のプロセスの Focus/Lost、新しいプロセスの Focus/Gained の順に発火する。
+/// 監視開始時点のフォアグラウンドのプロセスは Focus/Gained として発火する。
+/// </remarks>
+public class ForegroundWindowMonitor : IForegroundWindowMonitor
+{
+    /// <summary>
+    /// 生成するイベントのプロバイダー名
+    /// </summary>
+    public const string ProviderName = "WinEvent";
+
+    /// <summary>
+    /// フォーカスを得た時のイベント名
+    /// </summary>
+    public const string FocusGainedEventName = "Focus/Gained";
+
+    /// <summary>
+    /// フォーカスを失った時のイベント名
+    /// </summary>
+    public const string FocusLostEventName = "Focus/Lost";
+
+    private const int MaxWindowTitleLength = 512;
+
+    private readonly ILogger<ForegroundWindowMonitor> _logger;
+    private readonly object _lockObject = new();
+    private NativeMethods.WinEventProc? _callback;
+    private Thread? _hookThread;
+    private uint _hookThreadId;
+    private int _foregroundProcessId;
+    private DateTime _foregroundSince;
+    private bool _disposed;
+
+    /// <summary>
+    /// フォアグラウンドのプロセスが変わった時に発火するイベント
+    /// </summary>
+    public event EventHandler<RawEventData>? EventReceived;
+
+    /// <summary>
+    /// 監視中かどうか
+    /// </summary>
+    public bool IsMonitoring => _hookThread != null;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public ForegroundWindowMonitor(ILogger<ForegroundWindowMonitor> logger)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+    }
+
+    /// <summary>
+    /// 監視を開始
+    /// </summary>
+    public void Start()
+    {
+        if (_disposed)
+            throw new ObjectDisposedException(nameof(ForegroundWindowMonitor));
+
+        if (!OperatingSystem.IsWindows())
+        {
+            _logger.LogWarning("フォアグラウンドウィンドウの監視はWindowsでのみ使用できます");
+            return;
+        }
+
+        lock (_lockObject)
+        {
+            if (_hookThread != null)
+            {
+                return;
+            }
+
+            // WinEventフックのコールバックはフックを登録したスレッドのメッセージループで呼ばれる
+            using var started = new ManualResetEventSlim();
+            _hookThread = new Thread(() => RunMessageLoop(started))
+            {
+                IsBackground = true,
+                Name = "ProcTail Foreground Monitor"
+            };
+            _hookThread.Start();
+            started.Wait();
+        }
+    }
+
+    /// <summary>
+    /// 監視を停止
+    /// </summary>
+    public void Stop()
+    {
+        Thread? hookThread;
+        lock (_lockObject)
+        {
+            hookThread = _hookThread;
+            if (hookThread == null)
+            {
+                return;
+            }
+
+            NativeMethods.PostThreadMessage(_hookThreadId, NativeMethods.WmQuit, IntPtr.Zero, IntPtr.Zero);
+            _hookThread = null;
+        }
+
+        hookThread.Join(TimeSpan.FromSeconds(5));
+        _logger.LogInformation("フォアグラウンドウィンドウの監視を停止しました");
+    }
+
+    private void RunMessageLoop(ManualResetEventSlim started)
+    {
+        _hookThreadId = NativeMethods.GetCurrentThreadId();
+
+        // デリゲートがGCで回収されないようフィールドに保持
+        _callback = OnWinEvent;
+        var hook = NativeMethods.SetWinEventHook(
+            NativeMethods.EventSystemForeground,
+            NativeMethods.EventSystemForeground,
+            IntPtr.Zero,
+            _callback,
+            0,
+            0,
+            NativeMethods.WinEventOutOfContext | NativeMethods.WinEventSkipOwnProcess);
+        started.Set();
+
+        if (hook == IntPtr.Zero)
+        {
+            _logger.LogWarning("フォアグラウンドウィンドウのフックを登録できませんでした");
+            return;
+        }
+
+        _logger.LogInformation("フォアグラウンドウィンドウの監視を開始しました");
+
+        try
+        {
+            OnForegroundChanged(NativeMethods.GetForegroundWindow());
+
+            while (NativeMethods.GetMessage(out var message, IntPtr.Zero, 0, 0) > 0)
+            {
+                NativeMethods.TranslateMessage(ref message);
+                NativeMethods.DispatchMessage(ref message);
+            }
+        }
+        finally
+        {
+            NativeMethods.UnhookWinEvent(hook);
+        }
+    }
+
+    private void OnWinEvent(IntPtr hook, uint eventType, IntPtr window, int objectId, int childId, uint eventThreadId, uint eventTime)
+    {
+        if (eventType == NativeMethods.EventSystemForeground)
+        {
+            OnForegroundChanged(window);
+        }
+    }
+
+    private void OnForegroundChanged(IntPtr window)
+    {
+        try
+        {
+            if (window == IntPtr.Zero)
+            {
+                return;
+            }
+
+            NativeMethods.GetWindowThreadProcessId(window, out var processId);
+            if (processId == _foregroundProcessId)
+            {
+                return;
+            }
+
+            var now = DateTime.Now;
+            if (_foregroundProcessId > 0)
+            {
+                RaiseEvent(FocusLostEventName, _foregroundProcessId, now, new Dictionary<string, object>
+                {
+                    ["ForegroundDurationMs"] = (long)(now - _foregroundSince).TotalMilliseconds
+                });
+            }
+
+            _foregroundProcessId = processId;
+            _foregroundSince = now;
+            if (processId > 0)
+            {
+                RaiseEvent(FocusGainedEventName, processId, now, new Dictionary<string, object>
+                {
+                    ["WindowTitle"] = GetWindowTitle(window)
+                });
+            }
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "フォアグラウンドウィンドウの変更の処理中にエラーが発生しました");
+        }
+    }
+
+    private void RaiseEvent(string eventName, int processId, DateTime timestamp, Dictionary<string, object> payload)
+    {
+        EventReceived?.Invoke(this, new RawEventData(
+            timestamp,
+            ProviderName,
+            eventName,
+            processId,
+            0,
+            Guid.Empty,
+            Guid.Empty,
+            payload,
+            Stopwatch.GetTimestamp()));
+    }
+
+    private static string GetWindowTitle(IntPtr window)
+    {
+        var title = new StringBuilder(MaxWindowTitleLength);
+        return NativeMethods.GetWindowText(window, title, title.Capacity) > 0 ? title.ToString() : string.Empty;
+    }
+
+    /// <summary>
+    /// リソースの解放
+    /// </summary>
+    public void Dispose()
+    {
+        if (_disposed)
+            return;
+
+        Stop();
+        _disposed = true;
+    }
+}
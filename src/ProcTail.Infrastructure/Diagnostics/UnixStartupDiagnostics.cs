@@ -0,0 +1,98 @@
+using System.Runtime.Versioning;
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Interfaces;
+using ProcTail.Infrastructure.Ebpf;
+using ProcTail.Infrastructure.EndpointSecurity;
+
+namespace ProcTail.Infrastructure.Diagnostics;
+
+/// <summary>
+/// Linux/macOS向けの起動時診断
+/// </summary>
+/// <remarks>
+/// root権限と、イベントの購読に使う外部トレーサー（bpftrace/eslogger）の有無を確認する。
+/// </remarks>
+[UnsupportedOSPlatform("windows")]
+public class UnixStartupDiagnostics : IStartupDiagnostics
+{
+    private readonly ILogger<UnixStartupDiagnostics> _logger;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public UnixStartupDiagnostics(ILogger<UnixStartupDiagnostics> logger)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+    }
+
+    /// <summary>
+    /// 全てのチェックを実行
+    /// </summary>
+    public IReadOnlyList<DiagnosticCheckResult> Run()
+    {
+        return new[]
+        {
+            RunCheck("RootPrivileges", CheckRootPrivileges),
+            RunCheck("Tracer", CheckTracer)
+        };
+    }
+
+    private DiagnosticCheckResult RunCheck(string name, Func<string, DiagnosticCheckResult> check)
+    {
+        try
+        {
+            return check(name);
+        }
+        catch (Exception ex)
+        {
+            _logger.LogDebug(ex, "起動時診断でエラーが発生しました (Check: {Check})", name);
+            return new DiagnosticCheckResult(name, DiagnosticStatus.Warning, $"チェックできませんでした: {ex.Message}");
+        }
+    }
+
+    private static DiagnosticCheckResult CheckRootPrivileges(string name)
+    {
+        return Environment.IsPrivilegedProcess
+            ? new DiagnosticCheckResult(name, DiagnosticStatus.Passed, "root権限で実行中です")
+            : new DiagnosticCheckResult(name, DiagnosticStatus.Failed, "root権限がありません。制限モードで動作し、ディレクトリ変更とプロセスの開始・終了のみ記録します");
+    }
+
+    private static DiagnosticCheckResult CheckTracer(string name)
+    {
+        string tracerPath;
+        if (OperatingSystem.IsMacOS())
+        {
+            tracerPath = MacEndpointSecurityEventProvider.DefaultEsloggerPath;
+        }
+        else if (OperatingSystem.IsLinux())
+        {
+            tracerPath = LinuxEbpfEventProvider.DefaultBpftracePath;
+        }
+        else
+        {
+            return new DiagnosticCheckResult(name, DiagnosticStatus.Failed, "サポートされていないプラットフォームです");
+        }
+
+        var resolvedPath = ResolveExecutable(tracerPath);
+        return resolvedPath != null
+            ? new DiagnosticCheckResult(name, DiagnosticStatus.Passed, resolvedPath)
+            : new DiagnosticCheckResult(name, DiagnosticStatus.Failed, $"{tracerPath} が見つかりません。インストールしてPATHに追加してください");
+    }
+
+    /// <summary>
+    /// 実行ファイルのパスをPATHから解決
+    /// </summary>
+    private static string? ResolveExecutable(string fileName)
+    {
+        if (Path.IsPathRooted(fileName))
+        {
+            return File.Exists(fileName) ? fileName : null;
+        }
+
+        var searchPaths = (Environment.GetEnvironmentVariable("PATH") ?? string.Empty)
+            .Split(Path.PathSeparator, StringSplitOptions.RemoveEmptyEntries);
+        return searchPaths
+            .Select(directory => Path.Combine(directory, fileName))
+            .FirstOrDefault(File.Exists);
+    }
+}
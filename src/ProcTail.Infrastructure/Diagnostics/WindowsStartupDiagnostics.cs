@@ -0,0 +1,208 @@
+using System.IO.Pipes;
+using System.Runtime.InteropServices;
+using System.Runtime.Versioning;
+using System.Security.AccessControl;
+using System.Security.Principal;
+using Microsoft.Diagnostics.Tracing.Session;
+using Microsoft.Extensions.Logging;
+using Microsoft.Win32;
+using ProcTail.Core.Interfaces;
+using ProcTail.Infrastructure.Etw;
+using ProcTail.Infrastructure.NamedPipes;
+using ProcTail.Infrastructure.Processes;
+
+namespace ProcTail.Infrastructure.Diagnostics;
+
+/// <summary>
+/// Windows向けの起動時診断
+/// </summary>
+/// <remarks>
+/// SeDebugPrivilege、ETWセッションの空き、Named PipeのACL、前回のプロセスが残した孤立セッションを確認する。
+/// 権限や環境の問題で「何も記録されない」状態を、ログとステータスから判別できるようにする。
+/// </remarks>
+[SupportedOSPlatform("windows")]
+public class WindowsStartupDiagnostics : IStartupDiagnostics
+{
+    /// <summary>
+    /// EtwMaxLoggersが未設定の場合のETWセッション数の上限
+    /// </summary>
+    private const int DefaultMaxEtwSessions = 64;
+
+    /// <summary>
+    /// 空きがこの数以下の場合に警告する
+    /// </summary>
+    private const int LowEtwSessionThreshold = 2;
+
+    private const string EtwMaxLoggersKey = @"HKEY_LOCAL_MACHINE\SYSTEM\CurrentControlSet\Control\WMI";
+    private const string PipeDirectory = @"\\.\pipe\";
+
+    private readonly ILogger<WindowsStartupDiagnostics> _logger;
+    private readonly INamedPipeConfiguration _pipeConfiguration;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public WindowsStartupDiagnostics(
+        ILogger<WindowsStartupDiagnostics> logger,
+        INamedPipeConfiguration pipeConfiguration)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+        _pipeConfiguration = pipeConfiguration ?? throw new ArgumentNullException(nameof(pipeConfiguration));
+    }
+
+    /// <summary>
+    /// 全てのチェックを実行
+    /// </summary>
+    public IReadOnlyList<DiagnosticCheckResult> Run()
+    {
+        return new[]
+        {
+            RunCheck("SeDebugPrivilege", CheckDebugPrivilege),
+            RunCheck("EtwSessionQuota", CheckEtwSessionQuota),
+            RunCheck("PipeAcl", CheckPipeAcl),
+            RunCheck("OrphanedEtwSessions", CheckOrphanedSessions)
+        };
+    }
+
+    private DiagnosticCheckResult RunCheck(string name, Func<string, DiagnosticCheckResult> check)
+    {
+        try
+        {
+            return check(name);
+        }
+        catch (Exception ex)
+        {
+            _logger.LogDebug(ex, "起動時診断でエラーが発生しました (Check: {Check})", name);
+            return new DiagnosticCheckResult(name, DiagnosticStatus.Warning, $"チェックできませんでした: {ex.Message}");
+        }
+    }
+
+    /// <summary>
+    /// 他ユーザー・サービスのプロセス情報の取得に必要なSeDebugPrivilegeを保持しているか
+    /// </summary>
+    private static DiagnosticCheckResult CheckDebugPrivilege(string name)
+    {
+        if (!NativeMethods.LookupPrivilegeValue(null, "SeDebugPrivilege", out var debugLuid))
+        {
+            return new DiagnosticCheckResult(name, DiagnosticStatus.Warning, $"特権の値を取得できませんでした (Win32Error: {Marshal.GetLastWin32Error()})");
+        }
+
+        using var identity = WindowsIdentity.GetCurrent();
+        NativeMethods.GetTokenInformation(identity.Token, NativeMethods.TokenPrivileges, IntPtr.Zero, 0, out var length);
+        var buffer = Marshal.AllocHGlobal(length);
+        try
+        {
+            if (!NativeMethods.GetTokenInformation(identity.Token, NativeMethods.TokenPrivileges, buffer, length, out _))
+            {
+                return new DiagnosticCheckResult(name, DiagnosticStatus.Warning, $"トークンの特権を取得できませんでした (Win32Error: {Marshal.GetLastWin32Error()})");
+            }
+
+            // TOKEN_PRIVILEGES: PrivilegeCount（4バイト）に続いてLUID_AND_ATTRIBUTES（LUID 8バイト + Attributes 4バイト）の配列
+            var count = Marshal.ReadInt32(buffer);
+            for (var i = 0; i < count; i++)
+            {
+                var offset = sizeof(int) + i * NativeMethods.LuidAndAttributesSize;
+                if (Marshal.ReadInt64(buffer, offset) != debugLuid)
+                {
+                    continue;
+                }
+
+                var attributes = (uint)Marshal.ReadInt32(buffer, offset + sizeof(long));
+                return new DiagnosticCheckResult(name, DiagnosticStatus.Passed,
+                    (attributes & NativeMethods.SePrivilegeEnabled) != 0 ? "保持しています（有効）" : "保持しています（必要時に有効化）");
+            }
+        }
+        finally
+        {
+            Marshal.FreeHGlobal(buffer);
+        }
+
+        return new DiagnosticCheckResult(name, DiagnosticStatus.Warning,
+            "保持していません。他のユーザーやサービスとして実行中のプロセスは、実行ファイルパスや環境変数を取得できず監視対象の判定に使えない場合があります");
+    }
+
+    /// <summary>
+    /// 新しいETWセッションを作成できる空きがあるか
+    /// </summary>
+    private static DiagnosticCheckResult CheckEtwSessionQuota(string name)
+    {
+        var maxSessions = Registry.GetValue(EtwMaxLoggersKey, "EtwMaxLoggers", null) as int? ?? DefaultMaxEtwSessions;
+        var activeSessions = TraceEventSession.GetActiveSessionNames().Count;
+        var remaining = maxSessions - activeSessions;
+
+        if (remaining <= 0)
+        {
+            return new DiagnosticCheckResult(name, DiagnosticStatus.Failed,
+                $"ETWセッションが上限に達しています ({activeSessions}/{maxSessions})。logman query -ets で不要なセッションを確認し、停止してください");
+        }
+
+        if (remaining <= LowEtwSessionThreshold)
+        {
+            return new DiagnosticCheckResult(name, DiagnosticStatus.Warning,
+                $"ETWセッションの空きが少なくなっています ({activeSessions}/{maxSessions})");
+        }
+
+        return new DiagnosticCheckResult(name, DiagnosticStatus.Passed, $"{activeSessions}/{maxSessions} 使用中");
+    }
+
+    /// <summary>
+    /// Named PipeのACLが作成でき、現在のユーザーが接続でき、パイプ名が使われていないか
+    /// </summary>
+    private DiagnosticCheckResult CheckPipeAcl(string name)
+    {
+        var pipeName = _pipeConfiguration.PipeName;
+        if (Directory.GetFiles(PipeDirectory).Contains(PipeDirectory + pipeName, StringComparer.OrdinalIgnoreCase))
+        {
+            return new DiagnosticCheckResult(name, DiagnosticStatus.Failed,
+                $"パイプ名 {pipeName} は既に別のプロセスが使用しています。ProcTailが二重に起動していないか確認してください");
+        }
+
+        var pipeSecurity = WindowsNamedPipeServer.CreatePipeSecurity();
+        using var identity = WindowsIdentity.GetCurrent();
+        var grantsCurrentUser = pipeSecurity.GetAccessRules(true, false, typeof(SecurityIdentifier))
+            .Cast<PipeAccessRule>()
+            .Any(rule => rule.AccessControlType == AccessControlType.Allow
+                && rule.IdentityReference.Equals(identity.User)
+                && rule.PipeAccessRights.HasFlag(PipeAccessRights.FullControl));
+        if (!grantsCurrentUser)
+        {
+            return new DiagnosticCheckResult(name, DiagnosticStatus.Failed,
+                $"パイプのACLに現在のユーザー ({identity.Name}) へのアクセス許可が含まれていません");
+        }
+
+        // 同じACLで確認用のパイプを作成し、OSが受け付けることを確認
+        try
+        {
+            using var probe = NamedPipeServerStreamAcl.Create(
+                $"{pipeName}_diag_{Environment.ProcessId}",
+                PipeDirection.InOut,
+                1,
+                PipeTransmissionMode.Message,
+                PipeOptions.None,
+                0,
+                0,
+                pipeSecurity);
+        }
+        catch (Exception ex) when (ex is UnauthorizedAccessException or IOException)
+        {
+            return new DiagnosticCheckResult(name, DiagnosticStatus.Failed, $"パイプを作成できませんでした: {ex.Message}");
+        }
+
+        return new DiagnosticCheckResult(name, DiagnosticStatus.Passed, $"{identity.Name} とAdministratorsに許可しています");
+    }
+
+    /// <summary>
+    /// 前回のProcTailが残したETWセッションがないか
+    /// </summary>
+    private static DiagnosticCheckResult CheckOrphanedSessions(string name)
+    {
+        var orphanedSessions = ProcTailEtwSessions.FindOrphanedSessionNames();
+        if (orphanedSessions.Count == 0)
+        {
+            return new DiagnosticCheckResult(name, DiagnosticStatus.Passed, "ありません");
+        }
+
+        return new DiagnosticCheckResult(name, DiagnosticStatus.Warning,
+            $"終了したProcTailのセッションが残っています ({string.Join(", ", orphanedSessions)})。ETW監視の開始時に停止します");
+    }
+}
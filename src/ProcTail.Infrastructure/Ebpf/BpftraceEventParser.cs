@@ -0,0 +1,337 @@
+using System.Collections.Concurrent;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Infrastructure.Ebpf;
+
+/// <summary>
+/// bpftraceスクリプトの出力行をETWと同じ形式の生イベントに変換
+/// </summary>
+/// <remarks>
+/// プロバイダー名・イベント名・ペイロードキーはWindowsのカーネルプロバイダーに合わせるため、
+/// EventProcessor以降はバックエンドを意識せずに処理できる。
+/// write/closeはファイルディスクリプタしか持たないため、openat時のパスを (PID, FD) ごとに保持して解決する。
+/// </remarks>
+public class BpftraceEventParser
+{
+    /// <summary>
+    /// ファイルイベントのプロバイダー名
+    /// </summary>
+    public const string FileProviderName = "Microsoft-Windows-Kernel-FileIO";
+
+    /// <summary>
+    /// プロセスイベントのプロバイダー名
+    /// </summary>
+    public const string ProcessProviderName = "Microsoft-Windows-Kernel-Process";
+
+    private const int AtFdCwd = -100;
+
+    private readonly ConcurrentDictionary<(int ProcessId, int Fd), string> _openFiles = new();
+    private readonly Func<int, int, string?> _resolveFd;
+    private readonly Func<int, string?> _resolveCwd;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public BpftraceEventParser()
+        : this(ReadProcFdLink, ReadProcCwdLink)
+    {
+    }
+
+    /// <summary>
+    /// コンストラクタ（/procの参照を差し替える場合）
+    /// </summary>
+    /// <param name="resolveFd">(PID, FD) からパスを解決する関数</param>
+    /// <param name="resolveCwd">PIDからカレントディレクトリを解決する関数</param>
+    public BpftraceEventParser(Func<int, int, string?> resolveFd, Func<int, string?> resolveCwd)
+    {
+        _resolveFd = resolveFd ?? throw new ArgumentNullException(nameof(resolveFd));
+        _resolveCwd = resolveCwd ?? throw new ArgumentNullException(nameof(resolveCwd));
+    }
+
+    /// <summary>
+    /// 追跡中のファイルディスクリプタ数
+    /// </summary>
+    public int OpenFileCount => _openFiles.Count;
+
+    /// <summary>
+    /// 出力行を解析
+    /// </summary>
+    /// <param name="line">bpftraceの出力行</param>
+    /// <param name="timestamp">イベント時刻</param>
+    /// <returns>生イベント（対象外の行はnull）</returns>
+    public RawEventData? Parse(string line, DateTime timestamp)
+    {
+        if (string.IsNullOrWhiteSpace(line))
+        {
+            return null;
+        }
+
+        // 形式: <種別> <PID> <TID> <引数...>。パスは空白を含み得るため常に最後の列
+        var fields = line.Split(' ', 4);
+        if (fields.Length < 3 ||
+            !int.TryParse(fields[1], out var processId) ||
+            !int.TryParse(fields[2], out var threadId))
+        {
+            return null;
+        }
+
+        var rest = fields.Length > 3 ? fields[3] : string.Empty;
+
+        return fields[0] switch
+        {
+            "open" => ParseOpen(processId, threadId, rest, timestamp),
+            "write" => ParseWrite(processId, threadId, rest, timestamp),
+            "close" => ParseClose(processId, threadId, rest, timestamp),
+            "unlink" => ParseUnlink(processId, threadId, rest, timestamp),
+            "rename" => ParseRename(processId, threadId, rest, timestamp),
+            "fork" => ParseFork(processId, threadId, rest, timestamp),
+            "exec" => ParseExec(processId, threadId, rest, timestamp),
+            "exit" => ParseExit(processId, threadId, rest, timestamp),
+            _ => null
+        };
+    }
+
+    private RawEventData? ParseOpen(int processId, int threadId, string rest, DateTime timestamp)
+    {
+        // open <PID> <TID> <FD> <DIRFD> <FLAGS> <PATH>
+        var args = rest.Split(' ', 4);
+        if (args.Length < 4 ||
+            !int.TryParse(args[0], out var fd) ||
+            !int.TryParse(args[1], out var dirFd) ||
+            !int.TryParse(args[2], out var flags))
+        {
+            return null;
+        }
+
+        var path = ResolvePath(processId, dirFd, args[3]);
+        _openFiles[(processId, fd)] = path;
+
+        return CreateFileEvent("FileIO/Create", processId, threadId, timestamp, new Dictionary<string, object>
+        {
+            ["FileName"] = path,
+            ["FileDescriptor"] = fd,
+            ["CreateOptions"] = flags
+        });
+    }
+
+    private RawEventData? ParseWrite(int processId, int threadId, string rest, DateTime timestamp)
+    {
+        // write <PID> <TID> <FD> <SIZE>
+        var args = rest.Split(' ');
+        if (args.Length < 2 || !int.TryParse(args[0], out var fd) || !long.TryParse(args[1], out var size))
+        {
+            return null;
+        }
+
+        var path = GetOpenFilePath(processId, fd);
+        if (path == null)
+        {
+            // パイプ・ソケット・端末などファイル以外への書き込みは記録しない
+            return null;
+        }
+
+        return CreateFileEvent("FileIO/Write", processId, threadId, timestamp, new Dictionary<string, object>
+        {
+            ["FileName"] = path,
+            ["FileDescriptor"] = fd,
+            ["IoSize"] = size
+        });
+    }
+
+    private RawEventData? ParseClose(int processId, int threadId, string rest, DateTime timestamp)
+    {
+        // close <PID> <TID> <FD>
+        if (!int.TryParse(rest.Trim(), out var fd) || !_openFiles.TryRemove((processId, fd), out var path))
+        {
+            return null;
+        }
+
+        return CreateFileEvent("FileIO/Close", processId, threadId, timestamp, new Dictionary<string, object>
+        {
+            ["FileName"] = path,
+            ["FileDescriptor"] = fd
+        });
+    }
+
+    private RawEventData? ParseUnlink(int processId, int threadId, string rest, DateTime timestamp)
+    {
+        // unlink <PID> <TID> <DIRFD> <PATH>
+        var args = rest.Split(' ', 2);
+        if (args.Length < 2 || !int.TryParse(args[0], out var dirFd))
+        {
+            return null;
+        }
+
+        return CreateFileEvent("FileIO/Delete", processId, threadId, timestamp, new Dictionary<string, object>
+        {
+            ["FileName"] = ResolvePath(processId, dirFd, args[1])
+        });
+    }
+
+    private RawEventData? ParseRename(int processId, int threadId, string rest, DateTime timestamp)
+    {
+        // rename <PID> <TID> <OLDDIRFD> <NEWDIRFD> <OLDPATH>\t<NEWPATH>
+        var args = rest.Split(' ', 3);
+        if (args.Length < 3 ||
+            !int.TryParse(args[0], out var oldDirFd) ||
+            !int.TryParse(args[1], out var newDirFd))
+        {
+            return null;
+        }
+
+        var paths = args[2].Split('\t', 2);
+        if (paths.Length < 2)
+        {
+            return null;
+        }
+
+        return CreateFileEvent("FileIO/Rename", processId, threadId, timestamp, new Dictionary<string, object>
+        {
+            ["FileName"] = ResolvePath(processId, oldDirFd, paths[0]),
+            ["NewFileName"] = ResolvePath(processId, newDirFd, paths[1])
+        });
+    }
+
+    private RawEventData? ParseFork(int processId, int threadId, string rest, DateTime timestamp)
+    {
+        // fork <PID> <TID> <CHILDPID> <COMM>
+        var args = rest.Split(' ', 2);
+        if (args.Length < 1 || !int.TryParse(args[0], out var childProcessId))
+        {
+            return null;
+        }
+
+        // 子は親のファイルディスクリプタを引き継ぐ
+        foreach (var entry in _openFiles.Where(e => e.Key.ProcessId == processId).ToList())
+        {
+            _openFiles[(childProcessId, entry.Key.Fd)] = entry.Value;
+        }
+
+        // ETWのProcess/Startと同様に、子プロセスIDはペイロードのProcessIdに入れる
+        return CreateProcessEvent("Process/Start", processId, threadId, timestamp, new Dictionary<string, object>
+        {
+            ["ProcessId"] = childProcessId,
+            ["ParentId"] = processId,
+            ["ImageFileName"] = args.Length > 1 ? args[1] : string.Empty,
+            ["CurrentDirectory"] = _resolveCwd(childProcessId) ?? string.Empty
+        });
+    }
+
+    private RawEventData? ParseExec(int processId, int threadId, string rest, DateTime timestamp)
+    {
+        // exec <PID> <TID> <FILENAME>
+        if (string.IsNullOrEmpty(rest))
+        {
+            return null;
+        }
+
+        return CreateProcessEvent("Process/Exec", processId, threadId, timestamp, new Dictionary<string, object>
+        {
+            ["ProcessId"] = processId,
+            ["ImageFileName"] = Path.GetFileName(rest),
+            ["FileName"] = rest
+        });
+    }
+
+    private RawEventData? ParseExit(int processId, int threadId, string rest, DateTime timestamp)
+    {
+        // exit <PID> <TID> <CODE>  CODEはdo_exitの引数（wait(2)のステータス形式）
+        if (!long.TryParse(rest.Trim(), out var status))
+        {
+            return null;
+        }
+
+        foreach (var key in _openFiles.Keys.Where(k => k.ProcessId == processId).ToList())
+        {
+            _openFiles.TryRemove(key, out _);
+        }
+
+        var payload = new Dictionary<string, object>
+        {
+            ["ProcessId"] = processId,
+            ["ExitStatus"] = DecodeExitStatus(status)
+        };
+
+        // 終了コードが128+Nの通常終了とシグナルによる終了を区別できるよう、シグナル番号も記録
+        var signal = (int)(status & 0x7f);
+        if (signal != 0)
+        {
+            payload["TerminationSignal"] = signal;
+        }
+
+        return CreateProcessEvent("Process/End", processId, threadId, timestamp, payload);
+    }
+
+    /// <summary>
+    /// wait(2)形式のステータスを終了コードに変換（シグナル終了は128+シグナル番号）
+    /// </summary>
+    /// <param name="status">ステータス</param>
+    /// <returns>終了コード</returns>
+    public static int DecodeExitStatus(long status)
+    {
+        var signal = (int)(status & 0x7f);
+        return signal == 0 ? (int)((status >> 8) & 0xff) : 128 + signal;
+    }
+
+    private string? GetOpenFilePath(int processId, int fd)
+    {
+        if (_openFiles.TryGetValue((processId, fd), out var path))
+        {
+            return path;
+        }
+
+        // dup等で追跡できなかったFDは/procから解決
+        path = _resolveFd(processId, fd);
+        if (path == null || !path.StartsWith('/'))
+        {
+            return null;
+        }
+
+        _openFiles[(processId, fd)] = path;
+        return path;
+    }
+
+    private string ResolvePath(int processId, int dirFd, string path)
+    {
+        if (path.StartsWith('/'))
+        {
+            return path;
+        }
+
+        var baseDirectory = dirFd == AtFdCwd ? _resolveCwd(processId) : GetOpenFilePath(processId, dirFd);
+        return baseDirectory == null ? path : Path.GetFullPath(Path.Combine(baseDirectory, path));
+    }
+
+    private static RawEventData CreateFileEvent(string eventName, int processId, int threadId, DateTime timestamp, Dictionary<string, object> payload)
+    {
+        return new RawEventData(timestamp, FileProviderName, eventName, processId, threadId, Guid.Empty, Guid.Empty, payload);
+    }
+
+    private static RawEventData CreateProcessEvent(string eventName, int processId, int threadId, DateTime timestamp, Dictionary<string, object> payload)
+    {
+        return new RawEventData(timestamp, ProcessProviderName, eventName, processId, threadId, Guid.Empty, Guid.Empty, payload);
+    }
+
+    private static string? ReadProcFdLink(int processId, int fd)
+    {
+        return ReadLink($"/proc/{processId}/fd/{fd}");
+    }
+
+    private static string? ReadProcCwdLink(int processId)
+    {
+        return ReadLink($"/proc/{processId}/cwd");
+    }
+
+    private static string? ReadLink(string path)
+    {
+        try
+        {
+            return new FileInfo(path).LinkTarget;
+        }
+        catch
+        {
+            // プロセスが既に終了している場合など
+            return null;
+        }
+    }
+}
@@ -0,0 +1,140 @@
+using System.Diagnostics;
+using System.Runtime.InteropServices;
+using System.Runtime.Versioning;
+using System.Text;
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
+using ProcTail.Infrastructure.Tracing;
+
+namespace ProcTail.Infrastructure.Ebpf;
+
+/// <summary>
+/// bpftrace（eBPF）を使用したLinux向けイベントプロバイダー
+/// </summary>
+/// <remarks>
+/// syscalls/sched/taskトレースポイントとdo_exitのkprobeにアタッチするbpftraceを子プロセスとして起動し、
+/// その出力を<see cref="BpftraceEventParser"/>でETW互換のイベントに変換する。
+/// 実行にはroot（またはCAP_BPF/CAP_PERFMON）とPATH上のbpftraceが必要。
+/// </remarks>
+[SupportedOSPlatform("linux")]
+public class LinuxEbpfEventProvider : ExternalTracerEventProvider
+{
+    /// <summary>
+    /// 既定のbpftrace実行ファイル名
+    /// </summary>
+    public const string DefaultBpftracePath = "bpftrace";
+
+    private const string FileProbes = """
+        tracepoint:syscalls:sys_enter_openat /pid != $1/ { @open_path[tid] = args->filename; @open_dirfd[tid] = args->dfd; @open_flags[tid] = args->flags; }
+        tracepoint:syscalls:sys_enter_open /pid != $1/ { @open_path[tid] = args->filename; @open_dirfd[tid] = -100; @open_flags[tid] = args->flags; }
+        tracepoint:syscalls:sys_exit_openat, tracepoint:syscalls:sys_exit_open /@open_path[tid]/ {
+          if (args->ret >= 0) { printf("open %d %d %d %d %d %s\n", pid, tid, args->ret, @open_dirfd[tid], @open_flags[tid], str(@open_path[tid])); }
+          delete(@open_path[tid]); delete(@open_dirfd[tid]); delete(@open_flags[tid]);
+        }
+        tracepoint:syscalls:sys_enter_write /pid != $1/ { printf("write %d %d %d %d\n", pid, tid, args->fd, args->count); }
+        tracepoint:syscalls:sys_enter_close /pid != $1/ { printf("close %d %d %d\n", pid, tid, args->fd); }
+        tracepoint:syscalls:sys_enter_unlinkat /pid != $1/ { printf("unlink %d %d %d %s\n", pid, tid, args->dfd, str(args->pathname)); }
+        tracepoint:syscalls:sys_enter_unlink /pid != $1/ { printf("unlink %d %d -100 %s\n", pid, tid, str(args->pathname)); }
+        tracepoint:syscalls:sys_enter_renameat2, tracepoint:syscalls:sys_enter_renameat /pid != $1/ { printf("rename %d %d %d %d %s\t%s\n", pid, tid, args->olddfd, args->newdfd, str(args->oldname), str(args->newname)); }
+        tracepoint:syscalls:sys_enter_rename /pid != $1/ { printf("rename %d %d -100 -100 %s\t%s\n", pid, tid, str(args->oldname), str(args->newname)); }
+        """;
+
+    // CLONE_THREAD (0x10000) を除外してスレッド生成をプロセス生成と区別する
+    private const string ProcessProbes = """
+        tracepoint:task:task_newtask /!(args->clone_flags & 0x10000)/ { printf("fork %d %d %d %s\n", pid, tid, args->pid, args->comm); }
+        tracepoint:sched:sched_process_exec { printf("exec %d %d %s\n", pid, tid, str(args->filename)); }
+        kprobe:do_exit /pid == tid/ { printf("exit %d %d %d\n", pid, tid, arg0); }
+        """;
+
+    private readonly IEtwConfiguration _configuration;
+    private readonly BpftraceEventParser _parser = new();
+    private readonly string _bpftracePath;
+
+    /// <summary>
+    /// ログに表示するバックエンド名
+    /// </summary>
+    protected override string BackendName => "eBPF";
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public LinuxEbpfEventProvider(
+        ILogger<LinuxEbpfEventProvider> logger,
+        IEtwConfiguration configuration)
+        : this(logger, configuration, DefaultBpftracePath)
+    {
+    }
+
+    /// <summary>
+    /// コンストラクタ（bpftraceのパスを指定する場合）
+    /// </summary>
+    public LinuxEbpfEventProvider(
+        ILogger<LinuxEbpfEventProvider> logger,
+        IEtwConfiguration configuration,
+        string bpftracePath)
+        : base(logger)
+    {
+        _configuration = configuration ?? throw new ArgumentNullException(nameof(configuration));
+        _bpftracePath = string.IsNullOrWhiteSpace(bpftracePath) ? DefaultBpftracePath : bpftracePath;
+
+        // Linux環境のチェック
+        if (!RuntimeInformation.IsOSPlatform(OSPlatform.Linux))
+        {
+            throw new PlatformNotSupportedException("LinuxEbpfEventProvider is only supported on Linux platform");
+        }
+    }
+
+    /// <summary>
+    /// 有効なプロバイダーに応じたbpftraceスクリプトを構築
+    /// </summary>
+    /// <param name="enabledProviders">有効なプロバイダー一覧</param>
+    /// <returns>bpftraceスクリプト</returns>
+    public static string BuildScript(IReadOnlyList<string> enabledProviders)
+    {
+        var script = new StringBuilder();
+
+        if (enabledProviders.Contains(BpftraceEventParser.FileProviderName))
+        {
+            script.AppendLine(FileProbes);
+        }
+
+        if (enabledProviders.Contains(BpftraceEventParser.ProcessProviderName))
+        {
+            script.AppendLine(ProcessProbes);
+        }
+
+        if (script.Length == 0)
+        {
+            throw new InvalidOperationException("eBPFで監視できるプロバイダーが有効になっていません");
+        }
+
+        return script.ToString();
+    }
+
+    /// <summary>
+    /// bpftraceの起動情報を作成
+    /// </summary>
+    protected override ProcessStartInfo CreateStartInfo()
+    {
+        var startInfo = new ProcessStartInfo(_bpftracePath);
+
+        // 出力を行単位でフラッシュさせ、デーモン自身（$1）のイベントは除外する
+        startInfo.ArgumentList.Add("-B");
+        startInfo.ArgumentList.Add("line");
+        startInfo.ArgumentList.Add("-e");
+        startInfo.ArgumentList.Add(BuildScript(_configuration.EnabledProviders));
+        startInfo.ArgumentList.Add(Environment.ProcessId.ToString());
+        startInfo.Environment["BPFTRACE_STRLEN"] = "200";
+
+        return startInfo;
+    }
+
+    /// <summary>
+    /// bpftraceの出力1行を解析
+    /// </summary>
+    protected override RawEventData? ParseLine(string line, DateTime timestamp)
+    {
+        return _parser.Parse(line, timestamp);
+    }
+}
@@ -0,0 +1,230 @@
+using System.Text.Json;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Infrastructure.EndpointSecurity;
+
+/// <summary>
+/// eslogger（Endpoint Security）のJSON出力をETWと同じ形式の生イベントに変換
+/// </summary>
+/// <remarks>
+/// 1行が1つのes_message_tに対応する。プロバイダー名・イベント名・ペイロードキーは
+/// Windowsのカーネルプロバイダーに合わせ、EventProcessor以降はバックエンドを意識せずに処理できるようにする。
+/// </remarks>
+public class EsloggerEventParser
+{
+    /// <summary>
+    /// ファイルイベントのプロバイダー名
+    /// </summary>
+    public const string FileProviderName = "Microsoft-Windows-Kernel-FileIO";
+
+    /// <summary>
+    /// プロセスイベントのプロバイダー名
+    /// </summary>
+    public const string ProcessProviderName = "Microsoft-Windows-Kernel-Process";
+
+    /// <summary>
+    /// 購読するEndpoint Securityイベント（esloggerのイベント名）
+    /// </summary>
+    public static readonly IReadOnlyList<string> FileEventTypes = new[] { "open", "write", "close", "unlink", "rename" };
+
+    /// <summary>
+    /// 購読するEndpoint Securityイベント（プロセス系）
+    /// </summary>
+    public static readonly IReadOnlyList<string> ProcessEventTypes = new[] { "exec", "fork", "exit" };
+
+    /// <summary>
+    /// 出力行を解析
+    /// </summary>
+    /// <param name="line">esloggerの出力行</param>
+    /// <param name="timestamp">イベント時刻</param>
+    /// <returns>生イベント（対象外の行はnull）</returns>
+    public RawEventData? Parse(string line, DateTime timestamp)
+    {
+        if (string.IsNullOrWhiteSpace(line))
+        {
+            return null;
+        }
+
+        try
+        {
+            using var document = JsonDocument.Parse(line);
+            var root = document.RootElement;
+
+            if (!TryGetInt(root, out var processId, "process", "audit_token", "pid") ||
+                !root.TryGetProperty("event", out var eventElement) ||
+                eventElement.ValueKind != JsonValueKind.Object)
+            {
+                return null;
+            }
+
+            TryGetInt(root, out var threadId, "thread", "thread_id");
+
+            // eventオブジェクトは種別名をキーとする単一プロパティを持つ
+            foreach (var property in eventElement.EnumerateObject())
+            {
+                return property.Name switch
+                {
+                    "open" => CreateFileEvent("FileIO/Create", processId, threadId, timestamp, property.Value, "file"),
+                    "write" => CreateFileEvent("FileIO/Write", processId, threadId, timestamp, property.Value, "target"),
+                    "close" => CreateFileEvent("FileIO/Close", processId, threadId, timestamp, property.Value, "target"),
+                    "unlink" => CreateFileEvent("FileIO/Delete", processId, threadId, timestamp, property.Value, "target"),
+                    "rename" => ParseRename(processId, threadId, timestamp, property.Value),
+                    "fork" => ParseFork(root, processId, threadId, timestamp, property.Value),
+                    "exec" => ParseExec(processId, threadId, timestamp, property.Value),
+                    "exit" => ParseExit(processId, threadId, timestamp, property.Value),
+                    _ => null
+                };
+            }
+
+            return null;
+        }
+        catch (JsonException)
+        {
+            return null;
+        }
+    }
+
+    private static RawEventData? CreateFileEvent(string eventName, int processId, int threadId, DateTime timestamp, JsonElement data, string fileProperty)
+    {
+        var path = GetString(data, fileProperty, "path");
+        if (string.IsNullOrEmpty(path))
+        {
+            return null;
+        }
+
+        var payload = new Dictionary<string, object> { ["FileName"] = path };
+        if (TryGetInt(data, out var flags, "fflag"))
+        {
+            payload["CreateOptions"] = flags;
+        }
+
+        // closeは書き込みがあったかどうかを持つ
+        if (data.TryGetProperty("modified", out var modified) &&
+            modified.ValueKind is JsonValueKind.True or JsonValueKind.False)
+        {
+            payload["Modified"] = modified.GetBoolean();
+        }
+
+        return new RawEventData(timestamp, FileProviderName, eventName, processId, threadId, Guid.Empty, Guid.Empty, payload);
+    }
+
+    private static RawEventData? ParseRename(int processId, int threadId, DateTime timestamp, JsonElement data)
+    {
+        var source = GetString(data, "source", "path");
+        if (string.IsNullOrEmpty(source))
+        {
+            return null;
+        }
+
+        // 既存ファイルへの上書きはexisting_file、新規パスはディレクトリ+ファイル名で表される
+        var destination = GetString(data, "destination", "existing_file", "path");
+        if (string.IsNullOrEmpty(destination))
+        {
+            var directory = GetString(data, "destination", "new_path", "dir", "path");
+            var fileName = GetString(data, "destination", "new_path", "filename");
+            if (directory != null && fileName != null)
+            {
+                destination = Path.Combine(directory, fileName);
+            }
+        }
+
+        var payload = new Dictionary<string, object> { ["FileName"] = source };
+        if (!string.IsNullOrEmpty(destination))
+        {
+            payload["NewFileName"] = destination;
+        }
+
+        return new RawEventData(timestamp, FileProviderName, "FileIO/Rename", processId, threadId, Guid.Empty, Guid.Empty, payload);
+    }
+
+    private static RawEventData? ParseFork(JsonElement root, int processId, int threadId, DateTime timestamp, JsonElement data)
+    {
+        if (!TryGetInt(data, out var childProcessId, "child", "audit_token", "pid"))
+        {
+            return null;
+        }
+
+        // ETWのProcess/Startと同様に、子プロセスIDはペイロードのProcessIdに入れる
+        var executable = GetString(data, "child", "executable", "path") ?? GetString(root, "process", "executable", "path") ?? string.Empty;
+        var payload = new Dictionary<string, object>
+        {
+            ["ProcessId"] = childProcessId,
+            ["ParentId"] = processId,
+            ["ImageFileName"] = Path.GetFileName(executable),
+            ["CurrentDirectory"] = GetString(data, "child", "cwd", "path") ?? string.Empty
+        };
+
+        return new RawEventData(timestamp, ProcessProviderName, "Process/Start", processId, threadId, Guid.Empty, Guid.Empty, payload);
+    }
+
+    private static RawEventData? ParseExec(int processId, int threadId, DateTime timestamp, JsonElement data)
+    {
+        var executable = GetString(data, "target", "executable", "path");
+        if (string.IsNullOrEmpty(executable))
+        {
+            return null;
+        }
+
+        var payload = new Dictionary<string, object>
+        {
+            ["ProcessId"] = processId,
+            ["ImageFileName"] = Path.GetFileName(executable),
+            ["FileName"] = executable
+        };
+
+        return new RawEventData(timestamp, ProcessProviderName, "Process/Exec", processId, threadId, Guid.Empty, Guid.Empty, payload);
+    }
+
+    private static RawEventData? ParseExit(int processId, int threadId, DateTime timestamp, JsonElement data)
+    {
+        if (!TryGetInt(data, out var status, "stat"))
+        {
+            return null;
+        }
+
+        // statはwait(2)形式。シグナル終了は128+シグナル番号とする
+        var signal = status & 0x7f;
+        var exitStatus = signal == 0 ? (status >> 8) & 0xff : 128 + signal;
+
+        var payload = new Dictionary<string, object>
+        {
+            ["ProcessId"] = processId,
+            ["ExitStatus"] = exitStatus
+        };
+        if (signal != 0)
+        {
+            payload["TerminationSignal"] = signal;
+        }
+
+        return new RawEventData(timestamp, ProcessProviderName, "Process/End", processId, threadId, Guid.Empty, Guid.Empty, payload);
+    }
+
+    private static bool TryGetElement(JsonElement element, out JsonElement result, params string[] path)
+    {
+        result = element;
+        foreach (var name in path)
+        {
+            if (result.ValueKind != JsonValueKind.Object || !result.TryGetProperty(name, out result))
+            {
+                return false;
+            }
+        }
+
+        return true;
+    }
+
+    private static bool TryGetInt(JsonElement element, out int value, params string[] path)
+    {
+        value = 0;
+        return TryGetElement(element, out var result, path) &&
+               result.ValueKind == JsonValueKind.Number &&
+               result.TryGetInt32(out value);
+    }
+
+    private static string? GetString(JsonElement element, params string[] path)
+    {
+        return TryGetElement(element, out var result, path) && result.ValueKind == JsonValueKind.String
+            ? result.GetString()
+            : null;
+    }
+}
@@ -0,0 +1,122 @@
+using System.Diagnostics;
+using System.Runtime.InteropServices;
+using System.Runtime.Versioning;
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
+using ProcTail.Infrastructure.Tracing;
+
+namespace ProcTail.Infrastructure.EndpointSecurity;
+
+/// <summary>
+/// Endpoint Securityフレームワークを使用したmacOS向けイベントプロバイダー
+/// </summary>
+/// <remarks>
+/// Endpoint Securityクライアントを直接作成するにはAppleが付与するエンタイトルメントが必要なため、
+/// OS標準のESクライアントであるeslogger（macOS 13以降）を子プロセスとして起動し、
+/// NOTIFY_OPEN/WRITE/CLOSE/UNLINK/RENAME/EXEC/FORK/EXITのJSON出力を<see cref="EsloggerEventParser"/>で変換する。
+/// 実行にはrootと、ProcTailを起動するターミナル等へのフルディスクアクセス許可が必要。
+/// </remarks>
+[SupportedOSPlatform("macos")]
+public class MacEndpointSecurityEventProvider : ExternalTracerEventProvider
+{
+    /// <summary>
+    /// 既定のeslogger実行ファイルパス
+    /// </summary>
+    public const string DefaultEsloggerPath = "/usr/bin/eslogger";
+
+    private readonly IEtwConfiguration _configuration;
+    private readonly EsloggerEventParser _parser = new();
+    private readonly string _esloggerPath;
+    private readonly int _ownProcessId = Environment.ProcessId;
+
+    /// <summary>
+    /// ログに表示するバックエンド名
+    /// </summary>
+    protected override string BackendName => "Endpoint Security";
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public MacEndpointSecurityEventProvider(
+        ILogger<MacEndpointSecurityEventProvider> logger,
+        IEtwConfiguration configuration)
+        : this(logger, configuration, DefaultEsloggerPath)
+    {
+    }
+
+    /// <summary>
+    /// コンストラクタ（esloggerのパスを指定する場合）
+    /// </summary>
+    public MacEndpointSecurityEventProvider(
+        ILogger<MacEndpointSecurityEventProvider> logger,
+        IEtwConfiguration configuration,
+        string esloggerPath)
+        : base(logger)
+    {
+        _configuration = configuration ?? throw new ArgumentNullException(nameof(configuration));
+        _esloggerPath = string.IsNullOrWhiteSpace(esloggerPath) ? DefaultEsloggerPath : esloggerPath;
+
+        // macOS環境のチェック
+        if (!RuntimeInformation.IsOSPlatform(OSPlatform.OSX))
+        {
+            throw new PlatformNotSupportedException("MacEndpointSecurityEventProvider is only supported on macOS platform");
+        }
+    }
+
+    /// <summary>
+    /// 有効なプロバイダーに応じて購読するイベント種別を決定
+    /// </summary>
+    /// <param name="enabledProviders">有効なプロバイダー一覧</param>
+    /// <returns>esloggerに渡すイベント名</returns>
+    public static IReadOnlyList<string> GetEventTypes(IReadOnlyList<string> enabledProviders)
+    {
+        var eventTypes = new List<string>();
+
+        if (enabledProviders.Contains(EsloggerEventParser.FileProviderName))
+        {
+            eventTypes.AddRange(EsloggerEventParser.FileEventTypes);
+        }
+
+        if (enabledProviders.Contains(EsloggerEventParser.ProcessProviderName))
+        {
+            eventTypes.AddRange(EsloggerEventParser.ProcessEventTypes);
+        }
+
+        if (eventTypes.Count == 0)
+        {
+            throw new InvalidOperationException("Endpoint Securityで監視できるプロバイダーが有効になっていません");
+        }
+
+        return eventTypes;
+    }
+
+    /// <summary>
+    /// esloggerの起動情報を作成
+    /// </summary>
+    protected override ProcessStartInfo CreateStartInfo()
+    {
+        var startInfo = new ProcessStartInfo(_esloggerPath);
+
+        foreach (var eventType in GetEventTypes(_configuration.EnabledProviders))
+        {
+            startInfo.ArgumentList.Add(eventType);
+        }
+
+        startInfo.ArgumentList.Add("--format");
+        startInfo.ArgumentList.Add("json");
+
+        return startInfo;
+    }
+
+    /// <summary>
+    /// esloggerの出力1行を解析
+    /// </summary>
+    protected override RawEventData? ParseLine(string line, DateTime timestamp)
+    {
+        var rawEvent = _parser.Parse(line, timestamp);
+
+        // eslogger自体にはPIDフィルタがないため、デーモン自身のイベントはここで除外する
+        return rawEvent?.ProcessId == _ownProcessId ? null : rawEvent;
+    }
+}
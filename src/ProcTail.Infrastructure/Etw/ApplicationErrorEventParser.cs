@@ -0,0 +1,103 @@
+using System.Globalization;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Infrastructure.Etw;
+
+/// <summary>
+/// Windowsエラー報告が記録するアプリケーションのクラッシュイベント（Application Error のイベントID 1000）をRawEventDataに変換する
+/// </summary>
+/// <remarks>
+/// イベントを記録するのはWerFault.exeのため、ETWヘッダーのプロセスIDではなくペイロードのクラッシュしたプロセスのIDをRawEventDataのプロセスIDにする。
+/// ペイロードは順に アプリケーション名, バージョン, タイムスタンプ, モジュール名, バージョン, タイムスタンプ, 例外コード, オフセット,
+/// プロセスID（16進数）, プロセス開始時刻, アプリケーションのパス, モジュールのパス, レポートID, ... の文字列。
+/// </remarks>
+public static class ApplicationErrorEventParser
+{
+    /// <summary>
+    /// クラッシュイベントを記録するプロバイダー名
+    /// </summary>
+    public const string ProviderName = "Application Error";
+
+    /// <summary>
+    /// 変換後のイベント名
+    /// </summary>
+    public const string CrashEventName = "Crash/Detected";
+
+    /// <summary>
+    /// アプリケーションのクラッシュを表すイベントID
+    /// </summary>
+    public const int ApplicationCrashEventId = 1000;
+
+    private const int ApplicationNameIndex = 0;
+    private const int ModuleNameIndex = 3;
+    private const int ExceptionCodeIndex = 6;
+    private const int FaultOffsetIndex = 7;
+    private const int ProcessIdIndex = 8;
+    private const int ApplicationPathIndex = 10;
+    private const int ModulePathIndex = 11;
+    private const int ReportIdIndex = 12;
+
+    /// <summary>
+    /// ペイロードの値をクラッシュイベントに変換
+    /// </summary>
+    /// <param name="values">ペイロードの値（記録順）</param>
+    /// <param name="timestamp">イベント発生時刻</param>
+    /// <param name="threadId">イベントを記録したスレッドID</param>
+    /// <param name="monotonicTimestamp">QPC値</param>
+    /// <returns>クラッシュイベント（プロセスIDや例外コードを読み取れない場合はnull）</returns>
+    public static RawEventData? Parse(IReadOnlyList<object?> values, DateTime timestamp, int threadId = 0, long? monotonicTimestamp = null)
+    {
+        var processIdText = GetString(values, ProcessIdIndex);
+        var exceptionCodeText = GetString(values, ExceptionCodeIndex);
+        if (!TryParseHex(processIdText, out var processId) || processId == 0 || !TryParseHex(exceptionCodeText, out var exceptionCode))
+        {
+            return null;
+        }
+
+        var payload = new Dictionary<string, object>
+        {
+            ["ProcessId"] = (int)processId,
+            ["ExceptionCode"] = (uint)exceptionCode,
+            ["ApplicationName"] = GetString(values, ApplicationNameIndex) ?? string.Empty,
+            ["FaultingModuleName"] = GetString(values, ModuleNameIndex) ?? string.Empty
+        };
+        if (TryParseHex(GetString(values, FaultOffsetIndex), out var faultOffset))
+        {
+            payload["FaultOffset"] = faultOffset;
+        }
+        AddIfPresent(payload, "ApplicationPath", GetString(values, ApplicationPathIndex));
+        AddIfPresent(payload, "FaultingModulePath", GetString(values, ModulePathIndex));
+        AddIfPresent(payload, "ReportId", GetString(values, ReportIdIndex));
+
+        return new RawEventData(timestamp, ProviderName, CrashEventName, (int)processId, threadId, Guid.Empty, Guid.Empty, payload, monotonicTimestamp);
+    }
+
+    private static string? GetString(IReadOnlyList<object?> values, int index)
+    {
+        var value = index < values.Count ? values[index]?.ToString()?.Trim() : null;
+        return string.IsNullOrEmpty(value) ? null : value;
+    }
+
+    private static void AddIfPresent(Dictionary<string, object> payload, string key, string? value)
+    {
+        if (value != null)
+        {
+            payload[key] = value;
+        }
+    }
+
+    /// <summary>
+    /// "0x" の有無を問わず16進数として解釈
+    /// </summary>
+    private static bool TryParseHex(string? text, out ulong value)
+    {
+        value = 0;
+        if (text == null)
+        {
+            return false;
+        }
+
+        var digits = text.StartsWith("0x", StringComparison.OrdinalIgnoreCase) ? text[2..] : text;
+        return ulong.TryParse(digits, NumberStyles.AllowHexSpecifier, CultureInfo.InvariantCulture, out value);
+    }
+}
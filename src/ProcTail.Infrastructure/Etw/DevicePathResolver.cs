@@ -0,0 +1,156 @@
+using System.Diagnostics;
+using System.Runtime.Versioning;
+using System.Text;
+using ProcTail.Infrastructure.Processes;
+
+namespace ProcTail.Infrastructure.Etw;
+
+/// <summary>
+/// カーネルのデバイスパス（\Device\HarddiskVolumeN\...）をドライブレターのパスに変換する
+/// </summary>
+/// <remarks>
+/// リアルタイムセッションではTraceEventがボリュームの対応付けを得られず、デバイスパスのまま届く場合がある。
+/// ドライブレターとデバイス名の対応はQueryDosDeviceで取得し、USBドライブの抜き差しなどでボリューム番号が
+/// 変わる場合に備えて一定間隔で、また未知のデバイスを見つけた場合にも取り直す。
+/// substドライブは別のドライブのディレクトリを指し、ETWには元のボリュームのパスで届くため対応付けに含めない。
+/// \Device\Mup\server\share\... はUNCパス（\\server\share\...）に変換する。
+/// </remarks>
+public class DevicePathResolver
+{
+    private const string DevicePrefix = @"\Device\";
+    private const string MupPrefix = @"\Device\Mup\";
+
+    private static readonly TimeSpan DefaultRefreshInterval = TimeSpan.FromSeconds(30);
+    private static readonly TimeSpan MinimumRefreshOnMissInterval = TimeSpan.FromSeconds(5);
+
+    private readonly Func<IReadOnlyDictionary<string, string>> _loadMappings;
+    private readonly TimeSpan _refreshInterval;
+    private readonly object _lockObject = new();
+    private KeyValuePair<string, string>[] _mappings = Array.Empty<KeyValuePair<string, string>>();
+    private long _loadedAt;
+    private bool _loaded;
+
+    /// <summary>
+    /// コンストラクタ（QueryDosDeviceでドライブレターの対応を取得）
+    /// </summary>
+    [SupportedOSPlatform("windows")]
+    public DevicePathResolver()
+        : this(LoadDosDeviceMappings, DefaultRefreshInterval)
+    {
+    }
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    /// <param name="loadMappings">デバイス名（\Device\HarddiskVolume3）からドライブ（C:）への対応を取得する関数</param>
+    /// <param name="refreshInterval">対応を取り直す間隔</param>
+    public DevicePathResolver(Func<IReadOnlyDictionary<string, string>> loadMappings, TimeSpan refreshInterval)
+    {
+        _loadMappings = loadMappings ?? throw new ArgumentNullException(nameof(loadMappings));
+        _refreshInterval = refreshInterval;
+    }
+
+    /// <summary>
+    /// デバイスパスをドライブレターのパスに変換
+    /// </summary>
+    /// <param name="path">パス</param>
+    /// <returns>変換したパス（デバイスパスでない場合や対応するドライブがない場合はそのまま）</returns>
+    public string Resolve(string path)
+    {
+        if (string.IsNullOrEmpty(path) || !path.StartsWith(DevicePrefix, StringComparison.OrdinalIgnoreCase))
+        {
+            return path;
+        }
+
+        if (path.StartsWith(MupPrefix, StringComparison.OrdinalIgnoreCase))
+        {
+            return @"\\" + path[MupPrefix.Length..];
+        }
+
+        if (IsOlderThan(_refreshInterval))
+        {
+            Refresh();
+        }
+
+        var resolved = TryResolve(path);
+        if (resolved == null && IsOlderThan(MinimumRefreshOnMissInterval))
+        {
+            // 新しくマウントされたボリュームの可能性があるため取り直す
+            Refresh();
+            resolved = TryResolve(path);
+        }
+
+        return resolved ?? path;
+    }
+
+    private string? TryResolve(string path)
+    {
+        foreach (var (device, drive) in _mappings)
+        {
+            if (path.StartsWith(device, StringComparison.OrdinalIgnoreCase) &&
+                (path.Length == device.Length || path[device.Length] == '\\'))
+            {
+                return drive + (path.Length == device.Length ? @"\" : path[device.Length..]);
+            }
+        }
+
+        return null;
+    }
+
+    private bool IsOlderThan(TimeSpan interval)
+    {
+        return !_loaded || Stopwatch.GetElapsedTime(Interlocked.Read(ref _loadedAt)) >= interval;
+    }
+
+    private void Refresh()
+    {
+        lock (_lockObject)
+        {
+            try
+            {
+                // \Device\HarddiskVolume1 が \Device\HarddiskVolume10 に前方一致しないよう、長いデバイス名から照合する
+                _mappings = _loadMappings()
+                    .OrderByDescending(mapping => mapping.Key.Length)
+                    .ToArray();
+            }
+            catch
+            {
+                // 取得に失敗した場合は前回の対応を使い続ける
+            }
+
+            Interlocked.Exchange(ref _loadedAt, Stopwatch.GetTimestamp());
+            _loaded = true;
+        }
+    }
+
+    /// <summary>
+    /// A:〜Z:のデバイス名を取得
+    /// </summary>
+    [SupportedOSPlatform("windows")]
+    private static IReadOnlyDictionary<string, string> LoadDosDeviceMappings()
+    {
+        var mappings = new Dictionary<string, string>(StringComparer.OrdinalIgnoreCase);
+        var buffer = new StringBuilder(1024);
+
+        for (var letter = 'A'; letter <= 'Z'; letter++)
+        {
+            var drive = $"{letter}:";
+            buffer.Clear();
+            if (NativeMethods.QueryDosDevice(drive, buffer, buffer.Capacity) == 0)
+            {
+                continue;
+            }
+
+            // substドライブ（\??\C:\dir）やネットワークドライブ（\Device\LanmanRedirector\;Z:...）は除く
+            var device = buffer.ToString();
+            if (!device.StartsWith(DevicePrefix, StringComparison.OrdinalIgnoreCase) || device.Contains(';'))
+            {
+                continue;
+            }
+
+            mappings.TryAdd(device, drive);
+        }
+
+        return mappings;
+    }
+}
@@ -0,0 +1,108 @@
+using System.Diagnostics;
+using System.Runtime.Versioning;
+using Microsoft.Diagnostics.Tracing.Session;
+using Microsoft.Extensions.Logging;
+
+namespace ProcTail.Infrastructure.Etw;
+
+/// <summary>
+/// ProcTailが作成するETWセッションの命名と検出
+/// </summary>
+/// <remarks>
+/// セッション名は "ProcTail_{PID}_{ランダム8文字}" とし、作成したプロセスが終了しても残っているセッションを
+/// 名前のPIDから孤立セッションとして判定する。以前のバージョンが使っていた "PT_FileIO_{PID}" / "PT_Process_{PID}" も対象とする。
+/// 孤立セッションのバッファには前回のプロセスのイベントしか残っていないため、引き継がずに停止する。
+/// </remarks>
+internal static class ProcTailEtwSessions
+{
+    /// <summary>
+    /// セッション名の接頭辞
+    /// </summary>
+    public const string SessionNamePrefix = "ProcTail_";
+
+    /// <summary>
+    /// 以前のバージョンのセッション名の接頭辞
+    /// </summary>
+    private static readonly string[] LegacySessionNamePrefixes = { "PT_FileIO_", "PT_Process_" };
+
+    /// <summary>
+    /// 現在のプロセス用の新しいセッション名を作成
+    /// </summary>
+    public static string CreateSessionName()
+    {
+        return $"{SessionNamePrefix}{Environment.ProcessId}_{Guid.NewGuid().ToString("N")[..8]}";
+    }
+
+    /// <summary>
+    /// 作成したプロセスが既に終了しているProcTailのセッション名を取得
+    /// </summary>
+    [SupportedOSPlatform("windows")]
+    public static IReadOnlyList<string> FindOrphanedSessionNames()
+    {
+        return TraceEventSession.GetActiveSessionNames()
+            .Where(name => TryGetOwnerProcessId(name, out var processId) && processId != Environment.ProcessId && !IsProcessRunning(processId))
+            .ToList();
+    }
+
+    /// <summary>
+    /// 孤立セッションを停止
+    /// </summary>
+    /// <param name="logger">ロガー</param>
+    /// <returns>停止したセッション数</returns>
+    [SupportedOSPlatform("windows")]
+    public static int StopOrphanedSessions(ILogger logger)
+    {
+        var stoppedCount = 0;
+        foreach (var sessionName in FindOrphanedSessionNames())
+        {
+            try
+            {
+                using var session = TraceEventSession.GetActiveSession(sessionName);
+                if (session != null && session.Stop(noThrow: true))
+                {
+                    stoppedCount++;
+                    logger.LogInformation("前回のプロセスが残したETWセッションを停止しました (Session: {SessionName})", sessionName);
+                }
+            }
+            catch (Exception ex)
+            {
+                logger.LogWarning(ex, "孤立ETWセッションを停止できませんでした (Session: {SessionName})", sessionName);
+            }
+        }
+
+        return stoppedCount;
+    }
+
+    /// <summary>
+    /// セッション名から作成したプロセスのIDを取得
+    /// </summary>
+    public static bool TryGetOwnerProcessId(string sessionName, out int processId)
+    {
+        processId = 0;
+        if (sessionName.StartsWith(SessionNamePrefix, StringComparison.Ordinal))
+        {
+            var parts = sessionName[SessionNamePrefix.Length..].Split('_');
+            return parts.Length == 2 && int.TryParse(parts[0], out processId);
+        }
+
+        var legacyPrefix = LegacySessionNamePrefixes.FirstOrDefault(prefix => sessionName.StartsWith(prefix, StringComparison.Ordinal));
+        return legacyPrefix != null && int.TryParse(sessionName[legacyPrefix.Length..], out processId);
+    }
+
+    private static bool IsProcessRunning(int processId)
+    {
+        try
+        {
+            using var process = Process.GetProcessById(processId);
+            return !process.HasExited;
+        }
+        catch (ArgumentException)
+        {
+            return false;
+        }
+        catch (InvalidOperationException)
+        {
+            return false;
+        }
+    }
+}
@@ -1,6 +1,7 @@
 using Microsoft.Extensions.Logging;
 using ProcTail.Core.Interfaces;
 using ProcTail.Core.Models;
+using ProcTail.Infrastructure.Files;
 using System.Collections.Concurrent;
 using System.Runtime.InteropServices;
 using System.Runtime.Versioning;
@@ -16,28 +17,67 @@ namespace ProcTail.Infrastructure.Etw;
 /// 実際のWindows ETWを使用したイベントプロバイダー
 /// </summary>
 [SupportedOSPlatform("windows")]
-public class WindowsEtwEventProvider : IEtwEventProvider, IDisposable
+public class WindowsEtwEventProvider : IEtwEventProvider, IRawEventSource, IDisposable
 {
+    private const string FileIOProviderName = "Microsoft-Windows-Kernel-FileIO";
+    private const string RegistryProviderName = "Microsoft-Windows-Kernel-Registry";
+    private const string NetworkProviderName = "Microsoft-Windows-Kernel-Network";
+    private const string DnsProviderName = "Microsoft-Windows-DNS-Client";
+    private const string ImageProviderName = "Microsoft-Windows-Kernel-Image";
+    private const string ThreadProviderName = "Microsoft-Windows-Kernel-Thread";
+
+    // DNS-Clientの「クエリ完了」イベント（QueryName, QueryStatus, QueryResultsを含む）
+    private const int DnsQueryCompletedEventId = 3008;
+
     private readonly ILogger<WindowsEtwEventProvider> _logger;
     private readonly IEtwConfiguration _configuration;
     private readonly CancellationTokenSource _cancellationTokenSource = new();
     private readonly List<TraceEventSession> _sessions = new();
     private readonly List<Task> _sessionTasks = new();
     private readonly ConcurrentQueue<RawEventData> _eventQueue = new();
+    private readonly DevicePathResolver _devicePathResolver = new();
+    private readonly PathCanonicalizer _pathCanonicalizer = new();
     private bool _isMonitoring;
     private bool _disposed;
     private Task? _eventProcessingTask;
+    private ClockMapping _clockMapping = ClockMapping.Capture();
+    private long _stoppedSessionsEventsLost;
 
     /// <summary>
     /// ETWイベント受信時に発火するイベント
     /// </summary>
     public event EventHandler<RawEventData>? EventReceived;
 
+    /// <summary>
+    /// 生イベント受信時に発火するイベント
+    /// </summary>
+    public event EventHandler<RawEtwRecord>? RawRecordReceived;
+
     /// <summary>
     /// 監視中かどうか
     /// </summary>
     public bool IsMonitoring => _isMonitoring;
 
+    /// <summary>
+    /// 生イベントの配信が有効かどうか
+    /// </summary>
+    public bool IsRawPassthroughEnabled => _configuration.EnableRawPassthrough;
+
+    /// <summary>
+    /// 生イベントを配信するプロセスの判定
+    /// </summary>
+    public Func<int, bool>? RawRecordFilter { get; set; }
+
+    /// <summary>
+    /// ETWセッション開始時点のQPCと壁時計の対応付け
+    /// </summary>
+    public ClockMapping ClockMapping => _clockMapping;
+
+    /// <summary>
+    /// ETWのバッファ溢れで失われたイベントの累計（停止済みセッションの分を含む）
+    /// </summary>
+    public long EventsLost => Interlocked.Read(ref _stoppedSessionsEventsLost) + _sessions.ToArray().Sum(session => (long)session.EventsLost);
+
     /// <summary>
     /// コンストラクタ
     /// </summary>
@@ -83,18 +123,23 @@ public class WindowsEtwEventProvider : IEtwEventProvider, IDisposable
             
             _logger.LogInformation("管理者権限が確認されました");
 
-            // 軽量クリーンアップを実行
+            // 前回のプロセスがクラッシュして残したセッションを停止（セッション数の上限を消費し続けるため）
             try
             {
-                _logger.LogDebug("ETWリソース軽量クリーンアップを実行中...");
-                CleanupProcTailSessions(); // ProcTail関連のみ
+                _logger.LogDebug("孤立ETWセッションを確認中...");
+                var stoppedCount = ProcTailEtwSessions.StopOrphanedSessions(_logger);
+                if (stoppedCount > 0)
+                {
+                    _logger.LogInformation("孤立ETWセッション {Count} 個を停止しました", stoppedCount);
+                }
             }
             catch (Exception cleanupEx)
             {
-                _logger.LogWarning(cleanupEx, "軽量クリーンアップ中に警告が発生しました");
+                _logger.LogWarning(cleanupEx, "孤立ETWセッションの確認中に警告が発生しました");
             }
 
-            // ETWセッションを作成
+            // ETWセッションを作成（イベントのQPC値はこの時点を基準に時刻へ換算できる）
+            _clockMapping = ClockMapping.Capture();
             await CreateEtwSessionsAsync(cancellationToken);
 
             // イベント処理タスクを開始
@@ -149,6 +194,7 @@ public class WindowsEtwEventProvider : IEtwEventProvider, IDisposable
             {
                 try
                 {
+                    Interlocked.Add(ref _stoppedSessionsEventsLost, session?.EventsLost ?? 0);
                     session?.Stop();
                     session?.Dispose();
                 }
@@ -241,7 +287,7 @@ public class WindowsEtwEventProvider : IEtwEventProvider, IDisposable
     /// </summary>
     private TraceEventSession? CreateKernelTraceSession()
     {
-        var sessionName = $"ProcTail_{Environment.ProcessId}_{Guid.NewGuid().ToString("N")[..8]}";
+        var sessionName = ProcTailEtwSessions.CreateSessionName();
         
         try
         {
@@ -261,14 +307,46 @@ public class WindowsEtwEventProvider : IEtwEventProvider, IDisposable
             
             var session = new TraceEventSession(sessionName);
             
-            // リソース制約対応：FileIOとProcessを有効にするが、Readは除外してリソース消費を削減
-            _logger.LogTrace("最軽量ファイル監視を有効化中...");
-            session.EnableKernelProvider(
-                KernelTraceEventParser.Keywords.FileIOInit | 
-                KernelTraceEventParser.Keywords.FileIO |
-                KernelTraceEventParser.Keywords.Process);  // FileIOInitとFileIOとProcessでファイル操作イベントを監視
+            var keywords = GetKernelKeywords();
+            session.EnableKernelProvider(keywords);
             
-            _logger.LogInformation("カーネルプロバイダーを有効にしました (FileIOInit + FileIO + Process)");
+            _logger.LogInformation("カーネルプロバイダーを有効にしました ({Keywords})", keywords);
+
+            // DNS-Clientはユーザーモードプロバイダーのため、同じセッションに追加で有効化する
+            if (IsProviderEnabled(DnsProviderName))
+            {
+                try
+                {
+                    var (level, matchAnyKeywords) = GetProviderLevelAndKeywords(DnsProviderName);
+                    session.EnableProvider(DnsProviderName, level, matchAnyKeywords);
+                    _logger.LogInformation("DNS-Clientプロバイダーを有効にしました (Level: {Level}, Keywords: 0x{Keywords:X})", level, matchAnyKeywords);
+                }
+                catch (Exception ex)
+                {
+                    _logger.LogWarning(ex, "DNS-Clientプロバイダーを有効にできませんでした。ホスト名の解決は行われません");
+                }
+            }
+
+            // Windowsエラー報告が記録するクラッシュイベント（Application Error）もユーザーモードプロバイダーとして有効化する
+            if (IsProviderEnabled(ApplicationErrorEventParser.ProviderName))
+            {
+                try
+                {
+                    var (level, matchAnyKeywords) = GetProviderLevelAndKeywords(ApplicationErrorEventParser.ProviderName);
+                    session.EnableProvider(ApplicationErrorEventParser.ProviderName, level, matchAnyKeywords);
+                    _logger.LogInformation("Application Errorプロバイダーを有効にしました (Level: {Level}, Keywords: 0x{Keywords:X})", level, matchAnyKeywords);
+                }
+                catch (Exception ex)
+                {
+                    _logger.LogWarning(ex, "Application Errorプロバイダーを有効にできませんでした。クラッシュは検出されません");
+                }
+            }
+
+            // 生イベントのパススルー用に指定されたプロバイダーを追加で有効化する
+            if (IsRawPassthroughEnabled)
+            {
+                EnableRawPassthroughProviders(session);
+            }
             
             // イベントハンドラーを設定
             SetupKernelEventHandlers(session);
@@ -299,6 +377,83 @@ public class WindowsEtwEventProvider : IEtwEventProvider, IDisposable
         }
     }
 
+    /// <summary>
+    /// カーネルセッションで有効にするキーワードを決定
+    /// </summary>
+    /// <remarks>
+    /// 設定でキーワードを指定した場合はそれを使い、指定がない場合は有効なプロバイダーから決める。
+    /// 監視対象の子プロセス追跡にプロセスの開始・終了が必要なため、Processはどちらの場合も有効にする。
+    /// </remarks>
+    private KernelTraceEventParser.Keywords GetKernelKeywords()
+    {
+        var keywords = KernelTraceEventParser.Keywords.Process;
+
+        if (_configuration.KernelKeywords.Count > 0)
+        {
+            foreach (var name in _configuration.KernelKeywords)
+            {
+                if (Enum.TryParse<KernelTraceEventParser.Keywords>(name, ignoreCase: true, out var keyword))
+                {
+                    keywords |= keyword;
+                }
+                else
+                {
+                    _logger.LogWarning("不明なカーネルキーワードを無視します: {Keyword}", name);
+                }
+            }
+
+            return keywords;
+        }
+
+        // FileIOInitとFileIOでファイル操作イベントを監視
+        if (IsProviderEnabled(FileIOProviderName))
+        {
+            keywords |= KernelTraceEventParser.Keywords.FileIOInit | KernelTraceEventParser.Keywords.FileIO;
+        }
+
+        // レジストリ・ネットワーク・イメージロード・スレッドはプロバイダーが有効な場合のみ購読
+        if (IsProviderEnabled(RegistryProviderName))
+        {
+            keywords |= KernelTraceEventParser.Keywords.Registry;
+        }
+
+        if (IsProviderEnabled(NetworkProviderName))
+        {
+            keywords |= KernelTraceEventParser.Keywords.NetworkTCPIP;
+        }
+
+        if (IsProviderEnabled(ImageProviderName))
+        {
+            keywords |= KernelTraceEventParser.Keywords.ImageLoad;
+        }
+
+        if (IsProviderEnabled(ThreadProviderName))
+        {
+            keywords |= KernelTraceEventParser.Keywords.Thread;
+        }
+
+        return keywords;
+    }
+
+    /// <summary>
+    /// ユーザーモードプロバイダーを購読するレベルとキーワードを取得
+    /// </summary>
+    private (TraceEventLevel Level, ulong MatchAnyKeywords) GetProviderLevelAndKeywords(string providerName)
+    {
+        if (!_configuration.ProviderSettings.TryGetValue(providerName, out var settings))
+        {
+            return (TraceEventLevel.Verbose, ulong.MaxValue);
+        }
+
+        if (!Enum.TryParse<TraceEventLevel>(settings.Level, ignoreCase: true, out var level))
+        {
+            _logger.LogWarning("不明なレベルのためVerboseで購読します (Provider: {Provider}, Level: {Level})", providerName, settings.Level);
+            level = TraceEventLevel.Verbose;
+        }
+
+        return (level, settings.MatchAnyKeywords);
+    }
+
     /// <summary>
     /// 既存のProcTail ETWセッションをクリーンアップ
     /// </summary>
@@ -505,24 +660,233 @@ public class WindowsEtwEventProvider : IEtwEventProvider, IDisposable
     /// </summary>
     private void SetupKernelEventHandlers(TraceEventSession session)
     {
-        // ファイルI/Oイベント
-        session.Source.Kernel.FileIOCreate += OnFileIOEvent;
-        session.Source.Kernel.FileIOWrite += OnFileIOEvent;
-        session.Source.Kernel.FileIORead += OnFileIOEvent;
-        session.Source.Kernel.FileIODelete += OnFileIOEvent;
-        session.Source.Kernel.FileIORename += OnFileIOEvent;
-        session.Source.Kernel.FileIOSetInfo += OnFileIOEvent;
-        session.Source.Kernel.FileIOClose += OnFileIOEvent;
-        _logger.LogDebug("FileIOイベントハンドラーを設定しました (Create, Write, Read, Delete, Rename, SetInfo, Close)");
+        // ファイルI/Oイベント（イベント名ごとに設定で無効化できる）
+        if (IsProviderEnabled(FileIOProviderName))
+        {
+            if (IsEventEnabled("FileIO/Create"))
+            {
+                session.Source.Kernel.FileIOCreate += OnFileIOEvent;
+            }
+            if (IsEventEnabled("FileIO/Write"))
+            {
+                session.Source.Kernel.FileIOWrite += OnFileIOEvent;
+            }
+            if (IsEventEnabled("FileIO/Read"))
+            {
+                session.Source.Kernel.FileIORead += OnFileIOEvent;
+            }
+            if (IsEventEnabled("FileIO/Delete"))
+            {
+                session.Source.Kernel.FileIODelete += OnFileIOEvent;
+            }
+            if (IsEventEnabled("FileIO/Rename"))
+            {
+                session.Source.Kernel.FileIORename += OnFileIOEvent;
+            }
+            if (IsEventEnabled("FileIO/SetInfo"))
+            {
+                session.Source.Kernel.FileIOSetInfo += OnFileIOEvent;
+            }
+            if (IsEventEnabled("FileIO/Close"))
+            {
+                session.Source.Kernel.FileIOClose += OnFileIOEvent;
+            }
+            _logger.LogDebug("FileIOイベントハンドラーを設定しました");
+        }
         
-        // プロセスイベント
+        // プロセスイベント（監視対象の追跡に必要なため常に購読）
         session.Source.Kernel.ProcessStart += OnProcessEvent;
         session.Source.Kernel.ProcessStop += OnProcessEvent;
         _logger.LogDebug("プロセスイベントハンドラーを設定しました (Start, Stop)");
+
+        // レジストリイベント（Query系は高頻度のため除外）
+        if (IsProviderEnabled(RegistryProviderName))
+        {
+            if (IsEventEnabled("Registry/Create"))
+            {
+                session.Source.Kernel.RegistryCreate += OnRegistryEvent;
+            }
+            if (IsEventEnabled("Registry/Open"))
+            {
+                session.Source.Kernel.RegistryOpen += OnRegistryEvent;
+            }
+            if (IsEventEnabled("Registry/Delete"))
+            {
+                session.Source.Kernel.RegistryDelete += OnRegistryEvent;
+            }
+            if (IsEventEnabled("Registry/SetValue"))
+            {
+                session.Source.Kernel.RegistrySetValue += OnRegistryEvent;
+            }
+            if (IsEventEnabled("Registry/DeleteValue"))
+            {
+                session.Source.Kernel.RegistryDeleteValue += OnRegistryEvent;
+            }
+            _logger.LogDebug("レジストリイベントハンドラーを設定しました");
+        }
+
+        // ネットワークイベント（IPv4/IPv6）
+        if (IsProviderEnabled(NetworkProviderName))
+        {
+            if (IsEventEnabled("TcpIp/Connect"))
+            {
+                session.Source.Kernel.TcpIpConnect += OnNetworkEvent;
+                session.Source.Kernel.TcpIpConnectIPV6 += OnNetworkEvent;
+            }
+            if (IsEventEnabled("TcpIp/Accept"))
+            {
+                session.Source.Kernel.TcpIpAccept += OnNetworkEvent;
+                session.Source.Kernel.TcpIpAcceptIPV6 += OnNetworkEvent;
+            }
+            if (IsEventEnabled("TcpIp/Send"))
+            {
+                session.Source.Kernel.TcpIpSend += OnNetworkEvent;
+                session.Source.Kernel.TcpIpSendIPV6 += OnNetworkEvent;
+            }
+            if (IsEventEnabled("TcpIp/Recv"))
+            {
+                session.Source.Kernel.TcpIpRecv += OnNetworkEvent;
+                session.Source.Kernel.TcpIpRecvIPV6 += OnNetworkEvent;
+            }
+            if (IsEventEnabled("UdpIp/Send"))
+            {
+                session.Source.Kernel.UdpIpSend += OnNetworkEvent;
+                session.Source.Kernel.UdpIpSendIPV6 += OnNetworkEvent;
+            }
+            if (IsEventEnabled("UdpIp/Recv"))
+            {
+                session.Source.Kernel.UdpIpRecv += OnNetworkEvent;
+                session.Source.Kernel.UdpIpRecvIPV6 += OnNetworkEvent;
+            }
+            _logger.LogDebug("ネットワークイベントハンドラーを設定しました");
+        }
+
+        // イメージ（EXE/DLL）ロードイベント
+        if (IsProviderEnabled(ImageProviderName))
+        {
+            if (IsEventEnabled("Image/Load"))
+            {
+                session.Source.Kernel.ImageLoad += OnImageLoadEvent;
+            }
+            if (IsEventEnabled("Image/Unload"))
+            {
+                session.Source.Kernel.ImageUnload += OnImageLoadEvent;
+            }
+            _logger.LogDebug("イメージロードイベントハンドラーを設定しました");
+        }
+
+        // スレッドイベント（記録するかどうかはタグごとのオプションで判定）
+        if (IsProviderEnabled(ThreadProviderName))
+        {
+            if (IsEventEnabled("Thread/Start"))
+            {
+                session.Source.Kernel.ThreadStart += OnThreadEvent;
+            }
+            if (IsEventEnabled("Thread/End"))
+            {
+                session.Source.Kernel.ThreadStop += OnThreadEvent;
+            }
+            _logger.LogDebug("スレッドイベントハンドラーを設定しました");
+        }
+
+        // DNSクエリイベント
+        if (IsProviderEnabled(DnsProviderName))
+        {
+            session.Source.Dynamic.AddCallbackForProviderEvent(DnsProviderName, null, OnDnsEvent);
+            _logger.LogDebug("DNSイベントハンドラーを設定しました");
+        }
         
+        // クラッシュイベント（マニフェストはOSに登録されているため、登録済みプロバイダーのパーサーで解析する）
+        if (IsProviderEnabled(ApplicationErrorEventParser.ProviderName))
+        {
+            var registeredParser = new RegisteredTraceEventParser(session.Source);
+            registeredParser.AddCallbackForProviderEvent(ApplicationErrorEventParser.ProviderName, null, OnApplicationErrorEvent);
+            _logger.LogDebug("クラッシュイベントハンドラーを設定しました");
+        }
+
         // 汎用イベントハンドラー
         session.Source.UnhandledEvents += OnUnhandledEvent;
         _logger.LogDebug("未処理イベントハンドラーを設定しました");
+
+        // 生イベントのパススルー（カーネルとマニフェストベースのプロバイダーの全イベント）
+        if (IsRawPassthroughEnabled)
+        {
+            session.Source.Kernel.All += OnRawEvent;
+            session.Source.Dynamic.All += OnRawEvent;
+            _logger.LogDebug("生イベントのパススルーハンドラーを設定しました");
+        }
+    }
+
+    /// <summary>
+    /// 生イベントのパススルー用のプロバイダーを有効化
+    /// </summary>
+    private void EnableRawPassthroughProviders(TraceEventSession session)
+    {
+        foreach (var provider in _configuration.RawPassthroughProviders)
+        {
+            try
+            {
+                var (level, matchAnyKeywords) = GetProviderLevelAndKeywords(provider);
+                if (Guid.TryParse(provider, out var providerGuid))
+                {
+                    session.EnableProvider(providerGuid, level, matchAnyKeywords);
+                }
+                else
+                {
+                    session.EnableProvider(provider, level, matchAnyKeywords);
+                }
+                _logger.LogInformation("生イベント用のプロバイダーを有効にしました (Provider: {Provider}, Level: {Level})", provider, level);
+            }
+            catch (Exception ex)
+            {
+                _logger.LogWarning(ex, "生イベント用のプロバイダーを有効にできませんでした (Provider: {Provider})", provider);
+            }
+        }
+    }
+
+    /// <summary>
+    /// 生イベントハンドラー（監視対象プロセスのイベントを変換せずに配信）
+    /// </summary>
+    private void OnRawEvent(TraceEvent data)
+    {
+        if (_cancellationTokenSource.Token.IsCancellationRequested)
+            return;
+
+        var filter = RawRecordFilter;
+        if (filter == null || RawRecordReceived == null || !filter(data.ProcessID))
+            return;
+
+        try
+        {
+            var payload = new Dictionary<string, object>();
+            for (int i = 0; i < data.PayloadNames.Length; i++)
+            {
+                // シリアライズできない型は文字列にする
+                var value = data.PayloadValue(i);
+                payload[data.PayloadNames[i]] = value is string or byte[] || value?.GetType().IsPrimitive == true
+                    ? value
+                    : value?.ToString() ?? string.Empty;
+            }
+
+            RawRecordReceived?.Invoke(this, new RawEtwRecord(
+                0,
+                data.TimeStamp,
+                data.TimeStampQPC,
+                data.ProviderGuid,
+                data.ProviderName,
+                (int)data.ID,
+                (int)data.Opcode,
+                data.OpcodeName,
+                data.EventName,
+                data.ProcessID,
+                data.ThreadID,
+                payload
+            ));
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "生イベント処理中にエラーが発生しました");
+        }
     }
 
 
@@ -548,6 +912,8 @@ public class WindowsEtwEventProvider : IEtwEventProvider, IDisposable
                 payload[name] = value ?? string.Empty;
             }
 
+            NormalizePaths(payload);
+
             // ファイルパス情報を取得
             var fileName = payload.ContainsKey("FileName") ? payload["FileName"].ToString() : "Unknown";
             
@@ -569,7 +935,8 @@ public class WindowsEtwEventProvider : IEtwEventProvider, IDisposable
                 data.ThreadID,
                 data.ActivityID,
                 data.RelatedActivityID,
-                payload
+                payload,
+                data.TimeStampQPC
             );
 
             _eventQueue.Enqueue(rawEvent);
@@ -626,7 +993,8 @@ public class WindowsEtwEventProvider : IEtwEventProvider, IDisposable
                 data.ThreadID,
                 data.ActivityID,
                 data.RelatedActivityID,
-                payload
+                payload,
+                data.TimeStampQPC
             );
 
             _eventQueue.Enqueue(rawEvent);
@@ -637,6 +1005,332 @@ public class WindowsEtwEventProvider : IEtwEventProvider, IDisposable
         }
     }
 
+    /// <summary>
+    /// レジストリイベントハンドラー
+    /// </summary>
+    private void OnRegistryEvent(RegistryTraceData data)
+    {
+        if (_cancellationTokenSource.Token.IsCancellationRequested)
+            return;
+
+        try
+        {
+            var payload = new Dictionary<string, object>();
+            
+            // TraceEventからペイロード情報を取得
+            for (int i = 0; i < data.PayloadNames.Length; i++)
+            {
+                var name = data.PayloadNames[i];
+                var value = data.PayloadValue(i);
+                payload[name] = value ?? string.Empty;
+            }
+
+            // TraceEventがKCBから解決したフルパスで上書き
+            payload["KeyName"] = data.KeyName ?? string.Empty;
+            payload["ValueName"] = data.ValueName ?? string.Empty;
+
+            _logger.LogTrace("Registryイベントを受信: {EventName}, ProcessId: {ProcessId}, KeyName: {KeyName}", 
+                data.EventName, data.ProcessID, data.KeyName);
+
+            // イベント名を適切にフォーマット
+            var formattedEventName = data.EventName;
+            if (formattedEventName.StartsWith("Registry") && !formattedEventName.Contains("/"))
+            {
+                formattedEventName = $"Registry/{formattedEventName.Substring(8)}"; // RegistrySetValue -> Registry/SetValue
+            }
+            
+            var rawEvent = new RawEventData(
+                data.TimeStamp,
+                RegistryProviderName,
+                formattedEventName,
+                data.ProcessID,
+                data.ThreadID,
+                data.ActivityID,
+                data.RelatedActivityID,
+                payload,
+                data.TimeStampQPC
+            );
+
+            _eventQueue.Enqueue(rawEvent);
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "レジストリイベント処理中にエラーが発生しました");
+        }
+    }
+
+    /// <summary>
+    /// ネットワークイベントハンドラー
+    /// </summary>
+    private void OnNetworkEvent(TraceEvent data)
+    {
+        if (_cancellationTokenSource.Token.IsCancellationRequested)
+            return;
+
+        try
+        {
+            var payload = new Dictionary<string, object>();
+            
+            // TraceEventからペイロード情報を取得（IPAddressはJSONシリアライズできないため文字列化）
+            for (int i = 0; i < data.PayloadNames.Length; i++)
+            {
+                var name = data.PayloadNames[i];
+                var value = data.PayloadValue(i);
+                payload[name] = value is System.Net.IPAddress address ? address.ToString() : value ?? string.Empty;
+            }
+
+            // TCP/IPイベントのヘッダーPIDは信頼できないため、ペイロードのPIDを優先
+            var processId = payload.TryGetValue("PID", out var pidValue) && int.TryParse(pidValue.ToString(), out var pid) && pid > 0
+                ? pid
+                : data.ProcessID;
+
+            // TcpIp/ConnectIPV6 -> TcpIp/Connect（IPv6かどうかはアドレスで判別できる）
+            var formattedEventName = data.EventName.EndsWith("IPV6")
+                ? data.EventName[..^4]
+                : data.EventName;
+
+            _logger.LogTrace("ネットワークイベントを受信: {EventName}, ProcessId: {ProcessId}", formattedEventName, processId);
+
+            var rawEvent = new RawEventData(
+                data.TimeStamp,
+                NetworkProviderName,
+                formattedEventName,
+                processId,
+                data.ThreadID,
+                data.ActivityID,
+                data.RelatedActivityID,
+                payload,
+                data.TimeStampQPC
+            );
+
+            _eventQueue.Enqueue(rawEvent);
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "ネットワークイベント処理中にエラーが発生しました");
+        }
+    }
+
+    /// <summary>
+    /// ペイロードのパスを正規化（デバイスパスをドライブレターのパスに変換し、8.3形式の短い名前と大文字小文字を正式な長いパスに揃える）
+    /// </summary>
+    private void NormalizePaths(Dictionary<string, object> payload)
+    {
+        foreach (var (name, value) in payload.ToArray())
+        {
+            if (value is not string path || path.Length < 3)
+            {
+                continue;
+            }
+
+            if (path.StartsWith(@"\Device\", StringComparison.OrdinalIgnoreCase))
+            {
+                path = _devicePathResolver.Resolve(path);
+            }
+
+            payload[name] = _pathCanonicalizer.Canonicalize(path);
+        }
+    }
+
+    /// <summary>
+    /// イメージロードイベントハンドラー
+    /// </summary>
+    private void OnImageLoadEvent(ImageLoadTraceData data)
+    {
+        if (_cancellationTokenSource.Token.IsCancellationRequested)
+            return;
+
+        try
+        {
+            var payload = new Dictionary<string, object>();
+            
+            // TraceEventからペイロード情報を取得
+            for (int i = 0; i < data.PayloadNames.Length; i++)
+            {
+                var name = data.PayloadNames[i];
+                var value = data.PayloadValue(i);
+                payload[name] = value ?? string.Empty;
+            }
+
+            // デバイスパスからドライブレター形式に変換済みのパスで上書き
+            payload["FileName"] = data.FileName ?? string.Empty;
+            payload["ImageBase"] = data.ImageBase;
+            payload["ImageSize"] = data.ImageSize;
+            NormalizePaths(payload);
+
+            _logger.LogTrace("Imageイベントを受信: {EventName}, ProcessId: {ProcessId}, FileName: {FileName}", 
+                data.EventName, data.ProcessID, data.FileName);
+
+            // イベント名を適切にフォーマット
+            var formattedEventName = data.EventName;
+            if (formattedEventName.StartsWith("Image") && !formattedEventName.Contains("/"))
+            {
+                formattedEventName = $"Image/{formattedEventName.Substring(5)}"; // ImageLoad -> Image/Load
+            }
+
+            var rawEvent = new RawEventData(
+                data.TimeStamp,
+                ImageProviderName,
+                formattedEventName,
+                data.ProcessID,
+                data.ThreadID,
+                data.ActivityID,
+                data.RelatedActivityID,
+                payload,
+                data.TimeStampQPC
+            );
+
+            _eventQueue.Enqueue(rawEvent);
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "イメージロードイベント処理中にエラーが発生しました");
+        }
+    }
+
+    /// <summary>
+    /// スレッドイベントハンドラー
+    /// </summary>
+    private void OnThreadEvent(ThreadTraceData data)
+    {
+        if (_cancellationTokenSource.Token.IsCancellationRequested)
+            return;
+
+        try
+        {
+            var payload = new Dictionary<string, object>();
+            
+            // TraceEventからペイロード情報を取得
+            for (int i = 0; i < data.PayloadNames.Length; i++)
+            {
+                var name = data.PayloadNames[i];
+                var value = data.PayloadValue(i);
+                payload[name] = value ?? string.Empty;
+            }
+
+            // プロセスイベントに合わせてStopはEndとして扱う
+            var formattedEventName = data.OpcodeName == "Stop" ? "Thread/End" : $"Thread/{data.OpcodeName}";
+
+            var rawEvent = new RawEventData(
+                data.TimeStamp,
+                ThreadProviderName,
+                formattedEventName,
+                data.ProcessID,
+                data.ThreadID,
+                data.ActivityID,
+                data.RelatedActivityID,
+                payload,
+                data.TimeStampQPC
+            );
+
+            _eventQueue.Enqueue(rawEvent);
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "スレッドイベント処理中にエラーが発生しました");
+        }
+    }
+
+    /// <summary>
+    /// DNSクエリイベントハンドラー
+    /// </summary>
+    private void OnDnsEvent(TraceEvent data)
+    {
+        if (_cancellationTokenSource.Token.IsCancellationRequested)
+            return;
+
+        // 結果を含むのはクエリ完了イベントのみ
+        if ((int)data.ID != DnsQueryCompletedEventId)
+            return;
+
+        try
+        {
+            var payload = new Dictionary<string, object>();
+            
+            // TraceEventからペイロード情報を取得
+            for (int i = 0; i < data.PayloadNames.Length; i++)
+            {
+                var name = data.PayloadNames[i];
+                var value = data.PayloadValue(i);
+                payload[name] = value ?? string.Empty;
+            }
+
+            _logger.LogTrace("DNSイベントを受信: ProcessId: {ProcessId}, QueryName: {QueryName}", 
+                data.ProcessID, payload.GetValueOrDefault("QueryName"));
+
+            var rawEvent = new RawEventData(
+                data.TimeStamp,
+                DnsProviderName,
+                "Dns/QueryCompleted",
+                data.ProcessID,
+                data.ThreadID,
+                data.ActivityID,
+                data.RelatedActivityID,
+                payload,
+                data.TimeStampQPC
+            );
+
+            _eventQueue.Enqueue(rawEvent);
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "DNSイベント処理中にエラーが発生しました");
+        }
+    }
+
+    /// <summary>
+    /// クラッシュイベントハンドラー（ETWヘッダーのプロセスはWerFault.exeのため、ペイロードのプロセスIDで配信）
+    /// </summary>
+    private void OnApplicationErrorEvent(TraceEvent data)
+    {
+        if (_cancellationTokenSource.Token.IsCancellationRequested)
+            return;
+
+        if ((int)data.ID != ApplicationErrorEventParser.ApplicationCrashEventId)
+            return;
+
+        try
+        {
+            var values = new object?[data.PayloadNames.Length];
+            for (int i = 0; i < values.Length; i++)
+            {
+                values[i] = data.PayloadValue(i);
+            }
+
+            var rawEvent = ApplicationErrorEventParser.Parse(values, data.TimeStamp, data.ThreadID, data.TimeStampQPC);
+            if (rawEvent == null)
+            {
+                _logger.LogDebug("クラッシュイベントのプロセスIDまたは例外コードを読み取れませんでした (Payload: {Payload})", string.Join(", ", values));
+                return;
+            }
+
+            _logger.LogInformation("クラッシュを検出しました (ProcessId: {ProcessId}, Module: {Module}, ExceptionCode: 0x{ExceptionCode:X8})",
+                rawEvent.ProcessId, rawEvent.Payload["FaultingModuleName"], rawEvent.Payload["ExceptionCode"]);
+
+            _eventQueue.Enqueue(rawEvent);
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "クラッシュイベント処理中にエラーが発生しました");
+        }
+    }
+
+    /// <summary>
+    /// プロバイダーが有効かどうか
+    /// </summary>
+    private bool IsProviderEnabled(string providerName)
+    {
+        return _configuration.EnabledProviders.Contains(providerName);
+    }
+
+    /// <summary>
+    /// イベントが有効かどうか
+    /// </summary>
+    private bool IsEventEnabled(string eventName)
+    {
+        return _configuration.EnabledEventNames.Contains(eventName);
+    }
+
     /// <summary>
     /// 未処理イベントハンドラー
     /// </summary>
@@ -679,7 +1373,8 @@ public class WindowsEtwEventProvider : IEtwEventProvider, IDisposable
                 data.ThreadID,
                 data.ActivityID,
                 data.RelatedActivityID,
-                payload
+                payload,
+                data.TimeStampQPC
             );
 
             _eventQueue.Enqueue(rawEvent);
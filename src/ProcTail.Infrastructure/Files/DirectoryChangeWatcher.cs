@@ -0,0 +1,236 @@
+using System.Diagnostics;
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Infrastructure.Files;
+
+/// <summary>
+/// タグごとにディレクトリの変更を監視するウォッチャー
+/// </summary>
+/// <remarks>
+/// WindowsではFileSystemWatcherがReadDirectoryChangesWを使うため、ETWのファイルI/Oが届かないネットワークドライブや、
+/// ETWを使えない環境でもファイルの作成・書き込み・削除・リネームを記録できる。
+/// 変更通知を持たないファイルシステム（WSLのマウントやFUSEなど）は、ポーリングで同じ形式のイベントを生成する。
+/// 変更したプロセスは分からないため、イベントはPIDを0としてタグを直接指定し、ペイロードのSourceに取得元を入れる。
+/// </remarks>
+public class DirectoryChangeWatcher : IDirectoryChangeWatcher
+{
+    private const string FileProviderName = "Microsoft-Windows-Kernel-FileIO";
+    private const string WatcherSourceName = "DirectoryWatcher";
+    private const string PollingSourceName = "Polling";
+
+    private readonly ILogger<DirectoryChangeWatcher> _logger;
+    private readonly Dictionary<string, List<IDisposable>> _watchers = new();
+    private readonly object _lockObject = new();
+    private long _eventsLost;
+    private bool _disposed;
+
+    /// <summary>
+    /// ディレクトリの変更を受信した時に発火するイベント
+    /// </summary>
+    public event EventHandler<RawEventData>? EventReceived;
+
+    /// <summary>
+    /// バッファ溢れで失われた変更通知の累計
+    /// </summary>
+    public long EventsLost => Interlocked.Read(ref _eventsLost);
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    /// <param name="logger">ロガー</param>
+    public DirectoryChangeWatcher(ILogger<DirectoryChangeWatcher> logger)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+    }
+
+    /// <summary>
+    /// タグのディレクトリ監視を開始（同じタグの既存の監視は置き換える）
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="directories">監視するディレクトリ（配下のサブディレクトリも含む）</param>
+    /// <returns>監視を開始できたディレクトリ数</returns>
+    public int Watch(string tagName, IReadOnlyList<string> directories)
+    {
+        return Replace(tagName, directories, directory => CreateWatcher(tagName, directory));
+    }
+
+    /// <summary>
+    /// タグのディレクトリをポーリングで監視（同じタグの既存の監視は置き換える）
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="directories">監視するディレクトリ（配下のサブディレクトリも含む）</param>
+    /// <param name="interval">ポーリング間隔</param>
+    /// <returns>監視を開始できたディレクトリ数</returns>
+    public int WatchByPolling(string tagName, IReadOnlyList<string> directories, TimeSpan interval)
+    {
+        return Replace(tagName, directories, directory =>
+        {
+            var poller = new DirectoryPoller(directory, interval,
+                change => RaiseFileEvent(tagName, change.EventName, PollingSourceName, change.FilePath), _logger);
+            poller.Start();
+            return poller;
+        });
+    }
+
+    /// <summary>
+    /// タグの監視をディレクトリごとに作成したものに置き換える
+    /// </summary>
+    private int Replace(string tagName, IReadOnlyList<string> directories, Func<string, IDisposable> createWatcher)
+    {
+        if (_disposed)
+            throw new ObjectDisposedException(nameof(DirectoryChangeWatcher));
+
+        ArgumentException.ThrowIfNullOrWhiteSpace(tagName);
+        ArgumentNullException.ThrowIfNull(directories);
+
+        var watchers = new List<IDisposable>();
+        foreach (var directory in directories.Select(Environment.ExpandEnvironmentVariables).Distinct(StringComparer.OrdinalIgnoreCase))
+        {
+            if (!Directory.Exists(directory))
+            {
+                _logger.LogWarning("監視対象ディレクトリが存在しません (Tag: {TagName}, Directory: {Directory})", tagName, directory);
+                continue;
+            }
+
+            try
+            {
+                watchers.Add(createWatcher(directory));
+                _logger.LogInformation("ディレクトリ監視を開始しました (Tag: {TagName}, Directory: {Directory})", tagName, directory);
+            }
+            catch (Exception ex) when (ex is IOException or UnauthorizedAccessException or ArgumentException)
+            {
+                _logger.LogWarning(ex, "ディレクトリ監視を開始できませんでした (Tag: {TagName}, Directory: {Directory})", tagName, directory);
+            }
+        }
+
+        List<IDisposable>? previous;
+        lock (_lockObject)
+        {
+            _watchers.Remove(tagName, out previous);
+            if (watchers.Count > 0)
+            {
+                _watchers[tagName] = watchers;
+            }
+        }
+
+        DisposeWatchers(previous);
+        return watchers.Count;
+    }
+
+    /// <summary>
+    /// タグのディレクトリ監視を停止
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    public void Unwatch(string tagName)
+    {
+        List<IDisposable>? watchers;
+        lock (_lockObject)
+        {
+            _watchers.Remove(tagName, out watchers);
+        }
+
+        if (watchers != null)
+        {
+            DisposeWatchers(watchers);
+            _logger.LogInformation("ディレクトリ監視を停止しました (Tag: {TagName})", tagName);
+        }
+    }
+
+    /// <summary>
+    /// ディレクトリの変更を監視するウォッチャーを作成
+    /// </summary>
+    private FileSystemWatcher CreateWatcher(string tagName, string path)
+    {
+        var watcher = new FileSystemWatcher(path)
+        {
+            IncludeSubdirectories = true,
+            NotifyFilter = NotifyFilters.FileName | NotifyFilters.DirectoryName | NotifyFilters.LastWrite | NotifyFilters.Size,
+            InternalBufferSize = 64 * 1024
+        };
+
+        watcher.Created += (_, e) => RaiseFileEvent(tagName, "FileIO/Create", WatcherSourceName, e.FullPath);
+        watcher.Changed += (_, e) => RaiseFileEvent(tagName, "FileIO/Write", WatcherSourceName, e.FullPath);
+        watcher.Deleted += (_, e) => RaiseFileEvent(tagName, "FileIO/Delete", WatcherSourceName, e.FullPath);
+        watcher.Renamed += (_, e) => RaiseFileEvent(tagName, "FileIO/Rename", WatcherSourceName, e.OldFullPath, e.FullPath);
+        watcher.Error += (_, e) =>
+        {
+            Interlocked.Increment(ref _eventsLost);
+            _logger.LogWarning(e.GetException(), "ディレクトリ監視で変更通知を取りこぼしました (Tag: {TagName}, Directory: {Directory})", tagName, path);
+        };
+
+        watcher.EnableRaisingEvents = true;
+        return watcher;
+    }
+
+    private void RaiseFileEvent(string tagName, string eventName, string source, string filePath, string? newFilePath = null)
+    {
+        var payload = new Dictionary<string, object>
+        {
+            ["FileName"] = filePath,
+            ["Source"] = source
+        };
+        if (newFilePath != null)
+        {
+            payload["NewFileName"] = newFilePath;
+        }
+
+        var rawEvent = new RawEventData(
+            DateTime.Now,
+            FileProviderName,
+            eventName,
+            0,
+            0,
+            Guid.Empty,
+            Guid.Empty,
+            payload,
+            Stopwatch.GetTimestamp(),
+            TagName: tagName);
+
+        try
+        {
+            EventReceived?.Invoke(this, rawEvent);
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "イベント配信中にエラーが発生しました");
+        }
+    }
+
+    private static void DisposeWatchers(List<IDisposable>? watchers)
+    {
+        if (watchers == null)
+        {
+            return;
+        }
+
+        foreach (var watcher in watchers)
+        {
+            watcher.Dispose();
+        }
+    }
+
+    /// <summary>
+    /// リソースの解放
+    /// </summary>
+    public void Dispose()
+    {
+        if (_disposed)
+            return;
+
+        _disposed = true;
+
+        List<List<IDisposable>> watchers;
+        lock (_lockObject)
+        {
+            watchers = _watchers.Values.ToList();
+            _watchers.Clear();
+        }
+
+        foreach (var tagWatchers in watchers)
+        {
+            DisposeWatchers(tagWatchers);
+        }
+    }
+}
@@ -0,0 +1,168 @@
+using Microsoft.Extensions.Logging;
+
+namespace ProcTail.Infrastructure.Files;
+
+/// <summary>
+/// ディレクトリのポーリングで検出したファイルの変更
+/// </summary>
+/// <param name="EventName">イベント名（FileIO/Create, FileIO/Write, FileIO/Delete）</param>
+/// <param name="FilePath">ファイルパス</param>
+public record DirectoryPollChange(string EventName, string FilePath);
+
+/// <summary>
+/// ディレクトリ配下のファイルを定期的に列挙し、前回との差分から変更を検出する
+/// </summary>
+/// <remarks>
+/// 変更通知を持たないWSLのマウントやFUSEなどのファイルシステム向けの最後の手段。
+/// サイズと最終書き込み時刻の比較のみのため、リネームは削除と作成として検出し、ポーリング間隔内に元に戻った変更は検出できない。
+/// ディレクトリが見つからない間（アンマウント中など）は前回の状態を維持し、削除として扱わない。
+/// </remarks>
+public class DirectoryPoller : IDisposable
+{
+    private static readonly EnumerationOptions EnumerationOptions = new()
+    {
+        RecurseSubdirectories = true,
+        IgnoreInaccessible = true,
+        AttributesToSkip = 0
+    };
+
+    private readonly string _directory;
+    private readonly TimeSpan _interval;
+    private readonly Action<DirectoryPollChange> _onChange;
+    private readonly ILogger _logger;
+    private Dictionary<string, (long Length, DateTime LastWriteTimeUtc)> _snapshot;
+    private Timer? _timer;
+    private int _polling;
+    private bool _directoryMissing;
+    private bool _disposed;
+
+    /// <summary>
+    /// コンストラクタ（作成時点のファイルを基準とし、既存のファイルは変更として扱わない）
+    /// </summary>
+    /// <param name="directory">監視するディレクトリ（配下のサブディレクトリも含む）</param>
+    /// <param name="interval">ポーリング間隔</param>
+    /// <param name="onChange">変更を検出した時に呼び出す処理</param>
+    /// <param name="logger">ロガー</param>
+    public DirectoryPoller(string directory, TimeSpan interval, Action<DirectoryPollChange> onChange, ILogger logger)
+    {
+        _directory = directory ?? throw new ArgumentNullException(nameof(directory));
+        _interval = interval > TimeSpan.Zero ? interval : TimeSpan.FromSeconds(2);
+        _onChange = onChange ?? throw new ArgumentNullException(nameof(onChange));
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+        _snapshot = Scan() ?? new Dictionary<string, (long, DateTime)>(StringComparer.Ordinal);
+    }
+
+    /// <summary>
+    /// 定期的なポーリングを開始
+    /// </summary>
+    public void Start()
+    {
+        if (_disposed)
+            throw new ObjectDisposedException(nameof(DirectoryPoller));
+
+        _timer ??= new Timer(_ => PollSafely(), null, _interval, _interval);
+    }
+
+    /// <summary>
+    /// ディレクトリを列挙して前回との差分を検出
+    /// </summary>
+    /// <returns>検出した変更（ディレクトリが見つからない場合は空）</returns>
+    public IReadOnlyList<DirectoryPollChange> Poll()
+    {
+        var current = Scan();
+        if (current == null)
+        {
+            return Array.Empty<DirectoryPollChange>();
+        }
+
+        var changes = new List<DirectoryPollChange>();
+        foreach (var (path, state) in current)
+        {
+            if (!_snapshot.TryGetValue(path, out var previous))
+            {
+                changes.Add(new DirectoryPollChange("FileIO/Create", path));
+            }
+            else if (previous != state)
+            {
+                changes.Add(new DirectoryPollChange("FileIO/Write", path));
+            }
+        }
+
+        foreach (var path in _snapshot.Keys.Where(path => !current.ContainsKey(path)))
+        {
+            changes.Add(new DirectoryPollChange("FileIO/Delete", path));
+        }
+
+        _snapshot = current;
+        return changes;
+    }
+
+    private void PollSafely()
+    {
+        // 前回のポーリングが終わっていない場合（大きなディレクトリや遅いファイルシステム）は今回を飛ばす
+        if (Interlocked.Exchange(ref _polling, 1) == 1)
+        {
+            return;
+        }
+
+        try
+        {
+            foreach (var change in Poll())
+            {
+                _onChange(change);
+            }
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "ディレクトリのポーリング中にエラーが発生しました (Directory: {Directory})", _directory);
+        }
+        finally
+        {
+            Volatile.Write(ref _polling, 0);
+        }
+    }
+
+    private Dictionary<string, (long Length, DateTime LastWriteTimeUtc)>? Scan()
+    {
+        if (!Directory.Exists(_directory))
+        {
+            if (!_directoryMissing)
+            {
+                _directoryMissing = true;
+                _logger.LogWarning("ポーリング対象のディレクトリが見つかりません (Directory: {Directory})", _directory);
+            }
+            return null;
+        }
+
+        _directoryMissing = false;
+        var snapshot = new Dictionary<string, (long, DateTime)>(StringComparer.Ordinal);
+        try
+        {
+            foreach (var file in new DirectoryInfo(_directory).EnumerateFiles("*", EnumerationOptions))
+            {
+                snapshot[file.FullName] = (file.Length, file.LastWriteTimeUtc);
+            }
+        }
+        catch (Exception ex) when (ex is IOException or UnauthorizedAccessException)
+        {
+            // 列挙の途中で失敗した場合は、存在するファイルを削除と誤検出しないよう今回の結果を破棄する
+            _logger.LogWarning(ex, "ポーリング対象のディレクトリを列挙できませんでした (Directory: {Directory})", _directory);
+            return null;
+        }
+
+        return snapshot;
+    }
+
+    /// <summary>
+    /// リソースの解放
+    /// </summary>
+    public void Dispose()
+    {
+        if (_disposed)
+            return;
+
+        _disposed = true;
+        _timer?.Dispose();
+        _timer = null;
+    }
+}
@@ -0,0 +1,42 @@
+using System.Security.Cryptography;
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Interfaces;
+
+namespace ProcTail.Infrastructure.Files;
+
+/// <summary>
+/// ファイル内容のSHA-256を計算する
+/// </summary>
+/// <remarks>
+/// 監視対象プロセスの書き込みや実行を妨げないよう、書き込み・削除の共有を許可して読み取る。
+/// </remarks>
+public class FileContentHasher : IFileContentHasher
+{
+    private readonly ILogger<FileContentHasher> _logger;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public FileContentHasher(ILogger<FileContentHasher> logger)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+    }
+
+    /// <summary>
+    /// ファイル内容のSHA-256を計算
+    /// </summary>
+    public async Task<string?> ComputeSha256Async(string filePath, CancellationToken cancellationToken = default)
+    {
+        try
+        {
+            await using var stream = new FileStream(filePath, FileMode.Open, FileAccess.Read, FileShare.ReadWrite | FileShare.Delete, 81920, useAsync: true);
+            var hash = await SHA256.HashDataAsync(stream, cancellationToken);
+            return Convert.ToHexString(hash).ToLowerInvariant();
+        }
+        catch (Exception ex) when (ex is IOException or UnauthorizedAccessException)
+        {
+            _logger.LogDebug(ex, "ファイルを読み取れませんでした (Path: {Path})", filePath);
+            return null;
+        }
+    }
+}
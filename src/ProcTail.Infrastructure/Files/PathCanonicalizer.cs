@@ -0,0 +1,118 @@
+using System.Collections.Concurrent;
+using System.Runtime.Versioning;
+using ProcTail.Infrastructure.Processes;
+
+namespace ProcTail.Infrastructure.Files;
+
+/// <summary>
+/// 8.3形式の短い名前（PROGRA~1など）や大文字小文字の違いを、ディスク上の正式な長いパスに揃える
+/// </summary>
+/// <remarks>
+/// 同じファイルでもプロセスによって短い名前や異なる大文字小文字で開かれるため、
+/// パスフィルターや後段での突き合わせで取りこぼさないよう、ドライブレターのパスを構成要素ごとに実際の名前に置き換える。
+/// ディレクトリは繰り返し現れるため、解決した結果をキャッシュする。
+/// 存在しない構成要素（削除済みのファイルなど）はそのまま残し、キャッシュしない。
+/// </remarks>
+public class PathCanonicalizer
+{
+    /// <summary>
+    /// キャッシュするパス数の上限（超えた場合は全て破棄する）
+    /// </summary>
+    private const int MaxCacheEntries = 8192;
+
+    private readonly Func<string, string, string?> _findEntryName;
+    private readonly ConcurrentDictionary<string, string> _cache = new(StringComparer.OrdinalIgnoreCase);
+
+    /// <summary>
+    /// コンストラクタ（FindFirstFileで実際の名前を取得）
+    /// </summary>
+    [SupportedOSPlatform("windows")]
+    public PathCanonicalizer()
+        : this(FindEntryName)
+    {
+    }
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    /// <param name="findEntryName">ディレクトリと名前（短い名前を含む）から実際の長い名前を取得する関数（存在しない場合はnull）</param>
+    public PathCanonicalizer(Func<string, string, string?> findEntryName)
+    {
+        _findEntryName = findEntryName ?? throw new ArgumentNullException(nameof(findEntryName));
+    }
+
+    /// <summary>
+    /// パスを正式な長いパスに変換
+    /// </summary>
+    /// <param name="path">パス</param>
+    /// <returns>変換したパス（ドライブレターのパスでない場合はそのまま）</returns>
+    public string Canonicalize(string path)
+    {
+        // UNCパスはネットワーク越しの問い合わせになるため対象外
+        if (string.IsNullOrEmpty(path) || path.Length < 3 || !char.IsAsciiLetter(path[0]) || path[1] != ':' || path[2] != '\\')
+        {
+            return path;
+        }
+
+        var trimmed = path.Length > 3 ? path.TrimEnd('\\') : path;
+        return trimmed.Length <= 3
+            ? char.ToUpperInvariant(path[0]) + @":\"
+            : CanonicalizeCore(trimmed, out _);
+    }
+
+    private string CanonicalizeCore(string path, out bool exists)
+    {
+        if (_cache.TryGetValue(path, out var cached))
+        {
+            exists = true;
+            return cached;
+        }
+
+        var separator = path.LastIndexOf('\\');
+        var parentExists = true;
+        var parent = separator <= 2
+            ? char.ToUpperInvariant(path[0]) + @":\"
+            : CanonicalizeCore(path[..separator], out parentExists);
+
+        var name = path[(separator + 1)..];
+        var actualName = parentExists && name.Length > 0 ? _findEntryName(parent, name) : null;
+        var canonical = (parent.EndsWith('\\') ? parent : parent + '\\') + (actualName ?? name);
+
+        exists = actualName != null;
+        if (exists)
+        {
+            if (_cache.Count >= MaxCacheEntries)
+            {
+                _cache.Clear();
+            }
+
+            // 短い名前と長い名前のどちらで現れても同じディレクトリを引けるようにする
+            _cache[path] = canonical;
+            _cache[canonical] = canonical;
+        }
+
+        return canonical;
+    }
+
+    /// <summary>
+    /// FindFirstFileは短い名前にも一致し、見つかったエントリの長い名前を返す
+    /// </summary>
+    [SupportedOSPlatform("windows")]
+    private static string? FindEntryName(string directory, string name)
+    {
+        // ワイルドカードを含む名前は別のエントリに一致するため扱わない
+        if (name.AsSpan().IndexOfAny('*', '?') >= 0)
+        {
+            return null;
+        }
+
+        var findHandle = NativeMethods.FindFirstFile(Path.Join(directory, name), out var findData);
+        if (findHandle == NativeMethods.InvalidHandleValue)
+        {
+            return null;
+        }
+
+        NativeMethods.FindClose(findHandle);
+        return string.IsNullOrEmpty(findData.FileName) ? null : findData.FileName;
+    }
+}
@@ -0,0 +1,118 @@
+using System.Collections.Concurrent;
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Interfaces;
+
+namespace ProcTail.Infrastructure.Files;
+
+/// <summary>
+/// ファイルパスに含まれるシンボリックリンク・ジャンクション・マウントポイントを解決する
+/// </summary>
+/// <remarks>
+/// OneDriveやドキュメントフォルダのリダイレクトのように、保存先のディレクトリがジャンクションで別の場所を指している場合に、
+/// 実際の場所で指定したパスフィルタに一致させるために使う。
+/// ディレクトリは繰り返し現れるため、親ディレクトリから順に解決した結果をキャッシュする。
+/// Windowsの最終的なリンク先はハンドルから取得するため、別のボリュームのマウントポイントもそのボリュームのパスに解決される。
+/// </remarks>
+public class ReparsePointResolver : IReparsePointResolver
+{
+    /// <summary>
+    /// キャッシュするディレクトリ数の上限（超えた場合は全て破棄する）
+    /// </summary>
+    private const int MaxCacheEntries = 8192;
+
+    private readonly ILogger<ReparsePointResolver> _logger;
+    private readonly ConcurrentDictionary<string, string> _directoryCache =
+        new(OperatingSystem.IsWindows() ? StringComparer.OrdinalIgnoreCase : StringComparer.Ordinal);
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public ReparsePointResolver(ILogger<ReparsePointResolver> logger)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+    }
+
+    /// <summary>
+    /// 再解析ポイントを解決した実際のパスを取得
+    /// </summary>
+    public string? ResolveFinalPath(string filePath)
+    {
+        if (string.IsNullOrEmpty(filePath) || !Path.IsPathFullyQualified(filePath))
+        {
+            return null;
+        }
+
+        try
+        {
+            var directory = Path.GetDirectoryName(filePath);
+            var fileName = Path.GetFileName(filePath);
+            if (directory == null || string.IsNullOrEmpty(fileName))
+            {
+                return null;
+            }
+
+            var resolvedPath = ResolveEntry(Path.Join(ResolveDirectory(directory), fileName), isDirectory: false);
+            return string.Equals(resolvedPath, filePath, StringComparison.Ordinal) ? null : resolvedPath;
+        }
+        catch (Exception ex) when (ex is IOException or UnauthorizedAccessException or ArgumentException)
+        {
+            _logger.LogTrace(ex, "再解析ポイントを解決できませんでした (Path: {Path})", filePath);
+            return null;
+        }
+    }
+
+    private string ResolveDirectory(string directory)
+    {
+        if (_directoryCache.TryGetValue(directory, out var cached))
+        {
+            return cached;
+        }
+
+        var parent = Path.GetDirectoryName(directory);
+        var name = Path.GetFileName(directory);
+        var resolved = parent == null || string.IsNullOrEmpty(name)
+            ? directory
+            : ResolveEntry(Path.Join(ResolveDirectory(parent), name), isDirectory: true);
+
+        if (_directoryCache.Count >= MaxCacheEntries)
+        {
+            _directoryCache.Clear();
+        }
+
+        _directoryCache[directory] = resolved;
+        return resolved;
+    }
+
+    /// <summary>
+    /// エントリ自体が再解析ポイントの場合は最終的なリンク先に置き換える
+    /// </summary>
+    private static string ResolveEntry(string path, bool isDirectory)
+    {
+        FileSystemInfo entry = isDirectory ? new DirectoryInfo(path) : new FileInfo(path);
+        if (!entry.Exists || !entry.Attributes.HasFlag(FileAttributes.ReparsePoint))
+        {
+            return path;
+        }
+
+        var target = entry.ResolveLinkTarget(returnFinalTarget: true)?.FullName;
+        return target == null ? path : StripDevicePrefix(target) ?? path;
+    }
+
+    /// <summary>
+    /// Windowsの最終パスに付く \\?\ を通常のパスに戻す（ドライブレターのないボリューム（\\?\Volume{GUID}\）はnull）
+    /// </summary>
+    private static string? StripDevicePrefix(string path)
+    {
+        if (path.StartsWith(@"\\?\UNC\", StringComparison.OrdinalIgnoreCase))
+        {
+            return @"\\" + path[8..];
+        }
+
+        if (path.StartsWith(@"\\?\", StringComparison.Ordinal) && path.Length > 6 && path[5] == ':')
+        {
+            return path[4..];
+        }
+
+        return path.StartsWith(@"\\?\", StringComparison.Ordinal) ? null : path;
+    }
+}
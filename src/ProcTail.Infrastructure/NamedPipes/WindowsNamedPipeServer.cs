@@ -292,10 +292,10 @@ public class WindowsNamedPipeServer : INamedPipeServer, IDisposable
     }
 
     /// <summary>
-    /// パイプセキュリティを作成
+    /// パイプセキュリティを作成（gRPCの名前付きパイプでも同じアクセス制御を使う）
     /// </summary>
     [SupportedOSPlatform("windows")]
-    private static PipeSecurity CreatePipeSecurity()
+    public static PipeSecurity CreatePipeSecurity()
     {
         var pipeSecurity = new PipeSecurity();
         
@@ -0,0 +1,194 @@
+using System.Diagnostics;
+using System.Security;
+using System.Text;
+using System.Threading.Channels;
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Infrastructure.Notifications;
+
+/// <summary>
+/// アラートをWindowsのトースト通知で表示する
+/// </summary>
+/// <remarks>
+/// WinRTのパッケージに依存しないよう、Windows PowerShellからWindows.UI.Notificationsを呼び出して表示する。
+/// 通知はProcTailを実行しているユーザーのデスクトップに表示されるため、サービス（セッション0）として実行している場合は表示されない。
+/// 表示は1件ずつ順に行い、短時間に多数のアラートが生成された場合は表示待ちの上限を超えた分を破棄する。
+/// </remarks>
+public class WindowsToastNotifier : IAlertNotifier, IDisposable
+{
+    /// <summary>
+    /// 通知の送信元として使用するアプリケーションID（Windows PowerShell）
+    /// </summary>
+    public const string AppId = @"{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe";
+
+    private const int MaxPending = 5;
+    private static readonly TimeSpan ShowTimeout = TimeSpan.FromSeconds(10);
+
+    private readonly ILogger<WindowsToastNotifier> _logger;
+    private readonly Channel<Alert> _pending = Channel.CreateBounded<Alert>(new BoundedChannelOptions(MaxPending)
+    {
+        SingleReader = true
+    });
+    private readonly CancellationTokenSource _cancellationTokenSource = new();
+    private readonly Task _showTask;
+    private bool _disposed;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public WindowsToastNotifier(ILogger<WindowsToastNotifier> logger)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+        _showTask = Task.Run(ShowPendingAsync);
+    }
+
+    /// <summary>
+    /// アラートをトースト通知の表示待ちに追加
+    /// </summary>
+    public void Notify(Alert alert)
+    {
+        if (_disposed)
+            return;
+
+        if (!_pending.Writer.TryWrite(alert))
+        {
+            _logger.LogDebug("表示待ちのトースト通知が多いため破棄しました (Rule: {RuleName}, SequenceNumber: {SequenceNumber})",
+                alert.RuleName, alert.SequenceNumber);
+        }
+    }
+
+    /// <summary>
+    /// アラートからトースト通知のXMLを作成
+    /// </summary>
+    /// <param name="alert">アラート</param>
+    /// <returns>タイトルにルールのメッセージ（未設定の場合はルール名）、本文にタグとイベントの内容を表示するXML</returns>
+    public static string BuildToastXml(Alert alert)
+    {
+        var title = alert.Message ?? alert.RuleName;
+        var body = $"[{alert.TagName}] {DescribeEvent(alert.Event)}";
+        if (alert.MatchCount > 1)
+        {
+            body += $" ({alert.MatchCount}件)";
+        }
+
+        return "<toast><visual><binding template=\"ToastGeneric\">"
+            + $"<text>{SecurityElement.Escape(title)}</text>"
+            + $"<text>{SecurityElement.Escape(body)}</text>"
+            + "<text placement=\"attribution\">ProcTail</text>"
+            + "</binding></visual></toast>";
+    }
+
+    private static string DescribeEvent(BaseEventData eventData)
+    {
+        return eventData switch
+        {
+            FileEventData fileEvent => $"{eventData.EventName} {fileEvent.FilePath}",
+            ProcessStartEventData processStart => $"{eventData.EventName} {processStart.ChildProcessName} (PID: {processStart.ChildProcessId})",
+            ProcessEndEventData processEnd => $"{eventData.EventName} PID: {eventData.ProcessId}, ExitCode: {processEnd.ExitCode} ({processEnd.ExitReason})",
+            RegistryEventData registryEvent => $"{eventData.EventName} {registryEvent.KeyName}",
+            ImageLoadEventData imageLoadEvent => $"{eventData.EventName} {imageLoadEvent.ImagePath}",
+            CrashDetectedEventData crashEvent => $"{eventData.EventName} {crashEvent.ApplicationName} ({crashEvent.FaultingModule}, 0x{crashEvent.ExceptionCode:X8})",
+            _ => eventData.EventName
+        };
+    }
+
+    private static string BuildScript(string toastXml)
+    {
+        var script = new StringBuilder();
+        script.AppendLine("$ErrorActionPreference = 'Stop'");
+        script.AppendLine("[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null");
+        script.AppendLine("[Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] > $null");
+        script.AppendLine("$xml = New-Object Windows.Data.Xml.Dom.XmlDocument");
+        script.AppendLine($"$xml.LoadXml('{toastXml.Replace("'", "''")}')");
+        script.AppendLine("$toast = [Windows.UI.Notifications.ToastNotification]::new($xml)");
+        script.AppendLine($"[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('{AppId}').Show($toast)");
+        return script.ToString();
+    }
+
+    private async Task ShowPendingAsync()
+    {
+        try
+        {
+            await foreach (var alert in _pending.Reader.ReadAllAsync(_cancellationTokenSource.Token))
+            {
+                try
+                {
+                    await ShowAsync(alert, _cancellationTokenSource.Token);
+                }
+                catch (Exception ex) when (ex is not OperationCanceledException)
+                {
+                    _logger.LogWarning(ex, "トースト通知を表示できませんでした (Rule: {RuleName})", alert.RuleName);
+                }
+            }
+        }
+        catch (OperationCanceledException)
+        {
+            // 破棄による停止
+        }
+    }
+
+    private async Task ShowAsync(Alert alert, CancellationToken cancellationToken)
+    {
+        var startInfo = new ProcessStartInfo("powershell.exe")
+        {
+            UseShellExecute = false,
+            CreateNoWindow = true,
+            RedirectStandardError = true
+        };
+        startInfo.ArgumentList.Add("-NoProfile");
+        startInfo.ArgumentList.Add("-NonInteractive");
+        startInfo.ArgumentList.Add("-ExecutionPolicy");
+        startInfo.ArgumentList.Add("Bypass");
+        startInfo.ArgumentList.Add("-EncodedCommand");
+        startInfo.ArgumentList.Add(Convert.ToBase64String(Encoding.Unicode.GetBytes(BuildScript(BuildToastXml(alert)))));
+
+        using var process = Process.Start(startInfo)
+            ?? throw new InvalidOperationException("PowerShellを起動できませんでした");
+        var errorTask = process.StandardError.ReadToEndAsync(cancellationToken);
+
+        using var timeoutSource = CancellationTokenSource.CreateLinkedTokenSource(cancellationToken);
+        timeoutSource.CancelAfter(ShowTimeout);
+        try
+        {
+            await process.WaitForExitAsync(timeoutSource.Token);
+        }
+        catch (OperationCanceledException)
+        {
+            process.Kill();
+            if (cancellationToken.IsCancellationRequested)
+                throw;
+
+            throw new TimeoutException($"トースト通知の表示が{ShowTimeout.TotalSeconds}秒以内に完了しませんでした");
+        }
+
+        if (process.ExitCode != 0)
+        {
+            _logger.LogWarning("トースト通知の表示に失敗しました (Rule: {RuleName}, ExitCode: {ExitCode}, Error: {Error})",
+                alert.RuleName, process.ExitCode, (await errorTask).Trim());
+        }
+    }
+
+    /// <summary>
+    /// リソースの解放
+    /// </summary>
+    public void Dispose()
+    {
+        if (_disposed)
+            return;
+
+        _disposed = true;
+        _pending.Writer.TryComplete();
+        _cancellationTokenSource.Cancel();
+        try
+        {
+            _showTask.Wait(TimeSpan.FromSeconds(1));
+        }
+        catch (AggregateException)
+        {
+            // 停止時の例外は無視
+        }
+        _cancellationTokenSource.Dispose();
+    }
+}
@@ -0,0 +1,162 @@
+using System.Runtime.Versioning;
+using Microsoft.Extensions.Logging;
+using Microsoft.Win32;
+using ProcTail.Core.Interfaces;
+
+namespace ProcTail.Infrastructure.Processes;
+
+/// <summary>
+/// Windowsエラー報告のLocalDumpsが作成したクラッシュダンプを検索する
+/// </summary>
+/// <remarks>
+/// ダンプの保存先はHKLMのLocalDumps（アプリケーションごとのキーを優先）のDumpFolderで、未設定の場合は %LOCALAPPDATA%\CrashDumps。
+/// %LOCALAPPDATA% はクラッシュしたプロセスのユーザーで展開されるため、ProfileListの全ユーザーのプロファイルで探す。
+/// ダンプはクラッシュイベントの記録後に書き込まれるため、書き込みが終わって読み取れるようになるまで一定時間待つ。
+/// LocalDumpsが無効な場合は待たずにnullを返す。
+/// </remarks>
+public class CrashDumpLocator : ICrashDumpLocator
+{
+    private const string LocalDumpsKey = @"SOFTWARE\Microsoft\Windows\Windows Error Reporting\LocalDumps";
+    private const string ProfileListKey = @"SOFTWARE\Microsoft\Windows NT\CurrentVersion\ProfileList";
+    private const string DefaultDumpFolder = @"%LOCALAPPDATA%\CrashDumps";
+    private static readonly TimeSpan PollInterval = TimeSpan.FromMilliseconds(500);
+
+    private readonly ILogger<CrashDumpLocator> _logger;
+    private readonly TimeSpan _waitTimeout;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    /// <param name="logger">ロガー</param>
+    public CrashDumpLocator(ILogger<CrashDumpLocator> logger)
+        : this(logger, TimeSpan.FromSeconds(30))
+    {
+    }
+
+    /// <summary>
+    /// コンストラクタ（ダンプの書き込みを待つ時間を指定する場合）
+    /// </summary>
+    /// <param name="logger">ロガー</param>
+    /// <param name="waitTimeout">ダンプの書き込みを待つ最大時間</param>
+    public CrashDumpLocator(ILogger<CrashDumpLocator> logger, TimeSpan waitTimeout)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+        _waitTimeout = waitTimeout;
+    }
+
+    /// <summary>
+    /// クラッシュしたプロセスのダンプファイルを検索
+    /// </summary>
+    public async Task<string?> FindDumpPathAsync(string applicationName, int processId, CancellationToken cancellationToken = default)
+    {
+        if (!OperatingSystem.IsWindows() || string.IsNullOrEmpty(applicationName))
+        {
+            return null;
+        }
+
+        try
+        {
+            var folders = GetDumpFolders(applicationName);
+            if (folders.Count == 0)
+            {
+                return null;
+            }
+
+            // LocalDumpsのファイル名は "<実行ファイル名>.<PID>.dmp"
+            var candidates = folders.Select(folder => Path.Combine(folder, $"{applicationName}.{processId}.dmp")).ToList();
+            var deadline = DateTime.UtcNow + _waitTimeout;
+            while (true)
+            {
+                var dumpPath = candidates.FirstOrDefault(IsCompleted);
+                if (dumpPath != null)
+                {
+                    return dumpPath;
+                }
+
+                if (DateTime.UtcNow >= deadline)
+                {
+                    _logger.LogDebug("クラッシュダンプが見つかりませんでした (Application: {Application}, ProcessId: {ProcessId})", applicationName, processId);
+                    return null;
+                }
+
+                await Task.Delay(PollInterval, cancellationToken);
+            }
+        }
+        catch (Exception ex) when (ex is not OperationCanceledException)
+        {
+            _logger.LogDebug(ex, "クラッシュダンプの検索に失敗しました (Application: {Application}, ProcessId: {ProcessId})", applicationName, processId);
+            return null;
+        }
+    }
+
+    /// <summary>
+    /// ダンプの書き込みが終わっているかどうか（書き込み中は共有読み取りで開けない）
+    /// </summary>
+    private static bool IsCompleted(string path)
+    {
+        if (!File.Exists(path))
+        {
+            return false;
+        }
+
+        try
+        {
+            using var stream = new FileStream(path, FileMode.Open, FileAccess.Read, FileShare.Read);
+            return stream.Length > 0;
+        }
+        catch (IOException)
+        {
+            return false;
+        }
+    }
+
+    [SupportedOSPlatform("windows")]
+    private static IReadOnlyList<string> GetDumpFolders(string applicationName)
+    {
+        using var localDumps = Registry.LocalMachine.OpenSubKey(LocalDumpsKey);
+        if (localDumps == null)
+        {
+            return Array.Empty<string>();
+        }
+
+        using var applicationDumps = localDumps.OpenSubKey(applicationName);
+        var dumpFolder = ReadUnexpandedString(applicationDumps, "DumpFolder")
+            ?? ReadUnexpandedString(localDumps, "DumpFolder")
+            ?? DefaultDumpFolder;
+
+        if (!dumpFolder.Contains("%LOCALAPPDATA%", StringComparison.OrdinalIgnoreCase))
+        {
+            return new[] { Environment.ExpandEnvironmentVariables(dumpFolder) };
+        }
+
+        return GetUserProfiles()
+            .Select(profile => Environment.ExpandEnvironmentVariables(
+                dumpFolder.Replace("%LOCALAPPDATA%", Path.Combine(profile, "AppData", "Local"), StringComparison.OrdinalIgnoreCase)))
+            .ToList();
+    }
+
+    [SupportedOSPlatform("windows")]
+    private static string? ReadUnexpandedString(RegistryKey? key, string name)
+    {
+        return key?.GetValue(name, null, RegistryValueOptions.DoNotExpandEnvironmentNames) as string is { Length: > 0 } value ? value : null;
+    }
+
+    [SupportedOSPlatform("windows")]
+    private static IEnumerable<string> GetUserProfiles()
+    {
+        using var profileList = Registry.LocalMachine.OpenSubKey(ProfileListKey);
+        if (profileList == null)
+        {
+            yield break;
+        }
+
+        foreach (var sid in profileList.GetSubKeyNames())
+        {
+            using var profile = profileList.OpenSubKey(sid);
+            if (profile?.GetValue("ProfileImagePath") is string profilePath && Directory.Exists(profilePath))
+            {
+                yield return profilePath;
+            }
+        }
+    }
+}
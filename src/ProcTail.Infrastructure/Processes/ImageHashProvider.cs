@@ -0,0 +1,70 @@
+using System.Collections.Concurrent;
+using ProcTail.Core.Interfaces;
+
+namespace ProcTail.Infrastructure.Processes;
+
+/// <summary>
+/// プロセスの実行ファイルのSHA-256を計算する
+/// </summary>
+/// <remarks>
+/// 同じ実行ファイルから繰り返し起動されるプロセスのために、パス・サイズ・更新日時をキーとして結果をキャッシュする。
+/// 更新でファイルが置き換わるとキーが変わるため、新しいバージョンのハッシュが計算される。
+/// 同じファイルの計算中に別のプロセスが起動した場合は、計算中のタスクを共有する。
+/// </remarks>
+public class ImageHashProvider : IImageHashProvider
+{
+    /// <summary>
+    /// キャッシュする実行ファイル数の上限（超えた場合は全て破棄する）
+    /// </summary>
+    private const int MaxCacheEntries = 4096;
+
+    private readonly IProcessValidator _processValidator;
+    private readonly IFileContentHasher _fileContentHasher;
+    private readonly ConcurrentDictionary<ImageKey, Task<string?>> _cache = new();
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public ImageHashProvider(IProcessValidator processValidator, IFileContentHasher fileContentHasher)
+    {
+        _processValidator = processValidator ?? throw new ArgumentNullException(nameof(processValidator));
+        _fileContentHasher = fileContentHasher ?? throw new ArgumentNullException(nameof(fileContentHasher));
+    }
+
+    /// <summary>
+    /// プロセスの実行ファイルのSHA-256を取得
+    /// </summary>
+    public async Task<string?> GetImageSha256Async(int processId, CancellationToken cancellationToken = default)
+    {
+        // 短命なプロセスは起動イベントの処理前に終了している場合がある
+        var imagePath = _processValidator.GetProcessInfo(processId)?.ExecutablePath;
+        if (string.IsNullOrEmpty(imagePath))
+        {
+            return null;
+        }
+
+        var fileInfo = new FileInfo(imagePath);
+        if (!fileInfo.Exists)
+        {
+            return null;
+        }
+
+        var key = new ImageKey(fileInfo.FullName, fileInfo.Length, fileInfo.LastWriteTimeUtc);
+        if (_cache.Count >= MaxCacheEntries)
+        {
+            _cache.Clear();
+        }
+
+        var hashTask = _cache.GetOrAdd(key, k => _fileContentHasher.ComputeSha256Async(k.Path));
+        var hash = await hashTask.WaitAsync(cancellationToken);
+        if (hash == null)
+        {
+            // 読み取りに失敗した結果はキャッシュせず、次の起動時に再計算する
+            _cache.TryRemove(new KeyValuePair<ImageKey, Task<string?>>(key, hashTask));
+        }
+
+        return hash;
+    }
+
+    private readonly record struct ImageKey(string Path, long Length, DateTime LastWriteTimeUtc);
+}
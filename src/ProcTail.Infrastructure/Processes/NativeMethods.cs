@@ -0,0 +1,255 @@
+using System.Runtime.InteropServices;
+using System.Text;
+using Microsoft.Win32.SafeHandles;
+
+namespace ProcTail.Infrastructure.Processes;
+
+/// <summary>
+/// プロセス情報取得用のネイティブAPI定義
+/// </summary>
+internal static class NativeMethods
+{
+    #region Windows
+
+    public const int ProcessVmRead = 0x0010;
+    public const int ProcessQueryInformation = 0x0400;
+    public const int ProcessQueryLimitedInformation = 0x1000;
+
+    [StructLayout(LayoutKind.Sequential)]
+    public struct ProcessBasicInformation
+    {
+        public IntPtr ExitStatus;
+        public IntPtr PebBaseAddress;
+        public IntPtr AffinityMask;
+        public IntPtr BasePriority;
+        public IntPtr UniqueProcessId;
+        public IntPtr InheritedFromUniqueProcessId;
+    }
+
+    [DllImport("kernel32.dll", SetLastError = true)]
+    public static extern IntPtr OpenProcess(int desiredAccess, bool inheritHandle, int processId);
+
+    [DllImport("kernel32.dll", SetLastError = true)]
+    public static extern bool CloseHandle(IntPtr handle);
+
+    [DllImport("kernel32.dll", SetLastError = true)]
+    public static extern bool ReadProcessMemory(IntPtr process, IntPtr baseAddress, byte[] buffer, IntPtr size, out IntPtr bytesRead);
+
+    [DllImport("ntdll.dll")]
+    public static extern int NtQueryInformationProcess(IntPtr process, int processInformationClass, ref ProcessBasicInformation information, int length, out int returnLength);
+
+    // GetProcessIoCountersのIO_COUNTERS
+    [StructLayout(LayoutKind.Sequential)]
+    public struct IoCounters
+    {
+        public ulong ReadOperationCount;
+        public ulong WriteOperationCount;
+        public ulong OtherOperationCount;
+        public ulong ReadTransferCount;
+        public ulong WriteTransferCount;
+        public ulong OtherTransferCount;
+    }
+
+    [DllImport("kernel32.dll", SetLastError = true)]
+    public static extern bool GetProcessIoCounters(IntPtr process, out IoCounters ioCounters);
+
+    // GetTokenInformationのTokenPrivileges（TOKEN_PRIVILEGES）
+    public const int TokenPrivileges = 3;
+    public const int LuidAndAttributesSize = 12;
+    public const uint SePrivilegeEnabled = 0x00000002;
+
+    // GetTokenInformationのTokenUser（TOKEN_USER）、TokenSessionId（DWORD）、TokenIntegrityLevel（TOKEN_MANDATORY_LABEL）
+    public const int TokenUser = 1;
+    public const int TokenSessionId = 12;
+    public const int TokenIntegrityLevel = 25;
+    public const int TokenQuery = 0x0008;
+
+    [DllImport("advapi32.dll", SetLastError = true)]
+    public static extern bool OpenProcessToken(IntPtr process, int desiredAccess, out IntPtr token);
+
+    [DllImport("advapi32.dll", SetLastError = true)]
+    public static extern bool GetTokenInformation(IntPtr token, int tokenInformationClass, IntPtr tokenInformation, int tokenInformationLength, out int returnLength);
+
+    [DllImport("advapi32.dll", SetLastError = true, CharSet = CharSet.Unicode)]
+    public static extern bool LookupPrivilegeValue(string? systemName, string name, out long luid);
+
+    [DllImport("kernel32.dll", SetLastError = true, CharSet = CharSet.Unicode)]
+    public static extern uint QueryDosDevice(string deviceName, StringBuilder targetPath, int maxLength);
+
+    // FindFirstFileのWIN32_FIND_DATAW（FILETIMEは4バイト境界の64ビット値として扱う）
+    [StructLayout(LayoutKind.Sequential, CharSet = CharSet.Unicode, Pack = 4)]
+    public struct Win32FindData
+    {
+        public uint FileAttributes;
+        public ulong CreationTime;
+        public ulong LastAccessTime;
+        public ulong LastWriteTime;
+        public uint FileSizeHigh;
+        public uint FileSizeLow;
+        public uint Reserved0;
+        public uint Reserved1;
+        [MarshalAs(UnmanagedType.ByValTStr, SizeConst = 260)]
+        public string FileName;
+        [MarshalAs(UnmanagedType.ByValTStr, SizeConst = 14)]
+        public string AlternateFileName;
+    }
+
+    public static readonly IntPtr InvalidHandleValue = new(-1);
+
+    [DllImport("kernel32.dll", SetLastError = true, CharSet = CharSet.Unicode)]
+    public static extern IntPtr FindFirstFile(string fileName, out Win32FindData findData);
+
+    [DllImport("kernel32.dll", SetLastError = true)]
+    public static extern bool FindClose(IntPtr findFile);
+
+    // USNジャーナル（FSCTL_QUERY_USN_JOURNAL・FSCTL_READ_USN_JOURNAL）
+    public const uint GenericRead = 0x80000000;
+    public const uint FileShareReadWrite = 0x00000003;
+    public const uint OpenExisting = 3;
+    public const uint FileReadAttributes = 0x0080;
+    public const uint FileFlagBackupSemantics = 0x02000000;
+    public const uint FsctlQueryUsnJournal = 0x000900F4;
+    public const uint FsctlReadUsnJournal = 0x000900BB;
+    public const int ErrorJournalNotActive = 1179;
+    public const int ErrorJournalEntryDeleted = 1181;
+
+    [StructLayout(LayoutKind.Sequential)]
+    public struct UsnJournalData
+    {
+        public ulong UsnJournalId;
+        public long FirstUsn;
+        public long NextUsn;
+        public long LowestValidUsn;
+        public long MaxUsn;
+        public ulong MaximumSize;
+        public ulong AllocationDelta;
+    }
+
+    [StructLayout(LayoutKind.Sequential)]
+    public struct ReadUsnJournalData
+    {
+        public long StartUsn;
+        public uint ReasonMask;
+        public uint ReturnOnlyOnClose;
+        public ulong Timeout;
+        public ulong BytesToWaitFor;
+        public ulong UsnJournalId;
+    }
+
+    // FILE_ID_DESCRIPTOR（共用体はFileIdのみ使用し、ObjectIdの16バイト分の領域を確保する）
+    [StructLayout(LayoutKind.Sequential)]
+    public struct FileIdDescriptor
+    {
+        public int Size;
+        public int Type;
+        public long FileId;
+        public long Reserved;
+    }
+
+    [DllImport("kernel32.dll", SetLastError = true, CharSet = CharSet.Unicode)]
+    public static extern SafeFileHandle CreateFile(string fileName, uint desiredAccess, uint shareMode, IntPtr securityAttributes, uint creationDisposition, uint flagsAndAttributes, IntPtr templateFile);
+
+    [DllImport("kernel32.dll", SetLastError = true)]
+    public static extern bool DeviceIoControl(SafeFileHandle device, uint ioControlCode, IntPtr inBuffer, int inBufferSize, out UsnJournalData outBuffer, int outBufferSize, out int bytesReturned, IntPtr overlapped);
+
+    [DllImport("kernel32.dll", SetLastError = true)]
+    public static extern bool DeviceIoControl(SafeFileHandle device, uint ioControlCode, ref ReadUsnJournalData inBuffer, int inBufferSize, byte[] outBuffer, int outBufferSize, out int bytesReturned, IntPtr overlapped);
+
+    [DllImport("kernel32.dll", SetLastError = true)]
+    public static extern SafeFileHandle OpenFileById(SafeFileHandle volumeHint, ref FileIdDescriptor fileId, uint desiredAccess, uint shareMode, IntPtr securityAttributes, uint flagsAndAttributes);
+
+    [DllImport("kernel32.dll", SetLastError = true, CharSet = CharSet.Unicode)]
+    public static extern int GetFinalPathNameByHandle(SafeFileHandle file, StringBuilder filePath, int filePathLength, int flags);
+
+    // フォアグラウンドウィンドウの変更（SetWinEventHookのEVENT_SYSTEM_FOREGROUND）
+    public const uint EventSystemForeground = 0x0003;
+    public const uint WinEventOutOfContext = 0x0000;
+    public const uint WinEventSkipOwnProcess = 0x0002;
+    public const uint WmQuit = 0x0012;
+
+    public delegate void WinEventProc(IntPtr hook, uint eventType, IntPtr window, int objectId, int childId, uint eventThreadId, uint eventTime);
+
+    [StructLayout(LayoutKind.Sequential)]
+    public struct Msg
+    {
+        public IntPtr Window;
+        public uint Message;
+        public IntPtr WParam;
+        public IntPtr LParam;
+        public uint Time;
+        public int PointX;
+        public int PointY;
+    }
+
+    [DllImport("user32.dll")]
+    public static extern IntPtr SetWinEventHook(uint eventMin, uint eventMax, IntPtr module, WinEventProc callback, uint processId, uint threadId, uint flags);
+
+    [DllImport("user32.dll")]
+    public static extern bool UnhookWinEvent(IntPtr hook);
+
+    [DllImport("user32.dll")]
+    public static extern int GetMessage(out Msg message, IntPtr window, uint filterMin, uint filterMax);
+
+    [DllImport("user32.dll")]
+    public static extern bool TranslateMessage(ref Msg message);
+
+    [DllImport("user32.dll")]
+    public static extern IntPtr DispatchMessage(ref Msg message);
+
+    [DllImport("user32.dll", SetLastError = true)]
+    public static extern bool PostThreadMessage(uint threadId, uint message, IntPtr wParam, IntPtr lParam);
+
+    [DllImport("user32.dll")]
+    public static extern IntPtr GetForegroundWindow();
+
+    [DllImport("user32.dll")]
+    public static extern uint GetWindowThreadProcessId(IntPtr window, out int processId);
+
+    [DllImport("user32.dll", CharSet = CharSet.Unicode)]
+    public static extern int GetWindowText(IntPtr window, StringBuilder text, int maxCount);
+
+    [DllImport("kernel32.dll")]
+    public static extern uint GetCurrentThreadId();
+
+    // ウィンドウの応答確認（IsHungAppWindowは5秒以上メッセージを処理していないウィンドウで真）
+    public const uint GwOwner = 4;
+
+    public delegate bool EnumWindowsProc(IntPtr window, IntPtr parameter);
+
+    [DllImport("user32.dll")]
+    public static extern bool EnumWindows(EnumWindowsProc callback, IntPtr parameter);
+
+    [DllImport("user32.dll")]
+    public static extern bool IsWindowVisible(IntPtr window);
+
+    [DllImport("user32.dll")]
+    public static extern IntPtr GetWindow(IntPtr window, uint command);
+
+    [DllImport("user32.dll")]
+    public static extern bool IsHungAppWindow(IntPtr window);
+
+    #endregion
+
+    #region macOS
+
+    public const int CtlKern = 1;
+    public const int KernArgMax = 8;
+    public const int KernProcArgs2 = 49;
+
+    // proc_pidinfoのPROC_PIDTBSDINFO（struct proc_bsdinfo）
+    public const int ProcPidTBsdInfo = 3;
+    public const int ProcBsdInfoSize = 136;
+    public const int ProcBsdInfoParentPidOffset = 16;
+    public const int ProcBsdInfoUidOffset = 20;
+
+    [DllImport("libc", SetLastError = true)]
+    public static extern int sysctl(int[] name, uint nameLength, byte[]? oldValue, ref IntPtr oldLength, IntPtr newValue, IntPtr newLength);
+
+    [DllImport("libc", SetLastError = true)]
+    public static extern int getsid(int processId);
+
+    [DllImport("libproc", SetLastError = true)]
+    public static extern int proc_pidinfo(int processId, int flavor, ulong arg, byte[] buffer, int bufferSize);
+
+    #endregion
+}
@@ -0,0 +1,224 @@
+using System.Runtime.InteropServices;
+using System.Text;
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Interfaces;
+
+namespace ProcTail.Infrastructure.Processes;
+
+/// <summary>
+/// 他プロセスの環境変数を読み取る
+/// </summary>
+/// <remarks>
+/// Windowsは対象プロセスのPEBからRTL_USER_PROCESS_PARAMETERS.Environmentを、
+/// Linuxは/proc/&lt;pid&gt;/environを、macOSはsysctl(KERN_PROCARGS2)を読み取る。
+/// いずれも起動時点の環境変数のスナップショットであり、プロセス内での後からの変更は反映されない。
+/// </remarks>
+public class ProcessEnvironmentReader : IProcessEnvironmentReader
+{
+    private readonly ILogger<ProcessEnvironmentReader> _logger;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public ProcessEnvironmentReader(ILogger<ProcessEnvironmentReader> logger)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+    }
+
+    /// <summary>
+    /// 指定した名前の環境変数を読み取る
+    /// </summary>
+    public IReadOnlyDictionary<string, string> ReadEnvironment(int processId, IReadOnlyCollection<string> names)
+    {
+        // Windowsの環境変数名は大文字小文字を区別しない
+        var comparer = OperatingSystem.IsWindows() ? StringComparer.OrdinalIgnoreCase : StringComparer.Ordinal;
+        var result = new Dictionary<string, string>(comparer);
+        if (names.Count == 0)
+        {
+            return result;
+        }
+
+        try
+        {
+            var entries = ReadEnvironmentBlock(processId);
+            var allowed = new HashSet<string>(names, comparer);
+
+            foreach (var entry in entries)
+            {
+                // Windowsの "=C:=C:\..." のような隠し変数は先頭の'='を名前の一部として扱う
+                var separator = entry.IndexOf('=', 1);
+                if (separator <= 0)
+                {
+                    continue;
+                }
+
+                var name = entry[..separator];
+                if (allowed.Contains(name))
+                {
+                    result[name] = entry[(separator + 1)..];
+                }
+            }
+        }
+        catch (Exception ex)
+        {
+            _logger.LogWarning(ex, "環境変数の読み取りに失敗しました (ProcessId: {ProcessId})", processId);
+        }
+
+        return result;
+    }
+
+    /// <summary>
+    /// 環境変数ブロックを "NAME=VALUE" の一覧として読み取る
+    /// </summary>
+    private static IEnumerable<string> ReadEnvironmentBlock(int processId)
+    {
+        if (OperatingSystem.IsWindows())
+        {
+            return ReadWindowsEnvironment(processId);
+        }
+
+        if (OperatingSystem.IsLinux())
+        {
+            return SplitNullSeparated(Encoding.UTF8.GetString(File.ReadAllBytes($"/proc/{processId}/environ")));
+        }
+
+        if (OperatingSystem.IsMacOS())
+        {
+            return ReadMacEnvironment(processId);
+        }
+
+        throw new PlatformNotSupportedException("環境変数の読み取りに対応していないプラットフォームです");
+    }
+
+    private static IEnumerable<string> SplitNullSeparated(string block)
+    {
+        return block.Split('\0', StringSplitOptions.RemoveEmptyEntries);
+    }
+
+    #region Windows
+
+    // x64のPEB/RTL_USER_PROCESS_PARAMETERSのオフセット
+    private const int PebProcessParametersOffset = 0x20;
+    private const int ParametersEnvironmentOffset = 0x80;
+    private const int ParametersEnvironmentSizeOffset = 0x3F0;
+
+    // 異常値で巨大な読み取りを行わないための上限
+    private const int MaxEnvironmentBytes = 1024 * 1024;
+
+    private static IEnumerable<string> ReadWindowsEnvironment(int processId)
+    {
+        if (!Environment.Is64BitProcess)
+        {
+            throw new PlatformNotSupportedException("環境変数の読み取りは64ビットプロセスでのみサポートされます");
+        }
+
+        var process = NativeMethods.OpenProcess(NativeMethods.ProcessQueryInformation | NativeMethods.ProcessVmRead, false, processId);
+        if (process == IntPtr.Zero)
+        {
+            throw new System.ComponentModel.Win32Exception(Marshal.GetLastWin32Error());
+        }
+
+        try
+        {
+            var information = new NativeMethods.ProcessBasicInformation();
+            var status = NativeMethods.NtQueryInformationProcess(process, 0, ref information, Marshal.SizeOf<NativeMethods.ProcessBasicInformation>(), out _);
+            if (status != 0)
+            {
+                throw new InvalidOperationException($"NtQueryInformationProcessが失敗しました (Status: 0x{status:X8})");
+            }
+
+            var parameters = ReadPointer(process, information.PebBaseAddress + PebProcessParametersOffset);
+            var environment = ReadPointer(process, parameters + ParametersEnvironmentOffset);
+            var size = (long)ReadPointer(process, parameters + ParametersEnvironmentSizeOffset);
+            if (size <= 0 || size > MaxEnvironmentBytes)
+            {
+                throw new InvalidOperationException($"環境変数ブロックのサイズが不正です ({size})");
+            }
+
+            var block = ReadMemory(process, environment, (int)size);
+            return SplitNullSeparated(Encoding.Unicode.GetString(block));
+        }
+        finally
+        {
+            NativeMethods.CloseHandle(process);
+        }
+    }
+
+    private static IntPtr ReadPointer(IntPtr process, IntPtr address)
+    {
+        return (IntPtr)BitConverter.ToInt64(ReadMemory(process, address, IntPtr.Size));
+    }
+
+    private static byte[] ReadMemory(IntPtr process, IntPtr address, int size)
+    {
+        var buffer = new byte[size];
+        if (!NativeMethods.ReadProcessMemory(process, address, buffer, (IntPtr)size, out var bytesRead) || (long)bytesRead != size)
+        {
+            throw new System.ComponentModel.Win32Exception(Marshal.GetLastWin32Error());
+        }
+
+        return buffer;
+    }
+
+    #endregion
+
+    #region macOS
+
+    private static IEnumerable<string> ReadMacEnvironment(int processId)
+    {
+        // KERN_PROCARGS2: argc(int32), 実行パス, パディングのNUL, argv[0..argc), 環境変数... の順に並ぶ
+        var argMaxBuffer = new byte[sizeof(int)];
+        var length = (IntPtr)argMaxBuffer.Length;
+        if (NativeMethods.sysctl(new[] { NativeMethods.CtlKern, NativeMethods.KernArgMax }, 2, argMaxBuffer, ref length, IntPtr.Zero, IntPtr.Zero) != 0)
+        {
+            throw new System.ComponentModel.Win32Exception(Marshal.GetLastWin32Error());
+        }
+
+        var buffer = new byte[BitConverter.ToInt32(argMaxBuffer)];
+        length = (IntPtr)buffer.Length;
+        if (NativeMethods.sysctl(new[] { NativeMethods.CtlKern, NativeMethods.KernProcArgs2, processId }, 3, buffer, ref length, IntPtr.Zero, IntPtr.Zero) != 0)
+        {
+            throw new System.ComponentModel.Win32Exception(Marshal.GetLastWin32Error());
+        }
+
+        var argc = BitConverter.ToInt32(buffer, 0);
+        var end = (int)length;
+        var position = sizeof(int);
+
+        // 実行パスとその後のNULパディングを読み飛ばす
+        position = Array.IndexOf(buffer, (byte)0, position, end - position);
+        while (position >= 0 && position < end && buffer[position] == 0)
+        {
+            position++;
+        }
+
+        // 引数は空文字を含み得るため、NUL区切りで個数分だけ読み飛ばしてから環境変数を読む
+        var environment = new List<string>();
+        var index = 0;
+        while (position >= 0 && position < end)
+        {
+            var terminator = Array.IndexOf(buffer, (byte)0, position, end - position);
+            if (terminator < 0)
+            {
+                break;
+            }
+
+            if (index >= argc)
+            {
+                if (terminator == position)
+                {
+                    break;
+                }
+
+                environment.Add(Encoding.UTF8.GetString(buffer, position, terminator - position));
+            }
+
+            index++;
+            position = terminator + 1;
+        }
+
+        return environment;
+    }
+
+    #endregion
+}
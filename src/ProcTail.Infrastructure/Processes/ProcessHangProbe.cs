@@ -0,0 +1,77 @@
+using System.Text;
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Interfaces;
+
+namespace ProcTail.Infrastructure.Processes;
+
+/// <summary>
+/// プロセスの表示中のトップレベルウィンドウが応答しているかどうかを確認する
+/// </summary>
+/// <remarks>
+/// IsHungAppWindowで判定するため、ウィンドウが5秒以上メッセージを処理していない場合に応答なしとなる（タスクマネージャーの「応答なし」と同じ基準）。
+/// 確認対象のメッセージを送らないため、応答しないプロセスがあっても確認自体は待たされない。
+/// 表示中のウィンドウを持たないプロセス（コンソールやバックグラウンドのプロセス）と、Windows以外では判定しない。
+/// </remarks>
+public class ProcessHangProbe : IProcessHangProbe
+{
+    private const int MaxWindowTitleLength = 512;
+
+    private readonly ILogger<ProcessHangProbe> _logger;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public ProcessHangProbe(ILogger<ProcessHangProbe> logger)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+    }
+
+    /// <summary>
+    /// プロセスのウィンドウの応答状態を確認
+    /// </summary>
+    public ProcessWindowState? Probe(int processId)
+    {
+        if (!OperatingSystem.IsWindows())
+        {
+            return null;
+        }
+
+        try
+        {
+            var windows = new List<IntPtr>();
+            NativeMethods.EnumWindows((window, _) =>
+            {
+                // 所有者を持つウィンドウ（ダイアログなど）は所有者のウィンドウで判定する
+                if (NativeMethods.IsWindowVisible(window) &&
+                    NativeMethods.GetWindow(window, NativeMethods.GwOwner) == IntPtr.Zero &&
+                    NativeMethods.GetWindowThreadProcessId(window, out var windowProcessId) != 0 &&
+                    windowProcessId == processId)
+                {
+                    windows.Add(window);
+                }
+                return true;
+            }, IntPtr.Zero);
+
+            if (windows.Count == 0)
+            {
+                return null;
+            }
+
+            var hungWindow = windows.FirstOrDefault(NativeMethods.IsHungAppWindow);
+            return hungWindow != IntPtr.Zero
+                ? new ProcessWindowState(true, GetWindowTitle(hungWindow))
+                : new ProcessWindowState(false, GetWindowTitle(windows[0]));
+        }
+        catch (Exception ex)
+        {
+            _logger.LogDebug(ex, "ウィンドウの応答状態の確認に失敗しました (ProcessId: {ProcessId})", processId);
+            return null;
+        }
+    }
+
+    private static string GetWindowTitle(IntPtr window)
+    {
+        var title = new StringBuilder(MaxWindowTitleLength);
+        return NativeMethods.GetWindowText(window, title, title.Capacity) > 0 ? title.ToString() : string.Empty;
+    }
+}
@@ -0,0 +1,129 @@
+using System.Diagnostics;
+using System.Runtime.Versioning;
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Interfaces;
+
+namespace ProcTail.Infrastructure.Processes;
+
+/// <summary>
+/// 他プロセスのCPU時間・ワーキングセット・ハンドル数・I/Oカウンタを読み取る
+/// </summary>
+/// <remarks>
+/// CPU時間とワーキングセットは全OSでSystem.Diagnostics.Processから取得する。
+/// Windowsはハンドル数もProcessから、I/OカウンタはGetProcessIoCountersから取得する。
+/// Linuxは/proc/&lt;pid&gt;/fdのエントリ数をハンドル数とし、/proc/&lt;pid&gt;/ioのsyscr/syscw/rchar/wcharをI/Oカウンタとする
+/// （WindowsのI/Oカウンタと同様に、ディスク以外のI/Oも含む）。macOSのI/Oカウンタは取得しない。
+/// </remarks>
+public class ProcessResourceReader : IProcessResourceReader
+{
+    private readonly ILogger<ProcessResourceReader> _logger;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public ProcessResourceReader(ILogger<ProcessResourceReader> logger)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+    }
+
+    /// <summary>
+    /// プロセスのリソース使用量を読み取る
+    /// </summary>
+    public ProcessResourceUsage? ReadUsage(int processId)
+    {
+        try
+        {
+            using var process = Process.GetProcessById(processId);
+            var usage = new ProcessResourceUsage(process.TotalProcessorTime, process.UserProcessorTime, process.WorkingSet64, process.HandleCount);
+
+            if (OperatingSystem.IsWindows())
+            {
+                return ReadWindowsIoCounters(processId, usage);
+            }
+
+            if (OperatingSystem.IsLinux())
+            {
+                try
+                {
+                    return ReadLinuxCounters(processId, usage);
+                }
+                catch (Exception ex) when (ex is IOException or UnauthorizedAccessException)
+                {
+                    // 権限がない場合もCPU時間とワーキングセットは記録する
+                    _logger.LogTrace(ex, "I/Oカウンタの取得に失敗しました (ProcessId: {ProcessId})", processId);
+                    return usage;
+                }
+            }
+
+            return usage;
+        }
+        catch (Exception ex)
+        {
+            _logger.LogTrace(ex, "リソース使用量の取得に失敗しました (ProcessId: {ProcessId})", processId);
+        }
+
+        return null;
+    }
+
+    #region Windows
+
+    [SupportedOSPlatform("windows")]
+    private static ProcessResourceUsage ReadWindowsIoCounters(int processId, ProcessResourceUsage usage)
+    {
+        var process = NativeMethods.OpenProcess(NativeMethods.ProcessQueryLimitedInformation, false, processId);
+        if (process == IntPtr.Zero)
+        {
+            return usage;
+        }
+
+        try
+        {
+            if (!NativeMethods.GetProcessIoCounters(process, out var counters))
+            {
+                return usage;
+            }
+
+            return usage with
+            {
+                ReadOperations = (long)counters.ReadOperationCount,
+                WriteOperations = (long)counters.WriteOperationCount,
+                ReadBytes = (long)counters.ReadTransferCount,
+                WriteBytes = (long)counters.WriteTransferCount
+            };
+        }
+        finally
+        {
+            NativeMethods.CloseHandle(process);
+        }
+    }
+
+    #endregion
+
+    #region Linux
+
+    private static ProcessResourceUsage ReadLinuxCounters(int processId, ProcessResourceUsage usage)
+    {
+        usage = usage with { HandleCount = Directory.EnumerateFileSystemEntries($"/proc/{processId}/fd").Count() };
+
+        // /proc/<pid>/io: "rchar: N" の形式で1行1項目（他ユーザーのプロセスは読めない）
+        var counters = new Dictionary<string, long>(StringComparer.Ordinal);
+        foreach (var line in File.ReadLines($"/proc/{processId}/io"))
+        {
+            var separator = line.IndexOf(':');
+            if (separator > 0 && long.TryParse(line[(separator + 1)..].Trim(), out var value))
+            {
+                counters[line[..separator]] = value;
+            }
+        }
+
+        return usage with
+        {
+            ReadOperations = counters.TryGetValue("syscr", out var readOperations) ? readOperations : null,
+            WriteOperations = counters.TryGetValue("syscw", out var writeOperations) ? writeOperations : null,
+            ReadBytes = counters.TryGetValue("rchar", out var readBytes) ? readBytes : null,
+            WriteBytes = counters.TryGetValue("wchar", out var writeBytes) ? writeBytes : null
+        };
+    }
+
+    #endregion
+}
@@ -0,0 +1,172 @@
+using System.Runtime.InteropServices;
+using System.Runtime.Versioning;
+using System.Security.Principal;
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Interfaces;
+
+namespace ProcTail.Infrastructure.Processes;
+
+/// <summary>
+/// 他プロセスのユーザー・セッション・整合性レベルを読み取る
+/// </summary>
+/// <remarks>
+/// Windowsはプロセストークン（TokenUser/TokenSessionId/TokenIntegrityLevel）を、
+/// Linuxは/proc/&lt;pid&gt;/statusの実効UIDと/proc/&lt;pid&gt;/statのセッションIDを、
+/// macOSはproc_pidinfo(PROC_PIDTBSDINFO)のUIDと getsid(2) を使用する。
+/// 昇格したアップデーターや別ユーザーが起動した同じ実行ファイルを区別するために使う。
+/// </remarks>
+public class ProcessTokenReader : IProcessTokenReader
+{
+    private readonly ILogger<ProcessTokenReader> _logger;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public ProcessTokenReader(ILogger<ProcessTokenReader> logger)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+    }
+
+    /// <summary>
+    /// プロセスのトークン情報を読み取る
+    /// </summary>
+    public ProcessTokenInfo? ReadToken(int processId)
+    {
+        try
+        {
+            if (OperatingSystem.IsWindows())
+            {
+                return ReadWindowsToken(processId);
+            }
+
+            if (OperatingSystem.IsLinux())
+            {
+                return ReadLinuxToken(processId);
+            }
+
+            if (OperatingSystem.IsMacOS())
+            {
+                return ReadMacToken(processId);
+            }
+        }
+        catch (Exception ex)
+        {
+            _logger.LogTrace(ex, "トークン情報の取得に失敗しました (ProcessId: {ProcessId})", processId);
+        }
+
+        return null;
+    }
+
+    #region Windows
+
+    [SupportedOSPlatform("windows")]
+    private static ProcessTokenInfo? ReadWindowsToken(int processId)
+    {
+        var process = NativeMethods.OpenProcess(NativeMethods.ProcessQueryLimitedInformation, false, processId);
+        if (process == IntPtr.Zero)
+        {
+            return null;
+        }
+
+        try
+        {
+            if (!NativeMethods.OpenProcessToken(process, NativeMethods.TokenQuery, out var token))
+            {
+                return null;
+            }
+
+            try
+            {
+                // TOKEN_USER・TOKEN_MANDATORY_LABELはいずれも先頭がSID_AND_ATTRIBUTES（SIDへのポインタ）
+                var userSid = ReadTokenInformation(token, NativeMethods.TokenUser, buffer => new SecurityIdentifier(Marshal.ReadIntPtr(buffer)));
+                var integritySid = ReadTokenInformation(token, NativeMethods.TokenIntegrityLevel, buffer => new SecurityIdentifier(Marshal.ReadIntPtr(buffer)));
+                var sessionId = ReadTokenInformation(token, NativeMethods.TokenSessionId, buffer => (int?)Marshal.ReadInt32(buffer));
+
+                return new ProcessTokenInfo(userSid?.Value, sessionId, integritySid == null ? null : ToIntegrityLevel(integritySid));
+            }
+            finally
+            {
+                NativeMethods.CloseHandle(token);
+            }
+        }
+        finally
+        {
+            NativeMethods.CloseHandle(process);
+        }
+    }
+
+    [SupportedOSPlatform("windows")]
+    private static T? ReadTokenInformation<T>(IntPtr token, int informationClass, Func<IntPtr, T> read)
+    {
+        NativeMethods.GetTokenInformation(token, informationClass, IntPtr.Zero, 0, out var length);
+        if (length <= 0)
+        {
+            return default;
+        }
+
+        var buffer = Marshal.AllocHGlobal(length);
+        try
+        {
+            return NativeMethods.GetTokenInformation(token, informationClass, buffer, length, out _) ? read(buffer) : default;
+        }
+        finally
+        {
+            Marshal.FreeHGlobal(buffer);
+        }
+    }
+
+    /// <summary>
+    /// 整合性レベルのSID（S-1-16-RID）を名前に変換
+    /// </summary>
+    [SupportedOSPlatform("windows")]
+    private static string ToIntegrityLevel(SecurityIdentifier integritySid)
+    {
+        var value = integritySid.Value;
+        var rid = uint.Parse(value[(value.LastIndexOf('-') + 1)..]);
+        return rid switch
+        {
+            < 0x1000 => "Untrusted",
+            < 0x2000 => "Low",
+            < 0x2100 => "Medium",
+            < 0x3000 => "MediumPlus",
+            < 0x4000 => "High",
+            < 0x5000 => "System",
+            _ => "Protected"
+        };
+    }
+
+    #endregion
+
+    #region Linux/macOS
+
+    private static ProcessTokenInfo? ReadLinuxToken(int processId)
+    {
+        // "Uid:\t実UID\t実効UID\t保存UID\tファイルシステムUID"
+        var uidLine = File.ReadLines($"/proc/{processId}/status").FirstOrDefault(line => line.StartsWith("Uid:", StringComparison.Ordinal));
+        var uids = uidLine?.Split('\t', StringSplitOptions.RemoveEmptyEntries);
+        var userId = uids != null && uids.Length > 2 ? uids[2] : null;
+
+        // /proc/<pid>/stat: "pid (comm) state ppid pgrp session ..."
+        var stat = File.ReadAllText($"/proc/{processId}/stat");
+        var fields = stat[(stat.LastIndexOf(')') + 2)..].Split(' ');
+        int? sessionId = fields.Length > 3 && int.TryParse(fields[3], out var session) ? session : null;
+
+        return new ProcessTokenInfo(userId, sessionId, null);
+    }
+
+    private static ProcessTokenInfo? ReadMacToken(int processId)
+    {
+        var buffer = new byte[NativeMethods.ProcBsdInfoSize];
+        var size = NativeMethods.proc_pidinfo(processId, NativeMethods.ProcPidTBsdInfo, 0, buffer, buffer.Length);
+        if (size != buffer.Length)
+        {
+            return null;
+        }
+
+        var userId = BitConverter.ToUInt32(buffer, NativeMethods.ProcBsdInfoUidOffset);
+        var sessionId = NativeMethods.getsid(processId);
+        return new ProcessTokenInfo(userId.ToString(), sessionId >= 0 ? sessionId : null, null);
+    }
+
+    #endregion
+}
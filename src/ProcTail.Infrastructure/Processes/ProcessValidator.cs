@@ -0,0 +1,212 @@
+using System.Diagnostics;
+using System.Runtime.InteropServices;
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Interfaces;
+
+namespace ProcTail.Infrastructure.Processes;
+
+/// <summary>
+/// OSのプロセス情報を使用したプロセス検証
+/// </summary>
+/// <remarks>
+/// 親プロセスIDは.NETのProcessクラスから取得できないため、
+/// WindowsはNtQueryInformationProcess、Linuxは/proc/&lt;pid&gt;/stat、macOSはproc_pidinfoから取得する。
+/// </remarks>
+public class ProcessValidator : IProcessValidator
+{
+    private readonly ILogger<ProcessValidator> _logger;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    public ProcessValidator(ILogger<ProcessValidator> logger)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+    }
+
+    /// <summary>
+    /// プロセスが存在するかチェック
+    /// </summary>
+    public bool ProcessExists(int processId)
+    {
+        try
+        {
+            using var process = Process.GetProcessById(processId);
+            return !process.HasExited;
+        }
+        catch (ArgumentException)
+        {
+            return false;
+        }
+        catch (InvalidOperationException)
+        {
+            return false;
+        }
+    }
+
+    /// <summary>
+    /// プロセス情報を取得
+    /// </summary>
+    public ProcessInfo? GetProcessInfo(int processId)
+    {
+        try
+        {
+            using var process = Process.GetProcessById(processId);
+            return new ProcessInfo(
+                processId,
+                process.ProcessName,
+                GetExecutablePath(process),
+                GetStartTime(process),
+                GetParentProcessId(processId),
+                GetSessionId(process)
+            );
+        }
+        catch (ArgumentException)
+        {
+            // プロセスが既に終了している場合
+            return null;
+        }
+        catch (Exception ex)
+        {
+            _logger.LogDebug(ex, "プロセス情報の取得に失敗しました (ProcessId: {ProcessId})", processId);
+            return null;
+        }
+    }
+
+    /// <summary>
+    /// 子プロセス一覧を取得
+    /// </summary>
+    public IReadOnlyList<ProcessInfo> GetChildProcesses(int parentProcessId)
+    {
+        var children = new List<ProcessInfo>();
+
+        foreach (var process in Process.GetProcesses())
+        {
+            using (process)
+            {
+                if (GetParentProcessId(process.Id) != parentProcessId)
+                {
+                    continue;
+                }
+
+                var info = GetProcessInfo(process.Id);
+                if (info != null)
+                {
+                    children.Add(info);
+                }
+            }
+        }
+
+        return children;
+    }
+
+    /// <summary>
+    /// 実行ファイルパスを取得
+    /// </summary>
+    private static string GetExecutablePath(Process process)
+    {
+        try
+        {
+            return process.MainModule?.FileName ?? "[Unknown]";
+        }
+        catch
+        {
+            return "[Access Denied]";
+        }
+    }
+
+    /// <summary>
+    /// 開始時刻を取得（権限不足の場合はDateTime.MinValue）
+    /// </summary>
+    private static DateTime GetStartTime(Process process)
+    {
+        try
+        {
+            return process.StartTime.ToUniversalTime();
+        }
+        catch
+        {
+            return DateTime.MinValue;
+        }
+    }
+
+    /// <summary>
+    /// セッションIDを取得（取得できない場合はnull）
+    /// </summary>
+    private static int? GetSessionId(Process process)
+    {
+        try
+        {
+            return process.SessionId;
+        }
+        catch
+        {
+            return null;
+        }
+    }
+
+    /// <summary>
+    /// 親プロセスIDを取得
+    /// </summary>
+    private int? GetParentProcessId(int processId)
+    {
+        try
+        {
+            if (OperatingSystem.IsWindows())
+            {
+                return GetWindowsParentProcessId(processId);
+            }
+
+            if (OperatingSystem.IsLinux())
+            {
+                return GetLinuxParentProcessId(processId);
+            }
+
+            if (OperatingSystem.IsMacOS())
+            {
+                return GetMacParentProcessId(processId);
+            }
+        }
+        catch (Exception ex)
+        {
+            _logger.LogTrace(ex, "親プロセスIDの取得に失敗しました (ProcessId: {ProcessId})", processId);
+        }
+
+        return null;
+    }
+
+    private static int? GetWindowsParentProcessId(int processId)
+    {
+        var process = NativeMethods.OpenProcess(NativeMethods.ProcessQueryLimitedInformation, false, processId);
+        if (process == IntPtr.Zero)
+        {
+            return null;
+        }
+
+        try
+        {
+            var information = new NativeMethods.ProcessBasicInformation();
+            var status = NativeMethods.NtQueryInformationProcess(process, 0, ref information, Marshal.SizeOf<NativeMethods.ProcessBasicInformation>(), out _);
+            return status == 0 ? (int)information.InheritedFromUniqueProcessId : null;
+        }
+        finally
+        {
+            NativeMethods.CloseHandle(process);
+        }
+    }
+
+    private static int? GetLinuxParentProcessId(int processId)
+    {
+        // /proc/<pid>/stat: "pid (comm) state ppid ..." commは空白や括弧を含み得るため最後の')'以降を解析する
+        var stat = File.ReadAllText($"/proc/{processId}/stat");
+        var fields = stat[(stat.LastIndexOf(')') + 2)..].Split(' ');
+        return fields.Length > 1 && int.TryParse(fields[1], out var parentId) ? parentId : null;
+    }
+
+    private static int? GetMacParentProcessId(int processId)
+    {
+        var buffer = new byte[NativeMethods.ProcBsdInfoSize];
+        var size = NativeMethods.proc_pidinfo(processId, NativeMethods.ProcPidTBsdInfo, 0, buffer, buffer.Length);
+        return size == buffer.Length ? BitConverter.ToInt32(buffer, NativeMethods.ProcBsdInfoParentPidOffset) : null;
+    }
+}
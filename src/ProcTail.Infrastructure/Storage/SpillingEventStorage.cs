@@ -0,0 +1,568 @@
+using System.IO.MemoryMappedFiles;
+using System.Text;
+using System.Text.Json;
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
+using ZstdSharp;
+
+namespace ProcTail.Infrastructure.Storage;
+
+/// <summary>
+/// 新しいイベントをメモリに、古いイベントをディスク上のセグメントに保持するストレージ
+/// </summary>
+/// <remarks>
+/// タグごとのメモリ上のイベントが上限を超えると、古い方からセグメント単位でメモリマップトファイルに書き出す。
+/// セグメントの合計サイズがディスク上限を超える場合、BackpressurePolicy.DropOldestでは最も古いセグメントを削除し、
+/// それ以外では新しいイベントを破棄する（Blockはクリアで空きができるまで待機する）。
+/// セグメントは1行1イベントのポリモーフィックJSONで、既定ではzstdで圧縮して書き出す（ファイルパスの多いイベントはよく縮む）。
+/// ディスク上限は圧縮後のサイズで判定する。サービス起動時に前回のセグメントは削除する。
+/// </remarks>
+public class SpillingEventStorage : IEventStorage, IDisposable
+{
+    private const string SegmentExtension = ".seg";
+    private const int EstimatedEventSize = 1024;
+
+    private readonly ILogger<SpillingEventStorage> _logger;
+    private readonly string _spillDirectory;
+    private readonly int _maxHotEventsPerTag;
+    private readonly int _segmentEvents;
+    private readonly long _maxDiskBytes;
+    private readonly bool _compress;
+    private readonly Dictionary<string, TagBuffer> _buffers = new();
+    private readonly Dictionary<string, (BackpressurePolicy Policy, TimeSpan BlockTimeout)> _backpressurePolicies = new();
+    private readonly Dictionary<string, long> _droppedCounts = new();
+    private readonly Dictionary<string, long> _sequenceNumbers = new();
+    private readonly object _lockObject = new();
+    private TaskCompletionSource _spaceReleased = new(TaskCreationOptions.RunContinuationsAsynchronously);
+    private long _diskBytes;
+    private long _nextSegmentId;
+    private bool _disposed;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    /// <param name="logger">ロガー</param>
+    /// <param name="spillDirectory">セグメントを書き出すディレクトリ</param>
+    /// <param name="maxHotEventsPerTag">タグごとにメモリに保持する最大イベント数</param>
+    /// <param name="maxDiskBytes">全タグ合計のセグメントの最大サイズ（バイト）</param>
+    /// <param name="segmentEvents">1セグメントに書き出すイベント数（0以下の場合はメモリ上限の半分）</param>
+    /// <param name="compress">セグメントをzstdで圧縮するかどうか</param>
+    public SpillingEventStorage(
+        ILogger<SpillingEventStorage> logger,
+        string spillDirectory,
+        int maxHotEventsPerTag = 1000,
+        long maxDiskBytes = 1024L * 1024 * 1024,
+        int segmentEvents = 0,
+        bool compress = true)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+        if (string.IsNullOrWhiteSpace(spillDirectory))
+        {
+            throw new ArgumentException("書き出し先ディレクトリが指定されていません", nameof(spillDirectory));
+        }
+
+        _spillDirectory = Path.GetFullPath(spillDirectory);
+        _maxHotEventsPerTag = maxHotEventsPerTag > 0 ? maxHotEventsPerTag : 1000;
+        _segmentEvents = segmentEvents > 0 ? Math.Min(segmentEvents, _maxHotEventsPerTag) : Math.Max(1, _maxHotEventsPerTag / 2);
+        _maxDiskBytes = Math.Max(0, maxDiskBytes);
+        _compress = compress;
+
+        // 前回のセグメントは対応するタグ情報がないため削除
+        Directory.CreateDirectory(_spillDirectory);
+        foreach (var stale in Directory.EnumerateFiles(_spillDirectory, "*" + SegmentExtension))
+        {
+            File.Delete(stale);
+        }
+
+        _logger.LogInformation("SpillingEventStorageが初期化されました (Directory: {SpillDirectory}, MaxHotEventsPerTag: {MaxHotEventsPerTag}, MaxDiskMB: {MaxDiskMB}, Compress: {Compress})",
+            _spillDirectory, _maxHotEventsPerTag, _maxDiskBytes / 1024 / 1024, _compress);
+    }
+
+    /// <summary>
+    /// イベントを記録
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="eventData">イベントデータ</param>
+    /// <returns>連番を付与して記録したイベント（破棄した場合や記録できなかった場合はnull）</returns>
+    public async Task<BaseEventData?> StoreEventAsync(string tagName, BaseEventData eventData)
+    {
+        if (string.IsNullOrWhiteSpace(tagName))
+        {
+            _logger.LogWarning("イベント記録に失敗: タグ名が無効です");
+            return null;
+        }
+
+        if (eventData == null)
+        {
+            _logger.LogWarning("イベント記録に失敗: イベントデータがnullです (Tag: {TagName})", tagName);
+            return null;
+        }
+
+        try
+        {
+            var (policy, blockTimeout) = GetBackpressurePolicy(tagName);
+            var deadline = DateTime.UtcNow + (policy == BackpressurePolicy.Block ? blockTimeout : TimeSpan.Zero);
+            while (true)
+            {
+                // 空きの確認より先に通知を取得し、確認後の解放を取りこぼさない
+                var spaceReleased = Volatile.Read(ref _spaceReleased).Task;
+                var stored = await Task.Run(() => TryStore(tagName, eventData, policy));
+                if (stored != null)
+                {
+                    return stored;
+                }
+
+                var remaining = deadline - DateTime.UtcNow;
+                if (remaining <= TimeSpan.Zero)
+                {
+                    break;
+                }
+
+                try
+                {
+                    await spaceReleased.WaitAsync(remaining);
+                }
+                catch (TimeoutException)
+                {
+                    break;
+                }
+            }
+
+            // 破棄したイベントも連番を消費し、利用側で欠番として検出できるようにする
+            lock (_lockObject)
+            {
+                NextSequenceNumber(tagName);
+            }
+            AddDropped(tagName, 1);
+            _logger.LogDebug("ディスク上限に達したためイベントを破棄しました (Tag: {TagName}, Policy: {Policy})", tagName, policy);
+            return null;
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "イベント記録中にエラーが発生しました (Tag: {TagName}, EventType: {EventType})",
+                tagName, eventData.GetType().Name);
+            return null;
+        }
+    }
+
+    /// <summary>
+    /// タグに関連するイベントを取得
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <returns>イベント一覧（記録順）</returns>
+    public Task<IReadOnlyList<BaseEventData>> GetEventsAsync(string tagName)
+    {
+        return ReadEventsAsync(tagName, _ => true, events => events);
+    }
+
+    /// <summary>
+    /// タグに関連するイベントをクリア
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <returns>非同期タスク</returns>
+    public async Task ClearEventsAsync(string tagName)
+    {
+        if (string.IsNullOrWhiteSpace(tagName))
+        {
+            _logger.LogWarning("イベントクリアに失敗: タグ名が無効です");
+            return;
+        }
+
+        try
+        {
+            await Task.Run(() =>
+            {
+                lock (_lockObject)
+                {
+                    if (!_buffers.Remove(tagName, out var buffer))
+                    {
+                        _logger.LogDebug("クリア対象のイベントが見つかりません (Tag: {TagName})", tagName);
+                        return;
+                    }
+
+                    var removedCount = buffer.Count;
+                    foreach (var segment in buffer.Segments)
+                    {
+                        DeleteSegment(segment);
+                    }
+
+                    _logger.LogInformation("イベントをクリアしました (Tag: {TagName}, RemovedCount: {RemovedCount})", tagName, removedCount);
+                }
+            });
+
+            SignalSpaceReleased();
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "イベントクリア中にエラーが発生しました (Tag: {TagName})", tagName);
+        }
+    }
+
+    /// <summary>
+    /// 全イベント数を取得
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <returns>イベント数</returns>
+    public Task<int> GetEventCountAsync(string tagName)
+    {
+        lock (_lockObject)
+        {
+            return Task.FromResult(!string.IsNullOrWhiteSpace(tagName) && _buffers.TryGetValue(tagName, out var buffer) ? buffer.Count : 0);
+        }
+    }
+
+    /// <summary>
+    /// ストレージ統計情報
+    /// </summary>
+    /// <returns>統計情報</returns>
+    public Task<StorageStatistics> GetStatisticsAsync()
+    {
+        lock (_lockObject)
+        {
+            var eventCountByTag = _buffers.ToDictionary(kvp => kvp.Key, kvp => kvp.Value.Count);
+            var bufferStatisticsByTag = _buffers.Keys
+                .Concat(_backpressurePolicies.Keys)
+                .Concat(_droppedCounts.Keys)
+                .Distinct()
+                .ToDictionary(
+                    tag => tag,
+                    tag => new TagBufferStatistics(GetBackpressurePolicy(tag).Policy, _droppedCounts.GetValueOrDefault(tag)));
+
+            // ディスク上のセグメントはメモリ使用量に含めない
+            var hotEvents = _buffers.Values.Sum(buffer => buffer.Hot.Count);
+            return Task.FromResult(new StorageStatistics(
+                _buffers.Count,
+                eventCountByTag.Values.Sum(),
+                eventCountByTag,
+                (long)hotEvents * EstimatedEventSize,
+                bufferStatisticsByTag));
+        }
+    }
+
+    /// <summary>
+    /// 全てのタグ名を取得
+    /// </summary>
+    /// <returns>タグ名のリスト</returns>
+    public Task<IReadOnlyList<string>> GetAllTagsAsync()
+    {
+        lock (_lockObject)
+        {
+            return Task.FromResult<IReadOnlyList<string>>(_buffers.Keys.ToList());
+        }
+    }
+
+    /// <summary>
+    /// 期間を指定してイベントを取得
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="startTime">開始時刻</param>
+    /// <param name="endTime">終了時刻</param>
+    /// <returns>フィルタされたイベント一覧</returns>
+    public Task<IReadOnlyList<BaseEventData>> GetEventsByTimeRangeAsync(string tagName, DateTime startTime, DateTime endTime)
+    {
+        // 期間と重ならないセグメントは読み込まない
+        return ReadEventsAsync(
+            tagName,
+            segment => segment.LastTimestamp >= startTime && segment.FirstTimestamp <= endTime,
+            events => events.Where(e => e.Timestamp >= startTime && e.Timestamp <= endTime));
+    }
+
+    /// <summary>
+    /// 最新のN件のイベントを取得
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="count">取得件数</param>
+    /// <returns>最新のイベント一覧</returns>
+    public async Task<IReadOnlyList<BaseEventData>> GetLatestEventsAsync(string tagName, int count)
+    {
+        if (count <= 0)
+        {
+            return Array.Empty<BaseEventData>();
+        }
+
+        var events = await GetEventsAsync(tagName);
+        return events.OrderByDescending(e => e.Timestamp).Take(count).ToList();
+    }
+
+    /// <summary>
+    /// タグのバッファが満杯の場合の動作を設定
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="policy">満杯時の動作</param>
+    /// <param name="blockTimeout">BackpressurePolicy.Blockで空きを待つ最大時間</param>
+    public void SetBackpressurePolicy(string tagName, BackpressurePolicy policy, TimeSpan blockTimeout)
+    {
+        if (string.IsNullOrWhiteSpace(tagName))
+        {
+            return;
+        }
+
+        lock (_lockObject)
+        {
+            _backpressurePolicies[tagName] = (policy, blockTimeout > TimeSpan.Zero ? blockTimeout : TimeSpan.Zero);
+        }
+
+        _logger.LogInformation("バッファ満杯時の動作を設定しました (Tag: {TagName}, Policy: {Policy}, BlockTimeout: {BlockTimeout})",
+            tagName, policy, blockTimeout);
+    }
+
+    /// <summary>
+    /// リソースの解放
+    /// </summary>
+    public void Dispose()
+    {
+        if (_disposed)
+            return;
+
+        _disposed = true;
+        lock (_lockObject)
+        {
+            foreach (var segment in _buffers.Values.SelectMany(buffer => buffer.Segments))
+            {
+                DeleteSegment(segment);
+            }
+            _buffers.Clear();
+        }
+
+        _logger.LogInformation("SpillingEventStorageが解放されました");
+    }
+
+    /// <summary>
+    /// イベントを追加し、メモリ上限を超えた分をセグメントに書き出す
+    /// </summary>
+    /// <returns>連番を付与して記録したイベント（ディスク上限のため記録できない場合はnull）</returns>
+    private BaseEventData? TryStore(string tagName, BaseEventData eventData, BackpressurePolicy policy)
+    {
+        lock (_lockObject)
+        {
+            if (!_buffers.TryGetValue(tagName, out var buffer))
+            {
+                buffer = new TagBuffer();
+                _buffers[tagName] = buffer;
+            }
+
+            if (buffer.Hot.Count >= _maxHotEventsPerTag)
+            {
+                var spilled = buffer.Hot.Take(_segmentEvents).ToList();
+                var bytes = Encoding.UTF8.GetBytes(string.Concat(spilled.Select(e => JsonSerializer.Serialize(e) + "\n")));
+                if (_compress)
+                {
+                    using var compressor = new Compressor();
+                    bytes = compressor.Wrap(bytes).ToArray();
+                }
+
+                if (!EnsureDiskSpace(tagName, bytes.Length, policy))
+                {
+                    return null;
+                }
+
+                buffer.Segments.AddLast(WriteSegment(tagName, spilled, bytes));
+                for (var i = 0; i < spilled.Count; i++)
+                {
+                    buffer.Hot.Dequeue();
+                }
+            }
+
+            var stored = eventData with { SequenceNumber = NextSequenceNumber(tagName) };
+            buffer.Hot.Enqueue(stored);
+            return stored;
+        }
+    }
+
+    /// <summary>
+    /// タグの次の連番を取得（_lockObjectの取得中に呼び出す。クリア後も番号は戻さない）
+    /// </summary>
+    private long NextSequenceNumber(string tagName)
+    {
+        var sequenceNumber = _sequenceNumbers.GetValueOrDefault(tagName) + 1;
+        _sequenceNumbers[tagName] = sequenceNumber;
+        return sequenceNumber;
+    }
+
+    /// <summary>
+    /// ディスク上限内に書き出せるよう空きを確保（_lockObjectの取得中に呼び出す）
+    /// </summary>
+    private bool EnsureDiskSpace(string tagName, long requiredBytes, BackpressurePolicy policy)
+    {
+        if (_diskBytes + requiredBytes <= _maxDiskBytes)
+        {
+            return true;
+        }
+
+        if (policy != BackpressurePolicy.DropOldest || requiredBytes > _maxDiskBytes)
+        {
+            return false;
+        }
+
+        // 自タグの最も古いセグメントから削除し、なければ全タグで最も古いセグメントを削除
+        while (_diskBytes + requiredBytes > _maxDiskBytes)
+        {
+            var owner = _buffers.TryGetValue(tagName, out var own) && own.Segments.Count > 0
+                ? own
+                : _buffers.Values.Where(buffer => buffer.Segments.Count > 0).MinBy(buffer => buffer.Segments.First!.Value.Id);
+            if (owner == null)
+            {
+                return false;
+            }
+
+            var oldest = owner.Segments.First!.Value;
+            owner.Segments.RemoveFirst();
+            DeleteSegment(oldest);
+            AddDropped(oldest.TagName, oldest.Count);
+        }
+
+        return true;
+    }
+
+    private SpillSegment WriteSegment(string tagName, IReadOnlyList<BaseEventData> events, byte[] bytes)
+    {
+        var id = ++_nextSegmentId;
+        var path = Path.Combine(_spillDirectory, $"{id:D12}{SegmentExtension}");
+
+        using (var file = MemoryMappedFile.CreateFromFile(path, FileMode.CreateNew, null, bytes.Length, MemoryMappedFileAccess.ReadWrite))
+        using (var accessor = file.CreateViewAccessor(0, bytes.Length))
+        {
+            accessor.WriteArray(0, bytes, 0, bytes.Length);
+        }
+
+        _diskBytes += bytes.Length;
+        _logger.LogDebug("イベントをディスクに書き出しました (Tag: {TagName}, Count: {Count}, Bytes: {Bytes}, DiskBytes: {DiskBytes})",
+            tagName, events.Count, bytes.Length, _diskBytes);
+
+        return new SpillSegment(
+            id,
+            tagName,
+            path,
+            bytes.Length,
+            _compress,
+            events.Count,
+            events.Min(e => e.Timestamp),
+            events.Max(e => e.Timestamp));
+    }
+
+    private static IEnumerable<BaseEventData> ReadSegment(SpillSegment segment)
+    {
+        var bytes = new byte[segment.Length];
+        using (var file = MemoryMappedFile.CreateFromFile(segment.Path, FileMode.Open, null, 0, MemoryMappedFileAccess.Read))
+        using (var accessor = file.CreateViewAccessor(0, segment.Length, MemoryMappedFileAccess.Read))
+        {
+            accessor.ReadArray(0, bytes, 0, bytes.Length);
+        }
+
+        if (segment.Compressed)
+        {
+            using var decompressor = new Decompressor();
+            bytes = decompressor.Unwrap(bytes).ToArray();
+        }
+
+        foreach (var line in Encoding.UTF8.GetString(bytes).Split('\n', StringSplitOptions.RemoveEmptyEntries))
+        {
+            var eventData = JsonSerializer.Deserialize<BaseEventData>(line);
+            if (eventData != null)
+            {
+                yield return eventData;
+            }
+        }
+    }
+
+    private void DeleteSegment(SpillSegment segment)
+    {
+        _diskBytes -= segment.Length;
+        try
+        {
+            File.Delete(segment.Path);
+        }
+        catch (IOException ex)
+        {
+            _logger.LogWarning(ex, "セグメントの削除に失敗しました (Path: {Path})", segment.Path);
+        }
+    }
+
+    private async Task<IReadOnlyList<BaseEventData>> ReadEventsAsync(
+        string tagName,
+        Func<SpillSegment, bool> segmentFilter,
+        Func<IEnumerable<BaseEventData>, IEnumerable<BaseEventData>> eventFilter)
+    {
+        if (string.IsNullOrWhiteSpace(tagName))
+        {
+            _logger.LogWarning("イベント取得に失敗: タグ名が無効です");
+            return Array.Empty<BaseEventData>();
+        }
+
+        try
+        {
+            return await Task.Run(() =>
+            {
+                lock (_lockObject)
+                {
+                    if (!_buffers.TryGetValue(tagName, out var buffer))
+                    {
+                        _logger.LogDebug("イベントが見つかりません (Tag: {TagName})", tagName);
+                        return Array.Empty<BaseEventData>();
+                    }
+
+                    var events = buffer.Segments
+                        .Where(segmentFilter)
+                        .SelectMany(ReadSegment)
+                        .Concat(buffer.Hot);
+                    return (IReadOnlyList<BaseEventData>)eventFilter(events).ToList();
+                }
+            });
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "イベント取得中にエラーが発生しました (Tag: {TagName})", tagName);
+            return Array.Empty<BaseEventData>();
+        }
+    }
+
+    private (BackpressurePolicy Policy, TimeSpan BlockTimeout) GetBackpressurePolicy(string tagName)
+    {
+        lock (_lockObject)
+        {
+            return _backpressurePolicies.TryGetValue(tagName, out var setting)
+                ? setting
+                : (BackpressurePolicy.DropOldest, TimeSpan.Zero);
+        }
+    }
+
+    private void AddDropped(string tagName, long count)
+    {
+        lock (_lockObject)
+        {
+            _droppedCounts[tagName] = _droppedCounts.GetValueOrDefault(tagName) + count;
+        }
+    }
+
+    /// <summary>
+    /// ディスクの空きを待機中の記録に通知
+    /// </summary>
+    private void SignalSpaceReleased()
+    {
+        Interlocked.Exchange(ref _spaceReleased, new TaskCompletionSource(TaskCreationOptions.RunContinuationsAsynchronously)).TrySetResult();
+    }
+
+    /// <summary>
+    /// タグのメモリ上のイベントとディスク上のセグメント
+    /// </summary>
+    private sealed class TagBuffer
+    {
+        public Queue<BaseEventData> Hot { get; } = new();
+        public LinkedList<SpillSegment> Segments { get; } = new();
+        public int Count => Hot.Count + Segments.Sum(segment => segment.Count);
+    }
+
+    /// <summary>
+    /// ディスクに書き出したイベントの塊
+    /// </summary>
+    private sealed record SpillSegment(
+        long Id,
+        string TagName,
+        string Path,
+        int Length,
+        bool Compressed,
+        int Count,
+        DateTime FirstTimestamp,
+        DateTime LastTimestamp);
+}
@@ -0,0 +1,631 @@
+using System.Text.Json;
+using Microsoft.Data.Sqlite;
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Infrastructure.Storage;
+
+/// <summary>
+/// SQLiteにイベントを永続化するストレージ
+/// </summary>
+/// <remarks>
+/// タグごとにテーブル（events_&lt;タグID&gt;）を作成し、タイムスタンプとパスにインデックスを張る。
+/// タグ名とテーブルの対応はtagsテーブルで管理するため、タグ名に任意の文字を使用できる。
+/// イベント本体はポリモーフィックJSONとして保存し、サービス再起動後も同じ型で復元する。
+/// </remarks>
+public class SqliteEventStorage : IEventStorage, IDisposable
+{
+    private readonly ILogger<SqliteEventStorage> _logger;
+    private readonly SqliteConnection _connection;
+    private readonly int _maxEventsPerTag;
+    private readonly TimeSpan _retention;
+    private readonly SemaphoreSlim _gate = new(1, 1);
+    private readonly Dictionary<string, string> _tableNames = new();
+    private readonly Dictionary<string, int> _eventCounts = new();
+    private readonly Dictionary<string, (BackpressurePolicy Policy, TimeSpan BlockTimeout)> _backpressurePolicies = new();
+    private readonly Dictionary<string, long> _droppedCounts = new();
+    private readonly Dictionary<string, long> _sequenceNumbers = new();
+    private readonly Timer _cleanupTimer;
+    private TaskCompletionSource _spaceReleased = new(TaskCreationOptions.RunContinuationsAsynchronously);
+    private bool _disposed;
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    /// <param name="logger">ロガー</param>
+    /// <param name="databasePath">データベースファイルのパス</param>
+    /// <param name="maxEventsPerTag">タグごとの最大イベント数（0以下の場合は無制限）</param>
+    /// <param name="retention">イベントの保持期間（0以下の場合は期間で削除しない）</param>
+    public SqliteEventStorage(ILogger<SqliteEventStorage> logger, string databasePath, int maxEventsPerTag = 0, TimeSpan retention = default)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+        if (string.IsNullOrWhiteSpace(databasePath))
+        {
+            throw new ArgumentException("データベースのパスが指定されていません", nameof(databasePath));
+        }
+
+        _maxEventsPerTag = Math.Max(0, maxEventsPerTag);
+        _retention = retention;
+
+        var directory = Path.GetDirectoryName(Path.GetFullPath(databasePath));
+        if (!string.IsNullOrEmpty(directory))
+        {
+            Directory.CreateDirectory(directory);
+        }
+
+        _connection = new SqliteConnection(new SqliteConnectionStringBuilder { DataSource = databasePath }.ToString());
+        _connection.Open();
+        InitializeSchema();
+
+        // 定期的な保持期間切れイベントの削除（1時間間隔）
+        _cleanupTimer = new Timer(PerformCleanup, null, TimeSpan.FromMinutes(1), TimeSpan.FromHours(1));
+
+        _logger.LogInformation("SqliteEventStorageが初期化されました (Database: {DatabasePath}, Tags: {TagCount}, MaxEventsPerTag: {MaxEventsPerTag})",
+            databasePath, _tableNames.Count, _maxEventsPerTag);
+    }
+
+    /// <summary>
+    /// イベントを記録
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="eventData">イベントデータ</param>
+    /// <returns>連番を付与して記録したイベント（破棄した場合や記録できなかった場合はnull）</returns>
+    public async Task<BaseEventData?> StoreEventAsync(string tagName, BaseEventData eventData)
+    {
+        if (string.IsNullOrWhiteSpace(tagName))
+        {
+            _logger.LogWarning("イベント記録に失敗: タグ名が無効です");
+            return null;
+        }
+
+        if (eventData == null)
+        {
+            _logger.LogWarning("イベント記録に失敗: イベントデータがnullです (Tag: {TagName})", tagName);
+            return null;
+        }
+
+        try
+        {
+            // 満杯の場合はタグの設定に従って新しいイベントを破棄、または空きを待機
+            var (policy, blockTimeout) = GetBackpressurePolicy(tagName);
+            var deadline = DateTime.UtcNow + (policy == BackpressurePolicy.Block ? blockTimeout : TimeSpan.Zero);
+            BaseEventData stored;
+            while (true)
+            {
+                // 空きの確認より先に通知を取得し、確認後の解放を取りこぼさない
+                var spaceReleased = Volatile.Read(ref _spaceReleased).Task;
+                var remaining = deadline - DateTime.UtcNow;
+
+                // 空きの確認・採番・追加を同じロックで行い、同時に記録されても上限を超えないようにする
+                await _gate.WaitAsync();
+                try
+                {
+                    var isFull = policy != BackpressurePolicy.DropOldest && _maxEventsPerTag > 0 &&
+                        _eventCounts.GetValueOrDefault(tagName) >= _maxEventsPerTag;
+                    if (!isFull)
+                    {
+                        stored = await InsertEventAsync(tagName, eventData);
+                        break;
+                    }
+
+                    if (remaining <= TimeSpan.Zero)
+                    {
+                        // 破棄したイベントも連番を消費し、利用側で欠番として検出できるようにする
+                        NextSequenceNumber(tagName);
+                        lock (_droppedCounts)
+                        {
+                            _droppedCounts[tagName] = _droppedCounts.GetValueOrDefault(tagName) + 1;
+                        }
+                        _logger.LogDebug("バッファが満杯のためイベントを破棄しました (Tag: {TagName}, Policy: {Policy})", tagName, policy);
+                        return null;
+                    }
+                }
+                finally
+                {
+                    _gate.Release();
+                }
+
+                try
+                {
+                    await spaceReleased.WaitAsync(remaining);
+                }
+                catch (TimeoutException)
+                {
+                    // 期限後にもう一度空きを確認し、なければ破棄する
+                }
+            }
+
+            _logger.LogDebug("イベントを記録しました (Tag: {TagName}, EventType: {EventType}, ProcessId: {ProcessId})",
+                tagName, eventData.GetType().Name, eventData.ProcessId);
+            return stored;
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "イベント記録中にエラーが発生しました (Tag: {TagName}, EventType: {EventType})",
+                tagName, eventData.GetType().Name);
+            return null;
+        }
+    }
+
+    /// <summary>
+    /// イベントを採番してテーブルに追加（_gateの取得中に呼び出す）
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="eventData">イベントデータ</param>
+    /// <returns>連番を付与したイベント</returns>
+    private async Task<BaseEventData> InsertEventAsync(string tagName, BaseEventData eventData)
+    {
+        var table = EnsureTable(tagName);
+        var stored = eventData with { SequenceNumber = NextSequenceNumber(tagName) };
+
+        // 再起動後も連番が戻らないよう、最後の番号をイベントと同じトランザクションで保存
+        using (var transaction = _connection.BeginTransaction())
+        {
+            using (var update = _connection.CreateCommand())
+            {
+                update.Transaction = transaction;
+                update.CommandText = "UPDATE tags SET last_sequence = $sequence WHERE name = $name";
+                update.Parameters.AddWithValue("$sequence", stored.SequenceNumber);
+                update.Parameters.AddWithValue("$name", tagName);
+                await update.ExecuteNonQueryAsync();
+            }
+
+            using (var insert = _connection.CreateCommand())
+            {
+                insert.Transaction = transaction;
+                insert.CommandText = $"INSERT INTO {table} (timestamp, process_id, event_name, path, data) VALUES ($timestamp, $processId, $eventName, $path, $data)";
+                insert.Parameters.AddWithValue("$timestamp", eventData.Timestamp.Ticks);
+                insert.Parameters.AddWithValue("$processId", eventData.ProcessId);
+                insert.Parameters.AddWithValue("$eventName", eventData.EventName);
+                insert.Parameters.AddWithValue("$path", (object?)GetPath(eventData) ?? DBNull.Value);
+                insert.Parameters.AddWithValue("$data", JsonSerializer.Serialize(stored));
+                await insert.ExecuteNonQueryAsync();
+            }
+
+            transaction.Commit();
+        }
+
+        var currentCount = _eventCounts[tagName] + 1;
+        _eventCounts[tagName] = currentCount;
+
+        // 最大数を超えた場合、古いイベントを削除
+        if (_maxEventsPerTag > 0 && currentCount > _maxEventsPerTag)
+        {
+            var removeCount = currentCount - _maxEventsPerTag;
+            using var trim = _connection.CreateCommand();
+            trim.CommandText = $"DELETE FROM {table} WHERE id IN (SELECT id FROM {table} ORDER BY id LIMIT $count)";
+            trim.Parameters.AddWithValue("$count", removeCount);
+            var removed = await trim.ExecuteNonQueryAsync();
+
+            _eventCounts[tagName] = currentCount - removed;
+            lock (_droppedCounts)
+            {
+                _droppedCounts[tagName] = _droppedCounts.GetValueOrDefault(tagName) + removed;
+            }
+        }
+
+        return stored;
+    }
+
+    /// <summary>
+    /// タグに関連するイベントを取得
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <returns>イベント一覧（記録順）</returns>
+    public Task<IReadOnlyList<BaseEventData>> GetEventsAsync(string tagName)
+    {
+        return QueryEventsAsync(tagName, "ORDER BY id", _ => { });
+    }
+
+    /// <summary>
+    /// タグに関連するイベントをクリア
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <returns>非同期タスク</returns>
+    public async Task ClearEventsAsync(string tagName)
+    {
+        if (string.IsNullOrWhiteSpace(tagName))
+        {
+            _logger.LogWarning("イベントクリアに失敗: タグ名が無効です");
+            return;
+        }
+
+        try
+        {
+            await _gate.WaitAsync();
+            try
+            {
+                if (!_tableNames.Remove(tagName, out var table))
+                {
+                    _logger.LogDebug("クリア対象のイベントが見つかりません (Tag: {TagName})", tagName);
+                    return;
+                }
+
+                using var command = _connection.CreateCommand();
+                command.CommandText = $"DROP TABLE IF EXISTS {table}; DELETE FROM tags WHERE name = $name;";
+                command.Parameters.AddWithValue("$name", tagName);
+                await command.ExecuteNonQueryAsync();
+
+                _eventCounts.Remove(tagName, out var removedCount);
+                _logger.LogInformation("イベントをクリアしました (Tag: {TagName}, RemovedCount: {RemovedCount})", tagName, removedCount);
+            }
+            finally
+            {
+                _gate.Release();
+            }
+
+            SignalSpaceReleased();
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "イベントクリア中にエラーが発生しました (Tag: {TagName})", tagName);
+        }
+    }
+
+    /// <summary>
+    /// 全イベント数を取得
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <returns>イベント数</returns>
+    public async Task<int> GetEventCountAsync(string tagName)
+    {
+        if (string.IsNullOrWhiteSpace(tagName))
+        {
+            return 0;
+        }
+
+        await _gate.WaitAsync();
+        try
+        {
+            return _eventCounts.GetValueOrDefault(tagName);
+        }
+        finally
+        {
+            _gate.Release();
+        }
+    }
+
+    /// <summary>
+    /// ストレージ統計情報
+    /// </summary>
+    /// <returns>統計情報</returns>
+    public async Task<StorageStatistics> GetStatisticsAsync()
+    {
+        await _gate.WaitAsync();
+        try
+        {
+            var eventCountByTag = new Dictionary<string, int>(_eventCounts);
+
+            Dictionary<string, TagBufferStatistics> bufferStatisticsByTag;
+            lock (_droppedCounts)
+            {
+                bufferStatisticsByTag = eventCountByTag.Keys
+                    .Concat(_backpressurePolicies.Keys)
+                    .Concat(_droppedCounts.Keys)
+                    .Distinct()
+                    .ToDictionary(
+                        tag => tag,
+                        tag => new TagBufferStatistics(GetBackpressurePolicy(tag).Policy, _droppedCounts.GetValueOrDefault(tag)));
+            }
+
+            // イベントはディスクに保持するため、メモリ使用量は計上しない
+            return new StorageStatistics(
+                eventCountByTag.Count,
+                eventCountByTag.Values.Sum(),
+                eventCountByTag,
+                0,
+                bufferStatisticsByTag);
+        }
+        finally
+        {
+            _gate.Release();
+        }
+    }
+
+    /// <summary>
+    /// 全てのタグ名を取得
+    /// </summary>
+    /// <returns>タグ名のリスト</returns>
+    public async Task<IReadOnlyList<string>> GetAllTagsAsync()
+    {
+        await _gate.WaitAsync();
+        try
+        {
+            return _tableNames.Keys.ToList();
+        }
+        finally
+        {
+            _gate.Release();
+        }
+    }
+
+    /// <summary>
+    /// 期間を指定してイベントを取得
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="startTime">開始時刻</param>
+    /// <param name="endTime">終了時刻</param>
+    /// <returns>フィルタされたイベント一覧</returns>
+    public Task<IReadOnlyList<BaseEventData>> GetEventsByTimeRangeAsync(string tagName, DateTime startTime, DateTime endTime)
+    {
+        return QueryEventsAsync(tagName, "WHERE timestamp >= $start AND timestamp <= $end ORDER BY id", command =>
+        {
+            command.Parameters.AddWithValue("$start", startTime.Ticks);
+            command.Parameters.AddWithValue("$end", endTime.Ticks);
+        });
+    }
+
+    /// <summary>
+    /// 最新のN件のイベントを取得
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="count">取得件数</param>
+    /// <returns>最新のイベント一覧</returns>
+    public Task<IReadOnlyList<BaseEventData>> GetLatestEventsAsync(string tagName, int count)
+    {
+        if (count <= 0)
+        {
+            return Task.FromResult<IReadOnlyList<BaseEventData>>(Array.Empty<BaseEventData>());
+        }
+
+        return QueryEventsAsync(tagName, "ORDER BY timestamp DESC, id DESC LIMIT $count", command =>
+        {
+            command.Parameters.AddWithValue("$count", count);
+        });
+    }
+
+    /// <summary>
+    /// タグのバッファが満杯の場合の動作を設定
+    /// </summary>
+    /// <param name="tagName">タグ名</param>
+    /// <param name="policy">満杯時の動作</param>
+    /// <param name="blockTimeout">BackpressurePolicy.Blockで空きを待つ最大時間</param>
+    public void SetBackpressurePolicy(string tagName, BackpressurePolicy policy, TimeSpan blockTimeout)
+    {
+        if (string.IsNullOrWhiteSpace(tagName))
+        {
+            return;
+        }
+
+        lock (_backpressurePolicies)
+        {
+            _backpressurePolicies[tagName] = (policy, blockTimeout > TimeSpan.Zero ? blockTimeout : TimeSpan.Zero);
+        }
+
+        _logger.LogInformation("バッファ満杯時の動作を設定しました (Tag: {TagName}, Policy: {Policy}, BlockTimeout: {BlockTimeout})",
+            tagName, policy, blockTimeout);
+    }
+
+    /// <summary>
+    /// リソースの解放
+    /// </summary>
+    public void Dispose()
+    {
+        if (_disposed)
+            return;
+
+        _disposed = true;
+        _cleanupTimer.Dispose();
+        _connection.Dispose();
+        _gate.Dispose();
+        _logger.LogInformation("SqliteEventStorageが解放されました");
+    }
+
+    private void InitializeSchema()
+    {
+        using (var command = _connection.CreateCommand())
+        {
+            // 書き込み中の読み取りをブロックしない
+            command.CommandText = """
+                PRAGMA journal_mode = WAL;
+                PRAGMA synchronous = NORMAL;
+                CREATE TABLE IF NOT EXISTS tags (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL UNIQUE, last_sequence INTEGER NOT NULL DEFAULT 0);
+                """;
+            command.ExecuteNonQuery();
+        }
+
+        var tables = new List<(string TagName, string Table)>();
+        using (var command = _connection.CreateCommand())
+        {
+            command.CommandText = "SELECT id, name, last_sequence FROM tags";
+            using var reader = command.ExecuteReader();
+            while (reader.Read())
+            {
+                tables.Add((reader.GetString(1), GetTableName(reader.GetInt64(0))));
+                _sequenceNumbers[reader.GetString(1)] = reader.GetInt64(2);
+            }
+        }
+
+        // 前回起動時のイベント数を復元
+        foreach (var (tagName, table) in tables)
+        {
+            CreateTable(table);
+            using var command = _connection.CreateCommand();
+            command.CommandText = $"SELECT COUNT(*) FROM {table}";
+            _tableNames[tagName] = table;
+            _eventCounts[tagName] = Convert.ToInt32(command.ExecuteScalar());
+        }
+    }
+
+    /// <summary>
+    /// タグのテーブルを取得または作成（_gateの取得中に呼び出す）
+    /// </summary>
+    private string EnsureTable(string tagName)
+    {
+        if (_tableNames.TryGetValue(tagName, out var table))
+        {
+            return table;
+        }
+
+        using (var command = _connection.CreateCommand())
+        {
+            command.CommandText = "INSERT INTO tags (name) VALUES ($name) RETURNING id";
+            command.Parameters.AddWithValue("$name", tagName);
+            table = GetTableName((long)command.ExecuteScalar()!);
+        }
+
+        CreateTable(table);
+        _tableNames[tagName] = table;
+        _eventCounts[tagName] = 0;
+        return table;
+    }
+
+    /// <summary>
+    /// タグの次の連番を取得（クリア後も番号は戻さない）
+    /// </summary>
+    private long NextSequenceNumber(string tagName)
+    {
+        lock (_sequenceNumbers)
+        {
+            var sequenceNumber = _sequenceNumbers.GetValueOrDefault(tagName) + 1;
+            _sequenceNumbers[tagName] = sequenceNumber;
+            return sequenceNumber;
+        }
+    }
+
+    private void CreateTable(string table)
+    {
+        using var command = _connection.CreateCommand();
+        command.CommandText = $"""
+            CREATE TABLE IF NOT EXISTS {table} (
+                id INTEGER PRIMARY KEY AUTOINCREMENT,
+                timestamp INTEGER NOT NULL,
+                process_id INTEGER NOT NULL,
+                event_name TEXT NOT NULL,
+                path TEXT,
+                data TEXT NOT NULL
+            );
+            CREATE INDEX IF NOT EXISTS ix_{table}_timestamp ON {table} (timestamp);
+            CREATE INDEX IF NOT EXISTS ix_{table}_path ON {table} (path);
+            """;
+        command.ExecuteNonQuery();
+    }
+
+    private static string GetTableName(long tagId) => $"events_{tagId}";
+
+    private static string? GetPath(BaseEventData eventData)
+    {
+        return eventData switch
+        {
+            FileEventData fileEvent => fileEvent.FilePath,
+            ImageLoadEventData imageLoad => imageLoad.ImagePath,
+            RegistryEventData registry => registry.KeyName,
+            _ => null
+        };
+    }
+
+    private async Task<IReadOnlyList<BaseEventData>> QueryEventsAsync(string tagName, string clause, Action<SqliteCommand> bind)
+    {
+        if (string.IsNullOrWhiteSpace(tagName))
+        {
+            _logger.LogWarning("イベント取得に失敗: タグ名が無効です");
+            return Array.Empty<BaseEventData>();
+        }
+
+        try
+        {
+            await _gate.WaitAsync();
+            try
+            {
+                if (!_tableNames.TryGetValue(tagName, out var table))
+                {
+                    _logger.LogDebug("イベントが見つかりません (Tag: {TagName})", tagName);
+                    return Array.Empty<BaseEventData>();
+                }
+
+                using var command = _connection.CreateCommand();
+                command.CommandText = $"SELECT data FROM {table} {clause}";
+                bind(command);
+
+                var events = new List<BaseEventData>();
+                using var reader = await command.ExecuteReaderAsync();
+                while (await reader.ReadAsync())
+                {
+                    var eventData = JsonSerializer.Deserialize<BaseEventData>(reader.GetString(0));
+                    if (eventData != null)
+                    {
+                        events.Add(eventData);
+                    }
+                }
+
+                _logger.LogDebug("イベントを取得しました (Tag: {TagName}, Count: {Count})", tagName, events.Count);
+                return events;
+            }
+            finally
+            {
+                _gate.Release();
+            }
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "イベント取得中にエラーが発生しました (Tag: {TagName})", tagName);
+            return Array.Empty<BaseEventData>();
+        }
+    }
+
+    private (BackpressurePolicy Policy, TimeSpan BlockTimeout) GetBackpressurePolicy(string tagName)
+    {
+        lock (_backpressurePolicies)
+        {
+            return _backpressurePolicies.TryGetValue(tagName, out var setting)
+                ? setting
+                : (BackpressurePolicy.DropOldest, TimeSpan.Zero);
+        }
+    }
+
+    /// <summary>
+    /// テーブルの空きを待機中の記録に通知
+    /// </summary>
+    private void SignalSpaceReleased()
+    {
+        Interlocked.Exchange(ref _spaceReleased, new TaskCompletionSource(TaskCreationOptions.RunContinuationsAsynchronously)).TrySetResult();
+    }
+
+    /// <summary>
+    /// 保持期間を過ぎたイベントを削除
+    /// </summary>
+    /// <param name="state">状態オブジェクト</param>
+    private async void PerformCleanup(object? state)
+    {
+        if (_retention <= TimeSpan.Zero)
+        {
+            return;
+        }
+
+        try
+        {
+            var cutoffTicks = (DateTime.UtcNow - _retention).Ticks;
+            var totalCleaned = 0;
+
+            await _gate.WaitAsync();
+            try
+            {
+                foreach (var (tagName, table) in _tableNames)
+                {
+                    using var command = _connection.CreateCommand();
+                    command.CommandText = $"DELETE FROM {table} WHERE timestamp < $cutoff";
+                    command.Parameters.AddWithValue("$cutoff", cutoffTicks);
+                    var cleanedCount = await command.ExecuteNonQueryAsync();
+
+                    _eventCounts[tagName] = Math.Max(0, _eventCounts[tagName] - cleanedCount);
+                    totalCleaned += cleanedCount;
+                }
+            }
+            finally
+            {
+                _gate.Release();
+            }
+
+            if (totalCleaned > 0)
+            {
+                SignalSpaceReleased();
+                _logger.LogInformation("保持期間を過ぎたイベントを削除しました (CleanedEvents: {CleanedEvents})", totalCleaned);
+            }
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "定期クリーンアップ中にエラーが発生しました");
+        }
+    }
+}
@@ -0,0 +1,337 @@
+using System.Collections.Concurrent;
+using System.Diagnostics;
+using System.Text;
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Infrastructure.Tracing;
+
+/// <summary>
+/// 外部トレーサー（bpftrace、esloggerなど）を子プロセスとして起動し、その出力行をイベントに変換するプロバイダーの基底クラス
+/// </summary>
+/// <remarks>
+/// 派生クラスは起動コマンドと1行分のパース処理のみを実装する。
+/// ETWプロバイダーと同様に、出力の読み取りとイベント配信はキューで分離する。
+/// </remarks>
+public abstract class ExternalTracerEventProvider : IEtwEventProvider, IDisposable
+{
+    private readonly ILogger _logger;
+    private readonly CancellationTokenSource _cancellationTokenSource = new();
+    private readonly ConcurrentQueue<RawEventData> _eventQueue = new();
+    private Process? _tracerProcess;
+    private Task? _readTask;
+    private Task? _eventProcessingTask;
+    private bool _isMonitoring;
+    private bool _disposed;
+    private ClockMapping _clockMapping = ClockMapping.Capture();
+    private long _eventsLost;
+
+    /// <summary>
+    /// イベント受信時に発火するイベント
+    /// </summary>
+    public event EventHandler<RawEventData>? EventReceived;
+
+    /// <summary>
+    /// 監視中かどうか
+    /// </summary>
+    public bool IsMonitoring => _isMonitoring;
+
+    /// <summary>
+    /// トレーサー起動時点のStopwatchクロックと壁時計の対応付け
+    /// </summary>
+    public ClockMapping ClockMapping => _clockMapping;
+
+    /// <summary>
+    /// 解析に失敗して失われたトレーサー出力の累計
+    /// </summary>
+    public long EventsLost => Interlocked.Read(ref _eventsLost);
+
+    /// <summary>
+    /// ログに表示するバックエンド名
+    /// </summary>
+    protected abstract string BackendName { get; }
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    /// <param name="logger">ロガー</param>
+    protected ExternalTracerEventProvider(ILogger logger)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+    }
+
+    /// <summary>
+    /// トレーサーの起動情報を作成
+    /// </summary>
+    /// <returns>起動情報（標準出力・標準エラーのリダイレクトは基底クラスで設定）</returns>
+    protected abstract ProcessStartInfo CreateStartInfo();
+
+    /// <summary>
+    /// トレーサーの出力1行を解析
+    /// </summary>
+    /// <param name="line">出力行</param>
+    /// <param name="timestamp">受信時刻</param>
+    /// <returns>生イベント（対象外の行はnull）</returns>
+    protected abstract RawEventData? ParseLine(string line, DateTime timestamp);
+
+    /// <summary>
+    /// 監視を開始
+    /// </summary>
+    public async Task StartMonitoringAsync(CancellationToken cancellationToken = default)
+    {
+        if (_disposed)
+            throw new ObjectDisposedException(GetType().Name);
+
+        if (_isMonitoring)
+        {
+            _logger.LogWarning("{Backend}監視は既に開始されています", BackendName);
+            return;
+        }
+
+        // カーネルイベントの購読にはroot権限が必要
+        if (!Environment.IsPrivilegedProcess)
+        {
+            var errorMsg = $"{BackendName}監視にはroot権限が必要です";
+            _logger.LogError(errorMsg);
+            throw new UnauthorizedAccessException(errorMsg);
+        }
+
+        try
+        {
+            var startInfo = CreateStartInfo();
+            _logger.LogInformation("{Backend}監視を開始しています... ({Tracer})", BackendName, startInfo.FileName);
+
+            _clockMapping = ClockMapping.Capture();
+            _tracerProcess = StartTracer(startInfo);
+
+            _readTask = Task.Run(() => ReadOutputAsync(_tracerProcess), _cancellationTokenSource.Token);
+            _eventProcessingTask = Task.Run(ProcessEventsAsync, _cancellationTokenSource.Token);
+
+            // 購読の失敗（権限不足・未対応のプローブなど）はトレーサーの即時終了として現れる
+            await Task.Delay(500, cancellationToken);
+            if (_tracerProcess.HasExited)
+            {
+                throw new InvalidOperationException($"{startInfo.FileName}が終了しました (ExitCode: {_tracerProcess.ExitCode})");
+            }
+
+            _isMonitoring = true;
+            _logger.LogInformation("{Backend}監視が正常に開始されました (PID: {TracerPid})", BackendName, _tracerProcess.Id);
+        }
+        catch (Exception ex)
+        {
+            // ETWと同様に、監視開始に失敗してもサービスは継続する
+            _logger.LogError(ex, "{Backend}監視開始中にエラーが発生しました", BackendName);
+            StopTracer();
+            _logger.LogWarning("{Backend}監視の開始に失敗しましたが、サービスは継続します", BackendName);
+        }
+    }
+
+    /// <summary>
+    /// 監視を停止
+    /// </summary>
+    public async Task StopMonitoringAsync(CancellationToken cancellationToken = default)
+    {
+        if (!_isMonitoring)
+        {
+            return;
+        }
+
+        try
+        {
+            _logger.LogInformation("{Backend}監視を停止しています...", BackendName);
+
+            _cancellationTokenSource.Cancel();
+            StopTracer();
+
+            foreach (var task in new[] { _readTask, _eventProcessingTask })
+            {
+                if (task == null)
+                {
+                    continue;
+                }
+
+                try
+                {
+                    await task.WaitAsync(TimeSpan.FromSeconds(5), cancellationToken);
+                }
+                catch (TimeoutException)
+                {
+                    _logger.LogWarning("{Backend}イベント処理タスクの停止がタイムアウトしました", BackendName);
+                }
+                catch (OperationCanceledException)
+                {
+                    // 停止要求によるキャンセルは正常
+                }
+            }
+
+            _isMonitoring = false;
+            _logger.LogInformation("{Backend}監視が正常に停止されました", BackendName);
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "{Backend}監視停止中にエラーが発生しました", BackendName);
+            throw;
+        }
+    }
+
+    /// <summary>
+    /// トレーサーを起動
+    /// </summary>
+    private Process StartTracer(ProcessStartInfo startInfo)
+    {
+        startInfo.RedirectStandardOutput = true;
+        startInfo.RedirectStandardError = true;
+        startInfo.UseShellExecute = false;
+        startInfo.StandardOutputEncoding = Encoding.UTF8;
+
+        var process = new Process { StartInfo = startInfo };
+        var tracerName = Path.GetFileName(startInfo.FileName);
+        process.ErrorDataReceived += (_, e) =>
+        {
+            if (!string.IsNullOrEmpty(e.Data))
+            {
+                _logger.LogWarning("{Tracer}: {Message}", tracerName, e.Data);
+            }
+        };
+
+        process.Start();
+        process.BeginErrorReadLine();
+        return process;
+    }
+
+    /// <summary>
+    /// トレーサーを停止
+    /// </summary>
+    private void StopTracer()
+    {
+        var process = _tracerProcess;
+        if (process == null)
+        {
+            return;
+        }
+
+        try
+        {
+            if (!process.HasExited)
+            {
+                process.Kill();
+                process.WaitForExit(5000);
+            }
+        }
+        catch (Exception ex)
+        {
+            _logger.LogWarning(ex, "トレーサー停止中に警告が発生しました");
+        }
+        finally
+        {
+            process.Dispose();
+            _tracerProcess = null;
+        }
+    }
+
+    /// <summary>
+    /// トレーサーの出力読み取りループ
+    /// </summary>
+    private async Task ReadOutputAsync(Process process)
+    {
+        _logger.LogInformation("{Backend}出力の読み取りを開始しました", BackendName);
+
+        try
+        {
+            var output = process.StandardOutput;
+            string? line;
+            while ((line = await output.ReadLineAsync(_cancellationTokenSource.Token)) != null)
+            {
+                try
+                {
+                    // トレーサーの出力には単調増加クロックが含まれないため、読み取り時点の値で代用する
+                    var monotonicTimestamp = Stopwatch.GetTimestamp();
+                    var rawEvent = ParseLine(line, DateTime.Now);
+                    if (rawEvent != null)
+                    {
+                        _eventQueue.Enqueue(rawEvent with { MonotonicTimestamp = monotonicTimestamp });
+                    }
+                }
+                catch (Exception ex)
+                {
+                    Interlocked.Increment(ref _eventsLost);
+                    _logger.LogError(ex, "トレーサー出力の解析中にエラーが発生しました: {Line}", line);
+                }
+            }
+
+            if (!_cancellationTokenSource.IsCancellationRequested)
+            {
+                _logger.LogError("{Backend}のトレーサーが予期せず終了しました", BackendName);
+            }
+        }
+        catch (OperationCanceledException)
+        {
+            _logger.LogInformation("{Backend}出力の読み取りが停止されました", BackendName);
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "{Backend}出力の読み取り中にエラーが発生しました", BackendName);
+        }
+    }
+
+    /// <summary>
+    /// イベント処理ループ
+    /// </summary>
+    private async Task ProcessEventsAsync()
+    {
+        _logger.LogInformation("{Backend}イベント処理ループを開始しました", BackendName);
+
+        try
+        {
+            while (!_cancellationTokenSource.Token.IsCancellationRequested)
+            {
+                while (_eventQueue.TryDequeue(out var rawEvent))
+                {
+                    try
+                    {
+                        EventReceived?.Invoke(this, rawEvent);
+                    }
+                    catch (Exception ex)
+                    {
+                        _logger.LogError(ex, "イベント配信中にエラーが発生しました");
+                    }
+                }
+
+                await Task.Delay(10, _cancellationTokenSource.Token);
+            }
+        }
+        catch (OperationCanceledException)
+        {
+            _logger.LogInformation("{Backend}イベント処理ループが停止されました", BackendName);
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "{Backend}イベント処理ループでエラーが発生しました", BackendName);
+        }
+    }
+
+    /// <summary>
+    /// リソースの解放
+    /// </summary>
+    public void Dispose()
+    {
+        if (_disposed)
+            return;
+
+        _disposed = true;
+
+        try
+        {
+            StopMonitoringAsync().GetAwaiter().GetResult();
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "{Provider}解放中にエラーが発生しました", GetType().Name);
+        }
+
+        StopTracer();
+        _cancellationTokenSource.Dispose();
+        _logger.LogInformation("{Provider}が解放されました", GetType().Name);
+    }
+}
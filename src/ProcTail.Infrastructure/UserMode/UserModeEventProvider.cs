@@ -0,0 +1,329 @@
+using System.Collections.Concurrent;
+using System.Diagnostics;
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Infrastructure.UserMode;
+
+/// <summary>
+/// 管理者権限なしで動作する制限モードのイベントプロバイダー
+/// </summary>
+/// <remarks>
+/// ETW/eBPF/Endpoint Securityを購読できない場合の代替として、設定したディレクトリの変更（WindowsではReadDirectoryChangesW）と
+/// プロセス一覧のポーリングからイベントを生成する。ディレクトリの変更はPIDが分からないため設定したタグに記録し、
+/// 生成したイベントには全て IsDegraded を付ける。ポーリング間隔より短命なプロセスは検出できない。
+/// </remarks>
+public class UserModeEventProvider : IEtwEventProvider, IDisposable
+{
+    private const string FileProviderName = "Microsoft-Windows-Kernel-FileIO";
+    private const string ProcessProviderName = "Microsoft-Windows-Kernel-Process";
+
+    private readonly ILogger<UserModeEventProvider> _logger;
+    private readonly IProcessValidator _processValidator;
+    private readonly IReadOnlyDictionary<string, IReadOnlyList<string>> _watchDirectories;
+    private readonly TimeSpan _pollInterval;
+    private readonly CancellationTokenSource _cancellationTokenSource = new();
+    private readonly ConcurrentQueue<RawEventData> _eventQueue = new();
+    private readonly List<FileSystemWatcher> _watchers = new();
+    private HashSet<int> _knownProcessIds = new();
+    private Timer? _pollTimer;
+    private Task? _eventProcessingTask;
+    private ClockMapping _clockMapping = ClockMapping.Capture();
+    private long _eventsLost;
+    private bool _isMonitoring;
+    private bool _disposed;
+
+    /// <summary>
+    /// イベント受信時に発火するイベント
+    /// </summary>
+    public event EventHandler<RawEventData>? EventReceived;
+
+    /// <summary>
+    /// 監視中かどうか
+    /// </summary>
+    public bool IsMonitoring => _isMonitoring;
+
+    /// <summary>
+    /// 監視開始時点のStopwatchクロックと壁時計の対応付け
+    /// </summary>
+    public ClockMapping ClockMapping => _clockMapping;
+
+    /// <summary>
+    /// ディレクトリ監視のバッファ溢れで失われた変更通知の累計
+    /// </summary>
+    public long EventsLost => Interlocked.Read(ref _eventsLost);
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    /// <param name="logger">ロガー</param>
+    /// <param name="processValidator">プロセス情報の取得</param>
+    /// <param name="watchDirectories">タグごとに監視するディレクトリ</param>
+    /// <param name="pollInterval">プロセス一覧のポーリング間隔</param>
+    public UserModeEventProvider(
+        ILogger<UserModeEventProvider> logger,
+        IProcessValidator processValidator,
+        IReadOnlyDictionary<string, IReadOnlyList<string>> watchDirectories,
+        TimeSpan pollInterval)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+        _processValidator = processValidator ?? throw new ArgumentNullException(nameof(processValidator));
+        _watchDirectories = watchDirectories ?? throw new ArgumentNullException(nameof(watchDirectories));
+        _pollInterval = pollInterval > TimeSpan.Zero ? pollInterval : TimeSpan.FromSeconds(1);
+    }
+
+    /// <summary>
+    /// 監視を開始
+    /// </summary>
+    public Task StartMonitoringAsync(CancellationToken cancellationToken = default)
+    {
+        if (_disposed)
+            throw new ObjectDisposedException(nameof(UserModeEventProvider));
+
+        if (_isMonitoring)
+        {
+            return Task.CompletedTask;
+        }
+
+        _logger.LogWarning("制限モードで監視を開始します（ディレクトリ変更とプロセス一覧のポーリングのみ。PIDやレジストリ・ネットワークなどは記録されません）");
+        _clockMapping = ClockMapping.Capture();
+
+        foreach (var (tagName, directories) in _watchDirectories)
+        {
+            foreach (var directory in directories)
+            {
+                var path = Environment.ExpandEnvironmentVariables(directory);
+                if (!Directory.Exists(path))
+                {
+                    _logger.LogWarning("監視対象ディレクトリが存在しません (Tag: {TagName}, Directory: {Directory})", tagName, path);
+                    continue;
+                }
+
+                _watchers.Add(CreateWatcher(tagName, path));
+                _logger.LogInformation("ディレクトリ監視を開始しました (Tag: {TagName}, Directory: {Directory})", tagName, path);
+            }
+        }
+
+        // 起動時点で実行中のプロセスは開始イベントを出さない
+        _knownProcessIds = GetProcessIds();
+        _pollTimer = new Timer(PollProcesses, null, _pollInterval, _pollInterval);
+        _eventProcessingTask = Task.Run(ProcessEventsAsync, _cancellationTokenSource.Token);
+
+        _isMonitoring = true;
+        return Task.CompletedTask;
+    }
+
+    /// <summary>
+    /// 監視を停止
+    /// </summary>
+    public async Task StopMonitoringAsync(CancellationToken cancellationToken = default)
+    {
+        if (!_isMonitoring)
+        {
+            return;
+        }
+
+        _logger.LogInformation("制限モードの監視を停止しています...");
+
+        _pollTimer?.Dispose();
+        _pollTimer = null;
+        foreach (var watcher in _watchers)
+        {
+            watcher.Dispose();
+        }
+        _watchers.Clear();
+
+        _cancellationTokenSource.Cancel();
+        if (_eventProcessingTask != null)
+        {
+            try
+            {
+                await _eventProcessingTask.WaitAsync(TimeSpan.FromSeconds(5), cancellationToken);
+            }
+            catch (TimeoutException)
+            {
+                _logger.LogWarning("制限モードのイベント処理タスクの停止がタイムアウトしました");
+            }
+            catch (OperationCanceledException)
+            {
+                // 停止要求によるキャンセルは正常
+            }
+        }
+
+        _isMonitoring = false;
+        _logger.LogInformation("制限モードの監視が正常に停止されました");
+    }
+
+    /// <summary>
+    /// ディレクトリの変更を監視するウォッチャーを作成
+    /// </summary>
+    private FileSystemWatcher CreateWatcher(string tagName, string path)
+    {
+        var watcher = new FileSystemWatcher(path)
+        {
+            IncludeSubdirectories = true,
+            NotifyFilter = NotifyFilters.FileName | NotifyFilters.DirectoryName | NotifyFilters.LastWrite | NotifyFilters.Size,
+            InternalBufferSize = 64 * 1024
+        };
+
+        watcher.Created += (_, e) => EnqueueFileEvent(tagName, "FileIO/Create", e.FullPath);
+        watcher.Changed += (_, e) => EnqueueFileEvent(tagName, "FileIO/Write", e.FullPath);
+        watcher.Deleted += (_, e) => EnqueueFileEvent(tagName, "FileIO/Delete", e.FullPath);
+        watcher.Renamed += (_, e) => EnqueueFileEvent(tagName, "FileIO/Rename", e.OldFullPath, e.FullPath);
+        watcher.Error += (_, e) =>
+        {
+            Interlocked.Increment(ref _eventsLost);
+            _logger.LogWarning(e.GetException(), "ディレクトリ監視で変更通知を取りこぼしました (Tag: {TagName}, Directory: {Directory})", tagName, path);
+        };
+
+        watcher.EnableRaisingEvents = true;
+        return watcher;
+    }
+
+    private void EnqueueFileEvent(string tagName, string eventName, string filePath, string? newFilePath = null)
+    {
+        var payload = new Dictionary<string, object> { ["FileName"] = filePath };
+        if (newFilePath != null)
+        {
+            payload["NewFileName"] = newFilePath;
+        }
+
+        // 変更したプロセスは分からないため、PIDは0としてタグを直接指定する
+        _eventQueue.Enqueue(CreateRawEvent(FileProviderName, eventName, 0, payload) with { TagName = tagName });
+    }
+
+    /// <summary>
+    /// プロセス一覧を取得し、前回との差分から開始・終了イベントを生成
+    /// </summary>
+    private void PollProcesses(object? state)
+    {
+        try
+        {
+            var currentProcessIds = GetProcessIds();
+
+            foreach (var processId in currentProcessIds.Where(id => !_knownProcessIds.Contains(id)))
+            {
+                var processInfo = _processValidator.GetProcessInfo(processId);
+                if (processInfo == null)
+                {
+                    continue;
+                }
+
+                // ETWと同様に、開始イベントは親プロセスのイベントとして子プロセスの情報をペイロードに入れる
+                var imageFileName = string.IsNullOrEmpty(processInfo.ExecutablePath)
+                    ? processInfo.ProcessName
+                    : Path.GetFileName(processInfo.ExecutablePath);
+                _eventQueue.Enqueue(CreateRawEvent(ProcessProviderName, "Process/Start", processInfo.ParentProcessId ?? 0, new Dictionary<string, object>
+                {
+                    ["ProcessId"] = processId,
+                    ["ParentId"] = processInfo.ParentProcessId ?? 0,
+                    ["ImageFileName"] = imageFileName
+                }));
+            }
+
+            foreach (var processId in _knownProcessIds.Where(id => !currentProcessIds.Contains(id)))
+            {
+                // 終了コードはポーリングでは取得できない
+                _eventQueue.Enqueue(CreateRawEvent(ProcessProviderName, "Process/End", processId, new Dictionary<string, object>
+                {
+                    ["ProcessId"] = processId
+                }));
+            }
+
+            _knownProcessIds = currentProcessIds;
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "プロセス一覧のポーリング中にエラーが発生しました");
+        }
+    }
+
+    private static HashSet<int> GetProcessIds()
+    {
+        var processes = Process.GetProcesses();
+        try
+        {
+            return processes.Select(process => process.Id).ToHashSet();
+        }
+        finally
+        {
+            foreach (var process in processes)
+            {
+                process.Dispose();
+            }
+        }
+    }
+
+    private static RawEventData CreateRawEvent(string providerName, string eventName, int processId, Dictionary<string, object> payload)
+    {
+        return new RawEventData(
+            DateTime.Now,
+            providerName,
+            eventName,
+            processId,
+            0,
+            Guid.Empty,
+            Guid.Empty,
+            payload,
+            Stopwatch.GetTimestamp(),
+            IsDegraded: true
+        );
+    }
+
+    /// <summary>
+    /// イベント処理ループ
+    /// </summary>
+    private async Task ProcessEventsAsync()
+    {
+        try
+        {
+            while (!_cancellationTokenSource.Token.IsCancellationRequested)
+            {
+                while (_eventQueue.TryDequeue(out var rawEvent))
+                {
+                    try
+                    {
+                        EventReceived?.Invoke(this, rawEvent);
+                    }
+                    catch (Exception ex)
+                    {
+                        _logger.LogError(ex, "イベント配信中にエラーが発生しました");
+                    }
+                }
+
+                await Task.Delay(10, _cancellationTokenSource.Token);
+            }
+        }
+        catch (OperationCanceledException)
+        {
+            _logger.LogInformation("制限モードのイベント処理ループが停止されました");
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "制限モードのイベント処理ループでエラーが発生しました");
+        }
+    }
+
+    /// <summary>
+    /// リソースの解放
+    /// </summary>
+    public void Dispose()
+    {
+        if (_disposed)
+            return;
+
+        _disposed = true;
+
+        try
+        {
+            StopMonitoringAsync().GetAwaiter().GetResult();
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "{Provider}解放中にエラーが発生しました", GetType().Name);
+        }
+
+        _cancellationTokenSource.Dispose();
+    }
+}
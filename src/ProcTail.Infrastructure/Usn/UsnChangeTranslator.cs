@@ -0,0 +1,112 @@
+namespace ProcTail.Infrastructure.Usn;
+
+/// <summary>
+/// USNジャーナルのレコードから変換したファイル操作
+/// </summary>
+/// <param name="EventName">イベント名（FileIO/Create, FileIO/Write, FileIO/Delete, FileIO/Rename）</param>
+/// <param name="FilePath">ファイルパス（リネームの場合はリネーム前）</param>
+/// <param name="NewFilePath">リネーム後のファイルパス（リネーム以外はnull）</param>
+/// <param name="Timestamp">変更時刻</param>
+/// <param name="Usn">変換元のレコードのUSN</param>
+public record UsnFileChange(string EventName, string FilePath, string? NewFilePath, DateTime Timestamp, long Usn);
+
+/// <summary>
+/// USNジャーナルのレコードをファイル操作に変換する
+/// </summary>
+/// <remarks>
+/// レコードの変更理由はハンドルを閉じるまで累積するため、作成・書き込み・削除はクローズのレコードから1回だけ変換する。
+/// リネームはリネーム前とリネーム後の名前がそれぞれ独立したレコードになるため、ファイル参照番号で対応付ける。
+/// レコードはファイル名と親ディレクトリのファイル参照番号しか持たないため、ディレクトリのパスは呼び出し元が解決する。
+/// </remarks>
+public class UsnChangeTranslator
+{
+    /// <summary>
+    /// リネーム後のレコードを待つリネーム前のパスの上限（超えた場合は全て破棄する）
+    /// </summary>
+    private const int MaxPendingRenames = 1024;
+
+    private readonly Func<ulong, string?> _resolveDirectory;
+    private readonly bool _renameAndDeleteOnly;
+    private readonly Dictionary<ulong, string> _pendingRenames = new();
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    /// <param name="resolveDirectory">ディレクトリのファイル参照番号からパスを取得する関数（削除済みなどで取得できない場合はnull）</param>
+    /// <param name="renameAndDeleteOnly">リネームと削除のみを変換するか（ETWのファイルイベントと併用する場合）</param>
+    public UsnChangeTranslator(Func<ulong, string?> resolveDirectory, bool renameAndDeleteOnly)
+    {
+        _resolveDirectory = resolveDirectory ?? throw new ArgumentNullException(nameof(resolveDirectory));
+        _renameAndDeleteOnly = renameAndDeleteOnly;
+    }
+
+    /// <summary>
+    /// レコードをファイル操作に変換
+    /// </summary>
+    /// <param name="record">レコード</param>
+    /// <returns>ファイル操作（対象外のレコード、またはパスを解決できない場合は空）</returns>
+    public IReadOnlyList<UsnFileChange> Translate(UsnRecord record)
+    {
+        if (!record.HasReason(UsnReason.Close))
+        {
+            if (record.HasReason(UsnReason.RenameOldName))
+            {
+                var oldPath = GetPath(record);
+                if (oldPath != null)
+                {
+                    if (_pendingRenames.Count >= MaxPendingRenames)
+                    {
+                        _pendingRenames.Clear();
+                    }
+
+                    _pendingRenames[record.FileReferenceNumber] = oldPath;
+                }
+            }
+            else if (record.HasReason(UsnReason.RenameNewName) &&
+                     _pendingRenames.Remove(record.FileReferenceNumber, out var renamedFrom) &&
+                     GetPath(record) is { } newPath)
+            {
+                return new[] { new UsnFileChange("FileIO/Rename", renamedFrom, newPath, record.Timestamp, record.Usn) };
+            }
+
+            return Array.Empty<UsnFileChange>();
+        }
+
+        var path = GetPath(record);
+        if (path == null)
+        {
+            return Array.Empty<UsnFileChange>();
+        }
+
+        if (record.HasReason(UsnReason.FileDelete))
+        {
+            return new[] { new UsnFileChange("FileIO/Delete", path, null, record.Timestamp, record.Usn) };
+        }
+
+        if (_renameAndDeleteOnly)
+        {
+            return Array.Empty<UsnFileChange>();
+        }
+
+        var changes = new List<UsnFileChange>();
+        if (record.HasReason(UsnReason.FileCreate))
+        {
+            changes.Add(new UsnFileChange("FileIO/Create", path, null, record.Timestamp, record.Usn));
+        }
+
+        if (record.HasReason(UsnReason.DataChange))
+        {
+            changes.Add(new UsnFileChange("FileIO/Write", path, null, record.Timestamp, record.Usn));
+        }
+
+        return changes;
+    }
+
+    private string? GetPath(UsnRecord record)
+    {
+        var directory = _resolveDirectory(record.ParentFileReferenceNumber);
+        return directory == null
+            ? null
+            : (directory.EndsWith('\\') ? directory : directory + '\\') + record.FileName;
+    }
+}
@@ -0,0 +1,494 @@
+using System.Collections.Concurrent;
+using System.ComponentModel;
+using System.Diagnostics;
+using System.Runtime.InteropServices;
+using System.Runtime.Versioning;
+using System.Text;
+using System.Text.Json;
+using Microsoft.Extensions.Logging;
+using Microsoft.Win32.SafeHandles;
+using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
+using ProcTail.Infrastructure.Processes;
+
+namespace ProcTail.Infrastructure.Usn;
+
+/// <summary>
+/// NTFSのUSNジャーナルからファイルイベントを生成するプロバイダー
+/// </summary>
+/// <remarks>
+/// USNジャーナルはボリュームに永続化されるため、読み取った位置をチェックポイントとして保存しておけば、
+/// サービスが停止していた間のリネーム・削除も再起動時に取りこぼさずに記録できる。
+/// レコードには操作したプロセスが含まれないため、制限モードのディレクトリ監視と同様に、設定したディレクトリ配下の変更を設定したタグにPID 0として記録する。
+/// </remarks>
+[SupportedOSPlatform("windows")]
+public class UsnJournalEventProvider : IEtwEventProvider, IDisposable
+{
+    private const string FileProviderName = "Microsoft-Windows-Kernel-FileIO";
+    private const int ReadBufferSize = 64 * 1024;
+
+    /// <summary>
+    /// パスをキャッシュするディレクトリ数の上限（超えた場合は全て破棄する）
+    /// </summary>
+    private const int MaxDirectoryCacheEntries = 8192;
+
+    private static readonly TimeSpan CheckpointSaveInterval = TimeSpan.FromSeconds(5);
+
+    private readonly ILogger<UsnJournalEventProvider> _logger;
+    private readonly IReadOnlyDictionary<string, IReadOnlyList<string>> _watchDirectories;
+    private readonly string _checkpointPath;
+    private readonly bool _renameAndDeleteOnly;
+    private readonly TimeSpan _pollInterval;
+    private readonly CancellationTokenSource _cancellationTokenSource = new();
+    private readonly ConcurrentQueue<RawEventData> _eventQueue = new();
+    private readonly ConcurrentDictionary<string, UsnCheckpoint> _checkpoints = new(StringComparer.OrdinalIgnoreCase);
+    private readonly List<Task> _volumeTasks = new();
+    private readonly object _checkpointLock = new();
+    private Task? _eventProcessingTask;
+    private ClockMapping _clockMapping = ClockMapping.Capture();
+    private long _eventsLost;
+    private bool _isMonitoring;
+    private bool _disposed;
+
+    /// <summary>
+    /// イベント受信時に発火するイベント
+    /// </summary>
+    public event EventHandler<RawEventData>? EventReceived;
+
+    /// <summary>
+    /// 監視中かどうか
+    /// </summary>
+    public bool IsMonitoring => _isMonitoring;
+
+    /// <summary>
+    /// 監視開始時点のStopwatchクロックと壁時計の対応付け
+    /// </summary>
+    public ClockMapping ClockMapping => _clockMapping;
+
+    /// <summary>
+    /// ジャーナルの再作成や上限による切り捨てで読み取れなかった範囲の数
+    /// </summary>
+    public long EventsLost => Interlocked.Read(ref _eventsLost);
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    /// <param name="logger">ロガー</param>
+    /// <param name="watchDirectories">タグごとに監視するディレクトリ</param>
+    /// <param name="checkpointPath">読み取った位置を保存するファイルのパス</param>
+    /// <param name="renameAndDeleteOnly">リネームと削除のみを記録するか（ETWのファイルイベントと併用する場合）</param>
+    /// <param name="pollInterval">新しいレコードがない場合の読み取り間隔</param>
+    public UsnJournalEventProvider(
+        ILogger<UsnJournalEventProvider> logger,
+        IReadOnlyDictionary<string, IReadOnlyList<string>> watchDirectories,
+        string checkpointPath,
+        bool renameAndDeleteOnly,
+        TimeSpan pollInterval)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+        _watchDirectories = watchDirectories ?? throw new ArgumentNullException(nameof(watchDirectories));
+        _checkpointPath = checkpointPath ?? throw new ArgumentNullException(nameof(checkpointPath));
+        _renameAndDeleteOnly = renameAndDeleteOnly;
+        _pollInterval = pollInterval > TimeSpan.Zero ? pollInterval : TimeSpan.FromMilliseconds(500);
+    }
+
+    /// <summary>
+    /// 監視を開始
+    /// </summary>
+    public Task StartMonitoringAsync(CancellationToken cancellationToken = default)
+    {
+        if (_disposed)
+            throw new ObjectDisposedException(nameof(UsnJournalEventProvider));
+
+        if (_isMonitoring)
+        {
+            return Task.CompletedTask;
+        }
+
+        _clockMapping = ClockMapping.Capture();
+        LoadCheckpoints();
+
+        var directoriesByVolume = _watchDirectories
+            .SelectMany(kvp => kvp.Value.Select(directory => (TagName: kvp.Key, Directory: NormalizeDirectory(directory))))
+            .Where(entry => entry.Directory != null)
+            .GroupBy(entry => Path.GetPathRoot(entry.Directory)!.TrimEnd('\\'), StringComparer.OrdinalIgnoreCase);
+
+        foreach (var volume in directoriesByVolume)
+        {
+            var directories = volume.Select(entry => (entry.TagName, entry.Directory!)).ToList();
+            _volumeTasks.Add(Task.Run(() => ReadVolumeAsync(volume.Key, directories, _cancellationTokenSource.Token), _cancellationTokenSource.Token));
+            _logger.LogInformation("USNジャーナルの監視を開始しました (Volume: {Volume}, Directories: {Count})", volume.Key, directories.Count);
+        }
+
+        _eventProcessingTask = Task.Run(ProcessEventsAsync, _cancellationTokenSource.Token);
+        _isMonitoring = true;
+        return Task.CompletedTask;
+    }
+
+    /// <summary>
+    /// 監視を停止
+    /// </summary>
+    public async Task StopMonitoringAsync(CancellationToken cancellationToken = default)
+    {
+        if (!_isMonitoring)
+        {
+            return;
+        }
+
+        _logger.LogInformation("USNジャーナルの監視を停止しています...");
+        _cancellationTokenSource.Cancel();
+
+        try
+        {
+            var tasks = _volumeTasks.Append(_eventProcessingTask ?? Task.CompletedTask);
+            await Task.WhenAll(tasks).WaitAsync(TimeSpan.FromSeconds(5), cancellationToken);
+        }
+        catch (TimeoutException)
+        {
+            _logger.LogWarning("USNジャーナルの読み取りタスクの停止がタイムアウトしました");
+        }
+        catch (OperationCanceledException)
+        {
+            // 停止要求によるキャンセルは正常
+        }
+
+        // 次回の起動時に停止中の変更を読み取れるよう、最後に読み取った位置を保存する
+        SaveCheckpoints();
+        _volumeTasks.Clear();
+        _isMonitoring = false;
+        _logger.LogInformation("USNジャーナルの監視が正常に停止されました");
+    }
+
+    /// <summary>
+    /// ボリュームのジャーナルを読み取り続ける
+    /// </summary>
+    private async Task ReadVolumeAsync(string volume, IReadOnlyList<(string TagName, string Directory)> directories, CancellationToken cancellationToken)
+    {
+        try
+        {
+            using var volumeHandle = NativeMethods.CreateFile($@"\\.\{volume}", NativeMethods.GenericRead, NativeMethods.FileShareReadWrite,
+                IntPtr.Zero, NativeMethods.OpenExisting, 0, IntPtr.Zero);
+            if (volumeHandle.IsInvalid)
+            {
+                throw new Win32Exception(Marshal.GetLastWin32Error());
+            }
+
+            var journal = QueryJournal(volumeHandle);
+            var startUsn = GetStartUsn(volume, journal);
+            var directoryPaths = new Dictionary<ulong, string?>();
+            var translator = new UsnChangeTranslator(frn => ResolveDirectory(volumeHandle, directoryPaths, frn), _renameAndDeleteOnly);
+            var buffer = new byte[ReadBufferSize];
+            var lastSaved = Stopwatch.GetTimestamp();
+
+            while (!cancellationToken.IsCancellationRequested)
+            {
+                var readData = new NativeMethods.ReadUsnJournalData
+                {
+                    StartUsn = startUsn,
+                    ReasonMask = UsnReason.DataChange | UsnReason.FileCreate | UsnReason.FileDelete |
+                                 UsnReason.RenameOldName | UsnReason.RenameNewName | UsnReason.Close,
+                    UsnJournalId = journal.UsnJournalId
+                };
+
+                if (!NativeMethods.DeviceIoControl(volumeHandle, NativeMethods.FsctlReadUsnJournal, ref readData, Marshal.SizeOf<NativeMethods.ReadUsnJournalData>(),
+                        buffer, buffer.Length, out var bytesReturned, IntPtr.Zero))
+                {
+                    var error = Marshal.GetLastWin32Error();
+                    if (error != NativeMethods.ErrorJournalEntryDeleted)
+                    {
+                        throw new Win32Exception(error);
+                    }
+
+                    // 読み取りが追いつく前にジャーナルの上限で古いレコードが削除された
+                    journal = QueryJournal(volumeHandle);
+                    startUsn = journal.FirstUsn;
+                    Interlocked.Increment(ref _eventsLost);
+                    _logger.LogWarning("USNジャーナルのレコードが削除されたため、一部の変更を読み取れませんでした (Volume: {Volume})", volume);
+                    continue;
+                }
+
+                var records = UsnRecordParser.Parse(buffer.AsSpan(0, bytesReturned), out var nextUsn);
+                foreach (var record in records)
+                {
+                    if (record.IsDirectory && record.HasReason(UsnReason.RenameNewName | UsnReason.FileDelete))
+                    {
+                        // 配下のパスが変わるため、ディレクトリのリネーム・削除後に解決し直す
+                        directoryPaths.Clear();
+                    }
+
+                    foreach (var change in translator.Translate(record))
+                    {
+                        EnqueueChange(change, directories);
+                    }
+                }
+
+                startUsn = nextUsn;
+                _checkpoints[volume] = new UsnCheckpoint(journal.UsnJournalId, startUsn);
+                if (Stopwatch.GetElapsedTime(lastSaved) >= CheckpointSaveInterval)
+                {
+                    SaveCheckpoints();
+                    lastSaved = Stopwatch.GetTimestamp();
+                }
+
+                if (records.Count == 0)
+                {
+                    await Task.Delay(_pollInterval, cancellationToken);
+                }
+            }
+        }
+        catch (OperationCanceledException)
+        {
+            // 停止要求によるキャンセルは正常
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "USNジャーナルの読み取りでエラーが発生しました (Volume: {Volume})", volume);
+        }
+    }
+
+    /// <summary>
+    /// 前回のチェックポイントから読み取りを再開できるか判定し、開始位置を決める
+    /// </summary>
+    private long GetStartUsn(string volume, NativeMethods.UsnJournalData journal)
+    {
+        if (!_checkpoints.TryGetValue(volume, out var checkpoint))
+        {
+            return journal.NextUsn;
+        }
+
+        if (checkpoint.JournalId == journal.UsnJournalId && checkpoint.NextUsn >= journal.FirstUsn && checkpoint.NextUsn <= journal.NextUsn)
+        {
+            _logger.LogInformation("前回の停止位置からUSNジャーナルを読み取ります (Volume: {Volume}, Usn: {Usn})", volume, checkpoint.NextUsn);
+            return checkpoint.NextUsn;
+        }
+
+        // ジャーナルが再作成されたか、停止中の変更が多く前回の位置が既に削除されている
+        Interlocked.Increment(ref _eventsLost);
+        _logger.LogWarning("前回の停止位置のUSNジャーナルが残っていないため、停止中の変更は記録されません (Volume: {Volume})", volume);
+        return journal.NextUsn;
+    }
+
+    private static NativeMethods.UsnJournalData QueryJournal(SafeFileHandle volumeHandle)
+    {
+        if (!NativeMethods.DeviceIoControl(volumeHandle, NativeMethods.FsctlQueryUsnJournal, IntPtr.Zero, 0,
+                out var journal, Marshal.SizeOf<NativeMethods.UsnJournalData>(), out _, IntPtr.Zero))
+        {
+            var error = Marshal.GetLastWin32Error();
+            throw error == NativeMethods.ErrorJournalNotActive
+                ? new InvalidOperationException("ボリュームのUSNジャーナルが有効になっていません（fsutil usn createjournal で作成できます）")
+                : new Win32Exception(error);
+        }
+
+        return journal;
+    }
+
+    /// <summary>
+    /// ディレクトリのファイル参照番号からパスを取得
+    /// </summary>
+    private static string? ResolveDirectory(SafeFileHandle volumeHandle, Dictionary<ulong, string?> directoryPaths, ulong fileReferenceNumber)
+    {
+        if (directoryPaths.TryGetValue(fileReferenceNumber, out var cached))
+        {
+            return cached;
+        }
+
+        var fileId = new NativeMethods.FileIdDescriptor
+        {
+            Size = Marshal.SizeOf<NativeMethods.FileIdDescriptor>(),
+            FileId = (long)fileReferenceNumber
+        };
+
+        string? path = null;
+        using (var handle = NativeMethods.OpenFileById(volumeHandle, ref fileId, NativeMethods.FileReadAttributes,
+                   NativeMethods.FileShareReadWrite, IntPtr.Zero, NativeMethods.FileFlagBackupSemantics))
+        {
+            if (!handle.IsInvalid)
+            {
+                var buffer = new StringBuilder(1024);
+                var length = NativeMethods.GetFinalPathNameByHandle(handle, buffer, buffer.Capacity, 0);
+                if (length > 0 && length < buffer.Capacity)
+                {
+                    path = buffer.ToString();
+                    path = path.StartsWith(@"\\?\", StringComparison.Ordinal) ? path[4..] : path;
+                }
+            }
+        }
+
+        if (directoryPaths.Count >= MaxDirectoryCacheEntries)
+        {
+            directoryPaths.Clear();
+        }
+
+        directoryPaths[fileReferenceNumber] = path;
+        return path;
+    }
+
+    /// <summary>
+    /// 監視対象ディレクトリ配下の変更をタグごとのイベントとしてキューに追加
+    /// </summary>
+    private void EnqueueChange(UsnFileChange change, IReadOnlyList<(string TagName, string Directory)> directories)
+    {
+        var tagNames = directories
+            .Where(entry => IsUnder(change.FilePath, entry.Directory) || (change.NewFilePath != null && IsUnder(change.NewFilePath, entry.Directory)))
+            .Select(entry => entry.TagName)
+            .Distinct(StringComparer.Ordinal);
+
+        foreach (var tagName in tagNames)
+        {
+            var payload = new Dictionary<string, object>
+            {
+                ["FileName"] = change.FilePath,
+                ["Usn"] = change.Usn,
+                ["Source"] = "UsnJournal"
+            };
+            if (change.NewFilePath != null)
+            {
+                payload["NewFileName"] = change.NewFilePath;
+            }
+
+            // 変更したプロセスは分からないため、PIDは0としてタグを直接指定する
+            _eventQueue.Enqueue(new RawEventData(
+                change.Timestamp,
+                FileProviderName,
+                change.EventName,
+                0,
+                0,
+                Guid.Empty,
+                Guid.Empty,
+                payload,
+                ToMonotonicTimestamp(change.Timestamp),
+                TagName: tagName));
+        }
+    }
+
+    /// <summary>
+    /// レコードの変更時刻を単調増加クロックの値に換算（停止中の変更は監視開始より前の値になる）
+    /// </summary>
+    private long ToMonotonicTimestamp(DateTime timestamp)
+    {
+        var elapsed = timestamp.ToUniversalTime() - _clockMapping.SyncTimeUtc;
+        return _clockMapping.SyncTimestamp + (long)(elapsed.TotalSeconds * _clockMapping.Frequency);
+    }
+
+    private static bool IsUnder(string path, string directory)
+    {
+        return path.StartsWith(directory, StringComparison.OrdinalIgnoreCase) &&
+               (path.Length == directory.Length || path[directory.Length] == '\\');
+    }
+
+    /// <summary>
+    /// ドライブレターのボリューム上のディレクトリのみ対象とする（ネットワークドライブはUSNジャーナルを読み取れない）
+    /// </summary>
+    private static string? NormalizeDirectory(string directory)
+    {
+        var expanded = Environment.ExpandEnvironmentVariables(directory);
+        if (!Path.IsPathFullyQualified(expanded) || expanded.Length < 2 || expanded[1] != ':')
+        {
+            return null;
+        }
+
+        return Path.GetFullPath(expanded).TrimEnd('\\');
+    }
+
+    private void LoadCheckpoints()
+    {
+        try
+        {
+            if (!File.Exists(_checkpointPath))
+            {
+                return;
+            }
+
+            var checkpoints = JsonSerializer.Deserialize<Dictionary<string, UsnCheckpoint>>(File.ReadAllText(_checkpointPath));
+            foreach (var (volume, checkpoint) in checkpoints ?? new Dictionary<string, UsnCheckpoint>())
+            {
+                _checkpoints[volume] = checkpoint;
+            }
+        }
+        catch (Exception ex) when (ex is IOException or UnauthorizedAccessException or JsonException)
+        {
+            _logger.LogWarning(ex, "USNジャーナルのチェックポイントを読み込めませんでした (Path: {Path})", _checkpointPath);
+        }
+    }
+
+    private void SaveCheckpoints()
+    {
+        lock (_checkpointLock)
+        {
+            try
+            {
+                Directory.CreateDirectory(Path.GetDirectoryName(Path.GetFullPath(_checkpointPath))!);
+                var temporaryPath = _checkpointPath + ".tmp";
+                File.WriteAllText(temporaryPath, JsonSerializer.Serialize(_checkpoints));
+                File.Move(temporaryPath, _checkpointPath, overwrite: true);
+            }
+            catch (Exception ex) when (ex is IOException or UnauthorizedAccessException)
+            {
+                _logger.LogWarning(ex, "USNジャーナルのチェックポイントを保存できませんでした (Path: {Path})", _checkpointPath);
+            }
+        }
+    }
+
+    /// <summary>
+    /// イベント処理ループ
+    /// </summary>
+    private async Task ProcessEventsAsync()
+    {
+        try
+        {
+            while (!_cancellationTokenSource.Token.IsCancellationRequested)
+            {
+                while (_eventQueue.TryDequeue(out var rawEvent))
+                {
+                    try
+                    {
+                        EventReceived?.Invoke(this, rawEvent);
+                    }
+                    catch (Exception ex)
+                    {
+                        _logger.LogError(ex, "イベント配信中にエラーが発生しました");
+                    }
+                }
+
+                await Task.Delay(10, _cancellationTokenSource.Token);
+            }
+        }
+        catch (OperationCanceledException)
+        {
+            _logger.LogInformation("USNジャーナルのイベント処理ループが停止されました");
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "USNジャーナルのイベント処理ループでエラーが発生しました");
+        }
+    }
+
+    /// <summary>
+    /// リソースの解放
+    /// </summary>
+    public void Dispose()
+    {
+        if (_disposed)
+            return;
+
+        _disposed = true;
+
+        try
+        {
+            StopMonitoringAsync().GetAwaiter().GetResult();
+        }
+        catch (Exception ex)
+        {
+            _logger.LogError(ex, "{Provider}解放中にエラーが発生しました", GetType().Name);
+        }
+
+        _cancellationTokenSource.Dispose();
+    }
+
+    /// <summary>
+    /// ボリュームごとの読み取り位置
+    /// </summary>
+    /// <param name="JournalId">ジャーナルID（ジャーナルを再作成すると変わる）</param>
+    /// <param name="NextUsn">次に読み取るUSN</param>
+    private sealed record UsnCheckpoint(ulong JournalId, long NextUsn);
+}
@@ -0,0 +1,167 @@
+using Microsoft.Extensions.Logging;
+using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Infrastructure.Usn;
+
+/// <summary>
+/// ETWのイベントとUSNジャーナルのファイルイベントを1つのプロバイダーとして配信する
+/// </summary>
+/// <remarks>
+/// USNジャーナルをETWのファイルI/Oの代わりに使う場合は、ETWから届くファイルイベントを破棄する。
+/// 併用する場合はUSNジャーナル側がリネームと削除のみを記録するため、両方のイベントをそのまま配信する。
+/// 生イベントのパススルーはETW側のものをそのまま公開する。
+/// </remarks>
+public class UsnMergingEventProvider : IEtwEventProvider, IRawEventSource, IDisposable
+{
+    private const string FileProviderName = "Microsoft-Windows-Kernel-FileIO";
+
+    private readonly ILogger<UsnMergingEventProvider> _logger;
+    private readonly IEtwEventProvider _etwProvider;
+    private readonly IEtwEventProvider _usnProvider;
+    private readonly bool _replaceEtwFileEvents;
+    private bool _disposed;
+
+    /// <summary>
+    /// イベント受信時に発火するイベント
+    /// </summary>
+    public event EventHandler<RawEventData>? EventReceived;
+
+    /// <summary>
+    /// 生イベント受信時に発火するイベント（ETW側のイベントを転送）
+    /// </summary>
+    public event EventHandler<RawEtwRecord>? RawRecordReceived
+    {
+        add
+        {
+            if (_etwProvider is IRawEventSource rawEventSource)
+            {
+                rawEventSource.RawRecordReceived += value;
+            }
+        }
+        remove
+        {
+            if (_etwProvider is IRawEventSource rawEventSource)
+            {
+                rawEventSource.RawRecordReceived -= value;
+            }
+        }
+    }
+
+    /// <summary>
+    /// 監視中かどうか（ETW側の状態）
+    /// </summary>
+    public bool IsMonitoring => _etwProvider.IsMonitoring;
+
+    /// <summary>
+    /// ETWセッションの単調増加クロックと壁時計の対応付け
+    /// </summary>
+    public ClockMapping ClockMapping => _etwProvider.ClockMapping;
+
+    /// <summary>
+    /// ETWとUSNジャーナルで取りこぼしたイベントの合計
+    /// </summary>
+    public long EventsLost => _etwProvider.EventsLost + _usnProvider.EventsLost;
+
+    /// <summary>
+    /// 生イベントの配信が有効かどうか（ETW側の設定）
+    /// </summary>
+    public bool IsRawPassthroughEnabled => _etwProvider is IRawEventSource { IsRawPassthroughEnabled: true };
+
+    /// <summary>
+    /// 配信するプロセスの判定（ETW側に設定）
+    /// </summary>
+    public Func<int, bool>? RawRecordFilter
+    {
+        get => (_etwProvider as IRawEventSource)?.RawRecordFilter;
+        set
+        {
+            if (_etwProvider is IRawEventSource rawEventSource)
+            {
+                rawEventSource.RawRecordFilter = value;
+            }
+        }
+    }
+
+    /// <summary>
+    /// コンストラクタ
+    /// </summary>
+    /// <param name="logger">ロガー</param>
+    /// <param name="etwProvider">ETWのプロバイダー</param>
+    /// <param name="usnProvider">USNジャーナルのプロバイダー</param>
+    /// <param name="replaceEtwFileEvents">ETWのファイルイベントを破棄してUSNジャーナルで置き換えるか</param>
+    public UsnMergingEventProvider(
+        ILogger<UsnMergingEventProvider> logger,
+        IEtwEventProvider etwProvider,
+        IEtwEventProvider usnProvider,
+        bool replaceEtwFileEvents)
+    {
+        _logger = logger ?? throw new ArgumentNullException(nameof(logger));
+        _etwProvider = etwProvider ?? throw new ArgumentNullException(nameof(etwProvider));
+        _usnProvider = usnProvider ?? throw new ArgumentNullException(nameof(usnProvider));
+        _replaceEtwFileEvents = replaceEtwFileEvents;
+
+        _etwProvider.EventReceived += OnEtwEventReceived;
+        _usnProvider.EventReceived += OnUsnEventReceived;
+    }
+
+    /// <summary>
+    /// 監視を開始
+    /// </summary>
+    public async Task StartMonitoringAsync(CancellationToken cancellationToken = default)
+    {
+        if (_disposed)
+            throw new ObjectDisposedException(nameof(UsnMergingEventProvider));
+
+        await _etwProvider.StartMonitoringAsync(cancellationToken);
+
+        try
+        {
+            await _usnProvider.StartMonitoringAsync(cancellationToken);
+        }
+        catch (Exception ex)
+        {
+            // USNジャーナルを読み取れなくてもETWの監視は継続する
+            _logger.LogError(ex, "USNジャーナルの監視を開始できませんでした");
+        }
+    }
+
+    /// <summary>
+    /// 監視を停止
+    /// </summary>
+    public async Task StopMonitoringAsync(CancellationToken cancellationToken = default)
+    {
+        await _usnProvider.StopMonitoringAsync(cancellationToken);
+        await _etwProvider.StopMonitoringAsync(cancellationToken);
+    }
+
+    private void OnEtwEventReceived(object? sender, RawEventData rawEvent)
+    {
+        if (_replaceEtwFileEvents && rawEvent.ProviderName == FileProviderName)
+        {
+            return;
+        }
+
+        EventReceived?.Invoke(this, rawEvent);
+    }
+
+    private void OnUsnEventReceived(object? sender, RawEventData rawEvent)
+    {
+        EventReceived?.Invoke(this, rawEvent);
+    }
+
+    /// <summary>
+    /// リソースの解放
+    /// </summary>
+    public void Dispose()
+    {
+        if (_disposed)
+            return;
+
+        _disposed = true;
+        _etwProvider.EventReceived -= OnEtwEventReceived;
+        _usnProvider.EventReceived -= OnUsnEventReceived;
+        _usnProvider.Dispose();
+        _etwProvider.Dispose();
+    }
+}
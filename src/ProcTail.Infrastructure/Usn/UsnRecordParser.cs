@@ -0,0 +1,131 @@
+using System.Buffers.Binary;
+using System.Text;
+
+namespace ProcTail.Infrastructure.Usn;
+
+/// <summary>
+/// USNジャーナルの変更理由（USN_REASON_*）
+/// </summary>
+public static class UsnReason
+{
+    /// <summary>データの上書き</summary>
+    public const uint DataOverwrite = 0x00000001;
+
+    /// <summary>データの追加</summary>
+    public const uint DataExtend = 0x00000002;
+
+    /// <summary>データの切り詰め</summary>
+    public const uint DataTruncation = 0x00000004;
+
+    /// <summary>ファイルの作成</summary>
+    public const uint FileCreate = 0x00000100;
+
+    /// <summary>ファイルの削除</summary>
+    public const uint FileDelete = 0x00000200;
+
+    /// <summary>リネーム（リネーム前の名前のレコード）</summary>
+    public const uint RenameOldName = 0x00001000;
+
+    /// <summary>リネーム（リネーム後の名前のレコード）</summary>
+    public const uint RenameNewName = 0x00002000;
+
+    /// <summary>ハンドルのクローズ（それまでの変更理由を累積したレコード）</summary>
+    public const uint Close = 0x80000000;
+
+    /// <summary>
+    /// ファイル内容の変更
+    /// </summary>
+    public const uint DataChange = DataOverwrite | DataExtend | DataTruncation;
+}
+
+/// <summary>
+/// USNジャーナルのレコード（USN_RECORD_V2）
+/// </summary>
+/// <param name="FileReferenceNumber">ファイル参照番号</param>
+/// <param name="ParentFileReferenceNumber">親ディレクトリのファイル参照番号</param>
+/// <param name="Usn">レコードのUSN</param>
+/// <param name="Timestamp">変更時刻（ローカル時刻）</param>
+/// <param name="Reason">変更理由（ハンドルを閉じるまでの累積）</param>
+/// <param name="FileAttributes">ファイル属性</param>
+/// <param name="FileName">ファイル名（パスを含まない）</param>
+public readonly record struct UsnRecord(
+    ulong FileReferenceNumber,
+    ulong ParentFileReferenceNumber,
+    long Usn,
+    DateTime Timestamp,
+    uint Reason,
+    FileAttributes FileAttributes,
+    string FileName)
+{
+    /// <summary>
+    /// ディレクトリのレコードかどうか
+    /// </summary>
+    public bool IsDirectory => FileAttributes.HasFlag(FileAttributes.Directory);
+
+    /// <summary>
+    /// 指定した変更理由を含むかどうか
+    /// </summary>
+    public bool HasReason(uint reason) => (Reason & reason) != 0;
+}
+
+/// <summary>
+/// FSCTL_READ_USN_JOURNALの出力バッファを解析する
+/// </summary>
+/// <remarks>
+/// 出力の先頭8バイトは次に読み取るUSNで、その後にUSN_RECORD_V2が続く。
+/// READ_USN_JOURNAL_DATA_V0で読み取るためNTFSはV2のレコードを返すが、それ以外のバージョンは読み飛ばす。
+/// </remarks>
+public static class UsnRecordParser
+{
+    private const int RecordHeaderSize = 60;
+
+    /// <summary>
+    /// 出力バッファのレコードを解析
+    /// </summary>
+    /// <param name="buffer">FSCTL_READ_USN_JOURNALの出力（返されたバイト数に切り詰めたもの）</param>
+    /// <param name="nextUsn">次に読み取るUSN</param>
+    /// <returns>レコード</returns>
+    public static IReadOnlyList<UsnRecord> Parse(ReadOnlySpan<byte> buffer, out long nextUsn)
+    {
+        if (buffer.Length < sizeof(long))
+        {
+            throw new ArgumentException("USNジャーナルの出力が短すぎます", nameof(buffer));
+        }
+
+        nextUsn = BinaryPrimitives.ReadInt64LittleEndian(buffer);
+        var records = new List<UsnRecord>();
+        var offset = sizeof(long);
+
+        while (offset + RecordHeaderSize <= buffer.Length)
+        {
+            var record = buffer[offset..];
+            var recordLength = (int)BinaryPrimitives.ReadUInt32LittleEndian(record);
+            if (recordLength < RecordHeaderSize || recordLength > record.Length)
+            {
+                break;
+            }
+
+            var majorVersion = BinaryPrimitives.ReadUInt16LittleEndian(record[4..]);
+            if (majorVersion == 2)
+            {
+                var fileNameLength = BinaryPrimitives.ReadUInt16LittleEndian(record[56..]);
+                var fileNameOffset = BinaryPrimitives.ReadUInt16LittleEndian(record[58..]);
+                if (fileNameOffset + fileNameLength <= recordLength)
+                {
+                    records.Add(new UsnRecord(
+                        BinaryPrimitives.ReadUInt64LittleEndian(record[8..]),
+                        BinaryPrimitives.ReadUInt64LittleEndian(record[16..]),
+                        BinaryPrimitives.ReadInt64LittleEndian(record[24..]),
+                        DateTime.FromFileTime(BinaryPrimitives.ReadInt64LittleEndian(record[32..])),
+                        BinaryPrimitives.ReadUInt32LittleEndian(record[40..]),
+                        (FileAttributes)BinaryPrimitives.ReadUInt32LittleEndian(record[52..]),
+                        Encoding.Unicode.GetString(record.Slice(fileNameOffset, fileNameLength))));
+                }
+            }
+
+            offset += recordLength;
+        }
+
+        return records;
+    }
+}
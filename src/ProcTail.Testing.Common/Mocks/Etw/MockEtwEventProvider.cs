@@ -25,6 +25,16 @@ public class MockEtwEventProvider : IEtwEventProvider
     /// </summary>
     public bool IsMonitoring => _isMonitoring;
 
+    /// <summary>
+    /// クロック対応付け
+    /// </summary>
+    public ClockMapping ClockMapping { get; } = ClockMapping.Capture();
+
+    /// <summary>
+    /// 取りこぼしたイベント数（モックでは常に0）
+    /// </summary>
+    public long EventsLost => 0;
+
     /// <summary>
     /// コンストラクタ
     /// </summary>
@@ -212,7 +222,14 @@ public class MockEtwConfiguration : IEtwConfiguration
         "Process/End"
     };
 
+    public IReadOnlyList<string> KernelKeywords => Array.Empty<string>();
+    public IReadOnlyDictionary<string, EtwProviderSettings> ProviderSettings => new Dictionary<string, EtwProviderSettings>();
+    public bool EnableRawPassthrough => false;
+    public IReadOnlyList<string> RawPassthroughProviders => Array.Empty<string>();
+
     public TimeSpan EventBufferTimeout => TimeSpan.FromMilliseconds(100);
     public int BufferSizeMB => 64;
     public int BufferCount => 20;
+    public int MaxEventsPerSecond => 0;
+    public int OverflowSampleRate => 10;
 }
\ No newline at end of file
@@ -0,0 +1,115 @@
+using FluentAssertions;
+using Microsoft.Extensions.Logging;
+using Moq;
+using NUnit.Framework;
+using ProcTail.Application.Services;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Tests.Services;
+
+[TestFixture]
+[Category("Unit")]
+public class AlertRuleEngineTests
+{
+    private static readonly DateTime BaseTime = new(2024, 1, 1, 0, 0, 0, DateTimeKind.Utc);
+
+    [Test]
+    public void Evaluate_WithMatchingFileEvent_ShouldRaiseAlertForEachEvent()
+    {
+        // Arrange
+        var engine = CreateEngine(new AlertRule
+        {
+            Name = "save-changed",
+            TagName = "game",
+            EventNames = new[] { "FileIO/Write", "FileIO/Rename" },
+            PathPattern = @"D:\Saves\**"
+        });
+
+        // Act
+        var written = engine.Evaluate(CreateFileEvent("FileIO/Write", @"D:\Saves\slot1.sav", BaseTime));
+        var otherPath = engine.Evaluate(CreateFileEvent("FileIO/Write", @"D:\Logs\game.log", BaseTime));
+        var otherEvent = engine.Evaluate(CreateFileEvent("FileIO/Read", @"D:\Saves\slot1.sav", BaseTime));
+        var otherTag = engine.Evaluate(CreateFileEvent("FileIO/Write", @"D:\Saves\slot1.sav", BaseTime, "launcher"));
+        var renamed = engine.Evaluate(CreateFileEvent("FileIO/Rename", @"D:\Temp\slot1.tmp", BaseTime,
+            payload: new Dictionary<string, object> { ["NewFileName"] = @"D:\Saves\slot1.sav" }));
+
+        // Assert
+        written.Should().ContainSingle().Which.Should().BeEquivalentTo(new { RuleName = "save-changed", TagName = "game", MatchCount = 1, SequenceNumber = 1 });
+        otherPath.Should().BeEmpty();
+        otherEvent.Should().BeEmpty();
+        otherTag.Should().BeEmpty();
+        renamed.Should().ContainSingle().Which.SequenceNumber.Should().Be(2);
+    }
+
+    [Test]
+    public void Evaluate_WithThreshold_ShouldRaiseAlertOnlyWhenCountIsReachedWithinWindow()
+    {
+        // Arrange
+        var engine = CreateEngine(new AlertRule { Name = "burst", EventNames = new[] { "FileIO/*" }, Threshold = 3, WindowSeconds = 10 });
+
+        // Act
+        var results = new[] { 0, 5, 20, 25, 28, 29 }
+            .Select(seconds => engine.Evaluate(CreateFileEvent("FileIO/Write", @"C:\data.bin", BaseTime.AddSeconds(seconds))).Count)
+            .ToList();
+
+        // Assert（0秒と5秒の一致は期間外になり、20〜28秒の3件で発生した後は数え直す）
+        results.Should().Equal(0, 0, 0, 0, 1, 0);
+        engine.GetAfter(null, 0, 10).Alerts.Should().ContainSingle().Which.MatchCount.Should().Be(3);
+    }
+
+    [Test]
+    public void GetAfter_ShouldFilterByTagAndKeepSequenceNumbersAfterClear()
+    {
+        // Arrange
+        var engine = CreateEngine(new AlertRule { Name = "any" });
+        engine.Evaluate(CreateFileEvent("FileIO/Write", @"C:\a.txt", BaseTime, "game"));
+        engine.Evaluate(CreateFileEvent("FileIO/Write", @"C:\b.txt", BaseTime, "launcher"));
+        engine.Evaluate(CreateFileEvent("FileIO/Write", @"C:\c.txt", BaseTime, "game"));
+
+        // Act
+        var (gameAlerts, lastSequenceNumber) = engine.GetAfter("game", 1, 10);
+        engine.Clear("game");
+        var next = engine.Evaluate(CreateFileEvent("FileIO/Write", @"C:\d.txt", BaseTime, "game"));
+
+        // Assert
+        gameAlerts.Should().ContainSingle().Which.SequenceNumber.Should().Be(3);
+        lastSequenceNumber.Should().Be(3);
+        engine.GetAfter(null, 0, 10).Alerts.Select(alert => alert.TagName).Should().Equal("launcher", "game");
+        next.Should().ContainSingle().Which.SequenceNumber.Should().Be(4);
+    }
+
+    [Test]
+    public void Constructor_WithInvalidRules_ShouldIgnoreThem()
+    {
+        // Act
+        var engine = CreateEngine(
+            new AlertRule { Name = "invalid-pattern", PathPattern = "regex:(" },
+            new AlertRule { Name = "invalid-threshold", Threshold = 0 },
+            new AlertRule { Name = "valid" });
+
+        // Assert
+        engine.Rules.Select(rule => rule.Name).Should().Equal("valid");
+    }
+
+    private static AlertRuleEngine CreateEngine(params AlertRule[] rules)
+    {
+        return new AlertRuleEngine(new Mock<ILogger<AlertRuleEngine>>().Object, rules);
+    }
+
+    private static FileEventData CreateFileEvent(string eventName, string filePath, DateTime timestamp, string tagName = "game", Dictionary<string, object>? payload = null)
+    {
+        return new FileEventData
+        {
+            Timestamp = timestamp,
+            TagName = tagName,
+            ProcessId = 1234,
+            ThreadId = 5678,
+            ProviderName = "Microsoft-Windows-Kernel-FileIO",
+            EventName = eventName,
+            ActivityId = Guid.Empty,
+            RelatedActivityId = Guid.Empty,
+            Payload = payload ?? new Dictionary<string, object>(),
+            FilePath = filePath
+        };
+    }
+}
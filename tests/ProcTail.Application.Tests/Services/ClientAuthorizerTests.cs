@@ -0,0 +1,87 @@
+using FluentAssertions;
+using Microsoft.Extensions.Logging;
+using Moq;
+using NUnit.Framework;
+using ProcTail.Application.Services;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Tests.Services;
+
+[TestFixture]
+[Category("Unit")]
+public class ClientAuthorizerTests
+{
+    [Test]
+    public void Authenticate_WithConfiguredTokens_ShouldReturnMatchingClientOnly()
+    {
+        // Arrange
+        var authorizer = CreateAuthorizer(
+            new ClientToken { Name = "alice", Token = "alice-secret" },
+            new ClientToken { Name = "bob", Token = "bob-secret" });
+
+        // Act & Assert
+        authorizer.IsEnabled.Should().BeTrue();
+        authorizer.Authenticate("bob-secret")!.Name.Should().Be("bob");
+        authorizer.Authenticate("alice-secret")!.Name.Should().Be("alice");
+        authorizer.Authenticate("alice-secret ").Should().BeNull();
+        authorizer.Authenticate("unknown").Should().BeNull();
+        authorizer.Authenticate(null).Should().BeNull();
+    }
+
+    [Test]
+    public void Constructor_WithEmptyTokenOrInvalidTagPattern_ShouldIgnoreToken()
+    {
+        // Arrange & Act
+        var authorizer = CreateAuthorizer(
+            new ClientToken { Name = "empty", Token = "" },
+            new ClientToken { Name = "invalid", Token = "invalid-secret", Tags = new[] { "regex:(" } });
+
+        // Assert（有効なトークンがないため認証は行わない）
+        authorizer.IsEnabled.Should().BeFalse();
+        authorizer.Authenticate("invalid-secret").Should().BeNull();
+    }
+
+    [Test]
+    public void IsAllowed_ShouldRequirePermissionAndMatchingTag()
+    {
+        // Arrange
+        var watcher = new ClientToken { Name = "alice", Token = "alice-secret", Permission = ClientPermission.Watch, Tags = new[] { "alice-*" } };
+        var reader = new ClientToken { Name = "viewer", Token = "viewer-secret" };
+
+        // Act & Assert
+        ClientAuthorizer.IsAllowed(watcher, ClientPermission.Watch, "alice-game").Should().BeTrue();
+        ClientAuthorizer.IsAllowed(watcher, ClientPermission.Read, "alice-game").Should().BeTrue();
+        ClientAuthorizer.IsAllowed(watcher, ClientPermission.Read, "bob-game").Should().BeFalse();
+        ClientAuthorizer.IsAllowed(watcher, ClientPermission.Admin, null).Should().BeFalse();
+        ClientAuthorizer.IsAllowed(reader, ClientPermission.Read, "bob-game").Should().BeTrue();
+        ClientAuthorizer.IsAllowed(reader, ClientPermission.Watch, "bob-game").Should().BeFalse();
+    }
+
+    [TestCase("GetRecordedEvents", ClientPermission.Read)]
+    [TestCase("GetStatus", ClientPermission.Read)]
+    [TestCase("AddWatchTarget", ClientPermission.Watch)]
+    [TestCase("ClearEvents", ClientPermission.Watch)]
+    [TestCase("Shutdown", ClientPermission.Admin)]
+    [TestCase("Unknown", ClientPermission.Admin)]
+    public void GetRequiredPermission_ShouldMapRequestType(string requestType, ClientPermission expected)
+    {
+        // Act & Assert
+        ClientAuthorizer.GetRequiredPermission(requestType).Should().Be(expected);
+    }
+
+    [TestCase("Bearer abc123", "abc123")]
+    [TestCase("bearer  abc123 ", "abc123")]
+    [TestCase("Basic abc123", null)]
+    [TestCase("Bearer ", null)]
+    [TestCase(null, null)]
+    public void ParseBearerToken_ShouldExtractToken(string? authorization, string? expected)
+    {
+        // Act & Assert
+        ClientAuthorizer.ParseBearerToken(authorization).Should().Be(expected);
+    }
+
+    private static ClientAuthorizer CreateAuthorizer(params ClientToken[] tokens)
+    {
+        return new ClientAuthorizer(new Mock<ILogger<ClientAuthorizer>>().Object, tokens);
+    }
+}
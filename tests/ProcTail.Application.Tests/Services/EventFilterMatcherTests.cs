@@ -0,0 +1,122 @@
+using FluentAssertions;
+using NUnit.Framework;
+using ProcTail.Application.Services;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Tests.Services;
+
+[TestFixture]
+[Category("Unit")]
+public class EventFilterMatcherTests
+{
+    private static readonly DateTime BaseTime = new(2024, 1, 1, 0, 0, 0, DateTimeKind.Utc);
+
+    [Test]
+    public void IsMatch_WithEmptyFilter_ShouldMatchAllEvents()
+    {
+        // Arrange
+        var filter = new EventFilter();
+
+        // Act & Assert
+        EventFilterMatcher.IsMatch(filter, CreateFileEvent(@"C:\game\save\slot1.sav")).Should().BeTrue();
+        EventFilterMatcher.IsMatch(filter, CreateProcessStartEvent()).Should().BeTrue();
+    }
+
+    [Test]
+    public void IsMatch_WithEventTypes_ShouldMatchJsonTypeDiscriminator()
+    {
+        // Arrange
+        var filter = new EventFilter { EventTypes = new() { "process_start" } };
+
+        // Act & Assert
+        EventFilterMatcher.IsMatch(filter, CreateProcessStartEvent()).Should().BeTrue();
+        EventFilterMatcher.IsMatch(filter, CreateFileEvent(@"C:\game\save\slot1.sav")).Should().BeFalse();
+    }
+
+    [Test]
+    public void IsMatch_WithEventNameCategory_ShouldMatchEventsInCategory()
+    {
+        // Arrange
+        var filter = new EventFilter { EventNames = new() { "FileIO/*" } };
+
+        // Act & Assert
+        EventFilterMatcher.IsMatch(filter, CreateFileEvent(@"C:\game\save\slot1.sav")).Should().BeTrue();
+        EventFilterMatcher.IsMatch(filter, CreateProcessStartEvent()).Should().BeFalse();
+    }
+
+    [Test]
+    public void IsMatch_WithPathPatterns_ShouldMatchAnyPatternAndExcludeEventsWithoutPath()
+    {
+        // Arrange
+        var filter = new EventFilter { PathPatterns = new() { "**/*.sav", "**/*.ini" } };
+
+        // Act & Assert
+        EventFilterMatcher.IsMatch(filter, CreateFileEvent(@"C:\game\save\slot1.sav")).Should().BeTrue();
+        EventFilterMatcher.IsMatch(filter, CreateFileEvent(@"C:\game\config.ini")).Should().BeTrue();
+        EventFilterMatcher.IsMatch(filter, CreateFileEvent(@"C:\game\output.log")).Should().BeFalse();
+        EventFilterMatcher.IsMatch(filter, CreateProcessStartEvent()).Should().BeFalse();
+    }
+
+    [Test]
+    public void IsMatch_WithProcessIdsAndTimeRange_ShouldRequireAllConditions()
+    {
+        // Arrange
+        var filter = new EventFilter
+        {
+            ProcessIds = new() { 1234 },
+            Since = BaseTime,
+            Until = BaseTime.AddMinutes(1)
+        };
+
+        // Act & Assert
+        EventFilterMatcher.IsMatch(filter, CreateFileEvent(@"C:\game\a.sav", timestamp: BaseTime)).Should().BeTrue();
+        EventFilterMatcher.IsMatch(filter, CreateFileEvent(@"C:\game\a.sav", timestamp: BaseTime.AddMinutes(1))).Should().BeFalse();
+        EventFilterMatcher.IsMatch(filter, CreateFileEvent(@"C:\game\a.sav", timestamp: BaseTime.AddSeconds(-1))).Should().BeFalse();
+        EventFilterMatcher.IsMatch(filter, CreateFileEvent(@"C:\game\a.sav", processId: 5678, timestamp: BaseTime)).Should().BeFalse();
+    }
+
+    [Test]
+    public void IsValid_WithInvalidRegexPattern_ShouldReturnFalse()
+    {
+        // Arrange
+        var filter = new EventFilter { PathPatterns = new() { "**/*.sav", "regex:(" } };
+
+        // Act & Assert
+        EventFilterMatcher.IsValid(filter).Should().BeFalse();
+    }
+
+    private static FileEventData CreateFileEvent(string filePath, int processId = 1234, DateTime? timestamp = null)
+    {
+        return new FileEventData
+        {
+            Timestamp = timestamp ?? BaseTime,
+            TagName = "test-tag",
+            ProcessId = processId,
+            ThreadId = 1,
+            ProviderName = "Microsoft-Windows-Kernel-FileIO",
+            EventName = "FileIO/Write",
+            ActivityId = Guid.Empty,
+            RelatedActivityId = Guid.Empty,
+            Payload = new Dictionary<string, object>(),
+            FilePath = filePath
+        };
+    }
+
+    private static ProcessStartEventData CreateProcessStartEvent()
+    {
+        return new ProcessStartEventData
+        {
+            Timestamp = BaseTime,
+            TagName = "test-tag",
+            ProcessId = 1234,
+            ThreadId = 1,
+            ProviderName = "Microsoft-Windows-Kernel-Process",
+            EventName = "Process/Start",
+            ActivityId = Guid.Empty,
+            RelatedActivityId = Guid.Empty,
+            Payload = new Dictionary<string, object>(),
+            ChildProcessId = 5678,
+            ChildProcessName = "child.exe"
+        };
+    }
+}
@@ -198,7 +198,7 @@ public class EventProcessorTests
         );
 
         _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
-        _mockWatchTargetManager.Setup(x => x.GetTagForProcess(1234)).Returns((string?)null);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(Array.Empty<string>());
 
         // Act
         var result = await _processor.ProcessEventAsync(rawEvent);
@@ -209,6 +209,49 @@ public class EventProcessorTests
         result.ErrorMessage.Should().Contain("Tag not found");
     }
 
+    [Test]
+    public async Task ProcessEventAsync_WithMonotonicTimestamp_ShouldCarryItToEventData()
+    {
+        // Arrange
+        var rawEvent = TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-Kernel-FileIO",
+            "FileIO/Create",
+            1234,
+            new Dictionary<string, object> { { "FileName", @"C:\test\file.txt" } }
+        ) with { MonotonicTimestamp = 123456789 };
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+
+        // Act
+        var result = await _processor.ProcessEventAsync(rawEvent);
+
+        // Assert
+        result.Success.Should().BeTrue();
+        result.EventData!.MonotonicTimestamp.Should().Be(123456789);
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithTagNameFromProvider_ShouldRecordUnderThatTag()
+    {
+        // Arrange
+        var rawEvent = TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-Kernel-FileIO",
+            "FileIO/Create",
+            0,
+            new Dictionary<string, object> { { "FileName", @"C:\test\file.txt" } }
+        ) with { TagName = "degraded-tag", IsDegraded = true };
+
+        // Act
+        var result = await _processor.ProcessEventAsync(rawEvent);
+
+        // Assert
+        result.Success.Should().BeTrue();
+        result.EventData!.TagName.Should().Be("degraded-tag");
+        result.EventData.IsDegraded.Should().BeTrue();
+        _mockWatchTargetManager.Verify(x => x.IsWatchedProcess(It.IsAny<int>()), Times.Never);
+    }
+
     [Test]
     public async Task ProcessEventAsync_WithValidFileEvent_ShouldReturnFileEventData()
     {
@@ -227,7 +270,7 @@ public class EventProcessorTests
         );
 
         _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
-        _mockWatchTargetManager.Setup(x => x.GetTagForProcess(1234)).Returns("test-tag");
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
 
         // Act
         var result = await _processor.ProcessEventAsync(rawEvent);
@@ -265,7 +308,7 @@ public class EventProcessorTests
         );
 
         _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
-        _mockWatchTargetManager.Setup(x => x.GetTagForProcess(1234)).Returns("test-tag");
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
         _mockWatchTargetManager.Setup(x => x.AddChildProcessAsync(5678, 1234)).ReturnsAsync(true);
 
         // Act
@@ -282,6 +325,9 @@ public class EventProcessorTests
         processEvent.ProcessId.Should().Be(1234);
         processEvent.ChildProcessId.Should().Be(5678);
         processEvent.ChildProcessName.Should().Be("child.exe");
+        processEvent.ParentProcessId.Should().Be(1234);
+        processEvent.CommandLine.Should().Be("child.exe --test");
+        processEvent.CurrentDirectory.Should().BeEmpty();
         processEvent.ProviderName.Should().Be("Microsoft-Windows-Kernel-Process");
         processEvent.EventName.Should().Be("Process/Start");
 
@@ -310,7 +356,7 @@ public class EventProcessorTests
         );
 
         _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
-        _mockWatchTargetManager.Setup(x => x.GetTagForProcess(1234)).Returns("test-tag");
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
         _mockWatchTargetManager.Setup(x => x.RemoveTarget(1234)).Returns(true);
 
         // Act
@@ -336,6 +382,766 @@ public class EventProcessorTests
         _mockWatchTargetManager.Verify(x => x.RemoveTarget(1234), Times.Once);
     }
 
+    [TestCase(0, null, ProcessExitReason.Normal)]
+    [TestCase(1, null, ProcessExitReason.Normal)]
+    [TestCase(3221225477u, null, ProcessExitReason.Crashed)]
+    [TestCase(3221225786u, null, ProcessExitReason.Killed)]
+    [TestCase(139, 11, ProcessExitReason.Crashed)]
+    [TestCase(137, 9, ProcessExitReason.Killed)]
+    public async Task ProcessEventAsync_WithProcessEndEvent_ShouldClassifyExitReason(object exitStatus, int? terminationSignal, ProcessExitReason expected)
+    {
+        // Arrange（3221225477: 0xC0000005 アクセス違反、3221225786: 0xC000013A Ctrl+C）
+        var payload = new Dictionary<string, object> { { "ExitStatus", exitStatus } };
+        if (terminationSignal.HasValue)
+        {
+            payload["TerminationSignal"] = terminationSignal.Value;
+        }
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+
+        // Act
+        var result = await _processor.ProcessEventAsync(
+            TestEventFactory.CreateRawEvent("Microsoft-Windows-Kernel-Process", "Process/End", 1234, payload));
+
+        // Assert
+        result.EventData.Should().BeOfType<ProcessEndEventData>().Which.ExitReason.Should().Be(expected);
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithErrorReportingForWatchedProcess_ShouldClassifyExitAsCrashed()
+    {
+        // Arrange（WerFault.exe は監視対象外のプロセスから起動される場合もある）
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+
+        var werFaultStart = TestEventFactory.CreateRawEvent("Microsoft-Windows-Kernel-Process", "Process/Start", 900, new Dictionary<string, object>
+        {
+            { "ProcessId", 4321 },
+            { "ImageFileName", "WerFault.exe" },
+            { "CommandLine", @"C:\WINDOWS\system32\WerFault.exe -u -p 1234 -s 560" }
+        });
+        var processEnd = TestEventFactory.CreateRawEvent("Microsoft-Windows-Kernel-Process", "Process/End", 1234,
+            new Dictionary<string, object> { { "ExitStatus", 1 } });
+
+        // Act
+        await _processor.ProcessEventAsync(werFaultStart);
+        var result = await _processor.ProcessEventAsync(processEnd);
+
+        // Assert
+        var processEvent = result.EventData.Should().BeOfType<ProcessEndEventData>().Subject;
+        processEvent.ExitCode.Should().Be(1);
+        processEvent.ExitReason.Should().Be(ProcessExitReason.Crashed);
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithApplicationErrorEvent_ShouldReturnCrashDetectedEventAndReportDumpPathLater()
+    {
+        // Arrange
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+
+        var mockDumpLocator = new Mock<ICrashDumpLocator>();
+        mockDumpLocator.Setup(x => x.FindDumpPathAsync("game.exe", 1234, It.IsAny<CancellationToken>()))
+            .ReturnsAsync(@"C:\Users\player\AppData\Local\CrashDumps\game.exe.1234.dmp");
+
+        var processor = new EventProcessor(_mockLogger.Object, _mockWatchTargetManager.Object,
+            _mockEtwConfiguration.Object, null, null, crashDumpLocator: mockDumpLocator.Object);
+        var dumpWritten = new TaskCompletionSource<BaseEventData>(TaskCreationOptions.RunContinuationsAsynchronously);
+        processor.FollowUpEventCreated += (_, eventData) => dumpWritten.TrySetResult(eventData);
+
+        var crash = TestEventFactory.CreateRawEvent("Application Error", "Crash/Detected", 1234, new Dictionary<string, object>
+        {
+            { "ProcessId", 1234 },
+            { "ExceptionCode", 0xC0000005u },
+            { "ApplicationName", "game.exe" },
+            { "FaultingModuleName", "render.dll" },
+            { "FaultOffset", 0x1a2bUL },
+            { "FaultingModulePath", @"C:\Games\render.dll" }
+        });
+        var processEnd = TestEventFactory.CreateRawEvent("Microsoft-Windows-Kernel-Process", "Process/End", 1234,
+            new Dictionary<string, object> { { "ExitStatus", 1 } });
+
+        // Act
+        var crashResult = await processor.ProcessEventAsync(crash);
+        var endResult = await processor.ProcessEventAsync(processEnd);
+
+        // Assert
+        var crashEvent = crashResult.EventData.Should().BeOfType<CrashDetectedEventData>().Subject;
+        crashEvent.ApplicationName.Should().Be("game.exe");
+        crashEvent.ApplicationPath.Should().BeNull();
+        crashEvent.FaultingModule.Should().Be("render.dll");
+        crashEvent.FaultingModulePath.Should().Be(@"C:\Games\render.dll");
+        crashEvent.ExceptionCode.Should().Be(0xC0000005u);
+        crashEvent.FaultOffset.Should().Be(0x1a2bUL);
+        crashEvent.DumpPath.Should().BeNull();
+        endResult.EventData.Should().BeOfType<ProcessEndEventData>().Which.ExitReason.Should().Be(ProcessExitReason.Crashed);
+
+        // ダンプのパスはクラッシュイベントとは別に、同じプロセスのイベントとして配信される
+        var dumpEvent = (await dumpWritten.Task.WaitAsync(TimeSpan.FromSeconds(5))).Should().BeOfType<CrashDetectedEventData>().Subject;
+        dumpEvent.EventName.Should().Be(EventProcessor.CrashDumpWrittenEventName);
+        dumpEvent.ProcessId.Should().Be(1234);
+        dumpEvent.TagName.Should().Be("test-tag");
+        dumpEvent.ApplicationName.Should().Be("game.exe");
+        dumpEvent.DumpPath.Should().Be(@"C:\Users\player\AppData\Local\CrashDumps\game.exe.1234.dmp");
+    }
+
+    [TestCase("Focus/Gained", true, "MyGame", null)]
+    [TestCase("Focus/Lost", false, null, 90000L)]
+    public async Task ProcessEventAsync_WithForegroundEvent_ShouldReturnForegroundEventData(string eventName, bool expectedForeground, string? expectedTitle, long? expectedDuration)
+    {
+        // Arrange
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+
+        var payload = eventName == "Focus/Gained"
+            ? new Dictionary<string, object> { { "WindowTitle", "MyGame" } }
+            : new Dictionary<string, object> { { "ForegroundDurationMs", 90000L } };
+
+        // Act
+        var result = await _processor.ProcessEventAsync(TestEventFactory.CreateRawEvent("WinEvent", eventName, 1234, payload));
+
+        // Assert
+        var foregroundEvent = result.EventData.Should().BeOfType<ForegroundEventData>().Subject;
+        foregroundEvent.IsForeground.Should().Be(expectedForeground);
+        foregroundEvent.WindowTitle.Should().Be(expectedTitle);
+        foregroundEvent.ForegroundDurationMs.Should().Be(expectedDuration);
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithValidRegistryEvent_ShouldReturnRegistryEventData()
+    {
+        // Arrange
+        var payload = new Dictionary<string, object>
+        {
+            { "KeyName", @"\REGISTRY\USER\S-1-5-21\Software\TestApp" },
+            { "ValueName", "LastOpened" },
+            { "Status", 0 }
+        };
+
+        var rawEvent = TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-Kernel-Registry",
+            "Registry/SetValue",
+            1234,
+            payload
+        );
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+
+        // Act
+        var result = await _processor.ProcessEventAsync(rawEvent);
+
+        // Assert
+        result.Success.Should().BeTrue();
+        result.EventData.Should().BeOfType<RegistryEventData>();
+
+        var registryEvent = (RegistryEventData)result.EventData!;
+        registryEvent.TagName.Should().Be("test-tag");
+        registryEvent.KeyName.Should().Be(@"\REGISTRY\USER\S-1-5-21\Software\TestApp");
+        registryEvent.ValueName.Should().Be("LastOpened");
+        registryEvent.Operation.Should().Be("SetValue");
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithRegistryEventMissingKeyName_ShouldReturnFailureResult()
+    {
+        // Arrange
+        var rawEvent = TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-Kernel-Registry",
+            "Registry/Open",
+            1234,
+            new Dictionary<string, object> { { "Status", 0 } }
+        );
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+
+        // Act
+        var result = await _processor.ProcessEventAsync(rawEvent);
+
+        // Assert
+        result.Success.Should().BeFalse();
+        result.ErrorMessage.Should().Contain("Failed to convert");
+    }
+
+    [TestCase("TcpIp/Connect", "TCP", "Connect")]
+    [TestCase("TcpIp/Recv", "TCP", "Receive")]
+    [TestCase("UdpIp/Send", "UDP", "Send")]
+    public async Task ProcessEventAsync_WithValidNetworkEvent_ShouldReturnNetworkEventData(string eventName, string protocol, string operation)
+    {
+        // Arrange
+        var payload = new Dictionary<string, object>
+        {
+            { "PID", 1234 },
+            { "size", 512 },
+            { "daddr", "93.184.216.34" },
+            { "saddr", "192.168.1.10" },
+            { "dport", 443 },
+            { "sport", 50123 }
+        };
+
+        var rawEvent = TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-Kernel-Network",
+            eventName,
+            1234,
+            payload
+        );
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+
+        // Act
+        var result = await _processor.ProcessEventAsync(rawEvent);
+
+        // Assert
+        result.Success.Should().BeTrue();
+        result.EventData.Should().BeOfType<NetworkEventData>();
+
+        var networkEvent = (NetworkEventData)result.EventData!;
+        networkEvent.Protocol.Should().Be(protocol);
+        networkEvent.Operation.Should().Be(operation);
+        networkEvent.RemoteAddress.Should().Be("93.184.216.34");
+        networkEvent.RemotePort.Should().Be(443);
+        networkEvent.LocalAddress.Should().Be("192.168.1.10");
+        networkEvent.LocalPort.Should().Be(50123);
+        networkEvent.Bytes.Should().Be(512);
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithDnsQueryThenNetworkEvent_ShouldAttachRemoteHostName()
+    {
+        // Arrange
+        var dnsPayload = new Dictionary<string, object>
+        {
+            { "QueryName", "example.com" },
+            { "QueryType", 1 },
+            { "QueryStatus", 0 },
+            { "QueryResults", "type:  5 edge.example.net;::ffff:93.184.216.34;" }
+        };
+        var networkPayload = new Dictionary<string, object>
+        {
+            { "size", 128 },
+            { "daddr", "93.184.216.34" },
+            { "saddr", "192.168.1.10" },
+            { "dport", 443 },
+            { "sport", 50123 }
+        };
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+
+        // Act
+        var dnsResult = await _processor.ProcessEventAsync(TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-DNS-Client", "Dns/QueryCompleted", 1234, dnsPayload));
+        var networkResult = await _processor.ProcessEventAsync(TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-Kernel-Network", "TcpIp/Connect", 1234, networkPayload));
+
+        // Assert
+        dnsResult.Success.Should().BeTrue();
+        var dnsEvent = dnsResult.EventData.Should().BeOfType<DnsQueryEventData>().Subject;
+        dnsEvent.QueryName.Should().Be("example.com");
+        dnsEvent.QueryType.Should().Be(1);
+        dnsEvent.Addresses.Should().Equal("::ffff:93.184.216.34");
+
+        var networkEvent = networkResult.EventData.Should().BeOfType<NetworkEventData>().Subject;
+        networkEvent.RemoteHostName.Should().Be("example.com");
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithFailedDnsQuery_ShouldNotResolveHostName()
+    {
+        // Arrange
+        var dnsPayload = new Dictionary<string, object>
+        {
+            { "QueryName", "example.com" },
+            { "QueryType", 1 },
+            { "QueryStatus", 9003 },
+            { "QueryResults", "93.184.216.34;" }
+        };
+        var networkPayload = new Dictionary<string, object>
+        {
+            { "daddr", "93.184.216.34" },
+            { "dport", 443 }
+        };
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+
+        // Act
+        await _processor.ProcessEventAsync(TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-DNS-Client", "Dns/QueryCompleted", 1234, dnsPayload));
+        var networkResult = await _processor.ProcessEventAsync(TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-Kernel-Network", "TcpIp/Connect", 1234, networkPayload));
+
+        // Assert
+        var networkEvent = networkResult.EventData.Should().BeOfType<NetworkEventData>().Subject;
+        networkEvent.RemoteHostName.Should().BeNull();
+    }
+
+    [TestCase(1, false)]
+    [TestCase(4, true)]
+    [TestCase(0, null)]
+    public async Task ProcessEventAsync_WithImageLoadEvent_ShouldReturnImageLoadEventData(int signatureLevel, bool? expectedSigned)
+    {
+        // Arrange
+        var payload = new Dictionary<string, object>
+        {
+            { "FileName", @"C:\Program Files\App\plugin.dll" },
+            { "ImageBase", 0x7FF812340000UL },
+            { "ImageSize", 81920 },
+            { "SignatureLevel", signatureLevel }
+        };
+
+        var rawEvent = TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-Kernel-Image",
+            "Image/Load",
+            1234,
+            payload
+        );
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+
+        // Act
+        var result = await _processor.ProcessEventAsync(rawEvent);
+
+        // Assert
+        result.Success.Should().BeTrue();
+        var imageEvent = result.EventData.Should().BeOfType<ImageLoadEventData>().Subject;
+        imageEvent.Operation.Should().Be("Load");
+        imageEvent.ImagePath.Should().Be(@"C:\Program Files\App\plugin.dll");
+        imageEvent.BaseAddress.Should().Be(0x7FF812340000UL);
+        imageEvent.ImageSize.Should().Be(81920);
+        imageEvent.IsSigned.Should().Be(expectedSigned);
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithThreadEventAndTagOptIn_ShouldReturnThreadEventData()
+    {
+        // Arrange
+        var payload = new Dictionary<string, object>
+        {
+            { "Win32StartAddr", 0x7FF6A0001000UL }
+        };
+
+        var rawEvent = TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-Kernel-Thread",
+            "Thread/Start",
+            1234,
+            payload
+        );
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+        _mockWatchTargetManager.Setup(x => x.GetOptionsForTag("test-tag"))
+            .Returns(new WatchTargetOptions { IncludeThreadEvents = true });
+
+        // Act
+        var result = await _processor.ProcessEventAsync(rawEvent);
+
+        // Assert
+        result.Success.Should().BeTrue();
+        var threadEvent = result.EventData.Should().BeOfType<ThreadEventData>().Subject;
+        threadEvent.Operation.Should().Be("Start");
+        threadEvent.StartAddress.Should().Be(0x7FF6A0001000UL);
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithThreadEventWithoutTagOptIn_ShouldFilterOut()
+    {
+        // Arrange
+        var rawEvent = TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-Kernel-Thread",
+            "Thread/End",
+            1234,
+            new Dictionary<string, object>()
+        );
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+        _mockWatchTargetManager.Setup(x => x.GetOptionsForTag("test-tag")).Returns(WatchTargetOptions.Default);
+
+        // Act
+        var result = await _processor.ProcessEventAsync(rawEvent);
+
+        // Assert
+        result.Success.Should().BeFalse();
+        result.ErrorMessage.Should().Be("Event disabled for tag");
+    }
+
+    [TestCase(0, 0)]
+    [TestCase(1, 6)]
+    [TestCase(3, 2)]
+    public async Task ProcessEventAsync_WithFileReadEvents_ShouldRecordOneInSampleRate(int sampleRate, int expectedRecorded)
+    {
+        // Arrange
+        var payload = new Dictionary<string, object>
+        {
+            { "FileName", @"C:\data\input.bin" }
+        };
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+        _mockWatchTargetManager.Setup(x => x.GetOptionsForTag("test-tag"))
+            .Returns(new WatchTargetOptions { FileReadSampleRate = sampleRate });
+
+        // Act
+        var recorded = 0;
+        for (var i = 0; i < 6; i++)
+        {
+            var result = await _processor.ProcessEventAsync(TestEventFactory.CreateRawEvent(
+                "Microsoft-Windows-Kernel-FileIO", "FileIO/Read", 1234, payload));
+            if (result.Success)
+            {
+                recorded++;
+            }
+        }
+
+        // Assert
+        recorded.Should().Be(expectedRecorded);
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithFileEventsOnSameHandle_ShouldShareCorrelationIdUntilClose()
+    {
+        // Arrange
+        var payload = new Dictionary<string, object>
+        {
+            { "FileName", @"C:\temp\session.txt" },
+            { "FileObject", 0xFFFF9A0012345678UL }
+        };
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+
+        async Task<FileEventData> ProcessAsync(string eventName)
+        {
+            var result = await _processor.ProcessEventAsync(TestEventFactory.CreateRawEvent(
+                "Microsoft-Windows-Kernel-FileIO", eventName, 1234, payload));
+            return result.EventData.Should().BeOfType<FileEventData>().Subject;
+        }
+
+        // Act
+        var create = await ProcessAsync("FileIO/Create");
+        var write = await ProcessAsync("FileIO/Write");
+        var close = await ProcessAsync("FileIO/Close");
+        var writeAfterClose = await ProcessAsync("FileIO/Write");
+        var reopen = await ProcessAsync("FileIO/Create");
+
+        // Assert
+        create.CorrelationId.Should().NotBeNull();
+        write.CorrelationId.Should().Be(create.CorrelationId);
+        close.CorrelationId.Should().Be(create.CorrelationId);
+        writeAfterClose.CorrelationId.Should().BeNull();
+        reopen.CorrelationId.Should().NotBeNull().And.NotBe(create.CorrelationId);
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithAtomicSaveOnSeparateHandle_ShouldCarryWriteCorrelationIdThroughRename()
+    {
+        // Arrange
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+
+        async Task<FileEventData> ProcessAsync(string eventName, ulong fileObject)
+        {
+            var payload = new Dictionary<string, object>
+            {
+                { "FileName", @"C:\saves\slot1.sav.tmp" },
+                { "FileObject", fileObject }
+            };
+            var result = await _processor.ProcessEventAsync(TestEventFactory.CreateRawEvent(
+                "Microsoft-Windows-Kernel-FileIO", eventName, 1234, payload));
+            return result.EventData.Should().BeOfType<FileEventData>().Subject;
+        }
+
+        // Act
+        var create = await ProcessAsync("FileIO/Create", 1);
+        await ProcessAsync("FileIO/Write", 1);
+        await ProcessAsync("FileIO/Close", 1);
+        var renameCreate = await ProcessAsync("FileIO/Create", 2);
+        var rename = await ProcessAsync("FileIO/Rename", 2);
+        var renameClose = await ProcessAsync("FileIO/Close", 2);
+        var unrelatedRename = await ProcessAsync("FileIO/Rename", 3);
+
+        // Assert
+        renameCreate.CorrelationId.Should().NotBe(create.CorrelationId);
+        rename.CorrelationId.Should().Be(create.CorrelationId);
+        renameClose.CorrelationId.Should().Be(create.CorrelationId);
+        unrelatedRename.CorrelationId.Should().BeNull();
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithPathBasedRenameAfterWrite_ShouldCarryWriteCorrelationId()
+    {
+        // Arrange
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+
+        async Task<FileEventData> ProcessAsync(string eventName, Dictionary<string, object> payload)
+        {
+            var result = await _processor.ProcessEventAsync(TestEventFactory.CreateRawEvent(
+                "Microsoft-Windows-Kernel-FileIO", eventName, 1234, payload));
+            return result.EventData.Should().BeOfType<FileEventData>().Subject;
+        }
+
+        var handlePayload = new Dictionary<string, object>
+        {
+            { "FileName", "/home/user/.config/app/settings.json.tmp" },
+            { "FileDescriptor", 5 }
+        };
+
+        // Act
+        var create = await ProcessAsync("FileIO/Create", handlePayload);
+        await ProcessAsync("FileIO/Write", handlePayload);
+        await ProcessAsync("FileIO/Close", handlePayload);
+        var rename = await ProcessAsync("FileIO/Rename", new Dictionary<string, object>
+        {
+            { "FileName", "/home/user/.config/app/settings.json.tmp" },
+            { "NewFileName", "/home/user/.config/app/settings.json" }
+        });
+
+        // Assert
+        create.CorrelationId.Should().NotBeNull();
+        rename.CorrelationId.Should().Be(create.CorrelationId);
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithCloseAfterWriteUnderHashOnClosePath_ShouldIncludeContentSha256()
+    {
+        // Arrange
+        const string hash = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae";
+        var mockFileContentHasher = new Mock<IFileContentHasher>();
+        mockFileContentHasher.Setup(x => x.ComputeSha256Async(@"D:\Saves\slot1.sav", It.IsAny<CancellationToken>()))
+            .ReturnsAsync(hash);
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+        _mockWatchTargetManager.Setup(x => x.GetOptionsForTag("test-tag"))
+            .Returns(new WatchTargetOptions { HashOnClosePaths = new[] { @"D:\Saves\**" } });
+
+        var processor = new EventProcessor(_mockLogger.Object, _mockWatchTargetManager.Object,
+            _mockEtwConfiguration.Object, null, null, mockFileContentHasher.Object);
+
+        async Task<FileEventData> ProcessAsync(string eventName, ulong fileObject, string? fileName = null)
+        {
+            var payload = new Dictionary<string, object> { { "FileObject", fileObject } };
+            if (fileName != null)
+            {
+                payload["FileName"] = fileName;
+            }
+
+            var result = await processor.ProcessEventAsync(TestEventFactory.CreateRawEvent(
+                "Microsoft-Windows-Kernel-FileIO", eventName, 1234, payload));
+            return result.EventData.Should().BeOfType<FileEventData>().Subject;
+        }
+
+        // Act
+        await ProcessAsync("FileIO/Create", 1, @"D:\Saves\slot1.sav");
+        await ProcessAsync("FileIO/Write", 1, @"D:\Saves\slot1.sav");
+        var writtenClose = await ProcessAsync("FileIO/Close", 1);
+        await ProcessAsync("FileIO/Create", 2, @"D:\Saves\slot2.sav");
+        var readOnlyClose = await ProcessAsync("FileIO/Close", 2, @"D:\Saves\slot2.sav");
+
+        // Assert
+        writtenClose.FilePath.Should().Be(@"D:\Saves\slot1.sav");
+        writtenClose.ContentSha256.Should().Be(hash);
+        readOnlyClose.ContentSha256.Should().BeNull();
+        mockFileContentHasher.Verify(x => x.ComputeSha256Async(It.IsAny<string>(), It.IsAny<CancellationToken>()), Times.Once);
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithJunctionedPath_ShouldRecordResolvedPathAndMatchFilterOnEither()
+    {
+        // Arrange
+        var mockReparsePointResolver = new Mock<IReparsePointResolver>();
+        mockReparsePointResolver.Setup(x => x.ResolveFinalPath(@"C:\Users\test\Documents\Game\save.dat"))
+            .Returns(@"D:\OneDrive\Documents\Game\save.dat");
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+        _mockWatchTargetManager.Setup(x => x.GetOptionsForTag("test-tag"))
+            .Returns(new WatchTargetOptions { IncludePaths = new[] { @"D:\OneDrive\**" } });
+
+        var processor = new EventProcessor(_mockLogger.Object, _mockWatchTargetManager.Object,
+            _mockEtwConfiguration.Object, null, null, reparsePointResolver: mockReparsePointResolver.Object);
+
+        var rawEvent = TestEventFactory.CreateRawEvent("Microsoft-Windows-Kernel-FileIO", "FileIO/Write", 1234,
+            new Dictionary<string, object> { { "FileName", @"C:\Users\test\Documents\Game\save.dat" } });
+
+        // Act
+        var result = await processor.ProcessEventAsync(rawEvent);
+
+        // Assert
+        result.Success.Should().BeTrue();
+        var fileEvent = result.EventData.Should().BeOfType<FileEventData>().Subject;
+        fileEvent.FilePath.Should().Be(@"D:\OneDrive\Documents\Game\save.dat");
+        fileEvent.RawFilePath.Should().Be(@"C:\Users\test\Documents\Game\save.dat");
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithProcessStartAndEnvironmentAllowList_ShouldCaptureEnvironment()
+    {
+        // Arrange
+        var payload = new Dictionary<string, object>
+        {
+            { "ProcessId", 5678 },
+            { "ProcessName", "game.exe" }
+        };
+
+        var allowList = new[] { "LAUNCHER_SESSION_ID" };
+        var mockEnvironmentReader = new Mock<IProcessEnvironmentReader>();
+        mockEnvironmentReader.Setup(x => x.ReadEnvironment(5678, allowList))
+            .Returns(new Dictionary<string, string> { { "LAUNCHER_SESSION_ID", "abc-123" } });
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+        _mockWatchTargetManager.Setup(x => x.GetOptionsForTag("test-tag"))
+            .Returns(new WatchTargetOptions { EnvironmentVariables = allowList });
+
+        var processor = new EventProcessor(_mockLogger.Object, _mockWatchTargetManager.Object,
+            _mockEtwConfiguration.Object, mockEnvironmentReader.Object);
+
+        // Act
+        var result = await processor.ProcessEventAsync(TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-Kernel-Process", "Process/Start", 1234, payload));
+
+        // Assert
+        var processEvent = result.EventData.Should().BeOfType<ProcessStartEventData>().Subject;
+        processEvent.Environment.Should().ContainKey("LAUNCHER_SESSION_ID")
+            .WhoseValue.Should().Be("abc-123");
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithProcessStart_ShouldIncludeTokenFromPayloadAndIntegrityFromReader()
+    {
+        // Arrange
+        var payload = new Dictionary<string, object>
+        {
+            { "ProcessId", 5678 },
+            { "ProcessName", "updater.exe" },
+            { "UserSID", "S-1-5-21-1111111111-2222222222-3333333333-1001" },
+            { "SessionID", 1 }
+        };
+
+        var mockTokenReader = new Mock<IProcessTokenReader>();
+        mockTokenReader.Setup(x => x.ReadToken(5678))
+            .Returns(new ProcessTokenInfo("S-1-5-18", 0, "High"));
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+
+        var processor = new EventProcessor(_mockLogger.Object, _mockWatchTargetManager.Object,
+            _mockEtwConfiguration.Object, null, null, tokenReader: mockTokenReader.Object);
+
+        // Act
+        var result = await processor.ProcessEventAsync(TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-Kernel-Process", "Process/Start", 1234, payload));
+
+        // Assert
+        var processEvent = result.EventData.Should().BeOfType<ProcessStartEventData>().Subject;
+        processEvent.UserSid.Should().Be("S-1-5-21-1111111111-2222222222-3333333333-1001");
+        processEvent.SessionId.Should().Be(1);
+        processEvent.IntegrityLevel.Should().Be("High");
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithProcessStartAndImageHashProvider_ShouldIncludeImageSha256()
+    {
+        // Arrange
+        var payload = new Dictionary<string, object>
+        {
+            { "ProcessId", 5678 },
+            { "ProcessName", "game.exe" }
+        };
+
+        const string hash = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08";
+        var mockImageHashProvider = new Mock<IImageHashProvider>();
+        mockImageHashProvider.Setup(x => x.GetImageSha256Async(5678, It.IsAny<CancellationToken>()))
+            .ReturnsAsync(hash);
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+
+        var processor = new EventProcessor(_mockLogger.Object, _mockWatchTargetManager.Object,
+            _mockEtwConfiguration.Object, null, mockImageHashProvider.Object);
+
+        // Act
+        var result = await processor.ProcessEventAsync(TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-Kernel-Process", "Process/Start", 1234, payload));
+
+        // Assert
+        var processEvent = result.EventData.Should().BeOfType<ProcessStartEventData>().Subject;
+        processEvent.ImageSha256.Should().Be(hash);
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithInstallerStart_ShouldAppendUpdateDetectedEvent()
+    {
+        // Arrange
+        var payload = new Dictionary<string, object>
+        {
+            { "ProcessId", 5678 },
+            { "ImageName", @"C:\Users\player\Downloads\Game-Setup.exe" }
+        };
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+
+        // Act
+        var result = await _processor.ProcessEventAsync(TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-Kernel-Process", "Process/Start", 1234, payload));
+
+        // Assert
+        result.Events.Should().HaveCount(2);
+        result.Events[0].Should().BeOfType<ProcessStartEventData>();
+        var update = result.Events[1].Should().BeOfType<UpdateDetectedEventData>().Subject;
+        update.EventName.Should().Be(EventProcessor.UpdateDetectedEventName);
+        update.TagName.Should().Be("test-tag");
+        update.Trigger.Should().Be(UpdateTrigger.InstallerStarted);
+        update.ExecutablePath.Should().Be(@"C:\Users\player\Downloads\Game-Setup.exe");
+        update.InstallerProcessId.Should().Be(5678);
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithCloseAfterWriteToProgramFilesExecutable_ShouldAppendUpdateDetectedEventWithHash()
+    {
+        // Arrange
+        const string executablePath = @"C:\Program Files (x86)\Game\game.exe";
+        const string hash = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae";
+        var mockFileContentHasher = new Mock<IFileContentHasher>();
+        mockFileContentHasher.Setup(x => x.ComputeSha256Async(executablePath, It.IsAny<CancellationToken>()))
+            .ReturnsAsync(hash);
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
+
+        var processor = new EventProcessor(_mockLogger.Object, _mockWatchTargetManager.Object,
+            _mockEtwConfiguration.Object, null, null, mockFileContentHasher.Object);
+
+        Task<ProcessingResult> ProcessAsync(string eventName, string fileName)
+        {
+            var payload = new Dictionary<string, object> { { "FileObject", 1UL }, { "FileName", fileName } };
+            return processor.ProcessEventAsync(TestEventFactory.CreateRawEvent(
+                "Microsoft-Windows-Kernel-FileIO", eventName, 1234, payload));
+        }
+
+        // Act
+        var write = await ProcessAsync("FileIO/Write", executablePath);
+        var close = await ProcessAsync("FileIO/Close", executablePath);
+        var secondClose = await ProcessAsync("FileIO/Close", executablePath);
+
+        // Assert（書き込み時点ではなくクローズ時に1回だけ検出する）
+        write.Events.Should().ContainSingle();
+        close.Events.Should().HaveCount(2);
+        var update = close.Events[1].Should().BeOfType<UpdateDetectedEventData>().Subject;
+        update.Trigger.Should().Be(UpdateTrigger.ExecutableWritten);
+        update.ExecutablePath.Should().Be(executablePath);
+        update.ExecutableSha256.Should().Be(hash);
+        secondClose.Events.Should().ContainSingle();
+    }
+
     [Test]
     public async Task ProcessEventAsync_WithUnknownProvider_ShouldReturnGenericEventData()
     {
@@ -362,7 +1168,7 @@ public class EventProcessorTests
         var testProcessor = new EventProcessor(_mockLogger.Object, _mockWatchTargetManager.Object, mockEtwConfigForTest.Object);
 
         _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
-        _mockWatchTargetManager.Setup(x => x.GetTagForProcess(1234)).Returns("test-tag");
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
 
         // Act
         var result = await testProcessor.ProcessEventAsync(rawEvent);
@@ -397,7 +1203,7 @@ public class EventProcessorTests
         );
 
         _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
-        _mockWatchTargetManager.Setup(x => x.GetTagForProcess(1234)).Returns("test-tag");
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
 
         // Act
         var result = await _processor.ProcessEventAsync(rawEvent);
@@ -426,7 +1232,7 @@ public class EventProcessorTests
         );
 
         _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
-        _mockWatchTargetManager.Setup(x => x.GetTagForProcess(1234)).Returns("test-tag");
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "test-tag" });
 
         // Act
         var result = await _processor.ProcessEventAsync(rawEvent);
@@ -436,4 +1242,189 @@ public class EventProcessorTests
         result.Success.Should().BeFalse();
         result.ErrorMessage.Should().Contain("Failed to convert");
     }
-}
\ No newline at end of file
+
+    [Test]
+    public async Task ProcessEventAsync_WithExecEventOfUnwatchedProcess_ShouldMatchAgainstWatchedDirectories()
+    {
+        // Arrange
+        var rawEvent = TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-Kernel-Process",
+            "Process/Exec",
+            4321,
+            new Dictionary<string, object>
+            {
+                { "ProcessId", 4321 },
+                { "ImageFileName", "game" },
+                { "FileName", "/opt/games/my-game/bin/game" }
+            }
+        );
+
+        _mockWatchTargetManager.Setup(x => x.AddProcessByPathAsync(4321, "/opt/games/my-game/bin/game")).ReturnsAsync(true);
+
+        // Act
+        await _processor.ProcessEventAsync(rawEvent);
+
+        // Assert
+        _mockWatchTargetManager.Verify(x => x.AddProcessByPathAsync(4321, "/opt/games/my-game/bin/game"), Times.Once);
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithExcludedProcess_ShouldNotRecordEvent()
+    {
+        // Arrange
+        var rawEvent = TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-Kernel-FileIO",
+            "FileIO/Create",
+            1234,
+            new Dictionary<string, object> { { "FileName", @"C:\Temp\crash.dmp" } }
+        );
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "game" });
+        _mockWatchTargetManager.Setup(x => x.IsExcludedProcess(1234, "game")).Returns(true);
+
+        // Act
+        var result = await _processor.ProcessEventAsync(rawEvent);
+
+        // Assert
+        result.Success.Should().BeFalse();
+        result.ErrorMessage.Should().Be("Process excluded for tag");
+        _processor.GetFilterStatistics()["game"].Should().Be(new TagFilterStatistics(ExcludedProcessCount: 1, PathFilteredCount: 0, RateLimitedCount: 0));
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithProcessInMultipleTags_ShouldReturnEventPerTagWithMetadata()
+    {
+        // Arrange
+        var rawEvent = TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-Kernel-FileIO",
+            "FileIO/Write",
+            1234,
+            new Dictionary<string, object> { { "FileName", @"C:\Games\MyGame\save.dat" } }
+        );
+
+        var metadata = new Dictionary<string, string> { ["gameId"] = "1234" };
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "launcher", "game", "logs" });
+        _mockWatchTargetManager.Setup(x => x.GetOptionsForTag("game")).Returns(new WatchTargetOptions { Metadata = metadata });
+        _mockWatchTargetManager.Setup(x => x.GetOptionsForTag("logs")).Returns(new WatchTargetOptions { IncludePaths = new[] { "**/*.log" } });
+
+        // Act
+        var result = await _processor.ProcessEventAsync(rawEvent);
+
+        // Assert
+        result.Success.Should().BeTrue();
+        result.EventData!.TagName.Should().Be("launcher");
+        result.Events.Select(e => e.TagName).Should().Equal("launcher", "game");
+        result.Events[0].TagMetadata.Should().BeNull();
+        result.Events[1].TagMetadata.Should().BeEquivalentTo(metadata);
+        result.Events.Cast<FileEventData>().Select(e => e.FilePath).Should().OnlyContain(path => path == @"C:\Games\MyGame\save.dat");
+        _processor.GetFilterStatistics()["logs"].PathFilteredCount.Should().Be(1);
+    }
+
+    [TestCase(@"C:\Users\me\AppData\Roaming\MyGame\settings.json", true)]
+    [TestCase(@"C:\Users\me\AppData\Roaming\MyGame\cache.tmp", false)]
+    [TestCase(@"C:\Windows\Temp\log.txt", false)]
+    public async Task ProcessEventAsync_WithPathFilters_ShouldRecordOnlyIncludedPaths(string filePath, bool expectedRecorded)
+    {
+        // Arrange
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "game" });
+        _mockWatchTargetManager.Setup(x => x.GetOptionsForTag("game")).Returns(new WatchTargetOptions
+        {
+            IncludePaths = new[] { @"C:\Users\me\AppData\Roaming\MyGame\**" },
+            ExcludePaths = new[] { @"regex:\.tmp$" }
+        });
+
+        var rawEvent = TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-Kernel-FileIO",
+            "FileIO/Write",
+            1234,
+            new Dictionary<string, object> { { "FileName", filePath } }
+        );
+
+        // Act
+        var result = await _processor.ProcessEventAsync(rawEvent);
+
+        // Assert
+        result.Success.Should().Be(expectedRecorded);
+        if (!expectedRecorded)
+        {
+            result.ErrorMessage.Should().Be("Event filtered by path");
+        }
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_WithRecordRoots_ShouldCountButNotRecordEventsOutsideRoots()
+    {
+        // Arrange
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "game" });
+        _mockWatchTargetManager.Setup(x => x.GetOptionsForTag("game")).Returns(new WatchTargetOptions
+        {
+            RecordRoots = new[] { @"C:\Users\me\Saved Games\MyGame\" }
+        });
+
+        RawEventData CreateWrite(string filePath, long ioSize) => TestEventFactory.CreateRawEvent(
+            "Microsoft-Windows-Kernel-FileIO",
+            "FileIO/Write",
+            1234,
+            new Dictionary<string, object> { { "FileName", filePath }, { "IoSize", ioSize } }
+        );
+
+        // Act
+        var inside = await _processor.ProcessEventAsync(CreateWrite(@"C:\Users\me\Saved Games\MyGame\slot1.sav", 4096));
+        var outside = await _processor.ProcessEventAsync(CreateWrite(@"C:\Games\MyGame\shaders.bin", 1024));
+        var sibling = await _processor.ProcessEventAsync(CreateWrite(@"C:\Users\me\Saved Games\MyGame2\slot1.sav", 512));
+
+        // Assert
+        inside.Success.Should().BeTrue();
+        outside.Success.Should().BeFalse();
+        outside.ErrorMessage.Should().Be("Event outside record roots");
+        sibling.Success.Should().BeFalse();
+        _processor.GetFilterStatistics()["game"].Should().BeEquivalentTo(new
+        {
+            OutsideRootCount = 2L,
+            OutsideRootBytesWritten = 1536L,
+            PathFilteredCount = 0L
+        });
+    }
+
+    [Test]
+    public async Task ProcessEventAsync_OverTagRateLimit_ShouldSampleDeterministicallyAndKeepDeletes()
+    {
+        // Arrange
+        _mockEtwConfiguration.Setup(x => x.OverflowSampleRate).Returns(3);
+        var processor = new EventProcessor(_mockLogger.Object, _mockWatchTargetManager.Object, _mockEtwConfiguration.Object);
+
+        _mockWatchTargetManager.Setup(x => x.IsWatchedProcess(1234)).Returns(true);
+        _mockWatchTargetManager.Setup(x => x.GetTagsForProcess(1234)).Returns(new[] { "noisy" });
+        _mockWatchTargetManager.Setup(x => x.GetOptionsForTag("noisy")).Returns(new WatchTargetOptions { MaxEventsPerSecond = 2 });
+
+        var timestamp = new DateTime(2024, 1, 1, 12, 0, 0, DateTimeKind.Utc);
+        RawEventData CreateFileEvent(string eventName) => new(
+            timestamp,
+            "Microsoft-Windows-Kernel-FileIO",
+            eventName,
+            1234,
+            5678,
+            Guid.Empty,
+            Guid.Empty,
+            new Dictionary<string, object> { { "FileName", @"C:\logs\app.log" } });
+
+        // Act
+        var recordedWrites = 0;
+        for (var i = 0; i < 10; i++)
+        {
+            if ((await processor.ProcessEventAsync(CreateFileEvent("FileIO/Write"))).Success)
+            {
+                recordedWrites++;
+            }
+        }
+        var deleteResult = await processor.ProcessEventAsync(CreateFileEvent("FileIO/Delete"));
+
+        // Assert - 上限の2件 + 超過8件のうち3件に1件 (3件)
+        recordedWrites.Should().Be(5);
+        deleteResult.Success.Should().BeTrue();
+    }
+}
@@ -0,0 +1,140 @@
+using FluentAssertions;
+using NUnit.Framework;
+using ProcTail.Application.Services;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Tests.Services;
+
+[TestFixture]
+[Category("Unit")]
+public class EventQueryTests
+{
+    private static readonly DateTime Now = new(2025, 1, 1, 12, 0, 0, DateTimeKind.Utc);
+
+    [Test]
+    public void IsMatch_WithTypeOperationPathAndRelativeTime_ShouldRequireAllConditions()
+    {
+        // Arrange
+        var query = EventQuery.Parse("type=file.write AND path~\"*.sav\" AND ts>now-1h");
+
+        // Act & Assert
+        query.IsMatch(CreateFileEvent(@"C:\Saves\slot1.sav"), Now).Should().BeTrue();
+        query.IsMatch(CreateFileEvent(@"C:\Saves\slot1.sav", eventName: "FileIO/Read"), Now).Should().BeFalse();
+        query.IsMatch(CreateFileEvent(@"C:\Saves\output.log"), Now).Should().BeFalse();
+        query.IsMatch(CreateFileEvent(@"C:\Saves\slot1.sav", timestamp: Now.AddHours(-2)), Now).Should().BeFalse();
+    }
+
+    [Test]
+    public void IsMatch_WithOrNotAndParentheses_ShouldFollowOperatorPrecedence()
+    {
+        // Arrange
+        var query = EventQuery.Parse("NOT pid=1 AND (type=process_start OR path~*.sav)");
+
+        // Act & Assert
+        query.IsMatch(CreateProcessStartEvent(), Now).Should().BeTrue();
+        query.IsMatch(CreateFileEvent(@"C:\Saves\slot1.sav"), Now).Should().BeTrue();
+        query.IsMatch(CreateFileEvent(@"C:\Saves\slot1.sav", processId: 1), Now).Should().BeFalse();
+        query.IsMatch(CreateFileEvent(@"C:\Saves\output.log"), Now).Should().BeFalse();
+    }
+
+    [Test]
+    public void IsMatch_WithEventPropertyAndPayloadKey_ShouldCompareValues()
+    {
+        // Act & Assert
+        EventQuery.Parse("ChildProcessName=CRASHPAD.exe").IsMatch(CreateProcessStartEvent(), Now).Should().BeTrue();
+        EventQuery.Parse("CommandLine~\"*--database*\"").IsMatch(CreateProcessStartEvent(), Now).Should().BeTrue();
+        EventQuery.Parse("payload.IoSize>=4096").IsMatch(CreateFileEvent(@"C:\Saves\slot1.sav"), Now).Should().BeTrue();
+        EventQuery.Parse("IoSize<100").IsMatch(CreateFileEvent(@"C:\Saves\slot1.sav"), Now).Should().BeFalse();
+        EventQuery.Parse("IoSize=1").IsMatch(CreateProcessStartEvent(), Now).Should().BeFalse();
+    }
+
+    [Test]
+    public void IsMatch_WithQuotedWindowsPathAndAbsoluteTime_ShouldMatch()
+    {
+        // Arrange
+        var query = EventQuery.Parse("path=\"C:\\Saves\\slot1.sav\" AND ts<\"2025-01-01T12:00:00Z\"");
+
+        // Act & Assert
+        query.IsMatch(CreateFileEvent(@"C:\Saves\slot1.sav"), Now).Should().BeTrue();
+        query.IsMatch(CreateFileEvent(@"C:\Saves\slot1.sav", timestamp: Now), Now).Should().BeFalse();
+    }
+
+    [TestCase("type=file AND", "Expected a field name")]
+    [TestCase("(pid=1", "Expected ')'")]
+    [TestCase("pid=1 extra", "Unexpected 'e'")]
+    [TestCase("type>file", "'type' supports only")]
+    [TestCase("ts>yesterday", "is not a time")]
+    [TestCase("path~\"regex:(\"", "Invalid path pattern")]
+    [TestCase("name=\"FileIO/Write", "Unterminated string")]
+    [TestCase("ts>now-99999999999d", "is out of range")]
+    [TestCase("ts>now-3000000d", "is out of range")]
+    public void TryParse_WithInvalidExpression_ShouldReturnErrorWithPosition(string expression, string expectedError)
+    {
+        // Act
+        var parsed = EventQuery.TryParse(expression, out var query, out var error);
+
+        // Assert
+        parsed.Should().BeFalse();
+        query.Should().BeNull();
+        error.Should().Contain(expectedError).And.Contain("at position");
+    }
+
+    [Test]
+    public void EventFilterMatcher_WithQuery_ShouldCombineWithOtherConditions()
+    {
+        // Arrange
+        var filter = new EventFilter { ProcessIds = new() { 1234 }, Query = "path~*.sav" };
+
+        // Act & Assert
+        EventFilterMatcher.IsMatch(filter, CreateFileEvent(@"C:\Saves\slot1.sav")).Should().BeTrue();
+        EventFilterMatcher.IsMatch(filter, CreateFileEvent(@"C:\Saves\slot1.sav", processId: 1)).Should().BeFalse();
+        EventFilterMatcher.Validate(filter with { Query = "path~" }).Should().StartWith("Invalid query:");
+    }
+
+    [Test]
+    public void Parse_WithLargestTimeOffset_ShouldEvaluateWithoutOverflow()
+    {
+        // Arrange
+        var query = EventQuery.Parse("ts>now-36500d AND ts<now+876000h");
+
+        // Act & Assert
+        query.IsMatch(CreateFileEvent(@"C:\Saves\slot1.sav"), Now).Should().BeTrue();
+        EventFilterMatcher.Validate(new EventFilter { Query = "ts>now-3000000d" }).Should().StartWith("Invalid query:");
+    }
+
+    private static FileEventData CreateFileEvent(string filePath, string eventName = "FileIO/Write", int processId = 1234, DateTime? timestamp = null)
+    {
+        return new FileEventData
+        {
+            Timestamp = timestamp ?? Now.AddMinutes(-10),
+            TagName = "test-tag",
+            ProcessId = processId,
+            ThreadId = 1,
+            ProviderName = "Microsoft-Windows-Kernel-FileIO",
+            EventName = eventName,
+            ActivityId = Guid.Empty,
+            RelatedActivityId = Guid.Empty,
+            Payload = new Dictionary<string, object> { ["IoSize"] = 4096L },
+            FilePath = filePath
+        };
+    }
+
+    private static ProcessStartEventData CreateProcessStartEvent()
+    {
+        return new ProcessStartEventData
+        {
+            Timestamp = Now.AddMinutes(-10),
+            TagName = "test-tag",
+            ProcessId = 1234,
+            ThreadId = 1,
+            ProviderName = "Microsoft-Windows-Kernel-Process",
+            EventName = "Process/Start",
+            ActivityId = Guid.Empty,
+            RelatedActivityId = Guid.Empty,
+            Payload = new Dictionary<string, object>(),
+            ChildProcessId = 5678,
+            ChildProcessName = "crashpad.exe",
+            CommandLine = @"C:\Game\crashpad.exe --database C:\Game\dumps"
+        };
+    }
+}
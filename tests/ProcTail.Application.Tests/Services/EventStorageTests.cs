@@ -59,7 +59,7 @@ public class EventStorageTests
         // Assert
         var events = await _storage.GetEventsAsync(tagName);
         events.Should().HaveCount(1);
-        events[0].Should().Be(eventData);
+        events[0].Should().Be(eventData with { SequenceNumber = 1 });
 
         var count = await _storage.GetEventCountAsync(tagName);
         count.Should().Be(1);
@@ -85,7 +85,7 @@ public class EventStorageTests
         // FIFOなので順序が保持される
         for (int i = 0; i < events.Count; i++)
         {
-            storedEvents[i].Should().Be(events[i]);
+            storedEvents[i].Should().Be(events[i] with { SequenceNumber = i + 1 });
         }
     }
 
@@ -151,6 +151,112 @@ public class EventStorageTests
         count.Should().Be(maxEvents);
     }
 
+    [Test]
+    public async Task StoreEventAsync_WithDropNewestPolicy_ShouldKeepOldEventsAndCountDrops()
+    {
+        // Arrange
+        const string tagName = "test-tag";
+        using var limitedStorage = new EventStorage(_mockLogger.Object, 3);
+        limitedStorage.SetBackpressurePolicy(tagName, BackpressurePolicy.DropNewest, TimeSpan.Zero);
+
+        // Act
+        for (int i = 0; i < 5; i++)
+        {
+            await limitedStorage.StoreEventAsync(tagName, TestEventFactory.CreateFileEvent($@"C:\file{i}.txt", tagName, 1000 + i));
+        }
+
+        // Assert
+        var events = await limitedStorage.GetEventsAsync(tagName);
+        events.Select(e => e.ProcessId).Should().Equal(1000, 1001, 1002);
+
+        var statistics = await limitedStorage.GetStatisticsAsync();
+        statistics.BufferStatisticsByTag![tagName].Should().Be(new TagBufferStatistics(BackpressurePolicy.DropNewest, 2));
+    }
+
+    [Test]
+    public async Task StoreEventAsync_ShouldReturnStoredEventOrNullWhenDropped()
+    {
+        // Arrange
+        const string tagName = "test-tag";
+        using var limitedStorage = new EventStorage(_mockLogger.Object, 1);
+        limitedStorage.SetBackpressurePolicy(tagName, BackpressurePolicy.DropNewest, TimeSpan.Zero);
+
+        // Act
+        var stored = await limitedStorage.StoreEventAsync(tagName, TestEventFactory.CreateFileEvent(tagName: tagName));
+        var dropped = await limitedStorage.StoreEventAsync(tagName, TestEventFactory.CreateFileEvent(tagName: tagName));
+
+        // Assert
+        stored.Should().NotBeNull();
+        stored!.SequenceNumber.Should().Be(1);
+        dropped.Should().BeNull();
+        (await limitedStorage.GetEventsAsync(tagName)).Single().Should().Be(stored);
+    }
+
+    [Test]
+    public async Task StoreEventAsync_WithDropNewestPolicyAndConcurrentEvents_ShouldNotDropOldEvents()
+    {
+        // Arrange
+        const string tagName = "test-tag";
+        using var limitedStorage = new EventStorage(_mockLogger.Object, 10);
+        limitedStorage.SetBackpressurePolicy(tagName, BackpressurePolicy.DropNewest, TimeSpan.Zero);
+
+        // Act
+        await Task.WhenAll(Enumerable.Range(0, 200).Select(i => Task.Run(() =>
+            limitedStorage.StoreEventAsync(tagName, TestEventFactory.CreateFileEvent($@"C:\file{i}.txt", tagName, 1000 + i)))));
+
+        // Assert - 最初に採番された10件が残り、上限を超えて古いイベントが削除されない
+        var events = await limitedStorage.GetEventsAsync(tagName);
+        events.Select(e => e.SequenceNumber).Should().Equal(Enumerable.Range(1, 10).Select(i => (long)i));
+
+        var statistics = await limitedStorage.GetStatisticsAsync();
+        statistics.BufferStatisticsByTag![tagName].Should().Be(new TagBufferStatistics(BackpressurePolicy.DropNewest, 190));
+    }
+
+    [Test]
+    public async Task StoreEventAsync_WithDroppedEvents_ShouldLeaveSequenceGap()
+    {
+        // Arrange
+        const string tagName = "test-tag";
+        using var limitedStorage = new EventStorage(_mockLogger.Object, 3);
+        limitedStorage.SetBackpressurePolicy(tagName, BackpressurePolicy.DropNewest, TimeSpan.Zero);
+        for (int i = 0; i < 5; i++)
+        {
+            await limitedStorage.StoreEventAsync(tagName, TestEventFactory.CreateFileEvent($@"C:\file{i}.txt", tagName, 1000 + i));
+        }
+        var before = await limitedStorage.GetEventsAsync(tagName);
+
+        // Act - 破棄後にクリアして記録を再開
+        await limitedStorage.ClearEventsAsync(tagName);
+        await limitedStorage.StoreEventAsync(tagName, TestEventFactory.CreateFileEvent(tagName: tagName));
+
+        // Assert - 破棄された4, 5は欠番になり、クリア後も番号は戻らない
+        var after = await limitedStorage.GetEventsAsync(tagName);
+        before.Select(e => e.SequenceNumber).Should().Equal(1, 2, 3);
+        after.Single().SequenceNumber.Should().Be(6);
+        SequenceGap.Find(after, previousSequenceNumber: 3).Should().Equal(new SequenceGap(4, 5));
+    }
+
+    [Test]
+    public async Task StoreEventAsync_WithBlockPolicy_ShouldStoreAfterEventsAreCleared()
+    {
+        // Arrange
+        const string tagName = "test-tag";
+        using var limitedStorage = new EventStorage(_mockLogger.Object, 1);
+        limitedStorage.SetBackpressurePolicy(tagName, BackpressurePolicy.Block, TimeSpan.FromSeconds(5));
+        await limitedStorage.StoreEventAsync(tagName, TestEventFactory.CreateFileEvent(@"C:\file0.txt", tagName, 1000));
+
+        // Act - 満杯のため待機中にクリア
+        var storeTask = limitedStorage.StoreEventAsync(tagName, TestEventFactory.CreateFileEvent(@"C:\file1.txt", tagName, 1001));
+        await Task.Delay(100);
+        storeTask.IsCompleted.Should().BeFalse();
+        await limitedStorage.ClearEventsAsync(tagName);
+        await storeTask;
+
+        // Assert
+        var events = await limitedStorage.GetEventsAsync(tagName);
+        events.Should().ContainSingle().Which.ProcessId.Should().Be(1001);
+    }
+
     [Test]
     public async Task GetEventsAsync_WithNonExistentTag_ShouldReturnEmpty()
     {
@@ -0,0 +1,143 @@
+using FluentAssertions;
+using NUnit.Framework;
+using ProcTail.Application.Services;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Tests.Services;
+
+[TestFixture]
+[Category("Unit")]
+public class EventSubscriptionHubTests
+{
+    private EventSubscriptionHub _hub = null!;
+
+    [SetUp]
+    public void SetUp()
+    {
+        _hub = new EventSubscriptionHub();
+    }
+
+    [Test]
+    public void Publish_WithSubscribedTag_ShouldDeliverOnlyMatchingEvents()
+    {
+        // Arrange
+        using var subscription = _hub.Subscribe(new[] { "game" });
+
+        // Act
+        _hub.Publish(CreateFileEvent("game"));
+        _hub.Publish(CreateFileEvent("other"));
+
+        // Assert
+        subscription.Reader.TryRead(out var delivered).Should().BeTrue();
+        delivered!.TagName.Should().Be("game");
+        subscription.Reader.TryRead(out _).Should().BeFalse();
+    }
+
+    [Test]
+    public void Publish_WithEmptyTagNames_ShouldDeliverAllEvents()
+    {
+        // Arrange
+        using var subscription = _hub.Subscribe(Array.Empty<string>());
+
+        // Act
+        _hub.Publish(CreateFileEvent("game"));
+        _hub.Publish(CreateFileEvent("other"));
+
+        // Assert
+        subscription.Reader.Count.Should().Be(2);
+    }
+
+    [Test]
+    public void Publish_WithFilter_ShouldDeliverOnlyEventsMatchingFilter()
+    {
+        // Arrange
+        using var subscription = _hub.Subscribe(new[] { "game" }, new EventFilter { ProcessIds = new() { 42 } });
+
+        // Act
+        _hub.Publish(CreateFileEvent("game", processId: 1));
+        _hub.Publish(CreateFileEvent("game", processId: 42));
+
+        // Assert
+        subscription.Reader.TryRead(out var delivered).Should().BeTrue();
+        delivered!.ProcessId.Should().Be(42);
+        subscription.Reader.TryRead(out _).Should().BeFalse();
+    }
+
+    [Test]
+    public void Subscribe_WithInvalidFilterPattern_ShouldThrow()
+    {
+        // Act
+        var act = () => _hub.Subscribe(new[] { "game" }, new EventFilter { PathPatterns = new() { "regex:(" } });
+
+        // Assert
+        act.Should().Throw<ArgumentException>();
+        _hub.SubscriptionCount.Should().Be(0);
+    }
+
+    [Test]
+    public void Publish_WhenBufferIsFull_ShouldDropOldestAndCountDropped()
+    {
+        // Arrange
+        using var subscription = _hub.Subscribe(new[] { "game" }, capacity: 2);
+
+        // Act
+        for (var i = 1; i <= 5; i++)
+        {
+            _hub.Publish(CreateFileEvent("game", processId: i));
+        }
+
+        // Assert
+        subscription.TakeDroppedCount().Should().Be(3);
+        subscription.TakeDroppedCount().Should().Be(0);
+        subscription.Reader.TryRead(out var first).Should().BeTrue();
+        first!.ProcessId.Should().Be(4);
+    }
+
+    [Test]
+    public void Dispose_ShouldStopDeliveryAndCompleteReader()
+    {
+        // Arrange
+        var subscription = _hub.Subscribe(new[] { "game" });
+
+        // Act
+        subscription.Dispose();
+        _hub.Publish(CreateFileEvent("game"));
+
+        // Assert
+        _hub.SubscriptionCount.Should().Be(0);
+        subscription.Reader.Completion.IsCompleted.Should().BeTrue();
+    }
+
+    [Test]
+    public void CompleteAll_ShouldEndAllSubscriptions()
+    {
+        // Arrange
+        var first = _hub.Subscribe(new[] { "game" });
+        var second = _hub.Subscribe(Array.Empty<string>());
+
+        // Act
+        _hub.CompleteAll();
+
+        // Assert
+        _hub.SubscriptionCount.Should().Be(0);
+        first.Reader.Completion.IsCompleted.Should().BeTrue();
+        second.Reader.Completion.IsCompleted.Should().BeTrue();
+    }
+
+    private static FileEventData CreateFileEvent(string tagName, int processId = 1234)
+    {
+        return new FileEventData
+        {
+            Timestamp = DateTime.UtcNow,
+            TagName = tagName,
+            ProcessId = processId,
+            ThreadId = 1,
+            ProviderName = "Microsoft-Windows-Kernel-FileIO",
+            EventName = "FileIO/Write",
+            ActivityId = Guid.Empty,
+            RelatedActivityId = Guid.Empty,
+            Payload = new Dictionary<string, object>(),
+            FilePath = @"C:\game\output.log"
+        };
+    }
+}
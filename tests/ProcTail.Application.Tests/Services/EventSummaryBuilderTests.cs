@@ -0,0 +1,116 @@
+using FluentAssertions;
+using NUnit.Framework;
+using ProcTail.Application.Services;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Tests.Services;
+
+[TestFixture]
+[Category("Unit")]
+public class EventSummaryBuilderTests
+{
+    private static readonly DateTime BaseTime = new(2024, 1, 1, 0, 0, 0, DateTimeKind.Utc);
+
+    [Test]
+    public void Build_ShouldAggregateFilesByDirectoryAndChildProcesses()
+    {
+        // Arrange
+        var events = new List<BaseEventData>
+        {
+            CreateFileEvent("FileIO/Create", @"C:\Game\Saves\slot1.sav", BaseTime),
+            CreateFileEvent("FileIO/Write", @"C:\Game\Saves\slot1.sav", BaseTime.AddSeconds(1), 4096),
+            CreateFileEvent("FileIO/Write", @"C:\Game\Saves\slot2.sav", BaseTime.AddSeconds(2), 1024),
+            // 集約された書き込みは集約前の件数と合計バイト数で数える
+            CreateFileEvent("FileIO/Write", @"C:\Game\output.log", BaseTime.AddSeconds(30)) with
+            {
+                FirstTimestamp = BaseTime.AddSeconds(3),
+                CoalescedCount = 10,
+                TotalBytes = 500
+            },
+            CreateFileEvent("FileIO/Read", @"C:\Game\data.pak", BaseTime.AddSeconds(4), 65536),
+            CreateFileEvent("FileIO/Close", "", BaseTime.AddSeconds(5)),
+            CreateProcessStart("CrashReporter.exe", 2000, BaseTime.AddSeconds(10)),
+            CreateProcessStart("helper.exe", 2001, BaseTime.AddSeconds(11)),
+            CreateProcessStart("helper.exe", 2002, BaseTime.AddSeconds(60))
+        };
+
+        // Act
+        var summary = EventSummaryBuilder.Build(events, 2);
+
+        // Assert
+        summary.Should().BeEquivalentTo(new
+        {
+            EventCount = 18,
+            FirstEventAt = BaseTime,
+            LastEventAt = BaseTime.AddSeconds(60),
+            SessionDurationMs = 60000L,
+            FileCount = 4,
+            BytesWritten = 4096L + 1024 + 500,
+            ChildProcessCount = 3
+        });
+        summary.Directories.Should().BeEquivalentTo(new[]
+        {
+            new DirectorySummary(@"C:\Game", new List<string> { "data.pak", "output.log" }, 11, 500),
+            new DirectorySummary(@"C:\Game\Saves", new List<string> { "slot1.sav", "slot2.sav" }, 3, 5120)
+        }, options => options.WithStrictOrdering());
+        summary.NoisyPaths.Select(path => (path.FilePath, path.EventCount)).Should().Equal(
+            (@"C:\Game\output.log", 10),
+            (@"C:\Game\Saves\slot1.sav", 2));
+        summary.ChildProcesses.Should().Equal(
+            new ChildProcessSummary("helper.exe", 2),
+            new ChildProcessSummary("CrashReporter.exe", 1));
+    }
+
+    [Test]
+    public void Build_WithNoEvents_ShouldReturnEmptySummary()
+    {
+        // Act
+        var summary = EventSummaryBuilder.Build(Array.Empty<BaseEventData>(), 10);
+
+        // Assert
+        summary.EventCount.Should().Be(0);
+        summary.FirstEventAt.Should().BeNull();
+        summary.Directories.Should().BeEmpty();
+    }
+
+    private static FileEventData CreateFileEvent(string eventName, string filePath, DateTime timestamp, long? ioSize = null)
+    {
+        var payload = new Dictionary<string, object>();
+        if (ioSize.HasValue)
+        {
+            payload["IoSize"] = ioSize.Value;
+        }
+
+        return new FileEventData
+        {
+            Timestamp = timestamp,
+            TagName = "game",
+            ProcessId = 1234,
+            ThreadId = 1,
+            ProviderName = "Microsoft-Windows-Kernel-FileIO",
+            EventName = eventName,
+            ActivityId = Guid.Empty,
+            RelatedActivityId = Guid.Empty,
+            Payload = payload,
+            FilePath = filePath
+        };
+    }
+
+    private static ProcessStartEventData CreateProcessStart(string childProcessName, int childProcessId, DateTime timestamp)
+    {
+        return new ProcessStartEventData
+        {
+            Timestamp = timestamp,
+            TagName = "game",
+            ProcessId = 1234,
+            ThreadId = 1,
+            ProviderName = "Microsoft-Windows-Kernel-Process",
+            EventName = "Process/Start",
+            ActivityId = Guid.Empty,
+            RelatedActivityId = Guid.Empty,
+            Payload = new Dictionary<string, object>(),
+            ChildProcessId = childProcessId,
+            ChildProcessName = childProcessName
+        };
+    }
+}
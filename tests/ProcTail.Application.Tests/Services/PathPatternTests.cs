@@ -0,0 +1,48 @@
+using FluentAssertions;
+using NUnit.Framework;
+using ProcTail.Application.Services;
+
+namespace ProcTail.Application.Tests.Services;
+
+[TestFixture]
+[Category("Unit")]
+public class PathPatternTests
+{
+    [TestCase("CrashReporter*.exe", "CrashReporter64.exe", true)]
+    [TestCase("CrashReporter*.exe", "Game.exe", false)]
+    [TestCase("game?.exe", "game2.exe", true)]
+    [TestCase("/opt/game/*.log", "/opt/game/output.log", true)]
+    [TestCase("/opt/game/*.log", "/opt/game/logs/output.log", false)]
+    [TestCase("/opt/game/**.log", "/opt/game/logs/output.log", true)]
+    [TestCase("**/Overlay/**", @"C:\Program Files\Overlay\overlay.exe", true)]
+    [TestCase("", "/opt/game/game", false)]
+    [TestCase(@"regex:\.(tmp|bak)$", "/opt/game/save.bak", true)]
+    [TestCase(@"regex:\.(tmp|bak)$", "/opt/game/save.dat", false)]
+    public void IsMatch_WithPattern_ShouldMatchExpectedPaths(string pattern, string path, bool expected)
+    {
+        // Act & Assert
+        PathPattern.IsMatch(pattern, path).Should().Be(expected);
+    }
+
+    [TestCase(@"C:\Users\me\Saves", @"C:\Users\me\Saves\slot1.sav", true)]
+    [TestCase(@"C:\Users\me\Saves\", @"C:\Users\me\Saves", true)]
+    [TestCase(@"C:\Users\me\Saves", @"C:\Users\me\Saves2\slot1.sav", false)]
+    [TestCase(@"C:\Users\me\Saves", "C:/Users/me/Saves/slot1.sav", true)]
+    [TestCase("/home/me/.local/share/game", "/home/me/.local/share/game/save/slot1.sav", true)]
+    [TestCase("/home/me/.local/share/game", "/home/me/.local/share/other.sav", false)]
+    [TestCase("/", "/home/me/save.dat", true)]
+    public void IsUnderDirectory_WithDirectory_ShouldMatchDirectoryAndDescendants(string directory, string path, bool expected)
+    {
+        // Act & Assert
+        PathPattern.IsUnderDirectory(directory, path).Should().Be(expected);
+    }
+
+    [TestCase("**/*.log", true)]
+    [TestCase(@"regex:\.log$", true)]
+    [TestCase("regex:(unclosed", false)]
+    public void IsValid_WithPattern_ShouldRejectInvalidRegex(string pattern, bool expected)
+    {
+        // Act & Assert
+        PathPattern.IsValid(pattern).Should().Be(expected);
+    }
+}
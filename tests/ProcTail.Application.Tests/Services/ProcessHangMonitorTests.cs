@@ -0,0 +1,109 @@
+using FluentAssertions;
+using Moq;
+using NUnit.Framework;
+using ProcTail.Application.Services;
+using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Tests.Services;
+
+[TestFixture]
+[Category("Unit")]
+public class ProcessHangMonitorTests
+{
+    private static readonly DateTime BaseTime = new(2024, 1, 1, 0, 0, 0, DateTimeKind.Utc);
+
+    private Mock<IWatchTargetManager> _mockWatchTargetManager = null!;
+    private Mock<IProcessHangProbe> _mockHangProbe = null!;
+    private ProcessHangMonitor _monitor = null!;
+
+    [SetUp]
+    public void Setup()
+    {
+        _mockWatchTargetManager = new Mock<IWatchTargetManager>();
+        _mockHangProbe = new Mock<IProcessHangProbe>();
+        _mockWatchTargetManager.Setup(x => x.GetOptionsForTag(It.IsAny<string>())).Returns(WatchTargetOptions.Default);
+
+        _monitor = new ProcessHangMonitor(_mockWatchTargetManager.Object, _mockHangProbe.Object);
+    }
+
+    [Test]
+    public void Collect_ShouldCreateHangAndUnhangEventsOnlyWhenStateChanges()
+    {
+        // Arrange
+        _mockWatchTargetManager.Setup(x => x.GetWatchTargets()).Returns(new[]
+        {
+            new WatchTarget(1234, "game", BaseTime),
+            new WatchTarget(5678, "launcher", BaseTime)
+        });
+        _mockWatchTargetManager.Setup(x => x.GetOptionsForTag("game")).Returns(new WatchTargetOptions
+        {
+            DetectHangs = true,
+            Metadata = new Dictionary<string, string> { ["gameId"] = "570" }
+        });
+        _mockHangProbe.SetupSequence(x => x.Probe(1234))
+            .Returns(new ProcessWindowState(false, "Game"))
+            .Returns(new ProcessWindowState(true, "Game"))
+            .Returns(new ProcessWindowState(true, "Game"))
+            .Returns((ProcessWindowState?)null)
+            .Returns(new ProcessWindowState(false, "Game"));
+
+        // Act
+        var responding = _monitor.Collect(BaseTime);
+        var hung = _monitor.Collect(BaseTime.AddSeconds(1));
+        var stillHung = _monitor.Collect(BaseTime.AddSeconds(2));
+        var noWindow = _monitor.Collect(BaseTime.AddSeconds(3));
+        var recovered = _monitor.Collect(BaseTime.AddSeconds(4));
+
+        // Assert（検出を有効にしていないタグのプロセスは確認しない）
+        responding.Should().BeEmpty();
+        hung.Should().ContainSingle().Which.Should().BeEquivalentTo(new
+        {
+            TagName = "game",
+            ProcessId = 1234,
+            EventName = ProcessHangMonitor.HangEventName,
+            Timestamp = BaseTime.AddSeconds(1),
+            IsHung = true,
+            WindowTitle = "Game",
+            HangDurationMs = (long?)null
+        });
+        hung[0].TagMetadata.Should().Contain("gameId", "570");
+        stillHung.Should().BeEmpty();
+        noWindow.Should().BeEmpty();
+        recovered.Should().ContainSingle().Which.Should().BeEquivalentTo(new
+        {
+            EventName = ProcessHangMonitor.UnhangEventName,
+            IsHung = false,
+            HangDurationMs = 3000L
+        });
+        _mockHangProbe.Verify(x => x.Probe(5678), Times.Never);
+    }
+
+    [Test]
+    public void Collect_WithProcessInMultipleTags_ShouldProbeOnceAndDropStateOfRemovedProcesses()
+    {
+        // Arrange
+        _mockWatchTargetManager.SetupSequence(x => x.GetWatchTargets())
+            .Returns(new[]
+            {
+                new WatchTarget(1234, "launcher", BaseTime),
+                new WatchTarget(1234, "game", BaseTime)
+            })
+            .Returns(Array.Empty<WatchTarget>())
+            .Returns(new[] { new WatchTarget(1234, "game", BaseTime) });
+        _mockWatchTargetManager.Setup(x => x.GetOptionsForTag(It.IsAny<string>()))
+            .Returns(new WatchTargetOptions { DetectHangs = true });
+        _mockHangProbe.Setup(x => x.Probe(1234)).Returns(new ProcessWindowState(true, "Game"));
+
+        // Act
+        var hung = _monitor.Collect(BaseTime);
+        var removed = _monitor.Collect(BaseTime.AddSeconds(1));
+        var readded = _monitor.Collect(BaseTime.AddSeconds(2));
+
+        // Assert（監視対象から外れたプロセスは応答再開を記録せず、再び追加されたら改めて検出する）
+        hung.Select(hangEvent => hangEvent.TagName).Should().Equal("launcher", "game");
+        removed.Should().BeEmpty();
+        readded.Should().ContainSingle().Which.IsHung.Should().BeTrue();
+        _mockHangProbe.Verify(x => x.Probe(1234), Times.Exactly(2));
+    }
+}
@@ -0,0 +1,82 @@
+using FluentAssertions;
+using NUnit.Framework;
+using ProcTail.Application.Services;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Tests.Services;
+
+[TestFixture]
+[Category("Unit")]
+public class RawEventChannelTests
+{
+    [Test]
+    public void GetAfter_ShouldReturnRecordsAfterSequenceNumber()
+    {
+        // Arrange
+        var channel = new RawEventChannel();
+        for (var i = 0; i < 5; i++)
+        {
+            channel.Add("test-tag", CreateRecord(i));
+        }
+
+        // Act
+        var (records, lastSequenceNumber) = channel.GetAfter("test-tag", 2, 100);
+
+        // Assert
+        records.Select(r => r.SequenceNumber).Should().Equal(3, 4, 5);
+        records.Select(r => r.EventId).Should().Equal(2, 3, 4);
+        lastSequenceNumber.Should().Be(5);
+    }
+
+    [Test]
+    public void Add_WhenCapacityExceeded_ShouldDropOldestAndKeepNumbering()
+    {
+        // Arrange
+        var channel = new RawEventChannel(capacityPerTag: 3);
+
+        // Act
+        for (var i = 0; i < 5; i++)
+        {
+            channel.Add("test-tag", CreateRecord(i));
+        }
+        var (records, lastSequenceNumber) = channel.GetAfter("test-tag", 0, 100);
+
+        // Assert
+        records.Select(r => r.SequenceNumber).Should().Equal(3, 4, 5);
+        lastSequenceNumber.Should().Be(5);
+    }
+
+    [Test]
+    public void Clear_ShouldRemoveRecordsOnlyForThatTag()
+    {
+        // Arrange
+        var channel = new RawEventChannel();
+        channel.Add("tag-a", CreateRecord(1));
+        channel.Add("tag-b", CreateRecord(2));
+
+        // Act
+        channel.Clear("tag-a");
+        channel.Add("tag-a", CreateRecord(3));
+
+        // Assert
+        channel.GetAfter("tag-a", 0, 100).Records.Should().ContainSingle().Which.SequenceNumber.Should().Be(2);
+        channel.GetAfter("tag-b", 0, 100).Records.Should().ContainSingle();
+    }
+
+    private static RawEtwRecord CreateRecord(int eventId)
+    {
+        return new RawEtwRecord(
+            0,
+            DateTime.UtcNow,
+            null,
+            Guid.NewGuid(),
+            "Test-Provider",
+            eventId,
+            0,
+            "Info",
+            "Test/Info",
+            1234,
+            1,
+            new Dictionary<string, object>());
+    }
+}
@@ -0,0 +1,95 @@
+using FluentAssertions;
+using Moq;
+using NUnit.Framework;
+using ProcTail.Application.Services;
+using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Tests.Services;
+
+[TestFixture]
+[Category("Unit")]
+public class ResourceSnapshotSamplerTests
+{
+    private static readonly DateTime BaseTime = new(2024, 1, 1, 0, 0, 0, DateTimeKind.Utc);
+
+    private Mock<IWatchTargetManager> _mockWatchTargetManager = null!;
+    private Mock<IProcessResourceReader> _mockResourceReader = null!;
+    private ResourceSnapshotSampler _sampler = null!;
+
+    [SetUp]
+    public void Setup()
+    {
+        _mockWatchTargetManager = new Mock<IWatchTargetManager>();
+        _mockResourceReader = new Mock<IProcessResourceReader>();
+        _mockWatchTargetManager.Setup(x => x.GetOptionsForTag(It.IsAny<string>())).Returns(WatchTargetOptions.Default);
+
+        _sampler = new ResourceSnapshotSampler(_mockWatchTargetManager.Object, _mockResourceReader.Object);
+    }
+
+    [Test]
+    public void Collect_ShouldCreateSnapshotsOnlyWhenTagIntervalHasElapsed()
+    {
+        // Arrange
+        _mockWatchTargetManager.Setup(x => x.GetWatchTargets()).Returns(new[]
+        {
+            new WatchTarget(1234, "game", BaseTime),
+            new WatchTarget(5678, "launcher", BaseTime)
+        });
+        _mockWatchTargetManager.Setup(x => x.GetOptionsForTag("game")).Returns(new WatchTargetOptions
+        {
+            ResourceSnapshotIntervalMs = 1000,
+            Metadata = new Dictionary<string, string> { ["gameId"] = "570" }
+        });
+        _mockResourceReader.Setup(x => x.ReadUsage(1234)).Returns(new ProcessResourceUsage(
+            TimeSpan.FromMilliseconds(1500), TimeSpan.FromMilliseconds(1000), 64 * 1024 * 1024, 120, 10, 20, 4096, 8192));
+
+        // Act
+        var first = _sampler.Collect(BaseTime);
+        var beforeInterval = _sampler.Collect(BaseTime.AddMilliseconds(500));
+        var afterInterval = _sampler.Collect(BaseTime.AddMilliseconds(1000));
+
+        // Assert（間隔を指定していないタグのプロセスは読み取らない）
+        first.Should().ContainSingle().Which.Should().BeEquivalentTo(new
+        {
+            TagName = "game",
+            ProcessId = 1234,
+            EventName = ResourceSnapshotSampler.SnapshotEventName,
+            Timestamp = BaseTime,
+            CpuTimeMs = 1500L,
+            UserCpuTimeMs = 1000L,
+            WorkingSetBytes = 64L * 1024 * 1024,
+            HandleCount = 120,
+            ReadOperations = 10L,
+            WriteBytes = 8192L
+        });
+        first[0].TagMetadata.Should().Contain("gameId", "570");
+        beforeInterval.Should().BeEmpty();
+        afterInterval.Should().ContainSingle().Which.Timestamp.Should().Be(BaseTime.AddMilliseconds(1000));
+        _mockResourceReader.Verify(x => x.ReadUsage(5678), Times.Never);
+    }
+
+    [Test]
+    public void Collect_WithProcessInMultipleTags_ShouldReadOnceAndSkipExitedProcesses()
+    {
+        // Arrange
+        _mockWatchTargetManager.Setup(x => x.GetWatchTargets()).Returns(new[]
+        {
+            new WatchTarget(1234, "launcher", BaseTime),
+            new WatchTarget(1234, "game", BaseTime),
+            new WatchTarget(5678, "game", BaseTime)
+        });
+        _mockWatchTargetManager.Setup(x => x.GetOptionsForTag(It.IsAny<string>()))
+            .Returns(new WatchTargetOptions { ResourceSnapshotIntervalMs = 1000 });
+        _mockResourceReader.Setup(x => x.ReadUsage(1234)).Returns(new ProcessResourceUsage(TimeSpan.Zero, TimeSpan.Zero, 1024, 10));
+        _mockResourceReader.Setup(x => x.ReadUsage(5678)).Returns((ProcessResourceUsage?)null);
+
+        // Act
+        var snapshots = _sampler.Collect(BaseTime);
+
+        // Assert
+        snapshots.Select(snapshot => (snapshot.TagName, snapshot.ProcessId)).Should().Equal(("launcher", 1234), ("game", 1234));
+        snapshots.Should().OnlyContain(snapshot => snapshot.ReadBytes == null);
+        _mockResourceReader.Verify(x => x.ReadUsage(1234), Times.Once);
+    }
+}
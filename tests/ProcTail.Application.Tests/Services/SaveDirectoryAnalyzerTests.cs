@@ -0,0 +1,105 @@
+using FluentAssertions;
+using NUnit.Framework;
+using ProcTail.Application.Services;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Tests.Services;
+
+[TestFixture]
+[Category("Unit")]
+public class SaveDirectoryAnalyzerTests
+{
+    private static readonly DateTime BaseTime = new(2024, 1, 1, 0, 0, 0, DateTimeKind.Utc);
+
+    private const string SaveDirectory = @"C:\Users\player\AppData\LocalLow\Studio\Game\Saves";
+    private const string ConfigDirectory = @"C:\Users\player\AppData\LocalLow\Studio\Game";
+
+    [Test]
+    public void Analyze_ShouldRankRewrittenSaveDirectoryFirstAndCountBurstsAsSingleSave()
+    {
+        // Arrange（1回の保存は短い間隔の複数の書き込み）
+        var events = new List<BaseEventData>
+        {
+            CreateWrite(SaveDirectory + @"\slot1.sav", BaseTime, 4096),
+            CreateWrite(SaveDirectory + @"\slot1.sav", BaseTime.AddMilliseconds(100), 4096),
+            CreateWrite(SaveDirectory + @"\slot1.sav", BaseTime.AddMinutes(5), 8192),
+            CreateWrite(SaveDirectory + @"\slot1.sav", BaseTime.AddMinutes(10), 4096),
+            CreateWrite(SaveDirectory + @"\slot2.sav", BaseTime.AddMinutes(10), 1024),
+            CreateWrite(ConfigDirectory + @"\settings.ini", BaseTime, 256),
+            CreateWrite(ConfigDirectory + @"\settings.ini", BaseTime.AddMinutes(10), 256),
+            // 一度しか書き込まれないファイルだけのディレクトリは候補にしない
+            CreateWrite(@"C:\Users\player\Documents\Game\screenshot.png", BaseTime, 1024 * 1024)
+        };
+
+        // Act
+        var candidates = SaveDirectoryAnalyzer.Analyze(events, 10);
+
+        // Assert
+        candidates.Select(candidate => candidate.Directory).Should().Equal(SaveDirectory, ConfigDirectory);
+        candidates[0].Should().BeEquivalentTo(new
+        {
+            FileCount = 2,
+            RewrittenFileCount = 1,
+            SaveCount = 4,
+            MaxSaveBytes = 8192L,
+            LastWriteAt = BaseTime.AddMinutes(10)
+        });
+        candidates[0].Files.Should().Equal("slot1.sav", "slot2.sav");
+    }
+
+    [TestCase(@"C:\Users\player\AppData\Local\Game\ShaderCache\shaders.bin")]
+    [TestCase(@"C:\Users\player\AppData\Local\Temp\game.tmp")]
+    [TestCase(@"C:\Users\player\AppData\Local\Game\Logs\output.txt")]
+    [TestCase(@"C:\Users\player\AppData\Local\Game\output.log")]
+    [TestCase(@"C:\Program Files\Game\Saves\slot1.sav")]
+    public void Analyze_WithCacheLogOrNonUserPath_ShouldNotReportCandidate(string filePath)
+    {
+        // Arrange
+        var events = new List<BaseEventData>
+        {
+            CreateWrite(filePath, BaseTime, 1024),
+            CreateWrite(filePath, BaseTime.AddMinutes(1), 1024)
+        };
+
+        // Act & Assert
+        SaveDirectoryAnalyzer.Analyze(events, 10).Should().BeEmpty();
+    }
+
+    [Test]
+    public void Analyze_WithLargeSave_ShouldNotReportCandidate()
+    {
+        // Arrange（集約された書き込みはTotalBytesを保存の大きさとして数える）
+        var filePath = @"/home/player/.local/share/Game/world.dat";
+        var events = new List<BaseEventData>
+        {
+            CreateWrite(filePath, BaseTime, null) with { CoalescedCount = 100, TotalBytes = SaveDirectoryAnalyzer.MaxSaveBytes + 1 },
+            CreateWrite(filePath, BaseTime.AddMinutes(1), 1024)
+        };
+
+        // Act & Assert
+        SaveDirectoryAnalyzer.Analyze(events, 10).Should().BeEmpty();
+    }
+
+    private static FileEventData CreateWrite(string filePath, DateTime timestamp, long? ioSize)
+    {
+        var payload = new Dictionary<string, object>();
+        if (ioSize.HasValue)
+        {
+            payload["IoSize"] = ioSize.Value;
+        }
+
+        return new FileEventData
+        {
+            Timestamp = timestamp,
+            TagName = "game",
+            ProcessId = 1234,
+            ThreadId = 1,
+            ProviderName = "Microsoft-Windows-Kernel-FileIO",
+            EventName = "FileIO/Write",
+            ActivityId = Guid.Empty,
+            RelatedActivityId = Guid.Empty,
+            Payload = payload,
+            FilePath = filePath
+        };
+    }
+}
@@ -0,0 +1,117 @@
+using FluentAssertions;
+using Moq;
+using NUnit.Framework;
+using ProcTail.Application.Services;
+using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Tests.Services;
+
+[TestFixture]
+[Category("Unit")]
+public class TagActivityTrackerTests
+{
+    private static readonly DateTime BaseTime = new(2024, 1, 1, 0, 0, 0, DateTimeKind.Utc);
+
+    private Mock<IWatchTargetManager> _mockWatchTargetManager = null!;
+    private TagActivityTracker _tracker = null!;
+
+    [SetUp]
+    public void Setup()
+    {
+        _mockWatchTargetManager = new Mock<IWatchTargetManager>();
+        _mockWatchTargetManager.Setup(x => x.GetWatchTargets()).Returns(new[] { new WatchTarget(1234, "game", BaseTime) });
+        _mockWatchTargetManager.Setup(x => x.GetOptionsForTag(It.IsAny<string>())).Returns(WatchTargetOptions.Default);
+        _mockWatchTargetManager.Setup(x => x.GetOptionsForTag("game")).Returns(new WatchTargetOptions
+        {
+            IdleTimeoutMs = 60000,
+            Metadata = new Dictionary<string, string> { ["gameId"] = "570" }
+        });
+
+        _tracker = new TagActivityTracker(_mockWatchTargetManager.Object);
+    }
+
+    private static FileEventData CreateFileEvent(string tagName)
+    {
+        return new FileEventData
+        {
+            Timestamp = BaseTime,
+            TagName = tagName,
+            ProcessId = 1234,
+            ThreadId = 1,
+            ProviderName = "Microsoft-Windows-Kernel-FileIO",
+            EventName = "FileIO/Write",
+            ActivityId = Guid.Empty,
+            RelatedActivityId = Guid.Empty,
+            Payload = new Dictionary<string, object>(),
+            FilePath = @"C:\Games\save.dat"
+        };
+    }
+
+    [Test]
+    public void Collect_AfterIdleTimeout_ShouldCreateIdleOnceAndActiveOnNextEvent()
+    {
+        // Arrange
+        _tracker.RecordActivity(CreateFileEvent("game"), BaseTime).Should().BeNull();
+
+        // Act
+        var beforeTimeout = _tracker.Collect(BaseTime.AddSeconds(59));
+        var idle = _tracker.Collect(BaseTime.AddSeconds(60));
+        var stillIdle = _tracker.Collect(BaseTime.AddSeconds(120));
+        var active = _tracker.RecordActivity(CreateFileEvent("game"), BaseTime.AddSeconds(150));
+        var afterActive = _tracker.RecordActivity(CreateFileEvent("game"), BaseTime.AddSeconds(151));
+
+        // Assert
+        beforeTimeout.Should().BeEmpty();
+        idle.Should().ContainSingle().Which.Should().BeEquivalentTo(new
+        {
+            TagName = "game",
+            ProcessId = 0,
+            EventName = TagActivityTracker.IdleEventName,
+            Timestamp = BaseTime.AddSeconds(60),
+            State = TagActivityState.Idle,
+            LastActivityAt = BaseTime,
+            IdleDurationMs = (long?)null
+        });
+        idle[0].TagMetadata.Should().Contain("gameId", "570");
+        stillIdle.Should().BeEmpty();
+        active.Should().NotBeNull();
+        active!.EventName.Should().Be(TagActivityTracker.ActiveEventName);
+        active.LastActivityAt.Should().Be(BaseTime);
+        active.IdleDurationMs.Should().Be(150000);
+        afterActive.Should().BeNull();
+    }
+
+    [Test]
+    public void RecordActivity_WithOwnEventsOrTagWithoutTimeout_ShouldNotCountAsActivity()
+    {
+        // Arrange（監視を開始した時点から無操作時間を数える）
+        _tracker.Collect(BaseTime);
+        var snapshot = new ResourceSnapshotEventData
+        {
+            Timestamp = BaseTime,
+            TagName = "game",
+            ProcessId = 1234,
+            ThreadId = 0,
+            ProviderName = ResourceSnapshotSampler.SnapshotProviderName,
+            EventName = ResourceSnapshotSampler.SnapshotEventName,
+            ActivityId = Guid.Empty,
+            RelatedActivityId = Guid.Empty,
+            Payload = new Dictionary<string, object>(),
+            CpuTimeMs = 0,
+            UserCpuTimeMs = 0,
+            WorkingSetBytes = 0,
+            HandleCount = 0
+        };
+
+        // Act
+        var snapshotResult = _tracker.RecordActivity(snapshot, BaseTime.AddSeconds(30));
+        var otherTagResult = _tracker.RecordActivity(CreateFileEvent("launcher"), BaseTime.AddSeconds(30));
+        var idle = _tracker.Collect(BaseTime.AddSeconds(60));
+
+        // Assert
+        snapshotResult.Should().BeNull();
+        otherTagResult.Should().BeNull();
+        idle.Should().ContainSingle().Which.LastActivityAt.Should().Be(BaseTime);
+    }
+}
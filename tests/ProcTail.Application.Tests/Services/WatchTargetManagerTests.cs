@@ -3,6 +3,7 @@ using Microsoft.Extensions.Logging;
 using Moq;
 using NUnit.Framework;
 using ProcTail.Application.Services;
+using ProcTail.Core.Interfaces;
 using ProcTail.Core.Models;
 
 namespace ProcTail.Application.Tests.Services;
@@ -48,6 +49,220 @@ public class WatchTargetManagerTests
         _watchTargetManager.ActiveTargetCount.Should().Be(0);
     }
 
+    [Test]
+    public async Task AddTargetAsync_WithOptions_ShouldKeepOptionsForTagUntilRemoved()
+    {
+        // Arrange
+        const string tagName = "thread-tag";
+        var options = new WatchTargetOptions { IncludeThreadEvents = true };
+
+        // Act
+        await _watchTargetManager.AddTargetAsync(1234, tagName, options);
+        await _watchTargetManager.AddTargetAsync(5678, tagName);
+
+        // Assert
+        _watchTargetManager.GetOptionsForTag(tagName).Should().Be(options);
+        _watchTargetManager.GetOptionsForTag("other-tag").Should().Be(WatchTargetOptions.Default);
+
+        await _watchTargetManager.RemoveWatchTargetsByTagAsync(tagName);
+        _watchTargetManager.GetOptionsForTag(tagName).Should().Be(WatchTargetOptions.Default);
+    }
+
+    [Test]
+    public async Task AddTargetAsync_WithProcessValidator_ShouldRecordAncestorChainAndInheritToChildren()
+    {
+        // Arrange
+        var start = new DateTime(2024, 1, 1, 12, 0, 0, DateTimeKind.Utc);
+        var mockValidator = new Mock<IProcessValidator>();
+        mockValidator.Setup(x => x.GetProcessInfo(300))
+            .Returns(new ProcessInfo(300, "game", "/opt/game", start.AddMinutes(2), 200));
+        mockValidator.Setup(x => x.GetProcessInfo(200))
+            .Returns(new ProcessInfo(200, "launcher", "/opt/launcher", start.AddMinutes(1), 100));
+        mockValidator.Setup(x => x.GetProcessInfo(100)).Returns((ProcessInfo?)null);
+
+        using var manager = new WatchTargetManager(_mockLogger.Object, mockValidator.Object);
+
+        // Act
+        await manager.AddTargetAsync(300, "game");
+        await manager.AddChildProcessAsync(400, 300);
+
+        // Assert
+        var parent = manager.GetWatchTargets().Single(t => t.ProcessId == 300);
+        parent.Ancestors!.Select(a => a.ProcessId).Should().Equal(200, 100);
+        parent.Ancestors![0].ProcessName.Should().Be("launcher");
+        parent.Ancestors![1].ProcessName.Should().Be("[Terminated]");
+
+        var child = manager.GetWatchTargets().Single(t => t.ProcessId == 400);
+        child.Ancestors!.Select(a => a.ProcessId).Should().Equal(300, 200, 100);
+        child.Ancestors![0].ExecutablePath.Should().Be("/opt/game");
+    }
+
+    [Test]
+    public async Task AddProcessByPathAsync_WithRegisteredDirectory_ShouldAddOnlyProcessesUnderIt()
+    {
+        // Arrange
+        var installDirectory = Path.Combine(Path.GetTempPath(), "proctail-tests", "MyGame");
+        await _watchTargetManager.AddPathTargetAsync("my-game", installDirectory, null);
+
+        // Act
+        var insideResult = await _watchTargetManager.AddProcessByPathAsync(5000, Path.Combine(installDirectory, "bin", "game.exe"));
+        var siblingResult = await _watchTargetManager.AddProcessByPathAsync(5001, installDirectory + "Launcher" + Path.DirectorySeparatorChar + "launcher.exe");
+
+        // Assert
+        insideResult.Should().BeTrue();
+        siblingResult.Should().BeFalse();
+        _watchTargetManager.GetTagForProcess(5000).Should().Be("my-game");
+    }
+
+    [Test]
+    public async Task RemoveWatchTargetsByTagAsync_WithDirectoryTarget_ShouldStopMatchingNewProcesses()
+    {
+        // Arrange
+        var installDirectory = Path.Combine(Path.GetTempPath(), "proctail-tests", "MyGame");
+        await _watchTargetManager.AddPathTargetAsync("my-game", installDirectory, null);
+        await _watchTargetManager.AddProcessByPathAsync(5000, Path.Combine(installDirectory, "game.exe"));
+
+        // Act
+        var removedCount = await _watchTargetManager.RemoveWatchTargetsByTagAsync("my-game");
+        var result = await _watchTargetManager.AddProcessByPathAsync(5001, Path.Combine(installDirectory, "game.exe"));
+
+        // Assert
+        removedCount.Should().Be(2); // ディレクトリ登録とプロセス
+        result.Should().BeFalse();
+        _watchTargetManager.ActiveTargetCount.Should().Be(0);
+    }
+
+    [Test]
+    public async Task RewatchRestartedProcessAsync_WithinWindow_ShouldAddFirstRestartToSameTagWithOptions()
+    {
+        // Arrange
+        var gamePath = Path.Combine(Path.GetTempPath(), "proctail-tests", "MyGame", "game.exe");
+        var mockValidator = new Mock<IProcessValidator>();
+        mockValidator.Setup(x => x.GetProcessInfo(5000))
+            .Returns(new ProcessInfo(5000, "game", gamePath, DateTime.UtcNow, null));
+        var options = new WatchTargetOptions { RewatchWindowMs = 60000, Metadata = new Dictionary<string, string> { ["gameId"] = "570" } };
+
+        using var manager = new WatchTargetManager(_mockLogger.Object, mockValidator.Object);
+        await manager.AddTargetAsync(5000, "game", options);
+        await manager.AddTargetAsync(5000, "no-rewatch");
+
+        // Act
+        manager.RemoveTarget(5000);
+        var otherResult = await manager.RewatchRestartedProcessAsync(6000, Path.Combine(Path.GetTempPath(), "proctail-tests", "MyGame", "updater.exe"));
+        var restartResult = await manager.RewatchRestartedProcessAsync(6001, gamePath);
+        var secondResult = await manager.RewatchRestartedProcessAsync(6002, gamePath);
+
+        // Assert
+        otherResult.Should().BeFalse();
+        restartResult.Should().BeTrue();
+        secondResult.Should().BeFalse();
+        manager.GetTagsForProcess(6001).Should().Equal("game");
+        manager.GetOptionsForTag("game").Should().Be(options);
+    }
+
+    [Test]
+    public async Task RewatchRestartedProcessAsync_AfterWindow_ShouldNotAddAndDropOptions()
+    {
+        // Arrange
+        var gamePath = Path.Combine(Path.GetTempPath(), "proctail-tests", "MyGame", "game.exe");
+        var mockValidator = new Mock<IProcessValidator>();
+        mockValidator.Setup(x => x.GetProcessInfo(5000))
+            .Returns(new ProcessInfo(5000, "game", gamePath, DateTime.UtcNow, null));
+
+        using var manager = new WatchTargetManager(_mockLogger.Object, mockValidator.Object);
+        await manager.AddTargetAsync(5000, "game", new WatchTargetOptions { RewatchWindowMs = 1 });
+
+        // Act
+        manager.RemoveTarget(5000);
+        await Task.Delay(50);
+        var result = await manager.RewatchRestartedProcessAsync(6001, gamePath);
+
+        // Assert
+        result.Should().BeFalse();
+        manager.IsWatchedProcess(6001).Should().BeFalse();
+        manager.GetOptionsForTag("game").Should().Be(WatchTargetOptions.Default);
+    }
+
+    [Test]
+    public async Task IsExcludedProcess_WithExclusionRules_ShouldMatchPidImageNameAndPath()
+    {
+        // Arrange
+        var start = new DateTime(2024, 1, 1, 12, 0, 0, DateTimeKind.Utc);
+        var mockValidator = new Mock<IProcessValidator>();
+        mockValidator.Setup(x => x.GetProcessInfo(It.IsAny<int>()))
+            .Returns((int pid) => new ProcessInfo(pid, "game", "/opt/game/game", start, null));
+        mockValidator.Setup(x => x.GetProcessInfo(200))
+            .Returns(new ProcessInfo(200, "CrashReporter", "/opt/game/CrashReporter", start, 100));
+        mockValidator.Setup(x => x.GetProcessInfo(300))
+            .Returns(new ProcessInfo(300, "overlay", "/opt/overlay/bin/overlay", start, 100));
+
+        using var manager = new WatchTargetManager(_mockLogger.Object, mockValidator.Object);
+        await manager.AddTargetAsync(100, "game", new WatchTargetOptions
+        {
+            ExcludedProcesses = new[] { "Crash*", "/opt/overlay/**", "400" }
+        });
+        await manager.AddChildProcessAsync(200, 100);
+        await manager.AddChildProcessAsync(300, 100);
+        await manager.AddChildProcessAsync(400, 100);
+        await manager.AddChildProcessAsync(500, 100);
+
+        // Act & Assert
+        manager.IsExcludedProcess(100, "game").Should().BeFalse();
+        manager.IsExcludedProcess(200, "game").Should().BeTrue();
+        manager.IsExcludedProcess(300, "game").Should().BeTrue();
+        manager.IsExcludedProcess(400, "game").Should().BeTrue();
+        manager.IsExcludedProcess(500, "game").Should().BeFalse();
+
+        manager.UpdateExecutablePath(500, "/opt/overlay/bin/injector");
+        manager.IsExcludedProcess(500, "game").Should().BeTrue();
+    }
+
+    [Test]
+    public async Task AddChildProcessAsync_WithMaxChildDepth_ShouldStopAtDepthLimit()
+    {
+        // Arrange
+        await _watchTargetManager.AddTargetAsync(1000, "limited", new WatchTargetOptions { MaxChildDepth = 1 });
+
+        // Act
+        var childResult = await _watchTargetManager.AddChildProcessAsync(2000, 1000);
+        var grandchildResult = await _watchTargetManager.AddChildProcessAsync(3000, 2000);
+
+        // Assert
+        childResult.Should().BeTrue();
+        grandchildResult.Should().BeFalse();
+        _watchTargetManager.GetWatchTargets().Single(t => t.ProcessId == 2000).Depth.Should().Be(1);
+        _watchTargetManager.IsWatchedProcess(3000).Should().BeFalse();
+    }
+
+    [Test]
+    public async Task AddChildProcessAsync_WithPropagationRules_ShouldSkipOtherSessionsAndStopExecutables()
+    {
+        // Arrange
+        var start = new DateTime(2024, 1, 1, 12, 0, 0, DateTimeKind.Utc);
+        var mockValidator = new Mock<IProcessValidator>();
+        mockValidator.Setup(x => x.GetProcessInfo(100))
+            .Returns(new ProcessInfo(100, "launcher", @"C:\Games\launcher.exe", start, null, SessionId: 1));
+        mockValidator.Setup(x => x.GetProcessInfo(200))
+            .Returns(new ProcessInfo(200, "Updater", @"C:\Games\Updater.exe", start, 100, SessionId: 1));
+        mockValidator.Setup(x => x.GetProcessInfo(300))
+            .Returns(new ProcessInfo(300, "service", @"C:\Games\service.exe", start, 100, SessionId: 0));
+        mockValidator.Setup(x => x.GetProcessInfo(400))
+            .Returns(new ProcessInfo(400, "game", @"C:\Games\game.exe", start, 100, SessionId: 1));
+
+        using var manager = new WatchTargetManager(_mockLogger.Object, mockValidator.Object);
+        await manager.AddTargetAsync(100, "game", new WatchTargetOptions
+        {
+            FollowAcrossSessions = false,
+            StopAtExecutables = new[] { "updater.exe" }
+        });
+
+        // Act & Assert
+        (await manager.AddChildProcessAsync(200, 100)).Should().BeFalse();
+        (await manager.AddChildProcessAsync(300, 100)).Should().BeFalse();
+        (await manager.AddChildProcessAsync(400, 100)).Should().BeTrue();
+        manager.ActiveTargetCount.Should().Be(2);
+    }
+
     [Test]
     public async Task RemoveWatchTargetsByTagAsync_WithNonExistingTag_ShouldReturnZero()
     {
@@ -150,6 +365,55 @@ public class WatchTargetManagerTests
         _watchTargetManager.ActiveTargetCount.Should().Be(2);
     }
 
+    [Test]
+    public async Task AddTargetAsync_WithSameProcessInAnotherTag_ShouldBelongToBothTags()
+    {
+        // Arrange
+        const int launcherProcessId = 1000;
+        const int childProcessId = 2000;
+        var gameOptions = new WatchTargetOptions
+        {
+            Metadata = new Dictionary<string, string> { ["gameId"] = "1234", ["session"] = "abc" }
+        };
+
+        // Act
+        var addedToLauncher = await _watchTargetManager.AddTargetAsync(launcherProcessId, "launcher");
+        var addedToGame = await _watchTargetManager.AddTargetAsync(launcherProcessId, "game", gameOptions);
+        var addedTwice = await _watchTargetManager.AddTargetAsync(launcherProcessId, "game");
+        await _watchTargetManager.AddChildProcessAsync(childProcessId, launcherProcessId);
+        var infos = await _watchTargetManager.GetWatchTargetInfosAsync();
+
+        // Assert
+        addedToLauncher.Should().BeTrue();
+        addedToGame.Should().BeTrue();
+        addedTwice.Should().BeFalse();
+        _watchTargetManager.GetTagsForProcess(launcherProcessId).Should().Equal("launcher", "game");
+        _watchTargetManager.GetTagsForProcess(childProcessId).Should().Equal("launcher", "game");
+        _watchTargetManager.ActiveTargetCount.Should().Be(4);
+        infos.Single(info => info.ProcessId == launcherProcessId && info.TagName == "game").TagMetadata.Should().BeEquivalentTo(gameOptions.Metadata);
+        infos.Single(info => info.ProcessId == launcherProcessId && info.TagName == "launcher").TagMetadata.Should().BeNull();
+    }
+
+    [Test]
+    public async Task RemoveWatchTargetsByTagAsync_WithProcessInMultipleTags_ShouldKeepOtherTags()
+    {
+        // Arrange
+        const int processId = 1000;
+        await _watchTargetManager.AddTargetAsync(processId, "launcher");
+        await _watchTargetManager.AddTargetAsync(processId, "game");
+
+        // Act
+        var removedCount = await _watchTargetManager.RemoveWatchTargetsByTagAsync("game");
+
+        // Assert
+        removedCount.Should().Be(1);
+        _watchTargetManager.IsWatchedProcess(processId).Should().BeTrue();
+        _watchTargetManager.GetTagsForProcess(processId).Should().Equal("launcher");
+
+        _watchTargetManager.RemoveTarget(processId).Should().BeTrue();
+        _watchTargetManager.GetTagsForProcess(processId).Should().BeEmpty();
+    }
+
     [Test]
     public async Task AddChildProcessAsync_WithNonWatchedParent_ShouldFail()
     {
@@ -0,0 +1,57 @@
+using System.ServiceProcess;
+using FluentAssertions;
+using NUnit.Framework;
+using ProcTail.Cli.Services;
+
+namespace ProcTail.Application.Tests.Services;
+
+[TestFixture]
+[Category("Unit")]
+public class WindowsServiceManagerTests
+{
+    [Test]
+    public void CreateInstallArguments_ShouldQuoteBinaryPathInsideBinPath()
+    {
+        // Act
+        var arguments = WindowsServiceManager.CreateInstallArguments(
+            "ProcTail", @"C:\Program Files\ProcTail\ProcTail.Host.exe", "ProcTail Service");
+
+        // Assert（空白を含むパスの先頭部分 "C:\Program" が実行ファイルとして解釈されないこと）
+        arguments.Should().Be(
+            @"create ""ProcTail"" binpath= ""\""C:\Program Files\ProcTail\ProcTail.Host.exe\"""" start= auto DisplayName= ""ProcTail Service""");
+    }
+
+    [Test]
+    public void CreateDescriptionArguments_ShouldQuoteServiceNameAndDescription()
+    {
+        // Act & Assert
+        WindowsServiceManager.CreateDescriptionArguments("ProcTail", "Process monitoring service")
+            .Should().Be(@"description ""ProcTail"" ""Process monitoring service""");
+    }
+
+    [Test]
+    public void CreateRecoveryArguments_ShouldRestartOnFailureAndNonZeroExitCode()
+    {
+        // Act
+        var arguments = WindowsServiceManager.CreateRecoveryArguments("ProcTail");
+
+        // Assert
+        arguments.Should().Equal(
+            @"failure ""ProcTail"" reset= 86400 actions= restart/5000/restart/10000/restart/60000",
+            @"failureflag ""ProcTail"" 1");
+    }
+
+    [TestCase(ServiceControllerStatus.Stopped, ServiceStatus.Stopped)]
+    [TestCase(ServiceControllerStatus.Running, ServiceStatus.Running)]
+    [TestCase(ServiceControllerStatus.Paused, ServiceStatus.Paused)]
+    [TestCase(ServiceControllerStatus.StartPending, ServiceStatus.StartPending)]
+    [TestCase(ServiceControllerStatus.StopPending, ServiceStatus.StopPending)]
+    [TestCase(ServiceControllerStatus.ContinuePending, ServiceStatus.ContinuePending)]
+    [TestCase(ServiceControllerStatus.PausePending, ServiceStatus.PausePending)]
+    [TestCase((ServiceControllerStatus)0, ServiceStatus.NotFound)]
+    public void ToServiceStatus_ShouldMapControllerStatus(ServiceControllerStatus status, ServiceStatus expected)
+    {
+        // Act & Assert
+        WindowsServiceManager.ToServiceStatus(status).Should().Be(expected);
+    }
+}
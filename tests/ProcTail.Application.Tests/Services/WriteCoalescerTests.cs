@@ -0,0 +1,98 @@
+using FluentAssertions;
+using NUnit.Framework;
+using ProcTail.Application.Services;
+using ProcTail.Core.Models;
+
+namespace ProcTail.Application.Tests.Services;
+
+[TestFixture]
+[Category("Unit")]
+public class WriteCoalescerTests
+{
+    private static readonly DateTime BaseTime = new(2024, 1, 1, 0, 0, 0, DateTimeKind.Utc);
+
+    private WriteCoalescer _coalescer = null!;
+
+    [SetUp]
+    public void SetUp()
+    {
+        _coalescer = new WriteCoalescer();
+    }
+
+    [Test]
+    public void Add_WithBurstOfWritesToSameFile_ShouldEmitSingleAggregatedEvent()
+    {
+        // Arrange
+        var window = TimeSpan.FromMilliseconds(500);
+
+        // Act
+        var emitted = new List<BaseEventData>();
+        for (var i = 0; i < 5; i++)
+        {
+            emitted.AddRange(_coalescer.Add(CreateFileEvent("FileIO/Write", BaseTime.AddMilliseconds(i * 10), 100), window));
+        }
+        emitted.AddRange(_coalescer.Add(CreateFileEvent("FileIO/Close", BaseTime.AddMilliseconds(60)), window));
+
+        // Assert
+        emitted.Should().HaveCount(2);
+        var aggregated = emitted[0].Should().BeOfType<FileEventData>().Subject;
+        aggregated.CoalescedCount.Should().Be(5);
+        aggregated.TotalBytes.Should().Be(500);
+        aggregated.FirstTimestamp.Should().Be(BaseTime);
+        aggregated.Timestamp.Should().Be(BaseTime.AddMilliseconds(40));
+        emitted[1].EventName.Should().Be("FileIO/Close");
+    }
+
+    [Test]
+    public void Add_WithWriteOutsideWindow_ShouldStartNewAggregation()
+    {
+        // Arrange
+        var window = TimeSpan.FromMilliseconds(100);
+
+        // Act
+        _coalescer.Add(CreateFileEvent("FileIO/Write", BaseTime, 10), window).Should().BeEmpty();
+        var emitted = _coalescer.Add(CreateFileEvent("FileIO/Write", BaseTime.AddMilliseconds(150), 20), window);
+        var flushed = _coalescer.FlushAll();
+
+        // Assert
+        emitted.Should().ContainSingle().Which.As<FileEventData>().CoalescedCount.Should().BeNull();
+        flushed.Should().ContainSingle().Which.As<FileEventData>().Timestamp.Should().Be(BaseTime.AddMilliseconds(150));
+        _coalescer.PendingCount.Should().Be(0);
+    }
+
+    [Test]
+    public void Add_WithZeroWindow_ShouldPassThrough()
+    {
+        // Arrange
+        var write = CreateFileEvent("FileIO/Write", BaseTime, 10);
+
+        // Act
+        var emitted = _coalescer.Add(write, TimeSpan.Zero);
+
+        // Assert
+        emitted.Should().ContainSingle().Which.Should().BeSameAs(write);
+    }
+
+    private static FileEventData CreateFileEvent(string eventName, DateTime timestamp, long? ioSize = null)
+    {
+        var payload = new Dictionary<string, object>();
+        if (ioSize.HasValue)
+        {
+            payload["IoSize"] = ioSize.Value;
+        }
+
+        return new FileEventData
+        {
+            Timestamp = timestamp,
+            TagName = "test-tag",
+            ProcessId = 1234,
+            ThreadId = 1,
+            ProviderName = "Microsoft-Windows-Kernel-FileIO",
+            EventName = eventName,
+            ActivityId = Guid.Empty,
+            RelatedActivityId = Guid.Empty,
+            Payload = payload,
+            FilePath = @"C:\game\output.log"
+        };
+    }
+}
@@ -202,4 +202,16 @@ public class EventModelsTests
         result.EventData.Should().BeNull();
         result.ErrorMessage.Should().Be(errorMessage);
     }
-}
\ No newline at end of file
+
+    [Test]
+    public void ClockMapping_ToUtc_ShouldConvertRelativeToSyncPoint()
+    {
+        // Arrange
+        var syncTime = new DateTime(2024, 1, 1, 0, 0, 0, DateTimeKind.Utc);
+        var mapping = new ClockMapping(Frequency: 10_000_000, SyncTimestamp: 50_000_000, SyncTimeUtc: syncTime);
+
+        // Act & Assert
+        mapping.ToUtc(65_000_000).Should().Be(syncTime.AddSeconds(1.5));
+        mapping.ToUtc(40_000_000).Should().Be(syncTime.AddSeconds(-1));
+    }
+}
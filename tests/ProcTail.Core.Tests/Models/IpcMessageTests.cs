@@ -188,4 +188,33 @@ public class IpcMessageTests
         deserialized.StartTime.Should().BeCloseTo(original.StartTime, TimeSpan.FromMilliseconds(1));
         deserialized.TagName.Should().Be(original.TagName);
     }
-}
\ No newline at end of file
+
+    [Test]
+    public void EventCursor_Encode_ShouldRoundTripAndBeUrlSafe()
+    {
+        // Arrange
+        var original = new EventCursor("ゲーム:1/+", 123456789);
+
+        // Act
+        var encoded = original.Encode();
+        var decoded = EventCursor.TryDecode(encoded, out var result);
+
+        // Assert
+        encoded.Should().NotContainAny("+", "/", "=");
+        decoded.Should().BeTrue();
+        result.Should().Be(original);
+    }
+
+    [TestCase("")]
+    [TestCase("not a cursor!")]
+    [TestCase("YWJj")]
+    public void EventCursor_TryDecode_WithInvalidCursor_ShouldReturnFalse(string cursor)
+    {
+        // Act
+        var decoded = EventCursor.TryDecode(cursor, out var result);
+
+        // Assert
+        decoded.Should().BeFalse();
+        result.Should().BeNull();
+    }
+}
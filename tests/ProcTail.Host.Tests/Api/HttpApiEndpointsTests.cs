@@ -0,0 +1,114 @@
+using System.Text.Json;
+using System.Text.Json.Nodes;
+using FluentAssertions;
+using Microsoft.AspNetCore.Http;
+using Microsoft.AspNetCore.Http.HttpResults;
+using Moq;
+using NUnit.Framework;
+using ProcTail.Application.Services;
+using ProcTail.Core.Interfaces;
+using ProcTail.Host.Api;
+
+namespace ProcTail.Host.Tests.Api;
+
+[TestFixture]
+[Category("Unit")]
+public class HttpApiEndpointsTests
+{
+    [Test]
+    public async Task ForwardAsync_ShouldPassRequestAsPipeRequestAndReturnResponse()
+    {
+        // Arrange
+        var responseJson = """{"Success":true,"RemovedCount":1}""";
+        var (handler, requests) = CreateHandler(responseJson);
+
+        // Act
+        var result = await HttpApiEndpoints.ForwardAsync(handler, new DefaultHttpContext().Request,
+            new { RequestType = "RemoveWatchTarget", TagName = "game" }, CancellationToken.None);
+
+        // Assert
+        var request = JsonNode.Parse(requests.Single())!;
+        request["RequestType"]!.GetValue<string>().Should().Be("RemoveWatchTarget");
+        request["TagName"]!.GetValue<string>().Should().Be("game");
+        var content = result.Should().BeOfType<ContentHttpResult>().Which;
+        content.StatusCode.Should().Be(StatusCodes.Status200OK);
+        content.ContentType.Should().StartWith("application/json");
+        content.ResponseContent.Should().Be(responseJson);
+    }
+
+    [Test]
+    public async Task ForwardAsync_WithFailedResponse_ShouldReturnBadRequest()
+    {
+        // Arrange
+        var responseJson = JsonSerializer.Serialize(new { Success = false, ErrorMessage = "No watch targets found for tag: game" });
+        var (handler, _) = CreateHandler(responseJson);
+
+        // Act
+        var result = await HttpApiEndpoints.ForwardAsync(handler, new DefaultHttpContext().Request,
+            new { RequestType = "RemoveWatchTarget", TagName = "game" }, CancellationToken.None);
+
+        // Assert
+        var content = result.Should().BeOfType<ContentHttpResult>().Which;
+        content.StatusCode.Should().Be(StatusCodes.Status400BadRequest);
+        content.ResponseContent.Should().Be(responseJson);
+    }
+
+    [Test]
+    public async Task ForwardAsync_WithBearerToken_ShouldPassTokenAsAuthToken()
+    {
+        // Arrange
+        var (handler, requests) = CreateHandler("""{"Success":true}""");
+        var httpContext = new DefaultHttpContext();
+        httpContext.Request.Headers.Authorization = "Bearer alice-secret";
+
+        // Act
+        await HttpApiEndpoints.ForwardAsync(handler, httpContext.Request, new { RequestType = "GetStatus" }, CancellationToken.None);
+
+        // Assert
+        JsonNode.Parse(requests.Single())!["AuthToken"]!.GetValue<string>().Should().Be("alice-secret");
+    }
+
+    [TestCase(null)]
+    [TestCase("Basic alice-secret")]
+    public async Task ForwardAsync_WithoutBearerToken_ShouldNotAddAuthToken(string? authorization)
+    {
+        // Arrange
+        var (handler, requests) = CreateHandler("""{"Success":true}""");
+        var httpContext = new DefaultHttpContext();
+        if (authorization != null)
+        {
+            httpContext.Request.Headers.Authorization = authorization;
+        }
+
+        // Act
+        await HttpApiEndpoints.ForwardAsync(handler, httpContext.Request, new { RequestType = "GetStatus" }, CancellationToken.None);
+
+        // Assert
+        JsonNode.Parse(requests.Single())!.AsObject().ContainsKey("AuthToken").Should().BeFalse();
+    }
+
+    [TestCase(ClientAuthorizer.UnauthorizedMessage, StatusCodes.Status401Unauthorized)]
+    [TestCase("Forbidden: token 'alice' is not allowed to ClearEvents for tag: bob-game", StatusCodes.Status403Forbidden)]
+    [TestCase("No watch targets found for tag: alice-game", StatusCodes.Status400BadRequest)]
+    [TestCase(null, StatusCodes.Status400BadRequest)]
+    public void GetErrorStatusCode_ShouldMapErrorMessage(string? errorMessage, int expected)
+    {
+        // Arrange
+        using var response = JsonDocument.Parse(errorMessage == null
+            ? """{"Success":false}"""
+            : JsonSerializer.Serialize(new { Success = false, ErrorMessage = errorMessage }));
+
+        // Act & Assert
+        HttpApiEndpoints.GetErrorStatusCode(response.RootElement).Should().Be(expected);
+    }
+
+    private static (IIpcRequestHandler Handler, List<string> Requests) CreateHandler(string responseJson)
+    {
+        var requests = new List<string>();
+        var handler = new Mock<IIpcRequestHandler>();
+        handler.Setup(x => x.HandleRequestAsync(It.IsAny<string>(), It.IsAny<CancellationToken>()))
+            .Callback<string, CancellationToken>((requestJson, _) => requests.Add(requestJson))
+            .ReturnsAsync(responseJson);
+        return (handler.Object, requests);
+    }
+}
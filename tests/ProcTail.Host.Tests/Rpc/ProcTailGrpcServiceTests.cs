@@ -0,0 +1,212 @@
+using System.Text.Json;
+using FluentAssertions;
+using Google.Protobuf.WellKnownTypes;
+using Grpc.Core;
+using Microsoft.Extensions.Diagnostics.HealthChecks;
+using Microsoft.Extensions.Logging;
+using Moq;
+using NUnit.Framework;
+using ProcTail.Application.Services;
+using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
+using ProcTail.Host.Rpc;
+using EventFilter = ProcTail.Host.Rpc.EventFilter;
+
+namespace ProcTail.Host.Tests.Rpc;
+
+[TestFixture]
+[Category("Unit")]
+public class ProcTailGrpcServiceTests
+{
+    private static readonly ClientToken AliceToken = new()
+    {
+        Name = "alice",
+        Token = "alice-secret",
+        Permission = ClientPermission.Watch,
+        Tags = new[] { "alice-*" }
+    };
+
+    private static readonly ClientToken ViewerToken = new() { Name = "viewer", Token = "viewer-secret" };
+
+    [Test]
+    public void Authorize_WithoutConfiguredTokens_ShouldAllowAllOperations()
+    {
+        // Arrange
+        var service = CreateService();
+
+        // Act
+        var client = service.Authorize(new Metadata(), ClientPermission.Admin, "Shutdown", null);
+
+        // Assert
+        client.Should().BeNull();
+    }
+
+    [TestCase(null)]
+    [TestCase("Bearer unknown-secret")]
+    [TestCase("Basic alice-secret")]
+    public void Authorize_WithMissingOrUnknownToken_ShouldThrowUnauthenticated(string? authorization)
+    {
+        // Arrange
+        var service = CreateService(AliceToken);
+
+        // Act
+        var act = () => service.Authorize(CreateHeaders(authorization), ClientPermission.Read, "GetRecordedEvents", "alice-game");
+
+        // Assert
+        var exception = act.Should().Throw<RpcException>().Which;
+        exception.StatusCode.Should().Be(StatusCode.Unauthenticated);
+        exception.Status.Detail.Should().Be(ClientAuthorizer.UnauthorizedMessage);
+    }
+
+    [Test]
+    public void Authorize_WithTagLimitedToken_ShouldAllowOnlyMatchingTagsAndPermission()
+    {
+        // Arrange
+        var service = CreateService(AliceToken, ViewerToken);
+        var headers = CreateHeaders("Bearer alice-secret");
+
+        // Act
+        var client = service.Authorize(headers, ClientPermission.Watch, "AddWatchTarget", "alice-game");
+        var otherTag = () => service.Authorize(headers, ClientPermission.Read, "GetRecordedEvents", "bob-game");
+        var admin = () => service.Authorize(headers, ClientPermission.Admin, "Shutdown", null);
+
+        // Assert
+        client!.Name.Should().Be("alice");
+        var exception = otherTag.Should().Throw<RpcException>().Which;
+        exception.StatusCode.Should().Be(StatusCode.PermissionDenied);
+        exception.Status.Detail.Should().Be("Forbidden: token 'alice' is not allowed to GetRecordedEvents for tag: bob-game");
+        admin.Should().Throw<RpcException>().Which.StatusCode.Should().Be(StatusCode.PermissionDenied);
+    }
+
+    [Test]
+    public void AuthorizeSubscription_WithTagLimitedToken_ShouldRejectAllTagsAndUnmatchedTags()
+    {
+        // Arrange
+        var service = CreateService(AliceToken);
+        var headers = CreateHeaders("Bearer alice-secret");
+
+        // Act
+        var allTags = () => service.AuthorizeSubscription(headers, Array.Empty<string>());
+        var unmatchedTag = () => service.AuthorizeSubscription(headers, new[] { "alice-game", "bob-game" });
+        var matchedTags = () => service.AuthorizeSubscription(headers, new[] { "alice-game", "alice-tool" });
+
+        // Assert
+        allTags.Should().Throw<RpcException>().Which.Status.Detail.Should().Be("Forbidden: token 'alice' is not allowed to Subscribe for tag: *");
+        unmatchedTag.Should().Throw<RpcException>().Which.Status.Detail.Should().EndWith("for tag: bob-game");
+        matchedTags.Should().NotThrow();
+    }
+
+    [Test]
+    public void AuthorizeSubscription_WithUnlimitedToken_ShouldAllowAllTags()
+    {
+        // Arrange
+        var service = CreateService(AliceToken, ViewerToken);
+
+        // Act
+        var act = () => service.AuthorizeSubscription(CreateHeaders("Bearer viewer-secret"), Array.Empty<string>());
+
+        // Assert
+        act.Should().NotThrow();
+    }
+
+    [Test]
+    public void ToEventFilter_ShouldMapAllConditions()
+    {
+        // Arrange
+        var since = new DateTime(2026, 1, 1, 0, 0, 0, DateTimeKind.Utc);
+        var filter = new EventFilter
+        {
+            Since = Timestamp.FromDateTime(since),
+            Query = "type=file.write"
+        };
+        filter.EventTypes.Add("file");
+        filter.EventNames.Add("FileIO/*");
+        filter.PathPatterns.Add("*.sav");
+        filter.ProcessIds.Add(1234);
+
+        // Act
+        var result = ProcTailGrpcService.ToEventFilter(filter);
+
+        // Assert
+        result.Should().NotBeNull();
+        result!.EventTypes.Should().Equal("file");
+        result.EventNames.Should().Equal("FileIO/*");
+        result.PathPatterns.Should().Equal("*.sav");
+        result.ProcessIds.Should().Equal(1234);
+        result.Since.Should().Be(since);
+        result.Until.Should().BeNull();
+        result.Query.Should().Be("type=file.write");
+    }
+
+    [Test]
+    public void ToEventFilter_WithoutFilterOrQuery_ShouldReturnNullValues()
+    {
+        // Act & Assert
+        ProcTailGrpcService.ToEventFilter(null).Should().BeNull();
+        ProcTailGrpcService.ToEventFilter(new EventFilter())!.Query.Should().BeNull();
+    }
+
+    [Test]
+    public void ToRecordedEvent_ShouldCopyCommonFieldsAndPolymorphicJson()
+    {
+        // Arrange
+        var timestamp = new DateTime(2026, 1, 1, 12, 0, 0, DateTimeKind.Utc);
+        var eventData = new FileEventData
+        {
+            Timestamp = timestamp,
+            TagName = "alice-game",
+            SequenceNumber = 42,
+            ProcessId = 1234,
+            ThreadId = 5,
+            ProviderName = "Microsoft-Windows-Kernel-FileIO",
+            EventName = "FileIO/Write",
+            ActivityId = Guid.Empty,
+            RelatedActivityId = Guid.Empty,
+            Payload = new Dictionary<string, object>(),
+            FilePath = @"C:\game\save.sav"
+        };
+
+        // Act
+        var result = ProcTailGrpcService.ToRecordedEvent(eventData);
+
+        // Assert
+        result.Type.Should().Be("file");
+        result.Timestamp.ToDateTime().Should().Be(timestamp);
+        result.TagName.Should().Be("alice-game");
+        result.SequenceNumber.Should().Be(42);
+        result.ProcessId.Should().Be(1234);
+        result.ThreadId.Should().Be(5);
+        result.ProviderName.Should().Be("Microsoft-Windows-Kernel-FileIO");
+        result.EventName.Should().Be("FileIO/Write");
+        JsonSerializer.Deserialize<BaseEventData>(result.Json).Should().BeOfType<FileEventData>()
+            .Which.FilePath.Should().Be(@"C:\game\save.sav");
+    }
+
+    private static Metadata CreateHeaders(string? authorization)
+    {
+        var headers = new Metadata();
+        if (authorization != null)
+        {
+            headers.Add("authorization", authorization);
+        }
+        return headers;
+    }
+
+    private static ProcTailGrpcService CreateService(params ClientToken[] tokens)
+    {
+        var procTailService = new ProcTailService(
+            new Mock<ILogger<ProcTailService>>().Object,
+            new Mock<IEtwEventProvider>().Object,
+            new Mock<IWatchTargetManager>().Object,
+            new Mock<IEventProcessor>().Object,
+            new Mock<IEventStorage>().Object,
+            new Mock<INamedPipeServer>().Object);
+
+        return new ProcTailGrpcService(
+            new Mock<ILogger<ProcTailGrpcService>>().Object,
+            procTailService,
+            new Mock<HealthCheckService>().Object,
+            new EventSubscriptionHub(),
+            new ClientAuthorizer(new Mock<ILogger<ClientAuthorizer>>().Object, tokens));
+    }
+}
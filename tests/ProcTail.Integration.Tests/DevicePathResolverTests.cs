@@ -0,0 +1,81 @@
+using FluentAssertions;
+using NUnit.Framework;
+using ProcTail.Infrastructure.Etw;
+
+namespace ProcTail.Integration.Tests;
+
+[TestFixture]
+[Category("Integration")]
+public class DevicePathResolverTests
+{
+    [Test]
+    public void Resolve_WithVolumeDevicePath_ShouldUseLongestMatchingDevice()
+    {
+        // Arrange
+        var resolver = new DevicePathResolver(() => new Dictionary<string, string>
+        {
+            [@"\Device\HarddiskVolume1"] = "C:",
+            [@"\Device\HarddiskVolume10"] = "E:"
+        }, TimeSpan.FromMinutes(1));
+
+        // Act & Assert
+        resolver.Resolve(@"\Device\HarddiskVolume1\Users\test\file.txt").Should().Be(@"C:\Users\test\file.txt");
+        resolver.Resolve(@"\Device\HarddiskVolume10\data.bin").Should().Be(@"E:\data.bin");
+        resolver.Resolve(@"\Device\HarddiskVolume10").Should().Be(@"E:\");
+    }
+
+    [Test]
+    public void Resolve_WithNonDevicePathOrUnknownDevice_ShouldReturnPathUnchanged()
+    {
+        // Arrange
+        var resolver = new DevicePathResolver(() => new Dictionary<string, string>
+        {
+            [@"\Device\HarddiskVolume3"] = "C:"
+        }, TimeSpan.FromMinutes(1));
+
+        // Act & Assert
+        resolver.Resolve(@"C:\Windows\notepad.exe").Should().Be(@"C:\Windows\notepad.exe");
+        resolver.Resolve(@"\Device\HarddiskVolume4\file.txt").Should().Be(@"\Device\HarddiskVolume4\file.txt");
+        resolver.Resolve(string.Empty).Should().BeEmpty();
+    }
+
+    [Test]
+    public void Resolve_WithMupDevicePath_ShouldReturnUncPath()
+    {
+        // Arrange
+        var resolver = new DevicePathResolver(() => new Dictionary<string, string>(), TimeSpan.FromMinutes(1));
+
+        // Act
+        var result = resolver.Resolve(@"\Device\Mup\server\share\report.docx");
+
+        // Assert
+        result.Should().Be(@"\\server\share\report.docx");
+    }
+
+    [Test]
+    public void Resolve_AfterVolumeRemount_ShouldReloadMappings()
+    {
+        // Arrange
+        var mappings = new Dictionary<string, string>
+        {
+            [@"\Device\HarddiskVolume5"] = "D:"
+        };
+        var loadCount = 0;
+        var resolver = new DevicePathResolver(() =>
+        {
+            loadCount++;
+            return new Dictionary<string, string>(mappings);
+        }, TimeSpan.Zero);
+
+        resolver.Resolve(@"\Device\HarddiskVolume5\file.txt").Should().Be(@"D:\file.txt");
+
+        // Act
+        mappings.Clear();
+        mappings[@"\Device\HarddiskVolume6"] = "D:";
+        var result = resolver.Resolve(@"\Device\HarddiskVolume6\file.txt");
+
+        // Assert
+        result.Should().Be(@"D:\file.txt");
+        loadCount.Should().BeGreaterThan(1);
+    }
+}
@@ -0,0 +1,92 @@
+using System.Collections.Concurrent;
+using FluentAssertions;
+using Microsoft.Extensions.Logging;
+using Moq;
+using NUnit.Framework;
+using ProcTail.Core.Models;
+using ProcTail.Infrastructure.Files;
+
+namespace ProcTail.Integration.Tests;
+
+[TestFixture]
+[Category("Integration")]
+public class DirectoryChangeWatcherTests
+{
+    private string _rootDirectory = null!;
+    private DirectoryChangeWatcher _watcher = null!;
+    private ConcurrentQueue<RawEventData> _events = null!;
+
+    [SetUp]
+    public void Setup()
+    {
+        _rootDirectory = Path.Combine(Path.GetTempPath(), $"proctail-dirwatch-{Guid.NewGuid():N}");
+        Directory.CreateDirectory(_rootDirectory);
+        _events = new ConcurrentQueue<RawEventData>();
+        _watcher = new DirectoryChangeWatcher(new Mock<ILogger<DirectoryChangeWatcher>>().Object);
+        _watcher.EventReceived += (_, rawEvent) => _events.Enqueue(rawEvent);
+    }
+
+    [TearDown]
+    public void TearDown()
+    {
+        _watcher.Dispose();
+        if (Directory.Exists(_rootDirectory))
+        {
+            Directory.Delete(_rootDirectory, recursive: true);
+        }
+    }
+
+    [Test]
+    public async Task Watch_WhenFileIsCreatedAndRenamed_ShouldRaiseEventsForTag()
+    {
+        // Arrange
+        var watchedCount = _watcher.Watch("saves", new[] { _rootDirectory, Path.Combine(_rootDirectory, "missing") });
+        var tempPath = Path.Combine(_rootDirectory, "slot1.tmp");
+        var savePath = Path.Combine(_rootDirectory, "slot1.sav");
+
+        // Act
+        await File.WriteAllTextAsync(tempPath, "data");
+        File.Move(tempPath, savePath);
+        var received = await WaitForEventAsync(rawEvent => rawEvent.EventName == "FileIO/Rename");
+
+        // Assert
+        watchedCount.Should().Be(1);
+        received.Should().BeTrue();
+        _events.Should().OnlyContain(rawEvent => rawEvent.TagName == "saves" && rawEvent.ProcessId == 0);
+        _events.Should().Contain(rawEvent => rawEvent.EventName == "FileIO/Create" && (string)rawEvent.Payload["FileName"] == tempPath);
+        var rename = _events.Single(rawEvent => rawEvent.EventName == "FileIO/Rename");
+        rename.Payload["NewFileName"].Should().Be(savePath);
+        rename.Payload["Source"].Should().Be("DirectoryWatcher");
+    }
+
+    [Test]
+    public async Task Unwatch_ShouldStopRaisingEvents()
+    {
+        // Arrange
+        _watcher.Watch("saves", new[] { _rootDirectory });
+        _watcher.Unwatch("saves");
+
+        // Act
+        await File.WriteAllTextAsync(Path.Combine(_rootDirectory, "slot2.sav"), "data");
+        var received = await WaitForEventAsync(_ => true, TimeSpan.FromMilliseconds(300));
+
+        // Assert
+        received.Should().BeFalse();
+    }
+
+    private async Task<bool> WaitForEventAsync(Func<RawEventData, bool> predicate, TimeSpan? timeout = null)
+    {
+        var deadline = DateTime.UtcNow + (timeout ?? TimeSpan.FromSeconds(5));
+        while (DateTime.UtcNow < deadline)
+        {
+            if (_events.Any(predicate))
+            {
+                return true;
+            }
+
+            await Task.Delay(20);
+        }
+
+        return false;
+    }
+}
@@ -0,0 +1,85 @@
+using FluentAssertions;
+using Microsoft.Extensions.Logging;
+using Moq;
+using NUnit.Framework;
+using ProcTail.Infrastructure.Files;
+
+namespace ProcTail.Integration.Tests;
+
+[TestFixture]
+[Category("Integration")]
+public class DirectoryPollerTests
+{
+    private string _rootDirectory = null!;
+
+    [SetUp]
+    public void Setup()
+    {
+        _rootDirectory = Path.Combine(Path.GetTempPath(), $"proctail-poll-{Guid.NewGuid():N}");
+        Directory.CreateDirectory(Path.Combine(_rootDirectory, "sub"));
+    }
+
+    [TearDown]
+    public void TearDown()
+    {
+        if (Directory.Exists(_rootDirectory))
+        {
+            Directory.Delete(_rootDirectory, recursive: true);
+        }
+    }
+
+    [Test]
+    public void Poll_ShouldReportDifferencesFromPreviousScan()
+    {
+        // Arrange
+        var existingPath = Path.Combine(_rootDirectory, "existing.sav");
+        var deletedPath = Path.Combine(_rootDirectory, "deleted.sav");
+        var createdPath = Path.Combine(_rootDirectory, "sub", "created.sav");
+        File.WriteAllText(existingPath, "data");
+        File.WriteAllText(deletedPath, "data");
+        using var poller = CreatePoller(_rootDirectory);
+
+        // Act
+        var unchanged = poller.Poll();
+        File.WriteAllText(existingPath, "updated data");
+        File.Delete(deletedPath);
+        File.WriteAllText(createdPath, "data");
+        var changes = poller.Poll();
+
+        // Assert
+        unchanged.Should().BeEmpty();
+        changes.Should().BeEquivalentTo(new[]
+        {
+            new DirectoryPollChange("FileIO/Write", existingPath),
+            new DirectoryPollChange("FileIO/Delete", deletedPath),
+            new DirectoryPollChange("FileIO/Create", createdPath)
+        });
+        poller.Poll().Should().BeEmpty();
+    }
+
+    [Test]
+    public void Poll_WhenDirectoryIsMissing_ShouldKeepPreviousSnapshot()
+    {
+        // Arrange
+        var directory = Path.Combine(_rootDirectory, "mount");
+        var filePath = Path.Combine(directory, "save.dat");
+        Directory.CreateDirectory(directory);
+        File.WriteAllText(filePath, "data");
+        using var poller = CreatePoller(directory);
+
+        // Act
+        Directory.Move(directory, directory + ".unmounted");
+        var whileMissing = poller.Poll();
+        Directory.Move(directory + ".unmounted", directory);
+        var afterRemount = poller.Poll();
+
+        // Assert
+        whileMissing.Should().BeEmpty();
+        afterRemount.Should().BeEmpty();
+    }
+
+    private static DirectoryPoller CreatePoller(string directory)
+    {
+        return new DirectoryPoller(directory, TimeSpan.FromHours(1), _ => { }, new Mock<ILogger>().Object);
+    }
+}
@@ -0,0 +1,93 @@
+using FluentAssertions;
+using NUnit.Framework;
+using ProcTail.Infrastructure.Files;
+
+namespace ProcTail.Integration.Tests;
+
+[TestFixture]
+[Category("Integration")]
+public class PathCanonicalizerTests
+{
+    private static readonly Dictionary<string, string[]> Entries = new(StringComparer.OrdinalIgnoreCase)
+    {
+        [@"C:\"] = new[] { "Program Files|PROGRA~1", "Users|USERS" },
+        [@"C:\Program Files"] = new[] { "Common Files|COMMON~1" },
+        [@"C:\Program Files\Common Files"] = new[] { "setup.log|SETUP.LOG" }
+    };
+
+    private int _lookupCount;
+
+    [SetUp]
+    public void Setup()
+    {
+        _lookupCount = 0;
+    }
+
+    [Test]
+    public void Canonicalize_WithShortNamesAndDifferentCase_ShouldReturnLongPath()
+    {
+        // Arrange
+        var canonicalizer = new PathCanonicalizer(FindEntryName);
+
+        // Act
+        var result = canonicalizer.Canonicalize(@"c:\PROGRA~1\common files\Setup.LOG");
+
+        // Assert
+        result.Should().Be(@"C:\Program Files\Common Files\setup.log");
+    }
+
+    [Test]
+    public void Canonicalize_WithMissingLeaf_ShouldKeepLeafAndCanonicalizeParent()
+    {
+        // Arrange
+        var canonicalizer = new PathCanonicalizer(FindEntryName);
+
+        // Act
+        var result = canonicalizer.Canonicalize(@"C:\PROGRA~1\deleted.tmp");
+
+        // Assert
+        result.Should().Be(@"C:\Program Files\deleted.tmp");
+    }
+
+    [Test]
+    public void Canonicalize_WithNonDrivePath_ShouldReturnPathUnchanged()
+    {
+        // Arrange
+        var canonicalizer = new PathCanonicalizer(FindEntryName);
+
+        // Act & Assert
+        canonicalizer.Canonicalize(@"\\server\share\FILE~1.TXT").Should().Be(@"\\server\share\FILE~1.TXT");
+        canonicalizer.Canonicalize("HKLM\\Software").Should().Be("HKLM\\Software");
+        _lookupCount.Should().Be(0);
+    }
+
+    [Test]
+    public void Canonicalize_WithRepeatedDirectory_ShouldUseCache()
+    {
+        // Arrange
+        var canonicalizer = new PathCanonicalizer(FindEntryName);
+        canonicalizer.Canonicalize(@"C:\PROGRA~1\COMMON~1");
+        var lookupsAfterFirst = _lookupCount;
+
+        // Act
+        var result = canonicalizer.Canonicalize(@"c:\program files\common files\SETUP.LOG");
+
+        // Assert
+        result.Should().Be(@"C:\Program Files\Common Files\setup.log");
+        (_lookupCount - lookupsAfterFirst).Should().Be(1);
+    }
+
+    private string? FindEntryName(string directory, string name)
+    {
+        _lookupCount++;
+        if (!Entries.TryGetValue(directory, out var entries))
+        {
+            return null;
+        }
+
+        return entries
+            .Select(entry => entry.Split('|'))
+            .FirstOrDefault(entry => entry.Any(candidate => string.Equals(candidate, name, StringComparison.OrdinalIgnoreCase)))
+            ?[0];
+    }
+}
@@ -0,0 +1,50 @@
+using FluentAssertions;
+using NUnit.Framework;
+using ProcTail.Infrastructure.Etw;
+
+namespace ProcTail.Integration.Tests;
+
+[TestFixture]
+[Category("Integration")]
+public class ProcTailEtwSessionsTests
+{
+    [TestCase("ProcTail_1234_0a1b2c3d", 1234)]
+    [TestCase("PT_FileIO_5678", 5678)]
+    [TestCase("PT_Process_42", 42)]
+    public void TryGetOwnerProcessId_WithProcTailSessionName_ShouldReturnProcessId(string sessionName, int expected)
+    {
+        // Act
+        var result = ProcTailEtwSessions.TryGetOwnerProcessId(sessionName, out var processId);
+
+        // Assert
+        result.Should().BeTrue();
+        processId.Should().Be(expected);
+    }
+
+    [TestCase("NT Kernel Logger")]
+    [TestCase("ProcTail_1234")]
+    [TestCase("ProcTail_1234_0a1b2c3d_extra")]
+    [TestCase("ProcTail_abc_0a1b2c3d")]
+    [TestCase("procTail_1234_0a1b2c3d")]
+    [TestCase("PT_FileIO_")]
+    [TestCase("PT_FileIO_5678_old")]
+    [TestCase("PT_Registry_5678")]
+    public void TryGetOwnerProcessId_WithOtherSessionName_ShouldReturnFalse(string sessionName)
+    {
+        // Act & Assert
+        ProcTailEtwSessions.TryGetOwnerProcessId(sessionName, out _).Should().BeFalse();
+    }
+
+    [Test]
+    public void CreateSessionName_ShouldBeUniqueAndOwnedByCurrentProcess()
+    {
+        // Act
+        var first = ProcTailEtwSessions.CreateSessionName();
+        var second = ProcTailEtwSessions.CreateSessionName();
+
+        // Assert
+        first.Should().NotBe(second);
+        ProcTailEtwSessions.TryGetOwnerProcessId(first, out var processId).Should().BeTrue();
+        processId.Should().Be(Environment.ProcessId);
+    }
+}
@@ -1,6 +1,7 @@
 using FluentAssertions;
 using Microsoft.Extensions.DependencyInjection;
 using Microsoft.Extensions.Logging;
+using Moq;
 using NUnit.Framework;
 using ProcTail.Application.Services;
 using ProcTail.Core.Interfaces;
@@ -305,6 +306,50 @@ public class ProcTailServiceTests
         await _procTailService.StopAsync();
     }
 
+    [Test]
+    public async Task IpcIntegration_GetRecordedEventsRequest_WithCursorAndLimit_ShouldPageWithoutDuplicates()
+    {
+        // Arrange
+        const string tagName = "ipc-cursor-test";
+        const int processId = 9998;
+
+        await _procTailService.StartAsync();
+        await _mockEtwProvider.StopMonitoringAsync();
+        await _procTailService.AddWatchTargetAsync(processId, tagName);
+
+        for (var i = 0; i < 3; i++)
+        {
+            _mockEtwProvider.TriggerFileEvent(processId, $@"C:\cursor-test{i}.txt");
+        }
+        await Task.Delay(100);
+
+        async Task<GetRecordedEventsResponse> GetPageAsync(string? cursor)
+        {
+            var response = await _mockPipeServer.TriggerRequestReceivedAsync(
+                System.Text.Json.JsonSerializer.Serialize(new { RequestType = "GetRecordedEvents", TagName = tagName, Cursor = cursor, Limit = 2 }));
+            return System.Text.Json.JsonSerializer.Deserialize<GetRecordedEventsResponse>(response)!;
+        }
+
+        // Act
+        var first = await GetPageAsync(null);
+
+        // ページの取得中に記録されたイベントも続きとして取得できる
+        _mockEtwProvider.TriggerFileEvent(processId, @"C:\cursor-test3.txt");
+        await Task.Delay(100);
+        var second = await GetPageAsync(first.NextCursor);
+        var third = await GetPageAsync(second.NextCursor);
+
+        // Assert
+        first.Events.Should().HaveCount(2);
+        first.HasMore.Should().BeTrue();
+        second.Events.Should().HaveCount(2);
+        second.HasMore.Should().BeFalse();
+        third.Events.Should().BeEmpty();
+        first.Events.Concat(second.Events).Select(e => e.SequenceNumber).Should().BeInAscendingOrder().And.OnlyHaveUniqueItems();
+
+        await _procTailService.StopAsync();
+    }
+
     [Test]
     public async Task IpcIntegration_GetStatusRequest_ShouldWork()
     {
@@ -332,6 +377,212 @@ public class ProcTailServiceTests
         await _procTailService.StopAsync();
     }
 
+    [Test]
+    public async Task IpcIntegration_GetStatusRequest_ShouldIncludeStartupDiagnostics()
+    {
+        // Arrange
+        var diagnostics = new Mock<IStartupDiagnostics>();
+        diagnostics.Setup(x => x.Run()).Returns(new[]
+        {
+            new DiagnosticCheckResult("SeDebugPrivilege", DiagnosticStatus.Passed, "保持しています（有効）"),
+            new DiagnosticCheckResult("EtwSessionQuota", DiagnosticStatus.Failed, "ETWセッションが上限に達しています (64/64)")
+        });
+
+        using var service = new ProcTailService(
+            _serviceProvider.GetRequiredService<ILogger<ProcTailService>>(),
+            _mockEtwProvider,
+            _serviceProvider.GetRequiredService<IWatchTargetManager>(),
+            _serviceProvider.GetRequiredService<IEventProcessor>(),
+            _serviceProvider.GetRequiredService<IEventStorage>(),
+            _mockPipeServer,
+            diagnostics.Object);
+        await service.StartAsync();
+
+        // Act
+        var response = await _mockPipeServer.TriggerRequestReceivedAsync(
+            System.Text.Json.JsonSerializer.Serialize(new { RequestType = "GetStatus" }));
+
+        // Assert
+        service.Diagnostics.Should().HaveCount(2);
+        var results = System.Text.Json.JsonSerializer.Deserialize<System.Text.Json.JsonElement>(response)
+            .GetProperty("Diagnostics");
+        results.GetArrayLength().Should().Be(2);
+        results[1].GetProperty("Name").GetString().Should().Be("EtwSessionQuota");
+        results[1].GetProperty("Status").GetString().Should().Be("Failed");
+
+        await service.StopAsync();
+    }
+
+    [Test]
+    public async Task IpcIntegration_WithClientTokens_ShouldRejectMissingTokenAndOtherTags()
+    {
+        // Arrange
+        var authorizer = new ClientAuthorizer(
+            _serviceProvider.GetRequiredService<ILogger<ClientAuthorizer>>(),
+            new[] { new ClientToken { Name = "alice", Token = "alice-secret", Permission = ClientPermission.Watch, Tags = new[] { "alice-*" } } });
+
+        using var service = new ProcTailService(
+            _serviceProvider.GetRequiredService<ILogger<ProcTailService>>(),
+            _mockEtwProvider,
+            _serviceProvider.GetRequiredService<IWatchTargetManager>(),
+            _serviceProvider.GetRequiredService<IEventProcessor>(),
+            _serviceProvider.GetRequiredService<IEventStorage>(),
+            _mockPipeServer,
+            clientAuthorizer: authorizer);
+        await service.StartAsync();
+        await service.AddWatchTargetAsync(4441, "alice-game");
+        await service.AddWatchTargetAsync(4442, "bob-game");
+
+        async Task<System.Text.Json.JsonElement> SendAsync(object request)
+        {
+            var response = await _mockPipeServer.TriggerRequestReceivedAsync(System.Text.Json.JsonSerializer.Serialize(request));
+            return System.Text.Json.JsonSerializer.Deserialize<System.Text.Json.JsonElement>(response);
+        }
+
+        // Act
+        var withoutToken = await SendAsync(new { RequestType = "GetWatchTargets" });
+        var watchTargets = await SendAsync(new { RequestType = "GetWatchTargets", AuthToken = "alice-secret" });
+        var otherTag = await SendAsync(new { RequestType = "ClearEvents", TagName = "bob-game", AuthToken = "alice-secret" });
+        var ownTag = await SendAsync(new { RequestType = "ClearEvents", TagName = "alice-game", AuthToken = "alice-secret" });
+        var shutdown = await SendAsync(new { RequestType = "Shutdown", AuthToken = "alice-secret" });
+
+        // Assert
+        withoutToken.GetProperty("Success").GetBoolean().Should().BeFalse();
+        withoutToken.GetProperty("ErrorMessage").GetString().Should().Be(ClientAuthorizer.UnauthorizedMessage);
+        watchTargets.GetProperty("WatchTargets").EnumerateArray()
+            .Select(target => target.GetProperty("TagName").GetString())
+            .Should().Equal("alice-game");
+        otherTag.GetProperty("ErrorMessage").GetString().Should().StartWith(ClientAuthorizer.ForbiddenPrefix);
+        ownTag.GetProperty("Success").GetBoolean().Should().BeTrue();
+        shutdown.GetProperty("ErrorMessage").GetString().Should().StartWith(ClientAuthorizer.ForbiddenPrefix);
+        service.IsRunning.Should().BeTrue();
+
+        await service.StopAsync();
+    }
+
+    [Test]
+    public async Task AddWatchTargetAsync_WithDirectoryWatcherBackend_ShouldRecordDirectoryChangesInsteadOfEtwFileEvents()
+    {
+        // Arrange
+        const string tagName = "network-saves";
+        const int processId = 3333;
+        var directoryWatcher = new Mock<IDirectoryChangeWatcher>();
+        directoryWatcher.Setup(x => x.Watch(tagName, It.IsAny<IReadOnlyList<string>>())).Returns(1);
+
+        using var service = new ProcTailService(
+            _serviceProvider.GetRequiredService<ILogger<ProcTailService>>(),
+            _mockEtwProvider,
+            _serviceProvider.GetRequiredService<IWatchTargetManager>(),
+            _serviceProvider.GetRequiredService<IEventProcessor>(),
+            _serviceProvider.GetRequiredService<IEventStorage>(),
+            _mockPipeServer,
+            directoryWatcher: directoryWatcher.Object);
+        await service.StartAsync();
+
+        // Act
+        var options = new WatchTargetOptions
+        {
+            Backend = FileEventBackend.DirectoryWatcher,
+            WatchDirectories = new[] { @"\\nas\saves" }
+        };
+        var added = await service.AddWatchTargetAsync(processId, tagName, options);
+
+        _mockEtwProvider.TriggerFileEvent(processId, @"C:\local\etw.txt");
+        directoryWatcher.Raise(x => x.EventReceived += null, directoryWatcher.Object, new RawEventData(
+            DateTime.Now,
+            "Microsoft-Windows-Kernel-FileIO",
+            "FileIO/Create",
+            0,
+            0,
+            Guid.Empty,
+            Guid.Empty,
+            new Dictionary<string, object> { ["FileName"] = @"\\nas\saves\slot1.sav", ["Source"] = "DirectoryWatcher" },
+            TagName: tagName));
+        await Task.Delay(100);
+
+        var events = await service.GetRecordedEventsAsync(tagName);
+        await _mockPipeServer.TriggerRequestReceivedAsync(
+            System.Text.Json.JsonSerializer.Serialize(new { RequestType = "RemoveWatchTarget", TagName = tagName }));
+
+        // Assert
+        added.Should().BeTrue();
+        directoryWatcher.Verify(x => x.Watch(tagName, It.Is<IReadOnlyList<string>>(directories => directories.Single() == @"\\nas\saves")), Times.Once);
+        events.OfType<FileEventData>().Should().ContainSingle()
+            .Which.FilePath.Should().Be(@"\\nas\saves\slot1.sav");
+        directoryWatcher.Verify(x => x.Unwatch(tagName), Times.Once);
+
+        await service.StopAsync();
+    }
+
+    [Test]
+    public async Task AlertRule_WhenMatched_ShouldNotifyAlertWithRuleMessage()
+    {
+        // Arrange
+        const string tagName = "notify-test";
+        const int processId = 4444;
+        var alertNotifier = new Mock<IAlertNotifier>();
+        var alertRuleEngine = new AlertRuleEngine(
+            _serviceProvider.GetRequiredService<ILogger<AlertRuleEngine>>(),
+            new[] { new AlertRule { Name = "save-changed", PathPattern = @"C:\Saves\**", Message = "セーブデータが変更されました" } });
+
+        using var service = new ProcTailService(
+            _serviceProvider.GetRequiredService<ILogger<ProcTailService>>(),
+            _mockEtwProvider,
+            _serviceProvider.GetRequiredService<IWatchTargetManager>(),
+            _serviceProvider.GetRequiredService<IEventProcessor>(),
+            _serviceProvider.GetRequiredService<IEventStorage>(),
+            _mockPipeServer,
+            alertRuleEngine: alertRuleEngine,
+            alertNotifier: alertNotifier.Object);
+        await service.StartAsync();
+        await service.AddWatchTargetAsync(processId, tagName);
+
+        // Act
+        _mockEtwProvider.TriggerFileEvent(processId, @"C:\Saves\slot1.sav");
+        await Task.Delay(100);
+
+        // Assert
+        alertNotifier.Verify(x => x.Notify(It.Is<Alert>(alert =>
+            alert.RuleName == "save-changed" && alert.TagName == tagName && alert.Message == "セーブデータが変更されました")), Times.Once);
+
+        await service.StopAsync();
+    }
+
+    [Test]
+    public async Task Subscribe_WhenEventsAreStored_ShouldDeliverOnlySequencedEvents()
+    {
+        // Arrange
+        const string tagName = "subscribe-test";
+        const int processId = 5555;
+        var hub = new EventSubscriptionHub();
+        using var storage = new EventStorage(_serviceProvider.GetRequiredService<ILogger<EventStorage>>(), maxEventsPerTag: 1);
+        using var subscription = hub.Subscribe(new[] { tagName });
+
+        using var service = new ProcTailService(
+            _serviceProvider.GetRequiredService<ILogger<ProcTailService>>(),
+            _mockEtwProvider,
+            _serviceProvider.GetRequiredService<IWatchTargetManager>(),
+            _serviceProvider.GetRequiredService<IEventProcessor>(),
+            storage,
+            _mockPipeServer,
+            eventSubscriptionHub: hub);
+        await service.StartAsync();
+        await service.AddWatchTargetAsync(processId, tagName, new WatchTargetOptions { BackpressurePolicy = BackpressurePolicy.DropNewest });
+
+        // Act - 2件目はバッファが満杯のため破棄される
+        _mockEtwProvider.TriggerFileEvent(processId, @"C:\Saves\slot1.sav");
+        _mockEtwProvider.TriggerFileEvent(processId, @"C:\Saves\slot2.sav");
+        await Task.Delay(100);
+
+        // Assert - 記録したイベントのみ、記録時の連番付きで配信される
+        subscription.Reader.TryRead(out var delivered).Should().BeTrue();
+        delivered.Should().BeOfType<FileEventData>().Which.FilePath.Should().Be(@"C:\Saves\slot1.sav");
+        delivered!.SequenceNumber.Should().Be(1);
+        subscription.Reader.TryRead(out _).Should().BeFalse();
+
+        await service.StopAsync();
+    }
+
     [Test]
     public async Task IpcIntegration_ClearEventsRequest_ShouldWork()
     {
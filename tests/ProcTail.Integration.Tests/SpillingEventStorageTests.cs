@@ -0,0 +1,79 @@
+using FluentAssertions;
+using Microsoft.Extensions.Logging;
+using Moq;
+using NUnit.Framework;
+using ProcTail.Core.Models;
+using ProcTail.Infrastructure.Storage;
+using ProcTail.Testing.Common.Helpers;
+
+namespace ProcTail.Integration.Tests;
+
+[TestFixture]
+[Category("Integration")]
+public class SpillingEventStorageTests
+{
+    private Mock<ILogger<SpillingEventStorage>> _mockLogger = null!;
+    private string _spillDirectory = null!;
+
+    [SetUp]
+    public void Setup()
+    {
+        _mockLogger = new Mock<ILogger<SpillingEventStorage>>();
+        _spillDirectory = Path.Combine(Path.GetTempPath(), $"proctail-spill-{Guid.NewGuid():N}");
+    }
+
+    [TearDown]
+    public void TearDown()
+    {
+        if (Directory.Exists(_spillDirectory))
+        {
+            Directory.Delete(_spillDirectory, recursive: true);
+        }
+    }
+
+    [Test]
+    public async Task StoreEventAsync_ExceedingHotLimit_ShouldSpillOldEventsAndKeepOrder()
+    {
+        // Arrange
+        const string tagName = "test-tag";
+        using var storage = new SpillingEventStorage(_mockLogger.Object, _spillDirectory, maxHotEventsPerTag: 4, segmentEvents: 2);
+
+        // Act
+        for (int i = 0; i < 10; i++)
+        {
+            await storage.StoreEventAsync(tagName, TestEventFactory.CreateFileEvent($@"C:\file{i}.txt", tagName, 1000 + i));
+        }
+
+        // Assert
+        var events = await storage.GetEventsAsync(tagName);
+        events.Select(e => e.ProcessId).Should().Equal(Enumerable.Range(1000, 10));
+        events[0].Should().BeOfType<FileEventData>().Which.FilePath.Should().Be(@"C:\file0.txt");
+        Directory.GetFiles(_spillDirectory).Should().HaveCount(3);
+
+        var statistics = await storage.GetStatisticsAsync();
+        statistics.TotalEvents.Should().Be(10);
+    }
+
+    [Test]
+    public async Task StoreEventAsync_ExceedingDiskBudget_ShouldDropOldestSegments()
+    {
+        // Arrange
+        const string tagName = "test-tag";
+        var eventSize = System.Text.Json.JsonSerializer.Serialize<BaseEventData>(
+            TestEventFactory.CreateFileEvent(@"C:\file0.txt", tagName, 1000)).Length + 1;
+        using var storage = new SpillingEventStorage(_mockLogger.Object, _spillDirectory, maxHotEventsPerTag: 2, maxDiskBytes: eventSize * 2, segmentEvents: 1, compress: false);
+
+        // Act
+        for (int i = 0; i < 6; i++)
+        {
+            await storage.StoreEventAsync(tagName, TestEventFactory.CreateFileEvent($@"C:\file{i}.txt", tagName, 1000 + i));
+        }
+
+        // Assert - ディスクに2件、メモリに2件が残り、最も古い2件は破棄される
+        var events = await storage.GetEventsAsync(tagName);
+        events.Select(e => e.ProcessId).Should().Equal(1002, 1003, 1004, 1005);
+
+        var statistics = await storage.GetStatisticsAsync();
+        statistics.BufferStatisticsByTag![tagName].DroppedCount.Should().Be(2);
+    }
+}
@@ -0,0 +1,101 @@
+using FluentAssertions;
+using Microsoft.Extensions.Logging;
+using Moq;
+using NUnit.Framework;
+using ProcTail.Core.Models;
+using ProcTail.Infrastructure.Storage;
+using ProcTail.Testing.Common.Helpers;
+
+namespace ProcTail.Integration.Tests;
+
+[TestFixture]
+[Category("Integration")]
+public class SqliteEventStorageTests
+{
+    private Mock<ILogger<SqliteEventStorage>> _mockLogger = null!;
+    private string _databasePath = null!;
+
+    [SetUp]
+    public void Setup()
+    {
+        _mockLogger = new Mock<ILogger<SqliteEventStorage>>();
+        _databasePath = Path.Combine(Path.GetTempPath(), $"proctail-{Guid.NewGuid():N}.db");
+    }
+
+    [TearDown]
+    public void TearDown()
+    {
+        Microsoft.Data.Sqlite.SqliteConnection.ClearAllPools();
+        foreach (var path in new[] { _databasePath, _databasePath + "-wal", _databasePath + "-shm" })
+        {
+            if (File.Exists(path))
+            {
+                File.Delete(path);
+            }
+        }
+    }
+
+    [Test]
+    public async Task StoreEventAsync_AfterReopen_ShouldRestoreEventsWithTypes()
+    {
+        // Arrange
+        const string tagName = "game's \"tag\"";
+        var fileEvent = TestEventFactory.CreateFileEvent(@"C:\game\save.dat", tagName, 1000);
+        var processEvent = TestEventFactory.CreateProcessStartEvent(tagName: tagName);
+
+        using (var storage = new SqliteEventStorage(_mockLogger.Object, _databasePath))
+        {
+            await storage.StoreEventAsync(tagName, fileEvent);
+            await storage.StoreEventAsync(tagName, processEvent);
+        }
+
+        // Act - サービス再起動を想定して開き直す
+        using var reopened = new SqliteEventStorage(_mockLogger.Object, _databasePath);
+        var events = await reopened.GetEventsAsync(tagName);
+
+        // Assert
+        events.Should().HaveCount(2);
+        events[0].Should().BeOfType<FileEventData>().Which.FilePath.Should().Be(@"C:\game\save.dat");
+        events[1].Should().BeOfType<ProcessStartEventData>();
+        (await reopened.GetEventCountAsync(tagName)).Should().Be(2);
+        (await reopened.GetAllTagsAsync()).Should().Equal(tagName);
+    }
+
+    [Test]
+    public async Task StoreEventAsync_ExceedingMaxEvents_ShouldTrimOldEventsAndCountDrops()
+    {
+        // Arrange
+        const string tagName = "test-tag";
+        using var storage = new SqliteEventStorage(_mockLogger.Object, _databasePath, maxEventsPerTag: 3);
+
+        // Act
+        for (int i = 0; i < 5; i++)
+        {
+            await storage.StoreEventAsync(tagName, TestEventFactory.CreateFileEvent($@"C:\file{i}.txt", tagName, 1000 + i));
+        }
+
+        // Assert
+        var events = await storage.GetEventsAsync(tagName);
+        events.Select(e => e.ProcessId).Should().Equal(1002, 1003, 1004);
+
+        var statistics = await storage.GetStatisticsAsync();
+        statistics.TotalEvents.Should().Be(3);
+        statistics.BufferStatisticsByTag![tagName].DroppedCount.Should().Be(2);
+    }
+
+    [Test]
+    public async Task ClearEventsAsync_WithExistingEvents_ShouldRemoveTag()
+    {
+        // Arrange
+        const string tagName = "test-tag";
+        using var storage = new SqliteEventStorage(_mockLogger.Object, _databasePath);
+        await storage.StoreEventAsync(tagName, TestEventFactory.CreateFileEvent(tagName: tagName));
+
+        // Act
+        await storage.ClearEventsAsync(tagName);
+
+        // Assert
+        (await storage.GetEventsAsync(tagName)).Should().BeEmpty();
+        (await storage.GetAllTagsAsync()).Should().BeEmpty();
+    }
+}
@@ -0,0 +1,130 @@
+using System.Buffers.Binary;
+using System.Text;
+using FluentAssertions;
+using NUnit.Framework;
+using ProcTail.Infrastructure.Usn;
+
+namespace ProcTail.Integration.Tests;
+
+[TestFixture]
+[Category("Integration")]
+public class UsnChangeTranslatorTests
+{
+    private const ulong SavesDirectory = 0x0001000000000100;
+    private const ulong FileId = 0x0002000000000200;
+    private static readonly DateTime Timestamp = new(2026, 1, 1, 12, 0, 0, DateTimeKind.Local);
+
+    private static string? ResolveDirectory(ulong fileReferenceNumber)
+    {
+        return fileReferenceNumber == SavesDirectory ? @"D:\Saves" : null;
+    }
+
+    [Test]
+    public void Parse_WithV2Records_ShouldReturnRecordsAndNextUsn()
+    {
+        // Arrange
+        var buffer = BuildBuffer(4096,
+            (FileId, SavesDirectory, 1000L, UsnReason.FileCreate | UsnReason.Close, "slot1.sav"),
+            (FileId, SavesDirectory, 1100L, UsnReason.RenameOldName, "slot1.tmp"));
+
+        // Act
+        var records = UsnRecordParser.Parse(buffer, out var nextUsn);
+
+        // Assert
+        nextUsn.Should().Be(4096);
+        records.Should().HaveCount(2);
+        records[0].FileName.Should().Be("slot1.sav");
+        records[0].ParentFileReferenceNumber.Should().Be(SavesDirectory);
+        records[0].Usn.Should().Be(1000);
+        records[0].Timestamp.Should().Be(Timestamp);
+        records[1].HasReason(UsnReason.RenameOldName).Should().BeTrue();
+    }
+
+    [Test]
+    public void Translate_WithRenameRecords_ShouldPairOldAndNewNames()
+    {
+        // Arrange
+        var translator = new UsnChangeTranslator(ResolveDirectory, renameAndDeleteOnly: true);
+
+        // Act
+        var oldName = translator.Translate(CreateRecord(UsnReason.RenameOldName, "slot1.tmp"));
+        var newName = translator.Translate(CreateRecord(UsnReason.RenameNewName, "slot1.sav"));
+        var close = translator.Translate(CreateRecord(UsnReason.RenameOldName | UsnReason.RenameNewName | UsnReason.Close, "slot1.sav"));
+
+        // Assert
+        oldName.Should().BeEmpty();
+        newName.Should().ContainSingle().Which.Should().BeEquivalentTo(new
+        {
+            EventName = "FileIO/Rename",
+            FilePath = @"D:\Saves\slot1.tmp",
+            NewFilePath = @"D:\Saves\slot1.sav"
+        });
+        close.Should().BeEmpty();
+    }
+
+    [Test]
+    public void Translate_WithCloseRecords_ShouldConvertAccumulatedReasonsOnce()
+    {
+        // Arrange
+        var translator = new UsnChangeTranslator(ResolveDirectory, renameAndDeleteOnly: false);
+
+        // Act
+        var intermediate = translator.Translate(CreateRecord(UsnReason.FileCreate | UsnReason.DataExtend, "slot2.sav"));
+        var created = translator.Translate(CreateRecord(UsnReason.FileCreate | UsnReason.DataExtend | UsnReason.Close, "slot2.sav"));
+        var deleted = translator.Translate(CreateRecord(UsnReason.DataOverwrite | UsnReason.FileDelete | UsnReason.Close, "slot2.sav"));
+
+        // Assert
+        intermediate.Should().BeEmpty();
+        created.Select(change => change.EventName).Should().Equal("FileIO/Create", "FileIO/Write");
+        deleted.Should().ContainSingle().Which.EventName.Should().Be("FileIO/Delete");
+    }
+
+    [Test]
+    public void Translate_WithRenameAndDeleteOnly_ShouldSkipCreateAndWrite()
+    {
+        // Arrange
+        var translator = new UsnChangeTranslator(ResolveDirectory, renameAndDeleteOnly: true);
+
+        // Act
+        var written = translator.Translate(CreateRecord(UsnReason.FileCreate | UsnReason.DataExtend | UsnReason.Close, "slot3.sav"));
+        var unknownDirectory = translator.Translate(CreateRecord(UsnReason.FileDelete | UsnReason.Close, "other.sav", parent: 0x99));
+
+        // Assert
+        written.Should().BeEmpty();
+        unknownDirectory.Should().BeEmpty();
+    }
+
+    private static UsnRecord CreateRecord(uint reason, string fileName, ulong parent = SavesDirectory)
+    {
+        return new UsnRecord(FileId, parent, 1000, Timestamp, reason, FileAttributes.Archive, fileName);
+    }
+
+    private static byte[] BuildBuffer(long nextUsn, params (ulong File, ulong Parent, long Usn, uint Reason, string Name)[] records)
+    {
+        using var stream = new MemoryStream();
+        var header = new byte[8];
+        BinaryPrimitives.WriteInt64LittleEndian(header, nextUsn);
+        stream.Write(header);
+
+        foreach (var (file, parent, usn, reason, name) in records)
+        {
+            var nameBytes = Encoding.Unicode.GetBytes(name);
+            var length = (60 + nameBytes.Length + 7) & ~7;
+            var record = new byte[length];
+            BinaryPrimitives.WriteUInt32LittleEndian(record, (uint)length);
+            BinaryPrimitives.WriteUInt16LittleEndian(record.AsSpan(4), 2);
+            BinaryPrimitives.WriteUInt64LittleEndian(record.AsSpan(8), file);
+            BinaryPrimitives.WriteUInt64LittleEndian(record.AsSpan(16), parent);
+            BinaryPrimitives.WriteInt64LittleEndian(record.AsSpan(24), usn);
+            BinaryPrimitives.WriteInt64LittleEndian(record.AsSpan(32), Timestamp.ToFileTime());
+            BinaryPrimitives.WriteUInt32LittleEndian(record.AsSpan(40), reason);
+            BinaryPrimitives.WriteUInt32LittleEndian(record.AsSpan(52), (uint)FileAttributes.Archive);
+            BinaryPrimitives.WriteUInt16LittleEndian(record.AsSpan(56), (ushort)nameBytes.Length);
+            BinaryPrimitives.WriteUInt16LittleEndian(record.AsSpan(58), 60);
+            nameBytes.CopyTo(record.AsSpan(60));
+            stream.Write(record);
+        }
+
+        return stream.ToArray();
+    }
+}
@@ -0,0 +1,60 @@
+using System.Xml.Linq;
+using FluentAssertions;
+using NUnit.Framework;
+using ProcTail.Core.Models;
+using ProcTail.Infrastructure.Notifications;
+
+namespace ProcTail.Integration.Tests;
+
+[TestFixture]
+[Category("Integration")]
+public class WindowsToastNotifierTests
+{
+    [Test]
+    public void BuildToastXml_ShouldShowRuleMessageAndEscapedEventDetails()
+    {
+        // Arrange
+        var alert = CreateAlert(@"D:\Saves\<slot1> & 'backup'.sav", message: "セーブデータが変更されました", matchCount: 3);
+
+        // Act
+        var texts = XDocument.Parse(WindowsToastNotifier.BuildToastXml(alert)).Descendants("text").Select(text => text.Value).ToList();
+
+        // Assert
+        texts.Should().Equal(
+            "セーブデータが変更されました",
+            @"[game] FileIO/Write D:\Saves\<slot1> & 'backup'.sav (3件)",
+            "ProcTail");
+    }
+
+    [Test]
+    public void BuildToastXml_WithoutMessage_ShouldShowRuleName()
+    {
+        // Arrange
+        var alert = CreateAlert(@"D:\Saves\slot1.sav");
+
+        // Act
+        var texts = XDocument.Parse(WindowsToastNotifier.BuildToastXml(alert)).Descendants("text").Select(text => text.Value).ToList();
+
+        // Assert
+        texts.First().Should().Be("save-changed");
+        texts.Should().Contain(@"[game] FileIO/Write D:\Saves\slot1.sav");
+    }
+
+    private static Alert CreateAlert(string filePath, string? message = null, int matchCount = 1)
+    {
+        var fileEvent = new FileEventData
+        {
+            Timestamp = DateTime.UtcNow,
+            TagName = "game",
+            ProcessId = 1234,
+            ThreadId = 5678,
+            ProviderName = "Microsoft-Windows-Kernel-FileIO",
+            EventName = "FileIO/Write",
+            ActivityId = Guid.Empty,
+            RelatedActivityId = Guid.Empty,
+            Payload = new Dictionary<string, object>(),
+            FilePath = filePath
+        };
+        return new Alert(1, "save-changed", "game", fileEvent.Timestamp, matchCount, fileEvent, message);
+    }
+}
@@ -0,0 +1,70 @@
+using FluentAssertions;
+using NUnit.Framework;
+using ProcTail.Infrastructure.Etw;
+
+namespace ProcTail.System.Tests.Infrastructure;
+
+/// <summary>
+/// Application Error イベントパーサーのテスト
+/// ETWセッションを使用しないため全プラットフォームで実行可能
+/// </summary>
+[TestFixture]
+[Category("System")]
+public class ApplicationErrorEventParserTests
+{
+    private static readonly DateTime Now = new(2024, 1, 1, 12, 0, 0);
+
+    private static object?[] Values(string exceptionCode = "c0000005", string processId = "0x4d2")
+    {
+        return new object?[]
+        {
+            "game.exe", "1.2.0.0", "65a1b2c3",
+            "render.dll", "10.0.1.0", "65a1b2c4",
+            exceptionCode, "0000000000001a2b", processId, "0x1da3c4d5e6f7a8b",
+            @"C:\Games\game.exe", @"C:\Games\render.dll", "5f0c8b2e-1d3a-4c7b-9e21-0a1b2c3d4e5f", "", ""
+        };
+    }
+
+    [Test]
+    public void Parse_ApplicationCrash_ShouldUseCrashedProcessIdAndPayload()
+    {
+        // Act
+        var result = ApplicationErrorEventParser.Parse(Values(), Now, threadId: 77);
+
+        // Assert
+        result.Should().NotBeNull();
+        result!.ProviderName.Should().Be("Application Error");
+        result.EventName.Should().Be("Crash/Detected");
+        result.ProcessId.Should().Be(1234);
+        result.ThreadId.Should().Be(77);
+        result.Payload["ExceptionCode"].Should().Be(0xC0000005u);
+        result.Payload["ApplicationName"].Should().Be("game.exe");
+        result.Payload["FaultingModuleName"].Should().Be("render.dll");
+        result.Payload["FaultOffset"].Should().Be(0x1a2bUL);
+        result.Payload["ApplicationPath"].Should().Be(@"C:\Games\game.exe");
+        result.Payload["FaultingModulePath"].Should().Be(@"C:\Games\render.dll");
+        result.Payload["ReportId"].Should().Be("5f0c8b2e-1d3a-4c7b-9e21-0a1b2c3d4e5f");
+    }
+
+    [TestCase("0x0")]
+    [TestCase("not-a-pid")]
+    public void Parse_WithoutCrashedProcessId_ShouldReturnNull(string processId)
+    {
+        // Act
+        var result = ApplicationErrorEventParser.Parse(Values(processId: processId), Now);
+
+        // Assert
+        result.Should().BeNull();
+    }
+
+    [Test]
+    public void Parse_WithShortPayload_ShouldOmitOptionalFields()
+    {
+        // Act
+        var result = ApplicationErrorEventParser.Parse(Values().Take(9).ToArray(), Now);
+
+        // Assert
+        result.Should().NotBeNull();
+        result!.Payload.Should().NotContainKeys("ApplicationPath", "FaultingModulePath", "ReportId");
+    }
+}
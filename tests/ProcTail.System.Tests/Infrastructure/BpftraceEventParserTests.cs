@@ -0,0 +1,157 @@
+using FluentAssertions;
+using NUnit.Framework;
+using ProcTail.Infrastructure.Ebpf;
+
+namespace ProcTail.System.Tests.Infrastructure;
+
+/// <summary>
+/// bpftrace出力パーサーのテスト
+/// /procを参照しないため全プラットフォームで実行可能
+/// </summary>
+[TestFixture]
+[Category("System")]
+public class BpftraceEventParserTests
+{
+    private static readonly DateTime Now = new(2024, 1, 1, 12, 0, 0);
+
+    private BpftraceEventParser _parser = null!;
+
+    [SetUp]
+    public void Setup()
+    {
+        _parser = new BpftraceEventParser((_, _) => null, _ => "/home/user");
+    }
+
+    [Test]
+    public void Parse_OpenThenWriteThenClose_ShouldResolvePathFromFileDescriptor()
+    {
+        // Act
+        var open = _parser.Parse("open 100 101 3 -100 577 /tmp/out.txt", Now);
+        var write = _parser.Parse("write 100 101 3 42", Now);
+        var close = _parser.Parse("close 100 101 3", Now);
+
+        // Assert
+        open.Should().NotBeNull();
+        open!.ProviderName.Should().Be("Microsoft-Windows-Kernel-FileIO");
+        open.EventName.Should().Be("FileIO/Create");
+        open.ProcessId.Should().Be(100);
+        open.ThreadId.Should().Be(101);
+        open.Payload["FileName"].Should().Be("/tmp/out.txt");
+
+        write.Should().NotBeNull();
+        write!.EventName.Should().Be("FileIO/Write");
+        write.Payload["FileName"].Should().Be("/tmp/out.txt");
+        write.Payload["IoSize"].Should().Be(42L);
+
+        close.Should().NotBeNull();
+        close!.EventName.Should().Be("FileIO/Close");
+        close.Payload["FileName"].Should().Be("/tmp/out.txt");
+        _parser.OpenFileCount.Should().Be(0);
+    }
+
+    [Test]
+    public void Parse_OpenWithRelativePath_ShouldResolveAgainstCwd()
+    {
+        // Act
+        var open = _parser.Parse("open 100 100 3 -100 0 docs/my file.txt", Now);
+
+        // Assert
+        open!.Payload["FileName"].Should().Be("/home/user/docs/my file.txt");
+    }
+
+    [Test]
+    public void Parse_WriteToUntrackedNonFileDescriptor_ShouldReturnNull()
+    {
+        // Act & Assert
+        _parser.Parse("write 100 100 1 10", Now).Should().BeNull();
+        _parser.Parse("close 100 100 1", Now).Should().BeNull();
+    }
+
+    [Test]
+    public void Parse_Rename_ShouldIncludeOldAndNewNames()
+    {
+        // Act
+        var rename = _parser.Parse("rename 100 100 -100 -100 /tmp/a.txt\t/tmp/b c.txt", Now);
+
+        // Assert
+        rename!.EventName.Should().Be("FileIO/Rename");
+        rename.Payload["FileName"].Should().Be("/tmp/a.txt");
+        rename.Payload["NewFileName"].Should().Be("/tmp/b c.txt");
+    }
+
+    [Test]
+    public void Parse_Unlink_ShouldCreateDeleteEvent()
+    {
+        // Act
+        var delete = _parser.Parse("unlink 100 100 -100 old.log", Now);
+
+        // Assert
+        delete!.EventName.Should().Be("FileIO/Delete");
+        delete.Payload["FileName"].Should().Be("/home/user/old.log");
+    }
+
+    [Test]
+    public void Parse_Fork_ShouldCreateProcessStartWithChildInPayload()
+    {
+        // Act
+        var start = _parser.Parse("fork 100 100 200 bash", Now);
+
+        // Assert
+        start!.ProviderName.Should().Be("Microsoft-Windows-Kernel-Process");
+        start.EventName.Should().Be("Process/Start");
+        start.ProcessId.Should().Be(100);
+        start.Payload["ProcessId"].Should().Be(200);
+        start.Payload["ParentId"].Should().Be(100);
+        start.Payload["CurrentDirectory"].Should().Be("/home/user");
+    }
+
+    [Test]
+    public void Parse_Fork_ShouldInheritOpenFileDescriptors()
+    {
+        // Arrange
+        _parser.Parse("open 100 100 5 -100 1 /tmp/shared.txt", Now);
+        _parser.Parse("fork 100 100 200 sh", Now);
+
+        // Act
+        var write = _parser.Parse("write 200 200 5 8", Now);
+
+        // Assert
+        write!.Payload["FileName"].Should().Be("/tmp/shared.txt");
+    }
+
+    [TestCase(0L, 0)]
+    [TestCase(3L << 8, 3)]
+    [TestCase(9L, 137)]
+    [TestCase(15L, 143)]
+    public void Parse_Exit_ShouldDecodeExitStatus(long status, int expected)
+    {
+        // Act
+        var end = _parser.Parse($"exit 100 100 {status}", Now);
+
+        // Assert
+        end!.EventName.Should().Be("Process/End");
+        end.Payload["ExitStatus"].Should().Be(expected);
+    }
+
+    [Test]
+    public void Parse_ExitBySignal_ShouldRecordTerminationSignal()
+    {
+        // Act
+        var killed = _parser.Parse("exit 100 100 9", Now);
+        var exited = _parser.Parse($"exit 101 101 {137L << 8}", Now);
+
+        // Assert（exit(137) はシグナルによる終了と区別する）
+        killed!.Payload["TerminationSignal"].Should().Be(9);
+        exited!.Payload.Should().NotContainKey("TerminationSignal");
+    }
+
+    [TestCase("")]
+    [TestCase("Attaching 12 probes...")]
+    [TestCase("open x y")]
+    [TestCase("unknown 1 2 3")]
+    public void Parse_UnrecognizedLine_ShouldReturnNull(string line)
+    {
+        // Act & Assert
+        _parser.Parse(line, Now).Should().BeNull();
+    }
+}
@@ -0,0 +1,118 @@
+using FluentAssertions;
+using NUnit.Framework;
+using ProcTail.Infrastructure.EndpointSecurity;
+
+namespace ProcTail.System.Tests.Infrastructure;
+
+/// <summary>
+/// eslogger出力パーサーのテスト
+/// esloggerを起動しないため全プラットフォームで実行可能
+/// </summary>
+[TestFixture]
+[Category("System")]
+public class EsloggerEventParserTests
+{
+    private static readonly DateTime Now = new(2024, 1, 1, 12, 0, 0);
+
+    private EsloggerEventParser _parser = null!;
+
+    [SetUp]
+    public void Setup()
+    {
+        _parser = new EsloggerEventParser();
+    }
+
+    private static string Message(string eventJson, int pid = 100)
+    {
+        return "{\"process\":{\"audit_token\":{\"pid\":" + pid + "},\"executable\":{\"path\":\"/usr/bin/python3\"}}," +
+               "\"thread\":{\"thread_id\":555},\"event\":" + eventJson + "}";
+    }
+
+    [Test]
+    public void Parse_Open_ShouldCreateFileCreateEvent()
+    {
+        // Act
+        var open = _parser.Parse(Message("""{"open":{"fflag":3,"file":{"path":"/tmp/out.txt"}}}"""), Now);
+
+        // Assert
+        open.Should().NotBeNull();
+        open!.ProviderName.Should().Be("Microsoft-Windows-Kernel-FileIO");
+        open.EventName.Should().Be("FileIO/Create");
+        open.ProcessId.Should().Be(100);
+        open.ThreadId.Should().Be(555);
+        open.Payload["FileName"].Should().Be("/tmp/out.txt");
+        open.Payload["CreateOptions"].Should().Be(3);
+    }
+
+    [TestCase("write", "FileIO/Write")]
+    [TestCase("unlink", "FileIO/Delete")]
+    [TestCase("close", "FileIO/Close")]
+    public void Parse_TargetFileEvents_ShouldMapToEtwEventNames(string esEvent, string expected)
+    {
+        // Act
+        var result = _parser.Parse(Message("{\"" + esEvent + "\":{\"target\":{\"path\":\"/tmp/a.txt\"}}}"), Now);
+
+        // Assert
+        result!.EventName.Should().Be(expected);
+        result.Payload["FileName"].Should().Be("/tmp/a.txt");
+    }
+
+    [Test]
+    public void Parse_RenameToNewPath_ShouldCombineDirectoryAndFileName()
+    {
+        // Act
+        var rename = _parser.Parse(Message("""{"rename":{"source":{"path":"/tmp/a.txt"},"destination_type":1,"destination":{"new_path":{"dir":{"path":"/tmp/sub"},"filename":"b.txt"}}}}"""), Now);
+
+        // Assert
+        rename!.EventName.Should().Be("FileIO/Rename");
+        rename.Payload["FileName"].Should().Be("/tmp/a.txt");
+        rename.Payload["NewFileName"].Should().Be("/tmp/sub/b.txt");
+    }
+
+    [Test]
+    public void Parse_RenameOverExistingFile_ShouldUseExistingFilePath()
+    {
+        // Act
+        var rename = _parser.Parse(Message("""{"rename":{"source":{"path":"/tmp/a.txt"},"destination_type":0,"destination":{"existing_file":{"path":"/tmp/b.txt"}}}}"""), Now);
+
+        // Assert
+        rename!.Payload["NewFileName"].Should().Be("/tmp/b.txt");
+    }
+
+    [Test]
+    public void Parse_Fork_ShouldCreateProcessStartWithChildInPayload()
+    {
+        // Act
+        var start = _parser.Parse(Message("""{"fork":{"child":{"audit_token":{"pid":200},"executable":{"path":"/bin/sh"},"cwd":{"path":"/Users/me"}}}}"""), Now);
+
+        // Assert
+        start!.ProviderName.Should().Be("Microsoft-Windows-Kernel-Process");
+        start.EventName.Should().Be("Process/Start");
+        start.Payload["ProcessId"].Should().Be(200);
+        start.Payload["ParentId"].Should().Be(100);
+        start.Payload["ImageFileName"].Should().Be("sh");
+        start.Payload["CurrentDirectory"].Should().Be("/Users/me");
+    }
+
+    [TestCase(0, 0)]
+    [TestCase(3 << 8, 3)]
+    [TestCase(9, 137)]
+    public void Parse_Exit_ShouldDecodeExitStatus(int stat, int expected)
+    {
+        // Act
+        var end = _parser.Parse(Message("{\"exit\":{\"stat\":" + stat + "}}"), Now);
+
+        // Assert
+        end!.EventName.Should().Be("Process/End");
+        end.Payload["ExitStatus"].Should().Be(expected);
+    }
+
+    [TestCase("")]
+    [TestCase("not json")]
+    [TestCase("{\"event\":{\"open\":{}}}")]
+    public void Parse_UnrecognizedLine_ShouldReturnNull(string line)
+    {
+        // Act & Assert
+        _parser.Parse(line, Now).Should().BeNull();
+    }
+}
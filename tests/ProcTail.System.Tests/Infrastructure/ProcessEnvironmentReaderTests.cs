@@ -0,0 +1,46 @@
+using FluentAssertions;
+using Microsoft.Extensions.Logging;
+using NUnit.Framework;
+using ProcTail.Infrastructure.Processes;
+
+namespace ProcTail.System.Tests.Infrastructure;
+
+/// <summary>
+/// 環境変数リーダーのシステムテスト
+/// 自プロセスの環境変数ブロックを実際に読み取る
+/// </summary>
+[TestFixture]
+[Category("System")]
+public class ProcessEnvironmentReaderTests
+{
+    private ProcessEnvironmentReader _reader = null!;
+
+    [SetUp]
+    public void Setup()
+    {
+        var logger = LoggerFactory.Create(builder => builder.AddConsole()).CreateLogger<ProcessEnvironmentReader>();
+        _reader = new ProcessEnvironmentReader(logger);
+    }
+
+    [Test]
+    public void ReadEnvironment_OwnProcess_ShouldReturnOnlyAllowedVariables()
+    {
+        // Act
+        var environment = _reader.ReadEnvironment(Environment.ProcessId, new[] { "PATH", "PROCTAIL_UNDEFINED_VARIABLE" });
+
+        // Assert
+        environment.Should().ContainKey("PATH");
+        environment["PATH"].Should().Be(Environment.GetEnvironmentVariable("PATH"));
+        environment.Should().NotContainKey("PROCTAIL_UNDEFINED_VARIABLE");
+    }
+
+    [Test]
+    public void ReadEnvironment_NonExistentProcess_ShouldReturnEmpty()
+    {
+        // Act
+        var environment = _reader.ReadEnvironment(int.MaxValue, new[] { "PATH" });
+
+        // Assert
+        environment.Should().BeEmpty();
+    }
+}
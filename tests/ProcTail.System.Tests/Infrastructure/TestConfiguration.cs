@@ -1,5 +1,6 @@
 using Microsoft.Extensions.Logging;
 using ProcTail.Core.Interfaces;
+using ProcTail.Core.Models;
 using ProcTail.Infrastructure.Configuration;
 
 namespace ProcTail.System.Tests.Infrastructure;
@@ -35,6 +36,11 @@ public class TestEtwConfiguration : IEtwConfiguration
         "Process/Start", "Process/End", "Process/Stop"
     }.AsReadOnly();
     
+    public IReadOnlyList<string> KernelKeywords { get; set; } = Array.Empty<string>();
+    public IReadOnlyDictionary<string, EtwProviderSettings> ProviderSettings { get; set; } = new Dictionary<string, EtwProviderSettings>();
+    public bool EnableRawPassthrough { get; set; }
+    public IReadOnlyList<string> RawPassthroughProviders { get; set; } = Array.Empty<string>();
+
     public TimeSpan EventBufferTimeout { get; set; } = TimeSpan.FromSeconds(5);
     public int BufferSizeMB { get; set; } = 64;
     public int BufferCount { get; set; } = 32;
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"proctail-test-process/i18n"
+	"proctail-test-process/operations"
+	"strings"
+)
+
+// ControlServer accepts line-based commands from an orchestrator and applies them to a gate.
+//
+// Commands: start, pause, resume, trigger, status, ready. Each command is answered with
+// "ok <state>" or "error <message>". "ready" blocks until setup has finished.
+type ControlServer struct {
+	listener net.Listener
+	network  string
+	address  string
+	gate     *operations.Gate
+	verbose  bool
+	ready    chan struct{}
+}
+
+// StartControlServer listens on spec ("unix:/tmp/tp.sock" or "tcp:127.0.0.1:9000")
+func StartControlServer(spec string, gate *operations.Gate, verbose bool) (*ControlServer, error) {
+	network, address, ok := strings.Cut(spec, ":")
+	if !ok || address == "" {
+		return nil, fmt.Errorf(i18n.T("制御チャネルの形式が不正です (unix:PATH または tcp:ADDR): %s"), spec)
+	}
+
+	switch network {
+	case "unix":
+		// Remove a stale socket left by a previous run
+		os.Remove(address)
+	case "tcp":
+	case "pipe", "npipe":
+		// Named pipes would need a Windows-only server; unix sockets also work on Windows 10+
+		return nil, fmt.Errorf(i18n.T("名前付きパイプの制御チャネルには未対応です: %s (unix:PATH または tcp:ADDR を使用してください)"), spec)
+	default:
+		return nil, fmt.Errorf(i18n.T("不明な制御チャネル種別: %s (unix:PATH または tcp:ADDR)"), network)
+	}
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("制御チャネル待ち受けエラー %s: %w"), spec, err)
+	}
+
+	s := &ControlServer{
+		listener: listener,
+		network:  network,
+		address:  address,
+		gate:     gate,
+		verbose:  verbose,
+		ready:    make(chan struct{}),
+	}
+	go s.acceptLoop()
+	return s, nil
+}
+
+// MarkReady releases clients waiting on the "ready" command
+func (s *ControlServer) MarkReady() {
+	close(s.ready)
+}
+
+// Close stops accepting commands and removes the unix socket file
+func (s *ControlServer) Close() error {
+	err := s.listener.Close()
+	if s.network == "unix" {
+		os.Remove(s.address)
+	}
+	return err
+}
+
+func (s *ControlServer) acceptLoop() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *ControlServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		command := strings.TrimSpace(scanner.Text())
+		if command == "" {
+			continue
+		}
+		if s.verbose {
+			slog.Info(i18n.T("制御コマンド受信"), "command", command)
+		}
+
+		switch command {
+		case "start", "resume":
+			s.gate.Start()
+		case "pause":
+			s.gate.Pause()
+		case "trigger":
+			s.gate.Trigger()
+		case "status":
+		case "ready":
+			<-s.ready
+			fmt.Fprintf(conn, "ok ready\n")
+			continue
+		default:
+			fmt.Fprintf(conn, "error unknown command: %s\n", command)
+			continue
+		}
+		fmt.Fprintf(conn, "ok %s\n", s.state())
+	}
+}
+
+func (s *ControlServer) state() string {
+	if s.gate.Running() {
+		return "running"
+	}
+	return "paused"
+}
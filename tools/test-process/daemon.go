@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"proctail-test-process/i18n"
+	"proctail-test-process/schema"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultPipe        = `\\.\pipe\ProcTail`
+	errorPipeBusy      = syscall.Errno(231) // ERROR_PIPE_BUSY
+	maxDaemonMessage   = 10 * 1024 * 1024   // Same limit as ProcTail.Cli
+	daemonConnectRetry = 50 * time.Millisecond
+)
+
+// DaemonClient talks to the ProcTail daemon over its named pipe using the
+// length-prefixed JSON protocol of ProcTail.Cli. Each request uses its own connection.
+type DaemonClient struct {
+	path           string
+	connectTimeout time.Duration
+}
+
+type daemonResponse struct {
+	Success      bool   `json:"Success"`
+	ErrorMessage string `json:"ErrorMessage"`
+}
+
+// NewDaemonClient accepts a full pipe path (\\.\pipe\ProcTail) or a bare pipe name
+func NewDaemonClient(pipe string) *DaemonClient {
+	if !strings.HasPrefix(pipe, `\\`) {
+		pipe = `\\.\pipe\` + pipe
+	}
+	return &DaemonClient{path: pipe, connectTimeout: 10 * time.Second}
+}
+
+// AddWatchTarget asks the daemon to watch pid under tag. The daemon drops
+// file reads unless the tag sets a sample rate; 1 records every read.
+func (c *DaemonClient) AddWatchTarget(pid int, tag string, readSampleRate int) error {
+	req := map[string]interface{}{
+		"RequestType": "AddWatchTarget",
+		"ProcessId":   pid,
+		"TagName":     tag,
+	}
+	if readSampleRate > 0 {
+		req["Options"] = map[string]interface{}{"FileReadSampleRate": readSampleRate}
+	}
+	var resp daemonResponse
+	return c.request(req, &resp, &resp)
+}
+
+// RemoveWatchTarget asks the daemon to stop watching tag
+func (c *DaemonClient) RemoveWatchTarget(tag string) error {
+	var resp daemonResponse
+	return c.request(map[string]interface{}{
+		"RequestType": "RemoveWatchTarget",
+		"TagName":     tag,
+	}, &resp, &resp)
+}
+
+// GetRecordedEvents returns the events the daemon recorded for tag
+func (c *DaemonClient) GetRecordedEvents(tag string) ([]schema.DaemonEvent, error) {
+	var resp struct {
+		daemonResponse
+		Events []schema.DaemonEvent `json:"Events"`
+	}
+	err := c.request(map[string]interface{}{
+		"RequestType": "GetRecordedEvents",
+		"TagName":     tag,
+	}, &resp, &resp.daemonResponse)
+	return resp.Events, err
+}
+
+// request sends req and decodes the reply into resp; status is the embedded
+// Success/ErrorMessage part of resp
+func (c *DaemonClient) request(req interface{}, resp interface{}, status *daemonResponse) error {
+	conn, err := c.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	header := make([]byte, 4)
+	binary.LittleEndian.PutUint32(header, uint32(len(body)))
+	if _, err := conn.Write(append(header, body...)); err != nil {
+		return fmt.Errorf(i18n.T("デーモンへの要求送信エラー: %w"), err)
+	}
+
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return fmt.Errorf(i18n.T("デーモン応答の長さ受信エラー: %w"), err)
+	}
+	length := int32(binary.LittleEndian.Uint32(header))
+	if length <= 0 || length > maxDaemonMessage {
+		return fmt.Errorf(i18n.T("無効なデーモン応答の長さ: %d"), length)
+	}
+	reply := make([]byte, length)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf(i18n.T("デーモン応答受信エラー: %w"), err)
+	}
+
+	if err := json.Unmarshal(reply, resp); err != nil {
+		return fmt.Errorf(i18n.T("デーモン応答解析エラー: %w"), err)
+	}
+	if !status.Success {
+		return fmt.Errorf(i18n.T("デーモンがエラーを返しました: %s"), status.ErrorMessage)
+	}
+	return nil
+}
+
+// connect opens the pipe, retrying while all server instances are busy
+func (c *DaemonClient) connect() (*os.File, error) {
+	deadline := time.Now().Add(c.connectTimeout)
+	for {
+		conn, err := os.OpenFile(c.path, os.O_RDWR, 0)
+		if err == nil {
+			return conn, nil
+		}
+		if !errors.Is(err, errorPipeBusy) || time.Now().After(deadline) {
+			return nil, fmt.Errorf(i18n.T("デーモンに接続できません (%s): %w"), c.path, err)
+		}
+		time.Sleep(daemonConnectRetry)
+	}
+}
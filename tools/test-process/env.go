@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"proctail-test-process/i18n"
+	"proctail-test-process/operations"
+	"strings"
+)
+
+// envPrefix prefixes the environment variables that provide flag defaults;
+// self-spawned children are started without them
+const envPrefix = operations.EnvPrefix
+
+// envName is the environment variable for a flag, e.g. fail-threshold ->
+// PROCTAIL_TEST_FAIL_THRESHOLD
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// applyEnvDefaults sets every flag in fs from its environment variable, if
+// present. Call it before fs.Parse so that the command line still wins.
+func applyEnvDefaults(fs *flag.FlagSet) error {
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		value, ok := os.LookupEnv(envName(f.Name))
+		if !ok || err != nil {
+			return
+		}
+		if setErr := f.Value.Set(value); setErr != nil {
+			err = fmt.Errorf(i18n.T("環境変数 %s の値が不正です: %w"), envName(f.Name), setErr)
+		}
+	})
+	return err
+}
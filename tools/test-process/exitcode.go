@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"proctail-test-process/operations"
+)
+
+// Exit codes returned by test-process
+const (
+	ExitSuccess           = 0   // All operations succeeded (or failures within --fail-threshold)
+	ExitConfigError       = 2   // Invalid flags, operation name, platform or environment setup
+	ExitPartialFailure    = 3   // Failure rate exceeded --fail-threshold
+	ExitTotalFailure      = 4   // Every operation failed or the operation aborted
+	ExitValidationFailure = 5   // --validate found operations the daemon did not record
+	ExitSignal            = 130 // Interrupted by SIGINT/SIGTERM (after cleanup and the report)
+)
+
+// exitConfigError logs msg with the slog attrs in args and exits with ExitConfigError
+func exitConfigError(msg string, args ...interface{}) {
+	slog.Error(msg, args...)
+	os.Exit(ExitConfigError)
+}
+
+// exitCodeFor decides the exit code from the report and the operation error.
+// threshold is the tolerated failure percentage (0-100).
+func exitCodeFor(report *Report, err error, threshold float64) int {
+	if errors.Is(err, operations.ErrUnsupportedPlatform) {
+		return ExitConfigError
+	}
+	if err != nil {
+		return ExitTotalFailure
+	}
+
+	attempted := report.SuccessOps + report.FailedOps
+	if report.FailedOps > 0 && attempted > 0 {
+		if report.SuccessOps == 0 {
+			return ExitTotalFailure
+		}
+		failureRate := float64(report.FailedOps) / float64(attempted) * 100
+		if failureRate > threshold {
+			return ExitPartialFailure
+		}
+	}
+
+	if report.Validation != nil && report.Validation.Missing > 0 {
+		return ExitValidationFailure
+	}
+	return ExitSuccess
+}
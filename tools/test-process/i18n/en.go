@@ -0,0 +1,305 @@
+package i18n
+
+// en holds the English messages
+var en = map[string]string{
+	// Control channel and daemon client
+	"制御チャネルの形式が不正です (unix:PATH または tcp:ADDR): %s":                  "invalid control channel (unix:PATH or tcp:ADDR): %s",
+	"名前付きパイプの制御チャネルには未対応です: %s (unix:PATH または tcp:ADDR を使用してください)": "named pipe control channels are not supported: %s (use unix:PATH or tcp:ADDR)",
+	"不明な制御チャネル種別: %s (unix:PATH または tcp:ADDR)":                     "unknown control channel type: %s (unix:PATH or tcp:ADDR)",
+	"制御チャネル待ち受けエラー %s: %w":                                         "control channel listen error %s: %w",
+	"制御コマンド受信":                        "control command received",
+	"メトリクスのアドレスが不正です (HOST:PORT): %s": "invalid metrics address (HOST:PORT): %s",
+	"メトリクス待ち受けエラー %s: %w":             "metrics listen error %s: %w",
+	"メトリクス配信エラー":                      "metrics server error",
+	"デーモンへの要求送信エラー: %w":               "error sending request to daemon: %w",
+	"デーモン応答の長さ受信エラー: %w":              "error reading daemon response length: %w",
+	"無効なデーモン応答の長さ: %d":                "invalid daemon response length: %d",
+	"デーモン応答受信エラー: %w":                 "error reading daemon response: %w",
+	"デーモン応答解析エラー: %w":                 "error parsing daemon response: %w",
+	"デーモンがエラーを返しました: %s":              "daemon returned an error: %s",
+	"デーモンに接続できません (%s): %w":           "cannot connect to daemon (%s): %w",
+	"環境変数 %s の値が不正です: %w":             "invalid value in environment variable %s: %w",
+	"シグナル受信により中断":                     "interrupted by signal",
+
+	// Usage
+	"使用方法: test-process [operation] [options]": "usage: test-process [operation] [options]",
+	"操作:":    "operations:",
+	"オプション:": "options:",
+
+	// main
+	"replay操作には元のレポートJSONのパスが必要です":                   "replay requires the path of the original JSON report",
+	"リプレイ元読み込みエラー":                                   "error reading replay source",
+	"continuous操作には--durationまたは--profileオプションが必要です": "continuous requires --duration or --profile",
+	"--warmupには0以上の時間を指定してください":                      "--warmup must not be negative",
+	"--fail-thresholdは0から100の範囲で指定してください":            "--fail-threshold must be between 0 and 100",
+	"不明な出力形式 (json, csv, junit)":                     "unknown output format (json, csv, junit)",
+	"メモリ量解析エラー":                                      "error parsing memory size",
+	"チャンクサイズ解析エラー":                                   "error parsing chunk size",
+	"作業ディレクトリ作成エラー":                                  "error creating work directory",
+	"--workdirにはautoのみ指定できます":                        "--workdir only accepts auto",
+	"プロファイル解析エラー":                                    "error parsing profile",
+	"実行計画出力エラー":                                      "error writing plan",
+	"テストプロセス開始":                                      "test process started",
+	"設定":                                             "configuration",
+	"開始するにはEnterキーを押してください...":                       "press Enter to start...",
+	"メトリクス開始エラー":                                     "error starting metrics endpoint",
+	"メトリクス配信中 (/metrics)":                            "serving metrics (/metrics)",
+	"制御チャネル開始エラー":                                    "error starting control channel",
+	"--tagと--register-watchのタグが異なります":                "--tag and --register-watch name different tags",
+	"監視対象登録エラー":                                      "error adding watch target",
+	"監視対象登録":                                         "registered watch target",
+	"監視対象削除エラー":                                      "error removing watch target",
+	"作業ディレクトリ削除エラー":                                  "error removing working directory",
+	"作業ディレクトリ削除":                                     "removed working directory",
+	"PIDファイル作成エラー":                                   "error creating PID file",
+	"PIDファイル作成":                                      "created PID file",
+	"センチネルファイル作成エラー":                                 "error creating ready file",
+	"準備完了センチネル作成":                                    "created ready sentinel",
+	"制御チャネル待ち受け中 (startコマンドで開始)":                     "waiting on control channel (send start to begin)",
+	"記録イベント取得エラー":                                    "error getting recorded events",
+	"結果出力エラー":                                        "error writing results",
+	"実行完了":                                           "run complete",
+	"操作数":                                            "operations",
+	"ウォームアップの操作を統計から除外":                              "excluded warmup operations from statistics",
+	"実行時間":                                           "duration",
+	"照合結果":                                           "validation result",
+	"レイテンシ":                                          "latency",
+	"エラー":                                            "error",
+	"タイミング":                                          "timing",
+	"準備所要時間":                                         "setup time",
+	"照合所要時間":                                         "validation time",
+
+	// Flags
+	"操作回数":         "number of operations",
+	"操作間隔":         "interval between operations",
+	"対象ディレクトリ":     "target directory",
+	"詳細ログ (-vと同じ)": "verbose logging (same as -v)",
+	"操作ごとの詳細ログ":    "log each operation",
+	"-vに加えて読み書きしたバイト内容を出力":                                    "as -v, and print the bytes read and written",
+	"-vvに加えて内部のタイミングを出力":                                      "as -vv, and print internal timings",
+	"実行するコマンド (child-process用)":                               "command to run (child-process)",
+	"実行する操作のリスト (mixed用)":                                     "list of operations to run (mixed)",
+	"JSON形式で結果出力 (--format jsonと同じ)":                          "print the result as JSON (same as --format json)",
+	"結果の出力形式 (json, csv, junit)":                              "result format (json, csv, junit)",
+	"開始前にキー入力待機":                                              "wait for a key press before starting",
+	"継続実行時間 (0=無効)":                                           "run duration (0=disabled)",
+	"負荷プロファイル (continuous用、例: ramp:10:500:60s)":               "load profile (continuous, e.g. ramp:10:500:60s)",
+	"1バーストあたりの書き込み数 (burst用)":                                 "writes per burst (burst)",
+	"制御チャネル (例: unix:/tmp/tp.sock, tcp:127.0.0.1:9000)":       "control channel (e.g. unix:/tmp/tp.sock, tcp:127.0.0.1:9000)",
+	"実行中のカウンタをHTTPで公開するアドレス (例: 127.0.0.1:9100)":              "address to serve live counters over HTTP (e.g. 127.0.0.1:9100)",
+	"操作完了ごとにJSON Linesで標準出力へ出力":                               "print each completed operation to stdout as JSON Lines",
+	"ツリーの深さ (process-tree, dir-tree用)":                        "tree depth (process-tree, dir-tree)",
+	"各ノードの子の数 (process-tree, dir-tree用)":                      "children per node (process-tree, dir-tree)",
+	"葉ディレクトリごとのファイル数 (dir-tree用)":                             "files per leaf directory (dir-tree)",
+	"親終了後にファイル操作を始めるまでの待機時間 (orphan用)":                        "delay before file operations after the parent exits (orphan)",
+	"作業ディレクトリ (auto=--dir配下に実行ごとの一時ディレクトリを作成)":                "work directory (auto=create a per-run temporary directory under --dir)",
+	"終了時に作業ディレクトリを削除しない (--workdir auto用)":                    "keep the work directory on exit (--workdir auto)",
+	"負荷をかけるコア数 (stress用)":                                     "number of cores to load (stress)",
+	"確保するメモリ量 (stress用、例: 256MB)":                             "memory to allocate (stress, e.g. 256MB)",
+	"クラッシュモード panic|exit|segfault|signal (crash用)":            "crash mode panic|exit|segfault|signal (crash)",
+	"ファイルパスの最小文字数 (longpath用)":                                "minimum file path length (longpath)",
+	"同一ファイルを同時操作するゴルーチン数 (contend用)":                          "goroutines operating on the same file (contend)",
+	"1ファイルあたりの書き込みチャンク数 (flush用)":                             "write chunks per file (flush)",
+	"チャンクサイズ (flush用、例: 64, 4KB)":                             "chunk size (flush, e.g. 64, 4KB)",
+	"準備完了後・操作開始前に作成するセンチネルファイル":                               "sentinel file created when ready, before operations start",
+	"起動時にPIDを書き込むファイル (終了時に削除)":                               "file the PID is written to at startup (removed on exit)",
+	"自身を識別するタグ名 (デフォルト: test-process-<PID>)":                  "tag identifying this process (default: test-process-<PID>)",
+	"ファイルシステムに触れずに実行計画のみ出力":                                   "print the plan only, without touching the filesystem",
+	"開始からこの時間内に完了した操作を統計から除外":                                 "exclude operations completed within this time from the statistics",
+	"実行後にProcTailデーモンの記録イベントと照合":                              "validate against the events recorded by the ProcTail daemon after the run",
+	"ProcTailデーモンのNamed Pipe (--validate, --register-watch用)": "named pipe of the ProcTail daemon (--validate, --register-watch)",
+	"操作前に自身のPIDをこのタグでProcTailに監視登録し、終了時に削除":                   "register this PID with ProcTail under this tag before the run and remove it on exit",
+	"照合前にイベント記録を待つ時間":                                         "time to wait for events to be recorded before validating",
+	"許容する失敗率 (%)。超過時は終了コード3":                                  "tolerated failure rate (%); exit code 3 when exceeded",
+	"ログ形式 (text, json)":                                       "log format (text, json)",
+	"ログと使用方法の言語 (ja, en)":                                     "language of logs and usage (ja, en)",
+
+	// Operation descriptions
+	"ファイル書き込み操作":                                            "file write operations",
+	"ファイル読み込み操作":                                            "file read operations",
+	"ファイル削除操作":                                              "file delete operations",
+	"ファイルコピー (WindowsではCopyFileExW)":                        "file copy (CopyFileExW on Windows)",
+	"ディレクトリツリーの作成と再帰削除":                                     "create a directory tree and remove it recursively",
+	"MAX_PATH(260文字)を超えるパスでのファイル操作":                         "file operations on paths longer than MAX_PATH (260 characters)",
+	"日本語・絵文字・結合文字・サロゲートペアのファイル名での操作":                        "operations on file names with Japanese, emoji, combining characters and surrogate pairs",
+	"複数ゴルーチンによる同一ファイルの同時書き込み・リネーム":                          "concurrent writes and renames of one file from several goroutines",
+	"小さなチャンクごとにSyncする書き込み":                                  "writes that Sync after every small chunk",
+	"子プロセス作成":                                               "create child processes",
+	"CPU・メモリ負荷 (--cpu, --memory, --duration)":               "CPU and memory load (--cpu, --memory, --duration)",
+	"異常終了する子プロセスを作成 (--crash-mode)":                         "create child processes that crash (--crash-mode)",
+	"継続実行モード (--durationまたは--profile必須)":                    "continuous mode (requires --duration or --profile)",
+	"間隔なしの連続書き込みと休止の繰り返し":                                   "repeated back-to-back writes followed by a pause",
+	"自身を再帰的に起動してプロセスツリーを作成":                                 "start itself recursively to build a process tree",
+	"親より長く生存する切り離された子プロセスを作成":                               "create a detached child process that outlives the parent",
+	"自身をコピー・リネームして実行":                                       "copy and rename its own executable and run it",
+	"FILE_FLAG_DELETE_ON_CLOSEでの書き込み (Windows専用)":           "writes with FILE_FLAG_DELETE_ON_CLOSE (Windows only)",
+	"MoveFileEx(MOVEFILE_REPLACE_EXISTING)での置換 (Windows専用)": "replace with MoveFileEx(MOVEFILE_REPLACE_EXISTING) (Windows only)",
+	"SetFileInformationByHandleでのリネーム (Windows専用)":          "rename with SetFileInformationByHandle (Windows only)",
+	"SetFileInformationByHandleでの削除 (Windows専用)":            "delete with SetFileInformationByHandle (Windows only)",
+	"orphanが起動する切り離された子プロセス":                                "detached child process started by orphan",
+	"複数操作の組み合わせ":                                            "combination of several operations",
+	"crashが起動する子プロセス":                                       "child process started by crash",
+	"以前のレポートのタイムラインを再実行":                                    "re-run the timeline of a previous report",
+
+	// contend
+	"ワーカー数が不正です: %d": "invalid number of workers: %d",
+	"競合操作開始":         "contention operation started",
+	"競合操作エラー %s: %w": "contend error %s: %w",
+	"競合操作完了":         "contention operation complete",
+
+	// crash
+	"不明なクラッシュモード: %s":                "unknown crash mode: %s",
+	"実行ファイルパス取得エラー: %w":              "error getting executable path: %w",
+	"クラッシュ操作開始":                      "crash operation started",
+	"クラッシュ子プロセス開始エラー: %w":            "error starting crash child: %w",
+	"子プロセスがクラッシュしませんでした PID %d: %s":  "child process did not crash PID %d: %s",
+	"クラッシュ操作エラー (%s): %w":            "crash error (%s): %w",
+	"クラッシュ子プロセス終了":                   "crash child exited",
+	"親プロセスによって終了されませんでした":            "was not killed by the parent process",
+	"segfaultモードにはcgo有効ビルドが必要です: %w": "segfault mode requires a cgo-enabled build: %w",
+
+	// dir-tree
+	"ディレクトリツリーの深さ・幅が不正です: depth=%d, width=%d": "invalid directory tree depth or width: depth=%d, width=%d",
+	"ディレクトリツリー操作開始":                           "directory tree operation started",
+	"ディレクトリツリー作成エラー %s: %w":                   "error creating directory tree %s: %w",
+	"ディレクトリツリー作成完了":                           "directory tree created",
+	"ディレクトリツリー削除エラー %s: %w":                   "error removing directory tree %s: %w",
+	"ディレクトリツリー削除完了":                           "directory tree deleted",
+
+	// File operations
+	"ファイル書き込み操作開始":             "file write operation started",
+	"ファイル書き込み中":                "writing file",
+	"ファイル書き込みエラー %s: %w":       "file write error %s: %w",
+	"ファイル書き込み完了":               "file write complete",
+	"内容":                       "content",
+	"事前ファイル作成エラー: %w":          "error creating file in advance: %w",
+	"ファイル読み込み操作開始":             "file read operation started",
+	"ファイル読み込み中":                "reading file",
+	"ファイル読み込みエラー %s: %w":       "file read error %s: %w",
+	"ファイル読み込み完了":               "file read complete",
+	"ファイル削除操作開始":               "file delete operation started",
+	"ファイル削除中":                  "deleting file",
+	"ファイル削除エラー %s: %w":         "file delete error %s: %w",
+	"ファイル削除完了":                 "file delete complete",
+	"ファイルリネーム操作開始":             "file rename operation started",
+	"ファイルリネーム中":                "renaming file",
+	"ファイルリネームエラー %s -> %s: %w": "file rename error %s -> %s: %w",
+	"ファイルリネーム完了":               "file rename complete",
+	"ディレクトリ操作開始":               "directory operation started",
+	"ディレクトリ作成中":                "creating directory",
+	"ディレクトリ作成エラー %s: %w":       "directory create error %s: %w",
+	"ディレクトリ作成完了":               "directory create complete",
+	"ディレクトリ削除中":                "deleting directory",
+	"ディレクトリ削除エラー %s: %w":       "directory delete error %s: %w",
+	"ディレクトリ削除完了":               "directory delete complete",
+
+	// continuous and burst
+	"継続実行時間が設定されていません":           "no duration set for continuous mode",
+	"継続ファイル操作開始":                 "continuous file operation started",
+	"継続操作: ファイル作成 -> 読み込み -> 削除": "continuous operation: create -> read -> delete",
+	"継続書き込みエラー %s: %w":           "continuous write error %s: %w",
+	"継続読み込みエラー %s: %w":           "continuous read error %s: %w",
+	"継続削除エラー %s: %w":             "continuous delete error %s: %w",
+	"継続操作完了":                     "continuous operation complete",
+	"バーストサイズが不正です: %d":           "invalid burst size: %d",
+	"バースト書き込み操作開始":               "burst write operation started",
+	"バースト書き込みエラー %s: %w":         "burst write error %s: %w",
+	"バースト完了":                     "burst complete",
+
+	// flush
+	"チャンク設定が不正です: chunks=%d, chunk-size=%d": "invalid chunk settings: chunks=%d, chunk-size=%d",
+	"フラッシュ操作開始":                             "flush operation started",
+	"フラッシュ用ファイル作成エラー %s: %w":                "error creating flush file %s: %w",
+	"フラッシュ書き込みエラー %s (チャンク %d): %w":         "flush write error %s (chunk %d): %w",
+	"フラッシュ操作完了":                             "flush operation complete",
+
+	// mixed
+	"複合操作開始":                 "mixed operation started",
+	"操作種類":                   "operation types",
+	"複合操作セット":                "mixed operation set",
+	"操作":                     "operation",
+	"複合操作エラー %d.%d (%s): %w": "mixed error %d.%d (%s): %w",
+	"ディレクトリ作成/削除":            "creating/deleting directory",
+	"ディレクトリ作成/削除完了":          "directory create/delete complete",
+	"ランダム操作":                 "random operation",
+	"%d件の操作が失敗しました":          "%d operations failed",
+
+	// Process operations
+	"子プロセス作成開始":                             "child process creation started",
+	"無効なコマンド: %s":                           "invalid command: %s",
+	"子プロセス実行中":                              "running child process",
+	"子プロセス開始エラー: %w":                        "error starting child process: %w",
+	"子プロセス開始":                               "child process started",
+	"子プロセス実行エラー PID %d: %w":                 "child process error PID %d: %w",
+	"子プロセス完了":                               "child process complete",
+	"長時間実行子プロセス作成開始":                        "long-running child process creation started",
+	"長時間実行プロセス開始中":                          "starting long-running process",
+	"長時間実行プロセス開始エラー: %w":                    "error starting long-running process: %w",
+	"長時間実行プロセス開始":                           "long-running process started",
+	"プロセス実行中... (5秒待機)":                     "process running... (waiting 5 seconds)",
+	"プロセス終了中":                               "terminating process",
+	"プロセス終了エラー":                             "error terminating process",
+	"プロセス終了完了":                              "process terminated",
+	"プロセスツリーの深さ・幅が不正です: depth=%d, width=%d": "invalid process tree depth or width: depth=%d, width=%d",
+	"マーカーファイル作成エラー %s: %w":                  "error creating marker file %s: %w",
+	"プロセスツリー作成開始":                           "process tree creation started",
+	"プロセスツリー開始エラー: %w":                      "error starting process tree: %w",
+	"プロセスツリー子プロセス開始":                        "process tree child started",
+	"プロセスツリー実行エラー PID %d: %w":               "process tree error PID %d: %w",
+	"プロセスツリー子プロセス完了":                        "process tree child complete",
+	"孤児プロセス作成":                              "creating orphan process",
+	"孤児プロセス開始エラー: %w":                       "error starting orphan process: %w",
+	"孤児プロセス開始 (親は待機せず終了)":                   "orphan process started (parent exits without waiting)",
+	"孤児プロセス書き込みエラー %s: %w":                  "orphan process write error %s: %w",
+	"自己コピー実行開始":                             "self-copy run started",
+	"実行ファイルコピーエラー %s: %w":                   "error copying executable %s: %w",
+	"実行ファイルリネームエラー %s -> %s: %w":            "error renaming executable %s -> %s: %w",
+	"コピーした実行ファイルを起動中":                       "starting copied executable",
+	"コピー実行開始エラー %s: %w":                     "error starting copied executable %s: %w",
+	"コピー実行エラー PID %d (%s): %w":              "copied executable error PID %d (%s): %w",
+	"コピー実行完了":                               "copy run complete",
+
+	// Profiles and sizes
+	"rampプロファイルの形式が不正です (ramp:最小レート:最大レート:周期): %s": "invalid ramp profile (ramp:min-rate:max-rate:period): %s",
+	"最小レートが不正です: %s":  "invalid minimum rate: %s",
+	"最大レートが不正です: %s":  "invalid maximum rate: %s",
+	"周期が不正です: %s":     "invalid period: %s",
+	"不明なプロファイル: %s":   "unknown profile: %s",
+	"サイズの形式が不正です: %s": "invalid size: %s",
+	"不明な操作: %s":       "unknown operation: %s",
+
+	// replay
+	"再現できない操作をスキップ":         "skipping operations that cannot be replayed",
+	"リプレイ開始":                "replay started",
+	"リプレイ":                  "replay",
+	"リプレイエラー %s %s: %w":     "replay error %s %s: %w",
+	"レポートにタイムラインがありません: %s": "report has no timeline: %s",
+
+	// stress
+	"負荷時間が設定されていません":               "no duration set for stress",
+	"負荷設定が不正です: cpu=%d, memory=%d": "invalid stress settings: cpu=%d, memory=%d",
+	"負荷操作開始":                       "stress operation started",
+	"CPU負荷ワーカー完了":                  "CPU stress worker complete",
+	"負荷操作完了":                       "stress complete",
+
+	// unicode and Windows file operations
+	"Unicodeファイル名操作開始":          "Unicode file name operation started",
+	"Unicodeファイル名操作エラー %s: %w":  "unicode file name error %s: %w",
+	"Unicodeファイル名操作完了":          "Unicode file name operation complete",
+	"この操作は現在のプラットフォームでは利用できません": "this operation is not available on the current platform",
+	"操作開始":         "operation started",
+	"%sエラー %s: %w": "%s error %s: %w",
+	"操作完了":         "operation complete",
+	"ファイルコピー":      "file copy",
+	"ロングパス":        "long path",
+	"削除時クローズ":      "delete on close",
+	"置換移動":         "move replace",
+	"ハンドル経由リネーム":   "handle rename",
+	"ハンドル経由削除":     "handle delete",
+
+	// Output
+	"不明な出力形式: %s":              "unknown output format: %s",
+	"不明なログ形式: %s (text, json)": "unknown log format: %s (text, json)",
+	"実行計画: %s (ドライラン: ファイルシステムは変更しません)\n":    "plan: %s (dry run: the filesystem is not changed)\n",
+	"合計 %dステップ、見積もり所要時間 %v (操作自体の所要時間を除く)\n": "%d steps in total, estimated duration %v (excluding the operations themselves)\n",
+}
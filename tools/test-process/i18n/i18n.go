@@ -0,0 +1,51 @@
+// Package i18n translates the messages of test-process.
+//
+// Messages are written in Japanese in the source and used as catalog keys:
+//
+//	slog.Info(i18n.T("ファイル書き込み完了"), "path", path)
+//
+// T returns the message for the selected language, or the Japanese message
+// itself when the language is Japanese or the catalog has no entry for it.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// catalogs maps a language to its translations, keyed by the Japanese message
+var catalogs = map[string]map[string]string{
+	"en": en,
+}
+
+// lang is the selected language; "ja" uses the messages as written
+var lang = "ja"
+
+// SetLang selects the language for T. It is not safe to call concurrently
+// with T and should be called once at startup.
+func SetLang(l string) error {
+	if _, ok := catalogs[l]; !ok && l != "ja" {
+		return fmt.Errorf("不明な言語: %s (%s)", l, strings.Join(Langs(), ", "))
+	}
+	lang = l
+	return nil
+}
+
+// Langs lists the supported languages
+func Langs() []string {
+	langs := []string{"ja"}
+	for l := range catalogs {
+		langs = append(langs, l)
+	}
+	sort.Strings(langs[1:])
+	return langs
+}
+
+// T translates msg into the selected language
+func T(msg string) string {
+	if translated, ok := catalogs[lang][msg]; ok {
+		return translated
+	}
+	return msg
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"proctail-test-process/operations"
+	"proctail-test-process/schema"
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the upper bounds of the histogram buckets; the last
+// bucket in a summary collects everything above the largest bound
+var latencyBuckets = []time.Duration{
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	1 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	1 * time.Second,
+	5 * time.Second,
+}
+
+// latencyRecorder collects per-operation latencies; operations may report
+// from several goroutines
+type latencyRecorder struct {
+	mu      sync.Mutex
+	samples map[string][]time.Duration
+}
+
+func newLatencyRecorder() *latencyRecorder {
+	return &latencyRecorder{samples: make(map[string][]time.Duration)}
+}
+
+func (l *latencyRecorder) Record(event operations.OperationEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.samples[event.Type] = append(l.samples[event.Type], event.Latency)
+}
+
+// Summaries returns a summary per operation type, or nil if nothing was recorded
+func (l *latencyRecorder) Summaries() map[string]*schema.LatencySummary {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.samples) == 0 {
+		return nil
+	}
+
+	summaries := make(map[string]*schema.LatencySummary, len(l.samples))
+	for opType, samples := range l.samples {
+		summaries[opType] = summarizeLatencies(samples)
+	}
+	return summaries
+}
+
+func summarizeLatencies(samples []time.Duration) *schema.LatencySummary {
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+
+	summary := &schema.LatencySummary{
+		Count: len(sorted),
+		Min:   toMillis(sorted[0]),
+		Mean:  toMillis(total / time.Duration(len(sorted))),
+		P50:   toMillis(percentile(sorted, 50)),
+		P95:   toMillis(percentile(sorted, 95)),
+		P99:   toMillis(percentile(sorted, 99)),
+		Max:   toMillis(sorted[len(sorted)-1]),
+	}
+
+	// sorted is ascending, so each bucket takes a contiguous run
+	next := 0
+	for _, bound := range latencyBuckets {
+		count := 0
+		for next < len(sorted) && sorted[next] <= bound {
+			count++
+			next++
+		}
+		le := toMillis(bound)
+		summary.Histogram = append(summary.Histogram, schema.LatencyBucket{LE: &le, Count: count})
+	}
+	summary.Histogram = append(summary.Histogram, schema.LatencyBucket{Count: len(sorted) - next})
+
+	return summary
+}
+
+// percentile uses the nearest-rank method on an ascending slice
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func toMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
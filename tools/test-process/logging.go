@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"proctail-test-process/i18n"
+)
+
+// newLogHandler creates the slog handler for --log-format. JSON records carry
+// RFC 3339 timestamps with nanoseconds for correlation with daemon logs.
+func newLogHandler(format string, w io.Writer) (slog.Handler, error) {
+	switch format {
+	case "text":
+		return slog.NewTextHandler(w, nil), nil
+	case "json":
+		return slog.NewJSONHandler(w, nil), nil
+	default:
+		return nil, fmt.Errorf(i18n.T("不明なログ形式: %s (text, json)"), format)
+	}
+}
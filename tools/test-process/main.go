@@ -1,83 +1,131 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"proctail-test-process/i18n"
 	"proctail-test-process/operations"
+	"proctail-test-process/schema"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
-type Config struct {
-	Count    int           `json:"count"`
-	Interval time.Duration `json:"interval"`
-	Dir      string        `json:"dir"`
-	Verbose  bool          `json:"verbose"`
-	Command  string        `json:"command,omitempty"`
-	Ops      []string      `json:"operations,omitempty"`
-	Duration time.Duration `json:"duration,omitempty"`
+// Report is the JSON report plus the run state that is not serialized
+type Report struct {
+	schema.Report
+
+	profile *operations.Profile
+
+	// warmupEnd is when operations start counting towards the statistics
+	warmupEnd time.Time
+
+	tree processTreeRecorder
+
+	// hooks receive this run's operations and gate them for --control
+	hooks operations.Hooks
 }
 
-type Report struct {
-	Operation   string        `json:"operation"`
-	Config      Config        `json:"config"`
-	StartTime   time.Time     `json:"start_time"`
-	EndTime     time.Time     `json:"end_time"`
-	Duration    time.Duration `json:"duration"`
-	TotalOps    int           `json:"total_operations"`
-	SuccessOps  int           `json:"successful_operations"`
-	FailedOps   int           `json:"failed_operations"`
-	Errors      []string      `json:"errors,omitempty"`
-	ProcessID   int           `json:"process_id"`
-	ChildPIDs   []int         `json:"child_process_ids,omitempty"`
+// FileOptions builds the options for file operations
+func (r *Report) FileOptions() operations.FileOptions {
+	return operations.FileOptions{
+		Count:     r.Config.Count,
+		Interval:  time.Duration(r.Config.Interval),
+		Dir:       r.Config.Dir,
+		Verbosity: r.Config.Verbosity,
+		Duration:  time.Duration(r.Config.Duration),
+		Profile:   r.profile,
+		BurstSize: r.Config.BurstSize,
+		TreeDepth: r.Config.TreeDepth,
+		TreeWidth: r.Config.TreeWidth,
+		TreeFiles: r.Config.TreeFiles,
+
+		StressCPU:    r.Config.StressCPU,
+		StressMemory: r.Config.StressMemory,
+
+		PathLength: r.Config.PathLength,
+
+		Workers: r.Config.Workers,
+
+		Chunks:    r.Config.Chunks,
+		ChunkSize: r.Config.ChunkSize,
+
+		OrphanDelay: time.Duration(r.Config.OrphanDelay),
+	}
 }
 
-// Implement the required interfaces for operations
-func (r *Report) GetConfig() operations.Config {
-	return operations.Config{
-		Count:    r.Config.Count,
-		Interval: r.Config.Interval,
-		Dir:      r.Config.Dir,
-		Verbose:  r.Config.Verbose,
-		Duration: r.Config.Duration,
+// ProcessOptions builds the options for process operations
+func (r *Report) ProcessOptions() operations.ProcessOptions {
+	return operations.ProcessOptions{
+		Count:     r.Config.Count,
+		Interval:  time.Duration(r.Config.Interval),
+		Dir:       r.Config.Dir,
+		Verbosity: r.Config.Verbosity,
+		Command:   r.Config.Command,
+		Duration:  time.Duration(r.Config.Duration),
+		TreeDepth: r.Config.TreeDepth,
+		TreeWidth: r.Config.TreeWidth,
+
+		OrphanDelay: time.Duration(r.Config.OrphanDelay),
+
+		CrashMode: r.Config.CrashMode,
 	}
 }
 
-func (r *Report) GetProcessConfig() operations.ProcessConfig {
-	return operations.ProcessConfig{
-		Count:    r.Config.Count,
-		Interval: r.Config.Interval,
-		Dir:      r.Config.Dir,
-		Verbose:  r.Config.Verbose,
-		Command:  r.Config.Command,
-		Duration: r.Config.Duration,
+// MixedOptions builds the options for the mixed operation
+func (r *Report) MixedOptions() operations.MixedOptions {
+	return operations.MixedOptions{
+		Count:     r.Config.Count,
+		Interval:  time.Duration(r.Config.Interval),
+		Dir:       r.Config.Dir,
+		Verbosity: r.Config.Verbosity,
+		Command:   r.Config.Command,
+		Ops:       r.Config.Ops,
+		Duration:  time.Duration(r.Config.Duration),
 	}
 }
 
-func (r *Report) GetMixedConfig() operations.MixedConfig {
-	return operations.MixedConfig{
-		Count:    r.Config.Count,
-		Interval: r.Config.Interval,
-		Dir:      r.Config.Dir,
-		Verbose:  r.Config.Verbose,
-		Command:  r.Config.Command,
-		Ops:      r.Config.Ops,
-		Duration: r.Config.Duration,
+// Params bundles the options for every operation type with the report itself
+func (r *Report) Params() operations.Params {
+	return operations.Params{
+		File:    r.FileOptions(),
+		Process: r.ProcessOptions(),
+		Mixed:   r.MixedOptions(),
+		Report:  r,
+		Hooks:   r.hooks,
 	}
 }
 
+// Report receives results from every operation
+var _ operations.Reporter = (*Report)(nil)
+
 func (r *Report) IncrementSuccess() {
+	if r.inWarmup() {
+		r.WarmupOps++
+		return
+	}
 	r.SuccessOps++
 }
 
 func (r *Report) IncrementFailed() {
+	if r.inWarmup() {
+		r.WarmupOps++
+		return
+	}
 	r.FailedOps++
 }
 
 func (r *Report) AddError(err error) {
+	if r.inWarmup() {
+		return
+	}
 	r.Errors = append(r.Errors, err.Error())
 }
 
@@ -87,166 +135,524 @@ func (r *Report) SetTotalOps(count int) {
 
 func (r *Report) AddChildPID(pid int) {
 	r.ChildPIDs = append(r.ChildPIDs, pid)
+	r.tree.AddChild(os.Getpid(), pid)
 }
 
-// ProcessReportAdapter adapts Report to ProcessReport interface
-type ProcessReportAdapter struct {
-	report *Report
+func (r *Report) SetChildExitCode(pid, code int) {
+	r.tree.SetExitCode(pid, code)
 }
 
-func (a *ProcessReportAdapter) GetConfig() operations.ProcessConfig {
-	return a.report.GetProcessConfig()
+func (r *Report) AddDescendants(child int, nodes []schema.ProcessNode) {
+	for _, node := range nodes {
+		r.ChildPIDs = append(r.ChildPIDs, node.PID)
+		r.tree.AddChild(child, node.PID)
+		if node.ExitCode != nil {
+			r.tree.SetExitCode(node.PID, *node.ExitCode)
+		}
+		r.AddDescendants(node.PID, node.Children)
+	}
 }
 
-func (a *ProcessReportAdapter) IncrementSuccess() {
-	a.report.IncrementSuccess()
+// inWarmup reports whether results are currently excluded by --warmup
+func (r *Report) inWarmup() bool {
+	return time.Now().Before(r.warmupEnd)
 }
 
-func (a *ProcessReportAdapter) IncrementFailed() {
-	a.report.IncrementFailed()
+// printOperations lists the registered operations for usage and "list"
+func printOperations() {
+	for _, name := range operations.Names() {
+		op, _ := operations.New(name)
+		fmt.Printf("  %-13s - %s\n", name, i18n.T(op.Description()))
+	}
+	fmt.Printf("  %-13s - %s\n", "replay FILE", i18n.T(replayOperation(nil).Description()))
 }
 
-func (a *ProcessReportAdapter) AddError(err error) {
-	a.report.AddError(err)
+// printUsage prints the usage text in the selected language
+func printUsage() {
+	fmt.Println(i18n.T("使用方法: test-process [operation] [options]"))
+	fmt.Println("")
+	fmt.Println(i18n.T("操作:"))
+	printOperations()
+	fmt.Println("")
+	fmt.Println(i18n.T("オプション:"))
+	flag.VisitAll(func(f *flag.Flag) {
+		f.Usage = i18n.T(f.Usage)
+	})
+	flag.PrintDefaults()
 }
 
-func (a *ProcessReportAdapter) SetTotalOps(count int) {
-	a.report.SetTotalOps(count)
-}
+func main() {
+	var (
+		count         = flag.Int("count", 3, "操作回数")
+		interval      = flag.Duration("interval", time.Second, "操作間隔")
+		dir           = flag.String("dir", os.TempDir(), "対象ディレクトリ")
+		verboseFlag   = flag.Bool("verbose", false, "詳細ログ (-vと同じ)")
+		v1            = flag.Bool("v", false, "操作ごとの詳細ログ")
+		v2            = flag.Bool("vv", false, "-vに加えて読み書きしたバイト内容を出力")
+		v3            = flag.Bool("vvv", false, "-vvに加えて内部のタイミングを出力")
+		command       = flag.String("command", "", "実行するコマンド (child-process用)")
+		ops           = flag.String("operations", "write,read,delete", "実行する操作のリスト (mixed用)")
+		jsonOut       = flag.Bool("json", false, "JSON形式で結果出力 (--format jsonと同じ)")
+		format        = flag.String("format", "", "結果の出力形式 (json, csv, junit)")
+		waitKey       = flag.Bool("wait", false, "開始前にキー入力待機")
+		duration      = flag.Duration("duration", 0, "継続実行時間 (0=無効)")
+		profile       = flag.String("profile", "", "負荷プロファイル (continuous用、例: ramp:10:500:60s)")
+		burstSize     = flag.Int("burst-size", 100, "1バーストあたりの書き込み数 (burst用)")
+		control       = flag.String("control", "", "制御チャネル (例: unix:/tmp/tp.sock, tcp:127.0.0.1:9000)")
+		metricsAddr   = flag.String("metrics", "", "実行中のカウンタをHTTPで公開するアドレス (例: 127.0.0.1:9100)")
+		stream        = flag.Bool("stream", false, "操作完了ごとにJSON Linesで標準出力へ出力")
+		treeDepth     = flag.Int("tree-depth", 1, "ツリーの深さ (process-tree, dir-tree用)")
+		treeWidth     = flag.Int("tree-width", 3, "各ノードの子の数 (process-tree, dir-tree用)")
+		treeFiles     = flag.Int("tree-files", 3, "葉ディレクトリごとのファイル数 (dir-tree用)")
+		orphanDelay   = flag.Duration("orphan-delay", 2*time.Second, "親終了後にファイル操作を始めるまでの待機時間 (orphan用)")
+		workdir       = flag.String("workdir", "", "作業ディレクトリ (auto=--dir配下に実行ごとの一時ディレクトリを作成)")
+		keep          = flag.Bool("keep", false, "終了時に作業ディレクトリを削除しない (--workdir auto用)")
+		stressCPU     = flag.Int("cpu", 1, "負荷をかけるコア数 (stress用)")
+		stressMemory  = flag.String("memory", "0", "確保するメモリ量 (stress用、例: 256MB)")
+		crashMode     = flag.String("crash-mode", "panic", "クラッシュモード panic|exit|segfault|signal (crash用)")
+		pathLength    = flag.Int("path-length", 300, "ファイルパスの最小文字数 (longpath用)")
+		workers       = flag.Int("workers", 4, "同一ファイルを同時操作するゴルーチン数 (contend用)")
+		chunks        = flag.Int("chunks", 20, "1ファイルあたりの書き込みチャンク数 (flush用)")
+		chunkSize     = flag.String("chunk-size", "64", "チャンクサイズ (flush用、例: 64, 4KB)")
+		readyFile     = flag.String("ready-file", "", "準備完了後・操作開始前に作成するセンチネルファイル")
+		pidFile       = flag.String("pid-file", "", "起動時にPIDを書き込むファイル (終了時に削除)")
+		tag           = flag.String("tag", "", "自身を識別するタグ名 (デフォルト: test-process-<PID>)")
+		dryRun        = flag.Bool("dry-run", false, "ファイルシステムに触れずに実行計画のみ出力")
+		warmup        = flag.Duration("warmup", 0, "開始からこの時間内に完了した操作を統計から除外")
+		validate      = flag.Bool("validate", false, "実行後にProcTailデーモンの記録イベントと照合")
+		pipe          = flag.String("pipe", defaultPipe, "ProcTailデーモンのNamed Pipe (--validate, --register-watch用)")
+		registerWatch = flag.String("register-watch", "", "操作前に自身のPIDをこのタグでProcTailに監視登録し、終了時に削除")
+		validateWait  = flag.Duration("validate-wait", 2*time.Second, "照合前にイベント記録を待つ時間")
+		failThreshold = flag.Float64("fail-threshold", 0, "許容する失敗率 (%)。超過時は終了コード3")
+		lang          = flag.String("lang", "ja", "ログと使用方法の言語 (ja, en)")
+		logFormat     = flag.String("log-format", "text", "ログ形式 (text, json)")
+	)
+	flag.Usage = func() {
+		// -h stops parsing, so apply --lang if it came first
+		i18n.SetLang(*lang)
+		printUsage()
+	}
+	if err := applyEnvDefaults(flag.CommandLine); err != nil {
+		exitConfigError(err.Error())
+	}
+	flag.Parse()
 
-func (a *ProcessReportAdapter) AddChildPID(pid int) {
-	a.report.AddChildPID(pid)
-}
+	if err := i18n.SetLang(*lang); err != nil {
+		exitConfigError(err.Error())
+	}
+	handler, err := newLogHandler(*logFormat, os.Stderr)
+	if err != nil {
+		exitConfigError(err.Error())
+	}
+	// The PID ties records to the daemon's events for this process
+	slog.SetDefault(slog.New(handler).With("pid", os.Getpid()))
+
+	verbosity := 0
+	switch {
+	case *v3:
+		verbosity = operations.VerboseTiming
+	case *v2:
+		verbosity = operations.VerboseBytes
+	case *v1, *verboseFlag:
+		verbosity = operations.VerboseOps
+	}
+	verbose := verbosity >= operations.VerboseOps
+	// Measured for the -vvv phase timings
+	setupStart := time.Now()
 
-// MixedReportAdapter adapts Report to MixedReport interface
-type MixedReportAdapter struct {
-	report *Report
-}
+	if len(flag.Args()) == 0 {
+		printUsage()
+		os.Exit(ExitConfigError)
+	}
 
-func (a *MixedReportAdapter) GetConfig() operations.MixedConfig {
-	return a.report.GetMixedConfig()
-}
+	operation := flag.Args()[0]
 
-func (a *MixedReportAdapter) IncrementSuccess() {
-	a.report.IncrementSuccess()
-}
+	if operation == "list" {
+		printOperations()
+		os.Exit(ExitSuccess)
+	}
 
-func (a *MixedReportAdapter) IncrementFailed() {
-	a.report.IncrementFailed()
-}
+	var op operations.Operation
+	var replaySource *schema.Report
+	if operation == "replay" {
+		if len(flag.Args()) < 2 {
+			exitConfigError(i18n.T("replay操作には元のレポートJSONのパスが必要です"))
+		}
+		replaySource, err = readReplaySource(flag.Args()[1])
+		if err != nil {
+			exitConfigError(i18n.T("リプレイ元読み込みエラー"), "error", err)
+		}
+		op = replayOperation(replaySource)
+	} else {
+		op, err = operations.New(operation)
+		if err != nil {
+			exitConfigError(err.Error())
+		}
+	}
+	if *registerWatch != "" && *tag != "" && *tag != *registerWatch {
+		exitConfigError(i18n.T("--tagと--register-watchのタグが異なります"), "tag", *tag, "register_watch", *registerWatch)
+	}
 
-func (a *MixedReportAdapter) AddError(err error) {
-	a.report.AddError(err)
-}
+	if operation == "continuous" && *duration <= 0 && *profile == "" {
+		exitConfigError(i18n.T("continuous操作には--durationまたは--profileオプションが必要です"))
+	}
 
-func (a *MixedReportAdapter) SetTotalOps(count int) {
-	a.report.SetTotalOps(count)
-}
+	if *warmup < 0 {
+		exitConfigError(i18n.T("--warmupには0以上の時間を指定してください"), "warmup", *warmup)
+	}
 
-func (a *MixedReportAdapter) AddChildPID(pid int) {
-	a.report.AddChildPID(pid)
-}
+	if *failThreshold < 0 || *failThreshold > 100 {
+		exitConfigError(i18n.T("--fail-thresholdは0から100の範囲で指定してください"), "fail_threshold", *failThreshold)
+	}
 
-func main() {
-	var (
-		count    = flag.Int("count", 3, "操作回数")
-		interval = flag.Duration("interval", time.Second, "操作間隔")
-		dir      = flag.String("dir", os.TempDir(), "対象ディレクトリ")
-		verbose  = flag.Bool("verbose", false, "詳細ログ")
-		command  = flag.String("command", "", "実行するコマンド (child-process用)")
-		ops      = flag.String("operations", "write,read,delete", "実行する操作のリスト (mixed用)")
-		jsonOut  = flag.Bool("json", false, "JSON形式で結果出力")
-		waitKey  = flag.Bool("wait", false, "開始前にキー入力待機")
-		duration = flag.Duration("duration", 0, "継続実行時間 (0=無効)")
-	)
-	flag.Parse()
+	if *format == "" && *jsonOut {
+		*format = "json"
+	}
+	if *format != "" && !outputFormats[*format] {
+		exitConfigError(i18n.T("不明な出力形式 (json, csv, junit)"), "format", *format)
+	}
 
-	if len(flag.Args()) == 0 {
-		fmt.Println("使用方法: test-process [operation] [options]")
-		fmt.Println("")
-		fmt.Println("操作:")
-		fmt.Println("  file-write    - ファイル書き込み操作")
-		fmt.Println("  file-read     - ファイル読み込み操作")
-		fmt.Println("  file-delete   - ファイル削除操作")
-		fmt.Println("  child-process - 子プロセス作成")
-		fmt.Println("  mixed         - 複数操作の組み合わせ")
-		fmt.Println("  continuous    - 継続実行モード (--duration必須)")
-		fmt.Println("")
-		fmt.Println("オプション:")
-		flag.PrintDefaults()
-		os.Exit(1)
+	config := schema.Config{
+		Count:     *count,
+		Interval:  schema.Duration(*interval),
+		Dir:       *dir,
+		Verbose:   verbose,
+		Verbosity: verbosity,
+		Command:   *command,
+		Ops:       strings.Split(*ops, ","),
+		Duration:  schema.Duration(*duration),
+		Profile:   *profile,
+		Warmup:    schema.Duration(*warmup),
+	}
+	switch operation {
+	case "burst":
+		config.BurstSize = *burstSize
+	case "process-tree":
+		config.TreeDepth = *treeDepth
+		config.TreeWidth = *treeWidth
+	case "dir-tree":
+		config.TreeDepth = *treeDepth
+		config.TreeWidth = *treeWidth
+		config.TreeFiles = *treeFiles
+	case "stress":
+		size, err := operations.ParseSize(*stressMemory)
+		if err != nil {
+			exitConfigError(i18n.T("メモリ量解析エラー"), "error", err)
+		}
+		config.StressCPU = *stressCPU
+		config.StressMemory = size
+		if config.Duration <= 0 {
+			config.Duration = schema.Duration(10 * time.Second)
+		}
+	case "contend":
+		config.Workers = *workers
+	case "flush":
+		size, err := operations.ParseSize(*chunkSize)
+		if err != nil {
+			exitConfigError(i18n.T("チャンクサイズ解析エラー"), "error", err)
+		}
+		config.Chunks = *chunks
+		config.ChunkSize = size
+	case "longpath":
+		config.PathLength = *pathLength
+	case "crash", "crash-child":
+		config.CrashMode = *crashMode
+	case "orphan", "orphan-child":
+		config.OrphanDelay = schema.Duration(*orphanDelay)
+	case "replay":
+		// Reproduce the original run's settings for dir-tree, flush and child-process steps
+		config.Replay = flag.Args()[1]
+		config.TreeDepth = replaySource.Config.TreeDepth
+		config.TreeWidth = replaySource.Config.TreeWidth
+		config.TreeFiles = replaySource.Config.TreeFiles
+		config.ChunkSize = replaySource.Config.ChunkSize
+		if config.Command == "" {
+			config.Command = replaySource.Config.Command
+		}
 	}
 
-	operation := flag.Args()[0]
-	
-	config := Config{
-		Count:    *count,
-		Interval: *interval,
-		Dir:      *dir,
-		Verbose:  *verbose,
-		Command:  *command,
-		Ops:      strings.Split(*ops, ","),
-		Duration: *duration,
+	var autoWorkdir string
+	switch *workdir {
+	case "":
+	case "auto":
+		if *dryRun {
+			// Show where the run would go without creating anything
+			config.Dir = filepath.Join(config.Dir, fmt.Sprintf("proctail_test_%d_*", os.Getpid()))
+			break
+		}
+		dir, err := os.MkdirTemp(config.Dir, fmt.Sprintf("proctail_test_%d_", os.Getpid()))
+		if err != nil {
+			exitConfigError(i18n.T("作業ディレクトリ作成エラー"), "error", err)
+		}
+		autoWorkdir = dir
+		config.Dir = dir
+	default:
+		exitConfigError(i18n.T("--workdirにはautoのみ指定できます"), "workdir", *workdir)
 	}
 
-	if *verbose {
-		log.Printf("テストプロセス開始: %s", operation)
-		log.Printf("設定: %+v", config)
-		log.Printf("プロセスID: %d", os.Getpid())
+	var profileSpec *operations.Profile
+	if *profile != "" {
+		p, err := operations.ParseProfile(*profile)
+		if err != nil {
+			exitConfigError(i18n.T("プロファイル解析エラー"), "error", err)
+		}
+		profileSpec = p
+		if config.Duration <= 0 {
+			config.Duration = schema.Duration(p.Period)
+		}
+	}
+
+	if *dryRun {
+		plan := Report{Report: schema.Report{Operation: operation, Config: config}, profile: profileSpec}
+		steps := operations.Plan(operation, op, plan.Params())
+		if err := writePlan(os.Stdout, operation, steps, *format); err != nil {
+			exitConfigError(i18n.T("実行計画出力エラー"), "error", err)
+		}
+		os.Exit(ExitSuccess)
+	}
+
+	if verbose {
+		slog.Info(i18n.T("テストプロセス開始"), "operation", operation)
+		slog.Info(i18n.T("設定"), "config", config)
 	}
 
 	if *waitKey {
-		fmt.Print("開始するにはEnterキーを押してください...")
+		fmt.Print(i18n.T("開始するにはEnterキーを押してください..."))
 		fmt.Scanln()
 	}
 
-	report := Report{
-		Operation: operation,
-		Config:    config,
-		StartTime: time.Now(),
-		ProcessID: os.Getpid(),
+	latencies := newLatencyRecorder()
+	timeline := &timelineRecorder{}
+	var streamMu sync.Mutex
+	encoder := json.NewEncoder(os.Stdout)
+	// Set once the run starts; operations only report after that
+	var warmupEnd time.Time
+	metrics := newMetricsRecorder(operation)
+	onOperation := func(event operations.OperationEvent) {
+		warm := event.Timestamp.Before(warmupEnd)
+		if !warm {
+			latencies.Record(event)
+		}
+		timeline.Record(event, warm)
+		metrics.Record(event, warm)
+		if verbosity >= operations.VerboseTiming {
+			slog.Info(i18n.T("タイミング"), "type", event.Type, "path", event.Path, "start", event.Start.Format(time.RFC3339Nano), "latency", event.Latency)
+		}
+		if *stream {
+			streamMu.Lock()
+			defer streamMu.Unlock()
+			encoder.Encode(event)
+		}
 	}
 
-	var err error
-	switch operation {
-	case "file-write":
-		err = operations.ExecuteFileWrite(&report)
-	case "file-read":
-		err = operations.ExecuteFileRead(&report)
-	case "file-delete":
-		err = operations.ExecuteFileDelete(&report)
-	case "child-process":
-		processReport := &ProcessReportAdapter{report: &report}
-		err = operations.ExecuteChildProcess(processReport)
-	case "mixed":
-		mixedReport := &MixedReportAdapter{report: &report}
-		err = operations.ExecuteMixed(mixedReport)
-	case "continuous":
-		if config.Duration <= 0 {
-			log.Fatalf("continuous操作には--durationオプションが必要です")
+	var controlServer *ControlServer
+	var gate *operations.Gate
+	if *control != "" {
+		gate = operations.NewGate()
+
+		var err error
+		controlServer, err = StartControlServer(*control, gate, verbose)
+		if err != nil {
+			exitConfigError(i18n.T("制御チャネル開始エラー"), "error", err)
 		}
-		err = operations.ExecuteContinuous(&report)
-	default:
-		log.Fatalf("不明な操作: %s", operation)
 	}
 
+	var metricsServer *MetricsServer
+	if *metricsAddr != "" {
+		var err error
+		metricsServer, err = StartMetricsServer(*metricsAddr, metrics)
+		if err != nil {
+			exitConfigError(i18n.T("メトリクス開始エラー"), "error", err)
+		}
+		if verbose {
+			slog.Info(i18n.T("メトリクス配信中 (/metrics)"), "addr", metricsServer.Addr())
+		}
+	}
+
+	// The tag orchestrators register with the daemon; --validate and
+	// --register-watch use it too
+	watchTag := *tag
+	if *registerWatch != "" {
+		watchTag = *registerWatch
+	}
+	if watchTag == "" {
+		watchTag = fmt.Sprintf("test-process-%d", os.Getpid())
+	}
+
+	var daemon *DaemonClient
+	if *validate || *registerWatch != "" {
+		daemon = NewDaemonClient(*pipe)
+		// file-read is only checked if the daemon records every read
+		readSampleRate := 0
+		if *validate {
+			readSampleRate = 1
+		}
+		if err := daemon.AddWatchTarget(os.Getpid(), watchTag, readSampleRate); err != nil {
+			exitConfigError(i18n.T("監視対象登録エラー"), "error", err)
+		}
+		if verbose {
+			slog.Info(i18n.T("監視対象登録"), "tag", watchTag)
+		}
+	}
+
+	if *readyFile != "" {
+		// Remove a stale sentinel left by a previous run before anything else
+		os.Remove(*readyFile)
+	}
+
+	releaseResources := func() {
+		if daemon != nil {
+			if err := daemon.RemoveWatchTarget(watchTag); err != nil {
+				slog.Error(i18n.T("監視対象削除エラー"), "error", err)
+			}
+		}
+		if controlServer != nil {
+			controlServer.Close()
+		}
+		if metricsServer != nil {
+			metricsServer.Close()
+		}
+		if *readyFile != "" {
+			os.Remove(*readyFile)
+		}
+		if *pidFile != "" {
+			os.Remove(*pidFile)
+		}
+		if autoWorkdir != "" && !*keep {
+			if err := os.RemoveAll(autoWorkdir); err != nil {
+				slog.Error(i18n.T("作業ディレクトリ削除エラー"), "error", err)
+			} else if verbose {
+				slog.Info(i18n.T("作業ディレクトリ削除"), "dir", autoWorkdir)
+			}
+		}
+	}
+	// Early exits and the normal end of the run may both get here; only the
+	// first call removes files and closes servers
+	var cleanupOnce sync.Once
+	cleanup := func() { cleanupOnce.Do(releaseResources) }
+
+	// SIGINT/SIGTERM cancel the running operation, which then returns through
+	// the normal path below so that cleanup and the report still happen
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *pidFile != "" {
+		if err := writePIDFile(*pidFile); err != nil {
+			cleanup()
+			exitConfigError(i18n.T("PIDファイル作成エラー"), "error", err)
+		}
+		if verbose {
+			slog.Info(i18n.T("PIDファイル作成"), "path", *pidFile, "tag", watchTag)
+		}
+	}
+
+	if *readyFile != "" {
+		if err := writePIDFile(*readyFile); err != nil {
+			cleanup()
+			exitConfigError(i18n.T("センチネルファイル作成エラー"), "error", err)
+		}
+		if verbose {
+			slog.Info(i18n.T("準備完了センチネル作成"), "path", *readyFile)
+		}
+	}
+	if controlServer != nil {
+		controlServer.MarkReady()
+	}
+
+	if gate != nil {
+		if verbose {
+			slog.Info(i18n.T("制御チャネル待ち受け中 (startコマンドで開始)"), "control", *control)
+		}
+		gate.WaitStart(ctx)
+	}
+
+	report := Report{
+		Report: schema.Report{
+			SchemaVersion: schema.Version,
+			Operation:     operation,
+			Tag:           watchTag,
+			Config:        config,
+			StartTime:     time.Now(),
+			ProcessID:     os.Getpid(),
+		},
+		profile: profileSpec,
+		hooks:   operations.Hooks{Listener: onOperation, Gate: gate},
+	}
+	warmupEnd = report.StartTime.Add(*warmup)
+	metrics.Start(report.StartTime)
+	if verbosity >= operations.VerboseTiming {
+		slog.Info(i18n.T("準備所要時間"), "duration", report.StartTime.Sub(setupStart))
+	}
+	report.warmupEnd = warmupEnd
+
+	err = op.Run(ctx, report.Params())
+
 	report.EndTime = time.Now()
-	report.Duration = report.EndTime.Sub(report.StartTime)
+	report.Duration = schema.Duration(report.EndTime.Sub(report.StartTime))
+	// Operations planned up front include those that ran during the warm-up
+	report.TotalOps = max(report.TotalOps-report.WarmupOps, 0)
+	report.Latencies = latencies.Summaries()
+	report.Timeline = timeline.Entries()
+	report.ProcessTree = report.tree.Tree(os.Getpid())
+
+	// Give the daemon time to drain its ETW buffers before querying; an
+	// interrupted run is reported without validation
+	if *validate && waitUnlessInterrupted(ctx, *validateWait) {
+		events, vErr := daemon.GetRecordedEvents(watchTag)
+		if vErr != nil {
+			cleanup()
+			exitConfigError(i18n.T("記録イベント取得エラー"), "error", vErr)
+		}
+		report.Validation = validateTimeline(watchTag, report.Timeline, events, config.Dir)
+		if verbosity >= operations.VerboseTiming {
+			slog.Info(i18n.T("照合所要時間"), "duration", time.Since(report.EndTime))
+		}
+	}
 
-	if *jsonOut {
-		jsonData, _ := json.MarshalIndent(report, "", "  ")
-		fmt.Println(string(jsonData))
-	} else if *verbose {
-		log.Printf("実行完了: %s", operation)
-		log.Printf("総操作数: %d, 成功: %d, 失敗: %d", 
-			report.TotalOps, report.SuccessOps, report.FailedOps)
-		log.Printf("実行時間: %v", report.Duration)
+	interrupted := ctx.Err() != nil
+	if interrupted {
+		slog.Warn(i18n.T("シグナル受信により中断"))
 	}
+	cleanup()
 
-	if err != nil {
-		if *verbose {
-			log.Printf("エラー: %v", err)
+	if *format != "" {
+		if outErr := writeReport(os.Stdout, &report, *format, err); outErr != nil {
+			slog.Error(i18n.T("結果出力エラー"), "error", outErr)
+		}
+	} else if verbose {
+		slog.Info(i18n.T("実行完了"), "operation", operation)
+		slog.Info(i18n.T("操作数"), "total", report.TotalOps, "success", report.SuccessOps, "failed", report.FailedOps)
+		if report.WarmupOps > 0 {
+			slog.Info(i18n.T("ウォームアップの操作を統計から除外"), "operations", report.WarmupOps, "warmup", config.Warmup)
+		}
+		slog.Info(i18n.T("実行時間"), "duration", report.Duration)
+		if v := report.Validation; v != nil {
+			slog.Info(i18n.T("照合結果"), "matched", v.Matched, "missing", v.Missing, "extra", v.Extra, "unchecked", v.Unchecked, "daemon_events", v.Events)
+		}
+		for opType, l := range report.Latencies {
+			slog.Info(i18n.T("レイテンシ"), "type", opType, "p50_ms", l.P50, "p95_ms", l.P95, "p99_ms", l.P99, "count", l.Count)
 		}
-		os.Exit(1)
 	}
-}
\ No newline at end of file
+
+	if err != nil && verbose {
+		slog.Error(i18n.T("エラー"), "error", err)
+	}
+
+	if interrupted {
+		os.Exit(ExitSignal)
+	}
+	os.Exit(exitCodeFor(&report, err, *failThreshold))
+}
+
+// waitUnlessInterrupted waits for d and reports whether it elapsed before ctx
+// was cancelled by a signal
+func waitUnlessInterrupted(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"proctail-test-process/i18n"
+	"proctail-test-process/operations"
+	"proctail-test-process/schema"
+	"sync"
+	"time"
+)
+
+// metricsWindow is the span the current rate is averaged over
+const metricsWindow = 10 * time.Second
+
+// metricsRecorder keeps live counters for the metrics endpoint; operations
+// may report from several goroutines
+type metricsRecorder struct {
+	mu        sync.Mutex
+	operation string
+	start     time.Time
+	succeeded int
+	failed    int
+	warmup    int
+	lastError string
+	recent    []time.Time
+}
+
+// Metrics is the body served by the metrics endpoint
+type Metrics struct {
+	Operation string          `json:"operation"`
+	Elapsed   schema.Duration `json:"elapsed"`
+	Ops       int             `json:"operations"`
+	Succeeded int             `json:"successful_operations"`
+	Failed    int             `json:"failed_operations"`
+	WarmupOps int             `json:"warmup_operations,omitempty"`
+	Rate      float64         `json:"rate_per_second"`
+	LastError string          `json:"last_error,omitempty"`
+}
+
+func newMetricsRecorder(operation string) *metricsRecorder {
+	return &metricsRecorder{operation: operation}
+}
+
+// Start marks the beginning of the run; elapsed time and rate count from here
+func (m *metricsRecorder) Start(t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.start = t
+}
+
+// Record counts event; warmup operations are counted separately
+func (m *metricsRecorder) Record(event operations.OperationEvent, warmup bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if warmup {
+		m.warmup++
+		return
+	}
+	if event.Result == "failed" {
+		m.failed++
+		m.lastError = event.Error
+	} else {
+		m.succeeded++
+	}
+	m.recent = append(m.recent, event.Timestamp)
+	m.prune(event.Timestamp)
+}
+
+// prune drops completions older than metricsWindow before now
+func (m *metricsRecorder) prune(now time.Time) {
+	cutoff := now.Add(-metricsWindow)
+	i := 0
+	for i < len(m.recent) && m.recent[i].Before(cutoff) {
+		i++
+	}
+	m.recent = m.recent[i:]
+}
+
+// Snapshot returns the counters as of now
+func (m *metricsRecorder) Snapshot(now time.Time) Metrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metrics := Metrics{
+		Operation: m.operation,
+		Ops:       m.succeeded + m.failed,
+		Succeeded: m.succeeded,
+		Failed:    m.failed,
+		WarmupOps: m.warmup,
+		LastError: m.lastError,
+	}
+	if m.start.IsZero() {
+		return metrics
+	}
+
+	elapsed := now.Sub(m.start)
+	metrics.Elapsed = schema.Duration(elapsed)
+	m.prune(now)
+	// Early in the run the window is only as long as the run itself
+	if window := min(elapsed, metricsWindow); window > 0 {
+		metrics.Rate = float64(len(m.recent)) / window.Seconds()
+	}
+	return metrics
+}
+
+// MetricsServer serves live counters as JSON over HTTP at /metrics
+type MetricsServer struct {
+	listener net.Listener
+	server   *http.Server
+}
+
+// StartMetricsServer listens on addr ("127.0.0.1:9100"); a bare ":9100"
+// listens on localhost only
+func StartMetricsServer(addr string, recorder *metricsRecorder) (*MetricsServer, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("メトリクスのアドレスが不正です (HOST:PORT): %s"), addr)
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("メトリクス待ち受けエラー %s: %w"), addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(recorder.Snapshot(time.Now()))
+	})
+
+	s := &MetricsServer{
+		listener: listener,
+		server:   &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second},
+	}
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error(i18n.T("メトリクス配信エラー"), "error", err)
+		}
+	}()
+	return s, nil
+}
+
+// Addr returns the address the server listens on
+func (s *MetricsServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the server
+func (s *MetricsServer) Close() error {
+	return s.server.Close()
+}
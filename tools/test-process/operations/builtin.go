@@ -0,0 +1,77 @@
+package operations
+
+import "context"
+
+// fileOp adapts an Execute* function taking FileOptions
+func fileOp(desc string, fn func(context.Context, FileOptions, Reporter) error) Factory {
+	return func() Operation {
+		return Func{Desc: desc, Fn: func(ctx context.Context, p Params) error {
+			config := p.File
+			config.Hooks = p.Hooks
+			return fn(ctx, config, p.Report)
+		}}
+	}
+}
+
+// withPlan attaches a dry-run planner to a Func factory
+func withPlan(factory Factory, plan func(Params) []PlannedStep) Factory {
+	return func() Operation {
+		f := factory().(Func)
+		f.PlanFn = plan
+		return f
+	}
+}
+
+// processOp adapts an Execute* function taking ProcessOptions
+func processOp(desc string, fn func(context.Context, ProcessOptions, Reporter) error) Factory {
+	return func() Operation {
+		return Func{Desc: desc, Fn: func(ctx context.Context, p Params) error {
+			config := p.Process
+			config.Hooks = p.Hooks
+			return fn(ctx, config, p.Report)
+		}}
+	}
+}
+
+func init() {
+	Register("file-write", withPlan(fileOp("ファイル書き込み操作", ExecuteFileWrite), planFileWrite))
+	Register("file-read", withPlan(fileOp("ファイル読み込み操作", ExecuteFileRead), planFileRead))
+	Register("file-delete", withPlan(fileOp("ファイル削除操作", ExecuteFileDelete), planFileDelete))
+	Register("file-copy", fileOp("ファイルコピー (WindowsではCopyFileExW)", ExecuteFileCopy))
+	Register("dir-tree", withPlan(fileOp("ディレクトリツリーの作成と再帰削除", ExecuteDirTree), planDirTree))
+	Register("longpath", fileOp("MAX_PATH(260文字)を超えるパスでのファイル操作", ExecuteLongPath))
+	Register("unicode", fileOp("日本語・絵文字・結合文字・サロゲートペアのファイル名での操作", ExecuteUnicode))
+	Register("contend", fileOp("複数ゴルーチンによる同一ファイルの同時書き込み・リネーム", ExecuteContend))
+	Register("flush", withPlan(fileOp("小さなチャンクごとにSyncする書き込み", ExecuteFlush), planFlush))
+	Register("child-process", withPlan(processOp("子プロセス作成", ExecuteChildProcess), planChildProcess))
+	Register("stress", withPlan(fileOp("CPU・メモリ負荷 (--cpu, --memory, --duration)", ExecuteStress), planStress))
+	Register("crash", processOp("異常終了する子プロセスを作成 (--crash-mode)", ExecuteCrash))
+	Register("mixed", func() Operation {
+		return Func{Desc: "複数操作の組み合わせ", PlanFn: planMixed, Fn: func(ctx context.Context, p Params) error {
+			config := p.Mixed
+			config.Hooks = p.Hooks
+			return ExecuteMixed(ctx, config, p.Report)
+		}}
+	})
+	Register("continuous", withPlan(fileOp("継続実行モード (--durationまたは--profile必須)", ExecuteContinuous), planContinuous))
+	Register("burst", withPlan(fileOp("間隔なしの連続書き込みと休止の繰り返し", ExecuteBurst), planBurst))
+	Register("process-tree", withPlan(processOp("自身を再帰的に起動してプロセスツリーを作成", ExecuteProcessTree), planProcessTree))
+	Register("orphan", processOp("親より長く生存する切り離された子プロセスを作成", ExecuteOrphan))
+	Register("self-copy", processOp("自身をコピー・リネームして実行", ExecuteSelfCopy))
+	Register("delete-on-close", fileOp("FILE_FLAG_DELETE_ON_CLOSEでの書き込み (Windows専用)", ExecuteDeleteOnClose))
+	Register("move-replace", fileOp("MoveFileEx(MOVEFILE_REPLACE_EXISTING)での置換 (Windows専用)", ExecuteMoveReplace))
+	Register("handle-rename", fileOp("SetFileInformationByHandleでのリネーム (Windows専用)", ExecuteHandleRename))
+	Register("handle-delete", fileOp("SetFileInformationByHandleでの削除 (Windows専用)", ExecuteHandleDelete))
+
+	// Internal: children started by crash and orphan
+	Register("crash-child", func() Operation {
+		return Func{Desc: "crashが起動する子プロセス", Hidden: true, Fn: func(ctx context.Context, p Params) error {
+			return ExecuteCrashChild(p.Process.CrashMode)
+		}}
+	})
+	Register("orphan-child", func() Operation {
+		f := fileOp("orphanが起動する切り離された子プロセス", ExecuteOrphanChild)().(Func)
+		f.Hidden = true
+		return f
+	})
+}
@@ -0,0 +1,103 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"proctail-test-process/i18n"
+	"sync"
+	"time"
+)
+
+// contendRounds is the number of open/write/rename rounds each worker performs per iteration
+const contendRounds = 10
+
+// ExecuteContend runs Workers goroutines that open, write and rename the same
+// file simultaneously. Sharing violations and missing-file errors are expected
+// and counted as failures; use --fail-threshold to tolerate them.
+func ExecuteContend(ctx context.Context, config FileOptions, report Reporter) error {
+
+	if config.Workers <= 0 {
+		return fmt.Errorf(i18n.T("ワーカー数が不正です: %d"), config.Workers)
+	}
+
+	report.SetTotalOps(config.Count * config.Workers * contendRounds)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("競合操作開始"), "count", config.Count, "workers", config.Workers, "rounds", contendRounds)
+	}
+
+	// Report implementations are not goroutine-safe
+	var mu sync.Mutex
+	record := func(path string, start time.Time, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		config.notify("contend", path, start, err)
+		if err != nil {
+			report.AddError(fmt.Errorf(i18n.T("競合操作エラー %s: %w"), path, err))
+			report.IncrementFailed()
+		} else {
+			report.IncrementSuccess()
+		}
+	}
+
+	for i := 0; i < config.Count; i++ {
+		target := filepath.Join(config.Dir, fmt.Sprintf("test_contend_%d_%d.txt", os.Getpid(), i))
+
+		var wg sync.WaitGroup
+		release := make(chan struct{})
+		for w := 0; w < config.Workers; w++ {
+			wg.Add(1)
+			go func(worker int) {
+				defer wg.Done()
+				<-release
+				moved := fmt.Sprintf("%s.w%d", target, worker)
+				for r := 0; r < contendRounds; r++ {
+					start := time.Now()
+					err := contendRound(target, moved, worker, r)
+					record(target, start, err)
+				}
+				os.Remove(moved)
+			}(w)
+		}
+
+		// Release all workers at once to maximize overlap
+		close(release)
+		wg.Wait()
+		os.Remove(target)
+
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("競合操作完了"), "index", i+1, "count", config.Count, "path", target)
+		}
+
+		if i < config.Count-1 {
+			if err := config.sleep(ctx, config.Interval); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// contendRound appends to target, moves it aside and moves it back
+func contendRound(target, moved string, worker, round int) error {
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "worker %d round %d\n", worker, round)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(target, moved); err != nil {
+		return err
+	}
+	return os.Rename(moved, target)
+}
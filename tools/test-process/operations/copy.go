@@ -0,0 +1,33 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// copySourceSize is large enough that copy engines issue several read/write pairs
+const copySourceSize = 256 * 1024
+
+// ExecuteFileCopy copies files using the platform copy engine
+// (CopyFileExW on Windows) and removes both copies afterwards
+func ExecuteFileCopy(ctx context.Context, config FileOptions, report Reporter) error {
+	return runFileVariant(ctx, config, report, "file-copy", "ファイルコピー", func(i int) (string, error) {
+		dir := config.Dir
+		src := filepath.Join(dir, fmt.Sprintf("test_copy_src_%d_%d.txt", os.Getpid(), i))
+		dst := filepath.Join(dir, fmt.Sprintf("test_copy_dst_%d_%d.txt", os.Getpid(), i))
+
+		line := []byte(fmt.Sprintf("File copy source %d from PID %d\n", i+1, os.Getpid()))
+		content := bytes.Repeat(line, copySourceSize/len(line)+1)
+		if err := os.WriteFile(src, content, 0644); err != nil {
+			return src, err
+		}
+		defer os.Remove(src)
+
+		err := platformCopyFile(src, dst)
+		os.Remove(dst)
+		return dst, err
+	})
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+
+package operations
+
+import "os"
+
+// platformCopyFile copies content, then permissions and timestamps like cp -p
+func platformCopyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if err := copyFile(src, dst, info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := os.Chmod(dst, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
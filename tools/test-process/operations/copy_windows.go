@@ -0,0 +1,34 @@
+//go:build windows
+
+package operations
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procCopyFileExW = kernel32.NewProc("CopyFileExW")
+
+// platformCopyFile copies src to dst with CopyFileExW
+func platformCopyFile(src, dst string) error {
+	srcPtr, err := syscall.UTF16PtrFromString(src)
+	if err != nil {
+		return err
+	}
+	dstPtr, err := syscall.UTF16PtrFromString(dst)
+	if err != nil {
+		return err
+	}
+	r, _, err := procCopyFileExW.Call(
+		uintptr(unsafe.Pointer(srcPtr)),
+		uintptr(unsafe.Pointer(dstPtr)),
+		0, // lpProgressRoutine
+		0, // lpData
+		0, // pbCancel
+		0, // dwCopyFlags
+	)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
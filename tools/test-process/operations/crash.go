@@ -0,0 +1,153 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"proctail-test-process/i18n"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Crash modes supported by ExecuteCrash
+var crashModes = map[string]bool{
+	"panic":    true, // Go panic (exit code 2)
+	"exit":     true, // os.Exit(137)
+	"segfault": true, // NULL dereference through cgo
+	"signal":   true, // child is killed by the parent
+}
+
+// crashKillDelay is how long a "signal" mode child runs before being killed
+const crashKillDelay = 500 * time.Millisecond
+
+// crashExitCode is the exit code of an "exit" mode child
+const crashExitCode = 137
+
+// errSegfaultUnsupported is returned for the segfault mode in builds without cgo
+func errSegfaultUnsupported() error {
+	return fmt.Errorf(i18n.T("segfaultモードにはcgo有効ビルドが必要です: %w"), ErrUnsupportedPlatform)
+}
+
+// ExecuteCrash starts children that terminate abnormally in the configured mode
+// and records their exit codes
+func ExecuteCrash(ctx context.Context, config ProcessOptions, report Reporter) error {
+
+	if !crashModes[config.CrashMode] {
+		return fmt.Errorf(i18n.T("不明なクラッシュモード: %s"), config.CrashMode)
+	}
+	// Without cgo the child would only exit with an error, which isn't a crash
+	if config.CrashMode == "segfault" && !segfaultSupported {
+		return errSegfaultUnsupported()
+	}
+
+	report.SetTotalOps(config.Count)
+
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf(i18n.T("実行ファイルパス取得エラー: %w"), err)
+	}
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("クラッシュ操作開始"), "count", config.Count, "mode", config.CrashMode)
+	}
+
+	for i := 0; i < config.Count; i++ {
+		cmd := exec.CommandContext(ctx, self, "-crash-mode", config.CrashMode, "crash-child")
+		cmd.Env = childEnv()
+		stderr := &bytes.Buffer{}
+		cmd.Stderr = io.MultiWriter(os.Stderr, stderr)
+
+		start := time.Now()
+		if err := cmd.Start(); err != nil {
+			config.notify("crash", self, start, err)
+			report.AddError(fmt.Errorf(i18n.T("クラッシュ子プロセス開始エラー: %w"), err))
+			report.IncrementFailed()
+			continue
+		}
+
+		childPID := cmd.Process.Pid
+		report.AddChildPID(childPID)
+
+		if config.CrashMode == "signal" {
+			select {
+			case <-ctx.Done():
+				// CommandContext kills the child; reap it before returning
+				cmd.Wait()
+				reportExit(report, cmd)
+				return ctx.Err()
+			case <-time.After(crashKillDelay):
+				cmd.Process.Kill()
+			}
+		}
+
+		waitErr := cmd.Wait()
+		exitCode := reportExit(report, cmd)
+
+		// A crash is the expected outcome, but only the way the mode crashes;
+		// a clean exit or an ordinary error exit means the mode didn't work
+		var err error
+		if cmd.ProcessState == nil {
+			err = waitErr
+		} else if !crashedAsExpected(config.CrashMode, cmd.ProcessState, stderr.Bytes()) {
+			err = fmt.Errorf(i18n.T("子プロセスがクラッシュしませんでした PID %d: %s"), childPID, cmd.ProcessState)
+		}
+
+		config.notifyEvent(OperationEvent{Type: "crash", Path: self, PID: childPID, ExitCode: exitCode}, start, err)
+		if err != nil {
+			report.AddError(fmt.Errorf(i18n.T("クラッシュ操作エラー (%s): %w"), config.CrashMode, err))
+			report.IncrementFailed()
+		} else {
+			report.IncrementSuccess()
+			if config.Verbosity >= VerboseOps {
+				slog.Info(i18n.T("クラッシュ子プロセス終了"), "child_pid", childPID, "state", cmd.ProcessState)
+			}
+		}
+
+		if i < config.Count-1 {
+			if err := config.sleep(ctx, config.Interval); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// crashedAsExpected reports whether a crash child terminated the way its mode
+// crashes: killed by a signal (an exception on Windows) for segfault and
+// signal, the configured code for exit, and a Go panic for panic
+func crashedAsExpected(mode string, state *os.ProcessState, stderr []byte) bool {
+	switch mode {
+	case "panic":
+		return state.ExitCode() == 2 && strings.Contains(string(stderr), "panic: ")
+	case "exit":
+		return state.ExitCode() == crashExitCode
+	case "segfault":
+		return faulted(state)
+	case "signal":
+		return killed(state)
+	}
+	return false
+}
+
+// ExecuteCrashChild runs inside the child started by ExecuteCrash and terminates abnormally
+func ExecuteCrashChild(mode string) error {
+	switch mode {
+	case "panic":
+		panic("test-process: 意図的なpanic (PID " + strconv.Itoa(os.Getpid()) + ")")
+	case "exit":
+		os.Exit(crashExitCode)
+	case "segfault":
+		return segfault()
+	case "signal":
+		// Wait to be killed by the parent
+		time.Sleep(time.Minute)
+		return fmt.Errorf(i18n.T("親プロセスによって終了されませんでした"))
+	}
+	return fmt.Errorf(i18n.T("不明なクラッシュモード: %s"), mode)
+}
@@ -0,0 +1,28 @@
+//go:build cgo
+
+package operations
+
+/*
+#ifndef _WIN32
+#include <signal.h>
+#endif
+
+static void proctail_segfault(void) {
+#ifndef _WIN32
+	// Bypass the Go runtime's handler so the process dies from SIGSEGV itself
+	signal(SIGSEGV, SIG_DFL);
+#endif
+	volatile int *p = 0;
+	*p = 0;
+}
+*/
+import "C"
+
+// segfaultSupported reports whether the segfault mode can crash the process
+const segfaultSupported = true
+
+// segfault dereferences NULL in C so the process dies with an access violation
+func segfault() error {
+	C.proctail_segfault()
+	return nil
+}
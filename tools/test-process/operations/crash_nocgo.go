@@ -0,0 +1,12 @@
+//go:build !cgo
+
+package operations
+
+// segfaultSupported reports whether the segfault mode can crash the process;
+// pure Go builds turn faults into recoverable panics
+const segfaultSupported = false
+
+// segfault requires cgo
+func segfault() error {
+	return errSegfaultUnsupported()
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+
+package operations
+
+import (
+	"os"
+	"syscall"
+)
+
+// faulted reports whether the process died from a memory access fault
+func faulted(state *os.ProcessState) bool {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled() && (status.Signal() == syscall.SIGSEGV || status.Signal() == syscall.SIGBUS)
+}
+
+// killed reports whether the process was killed by the parent (SIGKILL)
+func killed(state *os.ProcessState) bool {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled() && status.Signal() == syscall.SIGKILL
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package operations
+
+import "os"
+
+// exceptionAccessViolation is the exit code of a process that died from an
+// access violation (STATUS_ACCESS_VIOLATION)
+const exceptionAccessViolation = 0xC0000005
+
+// faulted reports whether the process died from a memory access fault
+func faulted(state *os.ProcessState) bool {
+	return uint32(state.ExitCode()) == exceptionAccessViolation
+}
+
+// killed reports whether the process was killed by the parent; Process.Kill
+// terminates it with exit code 1
+func killed(state *os.ProcessState) bool {
+	return state.ExitCode() == 1
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package operations
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach makes cmd run in its own session so it survives the parent's exit
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package operations
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+const detachedProcess = 0x00000008 // DETACHED_PROCESS
+
+// detach makes cmd run without the parent's console so it survives the parent's exit
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: detachedProcess | syscall.CREATE_NEW_PROCESS_GROUP,
+	}
+}
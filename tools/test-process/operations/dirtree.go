@@ -0,0 +1,93 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"proctail-test-process/i18n"
+	"time"
+)
+
+// ExecuteDirTree builds a directory tree TreeDepth levels deep with TreeWidth
+// subdirectories per level and files in the leaves, then removes it with RemoveAll
+func ExecuteDirTree(ctx context.Context, config FileOptions, report Reporter) error {
+
+	if config.TreeDepth <= 0 || config.TreeWidth <= 0 {
+		return fmt.Errorf(i18n.T("ディレクトリツリーの深さ・幅が不正です: depth=%d, width=%d"), config.TreeDepth, config.TreeWidth)
+	}
+
+	report.SetTotalOps(config.Count * 2) // Build + RemoveAll
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("ディレクトリツリー操作開始"), "count", config.Count, "depth", config.TreeDepth, "width", config.TreeWidth, "files_per_leaf", config.TreeFiles)
+	}
+
+	for i := 0; i < config.Count; i++ {
+		root := filepath.Join(config.Dir, fmt.Sprintf("test_dirtree_%d_%d", os.Getpid(), i))
+
+		start := time.Now()
+		dirs, files, err := buildDirTree(root, config.TreeDepth, config.TreeWidth, config.TreeFiles)
+		config.notify("dir-tree-create", root, start, err)
+		if err != nil {
+			report.AddError(fmt.Errorf(i18n.T("ディレクトリツリー作成エラー %s: %w"), root, err))
+			report.IncrementFailed()
+		} else {
+			report.IncrementSuccess()
+			if config.Verbosity >= VerboseOps {
+				slog.Info(i18n.T("ディレクトリツリー作成完了"), "path", root, "dirs", dirs, "files", files)
+			}
+		}
+
+		start = time.Now()
+		err = os.RemoveAll(root)
+		config.notify("dir-tree-remove", root, start, err)
+		if err != nil {
+			report.AddError(fmt.Errorf(i18n.T("ディレクトリツリー削除エラー %s: %w"), root, err))
+			report.IncrementFailed()
+		} else {
+			report.IncrementSuccess()
+			if config.Verbosity >= VerboseOps {
+				slog.Info(i18n.T("ディレクトリツリー削除完了"), "path", root)
+			}
+		}
+
+		if i < config.Count-1 {
+			if err := config.sleep(ctx, config.Interval); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// buildDirTree creates the tree rooted at dir and returns the number of
+// directories and files created
+func buildDirTree(dir string, depth, width, filesPerLeaf int) (int, int, error) {
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return 0, 0, err
+	}
+
+	if depth == 0 {
+		for f := 0; f < filesPerLeaf; f++ {
+			path := filepath.Join(dir, fmt.Sprintf("leaf_%d.txt", f))
+			if err := os.WriteFile(path, []byte(path+"\n"), 0644); err != nil {
+				return 1, f, err
+			}
+		}
+		return 1, filesPerLeaf, nil
+	}
+
+	dirs, files := 1, 0
+	for w := 0; w < width; w++ {
+		d, f, err := buildDirTree(filepath.Join(dir, fmt.Sprintf("d%d", w)), depth-1, width, filesPerLeaf)
+		dirs += d
+		files += f
+		if err != nil {
+			return dirs, files, err
+		}
+	}
+	return dirs, files, nil
+}
@@ -0,0 +1,19 @@
+// Package operations generates file and process activity for ProcTail tests.
+//
+// Every Execute* function takes a context, the options for the operation and a
+// report that receives the counts, so tests can drive operations in-process:
+//
+//	err := operations.ExecuteFileWrite(ctx, operations.FileOptions{Count: 10, Dir: dir}, report)
+//
+// The options embed Hooks: Listener receives an OperationEvent after every
+// completed operation and Gate starts, pauses or single-steps the run. Hooks
+// belong to a single call, so several operations can run concurrently in one
+// process:
+//
+//	hooks := operations.Hooks{Listener: func(e operations.OperationEvent) { events <- e }}
+//	err := operations.ExecuteFileRead(ctx, operations.FileOptions{Count: 10, Dir: dir, Hooks: hooks}, report)
+//
+// Cancelling ctx stops the operation at its next wait point and kills child
+// processes started through it (except the intentionally detached orphan).
+// The operation then returns ctx.Err().
+package operations
@@ -0,0 +1,45 @@
+package operations
+
+import "time"
+
+// OperationEvent describes a single completed operation
+type OperationEvent struct {
+	Timestamp time.Time     `json:"timestamp"`
+	Start     time.Time     `json:"start"`
+	Latency   time.Duration `json:"latency_ns"`
+	Type      string        `json:"type"`
+	Path      string        `json:"path,omitempty"`
+	PID       int           `json:"pid,omitempty"`
+	ExitCode  int           `json:"exit_code,omitempty"`
+	Result    string        `json:"result"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// Hooks connects a run to its caller. Listener is invoked after every
+// completed operation and Gate lets a controller start, pause and
+// single-step the run; both are optional. Each run carries its own hooks,
+// so concurrent runs in one process do not see each other's operations.
+type Hooks struct {
+	Listener func(OperationEvent)
+	Gate     *Gate
+}
+
+// notify reports an operation that began at start and has just completed
+func (h Hooks) notify(opType, path string, start time.Time, err error) {
+	h.notifyEvent(OperationEvent{Type: opType, Path: path}, start, err)
+}
+
+func (h Hooks) notifyEvent(event OperationEvent, start time.Time, err error) {
+	if h.Listener == nil {
+		return
+	}
+	event.Timestamp = time.Now()
+	event.Start = start
+	event.Latency = event.Timestamp.Sub(start)
+	event.Result = "success"
+	if err != nil {
+		event.Result = "failed"
+		event.Error = err.Error()
+	}
+	h.Listener(event)
+}
@@ -1,63 +1,96 @@
 package operations
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"proctail-test-process/i18n"
+	"proctail-test-process/schema"
 	"time"
 )
 
-// Report is imported from main package (avoiding circular import by using interface)
-type FileReport interface {
-	GetConfig() Config
+// Reporter receives the results of every operation. main's Report implements
+// it; using an interface avoids importing the main package.
+type Reporter interface {
 	IncrementSuccess()
 	IncrementFailed()
 	AddError(error)
 	SetTotalOps(int)
+	AddChildPID(int)
+	// SetChildExitCode records the exit code of a child once it has exited
+	SetChildExitCode(pid, code int)
+	// AddDescendants records the processes started by child, as reported by
+	// the child itself
+	AddDescendants(child int, nodes []schema.ProcessNode)
 }
 
-type Config struct {
-	Count    int
-	Interval time.Duration
-	Dir      string
-	Verbose  bool
-	Duration time.Duration
+// FileOptions configures file operations
+type FileOptions struct {
+	Count     int
+	Interval  time.Duration
+	Dir       string
+	Verbosity int
+	Duration  time.Duration
+	Profile   *Profile
+	BurstSize int
+	TreeDepth int
+	TreeWidth int
+	TreeFiles int
+
+	StressCPU    int
+	StressMemory int64
+
+	PathLength int
+
+	Workers int
+
+	Chunks    int
+	ChunkSize int64
+
+	OrphanDelay time.Duration
+
+	Hooks
 }
 
 // ExecuteFileWrite performs file write operations
-func ExecuteFileWrite(report FileReport) error {
-	config := report.GetConfig()
+func ExecuteFileWrite(ctx context.Context, config FileOptions, report Reporter) error {
 	report.SetTotalOps(config.Count)
-	
-	if config.Verbose {
-		log.Printf("ファイル書き込み操作開始: %d回、間隔 %v", config.Count, config.Interval)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("ファイル書き込み操作開始"), "count", config.Count, "interval", config.Interval)
 	}
 
 	for i := 0; i < config.Count; i++ {
-		fileName := fmt.Sprintf("test_write_%d_%d.txt", os.Getpid(), i)
+		fileName := testFileName("write", i)
 		filePath := filepath.Join(config.Dir, fileName)
-		
-		content := fmt.Sprintf("Test write operation %d\nTimestamp: %s\nProcess ID: %d\n", 
+
+		content := fmt.Sprintf("Test write operation %d\nTimestamp: %s\nProcess ID: %d\n",
 			i+1, time.Now().Format(time.RFC3339), os.Getpid())
-		
-		if config.Verbose {
-			log.Printf("ファイル書き込み中: %s", filePath)
+
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("ファイル書き込み中"), "path", filePath)
 		}
 
+		start := time.Now()
 		err := os.WriteFile(filePath, []byte(content), 0644)
+		config.notify("file-write", filePath, start, err)
 		if err != nil {
-			report.AddError(fmt.Errorf("ファイル書き込みエラー %s: %w", filePath, err))
+			report.AddError(fmt.Errorf(i18n.T("ファイル書き込みエラー %s: %w"), filePath, err))
 			report.IncrementFailed()
 		} else {
 			report.IncrementSuccess()
-			if config.Verbose {
-				log.Printf("ファイル書き込み完了: %s", filePath)
+			logBytes(config.Verbosity, filePath, []byte(content))
+			if config.Verbosity >= VerboseOps {
+				slog.Info(i18n.T("ファイル書き込み完了"), "path", filePath)
 			}
 		}
 
 		if i < config.Count-1 {
-			time.Sleep(config.Interval)
+			if err := config.sleep(ctx, config.Interval); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -65,43 +98,45 @@ func ExecuteFileWrite(report FileReport) error {
 }
 
 // ExecuteFileRead performs file read operations
-func ExecuteFileRead(report FileReport) error {
-	config := report.GetConfig()
-	
+func ExecuteFileRead(ctx context.Context, config FileOptions, report Reporter) error {
+
 	// First create some files to read
 	tempFiles := make([]string, config.Count)
 	for i := 0; i < config.Count; i++ {
-		fileName := fmt.Sprintf("test_read_%d_%d.txt", os.Getpid(), i)
+		fileName := testFileName("read", i)
 		filePath := filepath.Join(config.Dir, fileName)
-		content := fmt.Sprintf("Test content for reading %d\nCreated: %s\n", 
+		content := fmt.Sprintf("Test content for reading %d\nCreated: %s\n",
 			i+1, time.Now().Format(time.RFC3339))
-		
+
 		err := os.WriteFile(filePath, []byte(content), 0644)
 		if err != nil {
-			return fmt.Errorf("事前ファイル作成エラー: %w", err)
+			return fmt.Errorf(i18n.T("事前ファイル作成エラー: %w"), err)
 		}
 		tempFiles[i] = filePath
 	}
 
 	report.SetTotalOps(config.Count)
-	
-	if config.Verbose {
-		log.Printf("ファイル読み込み操作開始: %d回、間隔 %v", config.Count, config.Interval)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("ファイル読み込み操作開始"), "count", config.Count, "interval", config.Interval)
 	}
 
 	for i, filePath := range tempFiles {
-		if config.Verbose {
-			log.Printf("ファイル読み込み中: %s", filePath)
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("ファイル読み込み中"), "path", filePath)
 		}
 
+		start := time.Now()
 		data, err := os.ReadFile(filePath)
+		config.notify("file-read", filePath, start, err)
 		if err != nil {
-			report.AddError(fmt.Errorf("ファイル読み込みエラー %s: %w", filePath, err))
+			report.AddError(fmt.Errorf(i18n.T("ファイル読み込みエラー %s: %w"), filePath, err))
 			report.IncrementFailed()
 		} else {
 			report.IncrementSuccess()
-			if config.Verbose {
-				log.Printf("ファイル読み込み完了: %s (%d bytes)", filePath, len(data))
+			logBytes(config.Verbosity, filePath, data)
+			if config.Verbosity >= VerboseOps {
+				slog.Info(i18n.T("ファイル読み込み完了"), "path", filePath, "bytes", len(data))
 			}
 		}
 
@@ -109,7 +144,9 @@ func ExecuteFileRead(report FileReport) error {
 		os.Remove(filePath)
 
 		if i < len(tempFiles)-1 {
-			time.Sleep(config.Interval)
+			if err := config.sleep(ctx, config.Interval); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -117,48 +154,51 @@ func ExecuteFileRead(report FileReport) error {
 }
 
 // ExecuteFileDelete performs file delete operations
-func ExecuteFileDelete(report FileReport) error {
-	config := report.GetConfig()
-	
+func ExecuteFileDelete(ctx context.Context, config FileOptions, report Reporter) error {
+
 	// First create some files to delete
 	tempFiles := make([]string, config.Count)
 	for i := 0; i < config.Count; i++ {
-		fileName := fmt.Sprintf("test_delete_%d_%d.txt", os.Getpid(), i)
+		fileName := testFileName("delete", i)
 		filePath := filepath.Join(config.Dir, fileName)
-		content := fmt.Sprintf("Test file for deletion %d\nCreated: %s\n", 
+		content := fmt.Sprintf("Test file for deletion %d\nCreated: %s\n",
 			i+1, time.Now().Format(time.RFC3339))
-		
+
 		err := os.WriteFile(filePath, []byte(content), 0644)
 		if err != nil {
-			return fmt.Errorf("事前ファイル作成エラー: %w", err)
+			return fmt.Errorf(i18n.T("事前ファイル作成エラー: %w"), err)
 		}
 		tempFiles[i] = filePath
 	}
 
 	report.SetTotalOps(config.Count)
-	
-	if config.Verbose {
-		log.Printf("ファイル削除操作開始: %d回、間隔 %v", config.Count, config.Interval)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("ファイル削除操作開始"), "count", config.Count, "interval", config.Interval)
 	}
 
 	for i, filePath := range tempFiles {
-		if config.Verbose {
-			log.Printf("ファイル削除中: %s", filePath)
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("ファイル削除中"), "path", filePath)
 		}
 
+		start := time.Now()
 		err := os.Remove(filePath)
+		config.notify("file-delete", filePath, start, err)
 		if err != nil {
-			report.AddError(fmt.Errorf("ファイル削除エラー %s: %w", filePath, err))
+			report.AddError(fmt.Errorf(i18n.T("ファイル削除エラー %s: %w"), filePath, err))
 			report.IncrementFailed()
 		} else {
 			report.IncrementSuccess()
-			if config.Verbose {
-				log.Printf("ファイル削除完了: %s", filePath)
+			if config.Verbosity >= VerboseOps {
+				slog.Info(i18n.T("ファイル削除完了"), "path", filePath)
 			}
 		}
 
 		if i < len(tempFiles)-1 {
-			time.Sleep(config.Interval)
+			if err := config.sleep(ctx, config.Interval); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -166,53 +206,56 @@ func ExecuteFileDelete(report FileReport) error {
 }
 
 // ExecuteFileRename performs file rename operations
-func ExecuteFileRename(report FileReport) error {
-	config := report.GetConfig()
-	
+func ExecuteFileRename(ctx context.Context, config FileOptions, report Reporter) error {
+
 	// First create some files to rename
 	tempFiles := make([]string, config.Count)
 	for i := 0; i < config.Count; i++ {
 		fileName := fmt.Sprintf("test_rename_old_%d_%d.txt", os.Getpid(), i)
 		filePath := filepath.Join(config.Dir, fileName)
-		content := fmt.Sprintf("Test file for renaming %d\nCreated: %s\n", 
+		content := fmt.Sprintf("Test file for renaming %d\nCreated: %s\n",
 			i+1, time.Now().Format(time.RFC3339))
-		
+
 		err := os.WriteFile(filePath, []byte(content), 0644)
 		if err != nil {
-			return fmt.Errorf("事前ファイル作成エラー: %w", err)
+			return fmt.Errorf(i18n.T("事前ファイル作成エラー: %w"), err)
 		}
 		tempFiles[i] = filePath
 	}
 
 	report.SetTotalOps(config.Count)
-	
-	if config.Verbose {
-		log.Printf("ファイルリネーム操作開始: %d回、間隔 %v", config.Count, config.Interval)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("ファイルリネーム操作開始"), "count", config.Count, "interval", config.Interval)
 	}
 
 	for i, oldPath := range tempFiles {
 		newFileName := fmt.Sprintf("test_rename_new_%d_%d.txt", os.Getpid(), i)
 		newPath := filepath.Join(config.Dir, newFileName)
-		
-		if config.Verbose {
-			log.Printf("ファイルリネーム中: %s -> %s", oldPath, newPath)
+
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("ファイルリネーム中"), "old_path", oldPath, "new_path", newPath)
 		}
 
+		start := time.Now()
 		err := os.Rename(oldPath, newPath)
+		config.notify("file-rename", newPath, start, err)
 		if err != nil {
-			report.AddError(fmt.Errorf("ファイルリネームエラー %s -> %s: %w", oldPath, newPath, err))
+			report.AddError(fmt.Errorf(i18n.T("ファイルリネームエラー %s -> %s: %w"), oldPath, newPath, err))
 			report.IncrementFailed()
 		} else {
 			report.IncrementSuccess()
-			if config.Verbose {
-				log.Printf("ファイルリネーム完了: %s -> %s", oldPath, newPath)
+			if config.Verbosity >= VerboseOps {
+				slog.Info(i18n.T("ファイルリネーム完了"), "old_path", oldPath, "new_path", newPath)
 			}
 			// Clean up the renamed file
 			os.Remove(newPath)
 		}
 
 		if i < len(tempFiles)-1 {
-			time.Sleep(config.Interval)
+			if err := config.sleep(ctx, config.Interval); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -220,107 +263,135 @@ func ExecuteFileRename(report FileReport) error {
 }
 
 // ExecuteDirectoryOps performs directory operations
-func ExecuteDirectoryOps(report FileReport) error {
-	config := report.GetConfig()
+func ExecuteDirectoryOps(ctx context.Context, config FileOptions, report Reporter) error {
 	report.SetTotalOps(config.Count * 2) // Create + Delete
-	
-	if config.Verbose {
-		log.Printf("ディレクトリ操作開始: %d回、間隔 %v", config.Count, config.Interval)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("ディレクトリ操作開始"), "count", config.Count, "interval", config.Interval)
 	}
 
 	for i := 0; i < config.Count; i++ {
 		dirName := fmt.Sprintf("test_dir_%d_%d", os.Getpid(), i)
 		dirPath := filepath.Join(config.Dir, dirName)
-		
+
 		// Create directory
-		if config.Verbose {
-			log.Printf("ディレクトリ作成中: %s", dirPath)
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("ディレクトリ作成中"), "path", dirPath)
 		}
 
+		start := time.Now()
 		err := os.Mkdir(dirPath, 0755)
+		config.notify("dir-create", dirPath, start, err)
 		if err != nil {
-			report.AddError(fmt.Errorf("ディレクトリ作成エラー %s: %w", dirPath, err))
+			report.AddError(fmt.Errorf(i18n.T("ディレクトリ作成エラー %s: %w"), dirPath, err))
 			report.IncrementFailed()
 		} else {
 			report.IncrementSuccess()
-			if config.Verbose {
-				log.Printf("ディレクトリ作成完了: %s", dirPath)
+			if config.Verbosity >= VerboseOps {
+				slog.Info(i18n.T("ディレクトリ作成完了"), "path", dirPath)
 			}
 		}
 
-		time.Sleep(config.Interval / 2)
+		if err := config.sleep(ctx, config.Interval/2); err != nil {
+			return err
+		}
 
 		// Delete directory
-		if config.Verbose {
-			log.Printf("ディレクトリ削除中: %s", dirPath)
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("ディレクトリ削除中"), "path", dirPath)
 		}
 
+		start = time.Now()
 		err = os.Remove(dirPath)
+		config.notify("dir-delete", dirPath, start, err)
 		if err != nil {
-			report.AddError(fmt.Errorf("ディレクトリ削除エラー %s: %w", dirPath, err))
+			report.AddError(fmt.Errorf(i18n.T("ディレクトリ削除エラー %s: %w"), dirPath, err))
 			report.IncrementFailed()
 		} else {
 			report.IncrementSuccess()
-			if config.Verbose {
-				log.Printf("ディレクトリ削除完了: %s", dirPath)
+			if config.Verbosity >= VerboseOps {
+				slog.Info(i18n.T("ディレクトリ削除完了"), "path", dirPath)
 			}
 		}
 
 		if i < config.Count-1 {
-			time.Sleep(config.Interval / 2)
+			if err := config.sleep(ctx, config.Interval/2); err != nil {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
 
-// ExecuteContinuous performs continuous file operations for specified duration
-func ExecuteContinuous(report FileReport) error {
-	config := report.GetConfig()
-	
+// continuousCycleOps is the number of operations in a continuous cycle
+// (write, read and delete)
+const continuousCycleOps = 3
+
+// ExecuteContinuous performs continuous file operations for specified duration.
+// With a profile, each cycle is scheduled continuousCycleOps/rate after the
+// previous one was due, so the operations follow the profile's rate
+// regardless of how long they take.
+func ExecuteContinuous(ctx context.Context, config FileOptions, report Reporter) error {
+
 	if config.Duration <= 0 {
-		return fmt.Errorf("継続実行時間が設定されていません")
+		return fmt.Errorf(i18n.T("継続実行時間が設定されていません"))
 	}
-	
-	if config.Verbose {
-		log.Printf("継続ファイル操作開始: %v間継続、間隔 %v", config.Duration, config.Interval)
+
+	if config.Verbosity >= VerboseOps {
+		if config.Profile != nil {
+			slog.Info(i18n.T("継続ファイル操作開始"), "duration", config.Duration, "profile", config.Profile)
+		} else {
+			slog.Info(i18n.T("継続ファイル操作開始"), "duration", config.Duration, "interval", config.Interval)
+		}
 	}
 
 	startTime := time.Now()
 	endTime := startTime.Add(config.Duration)
 	operationCount := 0
+	cycleDue := startTime
 
 	// Start continuous operations
 	for time.Now().Before(endTime) {
 		// Perform a cycle of write -> read -> delete operations
 		fileName := fmt.Sprintf("continuous_%d_%d.txt", os.Getpid(), operationCount)
 		filePath := filepath.Join(config.Dir, fileName)
-		
-		content := fmt.Sprintf("Continuous operation %d\nTimestamp: %s\nProcess ID: %d\n", 
+
+		content := fmt.Sprintf("Continuous operation %d\nTimestamp: %s\nProcess ID: %d\n",
 			operationCount+1, time.Now().Format(time.RFC3339), os.Getpid())
-		
-		if config.Verbose {
-			log.Printf("継続操作 %d: ファイル作成 -> 読み込み -> 削除", operationCount+1)
+
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("継続操作: ファイル作成 -> 読み込み -> 削除"), "cycle", operationCount+1)
 		}
 
 		// Write file
+		start := time.Now()
 		err := os.WriteFile(filePath, []byte(content), 0644)
+		config.notify("file-write", filePath, start, err)
 		if err != nil {
-			report.AddError(fmt.Errorf("継続書き込みエラー %s: %w", filePath, err))
+			report.AddError(fmt.Errorf(i18n.T("継続書き込みエラー %s: %w"), filePath, err))
 			report.IncrementFailed()
 		} else {
 			report.IncrementSuccess()
-			
+			logBytes(config.Verbosity, filePath, []byte(content))
+
 			// Read file
-			if _, err := os.ReadFile(filePath); err != nil {
-				report.AddError(fmt.Errorf("継続読み込みエラー %s: %w", filePath, err))
+			start := time.Now()
+			data, err := os.ReadFile(filePath)
+			config.notify("file-read", filePath, start, err)
+			if err != nil {
+				report.AddError(fmt.Errorf(i18n.T("継続読み込みエラー %s: %w"), filePath, err))
 				report.IncrementFailed()
 			} else {
 				report.IncrementSuccess()
-				
+				logBytes(config.Verbosity, filePath, data)
+
 				// Delete file
-				if err := os.Remove(filePath); err != nil {
-					report.AddError(fmt.Errorf("継続削除エラー %s: %w", filePath, err))
+				start := time.Now()
+				err := os.Remove(filePath)
+				config.notify("file-delete", filePath, start, err)
+				if err != nil {
+					report.AddError(fmt.Errorf(i18n.T("継続削除エラー %s: %w"), filePath, err))
 					report.IncrementFailed()
 				} else {
 					report.IncrementSuccess()
@@ -329,21 +400,88 @@ func ExecuteContinuous(report FileReport) error {
 		}
 
 		operationCount++
-		
+
+		next := time.Now().Add(config.Interval)
+		if config.Profile != nil {
+			cycleDue = cycleDue.Add(continuousCycleOps * config.Profile.IntervalAt(cycleDue.Sub(startTime)))
+			next = cycleDue
+		}
+
 		// Check if we should continue
-		if time.Now().Add(config.Interval).After(endTime) {
+		if next.After(endTime) {
+			break
+		}
+
+		// Stop early on cancellation but still record the cycles done so far.
+		// A cycle that is already overdue starts at once.
+		if config.sleep(ctx, time.Until(next)) != nil {
 			break
 		}
-		
-		time.Sleep(config.Interval)
 	}
 
-	report.SetTotalOps(operationCount * 3) // write + read + delete
-	
+	report.SetTotalOps(operationCount * continuousCycleOps)
+
 	actualDuration := time.Since(startTime)
-	if config.Verbose {
-		log.Printf("継続操作完了: %d回のサイクル、実行時間 %v", operationCount, actualDuration)
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("継続操作完了"), "cycles", operationCount, "duration", actualDuration)
+	}
+
+	return ctx.Err()
+}
+
+// ExecuteBurst performs bursts of file writes with no interval between writes,
+// pausing for the configured interval between bursts
+func ExecuteBurst(ctx context.Context, config FileOptions, report Reporter) error {
+
+	if config.BurstSize <= 0 {
+		return fmt.Errorf(i18n.T("バーストサイズが不正です: %d"), config.BurstSize)
+	}
+
+	report.SetTotalOps(config.Count * config.BurstSize)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("バースト書き込み操作開始"), "count", config.Count, "burst_size", config.BurstSize, "pause", config.Interval)
+	}
+
+	// Clean up after all bursts so deletions don't interleave with the write
+	// storm, and also when cancelled between bursts
+	var written []string
+	defer func() {
+		for _, filePath := range written {
+			os.Remove(filePath)
+		}
+	}()
+
+	for i := 0; i < config.Count; i++ {
+		burstStart := time.Now()
+		for j := 0; j < config.BurstSize; j++ {
+			fileName := fmt.Sprintf("test_burst_%d_%d_%d.txt", os.Getpid(), i, j)
+			filePath := filepath.Join(config.Dir, fileName)
+			content := fmt.Sprintf("Burst write %d.%d\nProcess ID: %d\n", i+1, j+1, os.Getpid())
+
+			start := time.Now()
+			err := os.WriteFile(filePath, []byte(content), 0644)
+			config.notify("file-write", filePath, start, err)
+			if err != nil {
+				report.AddError(fmt.Errorf(i18n.T("バースト書き込みエラー %s: %w"), filePath, err))
+				report.IncrementFailed()
+			} else {
+				report.IncrementSuccess()
+				logBytes(config.Verbosity, filePath, []byte(content))
+				written = append(written, filePath)
+			}
+		}
+
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("バースト完了"), "burst", i+1, "count", config.Count, "files", config.BurstSize, "duration", time.Since(burstStart))
+		}
+
+		if i < config.Count-1 {
+			if err := config.sleep(ctx, config.Interval); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
-}
\ No newline at end of file
+}
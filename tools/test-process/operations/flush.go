@@ -0,0 +1,76 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"proctail-test-process/i18n"
+	"time"
+)
+
+// ExecuteFlush writes each file in Chunks small chunks of ChunkSize bytes,
+// calling Sync after every chunk to produce a flush-per-write workload
+func ExecuteFlush(ctx context.Context, config FileOptions, report Reporter) error {
+
+	if config.Chunks <= 0 || config.ChunkSize <= 0 {
+		return fmt.Errorf(i18n.T("チャンク設定が不正です: chunks=%d, chunk-size=%d"), config.Chunks, config.ChunkSize)
+	}
+
+	report.SetTotalOps(config.Count * config.Chunks)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("フラッシュ操作開始"), "files", config.Count, "chunks", config.Chunks, "chunk_size", config.ChunkSize, "interval", config.Interval)
+	}
+
+	chunk := bytes.Repeat([]byte("f"), int(config.ChunkSize)-1)
+	chunk = append(chunk, '\n')
+
+	for i := 0; i < config.Count; i++ {
+		filePath := filepath.Join(config.Dir, testFileName("flush", i))
+
+		start := time.Now()
+		f, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			config.notify("flush", filePath, start, err)
+			report.AddError(fmt.Errorf(i18n.T("フラッシュ用ファイル作成エラー %s: %w"), filePath, err))
+			for c := 0; c < config.Chunks; c++ {
+				report.IncrementFailed()
+			}
+			continue
+		}
+
+		for c := 0; c < config.Chunks; c++ {
+			start := time.Now()
+			_, err := f.Write(chunk)
+			if err == nil {
+				err = f.Sync()
+			}
+			config.notify("flush", filePath, start, err)
+			if err != nil {
+				report.AddError(fmt.Errorf(i18n.T("フラッシュ書き込みエラー %s (チャンク %d): %w"), filePath, c, err))
+				report.IncrementFailed()
+			} else {
+				report.IncrementSuccess()
+				logBytes(config.Verbosity, filePath, chunk)
+			}
+		}
+
+		f.Close()
+		os.Remove(filePath)
+
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("フラッシュ操作完了"), "path", filePath)
+		}
+
+		if i < config.Count-1 {
+			if err := config.sleep(ctx, config.Interval); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package operations
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Gate lets an external controller start, pause and single-step operations.
+// Operations call wait between steps instead of sleeping directly.
+type Gate struct {
+	mu      sync.Mutex
+	running bool
+	changed chan struct{}
+	trigger chan struct{}
+}
+
+// NewGate creates a gate in the stopped state
+func NewGate() *Gate {
+	return &Gate{
+		changed: make(chan struct{}),
+		trigger: make(chan struct{}, 1),
+	}
+}
+
+// Start lets operations run
+func (g *Gate) Start() {
+	g.setRunning(true)
+}
+
+// Pause blocks operations at their next wait point
+func (g *Gate) Pause() {
+	g.setRunning(false)
+}
+
+// Trigger releases a single pending wait immediately, whether paused or not
+func (g *Gate) Trigger() {
+	select {
+	case g.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Running reports whether operations are currently allowed to run
+func (g *Gate) Running() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.running
+}
+
+func (g *Gate) setRunning(running bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.running == running {
+		return
+	}
+	g.running = running
+	close(g.changed)
+	g.changed = make(chan struct{})
+}
+
+// WaitStart blocks until the gate is started or triggered, or ctx is done
+func (g *Gate) WaitStart(ctx context.Context) error {
+	return g.Wait(ctx, 0)
+}
+
+// Wait sleeps for d while running, blocks while paused, and returns early on
+// trigger. It returns ctx.Err() if ctx is done first.
+func (g *Gate) Wait(ctx context.Context, d time.Duration) error {
+	deadline := time.Now().Add(d)
+	for {
+		g.mu.Lock()
+		running, changed := g.running, g.changed
+		g.mu.Unlock()
+
+		if !running {
+			select {
+			case <-g.trigger:
+				return nil
+			case <-changed:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(remaining)
+		select {
+		case <-timer.C:
+			return nil
+		case <-g.trigger:
+			timer.Stop()
+			return nil
+		case <-changed:
+			timer.Stop()
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// sleep waits between operation steps, honoring the run's gate if any.
+// It returns ctx.Err() if ctx is done before d elapses.
+func (h Hooks) sleep(ctx context.Context, d time.Duration) error {
+	if h.Gate != nil {
+		return h.Gate.Wait(ctx, d)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
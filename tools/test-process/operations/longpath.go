@@ -0,0 +1,60 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// longPathSegment is repeated to build directory chains beyond MAX_PATH
+const longPathSegment = "proctail_long_path_segment_0123456789abcdefghij"
+
+// ExecuteLongPath writes, reads and deletes files whose full path exceeds
+// PathLength characters, using \\?\ extended-length paths on Windows
+func ExecuteLongPath(ctx context.Context, config FileOptions, report Reporter) error {
+
+	return runFileVariant(ctx, config, report, "longpath", "ロングパス", func(i int) (string, error) {
+		root := filepath.Join(config.Dir, fmt.Sprintf("test_longpath_%d_%d", os.Getpid(), i))
+		dir := root
+		fileName := fmt.Sprintf("longpath_file_%d.txt", i)
+		for n := 0; len(filepath.Join(dir, fileName)) <= config.PathLength; n++ {
+			dir = filepath.Join(dir, fmt.Sprintf("%s_%02d", longPathSegment, n))
+		}
+
+		root = extendedLengthPath(root)
+		filePath := extendedLengthPath(filepath.Join(dir, fileName))
+		defer os.RemoveAll(root)
+
+		if err := os.MkdirAll(extendedLengthPath(dir), 0755); err != nil {
+			return filePath, err
+		}
+
+		content := fmt.Sprintf("Long path write %d (%d chars)\n", i+1, len(filePath))
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			return filePath, err
+		}
+		if _, err := os.ReadFile(filePath); err != nil {
+			return filePath, err
+		}
+		return filePath, os.Remove(filePath)
+	})
+}
+
+// extendedLengthPath converts an absolute Windows path to its \\?\ form.
+// Other platforms have no MAX_PATH limit and the path is returned as is.
+func extendedLengthPath(path string) string {
+	if runtime.GOOS != "windows" || strings.HasPrefix(path, `\\?\`) {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
@@ -1,37 +1,32 @@
 package operations
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"math/rand"
 	"os"
+	"proctail-test-process/i18n"
+	"proctail-test-process/schema"
 	"time"
 )
 
-// MixedReport interface for mixed operations
-type MixedReport interface {
-	GetConfig() MixedConfig
-	IncrementSuccess()
-	IncrementFailed()
-	AddError(error)
-	SetTotalOps(int)
-	AddChildPID(int)
-}
-
-type MixedConfig struct {
-	Count    int
-	Interval time.Duration
-	Dir      string
-	Verbose  bool
-	Command  string
-	Ops      []string
-	Duration time.Duration
+// MixedOptions configures the mixed operation
+type MixedOptions struct {
+	Count     int
+	Interval  time.Duration
+	Dir       string
+	Verbosity int
+	Command   string
+	Ops       []string
+	Duration  time.Duration
+
+	Hooks
 }
 
 // ExecuteMixed performs a combination of different operations
-func ExecuteMixed(report MixedReport) error {
-	config := report.GetConfig()
-	
+func ExecuteMixed(ctx context.Context, config MixedOptions, report Reporter) error {
 	// Parse operations list
 	operations := config.Ops
 	if len(operations) == 0 {
@@ -40,54 +35,50 @@ func ExecuteMixed(report MixedReport) error {
 
 	totalOps := config.Count * len(operations)
 	report.SetTotalOps(totalOps)
-	
-	if config.Verbose {
-		log.Printf("複合操作開始: %d回 x %d種類 = %d操作、間隔 %v", 
-			config.Count, len(operations), totalOps, config.Interval)
-		log.Printf("操作種類: %v", operations)
-	}
 
-	// Create a mixed report adapter that implements the required interfaces
-	adapter := &MixedReportAdapter{report: report}
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("複合操作開始"), "count", config.Count, "types", len(operations), "operations", totalOps, "interval", config.Interval)
+		slog.Info(i18n.T("操作種類"), "types", operations)
+	}
 
 	for i := 0; i < config.Count; i++ {
-		if config.Verbose {
-			log.Printf("=== 複合操作セット %d/%d ===", i+1, config.Count)
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("複合操作セット"), "set", i+1, "count", config.Count)
 		}
 
 		// Execute each operation type
 		for j, opType := range operations {
-			if config.Verbose {
-				log.Printf("操作 %d.%d: %s", i+1, j+1, opType)
+			if config.Verbosity >= VerboseOps {
+				slog.Info(i18n.T("操作"), "set", i+1, "index", j+1, "type", opType)
 			}
 
 			var err error
 			switch opType {
 			case "write", "file-write":
 				// Single file write
-				err = executeSingleFileWrite(adapter, i, j)
+				err = executeSingleFileWrite(ctx, config, report, i, j)
 			case "read", "file-read":
 				// Single file read
-				err = executeSingleFileRead(adapter, i, j)
+				err = executeSingleFileRead(ctx, config, report, i, j)
 			case "delete", "file-delete":
 				// Single file delete
-				err = executeSingleFileDelete(adapter, i, j)
+				err = executeSingleFileDelete(ctx, config, report, i, j)
 			case "rename", "file-rename":
 				// Single file rename
-				err = executeSingleFileRename(adapter, i, j)
+				err = executeSingleFileRename(ctx, config, report, i, j)
 			case "process", "child-process":
 				// Single child process
-				err = executeSingleChildProcess(adapter, i, j)
+				err = executeSingleChildProcess(ctx, config, report, i, j)
 			case "dir", "directory":
 				// Directory operations
-				err = executeSingleDirectoryOp(adapter, i, j)
+				err = executeSingleDirectoryOp(ctx, config, report, i, j)
 			default:
 				// Random operation
-				err = executeRandomOperation(adapter, i, j)
+				err = executeRandomOperation(ctx, config, report, i, j)
 			}
 
 			if err != nil {
-				report.AddError(fmt.Errorf("複合操作エラー %d.%d (%s): %w", i+1, j+1, opType, err))
+				report.AddError(fmt.Errorf(i18n.T("複合操作エラー %d.%d (%s): %w"), i+1, j+1, opType, err))
 				report.IncrementFailed()
 			} else {
 				report.IncrementSuccess()
@@ -95,112 +86,87 @@ func ExecuteMixed(report MixedReport) error {
 
 			// Wait between operations within the same set
 			if j < len(operations)-1 {
-				time.Sleep(config.Interval / time.Duration(len(operations)))
+				if err := config.sleep(ctx, config.Interval/time.Duration(len(operations))); err != nil {
+					return err
+				}
 			}
 		}
 
 		// Wait between operation sets
 		if i < config.Count-1 {
-			time.Sleep(config.Interval)
+			if err := config.sleep(ctx, config.Interval); err != nil {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
 
-// MixedReportAdapter adapts MixedReport to other report interfaces
-type MixedReportAdapter struct {
-	report MixedReport
-}
-
-func (a *MixedReportAdapter) GetConfig() Config {
-	config := a.report.GetConfig()
-	return Config{
-		Count:    config.Count,
-		Interval: config.Interval,
-		Dir:      config.Dir,
-		Verbose:  config.Verbose,
-		Duration: config.Duration,
-	}
-}
-
-func (a *MixedReportAdapter) GetProcessConfig() ProcessConfig {
-	config := a.report.GetConfig()
-	return ProcessConfig{
-		Count:    config.Count,
-		Interval: config.Interval,
-		Dir:      config.Dir,
-		Verbose:  config.Verbose,
-		Command:  config.Command,
-		Duration: config.Duration,
+// processOptions derives the options for a nested child-process step
+func (o MixedOptions) processOptions() ProcessOptions {
+	return ProcessOptions{
+		Count:     o.Count,
+		Interval:  o.Interval,
+		Dir:       o.Dir,
+		Verbosity: o.Verbosity,
+		Command:   o.Command,
+		Duration:  o.Duration,
+		Hooks:     o.Hooks,
 	}
 }
 
-func (a *MixedReportAdapter) IncrementSuccess() {
-	// Don't increment here, let the main report handle it
-}
-
-func (a *MixedReportAdapter) IncrementFailed() {
-	// Don't increment here, let the main report handle it
-}
-
-func (a *MixedReportAdapter) AddError(err error) {
-	// Don't add here, let the main report handle it
-}
-
-func (a *MixedReportAdapter) SetTotalOps(count int) {
-	// Don't set here, let the main report handle it
-}
-
-func (a *MixedReportAdapter) AddChildPID(pid int) {
-	a.report.AddChildPID(pid)
-}
-
 // Individual operation executors
-func executeSingleFileWrite(adapter *MixedReportAdapter, setNum, opNum int) error {
-	config := adapter.GetConfig()
+func executeSingleFileWrite(ctx context.Context, config MixedOptions, report Reporter, setNum, opNum int) error {
 	fileName := fmt.Sprintf("mixed_write_%d_%d_%d.txt", os.Getpid(), setNum, opNum)
 	filePath := fmt.Sprintf("%s/%s", config.Dir, fileName)
-	
-	content := fmt.Sprintf("Mixed write operation %d.%d\nTimestamp: %s\nPID: %d\n", 
+
+	content := fmt.Sprintf("Mixed write operation %d.%d\nTimestamp: %s\nPID: %d\n",
 		setNum+1, opNum+1, time.Now().Format(time.RFC3339), os.Getpid())
-	
-	if config.Verbose {
-		log.Printf("  ファイル書き込み: %s", filePath)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("ファイル書き込み中"), "path", filePath)
 	}
 
+	start := time.Now()
 	err := os.WriteFile(filePath, []byte(content), 0644)
-	if err == nil && config.Verbose {
-		log.Printf("  ファイル書き込み完了: %s", filePath)
+	config.notify("file-write", filePath, start, err)
+	if err == nil && config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("ファイル書き込み完了"), "path", filePath)
+		logBytes(config.Verbosity, filePath, []byte(content))
 	}
 	return err
 }
 
-func executeSingleFileRead(adapter *MixedReportAdapter, setNum, opNum int) error {
-	config := adapter.GetConfig()
-	
+func executeSingleFileRead(ctx context.Context, config MixedOptions, report Reporter, setNum, opNum int) error {
+
 	// Create a temporary file to read
 	fileName := fmt.Sprintf("mixed_read_%d_%d_%d.txt", os.Getpid(), setNum, opNum)
 	filePath := fmt.Sprintf("%s/%s", config.Dir, fileName)
-	
-	content := fmt.Sprintf("Mixed read test %d.%d\nCreated: %s\n", 
+
+	content := fmt.Sprintf("Mixed read test %d.%d\nCreated: %s\n",
 		setNum+1, opNum+1, time.Now().Format(time.RFC3339))
-	
+
 	// Write file first
+	start := time.Now()
 	err := os.WriteFile(filePath, []byte(content), 0644)
 	if err != nil {
+		config.notify("file-read", filePath, start, err)
 		return err
 	}
-	
-	if config.Verbose {
-		log.Printf("  ファイル読み込み: %s", filePath)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("ファイル読み込み中"), "path", filePath)
 	}
 
 	// Read the file
+	start = time.Now()
 	data, err := os.ReadFile(filePath)
+	config.notify("file-read", filePath, start, err)
 	if err == nil {
-		if config.Verbose {
-			log.Printf("  ファイル読み込み完了: %s (%d bytes)", filePath, len(data))
+		logBytes(config.Verbosity, filePath, data)
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("ファイル読み込み完了"), "path", filePath, "bytes", len(data))
 		}
 		// Clean up
 		os.Remove(filePath)
@@ -208,61 +174,67 @@ func executeSingleFileRead(adapter *MixedReportAdapter, setNum, opNum int) error
 	return err
 }
 
-func executeSingleFileDelete(adapter *MixedReportAdapter, setNum, opNum int) error {
-	config := adapter.GetConfig()
-	
+func executeSingleFileDelete(ctx context.Context, config MixedOptions, report Reporter, setNum, opNum int) error {
+
 	// Create a temporary file to delete
 	fileName := fmt.Sprintf("mixed_delete_%d_%d_%d.txt", os.Getpid(), setNum, opNum)
 	filePath := fmt.Sprintf("%s/%s", config.Dir, fileName)
-	
-	content := fmt.Sprintf("Mixed delete test %d.%d\nCreated: %s\n", 
+
+	content := fmt.Sprintf("Mixed delete test %d.%d\nCreated: %s\n",
 		setNum+1, opNum+1, time.Now().Format(time.RFC3339))
-	
+
 	// Write file first
+	start := time.Now()
 	err := os.WriteFile(filePath, []byte(content), 0644)
 	if err != nil {
+		config.notify("file-delete", filePath, start, err)
 		return err
 	}
-	
-	if config.Verbose {
-		log.Printf("  ファイル削除: %s", filePath)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("ファイル削除中"), "path", filePath)
 	}
 
 	// Delete the file
+	start = time.Now()
 	err = os.Remove(filePath)
-	if err == nil && config.Verbose {
-		log.Printf("  ファイル削除完了: %s", filePath)
+	config.notify("file-delete", filePath, start, err)
+	if err == nil && config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("ファイル削除完了"), "path", filePath)
 	}
 	return err
 }
 
-func executeSingleFileRename(adapter *MixedReportAdapter, setNum, opNum int) error {
-	config := adapter.GetConfig()
-	
+func executeSingleFileRename(ctx context.Context, config MixedOptions, report Reporter, setNum, opNum int) error {
+
 	// Create a temporary file to rename
 	oldFileName := fmt.Sprintf("mixed_rename_old_%d_%d_%d.txt", os.Getpid(), setNum, opNum)
 	newFileName := fmt.Sprintf("mixed_rename_new_%d_%d_%d.txt", os.Getpid(), setNum, opNum)
 	oldPath := fmt.Sprintf("%s/%s", config.Dir, oldFileName)
 	newPath := fmt.Sprintf("%s/%s", config.Dir, newFileName)
-	
-	content := fmt.Sprintf("Mixed rename test %d.%d\nCreated: %s\n", 
+
+	content := fmt.Sprintf("Mixed rename test %d.%d\nCreated: %s\n",
 		setNum+1, opNum+1, time.Now().Format(time.RFC3339))
-	
+
 	// Write file first
+	start := time.Now()
 	err := os.WriteFile(oldPath, []byte(content), 0644)
 	if err != nil {
+		config.notify("file-rename", newPath, start, err)
 		return err
 	}
-	
-	if config.Verbose {
-		log.Printf("  ファイルリネーム: %s -> %s", oldPath, newPath)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("ファイルリネーム中"), "old_path", oldPath, "new_path", newPath)
 	}
 
 	// Rename the file
+	start = time.Now()
 	err = os.Rename(oldPath, newPath)
+	config.notify("file-rename", newPath, start, err)
 	if err == nil {
-		if config.Verbose {
-			log.Printf("  ファイルリネーム完了: %s -> %s", oldPath, newPath)
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("ファイルリネーム完了"), "old_path", oldPath, "new_path", newPath)
 		}
 		// Clean up
 		os.Remove(newPath)
@@ -270,106 +242,118 @@ func executeSingleFileRename(adapter *MixedReportAdapter, setNum, opNum int) err
 	return err
 }
 
-func executeSingleChildProcess(adapter *MixedReportAdapter, setNum, opNum int) error {
-	// This is a simplified version - we'll create a single child process
-	config := adapter.GetProcessConfig()
-	
-	if config.Verbose {
-		log.Printf("  子プロセス作成 %d.%d", setNum+1, opNum+1)
+func executeSingleChildProcess(ctx context.Context, config MixedOptions, report Reporter, setNum, opNum int) error {
+	// Run the child-process operation once as a single step of this set
+	opts := config.processOptions()
+	opts.Count = 1
+
+	if opts.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("子プロセス作成"), "set", setNum+1, "index", opNum+1)
 	}
 
-	// Create a simple mock report for the child process operation
-	mockReport := &SimpleMockReport{
-		config: config,
-		adapter: adapter,
+	nested := &nestedReporter{parent: report}
+	if err := ExecuteChildProcess(ctx, opts, nested); err != nil {
+		return err
 	}
-	
-	// Set count to 1 for single operation
-	mockReport.config.Count = 1
-	
-	return ExecuteChildProcess(mockReport)
+	return nested.err()
 }
 
-func executeSingleDirectoryOp(adapter *MixedReportAdapter, setNum, opNum int) error {
-	config := adapter.GetConfig()
-	
+func executeSingleDirectoryOp(ctx context.Context, config MixedOptions, report Reporter, setNum, opNum int) error {
+
 	dirName := fmt.Sprintf("mixed_dir_%d_%d_%d", os.Getpid(), setNum, opNum)
 	dirPath := fmt.Sprintf("%s/%s", config.Dir, dirName)
-	
-	if config.Verbose {
-		log.Printf("  ディレクトリ作成/削除: %s", dirPath)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("ディレクトリ作成/削除"), "path", dirPath)
 	}
 
 	// Create directory
+	start := time.Now()
 	err := os.Mkdir(dirPath, 0755)
+	config.notify("dir-create", dirPath, start, err)
 	if err != nil {
 		return err
 	}
-	
+
 	// Wait a bit
 	time.Sleep(100 * time.Millisecond)
-	
+
 	// Delete directory
+	start = time.Now()
 	err = os.Remove(dirPath)
-	if err == nil && config.Verbose {
-		log.Printf("  ディレクトリ作成/削除完了: %s", dirPath)
+	config.notify("dir-delete", dirPath, start, err)
+	if err == nil && config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("ディレクトリ作成/削除完了"), "path", dirPath)
 	}
 	return err
 }
 
-func executeRandomOperation(adapter *MixedReportAdapter, setNum, opNum int) error {
+func executeRandomOperation(ctx context.Context, config MixedOptions, report Reporter, setNum, opNum int) error {
 	// Choose a random operation
 	operations := []string{"write", "read", "delete", "rename", "dir"}
 	rand.Seed(time.Now().UnixNano())
 	opType := operations[rand.Intn(len(operations))]
-	
-	config := adapter.GetConfig()
-	if config.Verbose {
-		log.Printf("  ランダム操作: %s", opType)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("ランダム操作"), "type", opType)
 	}
 
 	switch opType {
 	case "write":
-		return executeSingleFileWrite(adapter, setNum, opNum)
+		return executeSingleFileWrite(ctx, config, report, setNum, opNum)
 	case "read":
-		return executeSingleFileRead(adapter, setNum, opNum)
+		return executeSingleFileRead(ctx, config, report, setNum, opNum)
 	case "delete":
-		return executeSingleFileDelete(adapter, setNum, opNum)
+		return executeSingleFileDelete(ctx, config, report, setNum, opNum)
 	case "rename":
-		return executeSingleFileRename(adapter, setNum, opNum)
+		return executeSingleFileRename(ctx, config, report, setNum, opNum)
 	case "dir":
-		return executeSingleDirectoryOp(adapter, setNum, opNum)
+		return executeSingleDirectoryOp(ctx, config, report, setNum, opNum)
 	default:
-		return executeSingleFileWrite(adapter, setNum, opNum)
+		return executeSingleFileWrite(ctx, config, report, setNum, opNum)
 	}
 }
 
-// SimpleMockReport implements ProcessReport for single child process operations
-type SimpleMockReport struct {
-	config ProcessConfig
-	adapter *MixedReportAdapter
+// nestedReporter runs a whole operation as one step of a mixed set. Child PIDs
+// reach the parent report; the outcome is returned by err so that ExecuteMixed
+// counts the step exactly once.
+type nestedReporter struct {
+	parent Reporter
+	errs   []error
+	failed int
 }
 
-func (m *SimpleMockReport) GetConfig() ProcessConfig {
-	return m.config
+func (n *nestedReporter) IncrementSuccess() {}
+
+func (n *nestedReporter) IncrementFailed() {
+	n.failed++
 }
 
-func (m *SimpleMockReport) IncrementSuccess() {
-	// The actual counting is handled by the main report
+func (n *nestedReporter) AddError(err error) {
+	n.errs = append(n.errs, err)
 }
 
-func (m *SimpleMockReport) IncrementFailed() {
-	// The actual counting is handled by the main report
+func (n *nestedReporter) SetTotalOps(count int) {}
+
+func (n *nestedReporter) AddChildPID(pid int) {
+	n.parent.AddChildPID(pid)
 }
 
-func (m *SimpleMockReport) AddError(err error) {
-	// Errors are handled by the main report
+func (n *nestedReporter) SetChildExitCode(pid, code int) {
+	n.parent.SetChildExitCode(pid, code)
 }
 
-func (m *SimpleMockReport) SetTotalOps(count int) {
-	// Total ops are managed by the main report
+func (n *nestedReporter) AddDescendants(child int, nodes []schema.ProcessNode) {
+	n.parent.AddDescendants(child, nodes)
 }
 
-func (m *SimpleMockReport) AddChildPID(pid int) {
-	m.adapter.AddChildPID(pid)
-}
\ No newline at end of file
+// err reports the nested operation's failures as a single error
+func (n *nestedReporter) err() error {
+	if n.failed == 0 {
+		return nil
+	}
+	if len(n.errs) > 0 {
+		return errors.Join(n.errs...)
+	}
+	return fmt.Errorf(i18n.T("%d件の操作が失敗しました"), n.failed)
+}
@@ -0,0 +1,213 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// PlannedStep is one operation a run would perform, for --dry-run.
+// Offset only accounts for the configured intervals, not for the time the
+// operations themselves take.
+type PlannedStep struct {
+	Offset  time.Duration `json:"offset"`
+	Type    string        `json:"type"`
+	Path    string        `json:"path,omitempty"`
+	Command string        `json:"command,omitempty"`
+}
+
+// Planner is implemented by operations that can describe their steps
+// without touching the filesystem
+type Planner interface {
+	Plan(p Params) []PlannedStep
+}
+
+// Plan describes the steps op would perform. Operations without a planner
+// are described as Count steps spaced by Interval.
+func Plan(name string, op Operation, p Params) []PlannedStep {
+	if planner, ok := op.(Planner); ok {
+		if steps := planner.Plan(p); steps != nil {
+			return steps
+		}
+	}
+	return planSeries(p.File.Count, p.File.Interval, name, func(i int) string { return "" })
+}
+
+// testFileName is the name of the i-th file of a simple file operation
+func testFileName(kind string, i int) string {
+	return fmt.Sprintf("test_%s_%d_%d.txt", kind, os.Getpid(), i)
+}
+
+// planSeries plans count steps of opType spaced by interval
+func planSeries(count int, interval time.Duration, opType string, path func(i int) string) []PlannedStep {
+	var steps []PlannedStep
+	for i := 0; i < count; i++ {
+		steps = append(steps, PlannedStep{Offset: time.Duration(i) * interval, Type: opType, Path: path(i)})
+	}
+	return steps
+}
+
+// planPrepared plans files created up front and then operated on one by one
+func planPrepared(config FileOptions, kind, opType string) []PlannedStep {
+	path := func(i int) string { return filepath.Join(config.Dir, testFileName(kind, i)) }
+	steps := planSeries(config.Count, 0, "file-write", path)
+	return append(steps, planSeries(config.Count, config.Interval, opType, path)...)
+}
+
+func planFileWrite(p Params) []PlannedStep {
+	return planSeries(p.File.Count, p.File.Interval, "file-write", func(i int) string {
+		return filepath.Join(p.File.Dir, testFileName("write", i))
+	})
+}
+
+func planFileRead(p Params) []PlannedStep {
+	return planPrepared(p.File, "read", "file-read")
+}
+
+func planFileDelete(p Params) []PlannedStep {
+	return planPrepared(p.File, "delete", "file-delete")
+}
+
+func planFlush(p Params) []PlannedStep {
+	var steps []PlannedStep
+	for i := 0; i < p.File.Count; i++ {
+		path := filepath.Join(p.File.Dir, testFileName("flush", i))
+		for c := 0; c < p.File.Chunks; c++ {
+			steps = append(steps, PlannedStep{Offset: time.Duration(i) * p.File.Interval, Type: "flush", Path: path})
+		}
+	}
+	return steps
+}
+
+func planBurst(p Params) []PlannedStep {
+	var steps []PlannedStep
+	for i := 0; i < p.File.Count; i++ {
+		for j := 0; j < p.File.BurstSize; j++ {
+			path := filepath.Join(p.File.Dir, fmt.Sprintf("test_burst_%d_%d_%d.txt", os.Getpid(), i, j))
+			steps = append(steps, PlannedStep{Offset: time.Duration(i) * p.File.Interval, Type: "file-write", Path: path})
+		}
+	}
+	return steps
+}
+
+func planDirTree(p Params) []PlannedStep {
+	var steps []PlannedStep
+	for i := 0; i < p.File.Count; i++ {
+		root := filepath.Join(p.File.Dir, fmt.Sprintf("test_dirtree_%d_%d", os.Getpid(), i))
+		offset := time.Duration(i) * p.File.Interval
+		steps = append(steps,
+			PlannedStep{Offset: offset, Type: "dir-tree-create", Path: root},
+			PlannedStep{Offset: offset, Type: "dir-tree-remove", Path: root})
+	}
+	return steps
+}
+
+func planContinuous(p Params) []PlannedStep {
+	config := p.File
+	var steps []PlannedStep
+	for n, offset := 0, time.Duration(0); offset < config.Duration; n++ {
+		path := filepath.Join(config.Dir, fmt.Sprintf("continuous_%d_%d.txt", os.Getpid(), n))
+		for _, opType := range []string{"file-write", "file-read", "file-delete"} {
+			steps = append(steps, PlannedStep{Offset: offset, Type: opType, Path: path})
+		}
+
+		interval := config.Interval
+		if config.Profile != nil {
+			interval = continuousCycleOps * config.Profile.IntervalAt(offset)
+		}
+		if offset+interval > config.Duration || interval <= 0 {
+			break
+		}
+		offset += interval
+	}
+	return steps
+}
+
+// plannedCommand describes the command ExecuteChildProcess runs
+func plannedCommand(command string) string {
+	if command != "" {
+		return command
+	}
+	if runtime.GOOS == "windows" {
+		return "cmd /c echo + timeout 1"
+	}
+	return "sh -c echo + sleep 1"
+}
+
+func planChildProcess(p Params) []PlannedStep {
+	command := plannedCommand(p.Process.Command)
+	var steps []PlannedStep
+	for i := 0; i < p.Process.Count; i++ {
+		steps = append(steps, PlannedStep{Offset: time.Duration(i) * p.Process.Interval, Type: "child-process", Command: command})
+	}
+	return steps
+}
+
+func planProcessTree(p Params) []PlannedStep {
+	config := p.Process
+	var steps []PlannedStep
+	// Every level starts at once; each node then waits for its own children
+	nodes := 1
+	for level := 1; level <= config.TreeDepth; level++ {
+		nodes *= config.TreeWidth
+		command := fmt.Sprintf("test-process -tree-depth %d -tree-width %d process-tree", config.TreeDepth-level, config.TreeWidth)
+		for n := 0; n < nodes; n++ {
+			steps = append(steps, PlannedStep{Type: "process-tree", Command: command})
+		}
+	}
+	return steps
+}
+
+func planMixed(p Params) []PlannedStep {
+	config := p.Mixed
+	ops := config.Ops
+	if len(ops) == 0 {
+		ops = []string{"write", "read", "delete"}
+	}
+	step := config.Interval / time.Duration(len(ops))
+
+	var steps []PlannedStep
+	offset := time.Duration(0)
+	for i := 0; i < config.Count; i++ {
+		for j, op := range ops {
+			planned := PlannedStep{Offset: offset, Type: "mixed:" + op, Path: mixedPlanPath(config.Dir, op, i, j)}
+			if op == "process" || op == "child-process" {
+				planned.Command = plannedCommand(config.Command)
+			}
+			steps = append(steps, planned)
+			if j < len(ops)-1 {
+				offset += step
+			}
+		}
+		offset += config.Interval
+	}
+	return steps
+}
+
+// mixedPlanPath mirrors the names used by the executeSingle* functions;
+// child processes and random operations have no fixed path
+func mixedPlanPath(dir, op string, set, index int) string {
+	var name string
+	switch op {
+	case "write", "file-write", "read", "file-read", "delete", "file-delete":
+		name = fmt.Sprintf("mixed_%s_%d_%d_%d.txt", strings.TrimPrefix(op, "file-"), os.Getpid(), set, index)
+	case "rename", "file-rename":
+		name = fmt.Sprintf("mixed_rename_new_%d_%d_%d.txt", os.Getpid(), set, index)
+	case "dir", "directory":
+		name = fmt.Sprintf("mixed_dir_%d_%d_%d", os.Getpid(), set, index)
+	default:
+		return ""
+	}
+	return filepath.Join(dir, name)
+}
+
+func planStress(p Params) []PlannedStep {
+	steps := []PlannedStep{{Type: "stress-memory"}}
+	for i := 0; i < p.File.StressCPU; i++ {
+		steps = append(steps, PlannedStep{Offset: p.File.Duration, Type: "stress-cpu"})
+	}
+	return steps
+}
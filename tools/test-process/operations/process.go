@@ -1,41 +1,46 @@
 package operations
 
 import (
+	"bytes"
+	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"proctail-test-process/i18n"
+	"proctail-test-process/schema"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 )
 
-// ProcessReport interface for process operations
-type ProcessReport interface {
-	GetConfig() ProcessConfig
-	IncrementSuccess()
-	IncrementFailed()
-	AddError(error)
-	SetTotalOps(int)
-	AddChildPID(int)
-}
+// ProcessOptions configures process operations
+type ProcessOptions struct {
+	Count     int
+	Interval  time.Duration
+	Dir       string
+	Verbosity int
+	Command   string
+	Duration  time.Duration
+	TreeDepth int
+	TreeWidth int
+
+	OrphanDelay time.Duration
+
+	CrashMode string
 
-type ProcessConfig struct {
-	Count    int
-	Interval time.Duration
-	Dir      string
-	Verbose  bool
-	Command  string
-	Duration time.Duration
+	Hooks
 }
 
 // ExecuteChildProcess creates and manages child processes
-func ExecuteChildProcess(report ProcessReport) error {
-	config := report.GetConfig()
+func ExecuteChildProcess(ctx context.Context, config ProcessOptions, report Reporter) error {
 	report.SetTotalOps(config.Count)
-	
-	if config.Verbose {
-		log.Printf("子プロセス作成開始: %d回、間隔 %v", config.Count, config.Interval)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("子プロセス作成開始"), "count", config.Count, "interval", config.Interval)
 	}
 
 	for i := 0; i < config.Count; i++ {
@@ -46,34 +51,37 @@ func ExecuteChildProcess(report ProcessReport) error {
 			// Custom command specified
 			parts := strings.Fields(config.Command)
 			if len(parts) > 0 {
-				cmd = exec.Command(parts[0], parts[1:]...)
+				cmd = exec.CommandContext(ctx, parts[0], parts[1:]...)
 				cmdDesc = config.Command
 			}
 		} else {
 			// Default platform-specific commands
 			if runtime.GOOS == "windows" {
-				cmd = exec.Command("cmd", "/c", fmt.Sprintf("echo Child process %d from PID %d && timeout /t 1 > nul", i+1, os.Getpid()))
+				cmd = exec.CommandContext(ctx, "cmd", "/c", fmt.Sprintf("echo Child process %d from PID %d && timeout /t 1 > nul", i+1, os.Getpid()))
 				cmdDesc = "cmd /c echo + timeout"
 			} else {
-				cmd = exec.Command("sh", "-c", fmt.Sprintf("echo 'Child process %d from PID %d' && sleep 1", i+1, os.Getpid()))
+				cmd = exec.CommandContext(ctx, "sh", "-c", fmt.Sprintf("echo 'Child process %d from PID %d' && sleep 1", i+1, os.Getpid()))
 				cmdDesc = "sh -c echo + sleep"
 			}
 		}
 
+		start := time.Now()
 		if cmd == nil {
-			err := fmt.Errorf("無効なコマンド: %s", config.Command)
+			err := fmt.Errorf(i18n.T("無効なコマンド: %s"), config.Command)
+			config.notify("child-process", "", start, err)
 			report.AddError(err)
 			report.IncrementFailed()
 			continue
 		}
 
-		if config.Verbose {
-			log.Printf("子プロセス実行中 %d/%d: %s", i+1, config.Count, cmdDesc)
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("子プロセス実行中"), "index", i+1, "count", config.Count, "command", cmdDesc)
 		}
 
 		err := cmd.Start()
 		if err != nil {
-			report.AddError(fmt.Errorf("子プロセス開始エラー: %w", err))
+			config.notify("child-process", cmd.Path, start, err)
+			report.AddError(fmt.Errorf(i18n.T("子プロセス開始エラー: %w"), err))
 			report.IncrementFailed()
 			continue
 		}
@@ -81,24 +89,28 @@ func ExecuteChildProcess(report ProcessReport) error {
 		childPID := cmd.Process.Pid
 		report.AddChildPID(childPID)
 
-		if config.Verbose {
-			log.Printf("子プロセス開始: PID %d", childPID)
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("子プロセス開始"), "child_pid", childPID)
 		}
 
 		// Wait for the process to complete
 		err = cmd.Wait()
+		exitCode := reportExit(report, cmd)
+		config.notifyEvent(OperationEvent{Type: "child-process", Path: cmd.Path, PID: childPID, ExitCode: exitCode}, start, err)
 		if err != nil {
-			report.AddError(fmt.Errorf("子プロセス実行エラー PID %d: %w", childPID, err))
+			report.AddError(fmt.Errorf(i18n.T("子プロセス実行エラー PID %d: %w"), childPID, err))
 			report.IncrementFailed()
 		} else {
 			report.IncrementSuccess()
-			if config.Verbose {
-				log.Printf("子プロセス完了: PID %d", childPID)
+			if config.Verbosity >= VerboseOps {
+				slog.Info(i18n.T("子プロセス完了"), "child_pid", childPID)
 			}
 		}
 
 		if i < config.Count-1 {
-			time.Sleep(config.Interval)
+			if err := config.sleep(ctx, config.Interval); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -106,12 +118,11 @@ func ExecuteChildProcess(report ProcessReport) error {
 }
 
 // ExecuteLongRunningProcess creates long-running child processes
-func ExecuteLongRunningProcess(report ProcessReport) error {
-	config := report.GetConfig()
+func ExecuteLongRunningProcess(ctx context.Context, config ProcessOptions, report Reporter) error {
 	report.SetTotalOps(config.Count)
-	
-	if config.Verbose {
-		log.Printf("長時間実行子プロセス作成開始: %d回、間隔 %v", config.Count, config.Interval)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("長時間実行子プロセス作成開始"), "count", config.Count, "interval", config.Interval)
 	}
 
 	var processes []*exec.Cmd
@@ -123,153 +134,400 @@ func ExecuteLongRunningProcess(report ProcessReport) error {
 
 		if runtime.GOOS == "windows" {
 			// Windows: Use timeout command for long-running process
-			cmd = exec.Command("cmd", "/c", fmt.Sprintf("timeout /t 10 > nul"))
+			cmd = exec.CommandContext(ctx, "cmd", "/c", fmt.Sprintf("timeout /t 10 > nul"))
 			cmdDesc = "cmd /c timeout 10s"
 		} else {
 			// Unix: Use sleep command
-			cmd = exec.Command("sleep", "10")
+			cmd = exec.CommandContext(ctx, "sleep", "10")
 			cmdDesc = "sleep 10s"
 		}
 
-		if config.Verbose {
-			log.Printf("長時間実行プロセス開始中 %d/%d: %s", i+1, config.Count, cmdDesc)
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("長時間実行プロセス開始中"), "index", i+1, "count", config.Count, "command", cmdDesc)
 		}
 
+		start := time.Now()
 		err := cmd.Start()
 		if err != nil {
-			report.AddError(fmt.Errorf("長時間実行プロセス開始エラー: %w", err))
+			config.notify("long-running-process", cmd.Path, start, err)
+			report.AddError(fmt.Errorf(i18n.T("長時間実行プロセス開始エラー: %w"), err))
 			report.IncrementFailed()
 			continue
 		}
 
 		childPID := cmd.Process.Pid
+		config.notifyEvent(OperationEvent{Type: "long-running-process", Path: cmd.Path, PID: childPID}, start, nil)
 		report.AddChildPID(childPID)
 		processes = append(processes, cmd)
 
-		if config.Verbose {
-			log.Printf("長時間実行プロセス開始: PID %d", childPID)
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("長時間実行プロセス開始"), "child_pid", childPID)
 		}
 
 		report.IncrementSuccess()
 
 		if i < config.Count-1 {
-			time.Sleep(config.Interval)
+			if err := config.sleep(ctx, config.Interval); err != nil {
+				return err
+			}
 		}
 	}
 
 	// Wait a bit for processes to run
-	if config.Verbose {
-		log.Printf("プロセス実行中... (5秒待機)")
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("プロセス実行中... (5秒待機)"))
+	}
+	select {
+	case <-time.After(5 * time.Second):
+	case <-ctx.Done():
 	}
-	time.Sleep(5 * time.Second)
 
 	// Kill all processes
 	for _, cmd := range processes {
 		if cmd != nil && cmd.Process != nil {
-			if config.Verbose {
-				log.Printf("プロセス終了中: PID %d", cmd.Process.Pid)
+			if config.Verbosity >= VerboseOps {
+				slog.Info(i18n.T("プロセス終了中"), "child_pid", cmd.Process.Pid)
 			}
-			
+
 			err := cmd.Process.Kill()
 			if err != nil {
-				if config.Verbose {
-					log.Printf("プロセス終了エラー PID %d: %v", cmd.Process.Pid, err)
+				if config.Verbosity >= VerboseOps {
+					slog.Error(i18n.T("プロセス終了エラー"), "child_pid", cmd.Process.Pid, "error", err)
 				}
 			} else {
-				if config.Verbose {
-					log.Printf("プロセス終了完了: PID %d", cmd.Process.Pid)
+				if config.Verbosity >= VerboseOps {
+					slog.Info(i18n.T("プロセス終了完了"), "child_pid", cmd.Process.Pid)
 				}
 			}
+			// Reap the process so its exit code reaches the report
+			cmd.Wait()
+			reportExit(report, cmd)
 		}
 	}
 
 	return nil
 }
 
-// ExecuteProcessTree creates a tree of child processes
-func ExecuteProcessTree(report ProcessReport) error {
-	config := report.GetConfig()
-	report.SetTotalOps(config.Count)
-	
-	if config.Verbose {
-		log.Printf("プロセスツリー作成開始: %d階層", config.Count)
+// ExecuteProcessTree creates a tree of child processes by recursively invoking
+// this executable. Each node spawns TreeWidth children until TreeDepth reaches 0,
+// and touches a marker file so file events can be observed at every level.
+func ExecuteProcessTree(ctx context.Context, config ProcessOptions, report Reporter) error {
+
+	if config.TreeDepth < 0 || config.TreeWidth <= 0 {
+		return fmt.Errorf(i18n.T("プロセスツリーの深さ・幅が不正です: depth=%d, width=%d"), config.TreeDepth, config.TreeWidth)
 	}
 
-	// Create a script that creates child processes
-	var cmd *exec.Cmd
-	var cmdDesc string
+	markerPath := filepath.Join(config.Dir, fmt.Sprintf("test_tree_%d.txt", os.Getpid()))
+	if err := os.WriteFile(markerPath, []byte(fmt.Sprintf("Process tree node PID %d, remaining depth %d\n", os.Getpid(), config.TreeDepth)), 0644); err != nil {
+		report.AddError(fmt.Errorf(i18n.T("マーカーファイル作成エラー %s: %w"), markerPath, err))
+	} else {
+		defer os.Remove(markerPath)
+	}
 
-	if runtime.GOOS == "windows" {
-		// Windows: Create a batch script that spawns child processes
-		script := fmt.Sprintf(`
-@echo off
-echo Parent process: %%*
-for /L %%%%i in (1,1,%d) do (
-    start /min cmd /c "echo Child %%%%i from %%* && timeout /t 2 > nul"
-)
-timeout /t 3 > nul
-`, config.Count)
-		
-		// Write script to temp file
-		scriptPath := fmt.Sprintf("%s\\proctail_tree_%d.bat", os.TempDir(), os.Getpid())
-		err := os.WriteFile(scriptPath, []byte(script), 0644)
-		if err != nil {
-			return fmt.Errorf("スクリプト作成エラー: %w", err)
+	if config.TreeDepth == 0 {
+		// Leaf node: keep alive for a moment so the watcher can observe it
+		report.SetTotalOps(0)
+		if err := config.sleep(ctx, config.Interval); err != nil {
+			return err
 		}
-		defer os.Remove(scriptPath)
+		return nil
+	}
 
-		cmd = exec.Command("cmd", "/c", scriptPath, fmt.Sprintf("PID_%d", os.Getpid()))
-		cmdDesc = "batch script with child processes"
-	} else {
-		// Unix: Create a shell script that spawns child processes
-		script := fmt.Sprintf(`#!/bin/sh
-echo "Parent process: $1"
-for i in $(seq 1 %d); do
-    (echo "Child $i from $1" && sleep 2) &
-done
-sleep 3
-wait
-`, config.Count)
-		
-		scriptPath := fmt.Sprintf("%s/proctail_tree_%d.sh", os.TempDir(), os.Getpid())
-		err := os.WriteFile(scriptPath, []byte(script), 0755)
+	report.SetTotalOps(config.TreeWidth)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("プロセスツリー作成開始"), "remaining_depth", config.TreeDepth, "width", config.TreeWidth)
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf(i18n.T("実行ファイルパス取得エラー: %w"), err)
+	}
+
+	args := []string{
+		"-tree-depth", strconv.Itoa(config.TreeDepth - 1),
+		"-tree-width", strconv.Itoa(config.TreeWidth),
+		"-interval", config.Interval.String(),
+		"-dir", config.Dir,
+		"-json",
+	}
+	args = append(args, verbosityArgs(config.Verbosity)...)
+	args = append(args, "process-tree")
+
+	type child struct {
+		cmd    *exec.Cmd
+		stdout *bytes.Buffer
+		start  time.Time
+	}
+	var children []child
+
+	// Start all children first so the whole level is alive at the same time
+	for i := 0; i < config.TreeWidth; i++ {
+		cmd := exec.CommandContext(ctx, self, args...)
+		cmd.Env = childEnv()
+		stdout := &bytes.Buffer{}
+		cmd.Stdout = stdout
+		cmd.Stderr = os.Stderr
+
+		start := time.Now()
+		if err := cmd.Start(); err != nil {
+			config.notify("process-tree", self, start, err)
+			report.AddError(fmt.Errorf(i18n.T("プロセスツリー開始エラー: %w"), err))
+			report.IncrementFailed()
+			continue
+		}
+
+		report.AddChildPID(cmd.Process.Pid)
+		children = append(children, child{cmd: cmd, stdout: stdout, start: start})
+
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("プロセスツリー子プロセス開始"), "child_pid", cmd.Process.Pid)
+		}
+	}
+
+	for _, c := range children {
+		childPID := c.cmd.Process.Pid
+		err := c.cmd.Wait()
+		exitCode := reportExit(report, c.cmd)
+		config.notifyEvent(OperationEvent{Type: "process-tree", Path: self, PID: childPID, ExitCode: exitCode}, c.start, err)
 		if err != nil {
-			return fmt.Errorf("スクリプト作成エラー: %w", err)
+			report.AddError(fmt.Errorf(i18n.T("プロセスツリー実行エラー PID %d: %w"), childPID, err))
+			report.IncrementFailed()
+			continue
 		}
-		defer os.Remove(scriptPath)
 
-		cmd = exec.Command("sh", scriptPath, fmt.Sprintf("PID_%d", os.Getpid()))
-		cmdDesc = "shell script with child processes"
+		// Collect descendants reported by the child
+		if childReport, err := schema.Parse(c.stdout.Bytes()); err == nil && childReport.ProcessTree != nil {
+			report.AddDescendants(childPID, childReport.ProcessTree.Children)
+		}
+
+		report.IncrementSuccess()
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("プロセスツリー子プロセス完了"), "child_pid", childPID)
+		}
 	}
 
-	if config.Verbose {
-		log.Printf("プロセスツリー実行中: %s", cmdDesc)
+	return nil
+}
+
+// ExecuteOrphan starts a detached child that outlives this process and
+// touches files after the parent has exited
+func ExecuteOrphan(ctx context.Context, config ProcessOptions, report Reporter) error {
+	report.SetTotalOps(1)
+
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf(i18n.T("実行ファイルパス取得エラー: %w"), err)
 	}
 
-	err := cmd.Start()
+	cmd := exec.Command(self,
+		"-count", strconv.Itoa(config.Count),
+		"-interval", config.Interval.String(),
+		"-orphan-delay", config.OrphanDelay.String(),
+		"-dir", config.Dir,
+		"orphan-child",
+	)
+	cmd.Env = childEnv()
+	detach(cmd)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("孤児プロセス作成"), "delay", config.OrphanDelay, "count", config.Count)
+	}
+
+	start := time.Now()
+	err = cmd.Start()
 	if err != nil {
-		report.AddError(fmt.Errorf("プロセスツリー開始エラー: %w", err))
+		config.notify("orphan", self, start, err)
+		report.AddError(fmt.Errorf(i18n.T("孤児プロセス開始エラー: %w"), err))
 		report.IncrementFailed()
 		return nil
 	}
 
 	childPID := cmd.Process.Pid
 	report.AddChildPID(childPID)
+	config.notifyEvent(OperationEvent{Type: "orphan", Path: self, PID: childPID}, start, nil)
+	report.IncrementSuccess()
 
-	if config.Verbose {
-		log.Printf("プロセスツリー開始: 親PID %d", childPID)
+	// Don't wait: the child is expected to outlive us
+	cmd.Process.Release()
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("孤児プロセス開始 (親は待機せず終了)"), "child_pid", childPID)
+	}
+
+	return nil
+}
+
+// ExecuteOrphanChild runs inside the detached child started by ExecuteOrphan.
+// It waits for the parent to exit, then writes and removes files.
+func ExecuteOrphanChild(ctx context.Context, config FileOptions, report Reporter) error {
+	report.SetTotalOps(config.Count)
+
+	if err := config.sleep(ctx, config.OrphanDelay); err != nil {
+		return err
 	}
 
+	for i := 0; i < config.Count; i++ {
+		filePath := filepath.Join(config.Dir, fmt.Sprintf("test_orphan_%d_%d.txt", os.Getpid(), i))
+		content := fmt.Sprintf("Orphan write %d\nTimestamp: %s\nProcess ID: %d\nParent PID: %d\n",
+			i+1, time.Now().Format(time.RFC3339), os.Getpid(), os.Getppid())
+
+		start := time.Now()
+		err := os.WriteFile(filePath, []byte(content), 0644)
+		config.notify("file-write", filePath, start, err)
+		if err != nil {
+			report.AddError(fmt.Errorf(i18n.T("孤児プロセス書き込みエラー %s: %w"), filePath, err))
+			report.IncrementFailed()
+		} else {
+			report.IncrementSuccess()
+			os.Remove(filePath)
+		}
+
+		if i < config.Count-1 {
+			if err := config.sleep(ctx, config.Interval); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ExecuteSelfCopy copies this executable to a new name, runs the copy, renames it,
+// runs it again under the new name and deletes it
+func ExecuteSelfCopy(ctx context.Context, config ProcessOptions, report Reporter) error {
+	report.SetTotalOps(config.Count * 2)
+
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf(i18n.T("実行ファイルパス取得エラー: %w"), err)
+	}
+
+	ext := ""
+	if runtime.GOOS == "windows" {
+		ext = ".exe"
+	}
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("自己コピー実行開始"), "count", config.Count, "interval", config.Interval)
+	}
+
+	for i := 0; i < config.Count; i++ {
+		copyPath := filepath.Join(config.Dir, fmt.Sprintf("test_copy_%d_%d%s", os.Getpid(), i, ext))
+		renamedPath := filepath.Join(config.Dir, fmt.Sprintf("test_renamed_%d_%d%s", os.Getpid(), i, ext))
+
+		start := time.Now()
+		if err := copyFile(self, copyPath, 0755); err != nil {
+			config.notify("self-copy", copyPath, start, err)
+			report.AddError(fmt.Errorf(i18n.T("実行ファイルコピーエラー %s: %w"), copyPath, err))
+			report.IncrementFailed()
+			report.IncrementFailed()
+			continue
+		}
+
+		runCopiedExecutable(ctx, report, config, copyPath)
+
+		start = time.Now()
+		if err := os.Rename(copyPath, renamedPath); err != nil {
+			config.notify("self-copy", renamedPath, start, err)
+			report.AddError(fmt.Errorf(i18n.T("実行ファイルリネームエラー %s -> %s: %w"), copyPath, renamedPath, err))
+			report.IncrementFailed()
+			os.Remove(copyPath)
+		} else {
+			runCopiedExecutable(ctx, report, config, renamedPath)
+			os.Remove(renamedPath)
+		}
+
+		if i < config.Count-1 {
+			if err := config.sleep(ctx, config.Interval); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// runCopiedExecutable runs a copied test-process binary performing a single file operation
+func runCopiedExecutable(ctx context.Context, report Reporter, config ProcessOptions, path string) {
+	cmd := exec.CommandContext(ctx, path, "-count", "1", "-dir", config.Dir, "file-delete")
+	cmd.Env = childEnv()
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("コピーした実行ファイルを起動中"), "path", path)
+	}
+
+	start := time.Now()
+	err := cmd.Start()
+	if err != nil {
+		config.notify("self-copy", path, start, err)
+		report.AddError(fmt.Errorf(i18n.T("コピー実行開始エラー %s: %w"), path, err))
+		report.IncrementFailed()
+		return
+	}
+
+	childPID := cmd.Process.Pid
+	report.AddChildPID(childPID)
+
 	err = cmd.Wait()
+	exitCode := reportExit(report, cmd)
+	config.notifyEvent(OperationEvent{Type: "self-copy", Path: path, PID: childPID, ExitCode: exitCode}, start, err)
 	if err != nil {
-		report.AddError(fmt.Errorf("プロセスツリー実行エラー: %w", err))
+		report.AddError(fmt.Errorf(i18n.T("コピー実行エラー PID %d (%s): %w"), childPID, path, err))
 		report.IncrementFailed()
 	} else {
 		report.IncrementSuccess()
-		if config.Verbose {
-			log.Printf("プロセスツリー完了: 親PID %d", childPID)
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("コピー実行完了"), "child_pid", childPID, "path", path)
+		}
+	}
+}
+
+// EnvPrefix prefixes the environment variables that provide flag defaults
+const EnvPrefix = "PROCTAIL_TEST_"
+
+// childEnv returns the environment for a self-spawned child: this process's
+// environment without the PROCTAIL_TEST_* flag defaults, so the child is
+// configured only by the arguments it is started with and doesn't reuse the
+// parent's pid file, control socket or daemon watch
+func childEnv() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		// Windows environment names are case-insensitive
+		if !strings.HasPrefix(strings.ToUpper(kv), EnvPrefix) {
+			env = append(env, kv)
 		}
 	}
+	return env
+}
+
+// reportExit records the exit code of a child that has been waited for and
+// returns it; -1 if the process state is unknown
+func reportExit(report Reporter, cmd *exec.Cmd) int {
+	if cmd.ProcessState == nil {
+		return -1
+	}
+	code := cmd.ProcessState.ExitCode()
+	report.SetChildExitCode(cmd.Process.Pid, code)
+	return code
+}
 
-	return nil
-}
\ No newline at end of file
+// copyFile copies src to dst with the given permissions
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
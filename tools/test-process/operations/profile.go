@@ -0,0 +1,69 @@
+package operations
+
+import (
+	"fmt"
+	"proctail-test-process/i18n"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Profile describes how the operation rate changes over time.
+// A ramp profile increases the rate linearly from MinRate to MaxRate during
+// the first half of Period and decreases it back to MinRate during the second half.
+type Profile struct {
+	Kind    string
+	MinRate float64 // operations per second
+	MaxRate float64 // operations per second
+	Period  time.Duration
+}
+
+// ParseProfile parses a profile specification such as "ramp:10:500:60s"
+func ParseProfile(spec string) (*Profile, error) {
+	parts := strings.Split(spec, ":")
+	switch parts[0] {
+	case "ramp":
+		if len(parts) != 4 {
+			return nil, fmt.Errorf(i18n.T("rampプロファイルの形式が不正です (ramp:最小レート:最大レート:周期): %s"), spec)
+		}
+		minRate, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || minRate <= 0 {
+			return nil, fmt.Errorf(i18n.T("最小レートが不正です: %s"), parts[1])
+		}
+		maxRate, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil || maxRate < minRate {
+			return nil, fmt.Errorf(i18n.T("最大レートが不正です: %s"), parts[2])
+		}
+		period, err := time.ParseDuration(parts[3])
+		if err != nil || period <= 0 {
+			return nil, fmt.Errorf(i18n.T("周期が不正です: %s"), parts[3])
+		}
+		return &Profile{Kind: "ramp", MinRate: minRate, MaxRate: maxRate, Period: period}, nil
+	default:
+		return nil, fmt.Errorf(i18n.T("不明なプロファイル: %s"), parts[0])
+	}
+}
+
+// RateAt returns the target operation rate at the given elapsed time.
+// The ramp repeats when elapsed exceeds Period.
+func (p *Profile) RateAt(elapsed time.Duration) float64 {
+	half := p.Period / 2
+	pos := elapsed % p.Period
+	var progress float64
+	if pos < half {
+		progress = float64(pos) / float64(half)
+	} else {
+		progress = float64(p.Period-pos) / float64(p.Period-half)
+	}
+	return p.MinRate + (p.MaxRate-p.MinRate)*progress
+}
+
+// IntervalAt returns the time per operation at the given elapsed time
+func (p *Profile) IntervalAt(elapsed time.Duration) time.Duration {
+	return time.Duration(float64(time.Second) / p.RateAt(elapsed))
+}
+
+// String returns the profile specification
+func (p *Profile) String() string {
+	return fmt.Sprintf("%s:%g:%g:%s", p.Kind, p.MinRate, p.MaxRate, p.Period)
+}
@@ -0,0 +1,103 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"proctail-test-process/i18n"
+	"sync"
+)
+
+// Params carries everything an operation may need; each operation reads the
+// options struct that applies to it
+type Params struct {
+	File    FileOptions
+	Process ProcessOptions
+	Mixed   MixedOptions
+	Report  Reporter
+	// Hooks are passed on to the options of whichever operation runs
+	Hooks Hooks
+}
+
+// Operation is a runnable operation type
+type Operation interface {
+	Description() string
+	Run(ctx context.Context, p Params) error
+}
+
+// Factory creates a fresh Operation for a run
+type Factory func() Operation
+
+// Func adapts a plain function to Operation. Hidden operations are runnable
+// but left out of Names (e.g. children started by other operations).
+// PlanFn is optional and used by --dry-run.
+type Func struct {
+	Desc   string
+	Hidden bool
+	Fn     func(ctx context.Context, p Params) error
+	PlanFn func(p Params) []PlannedStep
+}
+
+// Description returns the one-line description shown in usage and list
+func (f Func) Description() string {
+	return f.Desc
+}
+
+// Run executes the function
+func (f Func) Run(ctx context.Context, p Params) error {
+	return f.Fn(ctx, p)
+}
+
+// Plan returns the planned steps, or nil if the function has no planner
+func (f Func) Plan(p Params) []PlannedStep {
+	if f.PlanFn == nil {
+		return nil
+	}
+	return f.PlanFn(p)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+	// registryOrder keeps usage output in registration order
+	registryOrder []string
+)
+
+// Register makes an operation available by name. It is meant to be called
+// from init functions and panics if the name is already taken.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("operations: %s は既に登録されています", name))
+	}
+	registry[name] = factory
+	registryOrder = append(registryOrder, name)
+}
+
+// New creates the operation registered under name
+func New(name string) (Operation, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf(i18n.T("不明な操作: %s"), name)
+	}
+	return factory(), nil
+}
+
+// Names lists the registered, non-hidden operations in registration order
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	var names []string
+	for _, name := range registryOrder {
+		if f, ok := registry[name]().(Func); ok && f.Hidden {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,249 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"proctail-test-process/i18n"
+	"strings"
+	"time"
+)
+
+// ReplayStep is one operation from a previous run's timeline, Offset after
+// the start of the replay
+type ReplayStep struct {
+	Offset time.Duration
+	Type   string
+	Path   string
+}
+
+// ReplayOptions configures the replay operation. The tree and chunk settings
+// come from the original run so that dir-tree and flush steps match it.
+type ReplayOptions struct {
+	Steps     []ReplayStep
+	Dir       string
+	Verbosity int
+	Command   string
+	TreeDepth int
+	TreeWidth int
+	TreeFiles int
+	ChunkSize int64
+
+	Hooks
+}
+
+// replayers re-execute a single timeline entry. Operation types not listed
+// here (e.g. stress or crash) cannot be reproduced from a path alone.
+var replayers = map[string]func(ctx context.Context, config ReplayOptions, report Reporter, path string) error{
+	"file-write":      replayFileWrite,
+	"file-read":       replayFileRead,
+	"file-delete":     replayFileDelete,
+	"file-rename":     replayFileRename,
+	"dir-create":      replayDirCreate,
+	"dir-delete":      replayDirDelete,
+	"dir-tree-create": replayDirTreeCreate,
+	"dir-tree-remove": replayDirTreeRemove,
+	"flush":           replayFlush,
+	"child-process":   replayChildProcess,
+}
+
+// Replayable reports whether steps of opType can be replayed
+func Replayable(opType string) bool {
+	_, ok := replayers[opType]
+	return ok
+}
+
+// ExecuteReplay re-executes Steps at the same offsets they had in the original
+// run. Steps that started simultaneously are run one after another.
+func ExecuteReplay(ctx context.Context, config ReplayOptions, report Reporter) error {
+	var steps []ReplayStep
+	skipped := map[string]int{}
+	for _, step := range config.Steps {
+		if Replayable(step.Type) {
+			steps = append(steps, step)
+		} else {
+			skipped[step.Type]++
+		}
+	}
+	for opType, n := range skipped {
+		slog.Warn(i18n.T("再現できない操作をスキップ"), "type", opType, "count", n)
+	}
+
+	report.SetTotalOps(len(steps))
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("リプレイ開始"), "operations", len(steps))
+	}
+
+	// The original operations remove these files afterwards without
+	// reporting it, so the timeline has no step for it
+	defer func() {
+		for _, step := range steps {
+			switch step.Type {
+			case "file-read", "file-rename", "flush":
+				os.Remove(step.Path)
+			}
+		}
+	}()
+
+	begin := time.Now()
+	for i, step := range steps {
+		if wait := time.Until(begin.Add(step.Offset)); wait > 0 {
+			if err := config.sleep(ctx, wait); err != nil {
+				return err
+			}
+		}
+
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("リプレイ"), "step", i+1, "steps", len(steps), "offset", step.Offset, "type", step.Type, "path", step.Path)
+		}
+
+		if err := replayers[step.Type](ctx, config, report, step.Path); err != nil {
+			report.AddError(fmt.Errorf(i18n.T("リプレイエラー %s %s: %w"), step.Type, step.Path, err))
+			report.IncrementFailed()
+		} else {
+			report.IncrementSuccess()
+		}
+	}
+
+	return nil
+}
+
+// Plan describes the replay for --dry-run
+func (o ReplayOptions) Plan() []PlannedStep {
+	var steps []PlannedStep
+	for _, step := range o.Steps {
+		if !Replayable(step.Type) {
+			continue
+		}
+		planned := PlannedStep{Offset: step.Offset, Type: step.Type, Path: step.Path}
+		if step.Type == "child-process" {
+			planned.Path = ""
+			planned.Command = plannedCommand(o.Command)
+		}
+		steps = append(steps, planned)
+	}
+	return steps
+}
+
+// ensureFile creates path if a previous step has not, so that steps which
+// operate on existing files can be replayed on their own
+func ensureFile(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	return os.WriteFile(path, []byte("Replay content\n"), 0644)
+}
+
+func replayFileWrite(ctx context.Context, config ReplayOptions, report Reporter, path string) error {
+	content := fmt.Sprintf("Replay write\nTimestamp: %s\nProcess ID: %d\n", time.Now().Format(time.RFC3339), os.Getpid())
+
+	start := time.Now()
+	err := os.WriteFile(path, []byte(content), 0644)
+	config.notify("file-write", path, start, err)
+	return err
+}
+
+func replayFileRead(ctx context.Context, config ReplayOptions, report Reporter, path string) error {
+	if err := ensureFile(path); err != nil {
+		return fmt.Errorf(i18n.T("事前ファイル作成エラー: %w"), err)
+	}
+
+	start := time.Now()
+	_, err := os.ReadFile(path)
+	config.notify("file-read", path, start, err)
+	return err
+}
+
+func replayFileDelete(ctx context.Context, config ReplayOptions, report Reporter, path string) error {
+	if err := ensureFile(path); err != nil {
+		return fmt.Errorf(i18n.T("事前ファイル作成エラー: %w"), err)
+	}
+
+	start := time.Now()
+	err := os.Remove(path)
+	config.notify("file-delete", path, start, err)
+	return err
+}
+
+// replayFileRename recreates the source file; the timeline only records the
+// new name, and the rename operations name their source *_rename_old_*
+func replayFileRename(ctx context.Context, config ReplayOptions, report Reporter, path string) error {
+	dir, name := filepath.Split(path)
+	oldPath := filepath.Join(dir, strings.Replace(name, "_rename_new_", "_rename_old_", 1))
+	if oldPath == path {
+		oldPath = path + ".old"
+	}
+	if err := ensureFile(oldPath); err != nil {
+		return fmt.Errorf(i18n.T("事前ファイル作成エラー: %w"), err)
+	}
+
+	start := time.Now()
+	err := os.Rename(oldPath, path)
+	config.notify("file-rename", path, start, err)
+	return err
+}
+
+func replayDirCreate(ctx context.Context, config ReplayOptions, report Reporter, path string) error {
+	start := time.Now()
+	err := os.Mkdir(path, 0755)
+	config.notify("dir-create", path, start, err)
+	return err
+}
+
+func replayDirDelete(ctx context.Context, config ReplayOptions, report Reporter, path string) error {
+	start := time.Now()
+	err := os.Remove(path)
+	config.notify("dir-delete", path, start, err)
+	return err
+}
+
+func replayDirTreeCreate(ctx context.Context, config ReplayOptions, report Reporter, path string) error {
+	start := time.Now()
+	_, _, err := buildDirTree(path, config.TreeDepth, config.TreeWidth, config.TreeFiles)
+	config.notify("dir-tree-create", path, start, err)
+	return err
+}
+
+func replayDirTreeRemove(ctx context.Context, config ReplayOptions, report Reporter, path string) error {
+	start := time.Now()
+	err := os.RemoveAll(path)
+	config.notify("dir-tree-remove", path, start, err)
+	return err
+}
+
+// replayFlush appends and syncs one chunk; each chunk of the original flush
+// operation is its own timeline entry
+func replayFlush(ctx context.Context, config ReplayOptions, report Reporter, path string) error {
+	size := config.ChunkSize
+	if size <= 0 {
+		size = 64
+	}
+	chunk := []byte(strings.Repeat("f", int(size)-1) + "\n")
+
+	start := time.Now()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err == nil {
+		_, err = f.Write(chunk)
+		if err == nil {
+			err = f.Sync()
+		}
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	config.notify("flush", path, start, err)
+	return err
+}
+
+func replayChildProcess(ctx context.Context, config ReplayOptions, report Reporter, path string) error {
+	opts := ProcessOptions{Count: 1, Dir: config.Dir, Verbosity: config.Verbosity, Command: config.Command, Hooks: config.Hooks}
+
+	nested := &nestedReporter{parent: report}
+	if err := ExecuteChildProcess(ctx, opts, nested); err != nil {
+		return err
+	}
+	return nested.err()
+}
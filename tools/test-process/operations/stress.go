@@ -0,0 +1,123 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"proctail-test-process/i18n"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const stressPageSize = 4096
+
+// ExecuteStress keeps StressCPU cores busy and holds StressMemory bytes of
+// resident memory for Duration
+func ExecuteStress(ctx context.Context, config FileOptions, report Reporter) error {
+
+	if config.Duration <= 0 {
+		return fmt.Errorf(i18n.T("負荷時間が設定されていません"))
+	}
+	if config.StressCPU < 0 || config.StressMemory < 0 {
+		return fmt.Errorf(i18n.T("負荷設定が不正です: cpu=%d, memory=%d"), config.StressCPU, config.StressMemory)
+	}
+
+	report.SetTotalOps(config.StressCPU + 1)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("負荷操作開始"), "cpu", config.StressCPU, "memory", config.StressMemory, "duration", config.Duration)
+	}
+
+	if config.StressCPU > runtime.GOMAXPROCS(0) {
+		runtime.GOMAXPROCS(config.StressCPU)
+	}
+
+	// Touch every page so the allocation is actually resident
+	start := time.Now()
+	memory := make([]byte, config.StressMemory)
+	for i := 0; i < len(memory); i += stressPageSize {
+		memory[i] = 1
+	}
+	config.notify("stress-memory", "", start, nil)
+	report.IncrementSuccess()
+
+	start = time.Now()
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	iterations := make([]uint64, config.StressCPU)
+	results := make([]uint64, config.StressCPU) // keeps the busy loop from being optimized away
+	for i := 0; i < config.StressCPU; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				// Busy work between stop checks
+				x := uint64(worker)
+				for j := 0; j < 100000; j++ {
+					x = x*6364136223846793005 + 1442695040888963407
+				}
+				results[worker] = x
+				iterations[worker]++
+			}
+		}(i)
+	}
+
+	select {
+	case <-time.After(config.Duration):
+	case <-ctx.Done():
+	}
+	close(stop)
+	wg.Wait()
+
+	for i := 0; i < config.StressCPU; i++ {
+		config.notify("stress-cpu", "", start, nil)
+		report.IncrementSuccess()
+		if config.Verbosity >= VerboseOps {
+			slog.Info(i18n.T("CPU負荷ワーカー完了"), "worker", i, "iterations", iterations[i])
+		}
+	}
+
+	runtime.KeepAlive(memory)
+	runtime.KeepAlive(results)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("負荷操作完了"))
+	}
+
+	return nil
+}
+
+// ParseSize parses a byte size such as "512", "64KB", "256MB" or "1GiB"
+func ParseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	value = strings.TrimSuffix(strings.TrimSuffix(value, "B"), "I")
+
+	multiplier := int64(1)
+	if n := len(value); n > 0 {
+		switch value[n-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		}
+		if multiplier != 1 {
+			value = value[:n-1]
+		}
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf(i18n.T("サイズの形式が不正です: %s"), s)
+	}
+	return n * multiplier, nil
+}
@@ -0,0 +1,76 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"proctail-test-process/i18n"
+	"time"
+)
+
+// unicodeNames exercise UTF-16 to UTF-8 conversion in the event pipeline
+var unicodeNames = []string{
+	"テスト_日本語ファイル",          // Japanese (BMP)
+	"セーブデータ_ｾｰﾌﾞ_全角半角",     // full-width and half-width katakana
+	"emoji_😀🎮💾",            // emoji (surrogate pairs in UTF-16)
+	"cafe\u0301_combining", // e + COMBINING ACUTE ACCENT
+	"\u304b\u3099_濁点分解",    // か + COMBINING VOICED SOUND MARK
+	"𠮷野家_surrogate",        // U+20BB7 (surrogate pair)
+	"flag_🇯🇵_zwj_👨‍👩‍👧",    // regional indicators and ZWJ sequence
+}
+
+// ExecuteUnicode writes, reads, renames and deletes files with non-ASCII names
+func ExecuteUnicode(ctx context.Context, config FileOptions, report Reporter) error {
+	report.SetTotalOps(config.Count * len(unicodeNames))
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("Unicodeファイル名操作開始"), "count", config.Count, "names", len(unicodeNames), "interval", config.Interval)
+	}
+
+	for i := 0; i < config.Count; i++ {
+		for j, name := range unicodeNames {
+			oldPath := filepath.Join(config.Dir, fmt.Sprintf("%s_%d_%d.txt", name, os.Getpid(), i))
+			newPath := filepath.Join(config.Dir, fmt.Sprintf("%s_%d_%d_リネーム.txt", name, os.Getpid(), i))
+
+			start := time.Now()
+			err := unicodeFileCycle(oldPath, newPath, j)
+			config.notify("unicode", newPath, start, err)
+			if err != nil {
+				report.AddError(fmt.Errorf(i18n.T("Unicodeファイル名操作エラー %s: %w"), oldPath, err))
+				report.IncrementFailed()
+			} else {
+				report.IncrementSuccess()
+				if config.Verbosity >= VerboseOps {
+					slog.Info(i18n.T("Unicodeファイル名操作完了"), "old_path", oldPath, "new_path", newPath)
+				}
+			}
+		}
+
+		if i < config.Count-1 {
+			if err := config.sleep(ctx, config.Interval); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// unicodeFileCycle writes oldPath, reads it back, renames it to newPath and deletes it
+func unicodeFileCycle(oldPath, newPath string, index int) error {
+	content := fmt.Sprintf("Unicode filename test %d\nName: %s\n", index+1, filepath.Base(oldPath))
+	if err := os.WriteFile(oldPath, []byte(content), 0644); err != nil {
+		return err
+	}
+	if _, err := os.ReadFile(oldPath); err != nil {
+		os.Remove(oldPath)
+		return err
+	}
+	if err := os.Rename(oldPath, newPath); err != nil {
+		os.Remove(oldPath)
+		return err
+	}
+	return os.Remove(newPath)
+}
@@ -0,0 +1,45 @@
+package operations
+
+import (
+	"log/slog"
+	"proctail-test-process/i18n"
+)
+
+// Verbosity levels selected with -v, -vv and -vvv. Each level includes the
+// output of the levels below it.
+const (
+	// VerboseOps logs the start and result of every operation
+	VerboseOps = 1
+	// VerboseBytes also logs the bytes written and read
+	VerboseBytes = 2
+	// VerboseTiming also logs internal timing such as latencies and waits
+	VerboseTiming = 3
+)
+
+// verbosityArgs returns the flag that passes level on to a child test-process
+func verbosityArgs(level int) []string {
+	switch {
+	case level >= VerboseTiming:
+		return []string{"-vvv"}
+	case level == VerboseBytes:
+		return []string{"-vv"}
+	case level == VerboseOps:
+		return []string{"-v"}
+	}
+	return nil
+}
+
+// maxLoggedBytes limits the content logged at VerboseBytes per operation
+const maxLoggedBytes = 256
+
+// logBytes logs the data written to or read from path at VerboseBytes
+func logBytes(level int, path string, data []byte) {
+	if level < VerboseBytes {
+		return
+	}
+	shown := data
+	if len(shown) > maxLoggedBytes {
+		shown = shown[:maxLoggedBytes]
+	}
+	slog.Info(i18n.T("内容"), "path", path, "bytes", len(data), "data", string(shown))
+}
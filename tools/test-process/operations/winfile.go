@@ -0,0 +1,54 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"proctail-test-process/i18n"
+	"time"
+)
+
+// ErrUnsupportedPlatform is returned by operations that are unavailable on the current OS
+var ErrUnsupportedPlatform error = unsupportedPlatformError{}
+
+// unsupportedPlatformError translates its message when printed rather than
+// at init, before --lang is known
+type unsupportedPlatformError struct{}
+
+func (unsupportedPlatformError) Error() string {
+	return i18n.T("この操作は現在のプラットフォームでは利用できません")
+}
+
+// runFileVariant runs fn Count times, counting and reporting each result.
+// fn returns the path it operated on and the operation error.
+func runFileVariant(ctx context.Context, config FileOptions, report Reporter, opType, desc string, fn func(i int) (string, error)) error {
+	report.SetTotalOps(config.Count)
+	desc = i18n.T(desc)
+
+	if config.Verbosity >= VerboseOps {
+		slog.Info(i18n.T("操作開始"), "operation", desc, "count", config.Count, "interval", config.Interval)
+	}
+
+	for i := 0; i < config.Count; i++ {
+		start := time.Now()
+		path, err := fn(i)
+		config.notify(opType, path, start, err)
+		if err != nil {
+			report.AddError(fmt.Errorf(i18n.T("%sエラー %s: %w"), desc, path, err))
+			report.IncrementFailed()
+		} else {
+			report.IncrementSuccess()
+			if config.Verbosity >= VerboseOps {
+				slog.Info(i18n.T("操作完了"), "operation", desc, "path", path)
+			}
+		}
+
+		if i < config.Count-1 {
+			if err := config.sleep(ctx, config.Interval); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package operations
+
+import "context"
+
+// ExecuteDeleteOnClose is only available on Windows
+func ExecuteDeleteOnClose(ctx context.Context, config FileOptions, report Reporter) error {
+	return ErrUnsupportedPlatform
+}
+
+// ExecuteMoveReplace is only available on Windows
+func ExecuteMoveReplace(ctx context.Context, config FileOptions, report Reporter) error {
+	return ErrUnsupportedPlatform
+}
+
+// ExecuteHandleRename is only available on Windows
+func ExecuteHandleRename(ctx context.Context, config FileOptions, report Reporter) error {
+	return ErrUnsupportedPlatform
+}
+
+// ExecuteHandleDelete is only available on Windows
+func ExecuteHandleDelete(ctx context.Context, config FileOptions, report Reporter) error {
+	return ErrUnsupportedPlatform
+}
@@ -0,0 +1,195 @@
+//go:build windows
+
+package operations
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	fileFlagDeleteOnClose   = 0x04000000 // FILE_FLAG_DELETE_ON_CLOSE
+	moveFileReplaceExisting = 0x00000001 // MOVEFILE_REPLACE_EXISTING
+	accessDelete            = 0x00010000 // DELETE
+	fileRenameInfoClass     = 3          // FileRenameInfo
+	fileDispositionClass    = 4          // FileDispositionInfo
+	fileAttributeTemporary  = 0x00000100 // FILE_ATTRIBUTE_TEMPORARY
+)
+
+var (
+	kernel32                       = syscall.NewLazyDLL("kernel32.dll")
+	procMoveFileExW                = kernel32.NewProc("MoveFileExW")
+	procSetFileInformationByHandle = kernel32.NewProc("SetFileInformationByHandle")
+)
+
+// fileRenameInfo mirrors FILE_RENAME_INFO; FileName is variable length
+type fileRenameInfo struct {
+	ReplaceIfExists uint32
+	RootDirectory   syscall.Handle
+	FileNameLength  uint32
+	FileName        [1]uint16
+}
+
+// ExecuteDeleteOnClose writes files opened with FILE_FLAG_DELETE_ON_CLOSE
+func ExecuteDeleteOnClose(ctx context.Context, config FileOptions, report Reporter) error {
+	return runFileVariant(ctx, config, report, "delete-on-close", "削除時クローズ", func(i int) (string, error) {
+		path := filepath.Join(config.Dir, fmt.Sprintf("test_doc_%d_%d.txt", os.Getpid(), i))
+		name, err := syscall.UTF16PtrFromString(path)
+		if err != nil {
+			return path, err
+		}
+		handle, err := syscall.CreateFile(name,
+			syscall.GENERIC_WRITE|accessDelete,
+			syscall.FILE_SHARE_DELETE,
+			nil,
+			syscall.CREATE_ALWAYS,
+			fileAttributeTemporary|fileFlagDeleteOnClose,
+			0)
+		if err != nil {
+			return path, err
+		}
+		var written uint32
+		content := []byte(fmt.Sprintf("Delete-on-close write %d\n", i+1))
+		if err := syscall.WriteFile(handle, content, &written, nil); err != nil {
+			syscall.CloseHandle(handle)
+			return path, err
+		}
+		// Closing the last handle deletes the file
+		return path, syscall.CloseHandle(handle)
+	})
+}
+
+// ExecuteMoveReplace renames files over existing targets with MoveFileEx(MOVEFILE_REPLACE_EXISTING)
+func ExecuteMoveReplace(ctx context.Context, config FileOptions, report Reporter) error {
+	return runFileVariant(ctx, config, report, "move-replace", "置換移動", func(i int) (string, error) {
+		dir := config.Dir
+		src := filepath.Join(dir, fmt.Sprintf("test_move_src_%d_%d.txt", os.Getpid(), i))
+		dst := filepath.Join(dir, fmt.Sprintf("test_move_dst_%d_%d.txt", os.Getpid(), i))
+		if err := os.WriteFile(src, []byte("new content\n"), 0644); err != nil {
+			return src, err
+		}
+		if err := os.WriteFile(dst, []byte("old content\n"), 0644); err != nil {
+			os.Remove(src)
+			return dst, err
+		}
+		defer os.Remove(dst)
+
+		err := moveFileEx(src, dst, moveFileReplaceExisting)
+		if err != nil {
+			os.Remove(src)
+		}
+		return dst, err
+	})
+}
+
+// ExecuteHandleRename renames files through SetFileInformationByHandle(FileRenameInfo)
+func ExecuteHandleRename(ctx context.Context, config FileOptions, report Reporter) error {
+	return runFileVariant(ctx, config, report, "handle-rename", "ハンドル経由リネーム", func(i int) (string, error) {
+		dir := config.Dir
+		oldPath := filepath.Join(dir, fmt.Sprintf("test_hrename_old_%d_%d.txt", os.Getpid(), i))
+		newPath := filepath.Join(dir, fmt.Sprintf("test_hrename_new_%d_%d.txt", os.Getpid(), i))
+		if err := os.WriteFile(oldPath, []byte("rename by handle\n"), 0644); err != nil {
+			return oldPath, err
+		}
+
+		handle, err := openForDelete(oldPath)
+		if err != nil {
+			os.Remove(oldPath)
+			return oldPath, err
+		}
+
+		err = setRenameInfo(handle, newPath)
+		syscall.CloseHandle(handle)
+		if err != nil {
+			os.Remove(oldPath)
+			return newPath, err
+		}
+		os.Remove(newPath)
+		return newPath, nil
+	})
+}
+
+// ExecuteHandleDelete deletes files through SetFileInformationByHandle(FileDispositionInfo)
+func ExecuteHandleDelete(ctx context.Context, config FileOptions, report Reporter) error {
+	return runFileVariant(ctx, config, report, "handle-delete", "ハンドル経由削除", func(i int) (string, error) {
+		path := filepath.Join(config.Dir, fmt.Sprintf("test_hdelete_%d_%d.txt", os.Getpid(), i))
+		if err := os.WriteFile(path, []byte("delete by handle\n"), 0644); err != nil {
+			return path, err
+		}
+
+		handle, err := openForDelete(path)
+		if err != nil {
+			os.Remove(path)
+			return path, err
+		}
+
+		deleteFile := uint32(1) // FILE_DISPOSITION_INFO.DeleteFile
+		err = setFileInformationByHandle(handle, fileDispositionClass, unsafe.Pointer(&deleteFile), 1)
+		syscall.CloseHandle(handle)
+		if err != nil {
+			os.Remove(path)
+		}
+		return path, err
+	})
+}
+
+func openForDelete(path string) (syscall.Handle, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return syscall.InvalidHandle, err
+	}
+	return syscall.CreateFile(name,
+		accessDelete|syscall.GENERIC_READ,
+		syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+		nil,
+		syscall.OPEN_EXISTING,
+		syscall.FILE_ATTRIBUTE_NORMAL,
+		0)
+}
+
+func setRenameInfo(handle syscall.Handle, newPath string) error {
+	name, err := syscall.UTF16FromString(newPath)
+	if err != nil {
+		return err
+	}
+	nameBytes := (len(name) - 1) * 2 // without terminating NUL
+
+	// Allocate the header plus the variable-length name (including NUL),
+	// backed by uint64 so the handle field stays aligned
+	size := int(unsafe.Offsetof(fileRenameInfo{}.FileName)) + len(name)*2
+	buf := make([]uint64, (size+7)/8)
+	info := (*fileRenameInfo)(unsafe.Pointer(&buf[0]))
+	info.ReplaceIfExists = 1
+	info.FileNameLength = uint32(nameBytes)
+	copy(unsafe.Slice(&info.FileName[0], len(name)), name)
+
+	return setFileInformationByHandle(handle, fileRenameInfoClass, unsafe.Pointer(&buf[0]), uint32(size))
+}
+
+func setFileInformationByHandle(handle syscall.Handle, class uint32, info unsafe.Pointer, size uint32) error {
+	r, _, err := procSetFileInformationByHandle.Call(uintptr(handle), uintptr(class), uintptr(info), uintptr(size))
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+func moveFileEx(from, to string, flags uint32) error {
+	fromPtr, err := syscall.UTF16PtrFromString(from)
+	if err != nil {
+		return err
+	}
+	toPtr, err := syscall.UTF16PtrFromString(to)
+	if err != nil {
+		return err
+	}
+	r, _, err := procMoveFileExW.Call(uintptr(unsafe.Pointer(fromPtr)), uintptr(unsafe.Pointer(toPtr)), uintptr(flags))
+	if r == 0 {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"proctail-test-process/i18n"
+	"proctail-test-process/operations"
+	"strconv"
+	"time"
+)
+
+// outputFormats are the accepted values of --format
+var outputFormats = map[string]bool{
+	"json":  true,
+	"csv":   true,
+	"junit": true,
+}
+
+// writeReport writes the report to w in the given format
+func writeReport(w io.Writer, report *Report, format string, opErr error) error {
+	switch format {
+	case "json":
+		jsonData, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(jsonData))
+		return err
+	case "csv":
+		return writeCSV(w, report)
+	case "junit":
+		return writeJUnit(w, report, opErr)
+	default:
+		return fmt.Errorf(i18n.T("不明な出力形式: %s"), format)
+	}
+}
+
+// writeCSV writes one row per timeline entry
+func writeCSV(w io.Writer, report *Report) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"start", "end", "latency_ms", "type", "path", "pid", "result", "error"})
+	for _, e := range report.Timeline {
+		pid := ""
+		if e.PID != 0 {
+			pid = strconv.Itoa(e.PID)
+		}
+		cw.Write([]string{
+			e.Start.Format(time.RFC3339Nano),
+			e.End.Format(time.RFC3339Nano),
+			strconv.FormatFloat(toMillis(e.End.Sub(e.Start)), 'f', 3, 64),
+			e.Type,
+			e.Path,
+			pid,
+			e.Result,
+			e.Error,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes one test case per timeline entry; warm-up operations are
+// skipped. An aborted operation is reported as an additional errored test case.
+func writeJUnit(w io.Writer, report *Report, opErr error) error {
+	suite := junitTestSuite{
+		Name:      "test-process." + report.Operation,
+		Time:      junitSeconds(time.Duration(report.Duration)),
+		Timestamp: report.StartTime.Format(time.RFC3339),
+	}
+
+	for i, e := range report.Timeline {
+		name := e.Path
+		if name == "" {
+			name = fmt.Sprintf("%s #%d", e.Type, i+1)
+		}
+		tc := junitTestCase{
+			ClassName: e.Type,
+			Name:      name,
+			Time:      junitSeconds(e.End.Sub(e.Start)),
+		}
+		if e.Warmup {
+			tc.Skipped = &junitSkipped{Message: "warmup"}
+			suite.Skipped++
+		} else if e.Result != "success" {
+			tc.Failure = &junitFailure{Message: e.Error, Text: e.Error}
+			suite.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	if opErr != nil {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			ClassName: report.Operation,
+			Name:      report.Operation,
+			Time:      junitSeconds(time.Duration(report.Duration)),
+			Error:     &junitFailure{Message: opErr.Error(), Text: opErr.Error()},
+		})
+		suite.Errors++
+	}
+	suite.Tests = len(suite.TestCases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+func junitSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 6, 64)
+}
+
+// writePlan writes the --dry-run plan: JSON or CSV when requested, an aligned
+// text table otherwise
+func writePlan(w io.Writer, operation string, steps []operations.PlannedStep, format string) error {
+	var estimated time.Duration
+	for _, step := range steps {
+		if step.Offset > estimated {
+			estimated = step.Offset
+		}
+	}
+
+	switch format {
+	case "json":
+		jsonData, err := json.MarshalIndent(struct {
+			Operation string                   `json:"operation"`
+			Steps     []operations.PlannedStep `json:"steps"`
+			Estimated time.Duration            `json:"estimated_duration"`
+		}{operation, steps, estimated}, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(jsonData))
+		return err
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"offset_ms", "type", "path", "command"})
+		for _, step := range steps {
+			cw.Write([]string{strconv.FormatFloat(toMillis(step.Offset), 'f', 3, 64), step.Type, step.Path, step.Command})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	fmt.Fprintf(w, i18n.T("実行計画: %s (ドライラン: ファイルシステムは変更しません)\n"), operation)
+	for _, step := range steps {
+		target := step.Path
+		if step.Command != "" {
+			target = step.Command
+		}
+		fmt.Fprintf(w, "  +%-10v %-16s %s\n", step.Offset.Round(time.Millisecond), step.Type, target)
+	}
+	_, err := fmt.Fprintf(w, i18n.T("合計 %dステップ、見積もり所要時間 %v (操作自体の所要時間を除く)\n"), len(steps), estimated.Round(time.Millisecond))
+	return err
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"proctail-test-process/i18n"
+	"proctail-test-process/operations"
+	"proctail-test-process/schema"
+	"sort"
+	"strings"
+)
+
+// readReplaySource loads a report written with --format json
+func readReplaySource(path string) (*schema.Report, error) {
+	source, err := schema.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(source.Timeline) == 0 {
+		return nil, fmt.Errorf(i18n.T("レポートにタイムラインがありません: %s"), path)
+	}
+	return source, nil
+}
+
+// replaySteps converts the source timeline into steps for this process.
+// Paths under the original directory are moved under dir, and the original
+// PID in file names is replaced with ours.
+func replaySteps(source *schema.Report, dir string) []operations.ReplayStep {
+	timeline := append([]schema.TimelineEntry(nil), source.Timeline...)
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].Start.Before(timeline[j].Start)
+	})
+
+	oldPID := fmt.Sprintf("_%d_", source.ProcessID)
+	newPID := fmt.Sprintf("_%d_", os.Getpid())
+
+	begin := timeline[0].Start
+	steps := make([]operations.ReplayStep, 0, len(timeline))
+	for _, entry := range timeline {
+		path := entry.Path
+		if path != "" {
+			if rel, err := filepath.Rel(source.Config.Dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = filepath.Join(dir, rel)
+			}
+			path = filepath.Join(filepath.Dir(path), strings.ReplaceAll(filepath.Base(path), oldPID, newPID))
+		}
+		steps = append(steps, operations.ReplayStep{
+			Offset: entry.Start.Sub(begin),
+			Type:   entry.Type,
+			Path:   path,
+		})
+	}
+	return steps
+}
+
+// replayOperation re-executes the source timeline. It is created per run
+// rather than registered because it needs the source report.
+func replayOperation(source *schema.Report) operations.Func {
+	options := func(p operations.Params) operations.ReplayOptions {
+		return operations.ReplayOptions{
+			Steps:     replaySteps(source, p.File.Dir),
+			Dir:       p.File.Dir,
+			Verbosity: p.File.Verbosity,
+			Command:   p.Process.Command,
+			TreeDepth: p.File.TreeDepth,
+			TreeWidth: p.File.TreeWidth,
+			TreeFiles: p.File.TreeFiles,
+			ChunkSize: p.File.ChunkSize,
+			Hooks:     p.Hooks,
+		}
+	}
+	return operations.Func{
+		Desc: "以前のレポートのタイムラインを再実行",
+		Fn: func(ctx context.Context, p operations.Params) error {
+			return operations.ExecuteReplay(ctx, options(p), p.Report)
+		},
+		PlanFn: func(p operations.Params) []operations.PlannedStep {
+			return options(p).Plan()
+		},
+	}
+}
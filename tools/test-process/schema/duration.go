@@ -0,0 +1,41 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Duration is a time.Duration that serializes as a string such as "1.5s".
+// Integer nanoseconds, as written by schema version 1, are still accepted.
+type Duration time.Duration
+
+// String formats d like time.Duration
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalJSON writes d as a duration string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON reads a duration string or integer nanoseconds
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("時間の解析エラー %q: %w", s, err)
+		}
+		*d = Duration(parsed)
+		return nil
+	}
+
+	var ns int64
+	if err := json.Unmarshal(data, &ns); err != nil {
+		return fmt.Errorf("時間は文字列または整数ナノ秒で指定してください: %s", data)
+	}
+	*d = Duration(ns)
+	return nil
+}
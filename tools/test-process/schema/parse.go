@@ -0,0 +1,46 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Parse decodes a report of any schema version up to Version. Reports
+// without schema_version are version 1.
+func Parse(data []byte) (*Report, error) {
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("レポート解析エラー: %w", err)
+	}
+	if r.SchemaVersion == 0 {
+		r.SchemaVersion = 1
+	}
+	if r.SchemaVersion > Version {
+		return nil, fmt.Errorf("未対応のスキーマバージョン: %d (%d以下に対応)", r.SchemaVersion, Version)
+	}
+	return &r, nil
+}
+
+// Read parses a report from r
+func Read(r io.Reader) (*Report, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// ReadFile parses the report stored at path
+func ReadFile(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
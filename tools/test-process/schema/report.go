@@ -0,0 +1,141 @@
+// Package schema defines the JSON report written by test-process with
+// --format json and parses it for downstream tooling.
+//
+// Field names are stable within a schema version. New optional fields may be
+// added without changing Version; renaming a field or changing its type
+// bumps Version, and Parse keeps reading the older versions.
+//
+//	r, err := schema.ReadFile("report.json")
+//	fmt.Println(r.Operation, r.SuccessOps, time.Duration(r.Duration))
+package schema
+
+import "time"
+
+// Version is the schema version written by this build.
+//
+//	1: no schema_version field, durations as integer nanoseconds
+//	2: schema_version added, durations as strings such as "1.5s"
+const Version = 2
+
+// Report is the result of one test-process run
+type Report struct {
+	SchemaVersion int       `json:"schema_version"`
+	Operation     string    `json:"operation"`
+	Tag           string    `json:"tag,omitempty"`
+	Config        Config    `json:"config"`
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
+	Duration      Duration  `json:"duration"`
+	TotalOps      int       `json:"total_operations"`
+	SuccessOps    int       `json:"successful_operations"`
+	FailedOps     int       `json:"failed_operations"`
+	WarmupOps     int       `json:"warmup_operations,omitempty"`
+	Errors        []string  `json:"errors,omitempty"`
+	ProcessID     int       `json:"process_id"`
+	ChildPIDs     []int     `json:"child_process_ids,omitempty"`
+
+	// ProcessTree is rooted at this process; omitted when no process was started
+	ProcessTree *ProcessNode `json:"process_tree,omitempty"`
+
+	Latencies map[string]*LatencySummary `json:"latencies,omitempty"`
+	Timeline  []TimelineEntry            `json:"timeline,omitempty"`
+
+	Validation *ValidationResult `json:"validation,omitempty"`
+}
+
+// Config is the configuration the run was started with
+type Config struct {
+	Count     int      `json:"count"`
+	Interval  Duration `json:"interval"`
+	Dir       string   `json:"dir"`
+	Verbose   bool     `json:"verbose"`
+	Verbosity int      `json:"verbosity,omitempty"`
+	Command   string   `json:"command,omitempty"`
+	Ops       []string `json:"operations,omitempty"`
+	Duration  Duration `json:"duration,omitempty"`
+	Profile   string   `json:"profile,omitempty"`
+	BurstSize int      `json:"burst_size,omitempty"`
+	TreeDepth int      `json:"tree_depth,omitempty"`
+	TreeWidth int      `json:"tree_width,omitempty"`
+	TreeFiles int      `json:"tree_files,omitempty"`
+
+	StressCPU    int   `json:"stress_cpu,omitempty"`
+	StressMemory int64 `json:"stress_memory,omitempty"`
+
+	CrashMode string `json:"crash_mode,omitempty"`
+
+	PathLength int `json:"path_length,omitempty"`
+
+	Workers int `json:"workers,omitempty"`
+
+	Chunks    int   `json:"chunks,omitempty"`
+	ChunkSize int64 `json:"chunk_size,omitempty"`
+
+	OrphanDelay Duration `json:"orphan_delay,omitempty"`
+
+	Replay string `json:"replay,omitempty"`
+
+	Warmup Duration `json:"warmup,omitempty"`
+}
+
+// LatencyBucket counts operations whose latency is at most LE milliseconds.
+// LE is omitted for the overflow bucket.
+type LatencyBucket struct {
+	LE    *float64 `json:"le_ms,omitempty"`
+	Count int      `json:"count"`
+}
+
+// LatencySummary aggregates the latencies of one operation type
+type LatencySummary struct {
+	Count     int             `json:"count"`
+	Min       float64         `json:"min_ms"`
+	Mean      float64         `json:"mean_ms"`
+	P50       float64         `json:"p50_ms"`
+	P95       float64         `json:"p95_ms"`
+	P99       float64         `json:"p99_ms"`
+	Max       float64         `json:"max_ms"`
+	Histogram []LatencyBucket `json:"histogram"`
+}
+
+// TimelineEntry is the ground truth for one operation, in completion order
+type TimelineEntry struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Type   string    `json:"type"`
+	Path   string    `json:"path,omitempty"`
+	PID    int       `json:"pid,omitempty"`
+	Result string    `json:"result"`
+	Error  string    `json:"error,omitempty"`
+	Warmup bool      `json:"warmup,omitempty"`
+}
+
+// ProcessNode is a process in the tree started by the run. ExitCode is
+// omitted for processes that were not waited for, such as orphans.
+type ProcessNode struct {
+	PID      int           `json:"pid"`
+	ExitCode *int          `json:"exit_code,omitempty"`
+	Children []ProcessNode `json:"children,omitempty"`
+}
+
+// ValidationResult compares the operation timeline with the events recorded by the daemon
+type ValidationResult struct {
+	Tag         string          `json:"tag"`
+	Events      int             `json:"daemon_events"`
+	Matched     int             `json:"matched"`
+	Missing     int             `json:"missing"`
+	Extra       int             `json:"extra"`
+	Unchecked   int             `json:"unchecked"`
+	MissingOps  []TimelineEntry `json:"missing_operations,omitempty"`
+	ExtraEvents []DaemonEvent   `json:"extra_events,omitempty"`
+}
+
+// DaemonEvent is the subset of an event recorded by the ProcTail daemon that
+// validation needs. It keeps the daemon's own field names.
+type DaemonEvent struct {
+	Kind           string    `json:"$type"`
+	Timestamp      time.Time `json:"Timestamp"`
+	ProcessID      int       `json:"ProcessId"`
+	EventName      string    `json:"EventName"`
+	FilePath       string    `json:"FilePath"`
+	ChildProcessID int       `json:"ChildProcessId"`
+}
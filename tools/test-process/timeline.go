@@ -0,0 +1,37 @@
+package main
+
+import (
+	"proctail-test-process/operations"
+	"proctail-test-process/schema"
+	"sync"
+)
+
+// timelineRecorder collects timeline entries; operations may report from
+// several goroutines
+type timelineRecorder struct {
+	mu      sync.Mutex
+	entries []schema.TimelineEntry
+}
+
+// Record adds event; warmup marks operations excluded from the statistics
+func (t *timelineRecorder) Record(event operations.OperationEvent, warmup bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = append(t.entries, schema.TimelineEntry{
+		Start:  event.Start,
+		End:    event.Timestamp,
+		Type:   event.Type,
+		Path:   event.Path,
+		PID:    event.PID,
+		Result: event.Result,
+		Error:  event.Error,
+		Warmup: warmup,
+	})
+}
+
+// Entries returns the recorded timeline
+func (t *timelineRecorder) Entries() []schema.TimelineEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.entries
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"proctail-test-process/schema"
+	"sync"
+)
+
+// processTreeRecorder collects parent→child edges and exit codes; operations
+// may report from several goroutines
+type processTreeRecorder struct {
+	mu        sync.Mutex
+	children  map[int][]int
+	exitCodes map[int]int
+}
+
+// AddChild records that parent started child
+func (t *processTreeRecorder) AddChild(parent, child int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.children == nil {
+		t.children = make(map[int][]int)
+	}
+	t.children[parent] = append(t.children[parent], child)
+}
+
+// SetExitCode records the exit code of pid
+func (t *processTreeRecorder) SetExitCode(pid, code int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.exitCodes == nil {
+		t.exitCodes = make(map[int]int)
+	}
+	t.exitCodes[pid] = code
+}
+
+// Tree returns the tree rooted at root, or nil if root started no process
+func (t *processTreeRecorder) Tree(root int) *schema.ProcessNode {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.children[root]) == 0 {
+		return nil
+	}
+	node := t.node(root)
+	return &node
+}
+
+func (t *processTreeRecorder) node(pid int) schema.ProcessNode {
+	node := schema.ProcessNode{PID: pid}
+	if code, ok := t.exitCodes[pid]; ok {
+		node.ExitCode = &code
+	}
+	for _, child := range t.children[pid] {
+		node.Children = append(node.Children, t.node(child))
+	}
+	return node
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"path/filepath"
+	"proctail-test-process/schema"
+	"runtime"
+	"strings"
+)
+
+// expectedFileEvents maps an operation type to the daemon event names that
+// prove it was captured. Types missing here (renames report the old path,
+// composite operations have no single event) are counted as unchecked.
+var expectedFileEvents = map[string][]string{
+	"file-write":  {"FileIO/Write"},
+	"flush":       {"FileIO/Write"},
+	"file-read":   {"FileIO/Read"},
+	"file-delete": {"FileIO/Delete", "FileIO/SetInfo"},
+	"dir-create":  {"FileIO/Create"},
+	"dir-delete":  {"FileIO/Delete", "FileIO/SetInfo"},
+}
+
+// processOperations are matched against Process/Start events by child PID
+var processOperations = map[string]bool{
+	"child-process": true,
+	"process-tree":  true,
+	"orphan":        true,
+	"self-copy":     true,
+	"crash":         true,
+}
+
+// validateTimeline matches successful operations against daemon events,
+// except those run during --warmup. Extra events are file events under dir
+// on paths no operation touched.
+func validateTimeline(tag string, timeline []schema.TimelineEntry, events []schema.DaemonEvent, dir string) *schema.ValidationResult {
+	result := &schema.ValidationResult{Tag: tag, Events: len(events)}
+
+	for _, entry := range timeline {
+		if entry.Result != "success" || entry.Warmup {
+			continue
+		}
+		if processOperations[entry.Type] && entry.PID != 0 {
+			if hasProcessStart(events, entry.PID) {
+				result.Matched++
+			} else {
+				result.Missing++
+				result.MissingOps = append(result.MissingOps, entry)
+			}
+			continue
+		}
+		names, ok := expectedFileEvents[entry.Type]
+		if !ok {
+			result.Unchecked++
+			continue
+		}
+		if hasFileEvent(events, entry.Path, names) {
+			result.Matched++
+		} else {
+			result.Missing++
+			result.MissingOps = append(result.MissingOps, entry)
+		}
+	}
+
+	root := normalizePath(dir)
+	for _, event := range events {
+		if event.FilePath == "" {
+			continue
+		}
+		path := normalizePath(event.FilePath)
+		if !strings.HasPrefix(path, root) || touchedByTimeline(timeline, path) {
+			continue
+		}
+		result.Extra++
+		result.ExtraEvents = append(result.ExtraEvents, event)
+	}
+
+	return result
+}
+
+func hasProcessStart(events []schema.DaemonEvent, pid int) bool {
+	for _, event := range events {
+		if event.EventName == "Process/Start" && event.ChildProcessID == pid {
+			return true
+		}
+	}
+	return false
+}
+
+func hasFileEvent(events []schema.DaemonEvent, path string, names []string) bool {
+	path = normalizePath(path)
+	for _, event := range events {
+		if normalizePath(event.FilePath) != path {
+			continue
+		}
+		for _, name := range names {
+			if event.EventName == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// touchedByTimeline reports whether path is an operation target or lies under
+// one (dir-tree leaves, contend's moved-aside copies)
+func touchedByTimeline(timeline []schema.TimelineEntry, path string) bool {
+	for _, entry := range timeline {
+		if entry.Path != "" && strings.HasPrefix(path, normalizePath(entry.Path)) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if runtime.GOOS == "windows" {
+		path = strings.ToLower(path)
+	}
+	return path
+}